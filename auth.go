@@ -133,4 +133,12 @@ var (
 	// ErrSCRAMSHA256PLUSAuthNotSupported is returned when the server does not support the "SCRAM-SHA-256-PLUS" SMTP
 	// authentication type.
 	ErrSCRAMSHA256PLUSAuthNotSupported = errors.New("server does not support SMTP AUTH type: SCRAM-SHA-256-PLUS")
+
+	// ErrNoSupportedAuthNegotiated is returned when none of the SMTP authentication types provided via
+	// WithSMTPAuthList is advertised by the server.
+	ErrNoSupportedAuthNegotiated = errors.New("server does not support any of the requested SMTP AUTH types")
+
+	// ErrEmptySMTPAuthList is returned when an empty list of SMTP authentication types is provided to
+	// WithSMTPAuthList.
+	ErrEmptySMTPAuthList = errors.New("list of SMTP AUTH types must not be empty")
 )
@@ -20,20 +20,22 @@ const ErrNoOutWriter = "no io.Writer set for Base64LineBreaker"
 // of characters.
 //
 // This struct is used to manage base64 encoding while ensuring that new lines are inserted after
-// reaching a specific line length. It satisfies the io.WriteCloser interface.
+// reaching a specific line length. It satisfies the io.WriteCloser interface. If no line length
+// is set, MaxBodyLength is used.
 //
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc2045 (Base64 and line length limitations)
 type Base64LineBreaker struct {
-	line [MaxBodyLength]byte
-	used int
-	out  io.Writer
+	line       [MaxBodyLength]byte
+	lineLength int
+	used       int
+	out        io.Writer
 }
 
-// Write writes data to the Base64LineBreaker, ensuring lines do not exceed MaxBodyLength.
+// Write writes data to the Base64LineBreaker, ensuring lines do not exceed the configured line length.
 //
 // This method writes the provided data to the Base64LineBreaker. It ensures that the written
-// lines do not exceed the MaxBodyLength. If the data exceeds the limit, it handles the
+// lines do not exceed the configured line length. If the data exceeds the limit, it handles the
 // continuation by splitting the data and writing new lines as necessary.
 //
 // Parameters:
@@ -47,7 +49,8 @@ func (l *Base64LineBreaker) Write(data []byte) (numBytes int, err error) {
 		err = errors.New(ErrNoOutWriter)
 		return
 	}
-	if l.used+len(data) < MaxBodyLength {
+	maxLength := normalizeLineLength(l.lineLength)
+	if l.used+len(data) < maxLength {
 		copy(l.line[l.used:], data)
 		l.used += len(data)
 		return len(data), nil
@@ -57,7 +60,7 @@ func (l *Base64LineBreaker) Write(data []byte) (numBytes int, err error) {
 	if err != nil {
 		return
 	}
-	excess := MaxBodyLength - l.used
+	excess := maxLength - l.used
 	l.used = 0
 
 	numBytes, err = l.out.Write(data[0:excess])
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -418,6 +419,39 @@ func TestBase64LineBreaker(t *testing.T) {
 	}
 }
 
+// TestBase64LineBreaker_LineLength tests the Base64LineBreaker with a custom line length
+func TestBase64LineBreaker_LineLength(t *testing.T) {
+	data := bytes.Repeat([]byte("go-mail line length test "), 20)
+	for _, length := range []int{MinBodyLength, 72, MaxBodyLength} {
+		var wbuf bytes.Buffer
+		lb := Base64LineBreaker{out: &wbuf, lineLength: length}
+		bw := base64.NewEncoder(base64.StdEncoding, &lb)
+		if _, err := bw.Write(data); err != nil {
+			t.Fatalf("failed to write to b64 encoder: %s", err)
+		}
+		if err := bw.Close(); err != nil {
+			t.Fatalf("failed to close b64 encoder: %s", err)
+		}
+		if err := lb.Close(); err != nil {
+			t.Fatalf("failed to close line breaker: %s", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(wbuf.String(), SingleNewLine), SingleNewLine)
+		for i, line := range lines {
+			if len(line) > length || (i < len(lines)-1 && len(line) != length) {
+				t.Errorf("line breaker with a length of %d produced line of %d chars: %q", length,
+					len(line), line)
+			}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+		if err != nil {
+			t.Fatalf("failed to decode line breaker output: %s", err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("line breaker round trip failed for a line length of %d", length)
+		}
+	}
+}
+
 // TestBase64LineBreakerFailures tests the cases in which the Base64LineBreaker would fail
 func TestBase64LineBreakerFailures(t *testing.T) {
 	stt := []byte("short")
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// TypeAppICS represents the MIME type of the iCalendar attachment that Outlook expects in addition to
+	// the text/calendar part of a calendar invite.
+	TypeAppICS ContentType = "application/ics"
+
+	// calendarFileName is the file name of the iCalendar attachment of a calendar invite.
+	calendarFileName = "invite.ics"
+)
+
+// ErrInvalidCalendarMethod indicates that the provided method of a calendar invite is not an iTIP method, or
+// that it does not match the METHOD property of the iCalendar data.
+var ErrInvalidCalendarMethod = errors.New("invalid calendar method")
+
+// calendarMethods holds the methods defined by iTIP for the scheduling of calendar components.
+var calendarMethods = []string{
+	"PUBLISH", "REQUEST", "REPLY", "ADD", "CANCEL", "REFRESH", "COUNTER", "DECLINECOUNTER",
+}
+
+// SetCalendar adds the given iCalendar data as calendar invite to the Msg.
+//
+// The iCalendar data is added as alternative part with the content type "text/calendar; charset=UTF-8" and the
+// given method as "method" parameter, so that it is displayed as invite next to the text/plain and text/html
+// bodies of the Msg. The calendar part is always written as the last alternative, after the text/html body.
+// In addition, the same data is attached as "invite.ics" with the content type "application/ics", which
+// Outlook expects as fallback. Together with the bodies, this results in a multipart/mixed Msg with a
+// multipart/alternative part, which is accepted by Outlook and Google Calendar. The method is
+// case-insensitive and must be one of the iTIP methods. If the iCalendar data holds a METHOD property, it
+// must match the given method. Calling SetCalendar again replaces the previous calendar invite and its
+// fallback attachment, while other attachments, including "application/ics" files attached by the caller,
+// are kept.
+//
+// Parameters:
+//   - ics: The iCalendar data of the invite.
+//   - method: The iTIP method of the invite, e.g. "REQUEST" or "CANCEL".
+//
+// Returns:
+//   - An error wrapping ErrInvalidCalendarMethod if the method is invalid; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5545
+//   - https://datatracker.ietf.org/doc/html/rfc5546#section-1.4
+//   - https://datatracker.ietf.org/doc/html/rfc6047#section-2.4
+func (m *Msg) SetCalendar(ics []byte, method string) error {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if !isCalendarMethod(method) {
+		return fmt.Errorf("%w: %q", ErrInvalidCalendarMethod, method)
+	}
+	if icsMethod := calendarMethod(ics); icsMethod != "" && !strings.EqualFold(icsMethod, method) {
+		return fmt.Errorf("%w: %q does not match the METHOD property %q of the iCalendar data",
+			ErrInvalidCalendarMethod, method, icsMethod)
+	}
+
+	parts := make([]*Part, 0, len(m.parts)+1)
+	for _, part := range m.parts {
+		if !strings.EqualFold(part.contentType.String(), TypeTextCalendar.String()) {
+			parts = append(parts, part)
+		}
+	}
+	m.parts = parts
+	attachments := make([]*File, 0, len(m.attachments)+1)
+	for _, file := range m.attachments {
+		if file == nil || !file.isCalendarInvite {
+			attachments = append(attachments, file)
+		}
+	}
+	m.attachments = attachments
+
+	content := make([]byte, len(ics))
+	copy(content, ics)
+	m.AddPartWithContentType(TypeTextCalendar, content, WithPartCharset(CharsetUTF8),
+		WithPartContentTypeParam("method", method))
+	m.AttachReadSeeker(calendarFileName, bytes.NewReader(content), WithFileContentType(TypeAppICS))
+	m.attachments[len(m.attachments)-1].isCalendarInvite = true
+	return nil
+}
+
+// isCalendarMethod reports whether the given method is one of the iTIP methods.
+//
+// Parameters:
+//   - method: The upper case method to check.
+//
+// Returns:
+//   - true if the method is an iTIP method, false otherwise.
+func isCalendarMethod(method string) bool {
+	for _, calendarMethod := range calendarMethods {
+		if method == calendarMethod {
+			return true
+		}
+	}
+	return false
+}
+
+// calendarMethod returns the value of the METHOD property of the given iCalendar data.
+//
+// Parameters:
+//   - ics: The iCalendar data.
+//
+// Returns:
+//   - The value of the METHOD property, or an empty string if the iCalendar data has no METHOD property.
+func calendarMethod(ics []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(ics))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 7 && strings.EqualFold(line[:7], "METHOD:") {
+			return strings.TrimSpace(line[7:])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testCalendarInvite = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//go-mail//calendar test//EN\r\n" +
+	"METHOD:REQUEST\r\nBEGIN:VEVENT\r\nUID:4711@example.com\r\nDTSTAMP:20240101T090000Z\r\n" +
+	"DTSTART:20240102T090000Z\r\nSUMMARY:Planning\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+// TestMsg_SetCalendar tests the Msg.SetCalendar method
+func TestMsg_SetCalendar(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "You are invited to the planning")
+	m.AddAlternativeString(TypeTextHTML, "<p>You are invited to the planning</p>")
+	if err := m.SetCalendar([]byte(testCalendarInvite), "request"); err != nil {
+		t.Fatalf("SetCalendar() failed: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	message := buf.String()
+	mixed := strings.Index(message, "multipart/mixed")
+	alternative := strings.Index(message, "multipart/alternative")
+	calendar := strings.Index(message, "Content-Type: text/calendar; charset=UTF-8; method=REQUEST\r\n")
+	html := strings.Index(message, "Content-Type: text/html")
+	attachment := strings.Index(message, `Content-Type: application/ics; name="invite.ics"`)
+	if mixed == -1 || alternative == -1 || calendar == -1 || html == -1 || attachment == -1 {
+		t.Fatalf("SetCalendar() failed. Expected mixed, alternative, calendar and ics parts in message: %s",
+			message)
+	}
+	if mixed > alternative || alternative > html || html > calendar || calendar > attachment {
+		t.Errorf("SetCalendar() failed. Unexpected order of the parts in message: %s", message)
+	}
+	if !strings.Contains(message, `Content-Disposition: attachment; filename="invite.ics"`) {
+		t.Errorf("SetCalendar() failed. Expected ics attachment in message: %s", message)
+	}
+
+	if err := m.SetCalendar([]byte(strings.Replace(testCalendarInvite, "REQUEST", "CANCEL", 1)),
+		"CANCEL"); err != nil {
+		t.Fatalf("SetCalendar() failed: %s", err)
+	}
+	if len(m.parts) != 3 || len(m.attachments) != 1 {
+		t.Errorf("SetCalendar() failed. Expected the previous invite to be replaced, got %d parts and %d "+
+			"attachments", len(m.parts), len(m.attachments))
+	}
+	if got := m.parts[2].GetContentTypeParam("method"); got != "CANCEL" {
+		t.Errorf("SetCalendar() failed. Expected method: CANCEL, got: %q", got)
+	}
+
+	m = NewMsg()
+	m.SetBodyString(TypeTextPlain, "You are invited to the planning")
+	if err := m.AttachReader("holidays.ics", strings.NewReader(testCalendarInvite),
+		WithFileContentType(TypeAppICS)); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.SetCalendar([]byte(testCalendarInvite), "REQUEST"); err != nil {
+			t.Fatalf("SetCalendar() failed: %s", err)
+		}
+	}
+	if len(m.attachments) != 2 || m.attachments[0].Name != "holidays.ics" ||
+		m.attachments[1].Name != calendarFileName {
+		t.Errorf("SetCalendar() failed. Expected the attached ics file to be kept, got %d attachments",
+			len(m.attachments))
+	}
+}
+
+// TestMsg_SetCalendar_invalidMethod tests that Msg.SetCalendar rejects invalid methods
+func TestMsg_SetCalendar_invalidMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		ics    string
+		method string
+	}{
+		{"empty method", testCalendarInvite, ""},
+		{"unknown method", testCalendarInvite, "INVITE"},
+		{"method with parameter", testCalendarInvite, "REQUEST; charset=UTF-8"},
+		{"mismatching METHOD property", testCalendarInvite, "PUBLISH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := m.SetCalendar([]byte(tt.ics), tt.method); !errors.Is(err, ErrInvalidCalendarMethod) {
+				t.Errorf("SetCalendar() expected error: %s, got: %v", ErrInvalidCalendarMethod, err)
+			}
+			if len(m.parts) != 0 || len(m.attachments) != 0 {
+				t.Errorf("SetCalendar() with invalid method expected no invite to be added")
+			}
+		})
+	}
+}
@@ -5,12 +5,15 @@
 package mail
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -114,12 +117,56 @@ type (
 	//   - https://datatracker.ietf.org/doc/html/rfc3207#section-2
 	//   - https://datatracker.ietf.org/doc/html/rfc8314
 	Client struct {
+		// afterSendHook is an optional function that is called after each message has been sent.
+		afterSendHook func(message *Msg, err error, duration time.Duration)
+
+		// attachmentMaxCount is the maximum number of attachments of a message the Client sends. Zero means
+		// that the number of attachments is not limited by the Client.
+		attachmentMaxCount int
+
+		// attachmentMaxSize is the maximum total size in bytes of the encoded attachments of a message the
+		// Client sends. Zero means that the size of the attachments is not limited by the Client.
+		attachmentMaxSize int64
+
+		// autoEncoding indicates whether quoted-printable encoded body parts are sent with the 8bit
+		// encoding, if the server supports the 8BITMIME extension.
+		autoEncoding bool
+
+		// beforeSendHook is an optional function that is called before each message is sent.
+		beforeSendHook func(message *Msg)
+
+		// chunking indicates whether the message content is transferred with the BDAT command instead of
+		// the DATA command, if the server supports the CHUNKING extension.
+		chunking bool
+
+		// commandTimeout specifies the timeout for each SMTP command after the connection has been established.
+		// If zero, connTimeout is used for the session as a whole.
+		commandTimeout time.Duration
+
 		// connTimeout specifies timeout for the connection to the SMTP server.
 		connTimeout time.Duration
 
+		// connectTimeout specifies the timeout for dialing the SMTP server, the greeting, the HELO/EHLO and
+		// the TLS handshake. If zero, connTimeout is used.
+		connectTimeout time.Duration
+
+		// connection is the network connection to the SMTP server established by DialWithContext.
+		connection net.Conn
+
+		// dataTimeout specifies the timeout for the transfer of the message content with the DATA or BDAT
+		// command, including the final response of the server. If zero, the deadline of the session applies.
+		dataTimeout time.Duration
+
 		// dialContextFunc is the DialContextFunc that is used by the Client to connect to the SMTP server.
 		dialContextFunc DialContextFunc
 
+		// dryRun indicates that the Client does not connect to the SMTP server, but only renders the
+		// messages passed to Send.
+		dryRun bool
+
+		// dryRunOutput is the io.Writer the rendered messages are written to in dry-run mode.
+		dryRunOutput io.Writer
+
 		// dsnRcptNotifyType represents the different types of notifications for DSN (Delivery Status Notifications)
 		// receipts.
 		dsnRcptNotifyType []string
@@ -128,6 +175,11 @@ type (
 		// email.
 		dsnReturnType DSNMailReturnOption
 
+		// explicitTLS indicates whether STARTTLS has been selected explicitly with a policy other than
+		// NoTLS, via WithTLSPolicy, WithTLSPortPolicy, SetTLSPolicy or SetTLSPortPolicy. The last selected
+		// policy counts, so that a later NoTLS policy deselects STARTTLS again.
+		explicitTLS bool
+
 		// fallbackPort is used as an alternative port number in case the primary port is unavailable or
 		// fails to bind.
 		//
@@ -145,9 +197,24 @@ type (
 		// isEncrypted indicates wether the Client connection is encrypted or not.
 		isEncrypted bool
 
+		// lastTLSState holds the TLS connection state of the connection that was used for the last message
+		// sent, or nil if that connection was not encrypted.
+		lastTLSState *tls.ConnectionState
+
 		// logger is a logger that satisfies the log.Logger interface.
 		logger log.Logger
 
+		// mailParams holds additional parameters that are appended to the MAIL FROM command.
+		mailParams []string
+
+		// maxMessageSize is the maximum size in bytes of a rendered message the Client sends. Zero means
+		// that the size is not limited by the Client.
+		maxMessageSize int64
+
+		// messageIDGenerator is an optional function that generates the Message-ID for messages that are
+		// sent without an explicitly set Message-ID.
+		messageIDGenerator func() string
+
 		// mutex is used to synchronize access to shared resources, ensuring that only one goroutine can
 		// modify them at a time.
 		mutex sync.RWMutex
@@ -161,12 +228,53 @@ type (
 		// pass represents a password or a secret token used for the SMTP authentication.
 		pass string
 
+		// pipelining indicates that the MAIL and RCPT commands are sent in a single batch if the server
+		// supports the PIPELINING extension.
+		pipelining bool
+
 		// port specifies the network port that is used to establish the connection with the SMTP server.
 		port int
 
+		// rateLimiter is an optional RateLimiter that limits the number of messages sent per second.
+		rateLimiter *RateLimiter
+
+		// rcptFilter is an optional function that decides whether a Msg is sent to a recipient.
+		rcptFilter func(addr string) bool
+
+		// rcptParams holds additional parameters that are appended to each RCPT TO command.
+		rcptParams []string
+
+		// reconnect indicates whether the Client reconnects to the server, if the connection is found to be
+		// broken before or after sending a message.
+		reconnect bool
+
 		// requestDSN indicates wether we want to request DSN (Delivery Status Notifications).
 		requestDSN bool
 
+		// requireTLS indicates that messages are sent with the REQUIRETLS parameter of RFC 8689, which
+		// requires TLS for every hop of the delivery.
+		requireTLS bool
+
+		// retryAttempts is the maximum number of attempts to send a Msg. Values below two disable the
+		// retrying of failed messages.
+		retryAttempts int
+
+		// retryBackoff is an optional function that returns the delay before the next attempt to send a
+		// Msg, after the given attempt has failed with a temporary error.
+		retryBackoff func(attempt int) time.Duration
+
+		// saslPrep indicates that the credentials are prepared with SASLprep before they are used for the
+		// PLAIN and LOGIN SMTP authentication.
+		saslPrep bool
+
+		// sessionMutex serializes the use of the connection to the SMTP server by Send, SendWithContext,
+		// SendWithResults, Reset and Close, so that the SMTP commands of concurrent calls are not interleaved.
+		sessionMutex sync.Mutex
+
+		// sizeCheck indicates whether the size of each message is checked against the SIZE limit advertised
+		// by the server and declared in the MAIL command.
+		sizeCheck bool
+
 		// smtpAuth is the authentication type that is used to authenticate the user with SMTP server. It
 		// satisfies the smtp.Auth interface.
 		//
@@ -174,6 +282,14 @@ type (
 		// You should use one of go-mail's SMTPAuthType, instead.
 		smtpAuth smtp.Auth
 
+		// smtpAuthList is a prioritized list of SMTPAuthType values that are negotiated against the SMTP
+		// AUTH mechanisms advertised by the server. If set, the first supported type is used.
+		smtpAuthList []SMTPAuthType
+
+		// smtpAuthNegotiated is the SMTPAuthType negotiated from smtpAuthList that the cached smtpAuth has
+		// been created for. The configured smtpAuthType is kept, so that each connection negotiates again.
+		smtpAuthNegotiated SMTPAuthType
+
 		// smtpAuthType specifies the authentication type to be used for SMTP authentication.
 		smtpAuthType SMTPAuthType
 
@@ -188,32 +304,110 @@ type (
 		// tlsconfig is a pointer to tls.Config that specifies the TLS configuration for the STARTTLS communication.
 		tlsconfig *tls.Config
 
+		// tlsServerName overrides the ServerName of the tls.Config that is used to verify the certificate of
+		// the server.
+		tlsServerName string
+
 		// useDebugLog indicates whether debug level logging is enabled for the Client.
 		useDebugLog bool
 
 		// user represents a username used for the SMTP authentication.
 		user string
 
+		// userAgent is the default User-Agent and X-Mailer of the messages the Client sends, if they do not
+		// set these headers themselves. If empty, the go-mail default is used.
+		userAgent string
+
+		// validation indicates whether each Msg is validated via Msg.Validate before it is sent.
+		validation bool
+
+		// verp is an optional function that computes a Variable Envelope Return Path for each recipient.
+		// If set, each Msg is sent in a separate SMTP transaction per recipient.
+		verp func(recipient string) string
+
+		// xoauth2TokenSource is an optional function that provides the bearer token for the XOAUTH2 SMTP
+		// authentication on each connection.
+		xoauth2TokenSource func(ctx context.Context) (string, error)
+
 		// useSSL indicates whether to use SSL/TLS encryption for network communication.
 		//
 		// https://datatracker.ietf.org/doc/html/rfc8314
 		useSSL bool
 	}
+
+	// SendResult represents the delivery result of a Msg for a single recipient.
+	//
+	// A SendResult is returned for each recipient of each Msg by Client.SendWithResults. If the
+	// delivery to the recipient succeeded, Err is nil. Otherwise, Err holds the error that caused the
+	// delivery to fail for this recipient, which usually is of type *SendError.
+	SendResult struct {
+		// EnvelopeFrom is the envelope sender address that was used in the MAIL FROM command for the
+		// recipient. It is empty if the transaction for the recipient was not started.
+		EnvelopeFrom string
+
+		// Err is the delivery error for the recipient, or nil if the Msg was accepted for the recipient.
+		Err error
+
+		// Msg is a pointer to the Msg the result belongs to.
+		Msg *Msg
+
+		// Recipient is the envelope recipient address the result belongs to. It is empty if the
+		// recipients of the Msg could not be determined.
+		Recipient string
+
+		// Skipped indicates that the recipient was dropped by the recipient filter of the Client, so
+		// that the Msg was not sent to it.
+		Skipped bool
+	}
 )
 
 var (
 	// ErrInvalidPort is returned when the specified port for the SMTP connection is not valid
 	ErrInvalidPort = errors.New("invalid port number")
 
+	// ErrInvalidRetryAttempts is returned when the provided maximum number of send attempts is zero or negative.
+	ErrInvalidRetryAttempts = errors.New("invalid number of retry attempts - must be greater than zero")
+
 	// ErrInvalidTimeout is returned when the specified timeout is zero or negative.
 	ErrInvalidTimeout = errors.New("timeout cannot be zero or negative")
 
 	// ErrInvalidHELO is returned when the HELO/EHLO value is invalid due to being empty.
 	ErrInvalidHELO = errors.New("invalid HELO/EHLO value - must not be empty")
 
+	// ErrInvalidAttachmentLimits is returned when one of the provided attachment limits is negative or when
+	// both limits are zero.
+	ErrInvalidAttachmentLimits = errors.New("invalid attachment limits - must not be negative or both zero")
+
+	// ErrInvalidRecipientFilter is returned when the provided recipient filter is nil or when no valid
+	// domain is provided for the allowed recipient domains.
+	ErrInvalidRecipientFilter = errors.New("invalid recipient filter - must not be nil or empty")
+
+	// ErrInvalidSendHook is returned when the provided send hook is nil.
+	ErrInvalidSendHook = errors.New("invalid send hook - must not be nil")
+
+	// ErrInvalidMaxMessageSize is returned when the provided maximum message size is zero or negative.
+	ErrInvalidMaxMessageSize = errors.New("invalid maximum message size - must be greater than zero")
+
+	// ErrInvalidUserAgent is returned when the provided product for the default User-Agent is empty.
+	ErrInvalidUserAgent = errors.New("invalid user agent - product must not be empty")
+
+	// ErrInvalidSMTPParam is returned when a parameter for the MAIL FROM or RCPT TO command is empty or
+	// contains spaces or control characters. It is the same error as smtp.ErrInvalidParam, since the
+	// parameters are validated by the smtp package.
+	ErrInvalidSMTPParam = smtp.ErrInvalidParam
+
 	// ErrInvalidTLSConfig is returned when the provided TLS configuration is invalid or nil.
 	ErrInvalidTLSConfig = errors.New("invalid TLS config")
 
+	// ErrInvalidTLSServerName is returned when the provided TLS server name is empty.
+	ErrInvalidTLSServerName = errors.New("invalid TLS server name - must not be empty")
+
+	// ErrInvalidVERPFunc is returned when the provided VERP function is nil.
+	ErrInvalidVERPFunc = errors.New("invalid VERP function - must not be nil")
+
+	// ErrSSLWithSTARTTLS is returned when both implicit SSL/TLS and STARTTLS are selected for the Client.
+	ErrSSLWithSTARTTLS = errors.New("implicit SSL/TLS and STARTTLS cannot be used together")
+
 	// ErrNoHostname is returned when the hostname for the client is not provided or empty.
 	ErrNoHostname = errors.New("hostname for client cannot be empty")
 
@@ -223,9 +417,26 @@ var (
 	// ErrNoActiveConnection indicates that there is no active connection to the SMTP server.
 	ErrNoActiveConnection = errors.New("not connected to SMTP server")
 
+	// ErrPipelinedDataAborted is returned when a transaction is aborted after the server accepted the
+	// pipelined DATA command. Since the transaction cannot be reset in the data phase, the connection
+	// to the server is closed to make sure that the message is not delivered.
+	ErrPipelinedDataAborted = errors.New("connection closed to abort the transaction after the " +
+		"pipelined DATA command was accepted")
+
 	// ErrServerNoUnencoded indicates that the server does not support 8BITMIME for unencoded 8-bit messages.
 	ErrServerNoUnencoded = errors.New("message is 8bit unencoded, but server does not support 8BITMIME")
 
+	// ErrSTARTTLSNotSupported is returned when the TLSPolicy is set to TLSMandatory but the server does
+	// not advertise the STARTTLS extension.
+	ErrSTARTTLSNotSupported = errors.New("server does not support STARTTLS")
+
+	// ErrREQUIRETLSNotSupported is returned when the REQUIRETLS parameter is requested via WithRequireTLS but
+	// the connection to the server is not encrypted or the server does not advertise the REQUIRETLS extension.
+	ErrREQUIRETLSNotSupported = errors.New("server does not support REQUIRETLS")
+
+	// ErrSTARTTLSFailed is returned when the STARTTLS handshake with the server fails.
+	ErrSTARTTLSFailed = errors.New("STARTTLS handshake failed")
+
 	// ErrInvalidDSNMailReturnOption is returned when an invalid DSNMailReturnOption is provided as argument
 	// to the WithDSN Option.
 	ErrInvalidDSNMailReturnOption = errors.New("DSN mail return option can only be HDRS or FULL")
@@ -282,6 +493,9 @@ func NewClient(host string, opts ...Option) (*Client, error) {
 	if c.host == "" {
 		return c, ErrNoHostname
 	}
+	if c.useSSL && c.explicitTLS {
+		return c, ErrSSLWithSTARTTLS
+	}
 
 	return c, nil
 }
@@ -310,7 +524,9 @@ func WithPort(port int) Option {
 // WithTimeout sets the connection timeout for the Client and overrides the default timeout.
 //
 // This function configures the Client with a specified connection timeout duration. It validates that the
-// provided timeout is greater than zero. If the timeout is invalid, an error is returned.
+// provided timeout is greater than zero. If the timeout is invalid, an error is returned. The timeout
+// applies to all phases of the SMTP session for which no specific timeout has been set via
+// WithConnectTimeout, WithCommandTimeout or WithDataTimeout.
 //
 // Parameters:
 //   - timeout: The duration to be set as the connection timeout. Must be greater than zero.
@@ -328,9 +544,83 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithConnectTimeout sets the timeout for establishing the connection to the SMTP server.
+//
+// The timeout bounds dialing the server, reading its greeting, the HELO/EHLO command and the TLS
+// handshake, be it implicit or via STARTTLS, so that an unreachable server is detected quickly while
+// the later phases of the SMTP session may take longer. If not set, the timeout set via WithTimeout
+// applies.
+//
+// Parameters:
+//   - timeout: The duration to be set as the connect timeout. Must be greater than zero.
+//
+// Returns:
+//   - An Option function that applies the connect timeout to the Client.
+//   - An error if the timeout duration is invalid.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		c.connectTimeout = timeout
+		return nil
+	}
+}
+
+// WithCommandTimeout sets the timeout for each SMTP command after the connection has been established.
+//
+// With this option, a new deadline is set on the connection before each SMTP command, e.g. AUTH, MAIL
+// FROM, RCPT TO or RSET, so that each command and its response are bound by the timeout on their own. The
+// transfer of the message content is not bound by it, see WithDataTimeout. If not set, the timeout set
+// via WithTimeout applies to the session as a whole and is only renewed before each message is sent.
+//
+// Parameters:
+//   - timeout: The duration to be set as the command timeout. Must be greater than zero.
+//
+// Returns:
+//   - An Option function that applies the command timeout to the Client.
+//   - An error if the timeout duration is invalid.
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		c.commandTimeout = timeout
+		return nil
+	}
+}
+
+// WithDataTimeout sets the timeout for the transfer of the message content.
+//
+// The timeout bounds the transfer of the message content after the DATA command, or with the BDAT
+// command if chunking is enabled, including the final response of the server. This allows for a longer
+// window to upload messages with big attachments, while the other phases of the SMTP session fail fast.
+// If not set, the deadline of the session set via WithTimeout or WithCommandTimeout applies.
+//
+// Parameters:
+//   - timeout: The duration to be set as the data timeout. Must be greater than zero.
+//
+// Returns:
+//   - An Option function that applies the data timeout to the Client.
+//   - An error if the timeout duration is invalid.
+func WithDataTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		c.dataTimeout = timeout
+		return nil
+	}
+}
+
 // WithSSL enables implicit SSL/TLS for the Client.
 //
-// This function configures the Client to use implicit SSL/TLS for secure communication.
+// This function configures the Client to use implicit SSL/TLS for secure communication, i.e. the
+// connection is wrapped in TLS from the first byte, as it is offered by many providers on port 465
+// (SMTPS), and no STARTTLS is issued. The TLS handshake uses the same tls.Config as STARTTLS, which can
+// be set via WithTLSConfig. Since implicit SSL/TLS and STARTTLS are mutually exclusive, NewClient and
+// DialWithContext fail with ErrSSLWithSTARTTLS if STARTTLS is selected as well via WithTLSPolicy,
+// WithTLSPortPolicy, SetTLSPolicy or SetTLSPortPolicy with a policy other than NoTLS.
 //
 // Returns:
 //   - An Option function that enables SSL/TLS for the Client.
@@ -363,10 +653,12 @@ func WithSSLPort(fallback bool) Option {
 
 // WithDebugLog enables debug logging for the Client.
 //
-// This function activates debug logging, which logs incoming and outgoing communication between the
-// Client and the SMTP server to os.Stderr. Be cautious when using this option, as the logs may include
-// unencrypted authentication data, depending on the SMTP authentication method in use, which could
-// pose a data protection risk.
+// This function activates debug logging, which logs each command sent to and each reply received from
+// the SMTP server to os.Stderr, or to the logger set via WithLogger. The credentials sent during the SMTP
+// authentication are redacted, while the mechanism and the replies of the server are still logged.
+// Enabling the debug logging does not change the communication with the server. Be cautious when using
+// this option nonetheless, as the logs include the sender and recipient addresses of the sent messages,
+// which could pose a data protection risk.
 //
 // Returns:
 //   - An Option function that enables debug logging for the Client.
@@ -381,6 +673,8 @@ func WithDebugLog() Option {
 //
 // This function sets a custom logger for the Client, which must satisfy the log.Logger interface. The custom
 // logger is used only when debug logging is enabled. By default, log.Stdlog is used if no custom logger is provided.
+// Each log message carries the direction of the communication, so that a structured logger like log.JSONlog,
+// which is based on log/slog, can route it accordingly.
 //
 // Parameters:
 //   - logger: A logger that satisfies the log.Logger interface.
@@ -397,7 +691,10 @@ func WithLogger(logger log.Logger) Option {
 // WithHELO sets the HELO/EHLO string used by the Client.
 //
 // This function configures the HELO/EHLO string sent by the Client when initiating communication
-// with the SMTP server. By default, os.Hostname is used to identify the HELO/EHLO string.
+// with the SMTP server. By default, os.Hostname is used to identify the HELO/EHLO string. Strict relays
+// may reject a greeting that does not match a FQDN or the PTR record of the sending host, in which case
+// the exact name can be set with this option. If the server rejects the EHLO command, the Client falls
+// back to the HELO command with the same name.
 //
 // Parameters:
 //   - helo: The string to be used for the HELO/EHLO greeting. Must not be empty.
@@ -430,7 +727,7 @@ func WithHELO(helo string) Option {
 // WithTLSPortPolicy instead.
 func WithTLSPolicy(policy TLSPolicy) Option {
 	return func(c *Client) error {
-		c.tlspolicy = policy
+		c.SetTLSPolicy(policy)
 		return nil
 	}
 }
@@ -477,6 +774,34 @@ func WithTLSConfig(tlsconfig *tls.Config) Option {
 	}
 }
 
+// WithTLSServerName sets the server name that is used to verify the certificate of the SMTP server.
+//
+// By default, the certificate of the server is verified against the ServerName of the tls.Config,
+// which is the hostname of the Client unless a custom tls.Config is set. If the server is reached via
+// an address that is not part of its certificate, e.g. a load balancer or an IP address, this option
+// allows to specify the name the certificate is issued for, instead of disabling the verification.
+// The server name is applied to both, the STARTTLS handshake and SSL/TLS connections, and takes
+// precedence over the ServerName of a tls.Config set via WithTLSConfig.
+//
+// Parameters:
+//   - serverName: The name the certificate of the SMTP server is verified against. Must not be empty.
+//
+// Returns:
+//   - An Option function that sets the TLS server name for the Client.
+//   - An error if the provided server name is empty.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6125
+func WithTLSServerName(serverName string) Option {
+	return func(c *Client) error {
+		if serverName == "" {
+			return ErrInvalidTLSServerName
+		}
+		c.tlsServerName = serverName
+		return nil
+	}
+}
+
 // WithSMTPAuth configures the Client to use the specified SMTPAuthType for SMTP authentication.
 //
 // This function sets the Client to use the specified SMTPAuthType for authenticating with the SMTP server.
@@ -489,6 +814,33 @@ func WithTLSConfig(tlsconfig *tls.Config) Option {
 func WithSMTPAuth(authtype SMTPAuthType) Option {
 	return func(c *Client) error {
 		c.smtpAuthType = authtype
+		c.smtpAuthList = nil
+		return nil
+	}
+}
+
+// WithSMTPAuthList configures the Client to negotiate the SMTP authentication mechanism from a
+// prioritized list of SMTPAuthType values.
+//
+// When authenticating, the Client compares the provided list against the AUTH mechanisms advertised
+// by the server in its EHLO response and uses the first type that is supported by the server. This
+// allows to fail over between servers that advertise different sets of mechanisms without having to
+// reconfigure the Client. If none of the types is supported, the authentication fails with an error
+// wrapping ErrNoSupportedAuthNegotiated, naming the requested and offered mechanisms.
+//
+// Parameters:
+//   - authtypes: The SMTPAuthType values to negotiate, in order of preference.
+//
+// Returns:
+//   - An Option function that configures the Client to negotiate the SMTP authentication type.
+//   - ErrEmptySMTPAuthList if no SMTPAuthType is provided.
+func WithSMTPAuthList(authtypes ...SMTPAuthType) Option {
+	return func(c *Client) error {
+		if len(authtypes) == 0 {
+			return ErrEmptySMTPAuthList
+		}
+		c.smtpAuthList = authtypes
+		c.smtpAuthType = authtypes[0]
 		return nil
 	}
 }
@@ -506,6 +858,7 @@ func WithSMTPAuth(authtype SMTPAuthType) Option {
 func WithSMTPAuthCustom(smtpAuth smtp.Auth) Option {
 	return func(c *Client) error {
 		c.smtpAuth = smtpAuth
+		c.smtpAuthList = nil
 		c.smtpAuthType = SMTPAuthCustom
 		return nil
 	}
@@ -640,6 +993,87 @@ func WithDSNRcptNotifyType(opts ...DSNRcptNotifyOption) Option {
 	}
 }
 
+// WithRequireTLS enables the REQUIRETLS SMTP extension for the Client.
+//
+// With REQUIRETLS, the sender requests that the message is only relayed via TLS connections with a verified
+// server certificate on every hop until the final delivery and that it is rejected or bounced rather than
+// delivered in plaintext. If this option is set, the "REQUIRETLS" parameter is appended to the MAIL FROM
+// command of every message. Since RFC 8689 only permits the parameter on a TLS connection to a server that
+// advertises the REQUIRETLS extension, the delivery of a message is aborted with a SendError wrapping
+// ErrREQUIRETLSNotSupported if the connection is not encrypted or the server does not advertise the
+// extension, instead of falling back to a delivery without REQUIRETLS. A TLSPolicy of TLSMandatory should
+// be used with this option, so that the connection is not established without STARTTLS in the first place.
+// The "TLS-Required: No" header field (HeaderTLSRequired) of RFC 8689 expresses the opposite preference and
+// is ignored by the servers if the REQUIRETLS parameter is present.
+//
+// Returns:
+//   - An Option function that enables REQUIRETLS for the Client.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8689#section-4.1
+func WithRequireTLS() Option {
+	return func(c *Client) error {
+		c.requireTLS = true
+		return nil
+	}
+}
+
+// WithMailParams sets additional parameters that are appended to the MAIL FROM command of each
+// SMTP transaction.
+//
+// This option allows to use ESMTP extensions that are not directly supported by the Client, e.g. the
+// "AUTH=<>" parameter of RFC 4954 or the "ENVID" parameter of RFC 3461. Each parameter has the form
+// "keyword" or "keyword=value" and is sent as it is, after the parameters that are set by the Client
+// itself, like BODY, SIZE or RET. Unlike those, the parameters are sent regardless of the extensions
+// advertised by the server, so it is up to the caller to only use them with servers that support them.
+// To request DSN, use WithDSN, WithDSNMailReturnType or WithDSNRcptNotifyType instead, which only send
+// the DSN parameters if the server advertises the DSN extension.
+//
+// Parameters:
+//   - params: A variadic list of parameters for the MAIL FROM command.
+//
+// Returns:
+//   - An Option function that sets the MAIL FROM parameters for the Client.
+//   - An error if any of the parameters is empty or contains spaces or control characters.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.1.2
+func WithMailParams(params ...string) Option {
+	return func(c *Client) error {
+		if err := smtp.ValidateParams(params...); err != nil {
+			return err
+		}
+		c.mailParams = params
+		return nil
+	}
+}
+
+// WithRcptParams sets additional parameters that are appended to each RCPT TO command.
+//
+// This option allows to use ESMTP extensions that are not directly supported by the Client, e.g. the
+// "ORCPT" parameter of RFC 3461. Each parameter has the form "keyword" or "keyword=value" and is sent
+// as it is, after the NOTIFY parameter that is set by the Client if DSN is requested. The parameters are
+// sent regardless of the extensions advertised by the server.
+//
+// Parameters:
+//   - params: A variadic list of parameters for the RCPT TO command.
+//
+// Returns:
+//   - An Option function that sets the RCPT TO parameters for the Client.
+//   - An error if any of the parameters is empty or contains spaces or control characters.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.1.2
+func WithRcptParams(params ...string) Option {
+	return func(c *Client) error {
+		if err := smtp.ValidateParams(params...); err != nil {
+			return err
+		}
+		c.rcptParams = params
+		return nil
+	}
+}
+
 // WithoutNoop indicates that the Client should skip the "NOOP" command during the dial.
 //
 // This option is useful for servers that delay potentially unwanted clients when they perform
@@ -657,7 +1091,12 @@ func WithoutNoop() Option {
 // WithDialContextFunc sets the provided DialContextFunc as the DialContext for connecting to the SMTP server.
 //
 // This function overrides the default DialContext function used by the Client when establishing a connection
-// to the SMTP server with the provided DialContextFunc.
+// to the SMTP server with the provided DialContextFunc. This allows routing the connection through a proxy,
+// binding it to a specific local address or injecting test doubles. The context passed to DialWithContext
+// or DialAndSendWithContext is propagated to the DialContextFunc, with the connection timeout of the Client
+// applied as deadline. Since the DialContextFunc replaces the default dialer entirely, it is responsible for
+// establishing the TLS session itself if implicit SSL/TLS is required. If dialCtxFunc is nil, the default
+// net.Dialer (or tls.Dialer, if SSL is enabled) is used.
 //
 // Parameters:
 //   - dialCtxFunc: The custom DialContextFunc to be used for connecting to the SMTP server.
@@ -671,554 +1110,2347 @@ func WithDialContextFunc(dialCtxFunc DialContextFunc) Option {
 	}
 }
 
-// TLSPolicy returns the TLSPolicy that is currently set on the Client as a string.
+// WithXOAuth2TokenSource sets a function that provides the bearer token for the XOAUTH2 SMTP
+// authentication.
 //
-// This method retrieves the current TLSPolicy configured for the Client and returns it as a string representation.
+// OAuth 2.0 access tokens usually expire after a short time. Instead of using the static password
+// of the Client as token, the token source is called right before the AUTH command on each new
+// connection to the server, so that a fresh token can be fetched or refreshed. The context passed
+// to DialWithContext or DialAndSendWithContext is passed on to the token source. The token is
+// sent together with the username of the Client as defined by the XOAUTH2 protocol. If the token
+// source returns an error, the connection attempt is aborted and the error is returned wrapped.
+// When passed to a Pool via WithPoolClientOptions, the token source is called for every new or
+// replaced pooled connection.
+//
+// The token source is only used if the SMTPAuthType of the Client is SMTPAuthXOAUTH2.
+//
+// Parameters:
+//   - tokenSource: A function returning the bearer token to authenticate with.
 //
 // Returns:
-//   - A string representing the currently set TLSPolicy for the Client.
-func (c *Client) TLSPolicy() string {
-	return c.tlspolicy.String()
+//   - An Option function that sets the XOAUTH2 token source for the Client.
+//
+// References:
+//   - https://developers.google.com/gmail/imap/xoauth2-protocol
+func WithXOAuth2TokenSource(tokenSource func(ctx context.Context) (string, error)) Option {
+	return func(c *Client) error {
+		c.xoauth2TokenSource = tokenSource
+		return nil
+	}
 }
 
-// ServerAddr returns the server address that is currently set on the Client in the format "host:port".
+// WithSASLprep enables the SASLprep preparation of the credentials for the PLAIN and LOGIN SMTP
+// authentication.
 //
-// This method constructs and returns the server address using the host and port currently configured
-// for the Client.
+// The PLAIN and LOGIN mechanisms transmit the raw UTF-8 bytes of the username and password. If
+// the credentials contain non-ASCII characters, the same visual string might be encoded with
+// different bytes, e.g. with precomposed or decomposed accented characters, which causes the
+// authentication to fail. With this option, the username and password are normalized with
+// smtp.SASLprep before they are sent to the server. The SCRAM mechanisms always apply this
+// normalization as required by RFC 5802.
 //
 // Returns:
-//   - A string representing the server address in the format "host:port".
-func (c *Client) ServerAddr() string {
-	return fmt.Sprintf("%s:%d", c.host, c.port)
+//   - An Option function that enables the SASLprep preparation of the credentials.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc4013
+//   - https://datatracker.ietf.org/doc/html/rfc8265
+func WithSASLprep() Option {
+	return func(c *Client) error {
+		c.saslPrep = true
+		return nil
+	}
 }
 
-// SetTLSPolicy sets or overrides the TLSPolicy currently configured on the Client with the given TLSPolicy.
+// WithPipelining enables the use of the SMTP PIPELINING extension for the Client.
 //
-// This method allows the user to set a new TLSPolicy for the Client. For best practices regarding
-// SMTP TLS connections, it is recommended to use SetTLSPortPolicy instead.
+// By default, the Client waits for the response to each SMTP command before sending the next one.
+// With this option, and if the server advertises the PIPELINING extension, the MAIL command, the
+// RCPT commands for all recipients of a Msg and the DATA command are sent to the server in a single
+// batch and the responses are read afterwards. This saves a round trip for each command, which
+// significantly speeds up the delivery over high-latency connections. Each response is associated
+// with its command, so that rejected recipients are reported the same way as without pipelining.
+// If the message is transferred with BDAT (see WithChunking), only the MAIL and RCPT commands are
+// batched. If the server does not support pipelining, the commands are sent one after another as
+// usual.
 //
-// Parameters:
-//   - policy: The TLSPolicy to be set for the Client.
-func (c *Client) SetTLSPolicy(policy TLSPolicy) {
-	c.tlspolicy = policy
+// Since a transaction cannot be reset once the server accepted the DATA command, a transaction that
+// must be aborted because some recipients were rejected (i.e. partial delivery is not allowed) ends
+// with the connection being closed and ErrPipelinedDataAborted being reported.
+//
+// Returns:
+//   - An Option function that enables the use of the PIPELINING extension for the Client.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2920
+func WithPipelining() Option {
+	return func(c *Client) error {
+		c.pipelining = true
+		return nil
+	}
 }
 
-// SetTLSPortPolicy sets or overrides the TLSPolicy currently configured on the Client with the given TLSPolicy.
-// The correct port is automatically set based on the specified policy.
+// WithDryRun enables the dry-run mode of the Client.
 //
-// If TLSMandatory or TLSOpportunistic is provided as the TLSPolicy, port 587 will be used for the connection.
-// If the connection fails with TLSOpportunistic, the Client will attempt to connect on port 25 using
-// an unencrypted connection as a fallback. If NoTLS is provided, the Client will always use port 25.
+// In dry-run mode, the Client never connects to the SMTP server. Dialing, closing and resetting
+// succeed without any network activity, while sending a Msg takes the same path as a real
+// delivery: the sender and recipients are determined, the Message-ID generator is applied, the
+// middlewares are executed and the Msg is fully rendered. The rendered output is discarded and any
+// error is returned as SendError just like for a real delivery. Since the Msg is not actually
+// delivered, it is not flagged as delivered. This is useful for validating messages in tests or
+// CI without the need for an SMTP server.
 //
-// Note: If a different port has already been set using WithPort, that port takes precedence and is used
-// to establish the SSL/TLS connection, skipping the automatic fallback mechanism.
+// Returns:
+//   - An Option function that enables the dry-run mode for the Client.
+func WithDryRun() Option {
+	return func(c *Client) error {
+		c.dryRun = true
+		return nil
+	}
+}
+
+// WithDryRunOutput enables the dry-run mode of the Client and writes the rendered messages to
+// the provided io.Writer.
+//
+// This works like WithDryRun, but instead of discarding the rendered messages, they are written
+// to the given io.Writer in the order they are sent, allowing to inspect the output.
 //
 // Parameters:
-//   - policy: The TLSPolicy to be set for the Client.
-func (c *Client) SetTLSPortPolicy(policy TLSPolicy) {
-	if c.port == DefaultPort {
-		c.port = DefaultPortTLS
-
-		if policy == TLSOpportunistic {
-			c.fallbackPort = DefaultPort
-		}
-		if policy == NoTLS {
-			c.port = DefaultPort
-		}
+//   - output: The io.Writer the rendered messages are written to.
+//
+// Returns:
+//   - An Option function that enables the dry-run mode with the given output for the Client.
+func WithDryRunOutput(output io.Writer) Option {
+	return func(c *Client) error {
+		c.dryRun = true
+		c.dryRunOutput = output
+		return nil
 	}
-
-	c.tlspolicy = policy
 }
 
-// SetSSL sets or overrides whether the Client should use implicit SSL/TLS.
+// WithMessageIDGenerator sets a custom generator for the Message-ID of the messages sent by the Client.
 //
-// This method configures the Client to either enable or disable implicit SSL/TLS for secure communication.
+// The generator is invoked for every message that is sent without an explicitly set "Message-ID" header.
+// The generated value is wrapped in angle brackets, unless it is already enclosed in them. Messages that
+// already have a Message-ID are not altered. If no generator is set, the default Message-ID generation of
+// the Msg is used.
 //
 // Parameters:
-//   - ssl: A boolean value indicating whether to enable (true) or disable (false) implicit SSL/TLS.
-func (c *Client) SetSSL(ssl bool) {
-	c.useSSL = ssl
+//   - generator: A function returning the Message-ID to use for a message.
+//
+// Returns:
+//   - An Option function that sets the custom Message-ID generator for the Client.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func WithMessageIDGenerator(generator func() string) Option {
+	return func(c *Client) error {
+		c.messageIDGenerator = generator
+		return nil
+	}
 }
 
-// SetSSLPort sets or overrides whether the Client should use implicit SSL/TLS with optional fallback.
-// The correct port is automatically set.
+// WithAutoEncoding enables the automatic selection of the transfer encoding for the body parts of a Msg,
+// based on the capabilities of the SMTP server.
 //
-// If ssl is set to true, the default port 25 will be overridden with port 465. If fallback is set to true
-// and the SSL/TLS connection fails, the Client will attempt to connect on port 25 using an unencrypted
-// connection.
+// With this option, the Client checks whether the server advertises the 8BITMIME extension before each
+// Msg is sent. If it does, all body parts that are set to the quoted-printable encoding or to EncodingAuto
+// are sent with the 8bit encoding instead, which saves size and keeps the content readable on the wire.
+// Body parts whose content is not suitable for the 8bit encoding, e.g. because of lines longer than 998
+// octets, as well as all body parts sent to servers without 8BITMIME support keep their encoding. The
+// Content-Transfer-Encoding header of each body part always matches the encoding that is
+// used. Attachments, embeds and body parts with any other encoding are not affected, and the Msg
+// itself is not modified.
 //
-// Note: If a different port has already been set using WithPort, that port takes precedence and is used
-// to establish the SSL/TLS connection, skipping the automatic fallback mechanism.
+// Returns:
+//   - An Option function that enables the automatic selection of the transfer encoding.
 //
-// Parameters:
-//   - ssl: A boolean value indicating whether to enable implicit SSL/TLS.
-//   - fallback: A boolean value indicating whether to enable fallback to an unencrypted connection.
-func (c *Client) SetSSLPort(ssl bool, fallback bool) {
-	if c.port == DefaultPort {
-		if ssl {
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6152
+func WithAutoEncoding() Option {
+	return func(c *Client) error {
+		c.autoEncoding = true
+		return nil
+	}
+}
+
+// WithChunking enables the transfer of the message content with the BDAT command of the CHUNKING extension.
+//
+// With this option, the Client sends the message content in chunks with the BDAT command instead of the
+// DATA command, if the server advertises the CHUNKING extension. Each chunk is prefixed with its size, so
+// the content does not need to be dot-stuffed and does not have to be scanned for the end-of-data marker
+// by the server, which can be more efficient for large messages. If the server does not advertise the
+// CHUNKING extension, the Client falls back to the DATA command.
+//
+// Returns:
+//   - An Option function that enables the transfer with the BDAT command.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3030
+func WithChunking() Option {
+	return func(c *Client) error {
+		c.chunking = true
+		return nil
+	}
+}
+
+// WithSizeCheck enables the check of the message size against the SIZE limit advertised by the server.
+//
+// With this option, the Client renders each Msg before the SMTP transaction is started and compares its size
+// with the maximum message size the server advertises with the SIZE extension. The rendered Msg is held in
+// memory and sent as is, so that it is only rendered once. If the Msg exceeds the limit, it is not sent and a
+// SendError with the ErrMessageTooLarge reason is returned, so that the bandwidth for the upload of an
+// oversized message is not wasted. In addition, the size of the Msg is declared with the SIZE parameter of the
+// MAIL FROM command, so that the server can reject it early. If the server does not advertise the SIZE
+// extension or no limit, only the limit set via WithMaxMessageSize applies.
+//
+// Returns:
+//   - An Option function that enables the message size check.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc1870
+func WithSizeCheck() Option {
+	return func(c *Client) error {
+		c.sizeCheck = true
+		return nil
+	}
+}
+
+// WithMaxMessageSize sets the maximum size of a message the Client sends, independent of the server.
+//
+// With this option, the Client renders each Msg into memory before the SMTP transaction is started and sends
+// the rendered Msg as is, so that it is only rendered once. If the size of the rendered Msg exceeds the
+// provided limit, it is not sent and a SendError with the ErrMessageTooLarge reason is returned. If
+// WithSizeCheck is set as well, the lower of both limits applies.
+//
+// Parameters:
+//   - size: The maximum message size in bytes. Must be greater than zero.
+//
+// Returns:
+//   - An Option function that sets the maximum message size for the Client.
+//   - An error if the provided size is zero or negative.
+func WithMaxMessageSize(size int64) Option {
+	return func(c *Client) error {
+		if size <= 0 {
+			return ErrInvalidMaxMessageSize
+		}
+		c.maxMessageSize = size
+		return nil
+	}
+}
+
+// WithRateLimit limits the number of messages the Client sends per second.
+//
+// The limit is enforced by a RateLimiter, which is shared by all concurrent senders of the Client.
+// Before each Msg is sent, the Client waits until the RateLimiter permits it. Since a sender holds
+// the session of the Client while it sends its messages, concurrent senders of the same Client are
+// blocked while it waits for the RateLimiter. The waiting can be canceled via the context.Context of
+// SendWithContext or DialAndSendWithContext, in which case the Msg is not sent and a SendError with the
+// reason ErrRateLimit is returned. The RateLimiter can be retrieved via Client.RateLimiter to adjust the
+// limit at runtime.
+//
+// Parameters:
+//   - perSecond: The maximum number of messages to send per second. Must be greater than zero.
+//
+// Returns:
+//   - An Option function that sets the rate limit for the Client.
+//   - An error if the provided limit is zero or negative.
+func WithRateLimit(perSecond int) Option {
+	return func(c *Client) error {
+		limiter, err := NewRateLimiter(perSecond)
+		if err != nil {
+			return err
+		}
+		c.rateLimiter = limiter
+		return nil
+	}
+}
+
+// WithReconnect enables the automatic reconnection of a Client that is reused for multiple sends.
+//
+// A long-lived Client that is dialed once via Dial or DialWithContext and then used for many calls of Send
+// may lose its connection, e.g. if the server closes idle connections. With this option, the Client dials
+// the server again if the connection check at the beginning of Send, SendWithContext or SendWithResults
+// fails, and if a Msg could not be sent because the connection broke, before the next Msg is sent. The
+// Msg that failed is not sent again, unless retrying is enabled via WithRetry.
+//
+// Returns:
+//   - An Option function that enables the automatic reconnection of the Client.
+func WithReconnect() Option {
+	return func(c *Client) error {
+		c.reconnect = true
+		return nil
+	}
+}
+
+// WithRetry enables retrying the delivery of a Msg that failed with a temporary error.
+//
+// If the delivery of a Msg fails with a temporary error, i.e. a 4xx SMTP reply or a network error, the
+// Client closes the connection, waits for the delay returned by the backoff function, dials a new
+// connection to the server and attempts the delivery once more, until it succeeds or maxAttempts is
+// reached. Permanent errors, i.e. 5xx SMTP replies, and local errors like a failed validation are never
+// retried. Only the recipients that failed with a temporary error are retried, so that no recipient that
+// already accepted the Msg receives it twice. For the same reason, a network error after the message
+// content has been transferred completely is not retried, since the server might already have accepted
+// the Msg. Since Send aborts the delivery of a Msg if any of its recipients is rejected, a Msg with a
+// permanently rejected recipient is not retried by Send. The final outcome for each recipient is
+// reported by the SendResult values of Client.SendWithResults and by the SendError stored in the Msg.
+//
+// Parameters:
+//   - maxAttempts: The maximum number of attempts to send a Msg, including the first one. Must be
+//     greater than zero.
+//   - backoff: A function that returns the delay before the next attempt, after the given attempt
+//     (starting at 1) has failed. If nil, the next attempt is started immediately.
+//
+// Returns:
+//   - An Option function that enables the retrying of failed messages for the Client.
+//   - An error if the provided maximum number of attempts is zero or negative.
+func WithRetry(maxAttempts int, backoff func(attempt int) time.Duration) Option {
+	return func(c *Client) error {
+		if maxAttempts <= 0 {
+			return ErrInvalidRetryAttempts
+		}
+		c.retryAttempts = maxAttempts
+		c.retryBackoff = backoff
+		return nil
+	}
+}
+
+// WithUserAgentVersion sets the default "User-Agent" and "X-Mailer" headers of the messages the Client sends.
+//
+// With this option, the Client brands each Msg that has neither a "User-Agent" nor an "X-Mailer" header with
+// the consistent string "product version" before it is sent, instead of the go-mail default. Headers that
+// are set on the Msg via Msg.SetUserAgent or Msg.SetUserAgentVersion take precedence over this default, and
+// a Msg created with WithNoDefaultUserAgent or WithNoDefaultXMailer omits the default headers accordingly.
+//
+// Parameters:
+//   - product: The name of the sending application. Must not be empty.
+//   - version: The version of the sending application. If empty, only the product is used.
+//
+// Returns:
+//   - An Option function that sets the default User-Agent for the Client.
+//   - An error if the provided product is empty.
+func WithUserAgentVersion(product, version string) Option {
+	return func(c *Client) error {
+		if strings.TrimSpace(product) == "" {
+			return ErrInvalidUserAgent
+		}
+		c.userAgent = userAgentString(product, version)
+		return nil
+	}
+}
+
+// WithValidation enables the validation of each Msg before it is sent.
+//
+// With this option, the Client calls Msg.Validate for each Msg before any SMTP command is issued for
+// it. If the validation fails, the Msg is not sent and a SendError with the reason ErrMsgValidation is
+// returned that lists all problems found. This way, configuration mistakes like malformed addresses or
+// missing recipients are reported early and completely instead of surfacing one at a time during the
+// SMTP transaction.
+//
+// Returns:
+//   - An Option function that enables the validation of each Msg before it is sent.
+func WithValidation() Option {
+	return func(c *Client) error {
+		c.validation = true
+		return nil
+	}
+}
+
+// WithAttachmentLimits limits the number and the total size of the attachments of each Msg the Client sends.
+//
+// With this option, the Client calls Msg.ValidateAttachmentLimits for each Msg before any SMTP command is
+// issued for it. If the Msg has more attachments than permitted or the total size of its attachments after
+// the transfer encoding exceeds the limit, the Msg is not sent and a SendError with the reason
+// ErrMsgValidation is returned that lists the exceeded limits. This way, the attachment policy of a mail
+// provider can be enforced before the server rejects the Msg.
+//
+// Parameters:
+//   - maxCount: The maximum number of attachments. Zero means that the number is not limited.
+//   - maxTotalBytes: The maximum total size of all attachments in bytes after the transfer encoding.
+//     Zero means that the size is not limited.
+//
+// Returns:
+//   - An Option function that sets the attachment limits for the Client.
+//   - An error if one of the limits is negative or if both limits are zero.
+func WithAttachmentLimits(maxCount int, maxTotalBytes int64) Option {
+	return func(c *Client) error {
+		if maxCount < 0 || maxTotalBytes < 0 || (maxCount == 0 && maxTotalBytes == 0) {
+			return ErrInvalidAttachmentLimits
+		}
+		c.attachmentMaxCount = maxCount
+		c.attachmentMaxSize = maxTotalBytes
+		return nil
+	}
+}
+
+// WithRecipientFilter sets a filter that decides to which recipients the Client sends a Msg.
+//
+// The filter is called for each envelope recipient of each Msg before the RCPT TO commands are issued. A
+// recipient for which the filter returns false is dropped, i.e. no RCPT TO command is issued for it, and
+// it is reported as skipped in the SendResult values of Client.SendWithResults. The "From" address and
+// the content of the Msg, including its "To" and "Cc" headers, are not modified. If all recipients of a
+// Msg are dropped, the Msg is not sent at all. This can be used as a guardrail in staging environments to
+// prevent accidentally sending mails to real customers.
+//
+// Parameters:
+//   - filter: The function that returns true if the Msg is sent to the given recipient address.
+//
+// Returns:
+//   - An Option function that sets the recipient filter for the Client.
+//   - An error if the filter is nil.
+func WithRecipientFilter(filter func(addr string) (allow bool)) Option {
+	return func(c *Client) error {
+		if filter == nil {
+			return ErrInvalidRecipientFilter
+		}
+		c.rcptFilter = filter
+		return nil
+	}
+}
+
+// WithAllowedDomains restricts the recipients the Client sends a Msg to to the given domains.
+//
+// This is a convenience option for WithRecipientFilter. A recipient is allowed if the domain part of its
+// address matches one of the given domains, which is compared case-insensitively. Subdomains of the
+// given domains are not allowed implicitly. All other recipients are dropped and reported as skipped.
+//
+// Parameters:
+//   - domains: The domains of the recipients the Client sends a Msg to, e.g. "example.com".
+//
+// Returns:
+//   - An Option function that sets the recipient filter for the Client.
+//   - An error if no domain or an empty domain is provided.
+func WithAllowedDomains(domains ...string) Option {
+	return func(c *Client) error {
+		if len(domains) == 0 {
+			return ErrInvalidRecipientFilter
+		}
+		allowed := make(map[string]struct{}, len(domains))
+		for _, domain := range domains {
+			domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+			if domain == "" {
+				return fmt.Errorf("%w: empty domain", ErrInvalidRecipientFilter)
+			}
+			allowed[domain] = struct{}{}
+		}
+		c.rcptFilter = func(addr string) bool {
+			separator := strings.LastIndex(addr, "@")
+			if separator == -1 {
+				return false
+			}
+			_, ok := allowed[strings.ToLower(addr[separator+1:])]
+			return ok
+		}
+		return nil
+	}
+}
+
+// WithBeforeSendHook sets a function that is called before each Msg is sent by the Client.
+//
+// The hook is called for every Msg passed to Send, SendWithContext or SendWithResults, which are also used by the
+// DialAndSend methods, before the Msg is validated and rendered, so that e.g. the start of the delivery can be
+// recorded for metrics or auditing. Together with WithAfterSendHook, this allows to instrument the Client without
+// wrapping it. The hook is called while the Client sends the messages, so it must not call any methods of the
+// Client that send messages or close the connection.
+//
+// Parameters:
+//   - hook: The function that is called with the Msg that is about to be sent.
+//
+// Returns:
+//   - An Option function that sets the before send hook for the Client.
+//   - An error if the hook is nil.
+func WithBeforeSendHook(hook func(*Msg)) Option {
+	return func(c *Client) error {
+		if hook == nil {
+			return ErrInvalidSendHook
+		}
+		c.beforeSendHook = hook
+		return nil
+	}
+}
+
+// WithAfterSendHook sets a function that is called after each Msg has been sent by the Client.
+//
+// The hook is called for every Msg passed to Send, SendWithContext or SendWithResults, which are also used by the
+// DialAndSend methods, once the delivery of the Msg has finished, including all retries. It receives the outcome of
+// the delivery, i.e. the SendError that is also stored in the Msg or nil if the Msg has been accepted, and the time
+// it took to send the Msg. The hook is also called with the error of the connection check if the messages could not
+// be sent at all. Since the Msg has already been transmitted, changes made to the Msg by the hook do not affect the
+// sent message. The hook is called while the Client sends the messages, so it must not call any methods of the
+// Client that send messages or close the connection.
+//
+// Parameters:
+//   - hook: The function that is called with the sent Msg, the delivery error and the send duration.
+//
+// Returns:
+//   - An Option function that sets the after send hook for the Client.
+//   - An error if the hook is nil.
+func WithAfterSendHook(hook func(*Msg, error, time.Duration)) Option {
+	return func(c *Client) error {
+		if hook == nil {
+			return ErrInvalidSendHook
+		}
+		c.afterSendHook = hook
+		return nil
+	}
+}
+
+// WithVERP enables Variable Envelope Return Paths (VERP) for the Client.
+//
+// With VERP, the envelope sender address of a Msg encodes the recipient it is sent to, so that a bounce
+// can be attributed to the failed recipient by its return path alone. The provided function is called
+// with the envelope address of each recipient and returns the envelope sender address to use for it,
+// e.g. "bounces+user=example.com@sender.tld" for the recipient "user@example.com".
+//
+// Since the envelope sender is set per SMTP transaction, a Msg with VERP enabled is sent in a separate
+// transaction for each of its recipients. Therefore a Msg might be delivered to some of its recipients
+// even if Send returns an error for others. The envelope sender used for each recipient is reported in
+// the SendResult values returned by Client.SendWithResults. A Msg with the null reverse-path as envelope
+// sender, see Msg.SetEnvelopeFrom, is sent without VERP, since it must not cause any bounces.
+//
+// Parameters:
+//   - verp: A function that returns the envelope sender address for the given recipient address.
+//
+// Returns:
+//   - An Option function that enables VERP for the Client.
+//   - An error if the provided function is nil.
+//
+// References:
+//   - https://cr.yp.to/proto/verp.txt
+func WithVERP(verp func(recipient string) string) Option {
+	return func(c *Client) error {
+		if verp == nil {
+			return ErrInvalidVERPFunc
+		}
+		c.verp = verp
+		return nil
+	}
+}
+
+// TLSPolicy returns the TLSPolicy that is currently set on the Client as a string.
+//
+// This method retrieves the current TLSPolicy configured for the Client and returns it as a string representation.
+//
+// Returns:
+//   - A string representing the currently set TLSPolicy for the Client.
+func (c *Client) TLSPolicy() string {
+	return c.tlspolicy.String()
+}
+
+// RateLimiter returns the RateLimiter of the Client that has been set via WithRateLimit.
+//
+// The returned RateLimiter can be used to adjust the rate limit at runtime via RateLimiter.SetLimit.
+//
+// Returns:
+//   - A pointer to the RateLimiter of the Client, or nil if no rate limit is set.
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.rateLimiter
+}
+
+// LastTLSState returns the TLS connection state of the connection that was used for the last message sent
+// by the Client.
+//
+// The connection state reflects the TLS handshake that was actually negotiated with the SMTP server, either
+// via STARTTLS or via implicit TLS, and holds e.g. the TLS version and the cipher suite. This allows to record
+// the encryption in transit of each delivery, e.g. in an audit trail. The connection state is recorded once
+// the transaction of a message is started and is kept after the connection has been closed. For a dry run,
+// no connection state is recorded.
+//
+// Returns:
+//   - The TLS connection state of the connection used for the last message sent.
+//   - A boolean indicating whether the last message was sent through a TLS connection. It is false if no
+//     message has been sent yet or if the connection was not encrypted.
+func (c *Client) LastTLSState() (tls.ConnectionState, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.lastTLSState == nil {
+		return tls.ConnectionState{}, false
+	}
+	return *c.lastTLSState, true
+}
+
+// SMTPClient returns the low-level smtp.Client of the current connection to the SMTP server.
+//
+// The smtp.Client is the client the Client is built on. Once the Client is connected via DialWithContext,
+// it has already performed the EHLO, STARTTLS and SMTP AUTH exchange, so the smtp.Client can be used to
+// script arbitrary command sequences, like VRFY, EXPN or NOOP, or the commands of an ESMTP extension that
+// the Client does not model, via smtp.Client.Command. The smtp.Client is not synchronized with the Client.
+// It must not be used while the Client sends messages, and commands that change the state of the SMTP
+// session, like an incomplete mail transaction or QUIT, may break subsequent sends of the Client.
+//
+// Returns:
+//   - A pointer to the smtp.Client of the current connection.
+//   - An error if the Client is not connected to the SMTP server (ErrNoActiveConnection).
+func (c *Client) SMTPClient() (*smtp.Client, error) {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+	if c.smtpClient == nil || !c.smtpClient.HasConnection() {
+		return nil, ErrNoActiveConnection
+	}
+	return c.smtpClient, nil
+}
+
+// ServerAddr returns the server address that is currently set on the Client in the format "host:port".
+//
+// This method constructs and returns the server address using the host and port currently configured
+// for the Client.
+//
+// Returns:
+//   - A string representing the server address in the format "host:port".
+func (c *Client) ServerAddr() string {
+	return fmt.Sprintf("%s:%d", c.host, c.port)
+}
+
+// SetTLSPolicy sets or overrides the TLSPolicy currently configured on the Client with the given TLSPolicy.
+//
+// This method allows the user to set a new TLSPolicy for the Client. For best practices regarding
+// SMTP TLS connections, it is recommended to use SetTLSPortPolicy instead.
+//
+// Parameters:
+//   - policy: The TLSPolicy to be set for the Client.
+func (c *Client) SetTLSPolicy(policy TLSPolicy) {
+	c.tlspolicy = policy
+	c.explicitTLS = policy != NoTLS
+}
+
+// SetTLSPortPolicy sets or overrides the TLSPolicy currently configured on the Client with the given TLSPolicy.
+// The correct port is automatically set based on the specified policy.
+//
+// If TLSMandatory or TLSOpportunistic is provided as the TLSPolicy, port 587 will be used for the connection.
+// If the connection fails with TLSOpportunistic, the Client will attempt to connect on port 25 using
+// an unencrypted connection as a fallback. If NoTLS is provided, the Client will always use port 25.
+//
+// Note: If a different port has already been set using WithPort, that port takes precedence and is used
+// to establish the SSL/TLS connection, skipping the automatic fallback mechanism.
+//
+// Parameters:
+//   - policy: The TLSPolicy to be set for the Client.
+func (c *Client) SetTLSPortPolicy(policy TLSPolicy) {
+	if c.port == DefaultPort {
+		c.port = DefaultPortTLS
+
+		if policy == TLSOpportunistic {
+			c.fallbackPort = DefaultPort
+		}
+		if policy == NoTLS {
+			c.port = DefaultPort
+		}
+	}
+
+	c.tlspolicy = policy
+	c.explicitTLS = policy != NoTLS
+}
+
+// SetSSL sets or overrides whether the Client should use implicit SSL/TLS.
+//
+// This method configures the Client to either enable or disable implicit SSL/TLS for secure communication.
+//
+// Parameters:
+//   - ssl: A boolean value indicating whether to enable (true) or disable (false) implicit SSL/TLS.
+func (c *Client) SetSSL(ssl bool) {
+	c.useSSL = ssl
+}
+
+// SetSSLPort sets or overrides whether the Client should use implicit SSL/TLS with optional fallback.
+// The correct port is automatically set.
+//
+// If ssl is set to true, the default port 25 will be overridden with port 465. If fallback is set to true
+// and the SSL/TLS connection fails, the Client will attempt to connect on port 25 using an unencrypted
+// connection.
+//
+// Note: If a different port has already been set using WithPort, that port takes precedence and is used
+// to establish the SSL/TLS connection, skipping the automatic fallback mechanism.
+//
+// Parameters:
+//   - ssl: A boolean value indicating whether to enable implicit SSL/TLS.
+//   - fallback: A boolean value indicating whether to enable fallback to an unencrypted connection.
+func (c *Client) SetSSLPort(ssl bool, fallback bool) {
+	if c.port == DefaultPort {
+		if ssl {
 			c.port = DefaultPortSSL
 		}
-
-		c.fallbackPort = 0
-		if fallback {
-			c.fallbackPort = DefaultPort
+
+		c.fallbackPort = 0
+		if fallback {
+			c.fallbackPort = DefaultPort
+		}
+	}
+
+	c.useSSL = ssl
+}
+
+// SetDebugLog sets or overrides whether the Client is using debug logging. The debug logger will log incoming
+// and outgoing communication between the Client and the server to os.Stderr.
+//
+// Note: The SMTP communication might include unencrypted authentication data, depending on whether you are using
+// SMTP authentication and the type of authentication mechanism. This could pose a data protection risk. Use
+// debug logging with caution.
+//
+// Parameters:
+//   - val: A boolean value indicating whether to enable (true) or disable (false) debug logging.
+func (c *Client) SetDebugLog(val bool) {
+	c.useDebugLog = val
+	if c.smtpClient != nil {
+		c.smtpClient.SetDebugLog(val)
+	}
+}
+
+// SetLogger sets or overrides the custom logger currently used by the Client. The logger must
+// satisfy the log.Logger interface and is only utilized when debug logging is enabled on the
+// Client.
+//
+// By default, log.Stdlog is used if no custom logger is provided.
+//
+// Parameters:
+//   - logger: A logger that satisfies the log.Logger interface to be set for the Client.
+func (c *Client) SetLogger(logger log.Logger) {
+	c.logger = logger
+	if c.smtpClient != nil {
+		c.smtpClient.SetLogger(logger)
+	}
+}
+
+// SetTLSConfig sets or overrides the tls.Config currently configured for the Client with the
+// given value. An error is returned if the provided tls.Config is invalid.
+//
+// This method ensures that the provided tls.Config is not nil before updating the Client's
+// TLS configuration.
+//
+// Parameters:
+//   - tlsconfig: A pointer to the tls.Config struct to be set for the Client. Must not be nil.
+//
+// Returns:
+//   - An error if the provided tls.Config is invalid or nil.
+func (c *Client) SetTLSConfig(tlsconfig *tls.Config) error {
+	if tlsconfig == nil {
+		return ErrInvalidTLSConfig
+	}
+	c.tlsconfig = tlsconfig
+	return nil
+}
+
+// SetUsername sets or overrides the username that the Client will use for SMTP authentication.
+//
+// This method updates the username used by the Client for authenticating with the SMTP server.
+//
+// Parameters:
+//   - username: The username to be set for SMTP authentication.
+func (c *Client) SetUsername(username string) {
+	c.user = username
+}
+
+// SetPassword sets or overrides the password that the Client will use for SMTP authentication.
+//
+// This method updates the password used by the Client for authenticating with the SMTP server.
+//
+// Parameters:
+//   - password: The password to be set for SMTP authentication.
+func (c *Client) SetPassword(password string) {
+	c.pass = password
+}
+
+// SetSMTPAuth sets or overrides the SMTPAuthType currently configured on the Client for SMTP
+// authentication.
+//
+// This method updates the authentication type used by the Client for authenticating with the
+// SMTP server and resets any custom SMTP authentication mechanism.
+//
+// Parameters:
+//   - authtype: The SMTPAuthType to be set for the Client.
+func (c *Client) SetSMTPAuth(authtype SMTPAuthType) {
+	c.smtpAuthType = authtype
+	c.smtpAuthList = nil
+	c.smtpAuth = nil
+}
+
+// SetSMTPAuthList sets or overrides the prioritized list of SMTPAuthType values that the Client
+// negotiates against the SMTP AUTH mechanisms advertised by the server.
+//
+// This method resets any previously configured SMTP authentication mechanism. An empty list
+// disables the negotiation.
+//
+// Parameters:
+//   - authtypes: The SMTPAuthType values to negotiate, in order of preference.
+func (c *Client) SetSMTPAuthList(authtypes ...SMTPAuthType) {
+	c.smtpAuthList = authtypes
+	c.smtpAuth = nil
+	if len(authtypes) > 0 {
+		c.smtpAuthType = authtypes[0]
+	}
+}
+
+// SetSMTPAuthCustom sets or overrides the custom SMTP authentication mechanism currently
+// configured for the Client. The provided authentication mechanism must satisfy the
+// smtp.Auth interface.
+//
+// This method updates the authentication mechanism used by the Client for authenticating
+// with the SMTP server and sets the authentication type to SMTPAuthCustom.
+//
+// Parameters:
+//   - smtpAuth: The custom SMTP authentication mechanism to be set for the Client.
+func (c *Client) SetSMTPAuthCustom(smtpAuth smtp.Auth) {
+	c.smtpAuth = smtpAuth
+	c.smtpAuthList = nil
+	c.smtpAuthType = SMTPAuthCustom
+}
+
+// SetMessageIDGenerator sets or overrides the custom generator for the Message-ID of the messages sent
+// by the Client.
+//
+// See WithMessageIDGenerator for details. Passing nil restores the default Message-ID generation.
+//
+// Parameters:
+//   - generator: A function returning the Message-ID to use for a message.
+func (c *Client) SetMessageIDGenerator(generator func() string) {
+	c.messageIDGenerator = generator
+}
+
+// Dial establishes a connection to the server with the Client's settings.
+//
+// This method is equivalent to calling DialWithContext with context.Background. Together with Send and
+// Close, it allows to reuse a single connection for many messages, e.g. in a long-lived worker, instead of
+// dialing the server for each message via DialAndSend. Between the messages, the session is reset with the
+// SMTP RSET command. Concurrent calls of Send on the same Client are serialized, so that a Client can safely
+// be shared by multiple goroutines. To reconnect automatically if the connection breaks, use WithReconnect.
+//
+// Returns:
+//   - An error if the connection to the SMTP server fails or any subsequent command fails.
+func (c *Client) Dial() error {
+	return c.DialWithContext(context.Background())
+}
+
+// DialWithContext establishes a connection to the server using the provided context.Context.
+//
+// This function adds a deadline based on the Client's timeout to the provided context.Context
+// before connecting to the server. After dialing the defined DialContextFunc and successfully
+// establishing the connection, it sends the HELO/EHLO SMTP command, followed by optional
+// STARTTLS and SMTP AUTH commands. If debug logging is enabled, it attaches the log.Logger.
+//
+// After this method is called, the Client will have an active (cancelable) connection to the
+// SMTP server.
+//
+// Parameters:
+//   - dialCtx: The context.Context used to control the connection timeout and cancellation.
+//
+// Returns:
+//   - An error if the connection to the SMTP server fails or any subsequent command fails.
+func (c *Client) DialWithContext(dialCtx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.dryRun {
+		return nil
+	}
+	// The SSL and TLS settings can be changed after NewClient via the Set* methods
+	if c.useSSL && c.explicitTLS {
+		return ErrSSLWithSTARTTLS
+	}
+
+	connectTimeout := c.connTimeout
+	if c.connectTimeout > 0 {
+		connectTimeout = c.connectTimeout
+	}
+	ctx, cancel := context.WithDeadline(dialCtx, time.Now().Add(connectTimeout))
+	defer cancel()
+
+	// The default dialer is not stored in the Client, so that later changes to the SSL settings
+	// are respected on the next dial
+	dialContextFunc := c.dialContextFunc
+	if dialContextFunc == nil {
+		netDialer := net.Dialer{}
+		dialContextFunc = netDialer.DialContext
+
+		if c.useSSL {
+			tlsDialer := tls.Dialer{NetDialer: &netDialer, Config: c.getTLSConfig()}
+			c.isEncrypted = true
+			dialContextFunc = tlsDialer.DialContext
+		}
+	}
+	connection, err := dialContextFunc(ctx, "tcp", c.ServerAddr())
+	if err != nil && c.fallbackPort != 0 {
+		// TODO: should we somehow log or append the previous error?
+		connection, err = dialContextFunc(ctx, "tcp", c.serverFallbackAddr())
+	}
+	if err != nil {
+		return err
+	}
+	c.connection = connection
+
+	// The dial context only covers the dialing itself, so each phase of the initial SMTP
+	// conversation gets its own deadline, while the context can still abort it at any time
+	stopWatch := c.watchContext(dialCtx)
+	defer stopWatch()
+	if err = connection.SetDeadline(time.Now().Add(connectTimeout)); err != nil {
+		return c.contextError(dialCtx, err)
+	}
+
+	client, err := smtp.NewClient(connection, c.host)
+	if err != nil {
+		return c.contextError(dialCtx, err)
+	}
+	if client == nil {
+		return fmt.Errorf("SMTP client is nil")
+	}
+	c.smtpClient = client
+
+	if c.logger != nil {
+		c.smtpClient.SetLogger(c.logger)
+	}
+	if c.useDebugLog {
+		c.smtpClient.SetDebugLog(true)
+	}
+	if err = c.smtpClient.Hello(c.helo); err != nil {
+		return c.contextError(dialCtx, err)
+	}
+
+	if err = c.smtpClient.UpdateDeadline(connectTimeout); err != nil {
+		return ErrDeadlineExtendFailed
+	}
+	if err = c.tls(); err != nil {
+		return c.contextError(dialCtx, err)
+	}
+
+	if err = c.smtpClient.UpdateDeadline(c.connTimeout); err != nil {
+		return ErrDeadlineExtendFailed
+	}
+	c.smtpClient.SetCommandTimeout(c.commandTimeout)
+	if err = c.auth(dialCtx); err != nil {
+		return c.contextError(dialCtx, err)
+	}
+
+	return nil
+}
+
+// Close terminates the connection to the SMTP server, returning an error if the disconnection
+// fails. If the connection is already closed, this method is a no-op and disregards any error.
+//
+// This function checks if the Client's SMTP connection is active. If not, it simply returns
+// without any action. If the connection is active, it attempts to gracefully close the
+// connection using the Quit method.
+//
+// Returns:
+//   - An error if the disconnection fails; otherwise, returns nil.
+func (c *Client) Close() error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+	return c.close()
+}
+
+// close terminates the connection to the SMTP server like Close, but without synchronizing the access to
+// the connection. The caller must hold the sessionMutex or otherwise ensure exclusive access.
+//
+// Returns:
+//   - An error if the disconnection fails; otherwise, returns nil.
+func (c *Client) close() error {
+	if c.dryRun || !c.smtpClient.HasConnection() {
+		return nil
+	}
+	if err := c.smtpClient.Quit(); err != nil {
+		return fmt.Errorf("failed to close SMTP client: %w", err)
+	}
+
+	return nil
+}
+
+// Reset sends an SMTP RSET command to reset the state of the current SMTP session.
+//
+// This method checks the connection to the SMTP server and, if the connection is valid,
+// it sends an RSET command to reset the session state. If the connection is invalid or
+// the command fails, an error is returned.
+//
+// Returns:
+//   - An error if the connection check fails or if sending the RSET command fails; otherwise, returns nil.
+func (c *Client) Reset() error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+	return c.reset()
+}
+
+// reset sends an SMTP RSET command like Reset, but without synchronizing the access to the connection. The
+// caller must hold the sessionMutex.
+//
+// Returns:
+//   - An error if the connection check fails or if sending the RSET command fails; otherwise, returns nil.
+func (c *Client) reset() error {
+	if c.dryRun {
+		return nil
+	}
+	if err := c.checkConn(); err != nil {
+		return err
+	}
+	if err := c.smtpClient.Reset(); err != nil {
+		return fmt.Errorf("failed to send RSET to SMTP client: %w", err)
+	}
+
+	return nil
+}
+
+// Send attempts to send one or more Msg using the Client connection to the SMTP server.
+//
+// This method is equivalent to calling SendWithContext with context.Background.
+//
+// Parameters:
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - An error that represents the sending result, which may include multiple SendErrors if
+//     any occurred; otherwise, returns nil.
+func (c *Client) Send(messages ...*Msg) error {
+	return c.SendWithContext(context.Background(), messages...)
+}
+
+// DialAndSend establishes a connection to the server and sends out the provided Msg.
+// It calls DialAndSendWithContext with an empty Context.Background.
+//
+// This method simplifies the process of connecting to the SMTP server and sending messages
+// by using a default context. It prepares the messages for sending and ensures the connection
+// is established before attempting to send them.
+//
+// Parameters:
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - An error if the connection fails or if sending the messages fails; otherwise, returns nil.
+func (c *Client) DialAndSend(messages ...*Msg) error {
+	ctx := context.Background()
+	return c.DialAndSendWithContext(ctx, messages...)
+}
+
+// DialAndSendWithContext establishes a connection to the SMTP server using DialWithContext
+// with the provided context.Context, then sends out the given Msg. After successful delivery,
+// the Client will close the connection to the server.
+//
+// This method first attempts to connect to the SMTP server using the provided context.
+// Upon successful connection, it sends the specified messages and ensures that the connection
+// is closed after the operation, regardless of success or failure in sending the messages.
+//
+// The context is honored during all phases of the SMTP conversation, i.e. dial, STARTTLS, AUTH
+// and the sending of each message including the DATA phase. Each phase is additionally bound by
+// the timeout of the Client. If the context is canceled or its deadline is exceeded, the
+// connection is closed and the returned error wraps the error of the context, so it can be
+// checked with errors.Is for context.Canceled or context.DeadlineExceeded.
+//
+// Parameters:
+//   - ctx: The context.Context to control the connection timeout and cancellation.
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - An error if the connection fails, if sending the messages fails, or if closing the
+//     connection fails; otherwise, returns nil.
+func (c *Client) DialAndSendWithContext(ctx context.Context, messages ...*Msg) error {
+	if err := c.DialWithContext(ctx); err != nil {
+		return fmt.Errorf("dial failed: %w", err)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	stopWatch := c.watchContext(ctx)
+	err := c.SendWithContext(ctx, messages...)
+	stopWatch()
+	if err != nil {
+		return fmt.Errorf("send failed: %w", c.contextError(ctx, err))
+	}
+	if err := c.Close(); err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
+	}
+	return nil
+}
+
+// SendWithResults sends out one or more Msg and returns the delivery result for each recipient.
+//
+// Unlike Send, this method does not abort the delivery of a Msg if some of its recipients are
+// rejected by the server during the RCPT TO command. Instead, the Msg is delivered to all accepted
+// recipients and a SendResult is returned for each recipient of each Msg, holding the recipient
+// specific error if the delivery to that recipient failed. This allows to log and retry only the
+// failed recipients. If a Msg could not be delivered to some or all of its recipients, the SendError
+// is also stored in the Msg and can be retrieved via Msg.SendError. Recipients that were dropped by
+// the recipient filter of the Client are reported with SendResult.Skipped set to true.
+//
+// Parameters:
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of each Msg.
+//   - An error if the connection to the server could not be verified before sending. Delivery
+//     errors are only reported via the returned SendResult values.
+func (c *Client) SendWithResults(messages ...*Msg) ([]SendResult, error) {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if err := c.checkConnWithReconnect(context.Background()); err != nil {
+		retError := &SendError{
+			Reason: ErrConnCheck, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+		}
+		c.callSendHooks(messages, retError)
+		return nil, retError
+	}
+
+	var results []SendResult
+	for _, message := range messages {
+		msgResults, err := c.sendSingleMsgWithHooks(context.Background(), message, true)
+		if err != nil {
+			message.sendError = err
+		}
+		results = append(results, msgResults...)
+	}
+	return results, nil
+}
+
+// auth attempts to authenticate the client using SMTP AUTH mechanisms. It checks the connection,
+// determines the supported authentication methods, and applies the appropriate authentication
+// type. An error is returned if authentication fails.
+//
+// This method first verifies the connection to the SMTP server. If no custom authentication
+// mechanism is provided, it checks which authentication methods are supported by the server.
+// Based on the configured SMTPAuthType, or the type negotiated from the SMTPAuthType list for the
+// current connection, it sets up the appropriate authentication mechanism. The configured SMTPAuthType
+// is not changed by the negotiation. Finally, it attempts to authenticate the client using the
+// selected method.
+//
+// Parameters:
+//   - ctx: The context.Context that is passed to the XOAUTH2 token source, if set.
+//
+// Returns:
+//   - An error if the connection check fails, if no supported authentication method is found,
+//     or if the authentication process fails.
+func (c *Client) auth(ctx context.Context) error {
+	if err := c.checkConn(); err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+	authType := c.smtpAuthType
+	if len(c.smtpAuthList) > 0 && authType != SMTPAuthCustom {
+		hasSMTPAuth, smtpAuthType := c.smtpClient.Extension("AUTH")
+		if !hasSMTPAuth {
+			return fmt.Errorf("server does not support SMTP AUTH")
+		}
+		negotiated, err := negotiateSMTPAuth(c.smtpAuthList, smtpAuthType)
+		if err != nil {
+			return err
+		}
+		if negotiated != c.smtpAuthNegotiated {
+			c.smtpAuth = nil
+		}
+		authType = negotiated
+	}
+	// A token from the token source is only valid for a limited time, so it is fetched on each connection
+	if authType == SMTPAuthXOAUTH2 && c.xoauth2TokenSource != nil {
+		c.smtpAuth = nil
+	}
+	if c.smtpAuth == nil && authType == SMTPAuthNoAuth {
+		return nil
+	}
+	if c.smtpAuth == nil && authType != SMTPAuthCustom {
+		hasSMTPAuth, smtpAuthType := c.smtpClient.Extension("AUTH")
+		if !hasSMTPAuth {
+			return fmt.Errorf("server does not support SMTP AUTH")
+		}
+
+		switch authType {
+		case SMTPAuthPlain:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthPlain)) {
+				return ErrPlainAuthNotSupported
+			}
+			user, pass, err := c.saslCredentials()
+			if err != nil {
+				return err
+			}
+			c.smtpAuth = smtp.PlainAuth("", user, pass, c.host)
+		case SMTPAuthLogin:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthLogin)) {
+				return ErrLoginAuthNotSupported
+			}
+			user, pass, err := c.saslCredentials()
+			if err != nil {
+				return err
+			}
+			c.smtpAuth = smtp.LoginAuth(user, pass, c.host)
+		case SMTPAuthCramMD5:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthCramMD5)) {
+				return ErrCramMD5AuthNotSupported
+			}
+			c.smtpAuth = smtp.CRAMMD5Auth(c.user, c.pass)
+		case SMTPAuthXOAUTH2:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthXOAUTH2)) {
+				return ErrXOauth2AuthNotSupported
+			}
+			token := c.pass
+			if c.xoauth2TokenSource != nil {
+				var err error
+				if token, err = c.xoauth2TokenSource(ctx); err != nil {
+					return fmt.Errorf("failed to get XOAUTH2 token from token source: %w", err)
+				}
+			}
+			c.smtpAuth = smtp.XOAuth2Auth(c.user, token)
+		case SMTPAuthSCRAMSHA1:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA1)) {
+				return ErrSCRAMSHA1AuthNotSupported
+			}
+			c.smtpAuth = smtp.ScramSHA1Auth(c.user, c.pass)
+		case SMTPAuthSCRAMSHA256:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA256)) {
+				return ErrSCRAMSHA256AuthNotSupported
+			}
+			c.smtpAuth = smtp.ScramSHA256Auth(c.user, c.pass)
+		case SMTPAuthSCRAMSHA1PLUS:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA1PLUS)) {
+				return ErrSCRAMSHA1PLUSAuthNotSupported
+			}
+			tlsConnState, err := c.smtpClient.GetTLSConnectionState()
+			if err != nil {
+				return err
+			}
+			c.smtpAuth = smtp.ScramSHA1PlusAuth(c.user, c.pass, tlsConnState)
+		case SMTPAuthSCRAMSHA256PLUS:
+			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA256PLUS)) {
+				return ErrSCRAMSHA256PLUSAuthNotSupported
+			}
+			tlsConnState, err := c.smtpClient.GetTLSConnectionState()
+			if err != nil {
+				return err
+			}
+			c.smtpAuth = smtp.ScramSHA256PlusAuth(c.user, c.pass, tlsConnState)
+		default:
+			return fmt.Errorf("unsupported SMTP AUTH type %q", authType)
+		}
+		c.smtpAuthNegotiated = authType
+	}
+
+	if c.smtpAuth != nil {
+		if err := c.smtpClient.Auth(c.smtpAuth); err != nil {
+			return fmt.Errorf("SMTP AUTH %s failed: %w", authType, err)
+		}
+	}
+	return nil
+}
+
+// saslCredentials returns the username and password of the Client for the PLAIN and LOGIN SMTP
+// authentication.
+//
+// If SASLprep is enabled for the Client, both credentials are prepared with smtp.SASLprep.
+// Otherwise, they are returned unchanged.
+//
+// Returns:
+//   - The username and password to authenticate with.
+//   - An error if the preparation of the credentials fails; otherwise, returns nil.
+func (c *Client) saslCredentials() (string, string, error) {
+	if !c.saslPrep {
+		return c.user, c.pass, nil
+	}
+	user, err := smtp.SASLprep(c.user)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare SMTP AUTH username: %w", err)
+	}
+	pass, err := smtp.SASLprep(c.pass)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare SMTP AUTH password: %w", err)
+	}
+	return user, pass, nil
+}
+
+// negotiateSMTPAuth returns the first SMTPAuthType of the prioritized list that is advertised by the server.
+//
+// The comparison is performed against the space separated list of mechanisms the server advertised in the
+// AUTH extension of its EHLO response and is case-insensitive.
+//
+// Parameters:
+//   - authtypes: The SMTPAuthType values to negotiate, in order of preference.
+//   - offered: The value of the AUTH extension as advertised by the server.
+//
+// Returns:
+//   - The first SMTPAuthType that is supported by the server.
+//   - An error wrapping ErrNoSupportedAuthNegotiated if none of the SMTPAuthType values is supported.
+func negotiateSMTPAuth(authtypes []SMTPAuthType, offered string) (SMTPAuthType, error) {
+	mechanisms := strings.Fields(offered)
+	for _, authtype := range authtypes {
+		for _, mechanism := range mechanisms {
+			if strings.EqualFold(mechanism, string(authtype)) {
+				return authtype, nil
+			}
+		}
+	}
+	requested := make([]string, 0, len(authtypes))
+	for _, authtype := range authtypes {
+		requested = append(requested, string(authtype))
+	}
+	return "", fmt.Errorf("%w (requested: %s, offered: %s)", ErrNoSupportedAuthNegotiated,
+		strings.Join(requested, ", "), strings.Join(mechanisms, ", "))
+}
+
+// sendSingleMsg sends out a single message and returns an error if the transmission or
+// delivery fails. It is invoked by the public Send methods.
+//
+// This method handles the process of sending a single email message through the SMTP
+// client. It performs several checks and operations, including verifying the encoding,
+// retrieving the sender and recipient addresses, and managing delivery status notifications
+// (DSN). It attempts to send the message and handles any errors that occur during the
+// transmission process, ensuring that any necessary cleanup is performed (such as resetting
+// the SMTP client if an error occurs).
+//
+// Parameters:
+//   - ctx: The context.Context to control the waiting for the rate limit.
+//   - message: A pointer to the Msg object representing the email message to be sent.
+//
+// Returns:
+//   - An error if any part of the sending process fails; otherwise, returns nil.
+func (c *Client) sendSingleMsg(ctx context.Context, message *Msg) error {
+	_, err := c.sendSingleMsgWithHooks(ctx, message, false)
+	return err
+}
+
+// sendSingleMsgWithHooks sends out a single message like sendSingleMsgWithRetry and calls the send hooks
+// of the Client before and after the message is sent.
+//
+// Parameters:
+//   - ctx: The context.Context to control the waiting for the rate limit, the backoff and the dial.
+//   - message: A pointer to the Msg object representing the email message to be sent.
+//   - allowPartial: Whether the message is sent to the accepted recipients if some are rejected.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of the message.
+//   - An error if the delivery failed for any of the recipients after the last attempt; otherwise nil.
+func (c *Client) sendSingleMsgWithHooks(ctx context.Context, message *Msg, allowPartial bool) ([]SendResult,
+	error,
+) {
+	if c.beforeSendHook != nil {
+		c.beforeSendHook(message)
+	}
+	start := time.Now()
+	results, err := c.sendSingleMsgWithRetry(ctx, message, allowPartial)
+	if c.afterSendHook != nil {
+		c.afterSendHook(message, err, time.Since(start))
+	}
+	return results, err
+}
+
+// callSendHooks calls the send hooks of the Client for messages that could not be sent at all, e.g.
+// because the connection check failed.
+//
+// Parameters:
+//   - messages: The messages that could not be sent.
+//   - err: The error that prevented the messages from being sent.
+func (c *Client) callSendHooks(messages []*Msg, err error) {
+	for _, message := range messages {
+		if c.beforeSendHook != nil {
+			c.beforeSendHook(message)
+		}
+		if c.afterSendHook != nil {
+			c.afterSendHook(message, err, 0)
 		}
 	}
-
-	c.useSSL = ssl
 }
 
-// SetDebugLog sets or overrides whether the Client is using debug logging. The debug logger will log incoming
-// and outgoing communication between the Client and the server to os.Stderr.
+// sendSingleMsgWithRetry sends out a single message and retries the recipients that failed with a
+// temporary error, if retrying is enabled via WithRetry.
 //
-// Note: The SMTP communication might include unencrypted authentication data, depending on whether you are using
-// SMTP authentication and the type of authentication mechanism. This could pose a data protection risk. Use
-// debug logging with caution.
+// Before each retry, the connection to the server is closed and dialed again, after waiting for the
+// backoff delay of the Client. The results of the recipients that are retried are replaced by the
+// results of the latest attempt, so that the returned results hold the final outcome for each recipient.
 //
 // Parameters:
-//   - val: A boolean value indicating whether to enable (true) or disable (false) debug logging.
-func (c *Client) SetDebugLog(val bool) {
-	c.useDebugLog = val
-	if c.smtpClient != nil {
-		c.smtpClient.SetDebugLog(val)
+//   - ctx: The context.Context to control the waiting for the rate limit, the backoff and the dial.
+//   - message: A pointer to the Msg object representing the email message to be sent.
+//   - allowPartial: Whether the message is sent to the accepted recipients if some are rejected.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of the message.
+//   - An error if the delivery failed for any of the recipients after the last attempt; otherwise nil.
+func (c *Client) sendSingleMsgWithRetry(ctx context.Context, message *Msg, allowPartial bool) ([]SendResult, error) {
+	results, err := c.sendSingleMsgWithResults(ctx, message, allowPartial, nil)
+	retried := false
+	for attempt := 1; attempt < c.retryAttempts && err != nil; attempt++ {
+		rcpts := retryRecipients(results, allowPartial)
+		if len(rcpts) == 0 {
+			break
+		}
+		if c.retryBackoff != nil {
+			timer := time.NewTimer(c.retryBackoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		retried = true
+		var retryResults []SendResult
+		if err = c.redial(ctx); err != nil {
+			retryErr := &SendError{
+				Reason: ErrConnCheck, errlist: []error{err}, isTemp: true, errcode: errorCode(err),
+				affectedMsg: message,
+			}
+			retryResults = newSendResults(message, rcpts, retryErr)
+			err = retryErr
+		} else {
+			retryResults, err = c.sendSingleMsgWithResults(ctx, message, allowPartial, rcpts)
+		}
+		results = mergeSendResults(results, retryResults)
+	}
+	c.reconnectAfter(ctx, err)
+	if !retried {
+		return results, err
+	}
+	if retryErr := newRetrySendError(message, results); retryErr != nil {
+		return results, retryErr
 	}
+	return results, err
 }
 
-// SetLogger sets or overrides the custom logger currently used by the Client. The logger must
-// satisfy the log.Logger interface and is only utilized when debug logging is enabled on the
-// Client.
-//
-// By default, log.Stdlog is used if no custom logger is provided.
+// redial closes the current connection to the SMTP server and establishes a new one.
 //
 // Parameters:
-//   - logger: A logger that satisfies the log.Logger interface to be set for the Client.
-func (c *Client) SetLogger(logger log.Logger) {
-	c.logger = logger
+//   - ctx: The context.Context used to control the connection timeout and cancellation.
+//
+// Returns:
+//   - An error if the new connection could not be established; otherwise, returns nil.
+func (c *Client) redial(ctx context.Context) error {
 	if c.smtpClient != nil {
-		c.smtpClient.SetLogger(logger)
+		if err := c.close(); err != nil {
+			_ = c.smtpClient.Close()
+		}
 	}
+	return c.DialWithContext(ctx)
 }
 
-// SetTLSConfig sets or overrides the tls.Config currently configured for the Client with the
-// given value. An error is returned if the provided tls.Config is invalid.
-//
-// This method ensures that the provided tls.Config is not nil before updating the Client's
-// TLS configuration.
+// checkConnWithReconnect checks the connection to the SMTP server and dials the server again, if the
+// connection is broken and the automatic reconnection is enabled via WithReconnect.
 //
 // Parameters:
-//   - tlsconfig: A pointer to the tls.Config struct to be set for the Client. Must not be nil.
+//   - ctx: The context.Context used to control the connection timeout and cancellation of the dial.
 //
 // Returns:
-//   - An error if the provided tls.Config is invalid or nil.
-func (c *Client) SetTLSConfig(tlsconfig *tls.Config) error {
-	if tlsconfig == nil {
-		return ErrInvalidTLSConfig
+//   - An error if the connection is broken and could not be established again; otherwise, returns nil.
+func (c *Client) checkConnWithReconnect(ctx context.Context) error {
+	err := c.checkConn()
+	if err == nil || !c.reconnect {
+		return err
+	}
+	if err = c.redial(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
 	}
-	c.tlsconfig = tlsconfig
 	return nil
 }
 
-// SetUsername sets or overrides the username that the Client will use for SMTP authentication.
-//
-// This method updates the username used by the Client for authenticating with the SMTP server.
+// reconnectAfter dials the server again, if the given error of a send shows that the connection to the
+// SMTP server broke and the automatic reconnection is enabled via WithReconnect. The error of the dial is
+// not returned, since it is reported by the send of the next Msg.
 //
 // Parameters:
-//   - username: The username to be set for SMTP authentication.
-func (c *Client) SetUsername(username string) {
-	c.user = username
+//   - ctx: The context.Context used to control the connection timeout and cancellation of the dial.
+//   - err: The error of the previous send.
+func (c *Client) reconnectAfter(ctx context.Context, err error) {
+	if !c.reconnect || c.dryRun || err == nil {
+		return
+	}
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) {
+		return
+	}
+	for _, cause := range sendErr.errlist {
+		if isNetworkError(cause) {
+			_ = c.redial(ctx)
+			return
+		}
+	}
 }
 
-// SetPassword sets or overrides the password that the Client will use for SMTP authentication.
+// retryRecipients returns the recipients of the given results that failed with a retryable error.
 //
-// This method updates the password used by the Client for authenticating with the SMTP server.
+// If partial deliveries are not allowed, no recipient is returned if any of the recipients failed with a
+// permanent error, since the delivery would be aborted again on the next attempt.
 //
 // Parameters:
-//   - password: The password to be set for SMTP authentication.
-func (c *Client) SetPassword(password string) {
-	c.pass = password
+//   - results: The results of the previous attempt to send a message.
+//   - allowPartial: Whether the message is sent to the accepted recipients if some are rejected.
+//
+// Returns:
+//   - The recipients to retry, or nil if the message should not be retried.
+func retryRecipients(results []SendResult, allowPartial bool) []string {
+	var rcpts []string
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if !isRetryableError(result.Err) || result.Recipient == "" {
+			if !allowPartial {
+				return nil
+			}
+			continue
+		}
+		rcpts = append(rcpts, result.Recipient)
+	}
+	return rcpts
 }
 
-// SetSMTPAuth sets or overrides the SMTPAuthType currently configured on the Client for SMTP
-// authentication.
+// isRetryableError reports whether the delivery of a message that failed with the given error can be
+// retried without the risk of a duplicate delivery.
 //
-// This method updates the authentication type used by the Client for authenticating with the
-// SMTP server and resets any custom SMTP authentication mechanism.
+// Errors caused by a 4xx SMTP reply and network errors are retryable. A network error while closing the
+// data writer is not, since the server might already have accepted the message.
 //
 // Parameters:
-//   - authtype: The SMTPAuthType to be set for the Client.
-func (c *Client) SetSMTPAuth(authtype SMTPAuthType) {
-	c.smtpAuthType = authtype
-	c.smtpAuth = nil
+//   - err: The delivery error of a recipient.
+//
+// Returns:
+//   - true if the delivery can be retried, false otherwise.
+func isRetryableError(err error) bool {
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) {
+		return false
+	}
+	switch sendErr.Reason {
+	case ErrGetSender, ErrGetRcpts, ErrNoUnencoded, ErrAmbiguous, ErrMsgValidation, ErrRateLimit,
+		ErrMessageTooLarge:
+		return false
+	}
+	if sendErr.errcode > 0 {
+		return sendErr.errcode >= 400 && sendErr.errcode < 500
+	}
+	if sendErr.Reason == ErrSMTPDataClose {
+		return false
+	}
+	if sendErr.isTemp {
+		return true
+	}
+	for _, cause := range sendErr.errlist {
+		if isNetworkError(cause) {
+			return true
+		}
+	}
+	return false
 }
 
-// SetSMTPAuthCustom sets or overrides the custom SMTP authentication mechanism currently
-// configured for the Client. The provided authentication mechanism must satisfy the
-// smtp.Auth interface.
+// isNetworkError reports whether the given error is caused by a broken or unavailable connection to the
+// SMTP server.
 //
-// This method updates the authentication mechanism used by the Client for authenticating
-// with the SMTP server and sets the authentication type to SMTPAuthCustom.
+// Parameters:
+//   - err: The error to check.
+//
+// Returns:
+//   - true if the error is a network error, false otherwise.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) || errors.Is(err, ErrNoActiveConnection) ||
+		errors.Is(err, ErrDeadlineExtendFailed)
+}
+
+// mergeSendResults replaces the results of the retried recipients with the results of the latest attempt.
 //
 // Parameters:
-//   - smtpAuth: The custom SMTP authentication mechanism to be set for the Client.
-func (c *Client) SetSMTPAuthCustom(smtpAuth smtp.Auth) {
-	c.smtpAuth = smtpAuth
-	c.smtpAuthType = SMTPAuthCustom
+//   - results: The results of all recipients of a message.
+//   - retryResults: The results of the latest attempt, which only covers the retried recipients.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient, in the order of the original results.
+func mergeSendResults(results, retryResults []SendResult) []SendResult {
+	latest := make(map[string]SendResult, len(retryResults))
+	for _, result := range retryResults {
+		latest[result.Recipient] = result
+	}
+	merged := make([]SendResult, 0, len(results))
+	for _, result := range results {
+		if retryResult, ok := latest[result.Recipient]; ok && result.Err != nil {
+			result = retryResult
+		}
+		merged = append(merged, result)
+	}
+	return merged
 }
 
-// DialWithContext establishes a connection to the server using the provided context.Context.
+// newRetrySendError returns a SendError that combines the delivery errors of all recipients that failed
+// after the last attempt to send a message.
 //
-// This function adds a deadline based on the Client's timeout to the provided context.Context
-// before connecting to the server. After dialing the defined DialContextFunc and successfully
-// establishing the connection, it sends the HELO/EHLO SMTP command, followed by optional
-// STARTTLS and SMTP AUTH commands. If debug logging is enabled, it attaches the log.Logger.
+// Parameters:
+//   - message: A pointer to the Msg the results belong to.
+//   - results: The final results of all recipients of the message.
 //
-// After this method is called, the Client will have an active (cancelable) connection to the
-// SMTP server.
+// Returns:
+//   - A SendError listing all failed recipients, or nil if the message was delivered to all recipients.
+func newRetrySendError(message *Msg, results []SendResult) *SendError {
+	var retryErr *SendError
+	seen := make(map[*SendError]bool)
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		var rcptErr *SendError
+		if !errors.As(result.Err, &rcptErr) {
+			rcptErr = &SendError{Reason: ErrAmbiguous, errlist: []error{result.Err}, affectedMsg: message}
+		}
+		if retryErr == nil {
+			retryErr = &SendError{Reason: rcptErr.Reason, affectedMsg: message}
+		}
+		if result.Recipient != "" {
+			retryErr.rcpt = append(retryErr.rcpt, result.Recipient)
+		}
+		if seen[rcptErr] {
+			continue
+		}
+		seen[rcptErr] = true
+		if rcptErr.Reason != retryErr.Reason {
+			retryErr.Reason = ErrAmbiguous
+		}
+		retryErr.errlist = append(retryErr.errlist, rcptErr.errlist...)
+		retryErr.isTemp = rcptErr.isTemp
+		retryErr.errcode = rcptErr.errcode
+		if len(rcptErr.rcptErrs) > 0 {
+			retryErr.rcptErrs = append(retryErr.rcptErrs, rcptErr.rcptErrs...)
+		} else if len(rcptErr.rcpt) == 1 {
+			retryErr.rcptErrs = append(retryErr.rcptErrs, rcptErr)
+		}
+	}
+	return retryErr
+}
+
+// sendSingleMsgWithResults sends out a single message and returns the delivery result for each
+// of its recipients.
+//
+// This method performs the same steps as sendSingleMsg. If allowPartial is true, recipients that
+// are rejected during the RCPT TO command do not abort the transmission, but the message is sent
+// to all accepted recipients and only the rejected recipients are reported as failed.
+//
+// If a rate limit is set for the Client, the method waits for the rate limit before the Msg is sent.
+// Since the caller holds the session of the Client for the whole batch, concurrent senders of the
+// same Client wait for each other, including the time spent waiting for the rate limit.
 //
 // Parameters:
-//   - dialCtx: The context.Context used to control the connection timeout and cancellation.
+//   - ctx: The context.Context to control the waiting for the rate limit.
+//   - message: A pointer to the Msg object representing the email message to be sent.
+//   - allowPartial: Whether the message is sent to the accepted recipients if some are rejected.
+//   - retryRcpts: The recipients to send the message to when it is retried, or nil to send it to all
+//     recipients of the message.
 //
 // Returns:
-//   - An error if the connection to the SMTP server fails or any subsequent command fails.
-func (c *Client) DialWithContext(dialCtx context.Context) error {
+//   - A slice of SendResult, one for each recipient of the message.
+//   - An error if any part of the sending process fails; otherwise, returns nil.
+func (c *Client) sendSingleMsgWithResults(ctx context.Context, message *Msg, allowPartial bool,
+	retryRcpts []string,
+) ([]SendResult, error) {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			retError := &SendError{Reason: ErrRateLimit, errlist: []error{err}, isTemp: true, affectedMsg: message}
+			rcpts, _ := message.GetRecipients()
+			return newSendResults(message, rcpts, retError), retError
+		}
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	ctx, cancel := context.WithDeadline(dialCtx, time.Now().Add(c.connTimeout))
-	defer cancel()
-
-	if c.dialContextFunc == nil {
-		netDialer := net.Dialer{}
-		c.dialContextFunc = netDialer.DialContext
-
-		if c.useSSL {
-			tlsDialer := tls.Dialer{NetDialer: &netDialer, Config: c.tlsconfig}
-			c.isEncrypted = true
-			c.dialContextFunc = tlsDialer.DialContext
-		}
+	if c.messageIDGenerator != nil && message.GetMessageID() == "" {
+		message.SetMessageIDWithValue(c.messageIDGenerator())
 	}
-	connection, err := c.dialContextFunc(ctx, "tcp", c.ServerAddr())
-	if err != nil && c.fallbackPort != 0 {
-		// TODO: should we somehow log or append the previous error?
-		connection, err = c.dialContextFunc(ctx, "tcp", c.serverFallbackAddr())
+	if c.userAgent != "" {
+		message.setDefaultUserAgent(c.userAgent)
+	}
+	rcpts, rcptErr := message.GetRecipients()
+	if retryRcpts != nil {
+		rcpts = retryRcpts
 	}
+	// The content of attachments that are produced lazily is produced once per attempt, so that all
+	// following checks and the transmission use the same content
+	content, err := message.withProducedFiles()
 	if err != nil {
-		return err
+		retError := &SendError{
+			Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+			affectedMsg: message,
+		}
+		return newSendResults(message, rcpts, retError), retError
+	}
+	if c.validation {
+		if err := content.Validate(); err != nil {
+			retError := &SendError{Reason: ErrMsgValidation, errlist: []error{err}, affectedMsg: message}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				retError.errlist = validationErr.Errors()
+			}
+			return newSendResults(message, rcpts, retError), retError
+		}
 	}
-
-	client, err := smtp.NewClient(connection, c.host)
+	if c.attachmentMaxCount > 0 || c.attachmentMaxSize > 0 {
+		if err := content.ValidateAttachmentLimits(c.attachmentMaxCount, c.attachmentMaxSize); err != nil {
+			retError := &SendError{Reason: ErrMsgValidation, errlist: []error{err}, affectedMsg: message}
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				retError.errlist = validationErr.Errors()
+			}
+			return newSendResults(message, rcpts, retError), retError
+		}
+	}
+	if message.encoding == NoEncoding && !c.dryRun {
+		if ok, _ := c.smtpClient.Extension("8BITMIME"); !ok {
+			retError := &SendError{Reason: ErrNoUnencoded, isTemp: false, affectedMsg: message}
+			return newSendResults(message, rcpts, retError), retError
+		}
+	}
+	from, err := message.GetSender(false)
 	if err != nil {
-		return err
+		retError := &SendError{
+			Reason: ErrGetSender, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+			affectedMsg: message,
+		}
+		return newSendResults(message, rcpts, retError), retError
 	}
-	if client == nil {
-		return fmt.Errorf("SMTP client is nil")
+	if rcptErr != nil {
+		retError := &SendError{
+			Reason: ErrGetRcpts, errlist: []error{rcptErr}, isTemp: isTempError(rcptErr),
+			errcode: errorCode(rcptErr), affectedMsg: message,
+		}
+		return newSendResults(message, nil, retError), retError
+	}
+	rcpts, skipped := c.filterRecipients(message, rcpts)
+	if len(rcpts) == 0 {
+		return skipped, nil
+	}
+	if c.dryRun {
+		results, err := c.sendDryRun(message, content, rcpts)
+		for i := range results {
+			results[i].EnvelopeFrom = from
+			if c.verp != nil && from != "" {
+				results[i].EnvelopeFrom = c.verp(results[i].Recipient)
+			}
+		}
+		return append(skipped, results...), err
 	}
-	c.smtpClient = client
 
-	if c.logger != nil {
-		c.smtpClient.SetLogger(c.logger)
+	c.lastTLSState = nil
+	if tlsConnState, err := c.smtpClient.GetTLSConnectionState(); err == nil {
+		c.lastTLSState = tlsConnState
 	}
-	if c.useDebugLog {
-		c.smtpClient.SetDebugLog(true)
+
+	if c.requestDSN {
+		if c.dsnReturnType != "" {
+			c.smtpClient.SetDSNMailReturnOption(string(c.dsnReturnType))
+		}
 	}
-	if err = c.smtpClient.Hello(c.helo); err != nil {
-		return err
+	rcptNotifyOpt := strings.Join(c.dsnRcptNotifyType, ",")
+	c.smtpClient.SetDSNRcptNotifyOption(rcptNotifyOpt)
+	mailParams := c.mailParams
+	if c.requireTLS {
+		if err = c.checkRequireTLS(); err != nil {
+			retError := &SendError{Reason: ErrSMTPMailFrom, errlist: []error{err}, affectedMsg: message}
+			return newSendResults(message, rcpts, retError), retError
+		}
+		mailParams = append(append(make([]string, 0, len(c.mailParams)+1), c.mailParams...), "REQUIRETLS")
 	}
+	if err = c.smtpClient.SetMailParams(mailParams...); err == nil {
+		err = c.smtpClient.SetRcptParams(c.rcptParams...)
+	}
+	if err != nil {
+		retError := &SendError{Reason: ErrSMTPMailFrom, errlist: []error{err}, affectedMsg: message}
+		return newSendResults(message, rcpts, retError), retError
+	}
+	if c.autoEncoding {
+		if ok, _ := c.smtpClient.Extension("8BITMIME"); ok {
+			if content, err = content.with8BitParts(); err != nil {
+				retError := &SendError{
+					Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+					affectedMsg: message,
+				}
+				return newSendResults(message, rcpts, retError), retError
+			}
+		}
+	}
+	c.smtpClient.SetMailSize(0)
+	var data io.WriterTo = content
+	if c.sizeCheck || c.maxMessageSize > 0 {
+		// The message is rendered only once, so that the size that is checked is the size of the
+		// content that is sent and the middlewares and lazy attachments are not run again
+		buffer := bytes.Buffer{}
+		if _, err = content.WriteTo(&buffer); err != nil {
+			err = fmt.Errorf("failed to render message: %w", err)
+			retError := &SendError{
+				Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+				affectedMsg: message,
+			}
+			return newSendResults(message, rcpts, retError), retError
+		}
+		size := int64(buffer.Len())
+		if limit := c.messageSizeLimit(); limit > 0 && size > limit {
+			retError := &SendError{
+				Reason: ErrMessageTooLarge, isTemp: false, affectedMsg: message,
+				errlist: []error{fmt.Errorf("message size of %d bytes exceeds the limit of %d bytes", size, limit)},
+			}
+			return newSendResults(message, rcpts, retError), retError
+		}
+		if c.sizeCheck {
+			c.smtpClient.SetMailSize(size)
+		}
+		data = renderedMsg(buffer.Bytes())
+	}
+	if c.verp != nil && from != "" {
+		results, err := c.sendVERPTransactions(message, data, rcpts)
+		return append(skipped, results...), err
+	}
+	results, err := c.sendTransaction(message, data, from, rcpts, allowPartial)
+	for i := range results {
+		results[i].EnvelopeFrom = from
+	}
+	return append(skipped, results...), err
+}
 
-	if err = c.tls(); err != nil {
-		return err
+// filterRecipients applies the recipient filter of the Client to the given recipients of a message.
+//
+// Parameters:
+//   - message: A pointer to the Msg the recipients belong to.
+//   - rcpts: The envelope recipient addresses of the message.
+//
+// Returns:
+//   - The recipients the message is sent to.
+//   - A SendResult marked as skipped for each recipient that was dropped by the filter.
+func (c *Client) filterRecipients(message *Msg, rcpts []string) ([]string, []SendResult) {
+	if c.rcptFilter == nil {
+		return rcpts, nil
+	}
+	var skipped []SendResult
+	allowed := make([]string, 0, len(rcpts))
+	for _, rcpt := range rcpts {
+		if !c.rcptFilter(rcpt) {
+			skipped = append(skipped, SendResult{Msg: message, Recipient: rcpt, Skipped: true})
+			continue
+		}
+		allowed = append(allowed, rcpt)
 	}
+	return allowed, skipped
+}
 
-	if err = c.auth(); err != nil {
-		return err
+// sendVERPTransactions sends out a single message in a separate SMTP transaction for each of its
+// recipients, using the envelope sender address computed by the VERP function of the Client.
+//
+// A recipient that is rejected does not prevent the delivery to the remaining recipients. If the
+// connection to the server is no longer usable after a failed transaction, the remaining recipients
+// are not attempted and are reported as failed with the same error.
+//
+// Parameters:
+//   - message: A pointer to the Msg object representing the email message to be sent.
+//   - content: The content that is written in the DATA command.
+//   - rcpts: The envelope recipient addresses of the message.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of the message.
+//   - A SendError listing all failed recipients if the delivery failed for any of them; otherwise nil.
+func (c *Client) sendVERPTransactions(message *Msg, content io.WriterTo, rcpts []string) ([]SendResult, error) {
+	results := make([]SendResult, 0, len(rcpts))
+	sendErr := &SendError{affectedMsg: message}
+	for i, rcpt := range rcpts {
+		from := c.verp(rcpt)
+		rcptResults, err := c.sendTransaction(message, content, from, []string{rcpt}, false)
+		for j := range rcptResults {
+			rcptResults[j].EnvelopeFrom = from
+		}
+		results = append(results, rcptResults...)
+		if err == nil {
+			continue
+		}
+
+		var rcptErr *SendError
+		if !errors.As(err, &rcptErr) {
+			rcptErr = &SendError{Reason: ErrAmbiguous, errlist: []error{err}, affectedMsg: message}
+		}
+		if len(sendErr.rcptErrs) == 0 {
+			sendErr.Reason = rcptErr.Reason
+		}
+		sendErr.errlist = append(sendErr.errlist, rcptErr.errlist...)
+		sendErr.rcpt = append(sendErr.rcpt, rcpt)
+		sendErr.isTemp = rcptErr.isTemp
+		sendErr.errcode = rcptErr.errcode
+		sendErr.rcptErrs = append(sendErr.rcptErrs, rcptErr)
+		if rcptErr.Reason != ErrSMTPMailFrom && rcptErr.Reason != ErrSMTPRcptTo {
+			results = append(results, newSendResults(message, rcpts[i+1:], rcptErr)...)
+			sendErr.rcpt = append(sendErr.rcpt, rcpts[i+1:]...)
+			break
+		}
+	}
+	if len(sendErr.rcptErrs) > 0 {
+		return results, sendErr
 	}
+	return results, nil
+}
 
-	return nil
+// renderedMsg is a Msg that has been rendered ahead of the SMTP transaction. It can be written
+// repeatedly, e.g. for each transaction of a VERP delivery.
+type renderedMsg []byte
+
+// WriteTo writes the rendered Msg to the given io.Writer and satisfies the io.WriterTo interface.
+//
+// Parameters:
+//   - writer: The io.Writer to write the rendered Msg to.
+//
+// Returns:
+//   - The number of bytes written.
+//   - An error if writing fails.
+func (r renderedMsg) WriteTo(writer io.Writer) (int64, error) {
+	n, err := writer.Write(r)
+	return int64(n), err
 }
 
-// Close terminates the connection to the SMTP server, returning an error if the disconnection
-// fails. If the connection is already closed, this method is a no-op and disregards any error.
+// messageSizeLimit returns the maximum size of a message that is sent by the Client.
 //
-// This function checks if the Client's SMTP connection is active. If not, it simply returns
-// without any action. If the connection is active, it attempts to gracefully close the
-// connection using the Quit method.
+// The limit is the maximum message size set via WithMaxMessageSize. If the size check is enabled and the
+// server advertises a lower limit with the SIZE extension, the limit of the server is returned instead.
 //
 // Returns:
-//   - An error if the disconnection fails; otherwise, returns nil.
-func (c *Client) Close() error {
-	if !c.smtpClient.HasConnection() {
-		return nil
+//   - The maximum message size in bytes, or zero if the message size is not limited.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc1870#section-4
+func (c *Client) messageSizeLimit() int64 {
+	limit := c.maxMessageSize
+	if !c.sizeCheck {
+		return limit
+	}
+	if ok, param := c.smtpClient.Extension("SIZE"); ok {
+		serverLimit, err := strconv.ParseInt(strings.TrimSpace(param), 10, 64)
+		if err == nil && serverLimit > 0 && (limit == 0 || serverLimit < limit) {
+			limit = serverLimit
+		}
 	}
-	if err := c.smtpClient.Quit(); err != nil {
-		return fmt.Errorf("failed to close SMTP client: %w", err)
+	return limit
+}
+
+// dataWriter starts the transfer of the message content in the current SMTP transaction.
+//
+// If chunking is enabled and the server supports the CHUNKING extension, the content is transferred
+// with the BDAT command. Otherwise, the DATA command is used. Since the BDAT command transfers the content
+// unchanged, bare LF line endings are converted to CRLF by the Client, like the DATA command does. If a
+// data timeout is set, it is set as deadline for the transfer once it has been started.
+//
+// Returns:
+//   - An io.WriteCloser the message content is written to. Closing it completes the transfer.
+//   - An error if the transfer could not be started; otherwise, returns nil.
+func (c *Client) dataWriter() (io.WriteCloser, error) {
+	var writer io.WriteCloser
+	var err error
+	chunking := false
+	if c.chunking {
+		chunking, _ = c.smtpClient.Extension("CHUNKING")
+	}
+	if chunking {
+		writer, err = c.smtpClient.Bdat()
+		if err == nil {
+			writer = &crlfWriter{WriteCloser: writer}
+		}
+	} else {
+		writer, err = c.smtpClient.Data()
 	}
-
-	return nil
+	return c.extendDataDeadline(writer, err)
 }
 
-// Reset sends an SMTP RSET command to reset the state of the current SMTP session.
+// extendDataDeadline extends the deadline of the connection for the transfer of the message content,
+// if a data timeout is configured.
 //
-// This method checks the connection to the SMTP server and, if the connection is valid,
-// it sends an RSET command to reset the session state. If the connection is invalid or
-// the command fails, an error is returned.
+// Parameters:
+//   - writer: The io.WriteCloser returned by the DATA or BDAT command.
+//   - err: The error returned by the DATA or BDAT command.
 //
 // Returns:
-//   - An error if the connection check fails or if sending the RSET command fails; otherwise, returns nil.
-func (c *Client) Reset() error {
-	if err := c.checkConn(); err != nil {
-		return err
+//   - The given io.WriteCloser.
+//   - The given error, or ErrDeadlineExtendFailed if the deadline cannot be extended.
+func (c *Client) extendDataDeadline(writer io.WriteCloser, err error) (io.WriteCloser, error) {
+	if err != nil || c.dataTimeout <= 0 {
+		return writer, err
 	}
-	if err := c.smtpClient.Reset(); err != nil {
-		return fmt.Errorf("failed to send RSET to SMTP client: %w", err)
+	if err = c.smtpClient.UpdateDeadline(c.dataTimeout); err != nil {
+		return nil, ErrDeadlineExtendFailed
 	}
-
-	return nil
+	return writer, nil
 }
 
-// DialAndSend establishes a connection to the server and sends out the provided Msg.
-// It calls DialAndSendWithContext with an empty Context.Background.
-//
-// This method simplifies the process of connecting to the SMTP server and sending messages
-// by using a default context. It prepares the messages for sending and ensures the connection
-// is established before attempting to send them.
+// canPipelineData reports whether the DATA command can be pipelined together with the MAIL and RCPT
+// commands.
 //
-// Parameters:
-//   - messages: A variadic list of pointers to Msg objects to be sent.
+// This is the case if the server advertises the PIPELINING extension and the message is not
+// transferred with the BDAT command of the CHUNKING extension.
 //
 // Returns:
-//   - An error if the connection fails or if sending the messages fails; otherwise, returns nil.
-func (c *Client) DialAndSend(messages ...*Msg) error {
-	ctx := context.Background()
-	return c.DialAndSendWithContext(ctx, messages...)
+//   - true if the DATA command can be pipelined; otherwise, false.
+func (c *Client) canPipelineData() bool {
+	if ok, _ := c.smtpClient.Extension("PIPELINING"); !ok {
+		return false
+	}
+	if !c.chunking {
+		return true
+	}
+	chunking, _ := c.smtpClient.Extension("CHUNKING")
+	return !chunking
 }
 
-// DialAndSendWithContext establishes a connection to the SMTP server using DialWithContext
-// with the provided context.Context, then sends out the given Msg. After successful delivery,
-// the Client will close the connection to the server.
+// resetTransaction resets the current SMTP transaction after the MAIL or RCPT commands failed.
 //
-// This method first attempts to connect to the SMTP server using the provided context.
-// Upon successful connection, it sends the specified messages and ensures that the connection
-// is closed after the operation, regardless of success or failure in sending the messages.
+// If the server already accepted a pipelined DATA command, the transaction cannot be reset anymore.
+// If no recipient has been accepted, the data phase is terminated with an empty message, which the
+// server rejects, as recommended by RFC 2920. Otherwise, the connection is closed, so that the
+// message is not delivered to the accepted recipients.
 //
 // Parameters:
-//   - ctx: The context.Context to control the connection timeout and cancellation.
-//   - messages: A variadic list of pointers to Msg objects to be sent.
+//   - pipelinedWriter: The io.WriteCloser of the accepted pipelined DATA command, or nil.
+//   - rcptErrs: The errors of the pipelined RCPT commands.
 //
 // Returns:
-//   - An error if the connection fails, if sending the messages fails, or if closing the
-//     connection fails; otherwise, returns nil.
-func (c *Client) DialAndSendWithContext(ctx context.Context, messages ...*Msg) error {
-	if err := c.DialWithContext(ctx); err != nil {
-		return fmt.Errorf("dial failed: %w", err)
+//   - An error if the transaction cannot be reset, or ErrPipelinedDataAborted if the connection
+//     has been closed; otherwise, nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2920#section-3.1
+func (c *Client) resetTransaction(pipelinedWriter io.WriteCloser, rcptErrs []error) error {
+	if pipelinedWriter == nil {
+		return c.smtpClient.Reset()
+	}
+	hasAccepted := false
+	for _, err := range rcptErrs {
+		if err == nil {
+			hasAccepted = true
+			break
+		}
 	}
-	defer func() {
-		_ = c.Close()
-	}()
-
-	if err := c.Send(messages...); err != nil {
-		return fmt.Errorf("send failed: %w", err)
+	if !hasAccepted {
+		_ = pipelinedWriter.Close()
+		return c.smtpClient.Reset()
 	}
-	if err := c.Close(); err != nil {
-		return fmt.Errorf("failed to close connection: %w", err)
+	if err := c.smtpClient.Close(); err != nil {
+		return &wrapError{err: ErrPipelinedDataAborted, cause: err}
 	}
-	return nil
+	return ErrPipelinedDataAborted
 }
 
-// auth attempts to authenticate the client using SMTP AUTH mechanisms. It checks the connection,
-// determines the supported authentication methods, and applies the appropriate authentication
-// type. An error is returned if authentication fails.
+// crlfWriter is an io.WriteCloser that converts bare LF line endings to CRLF before they are written to
+// the underlying io.WriteCloser.
+type crlfWriter struct {
+	io.WriteCloser
+
+	// lastChar is the last byte that has been written to the crlfWriter.
+	lastChar byte
+}
+
+// Write writes the given data to the underlying io.WriteCloser and inserts a CR before each bare LF.
 //
-// This method first verifies the connection to the SMTP server. If no custom authentication
-// mechanism is provided, it checks which authentication methods are supported by the server.
-// Based on the configured SMTPAuthType, it sets up the appropriate authentication mechanism.
-// Finally, it attempts to authenticate the client using the selected method.
+// The data between the line feeds is passed on in slices, so that the underlying io.WriteCloser does
+// not need to handle single bytes.
+//
+// Parameters:
+//   - payload: A byte slice containing the data to be written.
 //
 // Returns:
-//   - An error if the connection check fails, if no supported authentication method is found,
-//     or if the authentication process fails.
-func (c *Client) auth() error {
-	if err := c.checkConn(); err != nil {
-		return fmt.Errorf("failed to authenticate: %w", err)
-	}
-	if c.smtpAuth == nil && c.smtpAuthType != SMTPAuthCustom {
-		hasSMTPAuth, smtpAuthType := c.smtpClient.Extension("AUTH")
-		if !hasSMTPAuth {
-			return fmt.Errorf("server does not support SMTP AUTH")
+//   - The number of bytes of the payload that have been written.
+//   - An error if writing to the underlying io.WriteCloser fails.
+func (w *crlfWriter) Write(payload []byte) (int, error) {
+	start := 0
+	for i, char := range payload {
+		if char != '\n' {
+			continue
 		}
-
-		switch c.smtpAuthType {
-		case SMTPAuthPlain:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthPlain)) {
-				return ErrPlainAuthNotSupported
-			}
-			c.smtpAuth = smtp.PlainAuth("", c.user, c.pass, c.host)
-		case SMTPAuthLogin:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthLogin)) {
-				return ErrLoginAuthNotSupported
-			}
-			c.smtpAuth = smtp.LoginAuth(c.user, c.pass, c.host)
-		case SMTPAuthCramMD5:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthCramMD5)) {
-				return ErrCramMD5AuthNotSupported
-			}
-			c.smtpAuth = smtp.CRAMMD5Auth(c.user, c.pass)
-		case SMTPAuthXOAUTH2:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthXOAUTH2)) {
-				return ErrXOauth2AuthNotSupported
-			}
-			c.smtpAuth = smtp.XOAuth2Auth(c.user, c.pass)
-		case SMTPAuthSCRAMSHA1:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA1)) {
-				return ErrSCRAMSHA1AuthNotSupported
-			}
-			c.smtpAuth = smtp.ScramSHA1Auth(c.user, c.pass)
-		case SMTPAuthSCRAMSHA256:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA256)) {
-				return ErrSCRAMSHA256AuthNotSupported
-			}
-			c.smtpAuth = smtp.ScramSHA256Auth(c.user, c.pass)
-		case SMTPAuthSCRAMSHA1PLUS:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA1PLUS)) {
-				return ErrSCRAMSHA1PLUSAuthNotSupported
-			}
-			tlsConnState, err := c.smtpClient.GetTLSConnectionState()
-			if err != nil {
-				return err
-			}
-			c.smtpAuth = smtp.ScramSHA1PlusAuth(c.user, c.pass, tlsConnState)
-		case SMTPAuthSCRAMSHA256PLUS:
-			if !strings.Contains(smtpAuthType, string(SMTPAuthSCRAMSHA256PLUS)) {
-				return ErrSCRAMSHA256PLUSAuthNotSupported
-			}
-			tlsConnState, err := c.smtpClient.GetTLSConnectionState()
-			if err != nil {
-				return err
-			}
-			c.smtpAuth = smtp.ScramSHA256PlusAuth(c.user, c.pass, tlsConnState)
-		default:
-			return fmt.Errorf("unsupported SMTP AUTH type %q", c.smtpAuthType)
+		previous := w.lastChar
+		if i > 0 {
+			previous = payload[i-1]
 		}
-	}
-
-	if c.smtpAuth != nil {
-		if err := c.smtpClient.Auth(c.smtpAuth); err != nil {
-			return fmt.Errorf("SMTP AUTH failed: %w", err)
+		if previous == '\r' {
+			continue
+		}
+		if _, err := w.WriteCloser.Write(payload[start:i]); err != nil {
+			return start, err
+		}
+		if _, err := w.WriteCloser.Write([]byte{'\r'}); err != nil {
+			return i, err
 		}
+		start = i
 	}
-	return nil
+	if _, err := w.WriteCloser.Write(payload[start:]); err != nil {
+		return start, err
+	}
+	if len(payload) > 0 {
+		w.lastChar = payload[len(payload)-1]
+	}
+	return len(payload), nil
 }
 
-// sendSingleMsg sends out a single message and returns an error if the transmission or
-// delivery fails. It is invoked by the public Send methods.
+// sendTransaction sends out a single message to the given recipients in one SMTP transaction.
 //
-// This method handles the process of sending a single email message through the SMTP
-// client. It performs several checks and operations, including verifying the encoding,
-// retrieving the sender and recipient addresses, and managing delivery status notifications
-// (DSN). It attempts to send the message and handles any errors that occur during the
-// transmission process, ensuring that any necessary cleanup is performed (such as resetting
-// the SMTP client if an error occurs).
+// This method issues the MAIL FROM, RCPT TO and DATA (or BDAT) commands for the message and resets the
+// SMTP session afterwards. If allowPartial is true, recipients that are rejected during the RCPT
+// TO command do not abort the transaction, but the message is sent to all accepted recipients.
 //
 // Parameters:
 //   - message: A pointer to the Msg object representing the email message to be sent.
+//   - content: The content that is written in the DATA command. It is either the message itself, a
+//     copy of it with adjusted transfer encodings and produced attachment content, or the message
+//     rendered ahead of the transaction, if its size is checked.
+//   - from: The envelope sender address used for the MAIL FROM command.
+//   - rcpts: The envelope recipient addresses used for the RCPT TO commands.
+//   - allowPartial: Whether the message is sent to the accepted recipients if some are rejected.
 //
 // Returns:
-//   - An error if any part of the sending process fails; otherwise, returns nil.
-func (c *Client) sendSingleMsg(message *Msg) error {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	if message.encoding == NoEncoding {
-		if ok, _ := c.smtpClient.Extension("8BITMIME"); !ok {
-			return &SendError{Reason: ErrNoUnencoded, isTemp: false, affectedMsg: message}
-		}
-	}
-	from, err := message.GetSender(false)
-	if err != nil {
-		return &SendError{
-			Reason: ErrGetSender, errlist: []error{err}, isTemp: isTempError(err),
-			affectedMsg: message,
-		}
+//   - A slice of SendResult, one for each recipient.
+//   - An error if any part of the transaction fails; otherwise, returns nil.
+func (c *Client) sendTransaction(message *Msg, content io.WriterTo, from string, rcpts []string,
+	allowPartial bool,
+) ([]SendResult, error) {
+	var err, dataErr error
+	var rcptErrs []error
+	var pipelinedWriter io.WriteCloser
+	pipelinedData := c.pipelining && c.canPipelineData()
+	switch {
+	case pipelinedData:
+		rcptErrs, pipelinedWriter, dataErr, err = c.smtpClient.MailRcptDataPipelined(from, rcpts)
+	case c.pipelining:
+		rcptErrs, err = c.smtpClient.MailRcptPipelined(from, rcpts)
+	default:
+		err = c.smtpClient.Mail(from)
 	}
-	rcpts, err := message.GetRecipients()
 	if err != nil {
-		return &SendError{
-			Reason: ErrGetRcpts, errlist: []error{err}, isTemp: isTempError(err),
-			affectedMsg: message,
-		}
-	}
-
-	if c.requestDSN {
-		if c.dsnReturnType != "" {
-			c.smtpClient.SetDSNMailReturnOption(string(c.dsnReturnType))
-		}
-	}
-	if err = c.smtpClient.Mail(from); err != nil {
 		retError := &SendError{
-			Reason: ErrSMTPMailFrom, errlist: []error{err}, isTemp: isTempError(err),
+			Reason: ErrSMTPMailFrom, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
-		if resetSendErr := c.smtpClient.Reset(); resetSendErr != nil {
+		if resetSendErr := c.resetTransaction(pipelinedWriter, rcptErrs); resetSendErr != nil {
 			retError.errlist = append(retError.errlist, resetSendErr)
 		}
-		return retError
+		return newSendResults(message, rcpts, retError), retError
 	}
 	hasError := false
 	rcptSendErr := &SendError{affectedMsg: message}
 	rcptSendErr.errlist = make([]error, 0)
 	rcptSendErr.rcpt = make([]string, 0)
-	rcptNotifyOpt := strings.Join(c.dsnRcptNotifyType, ",")
-	c.smtpClient.SetDSNRcptNotifyOption(rcptNotifyOpt)
-	for _, rcpt := range rcpts {
-		if err = c.smtpClient.Rcpt(rcpt); err != nil {
+	results := make([]SendResult, 0, len(rcpts))
+	accepted := make([]string, 0, len(rcpts))
+	for i, rcpt := range rcpts {
+		if c.pipelining {
+			err = rcptErrs[i]
+		} else {
+			err = c.smtpClient.Rcpt(rcpt)
+		}
+		if err != nil {
+			singleRcptErr := &SendError{
+				Reason: ErrSMTPRcptTo, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+				rcpt: []string{rcpt}, affectedMsg: message,
+			}
 			rcptSendErr.Reason = ErrSMTPRcptTo
 			rcptSendErr.errlist = append(rcptSendErr.errlist, err)
 			rcptSendErr.rcpt = append(rcptSendErr.rcpt, rcpt)
 			rcptSendErr.isTemp = isTempError(err)
+			rcptSendErr.errcode = errorCode(err)
+			rcptSendErr.rcptErrs = append(rcptSendErr.rcptErrs, singleRcptErr)
+			results = append(results, SendResult{Msg: message, Recipient: rcpt, Err: singleRcptErr})
 			hasError = true
+			continue
 		}
+		accepted = append(accepted, rcpt)
 	}
-	if hasError {
-		if resetSendErr := c.smtpClient.Reset(); resetSendErr != nil {
+	if hasError && (!allowPartial || len(accepted) == 0) {
+		if resetSendErr := c.resetTransaction(pipelinedWriter, rcptErrs); resetSendErr != nil {
 			rcptSendErr.errlist = append(rcptSendErr.errlist, resetSendErr)
 		}
-		return rcptSendErr
+		if len(accepted) > 0 {
+			results = append(results, newSendResults(message, accepted, rcptSendErr)...)
+		}
+		return results, rcptSendErr
+	}
+	var writer io.WriteCloser
+	if pipelinedData {
+		writer, err = c.extendDataDeadline(pipelinedWriter, dataErr)
+	} else {
+		writer, err = c.dataWriter()
 	}
-	writer, err := c.smtpClient.Data()
 	if err != nil {
-		return &SendError{
-			Reason: ErrSMTPData, errlist: []error{err}, isTemp: isTempError(err),
+		retError := &SendError{
+			Reason: ErrSMTPData, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
+		return append(results, newSendResults(message, accepted, retError)...), retError
 	}
-	_, err = message.WriteTo(writer)
+	_, err = content.WriteTo(writer)
 	if err != nil {
-		return &SendError{
-			Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err),
+		retError := &SendError{
+			Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
+		return append(results, newSendResults(message, accepted, retError)...), retError
 	}
 	message.isDelivered = true
 
 	if err = writer.Close(); err != nil {
-		return &SendError{
-			Reason: ErrSMTPDataClose, errlist: []error{err}, isTemp: isTempError(err),
+		retError := &SendError{
+			Reason: ErrSMTPDataClose, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
+		return append(results, newSendResults(message, accepted, retError)...), retError
 	}
+	results = append(results, newSendResults(message, accepted, nil)...)
 
-	if err = c.Reset(); err != nil {
-		return &SendError{
-			Reason: ErrSMTPReset, errlist: []error{err}, isTemp: isTempError(err),
+	if err = c.reset(); err != nil {
+		return results, &SendError{
+			Reason: ErrSMTPReset, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
 	}
 	if err = c.checkConn(); err != nil {
-		return &SendError{
-			Reason: ErrConnCheck, errlist: []error{err}, isTemp: isTempError(err),
+		return results, &SendError{
+			Reason: ErrConnCheck, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
 			affectedMsg: message,
 		}
 	}
-	return nil
+	if hasError {
+		return results, rcptSendErr
+	}
+	return results, nil
+}
+
+// sendDryRun renders a single message in dry-run mode instead of sending it to the SMTP server.
+//
+// The message is written to the dry-run output of the Client, or discarded if no output is set.
+//
+// Parameters:
+//   - message: A pointer to the Msg object representing the email message to be rendered.
+//   - content: A pointer to the Msg that is written. It is either the message itself or a copy of it
+//     with produced attachment content.
+//   - rcpts: The recipients of the message.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of the message.
+//   - An error if rendering the message fails; otherwise, returns nil.
+func (c *Client) sendDryRun(message, content *Msg, rcpts []string) ([]SendResult, error) {
+	output := c.dryRunOutput
+	if output == nil {
+		output = io.Discard
+	}
+	if _, err := content.WriteTo(output); err != nil {
+		retError := &SendError{
+			Reason: ErrWriteContent, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err),
+			affectedMsg: message,
+		}
+		return newSendResults(message, rcpts, retError), retError
+	}
+	return newSendResults(message, rcpts, nil), nil
+}
+
+// newSendResults returns a SendResult with the given error for each of the given recipients.
+//
+// Parameters:
+//   - message: A pointer to the Msg the results belong to.
+//   - rcpts: The recipients to create a SendResult for.
+//   - err: The delivery error for the recipients, or nil if the delivery succeeded.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient. If no recipients are given and err is not nil,
+//     a single SendResult with an empty recipient is returned.
+func newSendResults(message *Msg, rcpts []string, err error) []SendResult {
+	if len(rcpts) == 0 {
+		if err == nil {
+			return nil
+		}
+		return []SendResult{{Msg: message, Err: err}}
+	}
+	results := make([]SendResult, 0, len(rcpts))
+	for _, rcpt := range rcpts {
+		results = append(results, SendResult{Msg: message, Recipient: rcpt, Err: err})
+	}
+	return results
+}
+
+// watchContext aborts any pending I/O on the connection of the Client once the given context
+// is done.
+//
+// A context can not be passed to the blocking reads and writes on the connection, so the
+// connection is closed when the context is canceled or its deadline is exceeded. This unblocks
+// any pending SMTP command and ensures that the connection is not leaked.
+//
+// Parameters:
+//   - ctx: The context.Context that controls the cancellation.
+//
+// Returns:
+//   - A function that stops watching the context. It must be called once the operation that is
+//     controlled by the context has finished.
+func (c *Client) watchContext(ctx context.Context) func() {
+	connection := c.connection
+	if ctx.Done() == nil || connection == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = connection.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// contextError returns the given error wrapped with the error of the context, if the context
+// is done.
+//
+// If the context is done, the SMTP client is closed as well, so that it is no longer considered
+// to be connected.
+//
+// Parameters:
+//   - ctx: The context.Context that controlled the failed operation.
+//   - err: The error of the failed operation.
+//
+// Returns:
+//   - An error that matches both the error of the context and the given error, if the context is
+//     done; otherwise the given error.
+func (c *Client) contextError(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil {
+		return err
+	}
+	if c.smtpClient != nil {
+		_ = c.smtpClient.Close()
+	}
+	return &wrapError{err: ctxErr, cause: err}
+}
+
+// wrapError is an error that wraps a sentinel error together with the error that caused it.
+//
+// Since multiple %w verbs in fmt.Errorf are not supported before Go 1.20, wrapError allows errors.Is
+// and errors.As to match both the sentinel error and the chain of the cause.
+type wrapError struct {
+	err   error
+	cause error
+}
+
+// Error returns the message of the sentinel error followed by the message of the cause.
+//
+// Returns:
+//   - The error message.
+func (e *wrapError) Error() string {
+	return fmt.Sprintf("%s: %s", e.err, e.cause)
+}
+
+// Is reports whether the sentinel error of the wrapError matches the given target error. The cause is
+// matched by errors.Is via Unwrap.
+//
+// Parameters:
+//   - target: The target error to match.
+//
+// Returns:
+//   - true if the sentinel error matches the target; otherwise false.
+func (e *wrapError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// As finds the first error in the chain of the sentinel error that matches the given target. The
+// cause is matched by errors.As via Unwrap.
+//
+// Parameters:
+//   - target: A pointer to the target to set.
+//
+// Returns:
+//   - true if an error in the chain of the sentinel error matches the target; otherwise false.
+func (e *wrapError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// Unwrap returns the cause of the wrapError.
+//
+// Returns:
+//   - The error that caused the sentinel error.
+func (e *wrapError) Unwrap() error {
+	return e.cause
 }
 
 // checkConn ensures that a required server connection is available and extends the connection
@@ -1234,6 +3466,9 @@ func (c *Client) sendSingleMsg(message *Msg) error {
 //   - An error if there is no active connection, if the NOOP command fails, or if extending
 //     the deadline fails; otherwise, returns nil.
 func (c *Client) checkConn() error {
+	if c.dryRun {
+		return nil
+	}
 	if !c.smtpClient.HasConnection() {
 		return ErrNoActiveConnection
 	}
@@ -1289,8 +3524,9 @@ func (c *Client) setDefaultHelo() error {
 // connection is encrypted and returns any errors encountered during these processes.
 //
 // Returns:
-//   - An error if there is no active connection, if STARTTLS is required but not supported,
-//     or if there are issues during the TLS handshake; otherwise, returns nil.
+//   - An error if there is no active connection, if STARTTLS is required but not supported
+//     (ErrSTARTTLSNotSupported), or if there are issues during the TLS handshake (ErrSTARTTLSFailed);
+//     otherwise, returns nil.
 func (c *Client) tls() error {
 	if !c.smtpClient.HasConnection() {
 		return ErrNoActiveConnection
@@ -1301,8 +3537,7 @@ func (c *Client) tls() error {
 		if c.tlspolicy == TLSMandatory {
 			hasStartTLS = true
 			if !extension {
-				return fmt.Errorf("STARTTLS mode set to: %q, but target host does not support STARTTLS",
-					c.tlspolicy)
+				return fmt.Errorf("%w: STARTTLS mode set to: %q", ErrSTARTTLSNotSupported, c.tlspolicy)
 			}
 		}
 		if c.tlspolicy == TLSOpportunistic {
@@ -1311,8 +3546,8 @@ func (c *Client) tls() error {
 			}
 		}
 		if hasStartTLS {
-			if err := c.smtpClient.StartTLS(c.tlsconfig); err != nil {
-				return err
+			if err := c.smtpClient.StartTLS(c.getTLSConfig()); err != nil {
+				return &wrapError{err: ErrSTARTTLSFailed, cause: err}
 			}
 		}
 		tlsConnState, err := c.smtpClient.GetTLSConnectionState()
@@ -1329,3 +3564,43 @@ func (c *Client) tls() error {
 	}
 	return nil
 }
+
+// checkRequireTLS checks whether the REQUIRETLS parameter can be used on the current connection.
+//
+// Returns:
+//   - An error wrapping ErrREQUIRETLSNotSupported if the connection is not encrypted or the server does not
+//     advertise the REQUIRETLS extension; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8689#section-4.1
+func (c *Client) checkRequireTLS() error {
+	if !c.isEncrypted {
+		return fmt.Errorf("%w: connection is not encrypted", ErrREQUIRETLSNotSupported)
+	}
+	if ok, _ := c.smtpClient.Extension("REQUIRETLS"); !ok {
+		return fmt.Errorf("%w: extension not advertised by the server", ErrREQUIRETLSNotSupported)
+	}
+	return nil
+}
+
+// getTLSConfig returns the tls.Config that is used for the TLS handshake with the SMTP server.
+//
+// If a TLS server name is set via WithTLSServerName or the configured tls.Config does not hold a
+// ServerName, a copy of the tls.Config with the ServerName set to the TLS server name or the hostname
+// of the Client respectively is returned, so that the certificate of the server can be verified. The
+// configured tls.Config itself is never modified.
+//
+// Returns:
+//   - A pointer to the tls.Config for the TLS handshake.
+func (c *Client) getTLSConfig() *tls.Config {
+	serverName := c.tlsServerName
+	if serverName == "" && c.tlsconfig.ServerName == "" {
+		serverName = c.host
+	}
+	if serverName == "" || serverName == c.tlsconfig.ServerName {
+		return c.tlsconfig
+	}
+	tlsConfig := c.tlsconfig.Clone()
+	tlsConfig.ServerName = serverName
+	return tlsConfig
+}
@@ -7,10 +7,13 @@
 
 package mail
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
-// Send attempts to send one or more Msg using the Client connection to the SMTP server.
-// If the Client has no active connection to the server, Send will fail with an error. For each
+// SendWithContext attempts to send one or more Msg using the Client connection to the SMTP server.
+// If the Client has no active connection to the server, SendWithContext will fail with an error. For each
 // of the provided Msg, it will associate a SendError with the Msg in case of a transmission
 // or delivery error.
 //
@@ -20,19 +23,33 @@ import "errors"
 // associates it with the corresponding Msg. If multiple errors are encountered, it aggregates
 // them into a single SendError to be returned.
 //
+// If a rate limit is set for the Client via WithRateLimit, the provided context.Context allows to
+// cancel the waiting for the rate limit before each Msg. Canceling the context does not abort the
+// transmission of a Msg that is already in progress.
+//
+// Concurrent calls on the same Client are serialized, so that the SMTP commands for the messages of
+// different calls are not interleaved on the connection. If the automatic reconnection is enabled via
+// WithReconnect, the Client dials the server again instead of failing, if the connection is broken.
+//
 // Parameters:
+//   - ctx: The context.Context to control the waiting for the rate limit.
 //   - messages: A variadic list of pointers to Msg objects to be sent.
 //
 // Returns:
 //   - An error that represents the sending result, which may include multiple SendErrors if
 //     any occurred; otherwise, returns nil.
-func (c *Client) Send(messages ...*Msg) error {
-	if err := c.checkConn(); err != nil {
-		return &SendError{Reason: ErrConnCheck, errlist: []error{err}, isTemp: isTempError(err)}
+func (c *Client) SendWithContext(ctx context.Context, messages ...*Msg) error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if err := c.checkConnWithReconnect(ctx); err != nil {
+		retError := &SendError{Reason: ErrConnCheck, errlist: []error{err}, isTemp: isTempError(err), errcode: errorCode(err)}
+		c.callSendHooks(messages, retError)
+		return retError
 	}
 	var errs []*SendError
 	for id, message := range messages {
-		if sendErr := c.sendSingleMsg(message); sendErr != nil {
+		if sendErr := c.sendSingleMsg(ctx, message); sendErr != nil {
 			messages[id].sendError = sendErr
 
 			var msgSendErr *SendError
@@ -48,6 +65,7 @@ func (c *Client) Send(messages ...*Msg) error {
 			for i := range errs {
 				returnErr.errlist = append(returnErr.errlist, errs[i].errlist...)
 				returnErr.rcpt = append(returnErr.rcpt, errs[i].rcpt...)
+				returnErr.rcptErrs = append(returnErr.rcptErrs, errs[i].rcptErrs...)
 			}
 
 			// We assume that the isTemp flag from the last error we received should be the
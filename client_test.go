@@ -6,12 +6,21 @@ package mail
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
+	"net/textproto"
 	"os"
 	"strconv"
 	"strings"
@@ -167,6 +176,37 @@ func TestWithHELO(t *testing.T) {
 	}
 }
 
+// TestClient_DialWithContext_HELOFallback tests that the Client greets the server with the name set via
+// WithHELO and falls back to HELO with the same name if EHLO is rejected
+func TestClient_DialWithContext_HELOFallback(t *testing.T) {
+	server := []string{
+		"220 Fake server ready", "502 5.5.2 Command not recognized", "250 fake.server", "221 OK",
+	}
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+		&wrote,
+	}
+	client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+		WithTLSPortPolicy(NoTLS), WithHELO("mail.example.com"))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
+	}
+	if want := "EHLO mail.example.com\r\nHELO mail.example.com\r\n"; !strings.HasPrefix(wrote.String(), want) {
+		t.Errorf("DialWithContext expected greeting %q, got: %q", want, wrote.String())
+	}
+}
+
 // TestWithPort tests the WithPort() option for the NewClient() method
 func TestWithPort(t *testing.T) {
 	tests := []struct {
@@ -578,6 +618,333 @@ func TestWithoutNoop(t *testing.T) {
 	}
 }
 
+// TestWithMessageIDGenerator tests the WithMessageIDGenerator and SetMessageIDGenerator methods
+func TestWithMessageIDGenerator(t *testing.T) {
+	generator := func() string { return "generated@domain.tld" }
+	c, err := NewClient(DefaultHost, WithMessageIDGenerator(generator))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	if c.messageIDGenerator == nil || c.messageIDGenerator() != "generated@domain.tld" {
+		t.Error("WithMessageIDGenerator failed. Expected generator to be set")
+	}
+	c.SetMessageIDGenerator(nil)
+	if c.messageIDGenerator != nil {
+		t.Error("SetMessageIDGenerator failed. Expected generator to be unset")
+	}
+}
+
+// TestClient_WithUserAgentVersion tests that the default User-Agent of the Client applies to all messages that
+// do not set their own User-Agent
+func TestClient_WithUserAgentVersion(t *testing.T) {
+	if _, err := NewMemoryClient(WithUserAgentVersion(" ", "1.0")); !errors.Is(err, ErrInvalidUserAgent) {
+		t.Errorf("WithUserAgentVersion with empty product expected error: %s, got: %v", ErrInvalidUserAgent, err)
+	}
+	client, err := NewMemoryClient(WithUserAgentVersion("Invoicer", "2.4.1"))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	branded := newPoolTestMsg(t)
+	explicit := newPoolTestMsg(t)
+	explicit.SetUserAgentVersion("Reminder", "1.0")
+	noXMailer := NewMsg(WithNoDefaultXMailer())
+	noDefault := NewMsg(WithNoDefaultUserAgent())
+	for _, message := range []*Msg{noXMailer, noDefault} {
+		if err = message.From("valid-from@domain.tld"); err != nil {
+			t.Fatalf("failed to set FROM address: %s", err)
+		}
+		if err = message.To("valid-to@domain.tld"); err != nil {
+			t.Fatalf("failed to set TO address: %s", err)
+		}
+		message.SetBodyString(TypeTextPlain, "Test body")
+	}
+	if err = client.DialAndSend(branded, explicit, noXMailer, noDefault); err != nil {
+		t.Fatalf("DialAndSend failed: %s", err)
+	}
+	messages := client.Messages()
+	if len(messages) != 4 {
+		t.Fatalf("DialAndSend failed. Expected 4 delivered messages, got: %d", len(messages))
+	}
+	tests := []struct {
+		name      string
+		data      string
+		userAgent string
+		xMailer   string
+	}{
+		{"client default", string(messages[0].Data), "Invoicer 2.4.1", "Invoicer 2.4.1"},
+		{"message precedence", string(messages[1].Data), "Reminder 1.0", "Reminder 1.0"},
+		{"no default X-Mailer", string(messages[2].Data), "Invoicer 2.4.1", ""},
+		{"no default headers", string(messages[3].Data), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for header, want := range map[Header]string{HeaderUserAgent: tt.userAgent, HeaderXMailer: tt.xMailer} {
+				prefix := "\r\n" + header.String() + ": "
+				if want == "" {
+					if strings.Contains(tt.data, prefix) {
+						t.Errorf("DialAndSend failed. Expected no %s header, got: %s", header, tt.data)
+					}
+					continue
+				}
+				if !strings.Contains(tt.data, prefix+want+"\r\n") {
+					t.Errorf("DialAndSend failed. Expected %s: %s, got: %s", header, want, tt.data)
+				}
+			}
+			if strings.Contains(tt.data, "go-mail v") {
+				t.Errorf("DialAndSend failed. Expected no go-mail default User-Agent, got: %s", tt.data)
+			}
+		})
+	}
+}
+
+// TestClient_SetEnvelopeFrom_null tests that the null reverse-path of a Msg is sent as MAIL FROM:<>, even with
+// VERP enabled, while the FROM header is kept
+func TestClient_SetEnvelopeFrom_null(t *testing.T) {
+	client, err := NewMemoryClient(WithVERP(func(string) string { return "verp@example.com" }))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	null := newPoolTestMsg(t)
+	if err = null.SetEnvelopeFrom("<>"); err != nil {
+		t.Fatalf("SetEnvelopeFrom failed: %s", err)
+	}
+	if err = client.DialAndSend(null); err != nil {
+		t.Fatalf("DialAndSend failed: %s", err)
+	}
+	messages := client.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("DialAndSend failed. Expected 1 delivered message, got: %d", len(messages))
+	}
+	if messages[0].EnvelopeFrom != "" {
+		t.Errorf("expected null reverse-path, got: %q", messages[0].EnvelopeFrom)
+	}
+	if !bytes.Contains(messages[0].Data, []byte("\r\nFrom: <valid-from@domain.tld>\r\n")) {
+		t.Errorf("expected FROM header to be kept, got: %s", messages[0].Data)
+	}
+}
+
+// TestClient_WithRecipientFilter tests that recipients dropped by the recipient filter of the Client are not
+// sent to and reported as skipped
+func TestClient_WithRecipientFilter(t *testing.T) {
+	if _, err := NewMemoryClient(WithRecipientFilter(nil)); !errors.Is(err, ErrInvalidRecipientFilter) {
+		t.Errorf("WithRecipientFilter with nil filter expected error: %s, got: %v", ErrInvalidRecipientFilter, err)
+	}
+	for _, domains := range [][]string{nil, {"example.com", " "}} {
+		if _, err := NewMemoryClient(WithAllowedDomains(domains...)); !errors.Is(err, ErrInvalidRecipientFilter) {
+			t.Errorf("WithAllowedDomains with domains %q expected error: %s, got: %v", domains,
+				ErrInvalidRecipientFilter, err)
+		}
+	}
+
+	client, err := NewMemoryClient(WithAllowedDomains("staging.example.com", "@Example.org"))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	message := newPoolTestMsg(t)
+	if err = message.To("tester@staging.example.com", "customer@example.com"); err != nil {
+		t.Fatalf("failed to set TO addresses: %s", err)
+	}
+	if err = message.Cc("qa@EXAMPLE.ORG", "customer@sub.example.org"); err != nil {
+		t.Fatalf("failed to set CC addresses: %s", err)
+	}
+	customers := newPoolTestMsg(t)
+	if err = customers.To("customer@example.com"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	results, err := client.SendWithResults(message, customers)
+	if err != nil {
+		t.Fatalf("SendWithResults failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
+	}
+
+	skipped := map[string]bool{
+		"tester@staging.example.com": false, "customer@example.com": true, "qa@EXAMPLE.ORG": false,
+		"customer@sub.example.org": true,
+	}
+	if len(results) != len(skipped)+1 {
+		t.Fatalf("SendWithResults failed. Expected %d results, got: %d", len(skipped)+1, len(results))
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("SendWithResults failed for %s: %s", result.Recipient, result.Err)
+		}
+		if result.Msg == customers {
+			if !result.Skipped {
+				t.Errorf("SendWithResults failed. Expected %s to be skipped", result.Recipient)
+			}
+			continue
+		}
+		if want, ok := skipped[result.Recipient]; !ok || result.Skipped != want {
+			t.Errorf("SendWithResults failed. Expected %s to be skipped: %t, got: %t", result.Recipient, want,
+				result.Skipped)
+		}
+	}
+	if customers.IsDelivered() {
+		t.Error("SendWithResults failed. Expected message with only filtered recipients not to be delivered")
+	}
+	messages := client.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("SendWithResults failed. Expected 1 delivered message, got: %d", len(messages))
+	}
+	rcpts := strings.Join(messages[0].Recipients, ",")
+	if rcpts != "tester@staging.example.com,qa@EXAMPLE.ORG" {
+		t.Errorf("SendWithResults failed. Unexpected envelope recipients: %s", rcpts)
+	}
+	if !strings.Contains(string(messages[0].Data), "customer@example.com") {
+		t.Errorf("SendWithResults failed. Expected the message content to be unchanged")
+	}
+}
+
+// TestClient_WithSendHooks tests that the send hooks of the Client are called for each message of a batch
+func TestClient_WithSendHooks(t *testing.T) {
+	if _, err := NewMemoryClient(WithBeforeSendHook(nil)); !errors.Is(err, ErrInvalidSendHook) {
+		t.Errorf("WithBeforeSendHook with nil hook expected error: %s, got: %v", ErrInvalidSendHook, err)
+	}
+	if _, err := NewMemoryClient(WithAfterSendHook(nil)); !errors.Is(err, ErrInvalidSendHook) {
+		t.Errorf("WithAfterSendHook with nil hook expected error: %s, got: %v", ErrInvalidSendHook, err)
+	}
+
+	var events []string
+	var outcomes []error
+	client, err := NewMemoryClient(
+		WithBeforeSendHook(func(message *Msg) {
+			events = append(events, "before: "+message.GetToString()[0])
+		}),
+		WithAfterSendHook(func(message *Msg, err error, duration time.Duration) {
+			events = append(events, "after: "+message.GetToString()[0])
+			outcomes = append(outcomes, err)
+			if duration < 0 {
+				t.Errorf("send hook expected non-negative duration, got: %s", duration)
+			}
+			message.Subject("changed by the hook")
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	client.SetRcptResponse("invalid@domain.tld", 550, "5.1.1 No such user")
+	delivered := newPoolTestMsg(t)
+	rejected := newPoolTestMsg(t)
+	if err = rejected.To("invalid@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	if err = client.DialAndSend(delivered, rejected); err == nil {
+		t.Fatal("DialAndSend with rejected recipient was supposed to fail, but didn't")
+	}
+	want := []string{
+		"before: <valid-to@domain.tld>", "after: <valid-to@domain.tld>", "before: <invalid@domain.tld>",
+		"after: <invalid@domain.tld>",
+	}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("send hooks were called in unexpected order: %v", events)
+	}
+	var sendErr *SendError
+	if len(outcomes) != 2 || outcomes[0] != nil || !errors.As(outcomes[1], &sendErr) ||
+		sendErr.Reason != ErrSMTPRcptTo {
+		t.Errorf("after send hook received unexpected outcomes: %v", outcomes)
+	}
+	messages := client.Messages()
+	if len(messages) != 1 || strings.Contains(string(messages[0].Data), "changed by the hook") {
+		t.Errorf("after send hook was not supposed to change the sent message")
+	}
+
+	events, outcomes = nil, nil
+	if _, err = client.SendWithResults(delivered); !errors.As(err, &sendErr) || sendErr.Reason != ErrConnCheck {
+		t.Errorf("SendWithResults without connection expected error: %s, got: %v", ErrConnCheck, err)
+	}
+	if len(events) != 2 || len(outcomes) != 1 || outcomes[0] != err {
+		t.Errorf("send hooks were expected to be called with the connection error, got: %v, %v", events, outcomes)
+	}
+}
+
+// TestClient_WithRequireTLS tests that WithRequireTLS adds the REQUIRETLS parameter to the MAIL FROM command
+// and aborts the delivery if the server does not support REQUIRETLS
+func TestClient_WithRequireTLS(t *testing.T) {
+	certificate, rootCAs := newTLSServerNameTestCertificate(t, memoryHost)
+	serverTLSConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: DefaultTLSMinVersion}
+	tests := []struct {
+		name       string
+		serverTLS  bool
+		options    []Option
+		wantParams string
+		wantErr    bool
+	}{
+		{"REQUIRETLS over STARTTLS", true, []Option{WithRequireTLS()}, "REQUIRETLS", false},
+		{
+			"REQUIRETLS with MAIL FROM parameters", true,
+			[]Option{WithRequireTLS(), WithMailParams("MT-PRIORITY=3")}, "MT-PRIORITY=3 REQUIRETLS", false,
+		},
+		{"no REQUIRETLS", true, nil, "", false},
+		{"REQUIRETLS without TLS", false, []Option{WithRequireTLS()}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{
+				WithTLSPolicy(TLSOpportunistic),
+				WithTLSConfig(&tls.Config{RootCAs: rootCAs, ServerName: memoryHost, MinVersion: DefaultTLSMinVersion}),
+			}, tt.options...)
+			client, err := NewMemoryClient(options...)
+			if err != nil {
+				t.Fatalf("NewMemoryClient failed: %s", err)
+			}
+			if tt.serverTLS {
+				client.SetServerTLSConfig(serverTLSConfig)
+			}
+			err = client.DialAndSend(newPoolTestMsg(t))
+			if tt.wantErr {
+				var sendErr *SendError
+				if !errors.As(err, &sendErr) || sendErr.Reason != ErrSMTPMailFrom || len(sendErr.errlist) != 1 ||
+					!errors.Is(sendErr.errlist[0], ErrREQUIRETLSNotSupported) {
+					t.Errorf("DialAndSend expected SendError with reason %s and error %s, got: %v", ErrSMTPMailFrom,
+						ErrREQUIRETLSNotSupported, err)
+				}
+				if len(client.Messages()) != 0 {
+					t.Errorf("DialAndSend expected no message to be delivered without REQUIRETLS")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DialAndSend failed: %s", err)
+			}
+			messages := client.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("DialAndSend expected 1 message, got: %d", len(messages))
+			}
+			var params []string
+			for _, param := range messages[0].MailParams {
+				if param != "BODY=8BITMIME" && param != "SMTPUTF8" {
+					params = append(params, param)
+				}
+			}
+			if got := strings.Join(params, " "); got != tt.wantParams {
+				t.Errorf("DialAndSend expected MAIL FROM parameters: %q, got: %q", tt.wantParams, got)
+			}
+		})
+	}
+
+	t.Run("REQUIRETLS not advertised", func(t *testing.T) {
+		serverPort := TestServerPortBase + 73
+		startTLSServerNameTestServer(t, serverPort, certificate, false)
+		client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(TLSMandatory),
+			WithTLSConfig(&tls.Config{RootCAs: rootCAs, ServerName: memoryHost, MinVersion: DefaultTLSMinVersion}),
+			WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"), WithRequireTLS())
+		if err != nil {
+			t.Fatalf("unable to create new client: %s", err)
+		}
+		var sendErr *SendError
+		err = client.DialAndSend(newPoolTestMsg(t))
+		if !errors.As(err, &sendErr) || len(sendErr.errlist) != 1 ||
+			!errors.Is(sendErr.errlist[0], ErrREQUIRETLSNotSupported) {
+			t.Errorf("DialAndSend expected error: %s, got: %v", ErrREQUIRETLSNotSupported, err)
+		}
+	})
+}
+
 // TestSetSMTPAuthCustom tests the SetSMTPAuthCustom method for the Client object
 func TestSetSMTPAuthCustom(t *testing.T) {
 	tests := []struct {
@@ -883,131 +1250,1078 @@ func TestClient_DialWithContextOptionDialContextFunc(t *testing.T) {
 	}
 }
 
-// TestClient_DialSendClose tests the Dial(), Send() and Close() method of Client
-func TestClient_DialSendClose(t *testing.T) {
-	if os.Getenv("TEST_ALLOW_SEND") == "" {
-		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+// TestClient_DialAndSendWithContext_DialContextFunc tests that the context passed to DialAndSendWithContext
+// is propagated to a custom DialContextFunc
+func TestClient_DialAndSendWithContext_DialContextFunc(t *testing.T) {
+	type ctxKey struct{}
+	serverPort := TestServerPortBase + 63
+	startPoolTestServer(t, serverPort, 0)
+
+	var gotValue interface{}
+	var gotAddress string
+	var hasDeadline bool
+	dialFunc := func(ctx context.Context, network, address string) (net.Conn, error) {
+		gotValue = ctx.Value(ctxKey{})
+		gotAddress = address
+		_, hasDeadline = ctx.Deadline()
+		return (&net.Dialer{}).DialContext(ctx, network, address)
 	}
-	m := NewMsg()
-	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
-	_ = m.To(TestRcpt)
-	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
-	m.SetBulk()
-	m.SetDate()
-	m.SetMessageID()
-	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+	client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(NoTLS),
+		WithSMTPAuth(SMTPAuthPlain), WithUsername("toni@tester.com"), WithPassword("V3ryS3cr3t+"),
+		WithDialContextFunc(dialFunc))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "propagated")
+	if err = client.DialAndSendWithContext(ctx, newPoolTestMsg(t)); err != nil {
+		t.Fatalf("failed to dial and send: %s", err)
+	}
+	if gotValue != "propagated" {
+		t.Errorf("DialContextFunc expected context value to be propagated, got: %v", gotValue)
+	}
+	if !hasDeadline {
+		t.Error("DialContextFunc expected context to have a deadline")
+	}
+	if gotAddress != fmt.Sprintf("%s:%d", TestServerAddr, serverPort) {
+		t.Errorf("DialContextFunc expected address %s:%d, got: %s", TestServerAddr, serverPort, gotAddress)
+	}
+}
 
-	c, err := getTestConnection(true)
+// TestClient_DialWithContext_defaultDialer tests that the default dialer is not persisted in the Client
+func TestClient_DialWithContext_defaultDialer(t *testing.T) {
+	client, err := NewClient(TestServerAddr, WithPort(TestServerPortBase+63), WithTLSPortPolicy(NoTLS))
 	if err != nil {
-		t.Skipf("failed to create test client: %s. Skipping tests", err)
+		t.Fatalf("failed to create new client: %s", err)
 	}
+	_ = client.DialWithContext(context.Background())
+	if client.dialContextFunc != nil {
+		t.Error("DialWithContext was not supposed to store the default dialer in the Client")
+	}
+}
 
-	ctx, cfn := context.WithTimeout(context.Background(), time.Second*10)
-	defer cfn()
-	if err := c.DialWithContext(ctx); err != nil {
-		t.Errorf("Dial() failed: %s", err)
+// TestClient_DryRun tests the dry-run mode of the Client
+func TestClient_DryRun(t *testing.T) {
+	output := &strings.Builder{}
+	client, err := NewClient("invalid.host.tld", WithDryRunOutput(output),
+		WithMessageIDGenerator(func() string { return "dry.run.message.id" }))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	first := newPoolTestMsg(t)
+	first.Subject("First dry-run message")
+	second := newPoolTestMsg(t)
+	second.Subject("Second dry-run message")
+	if err = client.DialAndSend(first, second); err != nil {
+		t.Fatalf("DialAndSend in dry-run mode failed: %s", err)
+	}
+	rendered := output.String()
+	for _, want := range []string{
+		"Subject: First dry-run message", "Subject: Second dry-run message",
+		"Message-ID: <dry.run.message.id>", "Test body",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("dry-run output expected to contain %q, got: %s", want, rendered)
+		}
 	}
-	if err := c.Send(m); err != nil {
-		t.Errorf("Send() failed: %s", err)
+	if first.IsDelivered() || first.HasSendError() {
+		t.Error("dry-run message was expected to be neither delivered nor failed")
 	}
-	if err := c.Close(); err != nil {
-		t.Errorf("Close() failed: %s", err)
+
+	results, err := client.SendWithResults(first)
+	if err != nil {
+		t.Fatalf("SendWithResults in dry-run mode failed: %s", err)
 	}
-	if !m.IsDelivered() {
-		t.Errorf("message should be delivered but is indicated no to")
+	if len(results) != 1 || results[0].Err != nil || results[0].Recipient != "valid-to@domain.tld" {
+		t.Errorf("SendWithResults in dry-run mode returned unexpected results: %+v", results)
 	}
 }
 
-// TestClient_DialAndSendWithContext tests the DialAndSendWithContext() method of Client
-func TestClient_DialAndSendWithContext(t *testing.T) {
-	if os.Getenv("TEST_ALLOW_SEND") == "" {
-		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+// TestClient_DryRun_errors tests that the dry-run mode of the Client reports build errors
+func TestClient_DryRun_errors(t *testing.T) {
+	client, err := NewClient("invalid.host.tld", WithDryRun())
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
 	}
-	m := NewMsg()
-	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
-	_ = m.To(TestRcpt)
-	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
-	m.SetBulk()
-	m.SetDate()
-	m.SetMessageID()
-	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+	noSender := NewMsg()
+	if err = noSender.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	noSender.SetBodyString(TypeTextPlain, "Test body")
+	failingWriter := newPoolTestMsg(t)
+	failingWriter.SetBodyWriter(TypeTextPlain, func(io.Writer) (int64, error) {
+		return 0, errors.New("broken template")
+	})
 
 	tests := []struct {
-		name string
-		to   time.Duration
-		sf   bool
+		name    string
+		message *Msg
+		reason  SendErrReason
 	}{
-		{"Timeout: 100s", time.Second * 100, false},
-		{"Timeout: 100ms", time.Millisecond * 100, true},
+		{"no sender", noSender, ErrGetSender},
+		{"failing body writer", failingWriter, ErrWriteContent},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c, err := getTestConnection(true)
-			if err != nil {
-				t.Skipf("failed to create test client: %s. Skipping tests", err)
+			err := client.DialAndSend(tt.message)
+			var sendErr *SendError
+			if !errors.As(err, &sendErr) {
+				t.Fatalf("DialAndSend in dry-run mode expected SendError, got: %v", err)
 			}
-
-			ctx, cfn := context.WithTimeout(context.Background(), tt.to)
-			defer cfn()
-			if err := c.DialAndSendWithContext(ctx, m); err != nil && !tt.sf {
-				t.Errorf("DialAndSendWithContext() failed: %s", err)
+			if sendErr.Reason != tt.reason {
+				t.Errorf("DialAndSend in dry-run mode expected reason %q, got: %q", tt.reason, sendErr.Reason)
+			}
+			if !tt.message.HasSendError() {
+				t.Error("DialAndSend in dry-run mode expected the message to have a send error")
 			}
 		})
 	}
 }
 
-// TestClient_DialAndSend tests the DialAndSend() method of Client
-func TestClient_DialAndSend(t *testing.T) {
-	if os.Getenv("TEST_ALLOW_SEND") == "" {
-		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+// TestClient_SendWithResults_Bcc tests that Bcc recipients are part of the SMTP envelope, but the
+// Bcc header is not transmitted
+func TestClient_SendWithResults_Bcc(t *testing.T) {
+	server := []string{
+		"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n250 8BITMIME",
+		"235 2.7.0 Accepted", "250 OK", "250 OK", "250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>",
+		"250 OK: queued", "250 OK", "221 OK",
 	}
-	m := NewMsg()
-	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
-	_ = m.To(TestRcpt)
-	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
-	m.SetBulk()
-	m.SetDate()
-	m.SetMessageID()
-	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
-
-	c, err := getTestConnection(true)
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+		&wrote,
+	}
+	client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"))
 	if err != nil {
-		t.Skipf("failed to create test client: %s. Skipping tests", err)
+		t.Fatalf("unable to create new client: %s", err)
 	}
-
-	if err := c.DialAndSend(m); err != nil {
-		t.Errorf("DialAndSend() failed: %s", err)
+	message := newPoolTestMsg(t)
+	if err = message.Cc("valid-cc@domain.tld"); err != nil {
+		t.Fatalf("failed to set CC address: %s", err)
 	}
-}
-
-// TestClient_DialAndSendWithDSN tests the DialAndSend() method of Client with DSN enabled
-func TestClient_DialAndSendWithDSN(t *testing.T) {
-	if os.Getenv("TEST_ALLOW_SEND") == "" {
-		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+	if err = message.Bcc("secret-bcc@domain.tld"); err != nil {
+		t.Fatalf("failed to set BCC address: %s", err)
 	}
-	m := NewMsg()
-	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
-	_ = m.To(TestRcpt)
-	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
-	m.SetBulk()
-	m.SetDate()
-	m.SetMessageID()
-	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
-
-	c, err := getTestConnectionWithDSN(true)
+	message.SetGenHeader(Header(HeaderBcc), "generic-bcc@domain.tld")
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	results, err := client.SendWithResults(message)
 	if err != nil {
-		t.Skipf("failed to create test client: %s. Skipping tests", err)
+		t.Fatalf("SendWithResults failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
 	}
 
-	if err := c.DialAndSend(m); err != nil {
-		t.Errorf("DialAndSend() failed: %s", err)
+	var attempted []string
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("SendWithResults failed for %s: %s", result.Recipient, result.Err)
+		}
+		attempted = append(attempted, result.Recipient)
+	}
+	if !strings.Contains(strings.Join(attempted, ","), "secret-bcc@domain.tld") {
+		t.Errorf("SendWithResults expected the Bcc recipient to be attempted, got: %v", attempted)
+	}
+	transmitted := wrote.String()
+	if !strings.Contains(transmitted, "RCPT TO:<secret-bcc@domain.tld>") {
+		t.Errorf("expected the Bcc recipient in the SMTP envelope, got: %s", transmitted)
+	}
+	data := transmitted[strings.Index(transmitted, "DATA\r\n"):]
+	if strings.Contains(strings.ToLower(data), "bcc") {
+		t.Errorf("expected no Bcc header in the transmitted message, got: %s", data)
+	}
+	if !strings.Contains(data, "Cc: <valid-cc@domain.tld>") {
+		t.Errorf("expected the Cc header in the transmitted message, got: %s", data)
 	}
 }
 
-// TestClient_DialSendCloseBroken tests the Dial(), Send() and Close() method of Client with broken settings
-func TestClient_DialSendCloseBroken(t *testing.T) {
-	if os.Getenv("TEST_ALLOW_SEND") == "" {
-		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+// TestClient_SendWithResults_Pipelining tests the delivery with the PIPELINING extension
+func TestClient_SendWithResults_Pipelining(t *testing.T) {
+	server := []string{
+		"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n250-PIPELINING\r\n250 8BITMIME",
+		"235 2.7.0 Accepted", "250 OK", "250 OK", "550 5.1.1 No such user", "250 OK",
+		"354 End data with <CR><LF>.<CR><LF>", "250 OK: queued", "250 OK", "221 OK",
+	}
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+		&wrote,
+	}
+	client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+		WithPipelining())
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	message := newPoolTestMsg(t)
+	if err = message.To("one@domain.tld", "invalid@domain.tld", "three@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO addresses: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	results, err := client.SendWithResults(message)
+	if err != nil {
+		t.Fatalf("SendWithResults failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("SendWithResults expected 3 results, got: %d", len(results))
+	}
+	for _, result := range results {
+		rejected := result.Recipient == "invalid@domain.tld"
+		if rejected != (result.Err != nil) {
+			t.Errorf("SendWithResults returned unexpected result for %s: %v", result.Recipient, result.Err)
+		}
+	}
+	if !message.IsDelivered() {
+		t.Error("SendWithResults expected message to be delivered to the accepted recipients")
+	}
+	want := "MAIL FROM:<valid-from@domain.tld> BODY=8BITMIME\r\nRCPT TO:<one@domain.tld>\r\n" +
+		"RCPT TO:<invalid@domain.tld>\r\nRCPT TO:<three@domain.tld>\r\nDATA\r\n"
+	if !strings.Contains(wrote.String(), want) {
+		t.Errorf("expected pipelined commands %q, got: %q", want, wrote.String())
+	}
+}
+
+// TestClient_Send_PipeliningAbort tests that a transaction is aborted by closing the connection if a
+// recipient is rejected after the server accepted the pipelined DATA command
+func TestClient_Send_PipeliningAbort(t *testing.T) {
+	server := []string{
+		"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n250-PIPELINING\r\n250 8BITMIME",
+		"235 2.7.0 Accepted", "250 OK", "250 OK", "550 5.1.1 No such user",
+		"354 End data with <CR><LF>.<CR><LF>",
+	}
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+		&wrote,
+	}
+	client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+		WithPipelining())
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	message := newPoolTestMsg(t)
+	if err = message.To("one@domain.tld", "invalid@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO addresses: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	err = client.Send(message)
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || sendErr.Reason != ErrSMTPRcptTo {
+		t.Fatalf("Send expected SendError with reason %q, got: %v", ErrSMTPRcptTo, err)
+	}
+	aborted := false
+	for _, listErr := range sendErr.errlist {
+		if errors.Is(listErr, ErrPipelinedDataAborted) {
+			aborted = true
+		}
+	}
+	if !aborted {
+		t.Errorf("Send expected error list to contain %q, got: %v", ErrPipelinedDataAborted, sendErr.errlist)
+	}
+	if message.IsDelivered() {
+		t.Error("Send expected message not to be delivered")
+	}
+	if !strings.HasSuffix(wrote.String(), "RCPT TO:<invalid@domain.tld>\r\nDATA\r\n") {
+		t.Errorf("expected no message content after the pipelined DATA command, got: %q", wrote.String())
+	}
+}
+
+// TestClient_SendWithResults_VERP tests that each recipient is sent in a separate transaction with
+// the envelope sender computed by the VERP function
+func TestClient_SendWithResults_VERP(t *testing.T) {
+	server := []string{
+		"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n250 8BITMIME",
+		"235 2.7.0 Accepted", "250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>", "250 OK: queued",
+		"250 OK", "250 OK", "550 5.1.1 No such user", "250 OK", "221 OK",
+	}
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+		&wrote,
+	}
+	verp := func(recipient string) string {
+		return "bounces+" + strings.Replace(recipient, "@", "=", 1) + "@sender.tld"
+	}
+	client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+		WithVERP(verp))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	message := newPoolTestMsg(t)
+	if err = message.To("one@domain.tld", "invalid@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO addresses: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("unexpected dial error: %s", err)
+	}
+	results, err := client.SendWithResults(message)
+	if err != nil {
+		t.Fatalf("SendWithResults failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("SendWithResults expected 2 results, got: %d", len(results))
+	}
+	for _, result := range results {
+		if want := verp(result.Recipient); result.EnvelopeFrom != want {
+			t.Errorf("SendWithResults expected envelope from %s for %s, got: %s", want, result.Recipient,
+				result.EnvelopeFrom)
+		}
+		rejected := result.Recipient == "invalid@domain.tld"
+		if rejected != (result.Err != nil) {
+			t.Errorf("SendWithResults returned unexpected result for %s: %v", result.Recipient, result.Err)
+		}
+	}
+	if !message.IsDelivered() {
+		t.Error("SendWithResults expected message to be delivered to the accepted recipient")
+	}
+	var sendErr *SendError
+	if !errors.As(message.SendError(), &sendErr) || sendErr.Reason != ErrSMTPRcptTo {
+		t.Errorf("SendWithResults expected a RCPT TO send error, got: %v", message.SendError())
+	}
+	for _, want := range []string{
+		"MAIL FROM:<bounces+one=domain.tld@sender.tld> BODY=8BITMIME\r\nRCPT TO:<one@domain.tld>\r\nDATA\r\n",
+		"MAIL FROM:<bounces+invalid=domain.tld@sender.tld> BODY=8BITMIME\r\nRCPT TO:<invalid@domain.tld>\r\n",
+	} {
+		if !strings.Contains(wrote.String(), want) {
+			t.Errorf("expected commands %q, got: %q", want, wrote.String())
+		}
+	}
+
+	if _, err = NewClient("fake.host", WithVERP(nil)); !errors.Is(err, ErrInvalidVERPFunc) {
+		t.Errorf("WithVERP(nil) expected error: %s, got: %v", ErrInvalidVERPFunc, err)
+	}
+}
+
+// TestClient_WithAutoEncoding tests that quoted-printable body parts are sent with the 8bit encoding if
+// the server supports 8BITMIME
+func TestClient_WithAutoEncoding(t *testing.T) {
+	body := "Grüße aus Köln – ☕"
+	tests := []struct {
+		name     string
+		opts     []Option
+		body     string
+		encoding Encoding
+	}{
+		{"auto encoding", []Option{WithAutoEncoding()}, body, NoEncoding},
+		{"without auto encoding", nil, body, EncodingQP},
+		{"auto encoding with long line", []Option{WithAutoEncoding()}, strings.Repeat("ä", 500), EncodingQP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewMemoryClient(tt.opts...)
+			if err != nil {
+				t.Fatalf("NewMemoryClient failed: %s", err)
+			}
+			message := newPoolTestMsg(t)
+			message.SetBodyString(TypeTextPlain, tt.body)
+			message.AddAlternativeString(TypeTextHTML, "<p>"+tt.body+"</p>")
+			if err = client.DialAndSend(message); err != nil {
+				t.Fatalf("DialAndSend failed: %s", err)
+			}
+			messages := client.Messages()
+			if len(messages) != 1 {
+				t.Fatalf("DialAndSend expected 1 message, got: %d", len(messages))
+			}
+			data := string(messages[0].Data)
+			header := fmt.Sprintf("Content-Transfer-Encoding: %s\r\n", tt.encoding)
+			if strings.Count(data, header) != 2 {
+				t.Errorf("DialAndSend expected both parts with %s encoding, got: %s", tt.encoding, data)
+			}
+			if tt.encoding == NoEncoding && !strings.Contains(data, "\r\n\r\n"+tt.body+"\r\n") {
+				t.Errorf("DialAndSend expected the unencoded body, got: %s", data)
+			}
+			if tt.encoding == EncodingQP && strings.Contains(data, tt.body) {
+				t.Errorf("DialAndSend expected the body to be quoted-printable encoded, got: %s", data)
+			}
+			for _, part := range message.GetParts() {
+				if part.GetEncoding() != EncodingQP {
+					t.Errorf("DialAndSend must not change the encoding of the Msg parts, got: %s", part.GetEncoding())
+				}
+			}
+		})
+	}
+
+	t.Run("server without 8BITMIME", func(t *testing.T) {
+		server := []string{
+			"220 Fake server ready ESMTP", "250-fake.server\r\n250 AUTH XOAUTH2", "235 2.7.0 Accepted",
+			"250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>", "250 OK: queued", "250 OK", "221 OK",
+		}
+		var wrote strings.Builder
+		var fake faker
+		fake.ReadWriter = struct {
+			io.Reader
+			io.Writer
+		}{
+			strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+			&wrote,
+		}
+		client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+			WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+			WithAutoEncoding())
+		if err != nil {
+			t.Fatalf("unable to create new client: %s", err)
+		}
+		message := newPoolTestMsg(t)
+		message.SetBodyString(TypeTextPlain, body)
+		if err = client.DialAndSend(message); err != nil {
+			t.Fatalf("DialAndSend failed: %s", err)
+		}
+		if !strings.Contains(wrote.String(), "Content-Transfer-Encoding: quoted-printable\r\n") {
+			t.Errorf("DialAndSend expected quoted-printable encoding, got: %s", wrote.String())
+		}
+	})
+}
+
+// TestClient_WithChunking tests that the WithChunking option transfers the message content with the BDAT
+// command if the server supports CHUNKING and falls back to the DATA command otherwise
+func TestClient_WithChunking(t *testing.T) {
+	t.Run("in-memory server", func(t *testing.T) {
+		client, err := NewMemoryClient(WithChunking())
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		message := newPoolTestMsg(t)
+		message.SetBoundary("chunking-test-boundary")
+		message.SetBodyString(TypeTextPlain, ".leading dot\r\n"+strings.Repeat("Large body. ", 20000))
+		binary := bytes.Repeat([]byte{0, '\r', '.', 0xff}, 50000)
+		if err = message.AttachReader("binary.bin", bytes.NewReader(binary)); err != nil {
+			t.Fatalf("failed to attach file: %s", err)
+		}
+		if err = client.DialAndSend(message); err != nil {
+			t.Fatalf("DialAndSend failed: %s", err)
+		}
+		messages := client.Messages()
+		if len(messages) != 1 {
+			t.Fatalf("DialAndSend expected 1 message, got: %d", len(messages))
+		}
+		var want bytes.Buffer
+		if _, err = message.WriteTo(&want); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+		if !bytes.Equal(messages[0].Data, want.Bytes()) {
+			t.Errorf("DialAndSend expected the transferred content to match the message")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		ehlo    string
+		replies []string
+		want    string
+	}{
+		{
+			"server with CHUNKING", "250 CHUNKING", []string{"250 OK: queued"}, "\r\nBDAT ",
+		},
+		{
+			"server without CHUNKING", "250 8BITMIME",
+			[]string{"354 End data with <CR><LF>.<CR><LF>", "250 OK: queued"}, "\r\nDATA\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := []string{
+				"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n" + tt.ehlo,
+				"235 2.7.0 Accepted", "250 OK", "250 OK",
+			}
+			server = append(append(server, tt.replies...), "250 OK", "221 OK")
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+				WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+				WithPassword("token"), WithChunking())
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			if err = client.DialAndSend(newPoolTestMsg(t)); err != nil {
+				t.Fatalf("DialAndSend failed: %s", err)
+			}
+			if !strings.Contains(wrote.String(), tt.want) {
+				t.Errorf("DialAndSend expected %q to be sent, got: %s", tt.want, wrote.String())
+			}
+		})
+	}
+}
+
+// TestCrlfWriter tests that the crlfWriter converts bare LF line endings to CRLF, also across writes
+func TestCrlfWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := &crlfWriter{WriteCloser: struct {
+		io.Writer
+		io.Closer
+	}{&buffer, io.NopCloser(nil)}}
+	for _, payload := range []string{"bare\nline feed\r", "\nsplit CRLF\n", "\nleading LF\x00\xff"} {
+		if n, err := writer.Write([]byte(payload)); err != nil || n != len(payload) {
+			t.Fatalf("Write(%q) failed: %d, %v", payload, n, err)
+		}
+	}
+	want := "bare\r\nline feed\r\nsplit CRLF\r\n\r\nleading LF\x00\xff"
+	if buffer.String() != want {
+		t.Errorf("crlfWriter failed. Expected: %q, got: %q", want, buffer.String())
+	}
+}
+
+// TestClient_WithMaxMessageSize tests that the Client does not send messages that exceed the maximum
+// message size set via WithMaxMessageSize
+func TestClient_WithMaxMessageSize(t *testing.T) {
+	if _, err := NewClient(DefaultHost, WithMaxMessageSize(0)); !errors.Is(err, ErrInvalidMaxMessageSize) {
+		t.Errorf("WithMaxMessageSize(0) expected error: %s, got: %v", ErrInvalidMaxMessageSize, err)
+	}
+	client, err := NewMemoryClient(WithMaxMessageSize(1024))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	message := newPoolTestMsg(t)
+	message.SetBodyString(TypeTextPlain, strings.Repeat("Too large. ", 100))
+	if err = client.DialAndSend(newPoolTestMsg(t), message); err == nil {
+		t.Fatal("DialAndSend expected an error for the oversized message")
+	}
+	var sendErr *SendError
+	if !errors.As(message.SendError(), &sendErr) || sendErr.Reason != ErrMessageTooLarge || sendErr.IsTemp() {
+		t.Errorf("DialAndSend expected permanent message size send error, got: %v", message.SendError())
+	}
+	if len(client.Messages()) != 1 {
+		t.Errorf("DialAndSend expected only the small message to be delivered, got: %d", len(client.Messages()))
+	}
+
+	// The message must be rendered only once for the size check and the transmission
+	counter := &countingMiddleware{}
+	message = newPoolTestMsg(t)
+	message.middlewares = []Middleware{counter}
+	if err = client.DialAndSend(message); err != nil {
+		t.Fatalf("DialAndSend failed: %s", err)
+	}
+	if counter.calls != 1 {
+		t.Errorf("DialAndSend expected the message to be rendered once, got %d renders", counter.calls)
+	}
+}
+
+// countingMiddleware is a Middleware that counts how often it is applied
+type countingMiddleware struct {
+	calls int
+}
+
+func (mw *countingMiddleware) Handle(m *Msg) *Msg {
+	mw.calls++
+	return m
+}
+
+func (mw *countingMiddleware) Type() MiddlewareType {
+	return "counting"
+}
+
+// TestClient_WithSizeCheck tests that the Client checks the message size against the SIZE limit of the
+// server and declares the size in the MAIL FROM command
+func TestClient_WithSizeCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		ehlo     string
+		replies  []string
+		wantErr  bool
+		wantSize bool
+	}{
+		{
+			"message within limit", "250 SIZE 1000000",
+			[]string{"250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>", "250 OK: queued", "250 OK"},
+			false, true,
+		},
+		{"message exceeds limit", "250 SIZE 100", nil, true, false},
+		{
+			"server without limit", "250 SIZE",
+			[]string{"250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>", "250 OK: queued", "250 OK"},
+			false, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := []string{
+				"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n" + tt.ehlo,
+				"235 2.7.0 Accepted",
+			}
+			server = append(append(server, tt.replies...), "221 OK")
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+				WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+				WithPassword("token"), WithSizeCheck())
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			message := newPoolTestMsg(t)
+			if err = client.DialWithContext(context.Background()); err != nil {
+				t.Fatalf("unexpected dial error: %s", err)
+			}
+			err = client.Send(message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send returned unexpected error: %v", err)
+			}
+			if err = client.Close(); err != nil {
+				t.Fatalf("failed to close connection: %s", err)
+			}
+			var sendErr *SendError
+			if tt.wantErr && (!errors.As(message.SendError(), &sendErr) || sendErr.Reason != ErrMessageTooLarge) {
+				t.Errorf("Send expected message size send error, got: %v", message.SendError())
+			}
+			if tt.wantErr && strings.Contains(wrote.String(), "MAIL FROM") {
+				t.Errorf("Send expected no transaction for the oversized message, got: %s", wrote.String())
+			}
+			if tt.wantSize {
+				var rendered bytes.Buffer
+				if _, err = message.WriteTo(&rendered); err != nil {
+					t.Fatalf("failed to write message: %s", err)
+				}
+				want := fmt.Sprintf("MAIL FROM:<valid-from@domain.tld> SIZE=%d\r\n", rendered.Len())
+				if !strings.Contains(wrote.String(), want) {
+					t.Errorf("Send expected %q, got: %s", want, wrote.String())
+				}
+			}
+		})
+	}
+}
+
+// TestClient_WithMailParams tests that the parameters set via WithMailParams and WithRcptParams are
+// appended to the MAIL FROM and RCPT TO commands
+func TestClient_WithMailParams(t *testing.T) {
+	for _, param := range []string{"", "AUTH=<> RET=FULL", "ENVID=1\r\nDATA"} {
+		if _, err := NewClient(DefaultHost, WithMailParams(param)); !errors.Is(err, ErrInvalidSMTPParam) {
+			t.Errorf("WithMailParams(%q) expected error: %s, got: %v", param, ErrInvalidSMTPParam, err)
+		}
+		if _, err := NewClient(DefaultHost, WithRcptParams(param)); !errors.Is(err, ErrInvalidSMTPParam) {
+			t.Errorf("WithRcptParams(%q) expected error: %s, got: %v", param, ErrInvalidSMTPParam, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ehlo     string
+		wantMail string
+		wantRcpt string
+	}{
+		{
+			"server with DSN", "250 DSN",
+			"MAIL FROM:<valid-from@domain.tld> RET=FULL AUTH=<>\r\n",
+			"RCPT TO:<valid-to@domain.tld> NOTIFY=FAILURE,SUCCESS ORCPT=rfc822;valid-to@domain.tld\r\n",
+		},
+		{
+			"server without DSN", "250 8BITMIME",
+			"MAIL FROM:<valid-from@domain.tld> BODY=8BITMIME AUTH=<>\r\n",
+			"RCPT TO:<valid-to@domain.tld> ORCPT=rfc822;valid-to@domain.tld\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := []string{
+				"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n" + tt.ehlo,
+				"235 2.7.0 Accepted", "250 OK", "250 OK", "354 End data with <CR><LF>.<CR><LF>", "250 OK: queued",
+				"250 OK", "221 OK",
+			}
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)), WithoutNoop(),
+				WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+				WithPassword("token"), WithDSN(), WithMailParams("AUTH=<>"),
+				WithRcptParams("ORCPT=rfc822;valid-to@domain.tld"))
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			if err = client.DialAndSend(newPoolTestMsg(t)); err != nil {
+				t.Fatalf("DialAndSend failed: %s", err)
+			}
+			if !strings.Contains(wrote.String(), tt.wantMail+tt.wantRcpt) {
+				t.Errorf("DialAndSend expected %q to be sent, got: %s", tt.wantMail+tt.wantRcpt, wrote.String())
+			}
+		})
+	}
+}
+
+// TestClient_WithRetry tests that the Client retries only the recipients that failed with a temporary
+// TestClient_Dial_reuse tests that a single connection opened via Client.Dial can be reused for many sends,
+// also from concurrent goroutines, and that WithReconnect dials the server again after the connection broke
+func TestClient_Dial_reuse(t *testing.T) {
+	t.Run("concurrent sends on one connection", func(t *testing.T) {
+		client, err := NewMemoryClient()
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		if err = client.Dial(); err != nil {
+			t.Fatalf("Dial failed: %s", err)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := client.Send(newPoolTestMsg(t)); err != nil {
+					t.Errorf("Send failed: %s", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if err = client.Close(); err != nil {
+			t.Errorf("Close failed: %s", err)
+		}
+		if len(client.Messages()) != 10 {
+			t.Errorf("Send failed. Expected 10 delivered messages, got: %d", len(client.Messages()))
+		}
+	})
+	t.Run("broken connection without reconnect", func(t *testing.T) {
+		client, err := NewMemoryClient()
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		if err = client.Dial(); err != nil {
+			t.Fatalf("Dial failed: %s", err)
+		}
+		_ = client.connection.Close()
+		if err = client.Send(newPoolTestMsg(t)); !errors.Is(err, &SendError{Reason: ErrConnCheck}) {
+			t.Errorf("Send expected connection check error, got: %v", err)
+		}
+	})
+	t.Run("broken connection with reconnect", func(t *testing.T) {
+		client, err := NewMemoryClient(WithReconnect())
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		if err = client.Dial(); err != nil {
+			t.Fatalf("Dial failed: %s", err)
+		}
+		if err = client.Send(newPoolTestMsg(t)); err != nil {
+			t.Fatalf("Send failed: %s", err)
+		}
+		_ = client.connection.Close()
+		if err = client.Send(newPoolTestMsg(t), newPoolTestMsg(t)); err != nil {
+			t.Fatalf("Send failed after the connection broke: %s", err)
+		}
+		if err = client.Close(); err != nil {
+			t.Errorf("Close failed: %s", err)
+		}
+		if len(client.Messages()) != 3 {
+			t.Errorf("Send failed. Expected 3 delivered messages, got: %d", len(client.Messages()))
+		}
+	})
+	t.Run("connection breaks during send with reconnect", func(t *testing.T) {
+		client, err := NewMemoryClient(WithReconnect(), WithoutNoop())
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		var connection *breakingConn
+		client.dialContextFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+			memoryConn, err := client.dialContext(ctx, network, address)
+			connection = &breakingConn{Conn: memoryConn}
+			return connection, err
+		}
+		if err = client.Dial(); err != nil {
+			t.Fatalf("Dial failed: %s", err)
+		}
+		connection.broken = true
+		failed, delivered := newPoolTestMsg(t), newPoolTestMsg(t)
+		if err = client.Send(failed, delivered); err == nil {
+			t.Fatal("Send expected error for the message on the broken connection, got nil")
+		}
+		if !failed.HasSendError() || !delivered.IsDelivered() {
+			t.Errorf("Send failed. Expected the message after the broken connection to be delivered: %v",
+				delivered.SendError())
+		}
+		if len(client.Messages()) != 1 {
+			t.Errorf("Send failed. Expected 1 delivered message, got: %d", len(client.Messages()))
+		}
+	})
+}
+
+// error and reports the final outcome for each recipient
+func TestClient_WithRetry(t *testing.T) {
+	if _, err := NewClient(DefaultHost, WithRetry(0, nil)); !errors.Is(err, ErrInvalidRetryAttempts) {
+		t.Errorf("WithRetry(0) expected error: %s, got: %v", ErrInvalidRetryAttempts, err)
+	}
+
+	t.Run("temporary recipient failure is retried", func(t *testing.T) {
+		var client *MemoryClient
+		var attempts []int
+		backoff := func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			client.SetRcptResponse("temp-fail@domain.tld", 250, "2.1.5 OK")
+			return time.Millisecond
+		}
+		var err error
+		client, err = NewMemoryClient(WithRetry(3, backoff))
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		client.SetRcptResponse("temp-fail@domain.tld", 450, "4.2.1 Mailbox busy")
+		client.SetRcptResponse("invalid@domain.tld", 550, "5.1.1 No such user")
+		message := newPoolTestMsg(t)
+		if err = message.To("valid-to@domain.tld", "temp-fail@domain.tld", "invalid@domain.tld"); err != nil {
+			t.Fatalf("failed to set TO addresses: %s", err)
+		}
+		if err = client.DialWithContext(context.Background()); err != nil {
+			t.Fatalf("failed to dial: %s", err)
+		}
+		results, err := client.SendWithResults(message)
+		if err != nil {
+			t.Fatalf("SendWithResults failed: %s", err)
+		}
+		if err = client.Close(); err != nil {
+			t.Fatalf("failed to close connection: %s", err)
+		}
+		if len(attempts) != 1 || attempts[0] != 1 {
+			t.Errorf("SendWithResults expected a single retry, got backoff calls: %v", attempts)
+		}
+		if len(results) != 3 {
+			t.Fatalf("SendWithResults expected 3 results, got: %d", len(results))
+		}
+		for _, result := range results {
+			switch result.Recipient {
+			case "invalid@domain.tld":
+				var sendErr *SendError
+				if !errors.As(result.Err, &sendErr) || sendErr.IsTemp() || sendErr.ErrorCode() != 550 {
+					t.Errorf("SendWithResults expected permanent error for %s, got: %v", result.Recipient, result.Err)
+				}
+			default:
+				if result.Err != nil {
+					t.Errorf("SendWithResults expected no error for %s, got: %s", result.Recipient, result.Err)
+				}
+			}
+		}
+		messages := client.Messages()
+		if len(messages) != 2 {
+			t.Fatalf("SendWithResults expected 2 delivered messages, got: %d", len(messages))
+		}
+		if strings.Join(messages[1].Recipients, ",") != "temp-fail@domain.tld" {
+			t.Errorf("SendWithResults expected only the failed recipient to be retried, got: %v",
+				messages[1].Recipients)
+		}
+		var sendErr *SendError
+		if !errors.As(message.SendError(), &sendErr) || sendErr.Reason != ErrSMTPRcptTo {
+			t.Fatalf("SendWithResults expected RCPT TO send error, got: %v", message.SendError())
+		}
+		if len(sendErr.Recipients()) != 1 || sendErr.Recipients()[0] != "invalid@domain.tld" {
+			t.Errorf("SendWithResults expected only the permanent failure, got: %v", sendErr.Recipients())
+		}
+	})
+	t.Run("permanent failure is not retried", func(t *testing.T) {
+		calls := 0
+		client, err := NewMemoryClient(WithRetry(3, func(int) time.Duration {
+			calls++
+			return 0
+		}))
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		client.SetRcptResponse("temp-fail@domain.tld", 450, "4.2.1 Mailbox busy")
+		client.SetRcptResponse("invalid@domain.tld", 550, "5.1.1 No such user")
+		message := newPoolTestMsg(t)
+		if err = message.To("temp-fail@domain.tld", "invalid@domain.tld"); err != nil {
+			t.Fatalf("failed to set TO addresses: %s", err)
+		}
+		if err = client.DialAndSend(message); err == nil {
+			t.Fatal("DialAndSend expected error for rejected recipients, got nil")
+		}
+		if calls != 0 {
+			t.Errorf("DialAndSend expected no retry, got %d backoff calls", calls)
+		}
+	})
+	t.Run("attempts are limited", func(t *testing.T) {
+		var attempts []int
+		client, err := NewMemoryClient(WithRetry(3, func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return 0
+		}))
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		client.SetRcptResponse("valid-to@domain.tld", 451, "4.3.0 Try again later")
+		message := newPoolTestMsg(t)
+		err = client.DialAndSend(message)
+		var sendErr *SendError
+		if !errors.As(err, &sendErr) || !sendErr.IsTemp() || sendErr.ErrorCode() != 451 {
+			t.Errorf("DialAndSend expected temporary send error, got: %v", err)
+		}
+		if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+			t.Errorf("DialAndSend expected 2 retries, got backoff calls: %v", attempts)
+		}
+		if message.IsDelivered() || len(client.Messages()) != 0 {
+			t.Error("DialAndSend expected the message not to be delivered")
+		}
+	})
+}
+
+// TestClient_DialSendClose tests the Dial(), Send() and Close() method of Client
+func TestClient_DialSendClose(t *testing.T) {
+	if os.Getenv("TEST_ALLOW_SEND") == "" {
+		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+	}
+	m := NewMsg()
+	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
+	_ = m.To(TestRcpt)
+	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
+	m.SetBulk()
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+
+	c, err := getTestConnection(true)
+	if err != nil {
+		t.Skipf("failed to create test client: %s. Skipping tests", err)
+	}
+
+	ctx, cfn := context.WithTimeout(context.Background(), time.Second*10)
+	defer cfn()
+	if err := c.DialWithContext(ctx); err != nil {
+		t.Errorf("Dial() failed: %s", err)
+	}
+	if err := c.Send(m); err != nil {
+		t.Errorf("Send() failed: %s", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() failed: %s", err)
+	}
+	if !m.IsDelivered() {
+		t.Errorf("message should be delivered but is indicated no to")
+	}
+}
+
+// TestClient_DialAndSendWithContext tests the DialAndSendWithContext() method of Client
+func TestClient_DialAndSendWithContext(t *testing.T) {
+	if os.Getenv("TEST_ALLOW_SEND") == "" {
+		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+	}
+	m := NewMsg()
+	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
+	_ = m.To(TestRcpt)
+	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
+	m.SetBulk()
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+
+	tests := []struct {
+		name string
+		to   time.Duration
+		sf   bool
+	}{
+		{"Timeout: 100s", time.Second * 100, false},
+		{"Timeout: 100ms", time.Millisecond * 100, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := getTestConnection(true)
+			if err != nil {
+				t.Skipf("failed to create test client: %s. Skipping tests", err)
+			}
+
+			ctx, cfn := context.WithTimeout(context.Background(), tt.to)
+			defer cfn()
+			if err := c.DialAndSendWithContext(ctx, m); err != nil && !tt.sf {
+				t.Errorf("DialAndSendWithContext() failed: %s", err)
+			}
+		})
+	}
+}
+
+// TestClient_DialAndSend tests the DialAndSend() method of Client
+func TestClient_DialAndSend(t *testing.T) {
+	if os.Getenv("TEST_ALLOW_SEND") == "" {
+		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+	}
+	m := NewMsg()
+	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
+	_ = m.To(TestRcpt)
+	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
+	m.SetBulk()
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+
+	c, err := getTestConnection(true)
+	if err != nil {
+		t.Skipf("failed to create test client: %s. Skipping tests", err)
+	}
+
+	if err := c.DialAndSend(m); err != nil {
+		t.Errorf("DialAndSend() failed: %s", err)
+	}
+}
+
+// TestClient_DialAndSendWithDSN tests the DialAndSend() method of Client with DSN enabled
+func TestClient_DialAndSendWithDSN(t *testing.T) {
+	if os.Getenv("TEST_ALLOW_SEND") == "" {
+		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
+	}
+	m := NewMsg()
+	_ = m.FromFormat("go-mail Test Mailer", os.Getenv("TEST_FROM"))
+	_ = m.To(TestRcpt)
+	m.Subject(fmt.Sprintf("This is a test mail from go-mail/v%s", VERSION))
+	m.SetBulk()
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "This is a test mail from the go-mail library")
+
+	c, err := getTestConnectionWithDSN(true)
+	if err != nil {
+		t.Skipf("failed to create test client: %s. Skipping tests", err)
+	}
+
+	if err := c.DialAndSend(m); err != nil {
+		t.Errorf("DialAndSend() failed: %s", err)
+	}
+}
+
+// TestClient_DialSendCloseBroken tests the Dial(), Send() and Close() method of Client with broken settings
+func TestClient_DialSendCloseBroken(t *testing.T) {
+	if os.Getenv("TEST_ALLOW_SEND") == "" {
+		t.Skipf("TEST_ALLOW_SEND is not set. Skipping mail sending test")
 	}
 	tests := []struct {
 		name       string
@@ -1190,7 +2504,7 @@ func TestClient_auth(t *testing.T) {
 			c.SetSMTPAuth(tt.auth)
 			c.SetUsername(os.Getenv("TEST_SMTPAUTH_USER"))
 			c.SetPassword(os.Getenv("TEST_SMTPAUTH_PASS"))
-			if err := c.auth(); err != nil && !tt.sf {
+			if err := c.auth(context.Background()); err != nil && !tt.sf {
 				t.Errorf("auth() failed: %s", err)
 			}
 			if err := c.Close(); err != nil {
@@ -1422,6 +2736,381 @@ func TestClient_SendErrorMailFrom(t *testing.T) {
 	}
 }
 
+func TestClient_SendErrorRcptToMulti(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverPort := TestServerPortBase + 53
+	featureSet := "250-AUTH PLAIN\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8"
+	go func() {
+		if err := simpleSMTPServer(ctx, featureSet, false, serverPort); err != nil {
+			t.Errorf("failed to start test server: %s", err)
+			return
+		}
+	}()
+	time.Sleep(time.Millisecond * 300)
+
+	message := NewMsg()
+	if err := message.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := message.To("valid-to@domain.tld", "temp-fail-to@domain.tld", "invalid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	message.Subject("Test subject")
+	message.SetBodyString(TypeTextPlain, "Test body")
+
+	client, err := NewClient(TestServerAddr, WithPort(serverPort),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthPlain),
+		WithUsername("toni@tester.com"),
+		WithPassword("V3ryS3cr3t+"))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("failed to dial to test server: %s", err)
+	}
+	if err = client.Send(message); err == nil {
+		t.Fatal("expected Send() to fail but didn't")
+	}
+
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) {
+		t.Fatalf("expected *SendError type as returned error, but got %T", err)
+	}
+	if sendErr.Reason != ErrSMTPRcptTo {
+		t.Errorf("expected ErrSMTPRcptTo error, but got %s", sendErr.Reason)
+	}
+	if len(sendErr.Recipients()) != 2 {
+		t.Errorf("expected 2 affected recipients, got: %d", len(sendErr.Recipients()))
+	}
+	rcptErrs := sendErr.RecipientErrors()
+	if len(rcptErrs) != 2 {
+		t.Fatalf("expected 2 recipient errors, got: %d", len(rcptErrs))
+	}
+	tests := []struct {
+		rcpt   string
+		code   int
+		isTemp bool
+	}{
+		{"temp-fail-to@domain.tld", 450, true},
+		{"invalid-to@domain.tld", 500, false},
+	}
+	for i, tt := range tests {
+		if len(rcptErrs[i].Recipients()) != 1 || rcptErrs[i].Recipients()[0] != tt.rcpt {
+			t.Errorf("expected recipient %s, got: %v", tt.rcpt, rcptErrs[i].Recipients())
+		}
+		if rcptErrs[i].ErrorCode() != tt.code {
+			t.Errorf("expected error code %d for %s, got: %d", tt.code, tt.rcpt, rcptErrs[i].ErrorCode())
+		}
+		if rcptErrs[i].IsTemp() != tt.isTemp {
+			t.Errorf("expected temporary state %t for %s, got: %t", tt.isTemp, tt.rcpt, rcptErrs[i].IsTemp())
+		}
+		if rcptErrs[i].Reason != ErrSMTPRcptTo {
+			t.Errorf("expected ErrSMTPRcptTo error for %s, but got %s", tt.rcpt, rcptErrs[i].Reason)
+		}
+	}
+
+	if err = client.Close(); err != nil {
+		t.Errorf("failed to close server connection: %s", err)
+	}
+}
+
+func TestClient_SendWithResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverPort := TestServerPortBase + 54
+	featureSet := "250-AUTH PLAIN\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8"
+	go func() {
+		if err := simpleSMTPServer(ctx, featureSet, false, serverPort); err != nil {
+			t.Errorf("failed to start test server: %s", err)
+			return
+		}
+	}()
+	time.Sleep(time.Millisecond * 300)
+
+	partial := NewMsg()
+	if err := partial.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := partial.To("valid-to@domain.tld", "temp-fail-to@domain.tld", "invalid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	partial.Subject("Test subject")
+	partial.SetBodyString(TypeTextPlain, "Test body")
+
+	failed := NewMsg()
+	if err := failed.From("invalid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := failed.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	failed.Subject("Test subject")
+	failed.SetBodyString(TypeTextPlain, "Test body")
+
+	client, err := NewClient(TestServerAddr, WithPort(serverPort),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthPlain),
+		WithUsername("toni@tester.com"),
+		WithPassword("V3ryS3cr3t+"))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("failed to dial to test server: %s", err)
+	}
+	results, err := client.SendWithResults(partial, failed)
+	if err != nil {
+		t.Fatalf("SendWithResults() failed: %s", err)
+	}
+	tests := []struct {
+		msg     *Msg
+		rcpt    string
+		success bool
+		reason  SendErrReason
+	}{
+		{partial, "temp-fail-to@domain.tld", false, ErrSMTPRcptTo},
+		{partial, "invalid-to@domain.tld", false, ErrSMTPRcptTo},
+		{partial, "valid-to@domain.tld", true, 0},
+		{failed, "valid-to@domain.tld", false, ErrSMTPMailFrom},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("SendWithResults() failed. Expected %d results, got: %d", len(tests), len(results))
+	}
+	for i, tt := range tests {
+		result := results[i]
+		if result.Msg != tt.msg {
+			t.Errorf("SendWithResults() failed. Unexpected message for result %d", i)
+		}
+		if result.Recipient != tt.rcpt {
+			t.Errorf("SendWithResults() failed. Expected recipient: %s, got: %s", tt.rcpt, result.Recipient)
+		}
+		if tt.success {
+			if result.Err != nil {
+				t.Errorf("SendWithResults() failed. Expected success for %s, got: %s", tt.rcpt, result.Err)
+			}
+			continue
+		}
+		var sendErr *SendError
+		if !errors.As(result.Err, &sendErr) {
+			t.Errorf("SendWithResults() failed. Expected *SendError for %s, got: %T", tt.rcpt, result.Err)
+			continue
+		}
+		if sendErr.Reason != tt.reason {
+			t.Errorf("SendWithResults() failed. Expected reason %s for %s, got: %s", tt.reason, tt.rcpt,
+				sendErr.Reason)
+		}
+	}
+	if !partial.IsDelivered() {
+		t.Error("SendWithResults() failed. Expected partially accepted message to be delivered")
+	}
+	if !partial.HasSendError() || !failed.HasSendError() {
+		t.Error("SendWithResults() failed. Expected messages to hold a send error")
+	}
+	if failed.IsDelivered() {
+		t.Error("SendWithResults() failed. Expected rejected message not to be delivered")
+	}
+
+	if err = client.Close(); err != nil {
+		t.Errorf("failed to close server connection: %s", err)
+	}
+}
+
+// TestClient_SendWithMessageIDGenerator tests that the Client applies the Message-ID generator
+// only to messages without a Message-ID
+func TestClient_SendWithMessageIDGenerator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverPort := TestServerPortBase + 55
+	featureSet := "250-AUTH PLAIN\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8"
+	go func() {
+		if err := simpleSMTPServer(ctx, featureSet, false, serverPort); err != nil {
+			t.Errorf("failed to start test server: %s", err)
+			return
+		}
+	}()
+	time.Sleep(time.Millisecond * 300)
+
+	generated := NewMsg()
+	explicit := NewMsg()
+	explicit.SetMessageIDWithValue("explicit@domain.tld")
+	for _, message := range []*Msg{generated, explicit} {
+		if err := message.From("valid-from@domain.tld"); err != nil {
+			t.Fatalf("failed to set FROM address: %s", err)
+		}
+		if err := message.To("valid-to@domain.tld"); err != nil {
+			t.Fatalf("failed to set TO address: %s", err)
+		}
+		message.Subject("Test subject")
+		message.SetBodyString(TypeTextPlain, "Test body")
+	}
+
+	client, err := NewClient(TestServerAddr, WithPort(serverPort),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthPlain),
+		WithUsername("toni@tester.com"),
+		WithPassword("V3ryS3cr3t+"),
+		WithMessageIDGenerator(func() string { return "generated@tracking.domain.tld" }))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialAndSend(generated, explicit); err != nil {
+		t.Fatalf("failed to send messages: %s", err)
+	}
+	if generated.GetMessageID() != "<generated@tracking.domain.tld>" {
+		t.Errorf("WithMessageIDGenerator failed. Expected Message-ID: %s, got: %s",
+			"<generated@tracking.domain.tld>", generated.GetMessageID())
+	}
+	if explicit.GetMessageID() != "<explicit@domain.tld>" {
+		t.Errorf("WithMessageIDGenerator failed. Expected Message-ID: %s, got: %s", "<explicit@domain.tld>",
+			explicit.GetMessageID())
+	}
+}
+
+// TestClient_DialAndSendWithContext_stallingServer tests that the context of DialAndSendWithContext
+// aborts a stalled SMTP conversation in the different phases and closes the connection
+func TestClient_DialAndSendWithContext_stallingServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		portOffset int
+		stallAt    string
+	}{
+		{"stall after EHLO", 56, "AUTH"},
+		{"stall during DATA", 57, "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverPort := TestServerPortBase + tt.portOffset
+			closed := make(chan struct{})
+			listener, err := net.Listen(TestServerProto, fmt.Sprintf("%s:%d", TestServerAddr, serverPort))
+			if err != nil {
+				t.Fatalf("unable to listen on %s:%d: %s", TestServerAddr, serverPort, err)
+			}
+			defer func() { _ = listener.Close() }()
+			go stallingSMTPServer(listener, tt.stallAt, closed)
+
+			message := NewMsg()
+			if err = message.From("valid-from@domain.tld"); err != nil {
+				t.Fatalf("failed to set FROM address: %s", err)
+			}
+			if err = message.To("valid-to@domain.tld"); err != nil {
+				t.Fatalf("failed to set TO address: %s", err)
+			}
+			message.SetBodyString(TypeTextPlain, "Test body")
+
+			client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(NoTLS),
+				WithSMTPAuth(SMTPAuthPlain), WithUsername("toni@tester.com"), WithPassword("V3ryS3cr3t+"),
+				WithTimeout(time.Minute))
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+			defer cancel()
+			start := time.Now()
+			err = client.DialAndSendWithContext(ctx, message)
+			if err == nil {
+				t.Fatal("DialAndSendWithContext with stalling server was supposed to fail, but didn't")
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("DialAndSendWithContext expected error to wrap %s, got: %s", context.DeadlineExceeded, err)
+			}
+			var sendErr *SendError
+			if tt.stallAt == "." && !errors.As(err, &sendErr) {
+				t.Errorf("DialAndSendWithContext expected error to wrap a SendError, got: %s", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second*5 {
+				t.Errorf("DialAndSendWithContext did not honor the context deadline, took: %s", elapsed)
+			}
+			select {
+			case <-closed:
+			case <-time.After(time.Second * 5):
+				t.Error("DialAndSendWithContext did not close the connection to the stalling server")
+			}
+		})
+	}
+}
+
+// TestClient_phaseTimeouts tests the WithConnectTimeout, WithCommandTimeout and WithDataTimeout options
+func TestClient_phaseTimeouts(t *testing.T) {
+	for name, option := range map[string]func(time.Duration) Option{
+		"WithConnectTimeout": WithConnectTimeout, "WithCommandTimeout": WithCommandTimeout,
+		"WithDataTimeout": WithDataTimeout,
+	} {
+		if _, err := NewClient(DefaultHost, option(0)); !errors.Is(err, ErrInvalidTimeout) {
+			t.Errorf("%s with zero timeout expected error: %s, got: %v", name, ErrInvalidTimeout, err)
+		}
+	}
+	client, err := NewClient(DefaultHost, WithConnectTimeout(time.Second), WithCommandTimeout(time.Second*2),
+		WithDataTimeout(time.Second*3))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	if client.connectTimeout != time.Second || client.commandTimeout != time.Second*2 ||
+		client.dataTimeout != time.Second*3 || client.connTimeout != DefaultTimeout {
+		t.Errorf("failed to set phase timeouts. Got connect: %s, command: %s, data: %s, default: %s",
+			client.connectTimeout, client.commandTimeout, client.dataTimeout, client.connTimeout)
+	}
+
+	tests := []struct {
+		name       string
+		portOffset int
+		stallAt    string
+		option     Option
+	}{
+		{"connect timeout on greeting", 65, "", WithConnectTimeout(time.Millisecond * 200)},
+		{"command timeout on MAIL FROM", 66, "MAIL FROM", WithCommandTimeout(time.Millisecond * 200)},
+		{"data timeout on end of data", 67, ".", WithDataTimeout(time.Millisecond * 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverPort := TestServerPortBase + tt.portOffset
+			closed := make(chan struct{})
+			listener, err := net.Listen(TestServerProto, fmt.Sprintf("%s:%d", TestServerAddr, serverPort))
+			if err != nil {
+				t.Fatalf("unable to listen on %s:%d: %s", TestServerAddr, serverPort, err)
+			}
+			defer func() { _ = listener.Close() }()
+			if tt.stallAt == "" {
+				go func() {
+					defer close(closed)
+					connection, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					_, _ = io.Copy(io.Discard, connection)
+					_ = connection.Close()
+				}()
+			} else {
+				go stallingSMTPServer(listener, tt.stallAt, closed)
+			}
+
+			message := newPoolTestMsg(t)
+			client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(NoTLS),
+				WithSMTPAuth(SMTPAuthPlain), WithUsername("toni@tester.com"), WithPassword("V3ryS3cr3t+"),
+				WithTimeout(time.Minute), tt.option)
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			start := time.Now()
+			if err = client.DialAndSend(message); err == nil {
+				t.Fatal("DialAndSend with stalling server was supposed to fail, but didn't")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second*5 {
+				t.Errorf("DialAndSend did not honor the phase timeout, took: %s", elapsed)
+			}
+			if client.connection != nil {
+				_ = client.connection.Close()
+			}
+			select {
+			case <-closed:
+			case <-time.After(time.Second * 5):
+				t.Error("DialAndSend did not close the connection to the stalling server")
+			}
+		})
+	}
+}
+
 func TestClient_SendErrorMailFromReset(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1977,6 +3666,119 @@ func TestClient_AuthLoginFail(t *testing.T) {
 	}
 }
 
+func TestWithSMTPAuthList(t *testing.T) {
+	client, err := NewClient(DefaultHost, WithSMTPAuthList(SMTPAuthPlain, SMTPAuthCramMD5))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	if len(client.smtpAuthList) != 2 {
+		t.Errorf("WithSMTPAuthList failed. Expected 2 auth types, got: %d", len(client.smtpAuthList))
+	}
+	if client.smtpAuthType != SMTPAuthPlain {
+		t.Errorf("WithSMTPAuthList failed. Expected auth type: %s, got: %s", SMTPAuthPlain, client.smtpAuthType)
+	}
+	client.SetSMTPAuth(SMTPAuthLogin)
+	if client.smtpAuthList != nil {
+		t.Error("SetSMTPAuth failed. Expected auth list to be reset")
+	}
+	if _, err = NewClient(DefaultHost, WithSMTPAuthList()); !errors.Is(err, ErrEmptySMTPAuthList) {
+		t.Errorf("WithSMTPAuthList with empty list expected error: %s, got: %v", ErrEmptySMTPAuthList, err)
+	}
+}
+
+func TestClient_negotiateSMTPAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		authtypes []SMTPAuthType
+		offered   string
+		want      SMTPAuthType
+		shouldErr bool
+	}{
+		{"first match", []SMTPAuthType{SMTPAuthPlain, SMTPAuthCramMD5}, "PLAIN CRAM-MD5", SMTPAuthPlain, false},
+		{"fallback", []SMTPAuthType{SMTPAuthPlain, SMTPAuthCramMD5}, "LOGIN CRAM-MD5", SMTPAuthCramMD5, false},
+		{"case insensitive", []SMTPAuthType{SMTPAuthLogin}, "plain login", SMTPAuthLogin, false},
+		{"no partial match", []SMTPAuthType{SMTPAuthSCRAMSHA1}, "SCRAM-SHA-1-PLUS", "", true},
+		{"no match", []SMTPAuthType{SMTPAuthPlain, SMTPAuthXOAUTH2}, "CRAM-MD5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := negotiateSMTPAuth(tt.authtypes, tt.offered)
+			if err != nil && !tt.shouldErr {
+				t.Fatalf("negotiateSMTPAuth failed: %s", err)
+			}
+			if tt.shouldErr {
+				if !errors.Is(err, ErrNoSupportedAuthNegotiated) {
+					t.Fatalf("negotiateSMTPAuth expected error: %s, got: %v", ErrNoSupportedAuthNegotiated, err)
+				}
+				if !strings.Contains(err.Error(), string(tt.authtypes[0])) ||
+					!strings.Contains(err.Error(), tt.offered) {
+					t.Errorf("negotiateSMTPAuth error expected to name requested and offered types, got: %s", err)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("negotiateSMTPAuth failed. Expected: %s, got: %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClient_AuthListNegotiation(t *testing.T) {
+	tests := []struct {
+		name       string
+		featureSet string
+		shouldFail bool
+	}{
+		{"fallback to LOGIN", "250-AUTH CRAM-MD5 LOGIN\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8", false},
+		{"no supported type", "250-AUTH CRAM-MD5\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8", true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			serverPort := TestServerPortBase + 51 + i
+			go func() {
+				if err := simpleSMTPServer(ctx, tt.featureSet, true, serverPort); err != nil {
+					t.Errorf("failed to start test server: %s", err)
+					return
+				}
+			}()
+			time.Sleep(time.Millisecond * 300)
+
+			client, err := NewClient(TestServerAddr,
+				WithPort(serverPort),
+				WithTLSPortPolicy(NoTLS),
+				WithSMTPAuthList(SMTPAuthPlain, SMTPAuthLogin),
+				WithUsername("toni@tester.com"),
+				WithPassword("V3ryS3cr3t+"))
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			err = client.DialWithContext(context.Background())
+			if tt.shouldFail {
+				if !errors.Is(err, ErrNoSupportedAuthNegotiated) {
+					t.Errorf("expected error: %s, got: %v", ErrNoSupportedAuthNegotiated, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to dial to test server: %s", err)
+			}
+			if client.smtpAuthNegotiated != SMTPAuthLogin {
+				t.Errorf("expected negotiated auth type: %s, got: %s", SMTPAuthLogin, client.smtpAuthNegotiated)
+			}
+			// The configured auth type must be kept, so that the next connection negotiates again
+			if client.smtpAuthType != SMTPAuthPlain {
+				t.Errorf("expected configured auth type: %s, got: %s", SMTPAuthPlain, client.smtpAuthType)
+			}
+			if err = client.Close(); err != nil {
+				t.Errorf("failed to close server connection: %s", err)
+			}
+		})
+	}
+}
+
 func TestClient_AuthLoginFail_noTLS(t *testing.T) {
 	if os.Getenv("TEST_SKIP_ONLINE") != "" {
 		t.Skipf("env variable TEST_SKIP_ONLINE is set. Skipping online tests")
@@ -2498,6 +4300,614 @@ func TestXOAuth2Unsupported_faker(t *testing.T) {
 	}
 }
 
+// TestClient_XOAuth2TokenSource tests that the XOAUTH2 token source is called on each connection
+func TestClient_XOAuth2TokenSource(t *testing.T) {
+	type ctxKey struct{}
+	newFake := func(wrote *strings.Builder) faker {
+		server := []string{
+			"220 Fake server ready ESMTP", "250-fake.server\r\n250-AUTH XOAUTH2\r\n250 8BITMIME",
+			"250 OK", "235 2.7.0 Accepted", "221 OK",
+		}
+		var fake faker
+		fake.ReadWriter = struct {
+			io.Reader
+			io.Writer
+		}{
+			strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+			wrote,
+		}
+		return fake
+	}
+	var wrote strings.Builder
+	calls := 0
+	tokenSource := func(ctx context.Context) (string, error) {
+		if ctx.Value(ctxKey{}) != "propagated" {
+			t.Error("XOAUTH2 token source expected context value to be propagated")
+		}
+		calls++
+		return fmt.Sprintf("token%d", calls), nil
+	}
+	client, err := NewClient("fake.host",
+		WithDialContextFunc(func(context.Context, string, string) (net.Conn, error) {
+			return newFake(&wrote), nil
+		}),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+		WithPassword("static"), WithXOAuth2TokenSource(tokenSource))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "propagated")
+	for i := 1; i <= 2; i++ {
+		wrote.Reset()
+		if err = client.DialWithContext(ctx); err != nil {
+			t.Fatalf("unexpected dial error: %s", err)
+		}
+		if err = client.Close(); err != nil {
+			t.Fatalf("failed to close connection: %s", err)
+		}
+		want := "AUTH XOAUTH2 " + base64.StdEncoding.EncodeToString(
+			[]byte(fmt.Sprintf("user=user\x01auth=Bearer token%d\x01\x01", i))) + "\r\n"
+		if !strings.Contains(wrote.String(), want) {
+			t.Errorf("connection %d: expected client to send %q, got: %q", i, want, wrote.String())
+		}
+	}
+	if calls != 2 {
+		t.Errorf("XOAUTH2 token source expected to be called 2 times, got: %d", calls)
+	}
+
+	tokenErr := errors.New("token expired")
+	client, err = NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(newFake(&wrote))),
+		WithTLSPortPolicy(NoTLS), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+		WithXOAuth2TokenSource(func(context.Context) (string, error) { return "", tokenErr }))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialWithContext(context.Background()); !errors.Is(err, tokenErr) {
+		t.Errorf("expected dial to fail with token source error, got: %v", err)
+	}
+}
+
+// TestClient_auth_UTF8Credentials tests the PLAIN and LOGIN SMTP authentication with non-ASCII credentials
+func TestClient_auth_UTF8Credentials(t *testing.T) {
+	password := "pa\u0308sswo\u0308rd-\U0001F600"
+	prepared := "p\u00e4ssw\u00f6rd-\U0001F600"
+	b64 := func(value string) string {
+		return base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	tests := []struct {
+		name     string
+		authType SMTPAuthType
+		saslPrep bool
+		replies  []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			"PLAIN raw", SMTPAuthPlain, false, []string{"235 2.7.0 Accepted"},
+			[]string{"AUTH PLAIN " + b64("\x00jürgen\x00"+password)}, false,
+		},
+		{
+			"PLAIN with SASLprep", SMTPAuthPlain, true, []string{"235 2.7.0 Accepted"},
+			[]string{"AUTH PLAIN " + b64("\x00jürgen\x00"+prepared)}, false,
+		},
+		{
+			"LOGIN raw", SMTPAuthLogin, false,
+			[]string{"334 VXNlcm5hbWU6", "334 UGFzc3dvcmQ6", "235 2.7.0 Accepted"},
+			[]string{"AUTH LOGIN", b64("jürgen"), b64(password)}, false,
+		},
+		{
+			"LOGIN with SASLprep", SMTPAuthLogin, true,
+			[]string{"334 VXNlcm5hbWU6", "334 UGFzc3dvcmQ6", "235 2.7.0 Accepted"},
+			[]string{"AUTH LOGIN", b64("jürgen"), b64(prepared)}, false,
+		},
+		{
+			"LOGIN failure", SMTPAuthLogin, false,
+			[]string{"334 VXNlcm5hbWU6", "334 UGFzc3dvcmQ6", "535 5.7.8 Authentication failed"},
+			nil, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := []string{"220 Fake server ready ESMTP", "250-localhost\r\n250-AUTH PLAIN LOGIN\r\n250 8BITMIME", "250 OK"}
+			server = append(server, tt.replies...)
+			server = append(server, "250 OK", "221 OK")
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			opts := []Option{
+				WithDialContextFunc(getFakeDialFunc(fake)), WithTLSPortPolicy(NoTLS), WithSMTPAuth(tt.authType),
+				WithUsername("jürgen"), WithPassword(password),
+			}
+			if tt.saslPrep {
+				opts = append(opts, WithSASLprep())
+			}
+			client, err := NewClient("localhost", opts...)
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			err = client.DialWithContext(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected authentication to fail")
+				}
+				if !strings.Contains(err.Error(), string(tt.authType)) {
+					t.Errorf("expected error to contain the auth mechanism %s, got: %s", tt.authType, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected dial error: %s", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(wrote.String(), want+"\r\n") {
+					t.Errorf("expected client to send %q, got: %q", want, wrote.String())
+				}
+			}
+		})
+	}
+}
+
+// TestClient_DialWithContext_TLSPolicy tests the STARTTLS behavior of the different TLS policies
+func TestClient_DialWithContext_TLSPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   TLSPolicy
+		starttls bool
+		wantErr  error
+	}{
+		{"mandatory without STARTTLS", TLSMandatory, false, ErrSTARTTLSNotSupported},
+		{"mandatory with failing STARTTLS", TLSMandatory, true, ErrSTARTTLSFailed},
+		{"opportunistic without STARTTLS", TLSOpportunistic, false, nil},
+		{"opportunistic with failing STARTTLS", TLSOpportunistic, true, ErrSTARTTLSFailed},
+		{"no TLS with STARTTLS", NoTLS, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ehlo := "250-fake.server\r\n250-AUTH XOAUTH2\r\n250 8BITMIME"
+			if tt.starttls {
+				ehlo = "250-fake.server\r\n250-AUTH XOAUTH2\r\n250-STARTTLS\r\n250 8BITMIME"
+			}
+			reply := "454 4.7.0 TLS not available"
+			if tt.wantErr == nil {
+				reply = "250 OK"
+			}
+			server := []string{"220 Fake server ready ESMTP", ehlo, reply, "235 2.7.0 Accepted", "221 OK"}
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			client, err := NewClient("fake.host", WithDialContextFunc(getFakeDialFunc(fake)),
+				WithTLSPortPolicy(tt.policy), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+				WithPassword("token"))
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			err = client.DialWithContext(context.Background())
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("DialWithContext failed: %s", err)
+				}
+				if client.isEncrypted {
+					t.Error("DialWithContext expected connection to be unencrypted")
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DialWithContext expected error: %s, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestClient_DialWithContext_TLSServerName tests that the certificate of the server is verified against
+// the TLS server name set via WithTLSServerName
+func TestClient_DialWithContext_TLSServerName(t *testing.T) {
+	serverPort := TestServerPortBase + 64
+	certificate, rootCAs := newTLSServerNameTestCertificate(t, "mail.go-mail.internal")
+	startTLSServerNameTestServer(t, serverPort, certificate, false)
+
+	tests := []struct {
+		name       string
+		serverName string
+		wantErr    error
+	}{
+		{"matching server name", "mail.go-mail.internal", nil},
+		{"no server name", "", ErrSTARTTLSFailed},
+		{"mismatching server name", "other.go-mail.internal", ErrSTARTTLSFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig := &tls.Config{RootCAs: rootCAs, MinVersion: DefaultTLSMinVersion}
+			opts := []Option{
+				WithPort(serverPort), WithTLSPortPolicy(TLSMandatory), WithTLSConfig(tlsConfig),
+				WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+			}
+			if tt.serverName != "" {
+				opts = append(opts, WithTLSServerName(tt.serverName))
+			}
+			client, err := NewClient(TestServerAddr, opts...)
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			err = client.DialWithContext(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("DialWithContext expected error: %s, got: %v", tt.wantErr, err)
+				}
+				var hostnameErr x509.HostnameError
+				if !errors.As(err, &hostnameErr) {
+					t.Errorf("DialWithContext expected error to wrap a x509.HostnameError, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DialWithContext failed: %s", err)
+			}
+			defer func() { _ = client.Close() }()
+			if !client.isEncrypted {
+				t.Error("DialWithContext expected connection to be encrypted")
+			}
+			if tlsConfig.ServerName != "" {
+				t.Errorf("DialWithContext expected the tls.Config to be left untouched, got ServerName: %s",
+					tlsConfig.ServerName)
+			}
+		})
+	}
+	if _, err := NewClient(DefaultHost, WithTLSServerName("")); !errors.Is(err, ErrInvalidTLSServerName) {
+		t.Errorf("WithTLSServerName expected error: %s, got: %v", ErrInvalidTLSServerName, err)
+	}
+}
+
+// TestClient_DialWithContext_implicitTLS tests that WithSSL connects to a TLS-only server using the
+// tls.Config of the Client and that it cannot be combined with STARTTLS
+func TestClient_DialWithContext_implicitTLS(t *testing.T) {
+	serverPort := TestServerPortBase + 68
+	certificate, rootCAs := newTLSServerNameTestCertificate(t, "smtps.go-mail.internal")
+	startTLSServerNameTestServer(t, serverPort, certificate, true)
+
+	tests := []struct {
+		name    string
+		rootCAs *x509.CertPool
+		useSSL  bool
+	}{
+		{"implicit TLS", rootCAs, true},
+		{"implicit TLS with untrusted certificate", nil, true},
+		{"plaintext to TLS-only server", rootCAs, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig := &tls.Config{RootCAs: tt.rootCAs, MinVersion: DefaultTLSMinVersion}
+			client, err := NewClient(TestServerAddr, WithPort(serverPort), WithSSL(), WithTLSConfig(tlsConfig),
+				WithTLSServerName("smtps.go-mail.internal"), WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"),
+				WithPassword("token"), WithTimeout(time.Millisecond*500))
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			client.SetSSL(tt.useSSL)
+			err = client.DialWithContext(context.Background())
+			if !tt.useSSL || tt.rootCAs == nil {
+				if err == nil {
+					_ = client.Close()
+					t.Fatal("DialWithContext was supposed to fail, but didn't")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DialWithContext failed: %s", err)
+			}
+			defer func() { _ = client.Close() }()
+			state, err := client.smtpClient.GetTLSConnectionState()
+			if err != nil {
+				t.Fatalf("failed to get TLS connection state: %s", err)
+			}
+			if !client.isEncrypted || !state.HandshakeComplete {
+				t.Error("DialWithContext expected connection to be encrypted with implicit TLS")
+			}
+			if state.ServerName != "smtps.go-mail.internal" {
+				t.Errorf("DialWithContext expected TLS server name: %s, got: %s", "smtps.go-mail.internal",
+					state.ServerName)
+			}
+		})
+	}
+
+	for _, option := range []Option{
+		WithTLSPolicy(TLSMandatory), WithTLSPolicy(TLSOpportunistic), WithTLSPortPolicy(TLSMandatory),
+	} {
+		if _, err := NewClient(DefaultHost, option, WithSSL()); !errors.Is(err, ErrSSLWithSTARTTLS) {
+			t.Errorf("NewClient with implicit TLS and STARTTLS expected error: %s, got: %v", ErrSSLWithSTARTTLS, err)
+		}
+	}
+	if _, err := NewClient(DefaultHost, WithSSLPort(false), WithTLSPortPolicy(NoTLS)); err != nil {
+		t.Errorf("NewClient with implicit TLS and NoTLS policy failed: %s", err)
+	}
+	if _, err := NewClient(DefaultHost, WithTLSPortPolicy(TLSMandatory), WithTLSPolicy(NoTLS), WithSSL()); err != nil {
+		t.Errorf("NewClient with implicit TLS and a later NoTLS policy failed: %s", err)
+	}
+
+	// The conflict is also detected if the settings are changed after NewClient
+	client, err := NewClient(DefaultHost, WithTLSPortPolicy(TLSMandatory))
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	client.SetSSL(true)
+	if err = client.DialWithContext(context.Background()); !errors.Is(err, ErrSSLWithSTARTTLS) {
+		t.Errorf("DialWithContext with SetSSL and STARTTLS expected error: %s, got: %v", ErrSSLWithSTARTTLS, err)
+	}
+	client, err = NewClient(DefaultHost, WithSSL())
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	client.SetTLSPolicy(TLSOpportunistic)
+	if err = client.DialWithContext(context.Background()); !errors.Is(err, ErrSSLWithSTARTTLS) {
+		t.Errorf("DialWithContext with SetTLSPolicy and SSL expected error: %s, got: %v", ErrSSLWithSTARTTLS, err)
+	}
+	client.SetTLSPortPolicy(NoTLS)
+	if err = client.DialWithContext(context.Background()); errors.Is(err, ErrSSLWithSTARTTLS) {
+		t.Errorf("DialWithContext with SSL and NoTLS policy returned unexpected error: %s", err)
+	}
+}
+
+// TestClient_LastTLSState tests that Client.LastTLSState reflects the TLS handshake of the connection that
+// was used to send the last message
+func TestClient_LastTLSState(t *testing.T) {
+	certificate, rootCAs := newTLSServerNameTestCertificate(t, "tls.go-mail.internal")
+	startTLSServerNameTestServer(t, TestServerPortBase+69, certificate, false)
+	startTLSServerNameTestServer(t, TestServerPortBase+70, certificate, true)
+
+	tests := []struct {
+		name    string
+		port    int
+		options []Option
+		wantTLS bool
+	}{
+		{"STARTTLS", TestServerPortBase + 69, []Option{WithTLSPortPolicy(TLSMandatory)}, true},
+		{"implicit TLS", TestServerPortBase + 70, []Option{WithSSL()}, true},
+		{"no TLS", TestServerPortBase + 69, []Option{WithTLSPortPolicy(NoTLS)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig := &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}
+			options := append([]Option{
+				WithPort(tt.port), WithTLSConfig(tlsConfig), WithTLSServerName("tls.go-mail.internal"),
+				WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token"),
+			}, tt.options...)
+			client, err := NewClient(TestServerAddr, options...)
+			if err != nil {
+				t.Fatalf("unable to create new client: %s", err)
+			}
+			if _, ok := client.LastTLSState(); ok {
+				t.Error("LastTLSState expected no connection state before the first message is sent")
+			}
+			if err = client.DialAndSend(newPoolTestMsg(t)); err != nil {
+				t.Fatalf("failed to send message: %s", err)
+			}
+			state, ok := client.LastTLSState()
+			if ok != tt.wantTLS {
+				t.Fatalf("LastTLSState expected TLS: %t, got: %t", tt.wantTLS, ok)
+			}
+			if !tt.wantTLS {
+				return
+			}
+			if !state.HandshakeComplete || state.Version < tls.VersionTLS12 || state.CipherSuite == 0 {
+				t.Errorf("LastTLSState expected a completed handshake with TLS version and cipher suite, got: "+
+					"version %x, cipher suite %x", state.Version, state.CipherSuite)
+			}
+			if state.ServerName != "tls.go-mail.internal" {
+				t.Errorf("LastTLSState expected TLS server name: %s, got: %s", "tls.go-mail.internal",
+					state.ServerName)
+			}
+		})
+	}
+}
+
+// TestClient_SMTPClient tests that Client.SMTPClient returns the smtp.Client of the current connection
+func TestClient_SMTPClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverPort := TestServerPortBase + 75
+	featureSet := "250-AUTH PLAIN\r\n250-8BITMIME\r\n250-DSN\r\n250 SMTPUTF8"
+	go func() {
+		if err := simpleSMTPServer(ctx, featureSet, false, serverPort); err != nil {
+			t.Errorf("failed to start test server: %s", err)
+			return
+		}
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(NoTLS))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if _, err = client.SMTPClient(); !errors.Is(err, ErrNoActiveConnection) {
+		t.Errorf("SMTPClient without connection expected error: %s, got: %v", ErrNoActiveConnection, err)
+	}
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	smtpClient, err := client.SMTPClient()
+	if err != nil {
+		t.Fatalf("SMTPClient failed: %s", err)
+	}
+	if code, _, err := smtpClient.Command("NOOP", ""); err != nil || code != 250 {
+		t.Errorf("Command NOOP expected code 250, got: %d, %v", code, err)
+	}
+	var protoErr *textproto.Error
+	if _, _, err = smtpClient.Command("EXPN", "list"); !errors.As(err, &protoErr) || protoErr.Code != 500 {
+		t.Errorf("Command EXPN expected error with code 500, got: %v", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Errorf("failed to close client: %s", err)
+	}
+	if _, err = client.SMTPClient(); !errors.Is(err, ErrNoActiveConnection) {
+		t.Errorf("SMTPClient after close expected error: %s, got: %v", ErrNoActiveConnection, err)
+	}
+}
+
+// TestClient_Send_AttachFunc tests that the content of an attachment added via Msg.AttachFunc is produced
+// once per delivery attempt, even if the size and the attachments of the message are checked before it
+// is sent
+func TestClient_Send_AttachFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverPort := TestServerPortBase + 76
+	featureSet := "250-AUTH PLAIN\r\n250-8BITMIME\r\n250 SMTPUTF8"
+	go func() {
+		if err := simpleSMTPServer(ctx, featureSet, false, serverPort); err != nil {
+			t.Errorf("failed to start test server: %s", err)
+			return
+		}
+	}()
+	time.Sleep(time.Millisecond * 500)
+
+	calls := 0
+	message := newPoolTestMsg(t)
+	message.AttachFunc("report.csv", TypeTextPlain, func() (io.Reader, error) {
+		calls++
+		return strings.NewReader("id;name\n1;Toni\n"), nil
+	})
+	client, err := NewClient(TestServerAddr, WithPort(serverPort), WithTLSPortPolicy(NoTLS),
+		WithMaxMessageSize(10000), WithAttachmentLimits(1, 1000))
+	if err != nil {
+		t.Fatalf("unable to create new client: %s", err)
+	}
+	if err = client.DialAndSend(message); err != nil {
+		t.Fatalf("failed to send message: %s", err)
+	}
+	if calls != 1 {
+		t.Errorf("AttachFunc content expected to be produced once, got %d calls", calls)
+	}
+
+	produceErr := errors.New("report generation failed")
+	failing := newPoolTestMsg(t)
+	failing.AttachFunc("report.csv", TypeTextPlain, func() (io.Reader, error) {
+		return nil, produceErr
+	})
+	err = client.DialAndSend(failing)
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || sendErr.Reason != ErrWriteContent {
+		t.Errorf("send with failing producer expected SendError with reason %s, got: %v", ErrWriteContent, err)
+	}
+	if !strings.Contains(fmt.Sprint(err), produceErr.Error()) {
+		t.Errorf("send with failing producer expected error to contain %q, got: %v", produceErr, err)
+	}
+}
+
+// newTLSServerNameTestCertificate returns a new self-signed certificate for the given server name and
+// a certificate pool that trusts it
+func newTLSServerNameTestCertificate(t *testing.T, serverName string) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: serverName},
+		DNSNames:              []string{serverName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key, Leaf: cert}, rootCAs
+}
+
+// startTLSServerNameTestServer starts a SMTP test server on the given port that supports STARTTLS
+// with the given certificate, or that only accepts implicit TLS connections if implicit is true
+func startTLSServerNameTestServer(t *testing.T, port int, certificate tls.Certificate, implicit bool) {
+	t.Helper()
+	listener, err := net.Listen(TestServerProto, fmt.Sprintf("%s:%d", TestServerAddr, port))
+	if err != nil {
+		t.Fatalf("unable to listen on %s:%d: %s", TestServerAddr, port, err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: DefaultTLSMinVersion}
+	features := "250-localhost.localdomain\r\n250-AUTH XOAUTH2\r\n250-STARTTLS\r\n250 8BITMIME"
+	if implicit {
+		listener = tls.NewListener(listener, tlsConfig)
+		features = "250-localhost.localdomain\r\n250-AUTH XOAUTH2\r\n250 8BITMIME"
+	}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(connection net.Conn) {
+				defer func() { _ = connection.Close() }()
+				reader := bufio.NewReader(connection)
+				writeLine := func(data string) {
+					_, _ = connection.Write([]byte(data + "\r\n"))
+				}
+				writeLine("220 Welcome to the go-mail TLS test server")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimSpace(line)
+					switch {
+					case strings.HasPrefix(line, "EHLO"):
+						writeLine(features)
+					case strings.HasPrefix(line, "AUTH"):
+						writeLine("235 2.7.0 Authentication successful")
+					case strings.HasPrefix(line, "STARTTLS"):
+						writeLine("220 2.0.0 Ready to start TLS")
+						tlsConn := tls.Server(connection, tlsConfig)
+						if err = tlsConn.Handshake(); err != nil {
+							return
+						}
+						connection = tlsConn
+						reader = bufio.NewReader(connection)
+					case strings.EqualFold(line, "DATA"):
+						writeLine("354 End data with <CR><LF>.<CR><LF>")
+						for {
+							data, err := reader.ReadString('\n')
+							if err != nil {
+								return
+							}
+							if data == ".\r\n" {
+								break
+							}
+						}
+						writeLine("250 2.0.0 Ok: queued as 1234567890")
+					case strings.HasPrefix(line, "QUIT"):
+						writeLine("221 2.0.0 Bye")
+						return
+					default:
+						writeLine("250 2.0.0 OK")
+					}
+				}
+			}(connection)
+		}
+	}()
+}
+
 func getFakeDialFunc(conn net.Conn) DialContextFunc {
 	return func(ctx context.Context, network, address string) (net.Conn, error) {
 		return conn, nil
@@ -2610,6 +5020,10 @@ func handleTestServerConnection(connection net.Conn, featureSet string, failRese
 		case strings.HasPrefix(data, "RCPT TO:"):
 			to := strings.TrimPrefix(data, "RCPT TO:")
 			to = strings.TrimSpace(to)
+			if strings.EqualFold(to, "<temp-fail-to@domain.tld>") {
+				_ = writeLine(fmt.Sprintf("450 4.2.1 Mailbox temporarily unavailable: %s", to))
+				break
+			}
 			if !strings.EqualFold(to, "<valid-to@domain.tld>") {
 				_ = writeLine(fmt.Sprintf("500 5.1.2 Invalid to: %s", to))
 				break
@@ -2713,3 +5127,61 @@ func handleTestServerConnection(connection net.Conn, featureSet string, failRese
 		}
 	}
 }
+
+// stallingSMTPServer accepts a single connection and answers the SMTP conversation until the
+// given command is received. From then on, it stops responding and waits for the client to close
+// the connection, which is signaled by closing the closed channel.
+func stallingSMTPServer(listener net.Listener, stallAt string, closed chan struct{}) {
+	defer close(closed)
+	connection, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = connection.Close() }()
+
+	reader := bufio.NewReader(connection)
+	writeLine := func(data string) {
+		_, _ = connection.Write([]byte(data + "\r\n"))
+	}
+	writeLine("220 Welcome to the stalling go-mail test server")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, stallAt) {
+			_, _ = io.Copy(io.Discard, reader)
+			return
+		}
+		switch {
+		case inData:
+			continue
+		case strings.HasPrefix(line, "EHLO"):
+			writeLine("250-localhost.localdomain\r\n250-AUTH PLAIN\r\n250 8BITMIME")
+		case strings.HasPrefix(line, "AUTH"):
+			writeLine("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(line, "DATA"):
+			inData = true
+			writeLine("354 End data with <CR><LF>.<CR><LF>")
+		default:
+			writeLine("250 2.0.0 OK")
+		}
+	}
+}
+
+// breakingConn is a net.Conn that fails all writes once it is marked as broken, while its deadlines can still
+// be set, so that a connection that breaks during the SMTP transaction can be simulated.
+type breakingConn struct {
+	net.Conn
+	broken bool
+}
+
+// Write implements the io.Writer interface for the breakingConn.
+func (c *breakingConn) Write(payload []byte) (int, error) {
+	if c.broken {
+		return 0, net.ErrClosed
+	}
+	return c.Conn.Write(payload)
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// typeMessageGlobalDeliveryStatus represents the MIME type for the machine-readable part of an
+// internationalized delivery status notification.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6533#section-6.1
+const typeMessageGlobalDeliveryStatus = "message/global-delivery-status"
+
+// ErrNoDeliveryStatus indicates that the Msg does not contain a message/delivery-status part.
+var ErrNoDeliveryStatus = errors.New("message does not contain a delivery status part")
+
+// DeliveryStatus represents the delivery status of a single recipient as reported in the
+// message/delivery-status part of a delivery status notification (DSN).
+//
+// Fields that are not present in the DSN are left empty. Address and diagnostic fields are
+// returned without their type prefix (e.g. "rfc822;" or "smtp;").
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3464#section-2.3
+type DeliveryStatus struct {
+	// Action is the action performed by the reporting MTA for the recipient, i.e. "failed",
+	// "delayed", "delivered", "relayed" or "expanded". The value is returned in lower case.
+	Action string
+
+	// DiagnosticCode is the diagnostic information returned by the remote MTA, if any.
+	DiagnosticCode string
+
+	// DiagnosticType is the type of the DiagnosticCode, usually "smtp".
+	DiagnosticType string
+
+	// FinalRecipient is the address of the recipient the delivery status refers to.
+	FinalRecipient string
+
+	// OriginalRecipient is the original recipient address as specified by the sender, if
+	// reported.
+	OriginalRecipient string
+
+	// RemoteMTA is the name of the MTA that reported the delivery status, if reported.
+	RemoteMTA string
+
+	// ReportingMTA is the name of the MTA that generated the DSN, taken from the per-message
+	// fields of the delivery status part.
+	ReportingMTA string
+
+	// Status is the RFC 3463 status code for the recipient, e.g. "5.1.1".
+	Status string
+}
+
+// ParseDeliveryStatus extracts the per-recipient delivery status fields of a delivery status
+// notification (DSN).
+//
+// The Msg is expected to be a DSN with a multipart/report; report-type=delivery-status structure,
+// as parsed by one of the EMLToMsg functions. The message/delivery-status part of the Msg is
+// located and each of its per-recipient field groups is returned as DeliveryStatus. Field names
+// are matched case-insensitively and missing optional fields are tolerated.
+//
+// Parameters:
+//   - msg: A pointer to the Msg representing the DSN.
+//
+// Returns:
+//   - A slice of DeliveryStatus, one for each recipient reported in the DSN.
+//   - An error if the Msg contains no delivery status part (ErrNoDeliveryStatus) or if the
+//     delivery status part cannot be parsed.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3464
+//   - https://datatracker.ietf.org/doc/html/rfc6522
+func ParseDeliveryStatus(msg *Msg) ([]DeliveryStatus, error) {
+	if msg == nil {
+		return nil, ErrNoDeliveryStatus
+	}
+	for _, part := range msg.parts {
+		if part.isDeleted {
+			continue
+		}
+		contentType := part.contentType.String()
+		if !strings.EqualFold(contentType, TypeMessageDeliveryStatus.String()) &&
+			!strings.EqualFold(contentType, typeMessageGlobalDeliveryStatus) {
+			continue
+		}
+		content, err := part.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read delivery status part: %w", err)
+		}
+		return parseDeliveryStatusFields(content)
+	}
+	return nil, ErrNoDeliveryStatus
+}
+
+// parseDeliveryStatusFields parses the content of a message/delivery-status part.
+//
+// The content consists of a group of per-message fields, followed by one or more groups of
+// per-recipient fields, each separated by a blank line. Each group is parsed like a MIME
+// header, so that folded lines and non-standard casing of the field names are handled.
+//
+// Parameters:
+//   - content: The raw content of the message/delivery-status part.
+//
+// Returns:
+//   - A slice of DeliveryStatus, one for each per-recipient field group.
+//   - An error if a field group cannot be parsed.
+func parseDeliveryStatusFields(content []byte) ([]DeliveryStatus, error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(content)))
+
+	var reportingMTA string
+	var statuses []DeliveryStatus
+	for {
+		// Skip any additional blank lines between the field groups
+		for {
+			peek, err := reader.R.Peek(1)
+			if err != nil || (peek[0] != '\n' && peek[0] != '\r') {
+				break
+			}
+			_, _ = reader.R.ReadByte()
+		}
+		fields, err := reader.ReadMIMEHeader()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse delivery status fields: %w", err)
+		}
+		if len(fields) > 0 {
+			switch {
+			case fields.Get("Final-Recipient") != "" || fields.Get("Original-Recipient") != "" ||
+				fields.Get("Action") != "":
+				diagType, diagCode := splitDeliveryStatusType(fields.Get("Diagnostic-Code"))
+				_, finalRcpt := splitDeliveryStatusType(fields.Get("Final-Recipient"))
+				_, origRcpt := splitDeliveryStatusType(fields.Get("Original-Recipient"))
+				_, remoteMTA := splitDeliveryStatusType(fields.Get("Remote-MTA"))
+				statuses = append(statuses, DeliveryStatus{
+					Action:            strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+					DiagnosticCode:    diagCode,
+					DiagnosticType:    diagType,
+					FinalRecipient:    finalRcpt,
+					OriginalRecipient: origRcpt,
+					RemoteMTA:         remoteMTA,
+					Status:            deliveryStatusCode(fields.Get("Status")),
+				})
+			case fields.Get("Reporting-MTA") != "":
+				_, reportingMTA = splitDeliveryStatusType(fields.Get("Reporting-MTA"))
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	for i := range statuses {
+		statuses[i].ReportingMTA = reportingMTA
+	}
+	return statuses, nil
+}
+
+// splitDeliveryStatusType splits a typed delivery status field value (e.g. "rfc822; user@domain.tld"
+// or "smtp; 550 5.1.1 User unknown") into its type and its value.
+//
+// If the value has no type prefix, the type is empty and the whole value is returned.
+//
+// Parameters:
+//   - value: The raw field value.
+//
+// Returns:
+//   - The lower case type of the field value.
+//   - The value without the type prefix.
+func splitDeliveryStatusType(value string) (string, string) {
+	parts := strings.SplitN(value, ";", 2)
+	if len(parts) != 2 {
+		return "", strings.TrimSpace(value)
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+}
+
+// deliveryStatusCode extracts the status code from a Status field value.
+//
+// Some MTAs append a comment to the status code (e.g. "5.1.1 (user unknown)"), which is removed.
+//
+// Parameters:
+//   - value: The raw Status field value.
+//
+// Returns:
+//   - The status code, e.g. "5.1.1".
+func deliveryStatusCode(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
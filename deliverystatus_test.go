@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const exampleDSN = `Date: Wed, 01 Nov 2023 00:00:00 +0000
+From: Mail Delivery System <MAILER-DAEMON@mx.domain.tld>
+To: <valid-from@domain.tld>
+Subject: Undelivered Mail Returned to Sender
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status;
+	boundary="dsn-boundary"
+
+--dsn-boundary
+Content-Type: text/plain; charset=us-ascii
+Content-Transfer-Encoding: 7bit
+
+This is the mail system at host mx.domain.tld.
+
+I'm sorry to have to inform you that your message could not
+be delivered to one or more recipients.
+
+--dsn-boundary
+Content-Type: message/delivery-status
+Content-Transfer-Encoding: 7bit
+
+Reporting-MTA: dns; mx.domain.tld
+Arrival-Date: Wed, 01 Nov 2023 00:00:00 +0000
+
+Final-Recipient: rfc822; unknown@domain.tld
+Original-Recipient: rfc822;Unknown@domain.tld
+Action: Failed
+Status: 5.1.1
+Remote-MTA: dns; remote.domain.tld
+Diagnostic-Code: smtp; 550 5.1.1 <unknown@domain.tld>:
+    Recipient address rejected: User unknown
+
+
+final-recipient: RFC822; delayed@domain.tld
+ACTION: delayed
+status: 4.4.1 (connection timed out)
+
+--dsn-boundary
+Content-Type: text/rfc822-headers
+
+From: <valid-from@domain.tld>
+To: <unknown@domain.tld>
+Subject: Test
+
+--dsn-boundary--
+`
+
+// TestParseDeliveryStatus tests the ParseDeliveryStatus function
+func TestParseDeliveryStatus(t *testing.T) {
+	msg, err := EMLToMsgFromString(exampleDSN)
+	if err != nil {
+		t.Fatalf("failed to parse DSN EML: %s", err)
+	}
+	statuses, err := ParseDeliveryStatus(msg)
+	if err != nil {
+		t.Fatalf("ParseDeliveryStatus failed: %s", err)
+	}
+	want := []DeliveryStatus{
+		{
+			Action:            "failed",
+			DiagnosticCode:    "550 5.1.1 <unknown@domain.tld>: Recipient address rejected: User unknown",
+			DiagnosticType:    "smtp",
+			FinalRecipient:    "unknown@domain.tld",
+			OriginalRecipient: "Unknown@domain.tld",
+			RemoteMTA:         "remote.domain.tld",
+			ReportingMTA:      "mx.domain.tld",
+			Status:            "5.1.1",
+		},
+		{
+			Action:         "delayed",
+			FinalRecipient: "delayed@domain.tld",
+			ReportingMTA:   "mx.domain.tld",
+			Status:         "4.4.1",
+		},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("ParseDeliveryStatus failed. Expected %d statuses, got: %d", len(want), len(statuses))
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Errorf("ParseDeliveryStatus failed. Expected status: %+v, got: %+v", want[i], statuses[i])
+		}
+	}
+}
+
+// TestParseDeliveryStatus_CRLF tests the ParseDeliveryStatus function with CRLF line endings
+func TestParseDeliveryStatus_CRLF(t *testing.T) {
+	msg, err := EMLToMsgFromString(strings.ReplaceAll(exampleDSN, "\n", "\r\n"))
+	if err != nil {
+		t.Fatalf("failed to parse DSN EML: %s", err)
+	}
+	statuses, err := ParseDeliveryStatus(msg)
+	if err != nil {
+		t.Fatalf("ParseDeliveryStatus failed: %s", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("ParseDeliveryStatus failed. Expected 2 statuses, got: %d", len(statuses))
+	}
+	if statuses[0].FinalRecipient != "unknown@domain.tld" || statuses[1].Status != "4.4.1" {
+		t.Errorf("ParseDeliveryStatus failed. Unexpected statuses: %+v", statuses)
+	}
+}
+
+// TestParseDeliveryStatus_NoDSN tests the ParseDeliveryStatus function with messages that are no DSN
+func TestParseDeliveryStatus_NoDSN(t *testing.T) {
+	if _, err := ParseDeliveryStatus(nil); !errors.Is(err, ErrNoDeliveryStatus) {
+		t.Errorf("ParseDeliveryStatus expected error: %s, got: %v", ErrNoDeliveryStatus, err)
+	}
+	msg := NewMsg()
+	msg.SetBodyString(TypeTextPlain, "This is not a DSN")
+	if _, err := ParseDeliveryStatus(msg); !errors.Is(err, ErrNoDeliveryStatus) {
+		t.Errorf("ParseDeliveryStatus expected error: %s, got: %v", ErrNoDeliveryStatus, err)
+	}
+}
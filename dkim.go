@@ -0,0 +1,475 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DKIMCanonicalization is a type wrapper for a string and represents the canonicalization algorithm
+// that is used to prepare the header and body of a Msg before it is DKIM signed.
+//
+// https://datatracker.ietf.org/doc/html/rfc6376#section-3.4
+type DKIMCanonicalization string
+
+// DKIMSignerOption is a function type that modifies the settings of a DKIMSigner.
+type DKIMSignerOption func(*DKIMSigner) error
+
+// DKIMSigner is a Middleware that adds a DKIM-Signature header to a Msg.
+//
+// The DKIMSigner renders the Msg, computes the body hash over the canonicalized body and signs
+// the canonicalized headers with the provided private key. RSA (rsa-sha256) and Ed25519
+// (ed25519-sha256) keys are supported. Since the signature covers the rendered Msg, the
+// DKIMSigner should be the last Middleware that is applied to a Msg. If the Msg has no boundary
+// set, the DKIMSigner assigns a random boundary to the Msg, so that the multipart structure is
+// rendered identically when the Msg is finally written.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376
+//   - https://datatracker.ietf.org/doc/html/rfc8463
+type DKIMSigner struct {
+	bodyCanon   DKIMCanonicalization
+	domain      string
+	headerCanon DKIMCanonicalization
+	headers     []string
+	key         crypto.Signer
+	selector    string
+}
+
+const (
+	// DKIMCanonicalizationSimple is the "simple" canonicalization algorithm, which tolerates almost
+	// no modification of the Msg in transit.
+	DKIMCanonicalizationSimple DKIMCanonicalization = "simple"
+
+	// DKIMCanonicalizationRelaxed is the "relaxed" canonicalization algorithm, which tolerates common
+	// modifications like whitespace replacement and header line rewrapping.
+	DKIMCanonicalizationRelaxed DKIMCanonicalization = "relaxed"
+
+	// MiddlewareTypeDKIM is the MiddlewareType of the DKIMSigner.
+	MiddlewareTypeDKIM MiddlewareType = "dkim"
+)
+
+var (
+	// ErrDKIMNoDomain is returned when no signing domain is provided to the DKIMSigner.
+	ErrDKIMNoDomain = errors.New("DKIM signing domain must not be empty")
+
+	// ErrDKIMNoSelector is returned when no selector is provided to the DKIMSigner.
+	ErrDKIMNoSelector = errors.New("DKIM selector must not be empty")
+
+	// ErrDKIMInvalidKey is returned when the private key provided to the DKIMSigner is nil or of an
+	// unsupported type.
+	ErrDKIMInvalidKey = errors.New("DKIM private key must be a RSA or Ed25519 private key")
+
+	// ErrDKIMInvalidCanonicalization is returned when an unsupported canonicalization algorithm is
+	// provided to the DKIMSigner.
+	ErrDKIMInvalidCanonicalization = errors.New("DKIM canonicalization must be simple or relaxed")
+
+	// ErrDKIMNoFromHeader is returned when the list of headers to sign does not include the "From"
+	// header, which is required to be signed.
+	ErrDKIMNoFromHeader = errors.New("DKIM signed headers must include the From header")
+)
+
+// defaultDKIMHeaders is the list of headers that are signed by the DKIMSigner by default, if they are
+// present in the Msg.
+var defaultDKIMHeaders = []string{
+	"From", "Reply-To", "Subject", "Date", "To", "Cc", "Message-ID", "In-Reply-To", "References",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+}
+
+// NewDKIMSigner returns a new DKIMSigner for the given domain, selector and private key.
+//
+// By default, the DKIMSigner uses the relaxed canonicalization algorithm for both, the header and the
+// body, and signs the common headers of a Msg. Optional DKIMSignerOption functions can be used to
+// override these defaults.
+//
+// Parameters:
+//   - domain: The signing domain (d= tag).
+//   - selector: The selector under which the public key is published in the DNS (s= tag).
+//   - key: The RSA (*rsa.PrivateKey) or Ed25519 (ed25519.PrivateKey) private key used for signing.
+//   - opts: Optional DKIMSignerOption functions to customize the DKIMSigner.
+//
+// Returns:
+//   - A pointer to the DKIMSigner.
+//   - An error if any of the provided parameters or options is invalid.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.5
+func NewDKIMSigner(domain, selector string, key crypto.Signer, opts ...DKIMSignerOption) (*DKIMSigner, error) {
+	if domain == "" {
+		return nil, ErrDKIMNoDomain
+	}
+	if selector == "" {
+		return nil, ErrDKIMNoSelector
+	}
+	signer := &DKIMSigner{
+		bodyCanon:   DKIMCanonicalizationRelaxed,
+		domain:      domain,
+		headerCanon: DKIMCanonicalizationRelaxed,
+		headers:     defaultDKIMHeaders,
+		key:         key,
+		selector:    selector,
+	}
+	if _, err := signer.algorithm(); err != nil {
+		return nil, err
+	}
+	for _, option := range opts {
+		if option == nil {
+			continue
+		}
+		if err := option(signer); err != nil {
+			return nil, fmt.Errorf("failed to apply DKIM signer option: %w", err)
+		}
+	}
+	return signer, nil
+}
+
+// WithDKIMCanonicalization sets the canonicalization algorithms for the header and the body.
+//
+// Parameters:
+//   - header: The DKIMCanonicalization used for the header.
+//   - body: The DKIMCanonicalization used for the body.
+//
+// Returns:
+//   - A DKIMSignerOption function that sets the canonicalization algorithms.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.4
+func WithDKIMCanonicalization(header, body DKIMCanonicalization) DKIMSignerOption {
+	return func(signer *DKIMSigner) error {
+		for _, canon := range []DKIMCanonicalization{header, body} {
+			if canon != DKIMCanonicalizationSimple && canon != DKIMCanonicalizationRelaxed {
+				return ErrDKIMInvalidCanonicalization
+			}
+		}
+		signer.headerCanon = header
+		signer.bodyCanon = body
+		return nil
+	}
+}
+
+// WithDKIMHeaders sets the list of headers that are signed by the DKIMSigner.
+//
+// Headers of the list that are not present in the Msg are skipped. The list needs to include
+// the "From" header.
+//
+// Parameters:
+//   - headers: The names of the headers to sign.
+//
+// Returns:
+//   - A DKIMSignerOption function that sets the list of headers to sign.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-5.4
+func WithDKIMHeaders(headers ...string) DKIMSignerOption {
+	return func(signer *DKIMSigner) error {
+		hasFrom := false
+		for _, header := range headers {
+			if strings.EqualFold(header, HeaderFrom.String()) {
+				hasFrom = true
+			}
+		}
+		if !hasFrom {
+			return ErrDKIMNoFromHeader
+		}
+		signer.headers = headers
+		return nil
+	}
+}
+
+// Handle satisfies the Middleware interface and returns a DKIM signed copy of the Msg.
+//
+// The returned Msg is a clone of the given Msg, to which the DKIM-Signature header is added, so
+// that the given Msg stays unchanged and can be signed again, e.g. when it is resent. If the Msg
+// cannot be rendered or signed, the returned Msg fails to render with the corresponding error, so
+// that it is never sent unsigned.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - A pointer to the signed copy of the Msg.
+func (s *DKIMSigner) Handle(msg *Msg) *Msg {
+	clone := msg.Clone()
+	signature, err := s.Sign(clone)
+	if err != nil {
+		return failingMsg(clone, err)
+	}
+	clone.SetGenHeaderPreformatted(HeaderDKIMSignature, signature)
+	return clone
+}
+
+// Type returns the MiddlewareType of the DKIMSigner.
+//
+// This method satisfies the Middleware interface.
+//
+// Returns:
+//   - MiddlewareTypeDKIM
+func (s *DKIMSigner) Type() MiddlewareType {
+	return MiddlewareTypeDKIM
+}
+
+// Sign renders the given Msg and returns the value of the DKIM-Signature header for it.
+//
+// The Msg is rendered without applying any Middleware and without an existing DKIM-Signature
+// header, which is removed from the Msg. If the Msg has no boundary set, a boundary is assigned
+// to it, so that the signed content stays the same when the Msg is written again. The boundary is
+// taken from the boundary generator of the Msg, if one is set, and is random otherwise.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - The value of the DKIM-Signature header, including folding whitespace.
+//   - An error if the Msg cannot be rendered or signed.
+func (s *DKIMSigner) Sign(msg *Msg) (string, error) {
+	algorithm, err := s.algorithm()
+	if err != nil {
+		return "", err
+	}
+	if msg.boundary == "" && msg.boundaryGenerator != nil {
+		msg.SetBoundary(msg.boundaryGenerator())
+	}
+	if msg.boundary == "" {
+		boundary, err := randomStringSecure(32)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate boundary: %w", err)
+		}
+		msg.SetBoundary(boundary)
+	}
+	delete(msg.preformHeader, HeaderDKIMSignature)
+
+	buffer := bytes.Buffer{}
+	mw := &msgWriter{
+		writer: &buffer, charset: msg.charset, encoder: msg.encoder,
+		foldColumn: msg.headerFoldColumn, maxLineLength: msg.maxLineLength,
+	}
+	mw.writeMsg(msg)
+	if mw.err != nil {
+		return "", fmt.Errorf("failed to render message for DKIM signing: %w", mw.err)
+	}
+	rawHeader, rawBody := splitDKIMMessage(buffer.Bytes())
+
+	bodyHash := sha256.Sum256(canonicalizeDKIMBody(rawBody, s.bodyCanon))
+	fields := parseDKIMHeaderFields(rawHeader)
+	var signedNames []string
+	var signedData bytes.Buffer
+	used := make(map[int]bool)
+	for _, name := range s.headers {
+		// Multiple instances of a header are signed from the bottom up
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(fields[i].name, name) {
+				continue
+			}
+			used[i] = true
+			signedNames = append(signedNames, strings.ToLower(name))
+			signedData.WriteString(canonicalizeDKIMHeader(fields[i].raw, s.headerCanon))
+			break
+		}
+	}
+
+	value := fmt.Sprintf("v=1; a=%s; c=%s/%s; d=%s; s=%s;\r\n t=%d; h=%s;\r\n bh=%s;\r\n b=",
+		algorithm, s.headerCanon, s.bodyCanon, s.domain, s.selector, time.Now().Unix(),
+		strings.Join(signedNames, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	sigHeader := canonicalizeDKIMHeader(fmt.Sprintf("%s: %s", HeaderDKIMSignature, value), s.headerCanon)
+	signedData.WriteString(strings.TrimSuffix(sigHeader, SingleNewLine))
+
+	signature, err := s.sign(signedData.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to sign message: %w", err)
+	}
+	return value + foldDKIMSignature(base64.StdEncoding.EncodeToString(signature)), nil
+}
+
+// algorithm returns the DKIM signing algorithm for the private key of the DKIMSigner.
+//
+// Returns:
+//   - The DKIM signing algorithm (a= tag).
+//   - ErrDKIMInvalidKey if the private key is nil or of an unsupported type.
+func (s *DKIMSigner) algorithm() (string, error) {
+	if s.key == nil {
+		return "", ErrDKIMInvalidKey
+	}
+	switch s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return "rsa-sha256", nil
+	case ed25519.PublicKey:
+		return "ed25519-sha256", nil
+	default:
+		return "", ErrDKIMInvalidKey
+	}
+}
+
+// sign hashes the given data with SHA-256 and signs the hash with the private key of the DKIMSigner.
+//
+// Parameters:
+//   - data: The canonicalized header data to sign.
+//
+// Returns:
+//   - The signature.
+//   - An error if signing fails.
+func (s *DKIMSigner) sign(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	if _, ok := s.key.Public().(ed25519.PublicKey); ok {
+		return s.key.Sign(rand.Reader, hash[:], crypto.Hash(0))
+	}
+	return s.key.Sign(rand.Reader, hash[:], crypto.SHA256)
+}
+
+// dkimHeaderField represents a single, possibly folded, header field of a rendered Msg.
+type dkimHeaderField struct {
+	name string
+	raw  string
+}
+
+// splitDKIMMessage splits a rendered Msg into its header and body.
+//
+// Parameters:
+//   - message: The rendered Msg.
+//
+// Returns:
+//   - The header, including the CRLF of the last header field.
+//   - The body.
+func splitDKIMMessage(message []byte) ([]byte, []byte) {
+	separator := []byte(SingleNewLine + SingleNewLine)
+	index := bytes.Index(message, separator)
+	if index < 0 {
+		return message, nil
+	}
+	return message[:index+len(SingleNewLine)], message[index+len(separator):]
+}
+
+// parseDKIMHeaderFields splits a rendered header into its header fields.
+//
+// Parameters:
+//   - header: The rendered header.
+//
+// Returns:
+//   - A slice of dkimHeaderField, holding the name and the raw value including CRLF of each field.
+func parseDKIMHeaderFields(header []byte) []dkimHeaderField {
+	var fields []dkimHeaderField
+	for _, line := range strings.SplitAfter(string(header), SingleNewLine) {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1].raw += line
+			continue
+		}
+		name := line
+		if index := strings.IndexByte(line, ':'); index >= 0 {
+			name = line[:index]
+		}
+		fields = append(fields, dkimHeaderField{name: strings.TrimSpace(name), raw: line})
+	}
+	return fields
+}
+
+// canonicalizeDKIMHeader canonicalizes a single header field.
+//
+// Parameters:
+//   - field: The raw header field, including CRLF.
+//   - canon: The DKIMCanonicalization to apply.
+//
+// Returns:
+//   - The canonicalized header field, terminated by CRLF.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.4.1
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.4.2
+func canonicalizeDKIMHeader(field string, canon DKIMCanonicalization) string {
+	if !strings.HasSuffix(field, SingleNewLine) {
+		field += SingleNewLine
+	}
+	if canon == DKIMCanonicalizationSimple {
+		return field
+	}
+	name, value := field, ""
+	if index := strings.IndexByte(field, ':'); index >= 0 {
+		name, value = field[:index], field[index+1:]
+	}
+	value = strings.ReplaceAll(value, SingleNewLine, "")
+	value = strings.Join(strings.FieldsFunc(value, isDKIMWhitespace), " ")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + value + SingleNewLine
+}
+
+// canonicalizeDKIMBody canonicalizes the body of a Msg.
+//
+// Parameters:
+//   - body: The rendered body.
+//   - canon: The DKIMCanonicalization to apply.
+//
+// Returns:
+//   - The canonicalized body.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.4.3
+//   - https://datatracker.ietf.org/doc/html/rfc6376#section-3.4.4
+func canonicalizeDKIMBody(body []byte, canon DKIMCanonicalization) []byte {
+	lines := strings.Split(string(body), SingleNewLine)
+	if canon == DKIMCanonicalizationRelaxed {
+		for i, line := range lines {
+			fields := strings.FieldsFunc(line, isDKIMWhitespace)
+			if len(fields) == 0 {
+				lines[i] = ""
+				continue
+			}
+			line = strings.Join(fields, " ")
+			if isDKIMWhitespace(rune(lines[i][0])) {
+				line = " " + line
+			}
+			lines[i] = line
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		if canon == DKIMCanonicalizationSimple {
+			return []byte(SingleNewLine)
+		}
+		return nil
+	}
+	return []byte(strings.Join(lines, SingleNewLine) + SingleNewLine)
+}
+
+// isDKIMWhitespace returns true if the given rune is whitespace as defined by the DKIM canonicalization.
+//
+// Parameters:
+//   - r: The rune to check.
+//
+// Returns:
+//   - true if the rune is a space or horizontal tab, false otherwise.
+func isDKIMWhitespace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// foldDKIMSignature folds the base64 encoded signature into lines of a length suitable for a header.
+//
+// Parameters:
+//   - signature: The base64 encoded signature.
+//
+// Returns:
+//   - The signature with folding whitespace inserted.
+func foldDKIMSignature(signature string) string {
+	const lineLength = 64
+	var folded strings.Builder
+	for len(signature) > lineLength {
+		folded.WriteString(signature[:lineLength])
+		folded.WriteString("\r\n ")
+		signature = signature[lineLength:]
+	}
+	folded.WriteString(signature)
+	return folded.String()
+}
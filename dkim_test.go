@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestCanonicalizeDKIM tests the DKIM canonicalization using the examples of RFC 6376
+func TestCanonicalizeDKIM(t *testing.T) {
+	header := []byte("A: X\r\nB : Y\t\r\n\tZ  \r\n")
+	body := []byte(" C \r\nD \t E\r\n\r\n\r\n")
+
+	tests := []struct {
+		name   string
+		canon  DKIMCanonicalization
+		header string
+		body   string
+	}{
+		{"simple", DKIMCanonicalizationSimple, "A: X\r\nB : Y\t\r\n\tZ  \r\n", " C \r\nD \t E\r\n"},
+		{"relaxed", DKIMCanonicalizationRelaxed, "a:X\r\nb:Y Z\r\n", " C\r\nD E\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canonHeader strings.Builder
+			for _, field := range parseDKIMHeaderFields(header) {
+				canonHeader.WriteString(canonicalizeDKIMHeader(field.raw, tt.canon))
+			}
+			if canonHeader.String() != tt.header {
+				t.Errorf("header canonicalization failed. Expected: %q, got: %q", tt.header, canonHeader.String())
+			}
+			if got := canonicalizeDKIMBody(body, tt.canon); string(got) != tt.body {
+				t.Errorf("body canonicalization failed. Expected: %q, got: %q", tt.body, got)
+			}
+		})
+	}
+
+	if got := canonicalizeDKIMBody(nil, DKIMCanonicalizationSimple); string(got) != "\r\n" {
+		t.Errorf("simple canonicalization of empty body failed. Expected: %q, got: %q", "\r\n", got)
+	}
+	if got := canonicalizeDKIMBody([]byte("\r\n\r\n"), DKIMCanonicalizationRelaxed); len(got) != 0 {
+		t.Errorf("relaxed canonicalization of empty body failed. Expected empty body, got: %q", got)
+	}
+}
+
+// TestNewDKIMSigner tests the validation of NewDKIMSigner and its options
+func TestNewDKIMSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	tests := []struct {
+		name     string
+		domain   string
+		selector string
+		key      crypto.Signer
+		opts     []DKIMSignerOption
+		want     error
+	}{
+		{"valid", "example.com", "default", key, nil, nil},
+		{"no domain", "", "default", key, nil, ErrDKIMNoDomain},
+		{"no selector", "example.com", "", key, nil, ErrDKIMNoSelector},
+		{"no key", "example.com", "default", nil, nil, ErrDKIMInvalidKey},
+		{
+			"invalid canonicalization", "example.com", "default", key,
+			[]DKIMSignerOption{WithDKIMCanonicalization("invalid", DKIMCanonicalizationSimple)},
+			ErrDKIMInvalidCanonicalization,
+		},
+		{
+			"headers without from", "example.com", "default", key,
+			[]DKIMSignerOption{WithDKIMHeaders("Subject", "To")}, ErrDKIMNoFromHeader,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDKIMSigner(tt.domain, tt.selector, tt.key, tt.opts...)
+			if tt.want == nil && err != nil {
+				t.Errorf("NewDKIMSigner failed: %s", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("NewDKIMSigner expected error: %s, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestDKIMSigner_Handle tests that a Msg signed by the DKIMSigner middleware can be verified
+func TestDKIMSigner_Handle(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %s", err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %s", err)
+	}
+	tests := []struct {
+		name   string
+		key    crypto.Signer
+		header DKIMCanonicalization
+		body   DKIMCanonicalization
+		verify func([]byte, []byte) error
+	}{
+		{
+			"rsa relaxed/relaxed", rsaKey, DKIMCanonicalizationRelaxed, DKIMCanonicalizationRelaxed,
+			func(hash, sig []byte) error {
+				return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, hash, sig)
+			},
+		},
+		{
+			"rsa simple/simple", rsaKey, DKIMCanonicalizationSimple, DKIMCanonicalizationSimple,
+			func(hash, sig []byte) error {
+				return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, hash, sig)
+			},
+		},
+		{
+			"ed25519 relaxed/simple", edKey, DKIMCanonicalizationRelaxed, DKIMCanonicalizationSimple,
+			func(hash, sig []byte) error {
+				if !ed25519.Verify(edPub, hash, sig) {
+					return errors.New("ed25519 signature verification failed")
+				}
+				return nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewDKIMSigner("example.com", "default", tt.key,
+				WithDKIMCanonicalization(tt.header, tt.body))
+			if err != nil {
+				t.Fatalf("failed to create DKIM signer: %s", err)
+			}
+			m := NewMsg(WithMiddleware(signer))
+			if err = m.From("Toni Tester <toni@example.com>"); err != nil {
+				t.Fatalf("failed to set FROM address: %s", err)
+			}
+			if err = m.To("recipient@example.com"); err != nil {
+				t.Fatalf("failed to set TO address: %s", err)
+			}
+			m.Subject("This is a DKIM signed message with a rather long subject to force folding of the header")
+			m.SetBodyString(TypeTextPlain, "This is the plain text body\nwith trailing whitespace  \n\n")
+			m.AddAlternativeString(TypeTextHTML, "<p>This is the HTML body</p>")
+			if err = m.AttachReader("attachment.txt", strings.NewReader("attachment content")); err != nil {
+				t.Fatalf("failed to attach reader: %s", err)
+			}
+
+			buffer := bytes.Buffer{}
+			if _, err = m.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			verifyDKIMSignature(t, buffer.Bytes(), tt.verify)
+			if _, ok := m.preformHeader[HeaderDKIMSignature]; ok {
+				t.Error("DKIM signer middleware was not supposed to modify the original Msg")
+			}
+
+			// Writing the message again must sign it again, without a stale signature
+			buffer.Reset()
+			if _, err = m.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			if count := bytes.Count(buffer.Bytes(), []byte(HeaderDKIMSignature.String()+":")); count != 1 {
+				t.Errorf("expected exactly 1 DKIM-Signature header, got: %d", count)
+			}
+			verifyDKIMSignature(t, buffer.Bytes(), tt.verify)
+		})
+	}
+}
+
+// TestDKIMSigner_Handle_boundary tests that the DKIMSigner middleware uses the boundary generator
+// of the Msg
+func TestDKIMSigner_Handle_boundary(t *testing.T) {
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %s", err)
+	}
+	signer, err := NewDKIMSigner("example.com", "default", edKey)
+	if err != nil {
+		t.Fatalf("failed to create DKIM signer: %s", err)
+	}
+	m := NewMsg(WithMiddleware(signer), WithBoundaryGenerator(func() string { return "testboundary" }))
+	if err = m.From("toni@example.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	m.AddAlternativeString(TypeTextHTML, "<p>This is the HTML body</p>")
+
+	buffer := bytes.Buffer{}
+	if _, err = m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buffer.String(), `boundary=testboundary`) {
+		t.Errorf("expected message to use the generated boundary, got: %s", buffer.String())
+	}
+	if m.boundary != "" {
+		t.Errorf("DKIM signer middleware was not supposed to set the boundary of the original Msg")
+	}
+	verifyDKIMSignature(t, buffer.Bytes(), func(hash, sig []byte) error {
+		if !ed25519.Verify(edPub, hash, sig) {
+			return errors.New("ed25519 signature verification failed")
+		}
+		return nil
+	})
+}
+
+// TestDKIMSigner_Handle_failure tests that a Msg that cannot be DKIM signed fails to render
+func TestDKIMSigner_Handle_failure(t *testing.T) {
+	m := NewMsg(WithMiddleware(&DKIMSigner{}))
+	if err := m.From("toni@example.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	buffer := bytes.Buffer{}
+	if _, err := m.WriteTo(&buffer); !errors.Is(err, ErrDKIMInvalidKey) {
+		t.Errorf("expected error: %s, got: %v", ErrDKIMInvalidKey, err)
+	}
+	if strings.Contains(buffer.String(), "This is the plain text body") {
+		t.Error("Msg that cannot be signed was not supposed to render its body")
+	}
+}
+
+// verifyDKIMSignature verifies the DKIM-Signature header of a rendered message
+func verifyDKIMSignature(t *testing.T, message []byte, verify func([]byte, []byte) error) {
+	t.Helper()
+	rawHeader, rawBody := splitDKIMMessage(message)
+	fields := parseDKIMHeaderFields(rawHeader)
+	var signature *dkimHeaderField
+	for i := range fields {
+		if fields[i].name == HeaderDKIMSignature.String() {
+			signature = &fields[i]
+		}
+	}
+	if signature == nil {
+		t.Fatal("message has no DKIM-Signature header")
+	}
+	tags := make(map[string]string)
+	value := strings.SplitN(signature.raw, ":", 2)[1]
+	for _, tag := range strings.Split(value, ";") {
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		tags[strings.TrimSpace(kv[0])] = strings.Join(strings.FieldsFunc(kv[1], func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+		}), "")
+	}
+	canons := strings.Split(tags["c"], "/")
+	if len(canons) != 2 {
+		t.Fatalf("invalid c= tag: %s", tags["c"])
+	}
+	headerCanon, bodyCanon := DKIMCanonicalization(canons[0]), DKIMCanonicalization(canons[1])
+
+	bodyHash := sha256.Sum256(canonicalizeDKIMBody(rawBody, bodyCanon))
+	if base64.StdEncoding.EncodeToString(bodyHash[:]) != tags["bh"] {
+		t.Fatalf("DKIM body hash mismatch. Expected: %s, got: %s", tags["bh"],
+			base64.StdEncoding.EncodeToString(bodyHash[:]))
+	}
+	if !strings.Contains(tags["h"], "from") {
+		t.Errorf("DKIM signed headers do not include the From header: %s", tags["h"])
+	}
+
+	var signedData bytes.Buffer
+	used := make(map[int]bool)
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(fields[i].name, name) {
+				continue
+			}
+			used[i] = true
+			signedData.WriteString(canonicalizeDKIMHeader(fields[i].raw, headerCanon))
+			break
+		}
+	}
+	emptied := regexp.MustCompile(`b=[^;]*$`).ReplaceAllString(strings.TrimSuffix(signature.raw, "\r\n"), "b=")
+	signedData.WriteString(strings.TrimSuffix(canonicalizeDKIMHeader(emptied, headerCanon), "\r\n"))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatalf("failed to decode DKIM signature: %s", err)
+	}
+	hash := sha256.Sum256(signedData.Bytes())
+	if err = verify(hash[:], sig); err != nil {
+		t.Errorf("DKIM signature verification failed: %s", err)
+	}
+}
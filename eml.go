@@ -5,6 +5,7 @@
 package mail
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/base64"
 	"errors"
@@ -16,8 +17,88 @@ import (
 	netmail "net/mail"
 	"os"
 	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
 )
 
+// EMLOption is a function type that modifies the behavior of the EML parser.
+type EMLOption func(*emlParseOptions)
+
+// emlParseOptions holds the settings that control how an EML is parsed into a Msg.
+type emlParseOptions struct {
+	// charsetReader, if set, is used to transcode text parts from their declared charset
+	// to UTF-8.
+	charsetReader func(charset string, input io.Reader) (io.Reader, error)
+
+	// lenientDate indicates that an unparsable Date header should not abort the parsing of
+	// the EML but be preserved as raw value instead.
+	lenientDate bool
+}
+
+// WithLenientDate treats an unparsable Date header as a soft error during EML parsing.
+//
+// By default, a Date header that cannot be parsed causes the EML parsing to fail. With this
+// option, the raw value of the Date header is preserved and can be retrieved via GetGenHeader,
+// the Msg is flagged accordingly (see Msg.HasInvalidDate) and parsing continues.
+//
+// Returns:
+//   - An EMLOption that enables lenient Date header parsing.
+func WithLenientDate() EMLOption {
+	return func(options *emlParseOptions) {
+		options.lenientDate = true
+	}
+}
+
+// WithCharsetReader transcodes the text parts of the EML to UTF-8 during parsing.
+//
+// By default, the body of a text part is kept in the charset declared in its Content-Type
+// header. With this option, every text/* part with a charset other than UTF-8 or US-ASCII is
+// passed through the given charsetReader, which needs to return a reader that provides the
+// content UTF-8 encoded. The charset of the part (and of the Content-Type header of single-part
+// messages) is updated to UTF-8 accordingly. This allows plugging in the decoders of
+// golang.org/x/text or similar packages. If charsetReader is nil, DefaultCharsetReader is used.
+//
+// An error returned by the charsetReader aborts the parsing of the EML.
+//
+// Parameters:
+//   - charsetReader: A function that returns a UTF-8 reader for the given charset and input.
+//
+// Returns:
+//   - An EMLOption that enables the charset transcoding of text parts.
+func WithCharsetReader(charsetReader func(charset string, input io.Reader) (io.Reader, error)) EMLOption {
+	return func(options *emlParseOptions) {
+		if charsetReader == nil {
+			charsetReader = DefaultCharsetReader
+		}
+		options.charsetReader = charsetReader
+	}
+}
+
+// DefaultCharsetReader returns a reader that transcodes the given input from the given charset
+// to UTF-8.
+//
+// The charset names and labels defined by the WHATWG Encoding Standard are supported, which
+// covers the common charsets like the ISO-8859 family, Windows-1252, KOI8-R, Shift_JIS,
+// ISO-2022-JP, EUC-KR or GB18030. It is meant to be used with WithCharsetReader.
+//
+// Parameters:
+//   - charset: The name of the charset the input is encoded in.
+//   - input: The io.Reader providing the encoded content.
+//
+// Returns:
+//   - An io.Reader providing the UTF-8 encoded content.
+//   - An error if the charset is not supported (ErrUnsupportedCharset).
+//
+// References:
+//   - https://encoding.spec.whatwg.org/#names-and-labels
+func DefaultCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, charset)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
 // EMLToMsgFromString parses a given EML string and returns a pre-filled Msg pointer.
 //
 // This function takes an EML formatted string, converts it into a bytes buffer, and then
@@ -31,15 +112,34 @@ import (
 //   - A pointer to the Msg object populated with the parsed data, and an error if parsing
 //     fails.
 func EMLToMsgFromString(emlString string) (*Msg, error) {
+	return EMLToMsgFromStringWithOptions(emlString)
+}
+
+// EMLToMsgFromStringWithOptions parses a given EML string using the provided EMLOption
+// values and returns a pre-filled Msg pointer.
+//
+// This function works like EMLToMsgFromString but allows to alter the parser behavior by
+// providing EMLOption values.
+//
+// Parameters:
+//   - emlString: A string containing the EML formatted message.
+//   - opts: Optional EMLOption values that modify the parser behavior.
+//
+// Returns:
+//   - A pointer to the Msg object populated with the parsed data, and an error if parsing
+//     fails.
+func EMLToMsgFromStringWithOptions(emlString string, opts ...EMLOption) (*Msg, error) {
 	eb := bytes.NewBufferString(emlString)
-	return EMLToMsgFromReader(eb)
+	return EMLToMsgFromReaderWithOptions(eb, opts...)
 }
 
 // EMLToMsgFromReader parses a reader that holds EML content and returns a pre-filled Msg pointer.
 //
 // This function reads EML content from the provided io.Reader and populates a Msg object
 // with the parsed data. It initializes the Msg and extracts headers and body parts from
-// the EML content. Any errors encountered during parsing are returned.
+// the EML content. The EML is parsed as a stream, i.e. the body is not buffered as a whole
+// but read part by part and decoded while it is read, so readers that return the data in
+// small chunks are supported as well. Any errors encountered during parsing are returned.
 //
 // Parameters:
 //   - reader: An io.Reader containing the EML formatted message.
@@ -48,19 +148,38 @@ func EMLToMsgFromString(emlString string) (*Msg, error) {
 //   - A pointer to the Msg object populated with the parsed data, and an error if parsing
 //     fails.
 func EMLToMsgFromReader(reader io.Reader) (*Msg, error) {
-	msg := &Msg{
-		addrHeader:    make(map[AddrHeader][]*netmail.Address),
-		genHeader:     make(map[Header][]string),
-		preformHeader: make(map[Header]string),
-		mimever:       MIME10,
+	return EMLToMsgFromReaderWithOptions(reader)
+}
+
+// EMLToMsgFromReaderWithOptions parses a reader that holds EML content using the provided
+// EMLOption values and returns a pre-filled Msg pointer.
+//
+// This function works like EMLToMsgFromReader but allows to alter the parser behavior by
+// providing EMLOption values.
+//
+// Parameters:
+//   - reader: An io.Reader containing the EML formatted message.
+//   - opts: Optional EMLOption values that modify the parser behavior.
+//
+// Returns:
+//   - A pointer to the Msg object populated with the parsed data, and an error if parsing
+//     fails.
+func EMLToMsgFromReaderWithOptions(reader io.Reader, opts ...EMLOption) (*Msg, error) {
+	msg := newEMLMsg()
+	options := &emlParseOptions{}
+	for _, option := range opts {
+		if option == nil {
+			continue
+		}
+		option(options)
 	}
 
-	parsedMsg, bodybuf, err := readEMLFromReader(reader)
+	parsedMsg, err := readEMLFromReader(reader)
 	if err != nil || parsedMsg == nil {
 		return msg, fmt.Errorf("failed to parse EML from reader: %w", err)
 	}
 
-	if err := parseEML(parsedMsg, bodybuf, msg); err != nil {
+	if err := parseEML(parsedMsg, msg, options); err != nil {
 		return msg, fmt.Errorf("failed to parse EML contents: %w", err)
 	}
 
@@ -70,9 +189,9 @@ func EMLToMsgFromReader(reader io.Reader) (*Msg, error) {
 // EMLToMsgFromFile opens and parses a .eml file at a provided file path and returns a
 // pre-filled Msg pointer.
 //
-// This function attempts to read and parse an EML file located at the specified file path.
-// It initializes a Msg object and populates it with the parsed headers and body. Any errors
-// encountered during the file operations or parsing are returned.
+// This function opens the EML file located at the specified file path and hands the file
+// handle to EMLToMsgFromReader, which populates a Msg object with the parsed headers and
+// body. Any errors encountered during the file operations or parsing are returned.
 //
 // Parameters:
 //   - filePath: The path to the .eml file to be parsed.
@@ -81,23 +200,44 @@ func EMLToMsgFromReader(reader io.Reader) (*Msg, error) {
 //   - A pointer to the Msg object populated with the parsed data, and an error if parsing
 //     fails.
 func EMLToMsgFromFile(filePath string) (*Msg, error) {
-	msg := &Msg{
+	return EMLToMsgFromFileWithOptions(filePath)
+}
+
+// EMLToMsgFromFileWithOptions opens and parses a .eml file at a provided file path using the
+// provided EMLOption values and returns a pre-filled Msg pointer.
+//
+// This function works like EMLToMsgFromFile but allows to alter the parser behavior by
+// providing EMLOption values.
+//
+// Parameters:
+//   - filePath: The path to the .eml file to be parsed.
+//   - opts: Optional EMLOption values that modify the parser behavior.
+//
+// Returns:
+//   - A pointer to the Msg object populated with the parsed data, and an error if parsing
+//     fails.
+func EMLToMsgFromFileWithOptions(filePath string, opts ...EMLOption) (*Msg, error) {
+	fileHandle, err := os.Open(filePath)
+	if err != nil {
+		return newEMLMsg(), fmt.Errorf("failed to open EML file: %w", err)
+	}
+	defer func() {
+		_ = fileHandle.Close()
+	}()
+	return EMLToMsgFromReaderWithOptions(fileHandle, opts...)
+}
+
+// newEMLMsg returns an empty Msg that is prepared to be populated by the EML parser.
+//
+// Returns:
+//   - A pointer to a Msg with initialized header maps and the MIME version set to 1.0.
+func newEMLMsg() *Msg {
+	return &Msg{
 		addrHeader:    make(map[AddrHeader][]*netmail.Address),
 		genHeader:     make(map[Header][]string),
 		preformHeader: make(map[Header]string),
 		mimever:       MIME10,
 	}
-
-	parsedMsg, bodybuf, err := readEML(filePath)
-	if err != nil || parsedMsg == nil {
-		return msg, fmt.Errorf("failed to parse EML file: %w", err)
-	}
-
-	if err := parseEML(parsedMsg, bodybuf, msg); err != nil {
-		return msg, fmt.Errorf("failed to parse EML contents: %w", err)
-	}
-
-	return msg, nil
 }
 
 // parseEML parses the EML's headers and body and inserts the parsed values into the Msg.
@@ -108,69 +248,86 @@ func EMLToMsgFromFile(filePath string) (*Msg, error) {
 //
 // Parameters:
 //   - parsedMsg: A pointer to the netmail.Message containing the parsed EML data.
-//   - bodybuf: A bytes.Buffer containing the body content of the EML message.
 //   - msg: A pointer to the Msg object to be populated with the parsed data.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
 //
 // Returns:
 //   - An error if any issues occur during the parsing process; otherwise, returns nil.
-func parseEML(parsedMsg *netmail.Message, bodybuf *bytes.Buffer, msg *Msg) error {
-	if err := parseEMLHeaders(&parsedMsg.Header, msg); err != nil {
+func parseEML(parsedMsg *netmail.Message, msg *Msg, options *emlParseOptions) error {
+	if err := parseEMLHeaders(&parsedMsg.Header, msg, options); err != nil {
 		return fmt.Errorf("failed to parse EML headers: %w", err)
 	}
-	if err := parseEMLBodyParts(parsedMsg, bodybuf, msg); err != nil {
+	if err := parseEMLBodyParts(parsedMsg, msg, options); err != nil {
 		return fmt.Errorf("failed to parse EML body parts: %w", err)
 	}
 	return nil
 }
 
-// readEML opens an EML file and uses net/mail to parse the header and body.
+// readEMLFromReader uses net/mail to parse the header and body from a given io.Reader.
 //
-// This function opens the specified EML file for reading and utilizes the net/mail package
-// to parse the message's headers and body. It returns the parsed message and a buffer
-// containing the body content, along with any errors encountered during the process.
+// This function reads the EML content from the provided io.Reader and uses the net/mail
+// package to parse the message's headers and body. Before the headers are parsed, the
+// header section is normalized by readEMLHeaderSection, so that EML files with a leading
+// UTF-8 byte order mark or with bare LF or mixed line endings are parsed as well. It returns
+// the parsed netmail.Message, whose Body reads the body of the EML from the given reader on
+// demand. Any errors encountered during the parsing process are returned.
 //
 // Parameters:
-//   - filePath: The path to the EML file to be opened and parsed.
+//   - reader: An io.Reader containing the EML formatted message.
 //
 // Returns:
-//   - A pointer to the parsed netmail.Message, a bytes.Buffer containing the body, and an
-//     error if any issues occur during file operations or parsing.
-func readEML(filePath string) (*netmail.Message, *bytes.Buffer, error) {
-	fileHandle, err := os.Open(filePath)
+//   - A pointer to the parsed netmail.Message and an error if any issues occur during parsing.
+func readEMLFromReader(reader io.Reader) (*netmail.Message, error) {
+	bufReader := bufio.NewReader(reader)
+	header, err := readEMLHeaderSection(bufReader)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open EML file: %w", err)
+		return nil, fmt.Errorf("failed to read EML header: %w", err)
 	}
-	defer func() {
-		_ = fileHandle.Close()
-	}()
-	return readEMLFromReader(fileHandle)
+	parsedMsg, err := netmail.ReadMessage(io.MultiReader(bytes.NewReader(header), bufReader))
+	if err != nil {
+		return parsedMsg, fmt.Errorf("failed to parse EML: %w", err)
+	}
+	return parsedMsg, nil
 }
 
-// readEMLFromReader uses net/mail to parse the header and body from a given io.Reader.
+// readEMLHeaderSection reads the header section of an EML up to and including the first blank
+// line and returns it in normalized form.
 //
-// This function reads the EML content from the provided io.Reader and uses the net/mail
-// package to parse the message's headers and body. It returns the parsed netmail.Message
-// along with a bytes.Buffer containing the body content. Any errors encountered during
-// the parsing process are returned.
+// A leading UTF-8 byte order mark is removed and every line of the header section is terminated
+// with CRLF, regardless of whether it ended with CRLF or a bare LF. The end of the header section
+// is detected on the first blank line in either line ending style. The body is not read and not
+// altered, so that it is left to the parser of the respective part.
 //
 // Parameters:
-//   - reader: An io.Reader containing the EML formatted message.
+//   - reader: A pointer to the bufio.Reader of the EML content, positioned at the start of the EML.
 //
 // Returns:
-//   - A pointer to the parsed netmail.Message, a bytes.Buffer containing the body, and an
-//     error if any issues occur during parsing.
-func readEMLFromReader(reader io.Reader) (*netmail.Message, *bytes.Buffer, error) {
-	parsedMsg, err := netmail.ReadMessage(reader)
-	if err != nil {
-		return parsedMsg, nil, fmt.Errorf("failed to parse EML: %w", err)
-	}
-
-	buf := bytes.Buffer{}
-	if _, err = buf.ReadFrom(parsedMsg.Body); err != nil {
-		return nil, nil, err
+//   - The normalized header section, including the terminating blank line if present.
+//   - An error if reading from the reader fails.
+func readEMLHeaderSection(reader *bufio.Reader) ([]byte, error) {
+	var header bytes.Buffer
+	for first := true; ; first = false {
+		line, err := reader.ReadBytes('\n')
+		if first {
+			line = bytes.TrimPrefix(line, []byte("\xef\xbb\xbf"))
+		}
+		if bytes.HasSuffix(line, []byte("\n")) {
+			content := bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			header.Write(content)
+			header.WriteString("\r\n")
+			if len(content) == 0 {
+				return header.Bytes(), nil
+			}
+		} else {
+			header.Write(line)
+		}
+		if errors.Is(err, io.EOF) {
+			return header.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	return parsedMsg, &buf, nil
 }
 
 // parseEMLHeaders parses the EML's headers and populates the Msg with relevant information.
@@ -182,15 +339,16 @@ func readEMLFromReader(reader io.Reader) (*netmail.Message, *bytes.Buffer, error
 // Parameters:
 //   - mailHeader: A pointer to the netmail.Header containing the EML headers.
 //   - msg: A pointer to the Msg object to be populated with parsed header information.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
 //
 // Returns:
 //   - An error if parsing the headers fails; otherwise, returns nil.
-func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg) error {
+func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg, options *emlParseOptions) error {
 	commonHeaders := []Header{
 		HeaderContentType, HeaderImportance, HeaderInReplyTo, HeaderListUnsubscribe,
 		HeaderListUnsubscribePost, HeaderMessageID, HeaderMIMEVersion, HeaderOrganization,
-		HeaderPrecedence, HeaderPriority, HeaderReferences, HeaderSubject, HeaderUserAgent,
-		HeaderXMailer, HeaderXMSMailPriority, HeaderXPriority,
+		HeaderPrecedence, HeaderPriority, HeaderReferences, HeaderReplyTo, HeaderReturnPath, HeaderSubject,
+		HeaderUserAgent, HeaderXMailer, HeaderXMSMailPriority, HeaderXPriority,
 	}
 
 	// Extract content type, charset and encoding first
@@ -203,6 +361,11 @@ func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg) error {
 			return fmt.Errorf(`failed to parse %q header: %w`, HeaderFrom, err)
 		}
 	}
+	if value := mailHeader.Get(HeaderSender.String()); value != "" {
+		if err := msg.SetSender(value); err != nil {
+			return fmt.Errorf(`failed to parse %q header: %w`, HeaderSender, err)
+		}
+	}
 	addrHeaders := map[AddrHeader]func(...string) error{
 		HeaderTo:  msg.To,
 		HeaderCc:  msg.Cc,
@@ -230,6 +393,9 @@ func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg) error {
 		switch {
 		case errors.Is(err, netmail.ErrHeaderNotPresent):
 			msg.SetDate()
+		case options.lenientDate:
+			msg.invalidDate = true
+			msg.SetGenHeader(HeaderDate, mailHeader.Get(HeaderDate.String()))
 		default:
 			return fmt.Errorf("failed to parse EML date: %w", err)
 		}
@@ -238,6 +404,11 @@ func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg) error {
 		msg.SetDateWithValue(date)
 	}
 
+	// Extract the trace headers, keeping every instance in the order of the EML
+	if values := (*mailHeader)[HeaderReceived.String()]; len(values) > 0 {
+		msg.SetGenHeader(HeaderReceived, append([]string(nil), values...)...)
+	}
+
 	// Extract common headers
 	for _, header := range commonHeaders {
 		if value := mailHeader.Get(header.String()); value != "" {
@@ -259,13 +430,14 @@ func parseEMLHeaders(mailHeader *netmail.Header, msg *Msg) error {
 // Msg object is populated with the appropriate body content.
 //
 // Parameters:
-//   - parsedMsg: A pointer to the netmail.Message containing the parsed EML data.
-//   - bodybuf: A bytes.Buffer containing the body content of the EML message.
+//   - parsedMsg: A pointer to the netmail.Message containing the parsed EML data. Its Body is read
+//     while the body parts are parsed.
 //   - msg: A pointer to the Msg object to be populated with the parsed body content.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
 //
 // Returns:
 //   - An error if any issues occur during the body parsing process; otherwise, returns nil.
-func parseEMLBodyParts(parsedMsg *netmail.Message, bodybuf *bytes.Buffer, msg *Msg) error {
+func parseEMLBodyParts(parsedMsg *netmail.Message, msg *Msg, options *emlParseOptions) error {
 	// Extract the transfer encoding of the body
 	mediatype, params, err := mime.ParseMediaType(parsedMsg.Header.Get(HeaderContentType.String()))
 	if err != nil {
@@ -286,13 +458,14 @@ func parseEMLBodyParts(parsedMsg *netmail.Message, bodybuf *bytes.Buffer, msg *M
 	switch {
 	case strings.EqualFold(mediatype, TypeTextPlain.String()),
 		strings.EqualFold(mediatype, TypeTextHTML.String()):
-		if err = parseEMLBodyPlain(mediatype, parsedMsg, bodybuf, msg); err != nil {
+		if err = parseEMLBodyPlain(mediatype, params, parsedMsg, msg, options); err != nil {
 			return fmt.Errorf("failed to parse plain body: %w", err)
 		}
 	case strings.EqualFold(mediatype, TypeMultipartAlternative.String()),
 		strings.EqualFold(mediatype, TypeMultipartMixed.String()),
-		strings.EqualFold(mediatype, TypeMultipartRelated.String()):
-		if err = parseEMLMultipart(params, bodybuf, msg); err != nil {
+		strings.EqualFold(mediatype, TypeMultipartRelated.String()),
+		strings.EqualFold(mediatype, TypeMultipartReport.String()):
+		if err = parseEMLMultipart(params, parsedMsg.Body, msg, options); err != nil {
 			return fmt.Errorf("failed to parse multipart body: %w", err)
 		}
 	default:
@@ -305,51 +478,86 @@ func parseEMLBodyParts(parsedMsg *netmail.Message, bodybuf *bytes.Buffer, msg *M
 //
 // This function handles the parsing of plain text messages based on their encoding. It
 // identifies the content transfer encoding and decodes the body content accordingly,
-// storing the result in the provided Msg object.
+// storing the result in the provided Msg object. The body is decoded while it is read from
+// the parsed message. If a charset reader is configured, the decoded body is transcoded to UTF-8.
 //
 // Parameters:
 //   - mediatype: The media type of the message (e.g., text/plain).
+//   - params: A map containing the parameters of the message's content type.
 //   - parsedMsg: A pointer to the netmail.Message containing the parsed EML data.
-//   - bodybuf: A bytes.Buffer containing the body content of the EML message.
 //   - msg: A pointer to the Msg object to be populated with the parsed body content.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
 //
 // Returns:
 //   - An error if any issues occur during the parsing of the plain body; otherwise, returns nil.
-func parseEMLBodyPlain(mediatype string, parsedMsg *netmail.Message, bodybuf *bytes.Buffer, msg *Msg) error {
+func parseEMLBodyPlain(mediatype string, params map[string]string, parsedMsg *netmail.Message,
+	msg *Msg, options *emlParseOptions,
+) error {
 	contentTransferEnc := parsedMsg.Header.Get(HeaderContentTransferEnc.String())
+	switch {
 	// If no Content-Transfer-Encoding is set, we can imply 7bit US-ASCII encoding
 	// https://datatracker.ietf.org/doc/html/rfc2045#section-6.1
-	if contentTransferEnc == "" || strings.EqualFold(contentTransferEnc, EncodingUSASCII.String()) {
+	case contentTransferEnc == "" || strings.EqualFold(contentTransferEnc, EncodingUSASCII.String()):
 		msg.SetEncoding(EncodingUSASCII)
-		msg.SetBodyString(ContentType(mediatype), bodybuf.String())
-		return nil
-	}
-	if strings.EqualFold(contentTransferEnc, NoEncoding.String()) {
+	case strings.EqualFold(contentTransferEnc, NoEncoding.String()):
 		msg.SetEncoding(NoEncoding)
-		msg.SetBodyString(ContentType(mediatype), bodybuf.String())
-		return nil
-	}
-	if strings.EqualFold(contentTransferEnc, EncodingQP.String()) {
+	case strings.EqualFold(contentTransferEnc, EncodingQP.String()):
 		msg.SetEncoding(EncodingQP)
-		qpReader := quotedprintable.NewReader(bodybuf)
-		qpBuffer := bytes.Buffer{}
-		if _, err := qpBuffer.ReadFrom(qpReader); err != nil {
-			return fmt.Errorf("failed to read quoted-printable body: %w", err)
+	case strings.EqualFold(contentTransferEnc, EncodingB64.String()):
+		msg.SetEncoding(EncodingB64)
+	default:
+		return fmt.Errorf("unsupported Content-Transfer-Encoding")
+	}
+	body, err := io.ReadAll(parsedMsg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	// The body is kept in its transfer encoded form and only decoded when it is read or written
+	msg.SetBodyString(ContentType(mediatype), "")
+	part := msg.parts[0]
+	if err = part.setEncodedContent(body, msg.encoding); err != nil {
+		return fmt.Errorf("failed to decode %s body: %w", msg.encoding, err)
+	}
+	if options.charsetReader != nil {
+		content, err := part.GetContent()
+		if err != nil {
+			return fmt.Errorf("failed to get content of body: %w", err)
+		}
+		transcoded, ok, err := transcodeEMLText(content, params["charset"], options)
+		if err != nil {
+			return err
+		}
+		if ok {
+			part.SetContent(string(transcoded))
+			msg.SetCharset(CharsetUTF8)
+			if msg.encoding == EncodingUSASCII {
+				msg.SetEncoding(EncodingQP)
+			}
+			params["charset"] = CharsetUTF8.String()
+			if _, hasContentType := msg.genHeader[HeaderContentType]; hasContentType {
+				msg.SetGenHeader(HeaderContentType, mime.FormatMediaType(mediatype, params))
+			}
 		}
-		msg.SetBodyString(ContentType(mediatype), qpBuffer.String())
-		return nil
 	}
-	if strings.EqualFold(contentTransferEnc, EncodingB64.String()) {
-		msg.SetEncoding(EncodingB64)
-		b64Decoder := base64.NewDecoder(base64.StdEncoding, bodybuf)
-		b64Buffer := bytes.Buffer{}
-		if _, err := b64Buffer.ReadFrom(b64Decoder); err != nil {
-			return fmt.Errorf("failed to read base64 body: %w", err)
+	setEMLFlowedParams(part, params)
+	return nil
+}
+
+// setEMLFlowedParams sets the format=flowed parameters of the Content-Type of a parsed EML part on the Part.
+//
+// Parameters:
+//   - part: The Part to set the parameters on.
+//   - params: A map containing the parameters of the content type of the parsed EML part.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4
+func setEMLFlowedParams(part *Part, params map[string]string) {
+	for _, name := range []string{"format", "delsp"} {
+		if value, ok := params[name]; ok {
+			part.SetContentTypeParam(name, value)
 		}
-		msg.SetBodyString(ContentType(mediatype), b64Buffer.String())
-		return nil
 	}
-	return fmt.Errorf("unsupported Content-Transfer-Encoding")
 }
 
 // parseEMLMultipart parses a multipart body part of an EML message.
@@ -360,20 +568,22 @@ func parseEMLBodyPlain(mediatype string, parsedMsg *netmail.Message, bodybuf *by
 //
 // Parameters:
 //   - params: A map containing the parameters from the multipart content type.
-//   - bodybuf: A bytes.Buffer containing the body content of the EML message.
+//   - body: An io.Reader providing the body content of the multipart section. The parts are read
+//     from it one after another.
 //   - msg: A pointer to the Msg object to be populated with the parsed body parts.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
 //
 // Returns:
 //   - An error if any issues occur during the parsing of the multipart body; otherwise,
 //     returns nil.
-func parseEMLMultipart(params map[string]string, bodybuf *bytes.Buffer, msg *Msg) error {
+func parseEMLMultipart(params map[string]string, body io.Reader, msg *Msg, options *emlParseOptions) error {
 	boundary, ok := params["boundary"]
 	if !ok {
 		return fmt.Errorf("no boundary tag found in multipart body")
 	}
-	multipartReader := multipart.NewReader(bodybuf, boundary)
+	multipartReader := multipart.NewReader(body, boundary)
 ReadNextPart:
-	multiPart, err := multipartReader.NextPart()
+	multiPart, err := multipartReader.NextRawPart()
 	defer func() {
 		if multiPart != nil {
 			_ = multiPart.Close()
@@ -392,13 +602,10 @@ ReadNextPart:
 					Header: netmail.Header(multiPart.Header),
 					Body:   multiPart,
 				}
-				relatedBuf := &bytes.Buffer{}
-				if _, err = relatedBuf.ReadFrom(multiPart); err != nil {
-					return fmt.Errorf("failed to read related multipart message to buffer: %w", err)
-				}
-				if err := parseEMLBodyParts(relatedPart, relatedBuf, msg); err != nil {
+				if err := parseEMLBodyParts(relatedPart, msg, options); err != nil {
 					return fmt.Errorf("failed to parse related multipart body: %w", err)
 				}
+				goto ReadNextPart
 			}
 		}
 
@@ -425,39 +632,47 @@ ReadNextPart:
 			goto ReadNextPart
 		}
 		part := msg.newPart(ContentType(contentType))
-		if charset, ok := optional["charset"]; ok {
-			part.SetCharset(Charset(charset))
+		charset, hasCharset := optional["charset"]
+		if _, mediaParams, mediaErr := mime.ParseMediaType(multiPartContentType[0]); mediaErr == nil {
+			charset, hasCharset = mediaParams["charset"]
+			setEMLFlowedParams(part, mediaParams)
 		}
-
-		mutliPartTransferEnc, ok := multiPart.Header[HeaderContentTransferEnc.String()]
-		if !ok {
-			// If CTE is empty we can assume that it's a quoted-printable CTE since the
-			// GO stdlib multipart packages deletes that header
-			// See: https://cs.opensource.google/go/go/+/refs/tags/go1.22.0:src/mime/multipart/multipart.go;l=161
-			mutliPartTransferEnc = []string{EncodingQP.String()}
+		if hasCharset {
+			part.SetCharset(Charset(charset))
 		}
 
+		// The parts are read raw, so that their content is kept in its transfer encoded form and
+		// only decoded when it is read or written. Parts without a Content-Transfer-Encoding are
+		// 7bit, but are written quoted-printable encoded, so that they stay 7bit safe.
+		mutliPartTransferEnc := multiPart.Header.Get(HeaderContentTransferEnc.String())
 		switch {
-		case strings.EqualFold(mutliPartTransferEnc[0], EncodingUSASCII.String()):
+		case mutliPartTransferEnc == "":
+			part.SetEncoding(EncodingQP)
+			err = part.setEncodedContent(multiPartData, EncodingUSASCII)
+		case strings.EqualFold(mutliPartTransferEnc, EncodingUSASCII.String()):
 			part.SetEncoding(EncodingUSASCII)
-			part.SetContent(string(multiPartData))
-		case strings.EqualFold(mutliPartTransferEnc[0], NoEncoding.String()):
+			err = part.setEncodedContent(multiPartData, EncodingUSASCII)
+		case strings.EqualFold(mutliPartTransferEnc, NoEncoding.String()):
 			part.SetEncoding(NoEncoding)
-			part.SetContent(string(multiPartData))
-		case strings.EqualFold(mutliPartTransferEnc[0], EncodingB64.String()):
+			err = part.setEncodedContent(multiPartData, NoEncoding)
+		case strings.EqualFold(mutliPartTransferEnc, EncodingB64.String()):
 			part.SetEncoding(EncodingB64)
-			if err = handleEMLMultiPartBase64Encoding(multiPartData, part); err != nil {
-				return fmt.Errorf("failed to handle multipart base64 transfer-encoding: %w", err)
-			}
-		case strings.EqualFold(mutliPartTransferEnc[0], EncodingQP.String()):
+			err = part.setEncodedContent(multiPartData, EncodingB64)
+		case strings.EqualFold(mutliPartTransferEnc, EncodingQP.String()):
 			part.SetEncoding(EncodingQP)
-			part.SetContent(string(multiPartData))
+			err = part.setEncodedContent(multiPartData, EncodingQP)
 		default:
-			return fmt.Errorf("unsupported Content-Transfer-Encoding: %s", mutliPartTransferEnc[0])
+			return fmt.Errorf("unsupported Content-Transfer-Encoding: %s", mutliPartTransferEnc)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode %s multipart: %w", part.GetEncoding(), err)
+		}
+		if err = transcodeEMLPart(part, charset, options); err != nil {
+			return err
 		}
 
 		msg.parts = append(msg.parts, part)
-		multiPart, err = multipartReader.NextPart()
+		multiPart, err = multipartReader.NextRawPart()
 	}
 	if !errors.Is(err, io.EOF) {
 		return fmt.Errorf("failed to read multipart: %w", err)
@@ -465,6 +680,72 @@ ReadNextPart:
 	return nil
 }
 
+// transcodeEMLPart transcodes the content of a text part to UTF-8.
+//
+// If a charset reader is configured and the part is a text part, its content is transcoded
+// from the given charset to UTF-8 and the charset of the part is updated accordingly. Since
+// the transcoded content might contain 8bit characters, a 7bit encoding is changed to
+// quoted-printable.
+//
+// Parameters:
+//   - part: A pointer to the Part to be transcoded.
+//   - charset: The charset declared in the Content-Type header of the part.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
+//
+// Returns:
+//   - An error if the transcoding fails; otherwise, returns nil.
+func transcodeEMLPart(part *Part, charset string, options *emlParseOptions) error {
+	if options.charsetReader == nil || !strings.HasPrefix(strings.ToLower(part.GetContentType().String()), "text/") {
+		return nil
+	}
+	content, err := part.GetContent()
+	if err != nil {
+		return fmt.Errorf("failed to get content of part: %w", err)
+	}
+	transcoded, ok, err := transcodeEMLText(content, charset, options)
+	if err != nil || !ok {
+		return err
+	}
+	part.SetContent(string(transcoded))
+	part.SetCharset(CharsetUTF8)
+	if part.GetEncoding() == EncodingUSASCII {
+		part.SetEncoding(EncodingQP)
+	}
+	return nil
+}
+
+// transcodeEMLText transcodes the given content from the given charset to UTF-8 using the
+// charset reader of the emlParseOptions.
+//
+// No transcoding takes place if no charset reader is configured or if the content is
+// already UTF-8 compatible, i. e. the charset is empty, UTF-8 or US-ASCII.
+//
+// Parameters:
+//   - content: The decoded content to be transcoded.
+//   - charset: The charset the content is encoded in.
+//   - options: A pointer to the emlParseOptions that control the parser behavior.
+//
+// Returns:
+//   - The transcoded content.
+//   - A boolean indicating whether the content was transcoded.
+//   - An error if the charset is not supported or the transcoding fails.
+func transcodeEMLText(content []byte, charset string, options *emlParseOptions) ([]byte, bool, error) {
+	charset = strings.Trim(strings.TrimSpace(charset), `"`)
+	if options.charsetReader == nil || charset == "" || strings.EqualFold(charset, CharsetUTF8.String()) ||
+		strings.EqualFold(charset, "utf8") || strings.EqualFold(charset, CharsetASCII.String()) {
+		return content, false, nil
+	}
+	reader, err := options.charsetReader(charset, bytes.NewReader(content))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to transcode charset %q: %w", charset, err)
+	}
+	transcoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to transcode charset %q: %w", charset, err)
+	}
+	return transcoded, true, nil
+}
+
 // parseEMLEncoding parses and determines the encoding of the message.
 //
 // This function extracts the content transfer encoding from the EML headers and sets the
@@ -509,28 +790,6 @@ func parseEMLContentTypeCharset(mailHeader *netmail.Header, msg *Msg) {
 	}
 }
 
-// handleEMLMultiPartBase64Encoding sets the content body of a base64 encoded Part.
-//
-// This function decodes the base64 encoded content of a multipart part and stores the
-// resulting content in the provided Part object. It handles any errors that occur during
-// the decoding process.
-//
-// Parameters:
-//   - multiPartData: A byte slice containing the base64 encoded data.
-//   - part: A pointer to the Part object where the decoded content will be stored.
-//
-// Returns:
-//   - An error if the base64 decoding fails; otherwise, returns nil.
-func handleEMLMultiPartBase64Encoding(multiPartData []byte, part *Part) error {
-	part.SetEncoding(EncodingB64)
-	content, err := base64.StdEncoding.DecodeString(string(multiPartData))
-	if err != nil {
-		return fmt.Errorf("failed to decode base64 part: %w", err)
-	}
-	part.SetContent(string(content))
-	return nil
-}
-
 // parseMultiPartHeader parses a multipart header and returns the value and optional parts as a map.
 //
 // This function splits a multipart header into its main value and any optional parameters,
@@ -576,28 +835,50 @@ func parseEMLAttachmentEmbed(contentDisposition []string, multiPart *multipart.P
 	if name, ok := optional["filename"]; ok {
 		filename = name[1 : len(name)-1]
 	}
+	// Prefer the fully parsed parameters, which include the RFC 2231 encoded filename* parameter
+	if _, params, err := mime.ParseMediaType(contentDisposition[0]); err == nil {
+		if name, ok := params["filename"]; ok && name != "" {
+			filename = name
+		}
+	}
 
 	var dataReader io.Reader
 	dataReader = multiPart
 	contentTransferEnc, _ := parseMultiPartHeader(multiPart.Header.Get(HeaderContentTransferEnc.String()))
-	b64Decoder := base64.NewDecoder(base64.StdEncoding, multiPart)
-	if strings.EqualFold(contentTransferEnc, EncodingB64.String()) {
-		dataReader = b64Decoder
+	switch {
+	case strings.EqualFold(contentTransferEnc, EncodingB64.String()):
+		dataReader = base64.NewDecoder(base64.StdEncoding, multiPart)
+	case strings.EqualFold(contentTransferEnc, EncodingQP.String()):
+		dataReader = quotedprintable.NewReader(multiPart)
+	}
+
+	// Keep the content type and the description of the part, so that the parsed files can be inspected
+	// and are written unchanged
+	var opts []FileOption
+	if mediaType, params, err := mime.ParseMediaType(multiPart.Header.Get(HeaderContentType.String())); err == nil {
+		opts = append(opts, WithFileContentType(ContentType(mediaType)))
+		if charset, ok := params["charset"]; ok {
+			opts = append(opts, WithFileCharset(Charset(charset)))
+		}
+	}
+	if description := multiPart.Header.Get(HeaderContentDescription.String()); description != "" {
+		wordDecoder := mime.WordDecoder{}
+		if decoded, err := wordDecoder.DecodeHeader(description); err == nil {
+			description = decoded
+		}
+		opts = append(opts, WithFileDescription(description))
 	}
 
 	switch strings.ToLower(cdType) {
 	case "attachment":
-		if err := msg.AttachReader(filename, dataReader); err != nil {
+		if err := msg.AttachReader(filename, dataReader, opts...); err != nil {
 			return fmt.Errorf("failed to attach multipart body: %w", err)
 		}
 	case "inline":
 		if contentID, _ := parseMultiPartHeader(multiPart.Header.Get(HeaderContentID.String())); contentID != "" {
-			if err := msg.EmbedReader(filename, dataReader, WithFileContentID(contentID)); err != nil {
-				return fmt.Errorf("failed to embed multipart body: %w", err)
-			}
-			return nil
+			opts = append(opts, WithFileContentID(contentID))
 		}
-		if err := msg.EmbedReader(filename, dataReader); err != nil {
+		if err := msg.EmbedReader(filename, dataReader, opts...); err != nil {
 			return fmt.Errorf("failed to embed multipart body: %w", err)
 		}
 	}
@@ -6,10 +6,14 @@ package mail
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net/mail"
 	"os"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -702,6 +706,131 @@ func TestEMLToMsgFromFile(t *testing.T) {
 	}
 }
 
+func TestEMLToMsgFromReader_SmallChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		eml  string
+		sub  string
+	}{
+		{
+			"Plain text quoted-printable", exampleMailPlainQP,
+			"Example mail // plain text quoted-printable",
+		},
+		{
+			"Plain text base64", exampleMailPlainB64,
+			"Example mail // plain text base64",
+		},
+		{
+			"Multipart with attachment", exampleMailPlainB64WithAttachment,
+			"Example mail // plain text base64 with attachment",
+		},
+	}
+	readers := []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{"OneByteReader", iotest.OneByteReader},
+		{"HalfReader", iotest.HalfReader},
+		{"DataErrReader", iotest.DataErrReader},
+	}
+	for _, tt := range tests {
+		for _, rt := range readers {
+			t.Run(tt.name+"/"+rt.name, func(t *testing.T) {
+				want, err := EMLToMsgFromString(tt.eml)
+				if err != nil {
+					t.Fatalf("failed to parse EML from string: %s", err)
+				}
+				msg, err := EMLToMsgFromReader(rt.wrap(strings.NewReader(tt.eml)))
+				if err != nil {
+					t.Fatalf("failed to parse EML from chunked reader: %s", err)
+				}
+				if msg.Encoding() != want.Encoding() {
+					t.Errorf("EMLToMsgFromReader failed: expected encoding: %s, but got: %s",
+						want.Encoding(), msg.Encoding())
+				}
+				if subject := msg.GetGenHeader(HeaderSubject); len(subject) > 0 &&
+					!strings.EqualFold(subject[0], tt.sub) {
+					t.Errorf("EMLToMsgFromReader failed: expected subject: %s, but got: %s",
+						tt.sub, subject[0])
+				}
+				if len(msg.GetParts()) != len(want.GetParts()) {
+					t.Fatalf("EMLToMsgFromReader failed: expected %d parts, got: %d",
+						len(want.GetParts()), len(msg.GetParts()))
+				}
+				for i, part := range msg.GetParts() {
+					got, err := part.GetContent()
+					if err != nil {
+						t.Fatalf("failed to get content of part %d: %s", i, err)
+					}
+					exp, err := want.GetParts()[i].GetContent()
+					if err != nil {
+						t.Fatalf("failed to get content of expected part %d: %s", i, err)
+					}
+					if !bytes.Equal(got, exp) {
+						t.Errorf("EMLToMsgFromReader failed: part %d content mismatch, expected: %q, got: %q",
+							i, exp, got)
+					}
+				}
+				if len(msg.GetAttachments()) != len(want.GetAttachments()) {
+					t.Errorf("EMLToMsgFromReader failed: expected %d attachments, got: %d",
+						len(want.GetAttachments()), len(msg.GetAttachments()))
+				}
+			})
+		}
+	}
+}
+
+// TestEMLToMsgFromReader_LargeStream tests that a large EML with nested multiparts and a large attachment is
+// parsed from a reader that returns the data byte by byte
+func TestEMLToMsgFromReader_LargeStream(t *testing.T) {
+	message := NewMsg()
+	if err := message.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := message.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	message.Subject("Large stream")
+	plainBody := strings.Repeat("This is a large plain text body. ", 20000)
+	message.SetBodyString(TypeTextPlain, plainBody)
+	message.AddAlternativeString(TypeTextHTML, "<p>"+plainBody+"</p>")
+	attachment := bytes.Repeat([]byte{0, 1, 2, 0xfe, 0xff, '\r', '\n'}, 150000)
+	if err := message.AttachReader("large.bin", bytes.NewReader(attachment)); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	var eml bytes.Buffer
+	if _, err := message.WriteTo(&eml); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+
+	parsed, err := EMLToMsgFromReader(iotest.OneByteReader(bytes.NewReader(eml.Bytes())))
+	if err != nil {
+		t.Fatalf("failed to parse EML from one byte reader: %s", err)
+	}
+	parts := parsed.GetParts()
+	if len(parts) != 2 {
+		t.Fatalf("EMLToMsgFromReader failed: expected 2 parts, got: %d", len(parts))
+	}
+	content, err := parts[0].GetContent()
+	if err != nil {
+		t.Fatalf("failed to get content of plain part: %s", err)
+	}
+	if string(content) != plainBody {
+		t.Errorf("EMLToMsgFromReader failed: plain part content mismatch, got %d bytes", len(content))
+	}
+	attachments := parsed.GetAttachments()
+	if len(attachments) != 1 {
+		t.Fatalf("EMLToMsgFromReader failed: expected 1 attachment, got: %d", len(attachments))
+	}
+	var attached bytes.Buffer
+	if _, err = attachments[0].Writer(&attached); err != nil {
+		t.Fatalf("failed to write attachment: %s", err)
+	}
+	if !bytes.Equal(attached.Bytes(), attachment) {
+		t.Errorf("EMLToMsgFromReader failed: attachment content mismatch, got %d bytes", attached.Len())
+	}
+}
+
 func TestEMLToMsgFromReaderFailing(t *testing.T) {
 	mailbuf := bytes.NewBufferString(exampleMailPlainBrokenFrom)
 	_, err := EMLToMsgFromReader(mailbuf)
@@ -830,6 +959,148 @@ func TestEMLToMsgFromStringBrokenDate(t *testing.T) {
 	}
 }
 
+func TestEMLToMsgFromStringWithOptions_LenientDate(t *testing.T) {
+	msg, err := EMLToMsgFromStringWithOptions(exampleMailPlainNoEncInvalidDate, WithLenientDate())
+	if err != nil {
+		t.Fatalf("EML with invalid date and lenient date parsing failed: %s", err)
+	}
+	if !msg.HasInvalidDate() {
+		t.Error("EML with invalid date was expected to be flagged as invalid date")
+	}
+	date := msg.GetGenHeader(HeaderDate)
+	if len(date) != 1 {
+		t.Fatalf("EML with invalid date expected 1 raw date value, got: %d", len(date))
+	}
+	if date[0] != "Inv, 99 Nov 9999 99:99:00 +0000" {
+		t.Errorf("EML with invalid date expected raw date value to be preserved, got: %s", date[0])
+	}
+	if subject := msg.GetGenHeader(HeaderSubject); len(subject) != 1 ||
+		subject[0] != "Example mail // plain text without encoding" {
+		t.Errorf("EML with invalid date expected subject to be parsed, got: %v", subject)
+	}
+	if len(msg.GetParts()) != 1 {
+		t.Errorf("EML with invalid date expected 1 part, got: %d", len(msg.GetParts()))
+	}
+
+	msg, err = EMLToMsgFromStringWithOptions(exampleMailPlainNoEnc, WithLenientDate())
+	if err != nil {
+		t.Fatalf("EML with valid date and lenient date parsing failed: %s", err)
+	}
+	if msg.HasInvalidDate() {
+		t.Error("EML with valid date was not expected to be flagged as invalid date")
+	}
+
+	if _, err = EMLToMsgFromStringWithOptions(exampleMailPlainNoEncInvalidDate); err == nil {
+		t.Error("EML with invalid date and without lenient date option was supposed to fail, but didn't")
+	}
+}
+
+func TestEMLToMsgFromStringWithOptions_CharsetReader(t *testing.T) {
+	header := "Date: Wed, 01 Nov 2023 00:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Subject: Charset test\r\n" +
+		"From: <go-mail@go-mail.dev>\r\n" +
+		"To: <go-mail+test@go-mail.dev>\r\n"
+	plain := header +
+		"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Gr=FC=DFe aus K=F6ln\r\n"
+	multi := header +
+		"Content-Type: multipart/mixed; boundary=\"abc\"\r\n" +
+		"\r\n" +
+		"--abc\r\n" +
+		"Content-Type: text/plain; charset=Shift_JIS\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"k/qWew==\r\n" +
+		"--abc\r\n" +
+		"Content-Type: text/html; charset=windows-1252\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"<p>=80 100</p>\r\n" +
+		"--abc--\r\n"
+
+	t.Run("single part", func(t *testing.T) {
+		msg, err := EMLToMsgFromStringWithOptions(plain, WithCharsetReader(nil))
+		if err != nil {
+			t.Fatalf("failed to parse EML with charset reader: %s", err)
+		}
+		parts := msg.GetParts()
+		if len(parts) != 1 {
+			t.Fatalf("expected 1 part, got: %d", len(parts))
+		}
+		content, err := parts[0].GetContent()
+		if err != nil {
+			t.Fatalf("failed to get part content: %s", err)
+		}
+		if string(content) != "Grüße aus Köln\r\n" {
+			t.Errorf("expected transcoded content, got: %q", content)
+		}
+		if msg.charset != CharsetUTF8 {
+			t.Errorf("expected charset to be updated to %s, got: %s", CharsetUTF8, msg.charset)
+		}
+		if ct := msg.GetGenHeader(HeaderContentType); len(ct) != 1 || ct[0] != "text/plain; charset=UTF-8" {
+			t.Errorf("expected Content-Type header to be updated, got: %v", ct)
+		}
+	})
+	t.Run("multipart", func(t *testing.T) {
+		msg, err := EMLToMsgFromStringWithOptions(multi, WithCharsetReader(DefaultCharsetReader))
+		if err != nil {
+			t.Fatalf("failed to parse EML with charset reader: %s", err)
+		}
+		parts := msg.GetParts()
+		if len(parts) != 2 {
+			t.Fatalf("expected 2 parts, got: %d", len(parts))
+		}
+		for i, want := range []string{"日本", "<p>€ 100</p>"} {
+			content, err := parts[i].GetContent()
+			if err != nil {
+				t.Fatalf("failed to get part content: %s", err)
+			}
+			if !strings.Contains(string(content), want) {
+				t.Errorf("expected part %d to contain %q, got: %q", i, want, content)
+			}
+			if parts[i].GetCharset() != CharsetUTF8 {
+				t.Errorf("expected part %d charset to be %s, got: %s", i, CharsetUTF8, parts[i].GetCharset())
+			}
+		}
+	})
+	t.Run("without charset reader", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(plain)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		content, err := msg.GetParts()[0].GetContent()
+		if err != nil {
+			t.Fatalf("failed to get part content: %s", err)
+		}
+		if string(content) != "Gr\xfc\xdfe aus K\xf6ln\r\n" {
+			t.Errorf("expected untouched content, got: %q", content)
+		}
+	})
+	t.Run("custom charset reader", func(t *testing.T) {
+		var charsets []string
+		reader := func(charset string, input io.Reader) (io.Reader, error) {
+			charsets = append(charsets, charset)
+			return input, nil
+		}
+		if _, err := EMLToMsgFromStringWithOptions(multi, WithCharsetReader(reader)); err != nil {
+			t.Fatalf("failed to parse EML with custom charset reader: %s", err)
+		}
+		if len(charsets) != 2 || charsets[0] != "Shift_JIS" || charsets[1] != "windows-1252" {
+			t.Errorf("expected custom charset reader to be called for both parts, got: %v", charsets)
+		}
+	})
+	t.Run("unknown charset", func(t *testing.T) {
+		eml := strings.Replace(plain, "ISO-8859-1", "x-unknown", 1)
+		_, err := EMLToMsgFromStringWithOptions(eml, WithCharsetReader(nil))
+		if !errors.Is(err, ErrUnsupportedCharset) {
+			t.Errorf("expected error: %s, got: %v", ErrUnsupportedCharset, err)
+		}
+	})
+}
+
 func TestEMLToMsgFromStringBrokenFrom(t *testing.T) {
 	_, err := EMLToMsgFromString(exampleMailPlainBrokenFrom)
 	if err == nil {
@@ -844,6 +1115,454 @@ func TestEMLToMsgFromStringBrokenTo(t *testing.T) {
 	}
 }
 
+func TestEMLToMsgFromString_addresses(t *testing.T) {
+	eml := "Date: Wed, 01 Nov 2023 00:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Message-ID: <1305604950.683004066175.AAAAAAAAaaaaaaaaB@go-mail.dev>\r\n" +
+		"Subject: Address test\r\n" +
+		"From: =?UTF-8?Q?J=C3=BCrgen_Tester?= <from@domain.tld>\r\n" +
+		"To: \"Tester, Toni\" <toni@domain.tld>, Team: alice@domain.tld, =?UTF-8?B?QsO2Yg==?= <bob@domain.tld>;\r\n" +
+		"Cc: carol@domain.tld\r\n" +
+		"Reply-To: \"Support, Team\" <support@domain.tld>, =?ISO-8859-1?Q?H=E9l=E8ne?= <helene@domain.tld>\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: 7bit\r\n" +
+		"\r\n" +
+		"Test body\r\n"
+	msg, err := EMLToMsgFromString(eml)
+	if err != nil {
+		t.Fatalf("EMLToMsgFromString failed: %s", err)
+	}
+	tests := []struct {
+		name      string
+		addresses []*mail.Address
+		want      []mail.Address
+	}{
+		{"From", msg.GetFrom(), []mail.Address{{Name: "Jürgen Tester", Address: "from@domain.tld"}}},
+		{
+			"To", msg.GetTo(), []mail.Address{
+				{Name: "Tester, Toni", Address: "toni@domain.tld"},
+				{Address: "alice@domain.tld"},
+				{Name: "Böb", Address: "bob@domain.tld"},
+			},
+		},
+		{"Cc", msg.GetCc(), []mail.Address{{Address: "carol@domain.tld"}}},
+	}
+	replyTo, err := msg.GetReplyTo()
+	if err != nil {
+		t.Fatalf("GetReplyTo failed: %s", err)
+	}
+	tests = append(tests, struct {
+		name      string
+		addresses []*mail.Address
+		want      []mail.Address
+	}{
+		"Reply-To", replyTo, []mail.Address{
+			{Name: "Support, Team", Address: "support@domain.tld"},
+			{Name: "Hélène", Address: "helene@domain.tld"},
+		},
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.addresses) != len(tt.want) {
+				t.Fatalf("expected %d addresses, got: %v", len(tt.want), tt.addresses)
+			}
+			for i, address := range tt.addresses {
+				if *address != tt.want[i] {
+					t.Errorf("expected address: %+v, got: %+v", tt.want[i], *address)
+				}
+			}
+		})
+	}
+}
+
+func TestMsg_GetBodyDecoded(t *testing.T) {
+	latin1 := "Date: Wed, 01 Nov 2023 00:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Subject: Latin1 test\r\n" +
+		"From: <go-mail@go-mail.dev>\r\n" +
+		"To: <go-mail+test@go-mail.dev>\r\n" +
+		"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Gr=FC=DFe aus K=F6ln\r\n"
+	unsupported := strings.Replace(latin1, "charset=ISO-8859-1", "charset=x-unknown", 1)
+	koi8r := strings.Replace(strings.Replace(latin1, "charset=ISO-8859-1", "charset=KOI8-R", 1),
+		"Gr=FC=DFe aus K=F6ln", "=F0=D2=C9=D7=C5=D4", 1)
+	tests := []struct {
+		name        string
+		eml         string
+		contentType ContentType
+		want        string
+		wantErr     error
+	}{
+		{
+			"plain base64", exampleMailPlainB64, "",
+			"Dear Customer,\n\nThis is a test mail. Please do not reply", nil,
+		},
+		{"plain quoted-printable", exampleMailPlainQP, TypeTextPlain, "Dear Customer,", nil},
+		{
+			"multipart base64 plain", exampleMailMultipartMixedAlternativeRelated, TypeTextPlain,
+			"marked “PAID”.", nil,
+		},
+		{"multipart base64 html", exampleMailMultipartMixedAlternativeRelated, TypeTextHTML, "<p>", nil},
+		{"latin1 quoted-printable", latin1, "", "Grüße aus Köln", nil},
+		{"koi8-r quoted-printable", koi8r, "", "Привет", nil},
+		{"missing content type", exampleMailPlainB64, TypeTextHTML, "", ErrNoBodyPart},
+		{"unsupported charset", unsupported, "", "", ErrUnsupportedCharset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := EMLToMsgFromString(tt.eml)
+			if err != nil {
+				t.Fatalf("failed to parse EML: %s", err)
+			}
+			body, err := msg.GetBodyDecoded(tt.contentType)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetBodyDecoded expected error: %s, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBodyDecoded failed: %s", err)
+			}
+			if !strings.Contains(string(body), tt.want) {
+				t.Errorf("GetBodyDecoded failed. Expected body to contain: %q, got: %q", tt.want, body)
+			}
+		})
+	}
+}
+
+func TestMsg_BodyReader(t *testing.T) {
+	latin1 := "Date: Wed, 01 Nov 2023 00:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Subject: Latin1 test\r\n" +
+		"From: <go-mail@go-mail.dev>\r\n" +
+		"To: <go-mail+test@go-mail.dev>\r\n" +
+		"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"R3L832UgYXVzIEv2bG4=\r\n"
+	unsupported := strings.Replace(latin1, "charset=ISO-8859-1", "charset=x-unknown", 1)
+	tests := []struct {
+		name        string
+		eml         string
+		contentType ContentType
+		wantErr     error
+	}{
+		{"plain base64", exampleMailPlainB64, "", nil},
+		{"plain quoted-printable", exampleMailPlainQP, TypeTextPlain, nil},
+		{"multipart base64 html", exampleMailMultipartMixedAlternativeRelated, TypeTextHTML, nil},
+		{"latin1 base64", latin1, "", nil},
+		{"missing content type", exampleMailPlainB64, TypeTextHTML, ErrNoBodyPart},
+		{"unsupported charset", unsupported, "", ErrUnsupportedCharset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := EMLToMsgFromString(tt.eml)
+			if err != nil {
+				t.Fatalf("failed to parse EML: %s", err)
+			}
+			reader, err := msg.BodyReader(tt.contentType)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("BodyReader expected error: %s, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BodyReader failed: %s", err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("failed to read body: %s", err)
+			}
+			if err = reader.Close(); err != nil {
+				t.Errorf("failed to close body reader: %s", err)
+			}
+			want, err := msg.GetBodyDecoded(tt.contentType)
+			if err != nil {
+				t.Fatalf("GetBodyDecoded failed: %s", err)
+			}
+			if !bytes.Equal(body, want) {
+				t.Errorf("BodyReader failed. Expected body: %q, got: %q", want, body)
+			}
+		})
+	}
+	t.Run("latin1 is converted to UTF-8", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(latin1)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		reader, err := msg.BodyReader(TypeTextPlain)
+		if err != nil {
+			t.Fatalf("BodyReader failed: %s", err)
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		body, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("failed to read body: %s", err)
+		}
+		if string(body) != "Grüße aus Köln" {
+			t.Errorf("BodyReader failed. Expected body: %q, got: %q", "Grüße aus Köln", body)
+		}
+	})
+	t.Run("streamed body writer", func(t *testing.T) {
+		chunk := bytes.Repeat([]byte("go-mail "), 512)
+		msg := NewMsg()
+		msg.SetBodyWriter(TypeTextPlain, func(writer io.Writer) (int64, error) {
+			var written int64
+			for i := 0; i < 1024; i++ {
+				n, err := writer.Write(chunk)
+				written += int64(n)
+				if err != nil {
+					return written, err
+				}
+			}
+			return written, nil
+		})
+		reader, err := msg.BodyReader(TypeTextPlain)
+		if err != nil {
+			t.Fatalf("BodyReader failed: %s", err)
+		}
+		buffer := make([]byte, len(chunk))
+		if _, err = io.ReadFull(reader, buffer); err != nil {
+			t.Fatalf("failed to read body: %s", err)
+		}
+		if !bytes.Equal(buffer, chunk) {
+			t.Errorf("BodyReader failed. Unexpected start of body: %q", buffer)
+		}
+		if err = reader.Close(); err != nil {
+			t.Errorf("failed to close body reader: %s", err)
+		}
+	})
+	t.Run("failing body writer", func(t *testing.T) {
+		msg := NewMsg()
+		msg.SetBodyWriter(TypeTextPlain, func(io.Writer) (int64, error) {
+			return 0, errors.New("failed")
+		})
+		reader, err := msg.BodyReader(TypeTextPlain)
+		if err != nil {
+			t.Fatalf("BodyReader failed: %s", err)
+		}
+		if _, err = io.ReadAll(reader); err == nil {
+			t.Error("reading the body of a failing body writer was supposed to fail, but didn't")
+		}
+		_ = reader.Close()
+	})
+}
+
+func TestEMLToMsgFromString_KeepsEncodedContent(t *testing.T) {
+	multipartQP := "Date: Wed, 01 Nov 2023 00:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Subject: Quoted-printable multipart\r\n" +
+		"From: <go-mail@go-mail.dev>\r\n" +
+		"To: <go-mail+test@go-mail.dev>\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"bnd\"\r\n" +
+		"\r\n" +
+		"--bnd\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Gr=C3=BC=C3=9Fe aus K=C3=B6ln\r\n" +
+		"--bnd\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"Content-Disposition: attachment; filename=\"note.txt\"\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"K=C3=B6ln\r\n" +
+		"--bnd--\r\n"
+	tests := []struct {
+		name    string
+		eml     string
+		encoded string
+		decoded string
+	}{
+		{
+			"plain base64", exampleMailPlainB64,
+			"RGVhciBDdXN0b21lciwKClRoaXMgaXMgYSB0ZXN0IG1haWwuIFBsZWFzZSBkbyBub3QgcmVwbHkg",
+			"Dear Customer,\n\nThis is a test mail.",
+		},
+		{"plain quoted-printable", exampleMailPlainQP, "very lo=\nng so it", "very long so it"},
+		{"multipart quoted-printable", multipartQP, "Gr=C3=BC=C3=9Fe aus K=C3=B6ln", "Grüße aus Köln"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := EMLToMsgFromString(tt.eml)
+			if err != nil {
+				t.Fatalf("failed to parse EML: %s", err)
+			}
+			part, _, err := msg.bodyPart(TypeTextPlain)
+			if err != nil {
+				t.Fatalf("failed to get body part: %s", err)
+			}
+			if !strings.Contains(string(part.encodedContent), tt.encoded) {
+				t.Errorf("expected part to keep its encoded content %q, got: %q", tt.encoded,
+					part.encodedContent)
+			}
+			reader, err := msg.BodyReader(TypeTextPlain)
+			if err != nil {
+				t.Fatalf("BodyReader failed: %s", err)
+			}
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("failed to read body: %s", err)
+			}
+			if !strings.Contains(string(body), tt.decoded) {
+				t.Errorf("expected decoded body to contain %q, got: %q", tt.decoded, body)
+			}
+			content, err := part.GetContent()
+			if err != nil {
+				t.Fatalf("failed to get content of part: %s", err)
+			}
+			if !bytes.Equal(content, body) {
+				t.Errorf("expected part content to match the body reader, got: %q", content)
+			}
+		})
+	}
+	t.Run("quoted-printable attachment is decoded", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(multipartQP)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		attachments := msg.GetAttachments()
+		if len(attachments) != 1 {
+			t.Fatalf("expected 1 attachment, got: %d", len(attachments))
+		}
+		var buffer bytes.Buffer
+		if _, err = attachments[0].Writer(&buffer); err != nil {
+			t.Fatalf("failed to write attachment: %s", err)
+		}
+		if buffer.String() != "Köln" {
+			t.Errorf("expected decoded attachment content %q, got: %q", "Köln", buffer.String())
+		}
+	})
+	t.Run("SetContent drops the encoded content", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(exampleMailPlainB64)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		msg.parts[0].SetContent("replaced")
+		if msg.parts[0].encodedContent != nil {
+			t.Error("expected encoded content to be dropped after SetContent")
+		}
+		body, err := msg.GetBodyDecoded(TypeTextPlain)
+		if err != nil {
+			t.Fatalf("GetBodyDecoded failed: %s", err)
+		}
+		if string(body) != "replaced" {
+			t.Errorf("expected body %q, got: %q", "replaced", body)
+		}
+	})
+}
+
+func TestMsg_TopReply(t *testing.T) {
+	t.Run("signature in parsed EML", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(exampleMailPlainNoEnc)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		reply, err := msg.TopReply()
+		if err != nil {
+			t.Fatalf("TopReply failed: %s", err)
+		}
+		if !strings.HasSuffix(reply, "Thank your for your business!\nThe go-mail team") {
+			t.Errorf("TopReply failed. Expected the signature to be removed, got: %q", reply)
+		}
+	})
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"no quote", "Thanks, that works.\r\n", "Thanks, that works."},
+		{"quote block", "Thanks, that works.\r\n\r\n> Did you try to restart it?\r\n", "Thanks, that works."},
+		{
+			"attribution", "Thanks, that works.\r\n\r\nOn Mon, 1 Jan 2024 at 10:00, Toni Tester " +
+				"<toni@example.com> wrote:\r\n> Did you try to restart it?\r\n",
+			"Thanks, that works.",
+		},
+		{
+			"wrapped attribution", "Thanks, that works.\r\n\r\nOn Mon, 1 Jan 2024 at 10:00, Toni Tester <\r\n" +
+				"toni@example.com> wrote:\r\n\r\n> Did you try to restart it?\r\n",
+			"Thanks, that works.",
+		},
+		{
+			"signature", "Thanks, that works.\r\nOn my way home now.\r\n-- \r\nToni Tester\r\n",
+			"Thanks, that works.\nOn my way home now.",
+		},
+		{
+			"original message", "See below.\r\n\r\n-----Original Message-----\r\nFrom: Toni Tester\r\n",
+			"See below.",
+		},
+		{
+			"forwarded message", "FYI\r\n\r\n---------- Forwarded message ---------\r\nFrom: Toni Tester\r\n",
+			"FYI",
+		},
+		{"quote only", "> Did you try to restart it?\r\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMsg()
+			msg.SetBodyString(TypeTextPlain, tt.body)
+			reply, err := msg.TopReply()
+			if err != nil {
+				t.Fatalf("TopReply failed: %s", err)
+			}
+			if reply != tt.want {
+				t.Errorf("TopReply failed. Expected: %q, got: %q", tt.want, reply)
+			}
+		})
+	}
+	t.Run("no plain text body", func(t *testing.T) {
+		msg := NewMsg()
+		msg.SetBodyString(TypeTextHTML, "<p>Thanks</p>")
+		if _, err := msg.TopReply(); !errors.Is(err, ErrNoBodyPart) {
+			t.Errorf("TopReply expected error: %s, got: %v", ErrNoBodyPart, err)
+		}
+	})
+}
+
+func TestEMLToMsgFromString_lineEndings(t *testing.T) {
+	crlf := strings.ReplaceAll(exampleMailPlainNoEnc, "\n", "\r\n")
+	headerEnd := strings.Index(crlf, "\r\n\r\n")
+	tests := []struct {
+		name string
+		eml  string
+	}{
+		{"LF only", exampleMailPlainNoEnc},
+		{"CRLF", crlf},
+		{"BOM with LF", "\ufeff" + exampleMailPlainNoEnc},
+		{"BOM with CRLF", "\ufeff" + crlf},
+		{"mixed line endings", strings.Replace(crlf[:headerEnd], "\r\n", "\n", 3) + "\n\r\n" + crlf[headerEnd+4:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := EMLToMsgFromString(tt.eml)
+			if err != nil {
+				t.Fatalf("failed to parse EML: %s", err)
+			}
+			if subject := msg.GetGenHeader(HeaderSubject); len(subject) != 1 ||
+				subject[0] != "Example mail // plain text without encoding" {
+				t.Errorf("EMLToMsgFromString failed. Unexpected subject: %q", subject)
+			}
+			if date := msg.GetGenHeader(HeaderDate); len(date) != 1 || date[0] != "Wed, 01 Nov 2023 00:00:00 +0000" {
+				t.Errorf("EMLToMsgFromString failed. Unexpected date: %q", date)
+			}
+			body, err := msg.GetBodyDecoded(TypeTextPlain)
+			if err != nil {
+				t.Fatalf("GetBodyDecoded failed: %s", err)
+			}
+			if !strings.HasPrefix(string(body), "Dear Customer,") {
+				t.Errorf("EMLToMsgFromString failed. Expected body to start after the first blank line, got: %q",
+					body)
+			}
+		})
+	}
+}
+
 func TestEMLToMsgFromStringNoBoundary(t *testing.T) {
 	_, err := EMLToMsgFromString(exampleMailPlainB64WithAttachmentNoBoundary)
 	if err == nil {
@@ -895,6 +1614,41 @@ func TestEMLToMsgFromStringWithEmbed(t *testing.T) {
 	}
 }
 
+// TestEMLToMsgFromString_GetEmbeds tests that the embeds of a parsed EML can be inspected via Msg.GetEmbeds
+func TestEMLToMsgFromString_GetEmbeds(t *testing.T) {
+	msg, err := EMLToMsgFromString(exampleMailPlainB64WithEmbed)
+	if err != nil {
+		t.Fatalf("EML with embed failed: %s", err)
+	}
+	embeds := msg.GetEmbeds()
+	if len(embeds) != 1 {
+		t.Fatalf("GetEmbeds failed: expected 1 embed, got: %d", len(embeds))
+	}
+	if embeds[0].Name != "pixel.png" {
+		t.Errorf("GetEmbeds failed: expected name: %s, got: %s", "pixel.png", embeds[0].Name)
+	}
+	if contentType := embeds[0].GetContentType(); contentType != "image/png" {
+		t.Errorf("GetEmbeds failed: expected content type: %s, got: %s", "image/png", contentType)
+	}
+	content, err := embeds[0].GetContent()
+	if err != nil {
+		t.Fatalf("GetContent failed: %s", err)
+	}
+	if !bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Errorf("GetContent failed: expected decoded PNG content, got: %q", content)
+	}
+	size, err := embeds[0].GetSize()
+	if err != nil {
+		t.Fatalf("GetSize failed: %s", err)
+	}
+	if size != int64(len(content)) {
+		t.Errorf("GetSize failed: expected size: %d, got: %d", len(content), size)
+	}
+	if len(msg.GetAttachments()) != 0 {
+		t.Errorf("GetAttachments failed: expected no attachments, got: %d", len(msg.GetAttachments()))
+	}
+}
+
 func TestEMLToMsgFromStringMultipartMixedAlternativeRelated(t *testing.T) {
 	wantSubject := "Example mail // plain text base64 with attachment, embed and alternative part"
 	msg, err := EMLToMsgFromString(exampleMailMultipartMixedAlternativeRelated)
@@ -913,9 +1667,9 @@ func TestEMLToMsgFromStringMultipartMixedAlternativeRelated(t *testing.T) {
 		t.Errorf("EMLToMsgFromString of EML multipart mixed, related, alternative failed: expected no. of "+
 			"attachments: %d, but got: %d", 1, len(msg.attachments))
 	}
-	if len(msg.parts) != 3 {
+	if len(msg.parts) != 2 {
 		t.Errorf("EMLToMsgFromString of EML multipart mixed, related, alternative failed: expected no. of "+
-			"parts: %d, but got: %d", 3, len(msg.parts))
+			"parts: %d, but got: %d", 2, len(msg.parts))
 	}
 
 	var hasPlain, hasHTML, hasAlternative bool
@@ -938,9 +1692,9 @@ func TestEMLToMsgFromStringMultipartMixedAlternativeRelated(t *testing.T) {
 		t.Error("EMLToMsgFromString of EML multipart mixed, related, alternative failed: expected HTML " +
 			"but got none")
 	}
-	if !hasAlternative {
-		t.Error("EMLToMsgFromString of EML multipart mixed, related, alternative failed: expected Alternative " +
-			"but got none")
+	if hasAlternative {
+		t.Error("EMLToMsgFromString of EML multipart mixed, related, alternative failed: expected the " +
+			"alternative part to be parsed into its sub-parts, but got an empty alternative part")
 	}
 }
 
@@ -1009,3 +1763,125 @@ func stringToTempFile(data, name string) (string, string, error) {
 	}
 	return tempDir, filePath, nil
 }
+
+// TestEMLToMsgFromString_returnPath tests that the Return-Path of an EML is exposed and preserved by WriteEML
+func TestEMLToMsgFromString_returnPath(t *testing.T) {
+	msg, err := EMLToMsgFromString("Return-Path: <bounces+4711@go-mail.dev>\n" + exampleMailPlainNoEnc)
+	if err != nil {
+		t.Fatalf("failed to parse EML: %s", err)
+	}
+	if returnPath := msg.GetReturnPath(); returnPath != "bounces+4711@go-mail.dev" {
+		t.Errorf("EMLToMsgFromString failed. Expected Return-Path: bounces+4711@go-mail.dev, got: %q", returnPath)
+	}
+	buffer := bytes.Buffer{}
+	if err = msg.WriteEML(&buffer); err != nil {
+		t.Fatalf("failed to write EML: %s", err)
+	}
+	if !strings.HasPrefix(buffer.String(), "Return-Path: <bounces+4711@go-mail.dev>\r\n") {
+		t.Errorf("WriteEML failed. Expected Return-Path at the top of the EML, got: %s", buffer.String())
+	}
+	reparsed, err := EMLToMsgFromString(buffer.String())
+	if err != nil {
+		t.Fatalf("failed to re-parse written EML: %s", err)
+	}
+	if returnPath := reparsed.GetReturnPath(); returnPath != "bounces+4711@go-mail.dev" {
+		t.Errorf("WriteEML failed. Expected Return-Path to be preserved, got: %q", returnPath)
+	}
+}
+
+// TestMsg_WriteEML tests that a Msg imported from an EML can be written back and re-imported
+func TestMsg_WriteEML(t *testing.T) {
+	tests := []struct {
+		name        string
+		eml         string
+		parts       int
+		attachments int
+		embeds      int
+	}{
+		{"plain text base64", exampleMailPlainB64, 1, 0, 0},
+		{"plain text quoted-printable", exampleMailPlainQP, 1, 0, 0},
+		{"base64 with attachment", exampleMailPlainB64WithAttachment, 1, 1, 0},
+		{"multipart mixed, related, alternative", exampleMailMultipartMixedAlternativeRelated, 2, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := EMLToMsgFromString(tt.eml)
+			if err != nil {
+				t.Fatalf("failed to parse EML: %s", err)
+			}
+			msg.SetBoundary("go-mail-test-boundary")
+			buffer := bytes.Buffer{}
+			if err = msg.WriteEML(&buffer); err != nil {
+				t.Fatalf("failed to write EML: %s", err)
+			}
+			if count := strings.Count(buffer.String(), HeaderContentType.String()+": "); count !=
+				tt.parts+tt.attachments+tt.embeds+strings.Count(buffer.String(), "boundary=") {
+				t.Errorf("WriteEML failed. Unexpected number of Content-Type headers: %d, EML: %s",
+					count, buffer.String())
+			}
+
+			reparsed, err := EMLToMsgFromString(buffer.String())
+			if err != nil {
+				t.Fatalf("failed to re-parse written EML: %s", err)
+			}
+			if len(reparsed.parts) != tt.parts || len(reparsed.attachments) != tt.attachments ||
+				len(reparsed.embeds) != tt.embeds {
+				t.Fatalf("WriteEML failed. Expected %d parts, %d attachments and %d embeds, got: %d, %d, %d",
+					tt.parts, tt.attachments, tt.embeds, len(reparsed.parts), len(reparsed.attachments),
+					len(reparsed.embeds))
+			}
+			for i, part := range msg.parts {
+				// Line breaks are normalized to CRLF when the message is rendered
+				wantContent, _ := part.GetContent()
+				wantContent = bytes.ReplaceAll(wantContent, []byte("\r\n"), []byte("\n"))
+				gotContent, _ := reparsed.parts[i].GetContent()
+				gotContent = bytes.ReplaceAll(gotContent, []byte("\r\n"), []byte("\n"))
+				if !bytes.Equal(wantContent, gotContent) {
+					t.Errorf("WriteEML failed. Part %d content mismatch. Expected: %q, got: %q", i,
+						wantContent, gotContent)
+				}
+				if part.GetEncoding() != reparsed.parts[i].GetEncoding() {
+					t.Errorf("WriteEML failed. Part %d encoding mismatch. Expected: %s, got: %s", i,
+						part.GetEncoding(), reparsed.parts[i].GetEncoding())
+				}
+			}
+			for _, header := range []Header{HeaderSubject, HeaderMessageID, HeaderDate} {
+				want, got := msg.GetGenHeader(header), reparsed.GetGenHeader(header)
+				if len(want) != 1 || len(got) != 1 || want[0] != got[0] {
+					t.Errorf("WriteEML failed. Header %s mismatch. Expected: %v, got: %v", header, want, got)
+				}
+			}
+
+			reparsed.SetBoundary("go-mail-test-boundary")
+			rewritten := bytes.Buffer{}
+			if err = reparsed.WriteEML(&rewritten); err != nil {
+				t.Fatalf("failed to write re-parsed EML: %s", err)
+			}
+			if rewritten.String() != buffer.String() {
+				t.Errorf("WriteEML failed. Round-tripped EML differs.\nExpected: %s\nGot: %s",
+					buffer.String(), rewritten.String())
+			}
+		})
+	}
+	t.Run("WriteEMLFile", func(t *testing.T) {
+		msg, err := EMLToMsgFromString(exampleMailPlainB64WithAttachment)
+		if err != nil {
+			t.Fatalf("failed to parse EML: %s", err)
+		}
+		msg.Subject("Modified subject")
+		path := t.TempDir() + "/roundtrip.eml"
+		if err = msg.WriteEMLFile(path); err != nil {
+			t.Fatalf("failed to write EML file: %s", err)
+		}
+		reparsed, err := EMLToMsgFromFile(path)
+		if err != nil {
+			t.Fatalf("failed to parse written EML file: %s", err)
+		}
+		if subject := reparsed.GetGenHeader(HeaderSubject); len(subject) != 1 || subject[0] != "Modified subject" {
+			t.Errorf("WriteEMLFile failed. Expected modified subject, got: %v", subject)
+		}
+		if err = msg.WriteEMLFile(t.TempDir() + "/nonexisting/roundtrip.eml"); err == nil {
+			t.Error("WriteEMLFile with invalid path was supposed to fail")
+		}
+	})
+}
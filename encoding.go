@@ -4,6 +4,8 @@
 
 package mail
 
+import "strings"
+
 // Charset is a type wrapper for a string representing different character encodings.
 type Charset string
 
@@ -14,6 +16,10 @@ type ContentType string
 // and/or parts.
 type Encoding string
 
+// HeaderEncoding is a type wrapper for a string and represents the RFC 2047 encoded-word encoding used for
+// non-ASCII header values.
+type HeaderEncoding string
+
 // MIMEVersion is a type wrapper for a string nad represents the MIME version used in email messages.
 type MIMEVersion string
 
@@ -42,6 +48,27 @@ const (
 	//
 	// https://datatracker.ietf.org/doc/html/rfc6152
 	NoEncoding Encoding = "8bit"
+
+	// EncodingAuto represents the automatic selection of the encoding for each body part at render time.
+	//
+	// The content of each body part is inspected when the Msg is written, and the encoding that results in
+	// the smallest output is used: "7bit" for content that consists of short lines of US-ASCII characters,
+	// "quoted-printable" for mostly US-ASCII text and "base64" for binary-ish content. EncodingAuto is never
+	// written as Content-Transfer-Encoding itself.
+	EncodingAuto Encoding = "auto"
+)
+
+const (
+	// HeaderEncodingB represents the "B" encoding for RFC 2047 encoded-words, which is based on Base64.
+	//
+	// https://datatracker.ietf.org/doc/html/rfc2047#section-4.1
+	HeaderEncodingB HeaderEncoding = "B"
+
+	// HeaderEncodingQ represents the "Q" encoding for RFC 2047 encoded-words, which is similar to the
+	// quoted-printable encoding.
+	//
+	// https://datatracker.ietf.org/doc/html/rfc2047#section-4.2
+	HeaderEncodingQ HeaderEncoding = "Q"
 )
 
 const (
@@ -146,9 +173,26 @@ const (
 const MIME10 MIMEVersion = "1.0"
 
 const (
+	// TypeAppJSON represents the MIME type for JSON data.
+	TypeAppJSON ContentType = "application/json"
+
 	// TypeAppOctetStream represents the MIME type for arbitrary binary data.
 	TypeAppOctetStream ContentType = "application/octet-stream"
 
+	// TypeMessageDeliveryStatus represents the MIME type for the machine-readable part of a delivery
+	// status notification (DSN).
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc3464#section-2.1
+	TypeMessageDeliveryStatus ContentType = "message/delivery-status"
+
+	// TypeMessageRFC822 represents the MIME type for an encapsulated message, e.g. a message that is forwarded
+	// as attachment.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.2.1
+	TypeMessageRFC822 ContentType = "message/rfc822"
+
 	// TypeMultipartAlternative represents the MIME type for a message body that can contain multiple alternative
 	// formats.
 	TypeMultipartAlternative ContentType = "multipart/alternative"
@@ -160,12 +204,25 @@ const (
 	// or resource.
 	TypeMultipartRelated ContentType = "multipart/related"
 
+	// TypeMultipartReport represents the MIME type for a multipart message containing a report, i. e. a
+	// delivery status notification (DSN).
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc6522
+	TypeMultipartReport ContentType = "multipart/report"
+
 	// TypePGPSignature represents the MIME type for PGP signed messages.
 	TypePGPSignature ContentType = "application/pgp-signature"
 
 	// TypePGPEncrypted represents the MIME type for PGP encrypted messages.
 	TypePGPEncrypted ContentType = "application/pgp-encrypted"
 
+	// TypeTextCalendar represents the MIME type for iCalendar data, e.g. a calendar invite.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5545#section-8.1
+	TypeTextCalendar ContentType = "text/calendar"
+
 	// TypeTextHTML represents the MIME type for HTML text content.
 	TypeTextHTML ContentType = "text/html"
 
@@ -196,6 +253,32 @@ func (c Charset) String() string {
 	return string(c)
 }
 
+// IsKnown reports whether the Charset is one of the charsets that are defined by this package.
+//
+// The comparison is case-insensitive, as charset names are case-insensitive. CharsetUnknown is not
+// considered a known charset, since it cannot be used to encode a message.
+//
+// Returns:
+//   - true if the Charset is a known charset, false otherwise.
+//
+// References:
+//   - https://www.iana.org/assignments/character-sets/character-sets.xhtml
+func (c Charset) IsKnown() bool {
+	for _, known := range []Charset{
+		CharsetUTF7, CharsetUTF8, CharsetASCII, CharsetISO88591, CharsetISO88592, CharsetISO88593,
+		CharsetISO88594, CharsetISO88595, CharsetISO88596, CharsetISO88597, CharsetISO88599,
+		CharsetISO885913, CharsetISO885914, CharsetISO885915, CharsetISO885916, CharsetISO2022JP,
+		CharsetISO2022KR, CharsetWindows1250, CharsetWindows1251, CharsetWindows1252, CharsetWindows1255,
+		CharsetWindows1256, CharsetKOI8R, CharsetKOI8U, CharsetBig5, CharsetGB18030, CharsetGB2312,
+		CharsetTIS620, CharsetEUCKR, CharsetShiftJIS, CharsetGBK,
+	} {
+		if strings.EqualFold(string(c), string(known)) {
+			return true
+		}
+	}
+	return false
+}
+
 // String satisfies the fmt.Stringer interface for the ContentType type.
 // It converts a ContentType into a printable format.
 //
@@ -219,3 +302,31 @@ func (c ContentType) String() string {
 func (e Encoding) String() string {
 	return string(e)
 }
+
+// isValidEncoding reports whether the given Encoding is one of the supported encodings.
+//
+// Parameters:
+//   - encoding: The Encoding to check.
+//
+// Returns:
+//   - true if the Encoding is supported, false otherwise.
+func isValidEncoding(encoding Encoding) bool {
+	switch encoding {
+	case EncodingB64, EncodingQP, EncodingUSASCII, NoEncoding, EncodingAuto:
+		return true
+	default:
+		return false
+	}
+}
+
+// String satisfies the fmt.Stringer interface for the HeaderEncoding type.
+// It converts a HeaderEncoding into a printable format.
+//
+// This method returns the string representation of the HeaderEncoding, which can be used
+// for displaying or logging purposes.
+//
+// Returns:
+//   - A string representation of the HeaderEncoding.
+func (e HeaderEncoding) String() string {
+	return string(e)
+}
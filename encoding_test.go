@@ -121,3 +121,26 @@ func TestCharset_String(t *testing.T) {
 		})
 	}
 }
+
+func TestCharset_IsKnown(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Charset
+		want bool
+	}{
+		{"Charset: UTF-8", CharsetUTF8, true},
+		{"Charset: ISO-8859-1", CharsetISO88591, true},
+		{"Charset: lowercase iso-8859-1", "iso-8859-1", true},
+		{"Charset: Shift_JIS", CharsetShiftJIS, true},
+		{"Charset: Unknown", CharsetUnknown, false},
+		{"Charset: empty", "", false},
+		{"Charset: X-LEGACY", "X-LEGACY", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.c.IsKnown() != tt.want {
+				t.Errorf("IsKnown for Charset %q failed. Expected: %t, got: %t", tt.c, tt.want, tt.c.IsKnown())
+			}
+		})
+	}
+}
@@ -5,10 +5,25 @@
 package mail
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"net/textproto"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
 )
 
+// sniffLength is the maximum number of bytes that are considered by http.DetectContentType.
+const sniffLength = 512
+
+// errSniffComplete is used to stop the writer function of a File once enough bytes for the content
+// type detection have been read.
+var errSniffComplete = errors.New("content type sniffing complete")
+
 // FileOption is a function type used to modify properties of a File
 type FileOption func(*File)
 
@@ -19,18 +34,40 @@ type FileOption func(*File)
 // metadata such as content type and encoding, as well as a function to write the file's content to an
 // io.Writer.
 type File struct {
+	Charset     Charset
 	ContentType ContentType
 	Desc        string
 	Enc         Encoding
 	Header      textproto.MIMEHeader
 	Name        string
 	Writer      func(w io.Writer) (int64, error)
+
+	// detectContentType indicates that the content type is detected from the content of the File if it
+	// cannot be determined from the file extension.
+	detectContentType bool
+
+	// isProduced indicates that the content of the File is produced lazily by a function, which is called
+	// each time the content is written.
+	isProduced bool
+
+	// isCalendarInvite indicates that the File is the iCalendar fallback attachment that is added by
+	// Msg.SetCalendar.
+	isCalendarInvite bool
+}
+
+// sniffWriter is an io.Writer that collects the first bytes written to it for the content type
+// detection and aborts the writing once enough bytes have been collected.
+type sniffWriter struct {
+	buffer []byte
 }
 
 // WithFileContentID sets the "Content-ID" header in the File's MIME headers to the specified ID.
 //
 // This function updates the File's MIME headers by setting the "Content-ID" to the provided string value,
-// allowing the file to be referenced by this ID within the MIME structure.
+// allowing the file to be referenced by this ID within the MIME structure. The option can be used for
+// attachments as well as for embeds, e.g. for receiving systems that key off the Content-ID of an attachment.
+// The value is used as is. To conform to RFC 2392, it should have the form "<id-left@id-right>", which is
+// checked by Msg.Validate.
 //
 // Parameters:
 //   - id: A string representing the content ID to be set in the "Content-ID" header.
@@ -98,6 +135,24 @@ func WithFileEncoding(encoding Encoding) FileOption {
 	}
 }
 
+// WithFileCharset sets the charset of a text File.
+//
+// The charset is added as "charset" parameter to the Content-Type of the File, so that mail clients
+// render the preview of text attachments, like CSV or TXT files, correctly. It is ignored for Files
+// whose content type is not a "text/*" type. If no charset is set, it is inferred from the content of
+// text Files, see File.GetCharset.
+//
+// Parameters:
+//   - charset: The Charset of the content of the File.
+//
+// Returns:
+//   - A FileOption function that sets the File's charset.
+func WithFileCharset(charset Charset) FileOption {
+	return func(f *File) {
+		f.Charset = charset
+	}
+}
+
 // WithFileContentType sets the content type of the File.
 //
 // By default, the content type is guessed based on the file type, and if no matching type is identified,
@@ -115,6 +170,173 @@ func WithFileContentType(contentType ContentType) FileOption {
 	}
 }
 
+// WithFileContentTypeDetection enables the detection of the content type from the content of the File.
+//
+// By default, the content type is guessed based on the file extension only. With this FileOption, the
+// first 512 bytes of the file content are inspected with http.DetectContentType if the extension is
+// unknown or only maps to the generic "application/octet-stream" type, e.g. for a PDF file with a ".dat"
+// extension or no extension at all. A content type that is explicitly set via WithFileContentType or a
+// Content-Type header is never overridden.
+//
+// Returns:
+//   - A FileOption function that enables the content type detection for the File.
+//
+// References:
+//   - https://mimesniff.spec.whatwg.org/
+func WithFileContentTypeDetection() FileOption {
+	return func(f *File) {
+		f.detectContentType = true
+	}
+}
+
+// GetContent returns the content of the File.
+//
+// This method reads the whole content of the File into memory, e.g. to display an attachment of a parsed
+// EML in a preview or to attach it to another Msg. The content is returned as it is, i.e. without any
+// transfer encoding.
+//
+// Returns:
+//   - A byte slice holding the content of the File.
+//   - An error if the content of the File cannot be read.
+func (f *File) GetContent() ([]byte, error) {
+	if f.Writer == nil {
+		return nil, nil
+	}
+	var buffer bytes.Buffer
+	if _, err := f.Writer(&buffer); err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// GetContentType returns the content type of the File.
+//
+// The content type is the one set via WithFileContentType. Otherwise, it is derived from the file extension
+// of the File and, if that is not possible and content type detection is enabled, from its content. If the
+// content type cannot be determined, "application/octet-stream" is returned. This is the content type that
+// is used for the File when the Msg is written.
+//
+// Returns:
+//   - The ContentType of the File.
+func (f *File) GetContentType() ContentType {
+	if f.ContentType != "" {
+		return f.ContentType
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(f.Name))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	if f.detectContentType && mimeType == "application/octet-stream" {
+		mimeType = f.sniffContentType()
+	}
+	return ContentType(mimeType)
+}
+
+// GetCharset returns the charset of the File.
+//
+// The charset is the one set via WithFileCharset. Otherwise, it is inferred from the first 512 bytes of
+// the content of the File: text that holds non-ASCII characters and is valid UTF-8 is reported as UTF-8,
+// while no charset is reported for US-ASCII text, which is the default charset of text types. No charset
+// is returned for Files whose content type is not a "text/*" type or already holds a charset parameter.
+// The charset of Files whose content is produced lazily, see Msg.AttachFunc, is not inferred.
+// This is the charset that is used for the File when the Msg is written.
+//
+// Returns:
+//   - The Charset of the File, or an empty Charset if no charset applies.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-4.1.2
+func (f *File) GetCharset() Charset {
+	mediaType, params, err := mime.ParseMediaType(f.GetContentType().String())
+	if err != nil || !strings.HasPrefix(mediaType, "text/") || params["charset"] != "" {
+		return ""
+	}
+	if f.Charset != "" || f.Writer == nil || f.isProduced {
+		return f.Charset
+	}
+	sniffer := &sniffWriter{buffer: make([]byte, 0, sniffLength)}
+	if _, err = f.Writer(sniffer); err != nil && !errors.Is(err, errSniffComplete) {
+		return ""
+	}
+	if isASCIIString(string(sniffer.buffer)) || !isUTF8Prefix(sniffer.buffer) {
+		return ""
+	}
+	return CharsetUTF8
+}
+
+// GetSize returns the size of the content of the File in bytes.
+//
+// The content of the File is read to determine its size, without holding it in memory. The size does not
+// include the overhead of the transfer encoding.
+//
+// Returns:
+//   - The size of the content of the File in bytes.
+//   - An error if the content of the File cannot be read.
+func (f *File) GetSize() (int64, error) {
+	if f.Writer == nil {
+		return 0, nil
+	}
+	size, err := f.Writer(io.Discard)
+	if err != nil {
+		return size, fmt.Errorf("failed to read file content: %w", err)
+	}
+	return size, nil
+}
+
+// sniffContentType detects the content type of the File from the first bytes of its content.
+//
+// Returns:
+//   - The detected content type, or "application/octet-stream" if the content cannot be read or
+//     the content type cannot be determined.
+func (f *File) sniffContentType() string {
+	if f.Writer == nil {
+		return "application/octet-stream"
+	}
+	sniffer := &sniffWriter{buffer: make([]byte, 0, sniffLength)}
+	if _, err := f.Writer(sniffer); err != nil && !errors.Is(err, errSniffComplete) {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(sniffer.buffer)
+}
+
+// isUTF8Prefix reports whether the given data is valid UTF-8, apart from a multibyte character that
+// is cut off at its end.
+//
+// Parameters:
+//   - data: The first bytes of a content.
+//
+// Returns:
+//   - true if the data is a valid prefix of UTF-8 encoded content, false otherwise.
+func isUTF8Prefix(data []byte) bool {
+	for start := len(data) - 1; start >= 0 && start >= len(data)-utf8.UTFMax; start-- {
+		if utf8.RuneStart(data[start]) {
+			if !utf8.FullRune(data[start:]) {
+				data = data[:start]
+			}
+			break
+		}
+	}
+	return utf8.Valid(data)
+}
+
+// Write collects the written bytes until sniffLength bytes are collected and returns errSniffComplete
+// afterwards to stop the writer.
+//
+// Parameters:
+//   - data: The bytes to be written.
+//
+// Returns:
+//   - The number of bytes accepted and errSniffComplete once enough bytes have been collected.
+func (s *sniffWriter) Write(data []byte) (int, error) {
+	remaining := sniffLength - len(s.buffer)
+	if len(data) < remaining {
+		s.buffer = append(s.buffer, data...)
+		return len(data), nil
+	}
+	s.buffer = append(s.buffer, data[:remaining]...)
+	return remaining, errSniffComplete
+}
+
 // setHeader sets the value of a specified MIME header field for the File.
 //
 // This method updates the MIME headers of the File by assigning the provided value to the specified
@@ -4,7 +4,12 @@
 
 package mail
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
 
 // TestFile_SetGetHeader tests the set-/getHeader method of the File object
 func TestFile_SetGetHeader(t *testing.T) {
@@ -137,3 +142,144 @@ func TestFile_WithFileContentType(t *testing.T) {
 		})
 	}
 }
+
+// TestFile_WithFileContentTypeDetection tests the WithFileContentTypeDetection FileOption
+func TestFile_WithFileContentTypeDetection(t *testing.T) {
+	pdf := "%PDF-1.7\n" + strings.Repeat("0", 1024)
+	tests := []struct {
+		name string
+		file string
+		data string
+		opts []FileOption
+		want string
+	}{
+		{"unknown extension", "file.dat", pdf, []FileOption{WithFileContentTypeDetection()}, "application/pdf"},
+		{"no extension", "file", "<html><body>test</body></html>", []FileOption{WithFileContentTypeDetection()},
+			"text/html; charset=utf-8"},
+		{"known extension", "file.txt", pdf, []FileOption{WithFileContentTypeDetection()}, "text/plain; charset=utf-8"},
+		{"detection disabled", "file.dat", pdf, nil, "application/octet-stream"},
+		{
+			"explicit content type", "file.dat", pdf,
+			[]FileOption{WithFileContentTypeDetection(), WithFileContentType(TypeTextPlain)}, "text/plain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := m.AttachReader(tt.file, strings.NewReader(tt.data), tt.opts...); err != nil {
+				t.Fatalf("failed to attach reader: %s", err)
+			}
+			buf := bytes.Buffer{}
+			if _, err := m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			want := fmt.Sprintf(`Content-Type: %s; name="%s"`, tt.want, tt.file)
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("WithFileContentTypeDetection() failed. Expected %q in output, got: %s", want, buf.String())
+			}
+		})
+	}
+}
+
+// TestFile_WithFileCharset tests the WithFileCharset FileOption and the charset inference of text files
+func TestFile_WithFileCharset(t *testing.T) {
+	csv := WithFileContentType("text/csv")
+	tests := []struct {
+		name string
+		file string
+		data string
+		opts []FileOption
+		want string
+	}{
+		{"explicit charset", "export.csv", "a;b", []FileOption{csv, WithFileCharset(CharsetISO88591)},
+			"text/csv; charset=ISO-8859-1"},
+		{"inferred UTF-8", "export.csv", "name;city\nToni;Köln", []FileOption{csv}, "text/csv; charset=UTF-8"},
+		{"inferred UTF-8 cut off", "export.csv", strings.Repeat("a", 511) + "ä", []FileOption{csv},
+			"text/csv; charset=UTF-8"},
+		{"US-ASCII", "export.csv", "a;b", []FileOption{csv}, "text/csv"},
+		{"invalid UTF-8", "export.csv", "K\xf6ln", []FileOption{csv}, "text/csv"},
+		{"non-text file", "logo.png", "Köln", []FileOption{WithFileCharset(CharsetUTF8)}, "image/png"},
+		{
+			"charset of detected content type", "notes", "Köln",
+			[]FileOption{WithFileContentTypeDetection(), WithFileCharset(CharsetISO88591)},
+			"text/plain; charset=utf-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			m.SetBodyString(TypeTextPlain, "Please find the file attached")
+			if err := m.AttachReader(tt.file, strings.NewReader(tt.data), tt.opts...); err != nil {
+				t.Fatalf("failed to attach reader: %s", err)
+			}
+			buf := bytes.Buffer{}
+			if _, err := m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			want := fmt.Sprintf(`Content-Type: %s; name="%s"`, tt.want, tt.file)
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("WithFileCharset() failed. Expected %q in output, got: %s", want, buf.String())
+			}
+
+			parsed, err := EMLToMsgFromReader(&buf)
+			if err != nil {
+				t.Fatalf("failed to parse message: %s", err)
+			}
+			attachments := parsed.GetAttachments()
+			if len(attachments) != 1 {
+				t.Fatalf("expected 1 attachment, got: %d", len(attachments))
+			}
+			got := fmt.Sprintf(`%s; name="%s"`, attachments[0].GetContentType(), tt.file)
+			if charset := attachments[0].GetCharset(); charset != "" {
+				got = fmt.Sprintf(`%s; charset=%s; name="%s"`, attachments[0].GetContentType(), charset, tt.file)
+			}
+			if !strings.EqualFold(got, strings.TrimPrefix(want, "Content-Type: ")) {
+				t.Errorf("charset of parsed attachment failed. Expected: %s, got: %s", want, got)
+			}
+		})
+	}
+}
+
+// TestFile_GetContent tests the GetContent, GetContentType and GetSize methods of the File object
+func TestFile_GetContent(t *testing.T) {
+	content := "%PDF-1.7\n" + strings.Repeat("0", 1024)
+	m := NewMsg()
+	if err := m.AttachReader("invoice.dat", strings.NewReader(content), WithFileContentTypeDetection()); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	if err := m.AttachReader("logo.png", strings.NewReader("logo")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	attachments := m.GetAttachments()
+	if len(attachments) != 2 {
+		t.Fatalf("GetAttachments() failed. Expected 2 attachments, got: %d", len(attachments))
+	}
+	data, err := attachments[0].GetContent()
+	if err != nil {
+		t.Fatalf("GetContent() failed: %s", err)
+	}
+	if string(data) != content {
+		t.Errorf("GetContent() failed. Expected content: %q, got: %q", content, data)
+	}
+	size, err := attachments[0].GetSize()
+	if err != nil {
+		t.Fatalf("GetSize() failed: %s", err)
+	}
+	if size != int64(len(content)) {
+		t.Errorf("GetSize() failed. Expected size: %d, got: %d", len(content), size)
+	}
+	if contentType := attachments[0].GetContentType(); contentType != "application/pdf" {
+		t.Errorf("GetContentType() failed. Expected: %s, got: %s", "application/pdf", contentType)
+	}
+	if contentType := attachments[1].GetContentType(); contentType != "image/png" {
+		t.Errorf("GetContentType() failed. Expected: %s, got: %s", "image/png", contentType)
+	}
+
+	empty := &File{Name: "empty"}
+	if data, err = empty.GetContent(); err != nil || data != nil {
+		t.Errorf("GetContent() of File without writer failed. Expected no content, got: %q, %v", data, err)
+	}
+	if size, err = empty.GetSize(); err != nil || size != 0 {
+		t.Errorf("GetSize() of File without writer failed. Expected 0, got: %d, %v", size, err)
+	}
+}
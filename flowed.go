@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"strings"
+)
+
+// DefaultFlowedColumn defines the default column at which the lines of a format=flowed body are wrapped.
+//
+// This constant follows the recommendation of RFC 3676, which suggests that the lines of a format=flowed
+// body should not be longer than 66 characters, so that they display well in clients that do not support
+// format=flowed and can still be quoted a couple of times.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4.2
+const DefaultFlowedColumn = 66
+
+// SetBodyFlowed sets the given plain text as format=flowed body of the Msg.
+//
+// The text is set as body with the content type "text/plain; format=flowed; delsp=yes". Each line of the
+// text is treated as a logical paragraph, which is wrapped with soft line breaks at DefaultFlowedColumn
+// when the Msg is written, so that clients that support format=flowed, like Thunderbird, can reflow the
+// paragraphs to the width of their window. Lines that start with ">" are treated as quoted and keep their
+// quote depth on each wrapped line, lines are space-stuffed where required and the signature separator
+// "-- " is kept untouched. The column can be overridden via the WithPartWrapAt option.
+//
+// Parameters:
+//   - text: The plain text of the body, with one logical paragraph per line.
+//   - opts: Optional parameters for customizing the body part.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676
+func (m *Msg) SetBodyFlowed(text string, opts ...PartOption) {
+	flowedOpts := []PartOption{
+		WithPartContentTypeParam("format", "flowed"), WithPartContentTypeParam("delsp", "yes"),
+		WithPartWrapAt(DefaultFlowedColumn),
+	}
+	m.SetBodyString(TypeTextPlain, text, append(flowedOpts, opts...)...)
+}
+
+// GetBodyUnflowed returns the text/plain body of the Msg with its format=flowed lines joined to logical
+// paragraphs.
+//
+// If the "format" parameter of the Content-Type of the body part is "flowed", e.g. for a Msg that has been
+// imported via EMLToMsgFromReader or composed via SetBodyFlowed, the lines that end with a soft line
+// break are joined with the following lines of the same quote depth, and the space-stuffing is removed.
+// If the "delsp" parameter is "yes", the space of each soft line break is deleted. Quoted paragraphs
+// start with their quote marks, followed by a space. Other bodies, as well as bodies that are only wrapped
+// when the Msg is written, e.g. via SetBodyFlowed, are returned unchanged, since their content already
+// holds the logical paragraphs.
+//
+// Returns:
+//   - The UTF-8 encoded text of the body, with one logical paragraph per line.
+//   - An error if the Msg has no text/plain body part (ErrNoBodyPart) or the body cannot be decoded.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4.2
+func (m *Msg) GetBodyUnflowed() (string, error) {
+	part, _, err := m.bodyPart(TypeTextPlain)
+	if err != nil {
+		return "", err
+	}
+	content, err := m.GetBodyDecoded(TypeTextPlain)
+	if err != nil {
+		return "", err
+	}
+	if part.wrapAt > 0 || !strings.EqualFold(part.GetContentTypeParam("format"), "flowed") {
+		return string(content), nil
+	}
+	return unflowText(string(content), strings.EqualFold(part.GetContentTypeParam("delsp"), "yes")), nil
+}
+
+// unflowText joins the flowed lines of a format=flowed text to logical paragraphs.
+//
+// Parameters:
+//   - text: The format=flowed text.
+//   - delSp: If true, the space of each soft line break is deleted.
+//
+// Returns:
+//   - The text with one logical paragraph per line. The line ending of the text is kept.
+func unflowText(text string, delSp bool) string {
+	lineBreak := "\n"
+	if strings.Contains(text, "\r\n") {
+		lineBreak = "\r\n"
+	}
+
+	var paragraphs []string
+	paragraph := ""
+	depth := 0
+	open := false
+	closeParagraph := func() {
+		if depth > 0 && paragraph != "" {
+			paragraph = " " + paragraph
+		}
+		paragraphs = append(paragraphs, strings.Repeat(">", depth)+paragraph)
+		paragraph, open = "", false
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		quotes := len(line) - len(strings.TrimLeft(line, ">"))
+		line = strings.TrimPrefix(line[quotes:], " ")
+
+		// A line of a different quote depth and the signature separator end the paragraph, even if
+		// the previous line is flowed
+		if open && (quotes != depth || line == flowedSignatureSeparator) {
+			closeParagraph()
+		}
+		depth = quotes
+		flowed := strings.HasSuffix(line, " ") && line != flowedSignatureSeparator
+		if flowed && delSp {
+			line = line[:len(line)-1]
+		}
+		paragraph += line
+		open = true
+		if !flowed {
+			closeParagraph()
+		}
+	}
+	if open {
+		closeParagraph()
+	}
+	return strings.Join(paragraphs, lineBreak)
+}
@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testFlowedText holds a plain text with logical paragraphs that exceed the DefaultFlowedColumn
+const testFlowedText = "Hi Toni,\r\n\r\nthis is a long paragraph that exceeds the default column of a format=flowed " +
+	"body, so that it has to be wrapped with soft line breaks. From now on it should reflow.\r\n" +
+	"> This is a quoted paragraph that is long enough to be wrapped with soft line breaks as well.\r\n" +
+	">> And this one is quoted twice.\r\n" +
+	" An indented line\r\n" +
+	"\r\n" +
+	"-- \r\nToni Tester"
+
+// TestMsg_SetBodyFlowed tests that Msg.SetBodyFlowed writes a format=flowed body that is unflowed to the
+// original text again by Msg.GetBodyUnflowed
+func TestMsg_SetBodyFlowed(t *testing.T) {
+	for _, alternative := range []bool{false, true} {
+		m := NewMsg()
+		m.SetBodyFlowed(testFlowedText)
+		if alternative {
+			m.AddAlternativeString(TypeTextHTML, "<p>Hi Toni</p>")
+		}
+		buf := bytes.Buffer{}
+		if _, err := m.WriteTo(&buf); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+		if !strings.Contains(buf.String(), "Content-Type: text/plain; charset=UTF-8; delsp=yes; format=flowed") {
+			t.Errorf("SetBodyFlowed failed. Expected format=flowed content type, got: %s", buf.String())
+		}
+
+		if got, err := m.GetBodyUnflowed(); err != nil || got != testFlowedText {
+			t.Errorf("GetBodyUnflowed failed. Want: %q, got: %q, %v", testFlowedText, got, err)
+		}
+		parsed, err := EMLToMsgFromReader(&buf)
+		if err != nil {
+			t.Fatalf("failed to parse written message: %s", err)
+		}
+		content, err := parsed.GetBodyDecoded(TypeTextPlain)
+		if err != nil {
+			t.Fatalf("GetBodyDecoded failed: %s", err)
+		}
+		for _, line := range strings.Split(string(content), "\r\n") {
+			if len(line) > DefaultFlowedColumn {
+				t.Errorf("SetBodyFlowed failed. Line exceeds column %d: %q", DefaultFlowedColumn, line)
+			}
+		}
+		if !strings.Contains(string(content), "\r\n  An indented line\r\n") {
+			t.Errorf("SetBodyFlowed failed. Expected space-stuffed indented line, got: %q", content)
+		}
+		if got, err := parsed.GetBodyUnflowed(); err != nil || got != testFlowedText {
+			t.Errorf("GetBodyUnflowed of parsed message failed. Want: %q, got: %q, %v", testFlowedText, got, err)
+		}
+	}
+}
+
+// TestMsg_GetBodyUnflowed tests Msg.GetBodyUnflowed with bodies that are not written by go-mail
+func TestMsg_GetBodyUnflowed(t *testing.T) {
+	m := NewMsg()
+	if _, err := m.GetBodyUnflowed(); !errors.Is(err, ErrNoBodyPart) {
+		t.Errorf("GetBodyUnflowed without body expected error: %s, got: %v", ErrNoBodyPart, err)
+	}
+	m.SetBodyString(TypeTextPlain, "Not flowed \r\ntext")
+	if got, err := m.GetBodyUnflowed(); err != nil || got != "Not flowed \r\ntext" {
+		t.Errorf("GetBodyUnflowed of fixed body expected unchanged text, got: %q, %v", got, err)
+	}
+
+	tests := []struct {
+		name  string
+		delSp bool
+		text  string
+		want  string
+	}{
+		{"delsp=no", false, "Soft \nline \nbreaks\nHard\n", "Soft line breaks\nHard\n"},
+		{"delsp=yes", true, "Long word spl it \r\nacross lines  \r\nend", "Long word spl itacross lines end"},
+		{"quote depth change", false, "> Quoted \r\nNot quoted", "> Quoted \r\nNot quoted"},
+		{"signature after flowed line", false, "Regards, \r\n-- \r\nToni", "Regards, \r\n-- \r\nToni"},
+		{"space-stuffing", false, "  Indented\r\n >From", " Indented\r\n>From"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unflowText(tt.text, tt.delSp); got != tt.want {
+				t.Errorf("unflowText failed. Want: %q, got: %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestEMLToMsgFromString_flowed tests that the format=flowed parameters of an imported body part are kept
+func TestEMLToMsgFromString_flowed(t *testing.T) {
+	parsed, err := EMLToMsgFromString(exampleMultiPart7BitBase64)
+	if err != nil {
+		t.Fatalf("failed to parse EML: %s", err)
+	}
+	part, charset, err := parsed.bodyPart(TypeTextPlain)
+	if err != nil {
+		t.Fatalf("failed to get body part: %s", err)
+	}
+	if charset != CharsetASCII || part.GetContentTypeParam("format") != "flowed" {
+		t.Errorf("expected charset US-ASCII and format=flowed, got: %q, %q", charset,
+			part.GetContentTypeParam("format"))
+	}
+	if got, err := parsed.GetBodyUnflowed(); err != nil || !strings.HasPrefix(got, "testtest\ntesttest") {
+		t.Errorf("GetBodyUnflowed failed, got: %q, %v", got, err)
+	}
+}
@@ -13,7 +13,20 @@ type AddrHeader string
 // Importance is a type wrapper for an int and represents the level of importance or priority for a Msg.
 type Importance int
 
+// AutoSubmitted is a type wrapper for a string and represents the value of the "Auto-Submitted" header,
+// which indicates whether and why a Msg has been generated automatically.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3834#section-5
+type AutoSubmitted string
+
 const (
+	// HeaderAutoSubmitted is the "Auto-Submitted" header field.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc3834#section-5
+	HeaderAutoSubmitted Header = "Auto-Submitted"
+
 	// HeaderContentDescription is the "Content-Description" header.
 	HeaderContentDescription Header = "Content-Description"
 
@@ -40,6 +53,10 @@ const (
 	// https://datatracker.ietf.org/doc/html/rfc822#section-5.1
 	HeaderDate Header = "Date"
 
+	// HeaderDKIMSignature is the "DKIM-Signature" header as described in RFC 6376.
+	// https://datatracker.ietf.org/doc/html/rfc6376#section-3.5
+	HeaderDKIMSignature Header = "DKIM-Signature"
+
 	// HeaderDispositionNotificationTo is the MDN header as described in RFC 8098.
 	// https://datatracker.ietf.org/doc/html/rfc8098#section-2.1
 	HeaderDispositionNotificationTo Header = "Disposition-Notification-To"
@@ -73,15 +90,41 @@ const (
 	// HeaderPriority represents the "Priority" field.
 	HeaderPriority Header = "Priority"
 
+	// HeaderReceived is the "Received" trace header field, which each SMTP server that relays a message
+	// adds on top of the header section.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.4
+	HeaderReceived Header = "Received"
+
 	// HeaderReferences is the "References" header field.
 	HeaderReferences Header = "References"
 
 	// HeaderReplyTo is the "Reply-To" header field.
 	HeaderReplyTo Header = "Reply-To"
 
+	// HeaderReturnPath is the "Return-Path" header field.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.4
+	HeaderReturnPath Header = "Return-Path"
+
+	// HeaderReturnReceiptTo is the non-standard "Return-Receipt-To" header field, which requests a delivery
+	// receipt from the MTAs that honor it.
+	HeaderReturnReceiptTo Header = "Return-Receipt-To"
+
 	// HeaderSubject is the "Subject" header field.
 	HeaderSubject Header = "Subject"
 
+	// HeaderTLSRequired is the "TLS-Required" header field.
+	//
+	// With the value "No", it requests that the message is delivered even if TLS is not available or the
+	// certificate of a server cannot be verified.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc8689#section-5
+	HeaderTLSRequired Header = "TLS-Required"
+
 	// HeaderUserAgent is the "User-Agent" header field.
 	HeaderUserAgent Header = "User-Agent"
 
@@ -115,10 +158,37 @@ const (
 	// HeaderFrom is the "From" header field.
 	HeaderFrom AddrHeader = "From"
 
+	// HeaderSender is the "Sender" header field.
+	//
+	// It specifies the mailbox of the agent responsible for the actual transmission of the message, if it
+	// differs from the author given in the "From" header field. If set, it is used as the envelope from
+	// address, unless the envelope from address has been set explicitly.
+	HeaderSender AddrHeader = "Sender"
+
 	// HeaderTo is the "Receipient" header field.
 	HeaderTo AddrHeader = "To"
 )
 
+const (
+	// AutoSubmittedNo indicates that the Msg has been written by a human.
+	AutoSubmittedNo AutoSubmitted = "no"
+
+	// AutoSubmittedAutoGenerated indicates that the Msg has been generated by an automatic process, e.g. a
+	// newsletter or a notification, and is not a response to another message.
+	AutoSubmittedAutoGenerated AutoSubmitted = "auto-generated"
+
+	// AutoSubmittedAutoReplied indicates that the Msg is an automatic response to another message, e.g. an
+	// out-of-office reply.
+	AutoSubmittedAutoReplied AutoSubmitted = "auto-replied"
+
+	// AutoSubmittedAutoNotified indicates that the Msg is an automatic notification, e.g. of a Sieve
+	// "notify" action.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5436#section-2.7.1
+	AutoSubmittedAutoNotified AutoSubmitted = "auto-notified"
+)
+
 const (
 	// ImportanceLow indicates a low level of importance or priority in a Msg.
 	ImportanceLow Importance = iota
@@ -214,6 +284,15 @@ func (h Header) String() string {
 	return string(h)
 }
 
+// String satisfies the fmt.Stringer interface for the AutoSubmitted type and returns the value of the
+// "Auto-Submitted" header.
+//
+// Returns:
+//   - A string representing the AutoSubmitted value.
+func (a AutoSubmitted) String() string {
+	return string(a)
+}
+
 // String satisfies the fmt.Stringer interface for the AddrHeader type and returns the string
 // representation of the AddrHeader.
 //
@@ -73,6 +73,7 @@ func TestHeader_String(t *testing.T) {
 	}{
 		{"Header: Content-Disposition", HeaderContentDisposition, "Content-Disposition"},
 		{"Header: Content-ID", HeaderContentID, "Content-ID"},
+		{"Header: Auto-Submitted", HeaderAutoSubmitted, "Auto-Submitted"},
 		{"Header: Content-Language", HeaderContentLang, "Content-Language"},
 		{"Header: Content-Location", HeaderContentLocation, "Content-Location"},
 		{"Header: Content-Transfer-Encoding", HeaderContentTransferEnc, "Content-Transfer-Encoding"},
@@ -87,9 +88,13 @@ func TestHeader_String(t *testing.T) {
 		{"Header: Organization", HeaderOrganization, "Organization"},
 		{"Header: Precedence", HeaderPrecedence, "Precedence"},
 		{"Header: Priority", HeaderPriority, "Priority"},
+		{"Header: Received", HeaderReceived, "Received"},
 		{"Header: HeaderReferences", HeaderReferences, "References"},
 		{"Header: Reply-To", HeaderReplyTo, "Reply-To"},
+		{"Header: Return-Path", HeaderReturnPath, "Return-Path"},
+		{"Header: Return-Receipt-To", HeaderReturnReceiptTo, "Return-Receipt-To"},
 		{"Header: Subject", HeaderSubject, "Subject"},
+		{"Header: TLS-Required", HeaderTLSRequired, "TLS-Required"},
 		{"Header: User-Agent", HeaderUserAgent, "User-Agent"},
 		{"Header: X-Mailer", HeaderXMailer, "X-Mailer"},
 		{"Header: X-MSMail-Priority", HeaderXMSMailPriority, "X-MSMail-Priority"},
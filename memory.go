@@ -0,0 +1,431 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// memoryHost is the host name that is used by the MemoryClient and announced by its in-memory server.
+// Using "localhost" allows the PLAIN and LOGIN SMTP authentication without TLS.
+const memoryHost = "localhost"
+
+type (
+	// MemoryClient is a Client that delivers messages to an in-memory SMTP server instead of a
+	// remote server over the network.
+	//
+	// The MemoryClient embeds a regular Client, so that all its methods, like DialAndSend, Send or
+	// SendWithResults, can be used unchanged. Each connection is served by an in-memory SMTP server
+	// that speaks the SMTP protocol on a synchronous in-memory network connection, so that the same
+	// code paths for the SMTP dialog, the SMTP authentication and STARTTLS are exercised as with a
+	// remote server. Each message accepted by the server is recorded and can be retrieved via the
+	// Messages method. This makes the MemoryClient suitable for deterministic tests of code that
+	// sends mails.
+	MemoryClient struct {
+		*Client
+
+		// mutex is used to synchronize the access to the recorded messages and the scripted responses.
+		mutex sync.Mutex
+
+		// messages holds all messages that have been accepted by the in-memory server.
+		messages []MemoryMessage
+
+		// rcptResponses holds the scripted responses to the RCPT TO command, keyed by the lowercase
+		// recipient address.
+		rcptResponses map[string]MemoryResponse
+
+		// tlsConfig is the server side TLS configuration for the STARTTLS command. If nil, STARTTLS is
+		// not offered by the in-memory server.
+		tlsConfig *tls.Config
+	}
+
+	// MemoryMessage represents a message that has been accepted by the in-memory server of a
+	// MemoryClient.
+	MemoryMessage struct {
+		// Data is the rendered message as it has been transmitted in the DATA command, with the
+		// dot-stuffing removed, or in the chunks of the BDAT command.
+		Data []byte
+
+		// EnvelopeFrom is the envelope sender address of the MAIL FROM command.
+		EnvelopeFrom string
+
+		// MailParams holds the parameters of the MAIL FROM command, e.g. "BODY=8BITMIME" or "REQUIRETLS".
+		MailParams []string
+
+		// Recipients holds the envelope recipient addresses that have been accepted by the server.
+		Recipients []string
+	}
+
+	// MemoryResponse represents a scripted SMTP reply of the in-memory server of a MemoryClient.
+	MemoryResponse struct {
+		// Code is the three-digit SMTP reply code, e.g. 250, 450 or 550.
+		Code int
+
+		// Message is the text of the SMTP reply, e.g. "5.1.1 No such user".
+		Message string
+	}
+)
+
+// NewMemoryClient creates a new MemoryClient with the provided options.
+//
+// The returned MemoryClient connects to its in-memory SMTP server, which accepts all senders and
+// recipients and all SMTP authentication attempts by default. Responses for single recipients can be
+// scripted via SetRcptResponse. The options are applied to the embedded Client after the defaults of
+// the MemoryClient, which disable TLS and set the DialContextFunc for the in-memory server. To test
+// STARTTLS, a server side TLS configuration has to be set via SetServerTLSConfig and the TLSPolicy and TLS
+// configuration of the Client have to be set via the respective options.
+//
+// Parameters:
+//   - opts: Optional parameters for customizing the embedded Client.
+//
+// Returns:
+//   - A pointer to the newly created MemoryClient.
+//   - An error if any of the provided options fails to apply.
+func NewMemoryClient(opts ...Option) (*MemoryClient, error) {
+	memClient := &MemoryClient{rcptResponses: make(map[string]MemoryResponse)}
+	clientOpts := []Option{WithTLSPortPolicy(NoTLS), WithDialContextFunc(memClient.dialContext)}
+	client, err := NewClient(memoryHost, append(clientOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	memClient.Client = client
+	return memClient, nil
+}
+
+// Messages returns a copy of all messages that have been accepted by the in-memory server.
+//
+// Returns:
+//   - A slice of MemoryMessage in the order in which the messages have been accepted.
+func (m *MemoryClient) Messages() []MemoryMessage {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	messages := make([]MemoryMessage, len(m.messages))
+	for i, message := range m.messages {
+		messages[i] = MemoryMessage{
+			Data:         append([]byte(nil), message.Data...),
+			EnvelopeFrom: message.EnvelopeFrom,
+			MailParams:   append([]string(nil), message.MailParams...),
+			Recipients:   append([]string(nil), message.Recipients...),
+		}
+	}
+	return messages
+}
+
+// ResetMessages removes all recorded messages of the MemoryClient.
+func (m *MemoryClient) ResetMessages() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = nil
+}
+
+// SetRcptResponse scripts the reply of the in-memory server to the RCPT TO command for the given
+// recipient address.
+//
+// A reply code in the 2xx range accepts the recipient, a reply code in the 4xx or 5xx range rejects
+// it with a temporary or permanent error. Recipients without a scripted reply are accepted.
+//
+// Parameters:
+//   - rcpt: The recipient address the reply is used for. The address is compared case-insensitively.
+//   - code: The three-digit SMTP reply code.
+//   - message: The text of the SMTP reply.
+func (m *MemoryClient) SetRcptResponse(rcpt string, code int, message string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rcptResponses[strings.ToLower(rcpt)] = MemoryResponse{Code: code, Message: message}
+}
+
+// SetServerTLSConfig sets the server side TLS configuration of the in-memory server.
+//
+// If a TLS configuration with a certificate is set, the in-memory server offers the STARTTLS
+// extension, so that the STARTTLS code path of the Client can be tested. Once the connection is
+// encrypted, the in-memory server offers the REQUIRETLS extension as well. Session tickets are
+// disabled for the in-memory server.
+//
+// Parameters:
+//   - config: A pointer to the server side tls.Config, or nil to disable STARTTLS.
+func (m *MemoryClient) SetServerTLSConfig(config *tls.Config) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if config == nil {
+		m.tlsConfig = nil
+		return
+	}
+	m.tlsConfig = config.Clone()
+	m.tlsConfig.SessionTicketsDisabled = true
+}
+
+// dialContext is the DialContextFunc of the MemoryClient. It returns one end of a synchronous
+// in-memory network connection and serves the other end with the in-memory SMTP server.
+//
+// Parameters:
+//   - ctx: The context.Context for the connection. It is not used by the in-memory connection.
+//   - network: The network type. It is not used by the in-memory connection.
+//   - address: The address to connect to. It is not used by the in-memory connection.
+//
+// Returns:
+//   - The client end of the in-memory network connection. The error is always nil.
+func (m *MemoryClient) dialContext(_ context.Context, _, _ string) (net.Conn, error) {
+	clientConn, serverConn := net.Pipe()
+	go m.serve(serverConn)
+	return clientConn, nil
+}
+
+// serve handles the SMTP dialog of a single connection of the in-memory server.
+//
+// The server does not offer the PIPELINING extension, since replies are written synchronously to the
+// in-memory connection, which requires the client to read each reply before sending the next command.
+//
+// Parameters:
+//   - connection: The server end of the in-memory network connection.
+func (m *MemoryClient) serve(connection net.Conn) {
+	defer func() { _ = connection.Close() }()
+	reader := bufio.NewReader(connection)
+	reply := func(code int, message string) bool {
+		_, err := fmt.Fprintf(connection, "%d %s\r\n", code, message)
+		return err == nil
+	}
+
+	var transaction *MemoryMessage
+	isTLS := false
+	if !reply(220, memoryHost+" ESMTP go-mail in-memory server") {
+		return
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			extensions := []string{
+				memoryHost, "8BITMIME", "CHUNKING", "DSN", "SMTPUTF8", "AUTH PLAIN LOGIN CRAM-MD5 XOAUTH2",
+			}
+			m.mutex.Lock()
+			if m.tlsConfig != nil && !isTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if isTLS {
+				extensions = append(extensions, "REQUIRETLS")
+			}
+			m.mutex.Unlock()
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				if _, err = fmt.Fprintf(connection, "250%s%s\r\n", separator, extension); err != nil {
+					return
+				}
+			}
+			transaction = nil
+		case strings.HasPrefix(command, "HELO"):
+			transaction = nil
+			reply(250, memoryHost)
+		case command == "STARTTLS":
+			m.mutex.Lock()
+			tlsConfig := m.tlsConfig
+			m.mutex.Unlock()
+			if tlsConfig == nil || isTLS {
+				reply(502, "5.5.1 STARTTLS not available")
+				continue
+			}
+			if !reply(220, "2.0.0 Ready to start TLS") {
+				return
+			}
+			tlsConn := tls.Server(connection, tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			connection = tlsConn
+			reader = bufio.NewReader(connection)
+			transaction = nil
+			isTLS = true
+		case strings.HasPrefix(command, "AUTH"):
+			if !m.serveAuth(connection, reader, strings.Fields(line)) {
+				return
+			}
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			transaction = &MemoryMessage{
+				EnvelopeFrom: memoryCommandAddress(line), MailParams: memoryCommandParams(line),
+			}
+			reply(250, "2.1.0 Sender OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if transaction == nil {
+				reply(503, "5.5.1 MAIL FROM required")
+				continue
+			}
+			rcpt := memoryCommandAddress(line)
+			m.mutex.Lock()
+			response, ok := m.rcptResponses[strings.ToLower(rcpt)]
+			m.mutex.Unlock()
+			if !ok {
+				response = MemoryResponse{Code: 250, Message: "2.1.5 Recipient OK"}
+			}
+			if response.Code >= 200 && response.Code < 300 {
+				transaction.Recipients = append(transaction.Recipients, rcpt)
+			}
+			reply(response.Code, response.Message)
+		case command == "DATA":
+			if transaction == nil || len(transaction.Recipients) == 0 {
+				reply(503, "5.5.1 No valid recipients")
+				continue
+			}
+			if !reply(354, "End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			transaction.Data = []byte(data.String())
+			m.mutex.Lock()
+			m.messages = append(m.messages, *transaction)
+			m.mutex.Unlock()
+			transaction = nil
+			reply(250, "2.0.0 OK: queued")
+		case strings.HasPrefix(command, "BDAT "):
+			fields := strings.Fields(command)
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < 0 || len(fields) > 3 || (len(fields) == 3 && fields[2] != "LAST") {
+				reply(501, "5.5.4 Syntax error in parameters")
+				continue
+			}
+			chunk := make([]byte, size)
+			if _, err = io.ReadFull(reader, chunk); err != nil {
+				return
+			}
+			if transaction == nil || len(transaction.Recipients) == 0 {
+				reply(503, "5.5.1 No valid recipients")
+				continue
+			}
+			transaction.Data = append(transaction.Data, chunk...)
+			if len(fields) == 2 {
+				reply(250, fmt.Sprintf("2.0.0 %d octets received", size))
+				continue
+			}
+			m.mutex.Lock()
+			m.messages = append(m.messages, *transaction)
+			m.mutex.Unlock()
+			transaction = nil
+			reply(250, "2.0.0 OK: queued")
+		case command == "RSET":
+			transaction = nil
+			reply(250, "2.0.0 OK")
+		case command == "NOOP":
+			reply(250, "2.0.0 OK")
+		case command == "QUIT":
+			reply(221, "2.0.0 Bye")
+			// The client closes the connection after the QUIT reply, which includes the TLS close
+			// notification on encrypted connections. It has to be read from the synchronous in-memory
+			// connection, so that the client does not block.
+			_, _ = io.Copy(io.Discard, reader)
+			return
+		default:
+			reply(502, "5.5.2 Command not implemented")
+		}
+	}
+}
+
+// serveAuth handles the AUTH command of the in-memory server. All authentication attempts with the
+// offered mechanisms are accepted.
+//
+// Parameters:
+//   - connection: The server end of the in-memory network connection.
+//   - reader: The bufio.Reader of the connection.
+//   - fields: The fields of the AUTH command line, i.e. the command, the mechanism and the optional
+//     initial response.
+//
+// Returns:
+//   - false if the communication with the client failed, true otherwise.
+func (m *MemoryClient) serveAuth(connection net.Conn, reader *bufio.Reader, fields []string) bool {
+	if len(fields) < 2 {
+		_, err := fmt.Fprint(connection, "501 5.5.4 Syntax error in parameters\r\n")
+		return err == nil
+	}
+
+	var challenges []string
+	switch strings.ToUpper(fields[1]) {
+	case "PLAIN", "XOAUTH2":
+		challenges = []string{""}
+	case "LOGIN":
+		challenges = []string{"Username:", "Password:"}
+	case "CRAM-MD5":
+		challenges = []string{"<1.1@" + memoryHost + ">"}
+	default:
+		_, err := fmt.Fprint(connection, "504 5.5.4 Unrecognized authentication type\r\n")
+		return err == nil
+	}
+	if len(fields) > 2 {
+		challenges = challenges[1:]
+	}
+	for _, challenge := range challenges {
+		if _, err := fmt.Fprintf(connection, "334 %s\r\n", base64.StdEncoding.EncodeToString([]byte(challenge))); err != nil {
+			return false
+		}
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		if strings.TrimSpace(response) == "*" {
+			_, err = fmt.Fprint(connection, "501 5.7.0 Authentication aborted\r\n")
+			return err == nil
+		}
+	}
+	_, err := fmt.Fprint(connection, "235 2.7.0 Authentication successful\r\n")
+	return err == nil
+}
+
+// memoryCommandAddress returns the address between the angle brackets of a MAIL FROM or RCPT TO
+// command line.
+//
+// Parameters:
+//   - line: The command line.
+//
+// Returns:
+//   - The address of the command, or an empty string if the line holds no address in angle brackets.
+func memoryCommandAddress(line string) string {
+	start := strings.Index(line, "<")
+	if start == -1 {
+		return ""
+	}
+	end := strings.Index(line[start:], ">")
+	if end == -1 {
+		return ""
+	}
+	return line[start+1 : start+end]
+}
+
+// memoryCommandParams returns the parameters that follow the address of a MAIL FROM or RCPT TO command.
+//
+// Parameters:
+//   - line: The command line as sent by the client.
+//
+// Returns:
+//   - The parameters of the command, or nil if the command has no parameters.
+func memoryCommandParams(line string) []string {
+	end := strings.Index(line, ">")
+	if end == -1 {
+		return nil
+	}
+	return strings.Fields(line[end+1:])
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestMemoryClient_DialAndSend tests the delivery of messages to the in-memory server with the supported
+// SMTP authentication mechanisms
+func TestMemoryClient_DialAndSend(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"no auth", nil},
+		{"auth PLAIN", []Option{WithSMTPAuth(SMTPAuthPlain), WithUsername("user"), WithPassword("secret")}},
+		{"auth LOGIN", []Option{WithSMTPAuth(SMTPAuthLogin), WithUsername("user"), WithPassword("secret")}},
+		{"auth CRAM-MD5", []Option{WithSMTPAuth(SMTPAuthCramMD5), WithUsername("user"), WithPassword("secret")}},
+		{"auth XOAUTH2", []Option{WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewMemoryClient(tt.opts...)
+			if err != nil {
+				t.Fatalf("NewMemoryClient failed: %s", err)
+			}
+			first, second := newPoolTestMsg(t), newPoolTestMsg(t)
+			if err = second.Cc("dotted-cc@domain.tld"); err != nil {
+				t.Fatalf("failed to set CC address: %s", err)
+			}
+			second.SetBodyString(TypeTextPlain, ".leading dot\r\nTest body")
+			if err = client.DialAndSend(first, second); err != nil {
+				t.Fatalf("DialAndSend failed: %s", err)
+			}
+
+			messages := client.Messages()
+			if len(messages) != 2 {
+				t.Fatalf("Messages failed. Expected 2 messages, got: %d", len(messages))
+			}
+			if messages[0].EnvelopeFrom != "valid-from@domain.tld" {
+				t.Errorf("Messages failed. Unexpected envelope from: %s", messages[0].EnvelopeFrom)
+			}
+			if got := strings.Join(messages[1].Recipients, ","); got != "valid-to@domain.tld,dotted-cc@domain.tld" {
+				t.Errorf("Messages failed. Unexpected recipients: %s", got)
+			}
+			if !strings.Contains(string(messages[0].Data), "Subject: Test subject\r\n") {
+				t.Errorf("Messages failed. Expected rendered message, got: %s", messages[0].Data)
+			}
+			if !strings.Contains(string(messages[1].Data), "\r\n.leading dot\r\n") {
+				t.Errorf("Messages failed. Expected dot-stuffing to be removed, got: %s", messages[1].Data)
+			}
+
+			client.ResetMessages()
+			if len(client.Messages()) != 0 {
+				t.Error("ResetMessages failed. Expected no recorded messages")
+			}
+		})
+	}
+}
+
+// TestMemoryClient_SetRcptResponse tests the scripted RCPT TO responses of the in-memory server
+func TestMemoryClient_SetRcptResponse(t *testing.T) {
+	client, err := NewMemoryClient()
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	client.SetRcptResponse("Temp-Fail@domain.tld", 450, "4.2.1 Mailbox busy")
+	client.SetRcptResponse("invalid@domain.tld", 550, "5.1.1 No such user")
+	message := newPoolTestMsg(t)
+	if err = message.To("valid-to@domain.tld", "temp-fail@domain.tld", "invalid@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO addresses: %s", err)
+	}
+	if err = client.DialAndSend(message); err == nil {
+		t.Fatal("DialAndSend expected error for rejected recipients, got nil")
+	}
+	if len(client.Messages()) != 0 {
+		t.Errorf("DialAndSend expected no message to be delivered, got: %d", len(client.Messages()))
+	}
+
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	results, err := client.SendWithResults(message)
+	if err != nil {
+		t.Fatalf("SendWithResults failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Fatalf("failed to close connection: %s", err)
+	}
+	for _, result := range results {
+		var sendErr *SendError
+		switch result.Recipient {
+		case "valid-to@domain.tld":
+			if result.Err != nil {
+				t.Errorf("SendWithResults expected success for %s, got: %s", result.Recipient, result.Err)
+			}
+		case "temp-fail@domain.tld":
+			if !errors.As(result.Err, &sendErr) || !sendErr.IsTemp() || sendErr.ErrorCode() != 450 {
+				t.Errorf("SendWithResults expected temporary error for %s, got: %v", result.Recipient, result.Err)
+			}
+		case "invalid@domain.tld":
+			if !errors.As(result.Err, &sendErr) || sendErr.IsTemp() || sendErr.ErrorCode() != 550 {
+				t.Errorf("SendWithResults expected permanent error for %s, got: %v", result.Recipient, result.Err)
+			}
+		}
+	}
+	messages := client.Messages()
+	if len(messages) != 1 || strings.Join(messages[0].Recipients, ",") != "valid-to@domain.tld" {
+		t.Errorf("SendWithResults expected delivery to the accepted recipient only, got: %+v", messages)
+	}
+}
+
+// TestMemoryClient_SetServerTLSConfig tests STARTTLS with the in-memory server
+func TestMemoryClient_SetServerTLSConfig(t *testing.T) {
+	certificate, pool := newTLSServerNameTestCertificate(t, memoryHost)
+	client, err := NewMemoryClient(WithTLSPolicy(TLSMandatory),
+		WithTLSConfig(&tls.Config{RootCAs: pool, ServerName: memoryHost, MinVersion: DefaultTLSMinVersion}),
+		WithSMTPAuth(SMTPAuthPlain), WithUsername("user"), WithPassword("secret"))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	if err = client.DialAndSend(newPoolTestMsg(t)); !errors.Is(err, ErrSTARTTLSNotSupported) {
+		t.Errorf("DialAndSend without server TLS config expected error: %s, got: %v", ErrSTARTTLSNotSupported, err)
+	}
+
+	client.SetServerTLSConfig(&tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: DefaultTLSMinVersion})
+	if err = client.DialWithContext(context.Background()); err != nil {
+		t.Fatalf("failed to dial with STARTTLS: %s", err)
+	}
+	if !client.isEncrypted {
+		t.Errorf("DialWithContext expected an encrypted connection")
+	}
+	if err = client.Send(newPoolTestMsg(t)); err != nil {
+		t.Errorf("Send over STARTTLS failed: %s", err)
+	}
+	if err = client.Close(); err != nil {
+		t.Errorf("failed to close connection: %s", err)
+	}
+	if len(client.Messages()) != 1 {
+		t.Errorf("Send over STARTTLS expected 1 message, got: %d", len(client.Messages()))
+	}
+}
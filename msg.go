@@ -14,9 +14,13 @@ import (
 	"io"
 	"mime"
 	"net/mail"
+	"net/textproto"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"syscall"
 	tt "text/template"
@@ -24,13 +28,95 @@ import (
 )
 
 var (
+	// ErrNoDateHeader indicates that no "Date" header has been set for the Msg.
+	ErrNoDateHeader = errors.New("no Date header set")
+
+	// ErrNoBodyPart indicates that the Msg does not contain a body part with the requested content type.
+	ErrNoBodyPart = errors.New("no body part with the requested content type found")
+
+	// ErrNoEmbedReference indicates that no HTML body part of the Msg references the placeholder of a file
+	// that should be embedded.
+	ErrNoEmbedReference = errors.New("no HTML body part references the embed placeholder")
+
+	// ErrFileSizeExceeded indicates that the content of an attachment exceeds the provided size.
+	ErrFileSizeExceeded = errors.New("file content exceeds the size limit")
+
+	// ErrInvalidEncoding indicates that an Encoding is not one of the supported encodings.
+	ErrInvalidEncoding = errors.New("invalid encoding")
+
+	// ErrInvalidFileSize indicates that the provided size of an attachment is negative.
+	ErrInvalidFileSize = errors.New("invalid file size - must not be negative")
+
+	// ErrInvalidImportance indicates that the importance headers of the Msg hold a value that does not map
+	// to a known Importance level.
+	ErrInvalidImportance = errors.New("invalid importance header value")
+
+	// ErrInvalidLanguageTag indicates that a language tag does not conform to the BCP 47 syntax.
+	ErrInvalidLanguageTag = errors.New("invalid BCP 47 language tag")
+
+	// ErrInvalidListUnsubscribeURL indicates that a List-Unsubscribe URL is not a valid mailto, http or https
+	// URL.
+	ErrInvalidListUnsubscribeURL = errors.New("invalid List-Unsubscribe URL")
+
+	// ErrInvalidMessageID indicates that a message identifier does not conform to the msg-id syntax of
+	// RFC 5322.
+	ErrInvalidMessageID = errors.New("invalid message identifier")
+
 	// ErrNoFromAddress indicates that the FROM address is not set, which is required.
 	ErrNoFromAddress = errors.New("no FROM address set")
 
+	// ErrNoListUnsubscribeURL indicates that no URL has been provided for the "List-Unsubscribe" header.
+	ErrNoListUnsubscribeURL = errors.New("no List-Unsubscribe URL provided")
+
+	// ErrNoMessage indicates that no Msg has been provided to be attached to another Msg.
+	ErrNoMessage = errors.New("no message provided")
+
 	// ErrNoRcptAddresses indicates that no recipient addresses have been set.
 	ErrNoRcptAddresses = errors.New("no recipient addresses set")
+
+	// ErrNoReplyToAddress indicates that no address has been provided for the "Reply-To" header.
+	ErrNoReplyToAddress = errors.New("no reply-to address provided")
+
+	// ErrUnsupportedCharset indicates that the content of a body part cannot be converted from its charset
+	// to UTF-8.
+	ErrUnsupportedCharset = errors.New("unsupported charset")
 )
 
+// importanceHeaders holds the headers that indicate the Importance level of a Msg, in the order of their
+// precedence.
+var importanceHeaders = []Header{HeaderImportance, HeaderXPriority, HeaderPriority}
+
+// emlFilenameReplacer replaces the characters of a subject that are not permitted in file names, when a
+// Msg is attached via AttachMessage.
+var emlFilenameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", `"`, "_",
+	"<", "_", ">", "_", "|", "_", "\r", "", "\n", "", "\t", " ")
+
+// replyAttributionRegexp matches the attribution line that mail clients put above a quoted reply, e.g.
+// "On Mon, 1 Jan 2024 at 10:00, Toni Tester <toni@example.com> wrote:".
+var replyAttributionRegexp = regexp.MustCompile(`^On\s.*\swrote:$`)
+
+// replySeparatorRegexp matches the separator lines that mail clients put above a quoted or forwarded
+// message, e.g. "-----Original Message-----" or "---------- Forwarded message ---------".
+var replySeparatorRegexp = regexp.MustCompile(`(?i)^(?:-{2,}\s*(?:original message|forwarded message)\s*-{2,}|` +
+	`begin forwarded message:)$`)
+
+// languageTagRegexp matches the syntax of a BCP 47 language tag, i.e. a language tag with its optional
+// subtags, a private use tag or an irregular grandfathered tag.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5646#section-2.1
+var languageTagRegexp = regexp.MustCompile(`(?i)^(?:` +
+	`(?:[a-z]{2,3}(?:-[a-z]{3}){0,3}|[a-z]{4,8})` + // language and extlang
+	`(?:-[a-z]{4})?` + // script
+	`(?:-(?:[a-z]{2}|[0-9]{3}))?` + // region
+	`(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` + // variant
+	`(?:-[0-9a-wy-z](?:-[a-z0-9]{2,8})+)*` + // extension
+	`(?:-x(?:-[a-z0-9]{1,8})+)?` + // private use
+	`|x(?:-[a-z0-9]{1,8})+` +
+	`|en-gb-oed|i-ami|i-bnn|i-default|i-enochian|i-hak|i-klingon|i-lux|i-mingo|i-navajo|i-pwn|i-tao|i-tay` +
+	`|i-tsu|sgn-be-fr|sgn-be-nl|sgn-ch-de` +
+	`)$`)
+
 const (
 	// errTplExecuteFailed indicates that the execution of a template has failed, including the underlying error.
 	errTplExecuteFailed = "failed to execute template: %w"
@@ -42,6 +128,9 @@ const (
 	// errParseMailAddr indicates that parsing of a mail address has failed, including the problematic address
 	// and error.
 	errParseMailAddr = "failed to parse mail address %q: %w"
+
+	// redactedHeaderValue is the placeholder that replaces the value of a redacted header.
+	redactedHeaderValue = "<redacted>"
 )
 
 const (
@@ -91,6 +180,10 @@ type Msg struct {
 	// boundary represents the delimiter for separating parts in a multipart message.
 	boundary string
 
+	// boundaryGenerator is an optional function that generates the boundary of the Msg on each
+	// render, if no fixed boundary is set.
+	boundaryGenerator func() string
+
 	// charset represents the Charset of the Msg.
 	//
 	// By default we set CharsetUTF8 for a Msg unless overridden by a corresponding MsgOption.
@@ -106,13 +199,31 @@ type Msg struct {
 	// encoding specifies the type of Encoding used for email messages and/or parts.
 	encoding Encoding
 
+	// forceMultipart indicates that the Msg is always rendered as multipart message, even if it consists of
+	// a single part only.
+	forceMultipart bool
+
 	// genHeader is a map where the keys are email headers (of type Header) and the values are slices of strings
 	// representing header values.
 	genHeader map[Header][]string
 
+	// headerFoldColumn defines the column at which long header lines are folded.
+	//
+	// If not set, DefaultHeaderFoldColumn is used.
+	headerFoldColumn int
+
+	// invalidDate indicates that the Date header of a parsed EML could not be parsed and has been
+	// preserved as raw value.
+	invalidDate bool
+
 	// isDelivered indicates wether the Msg has been delivered.
 	isDelivered bool
 
+	// maxLineLength defines the maximum line length of quoted-printable and base64 encoded bodies.
+	//
+	// If not set, MaxBodyLength is used.
+	maxLineLength int
+
 	// middlewares is a slice of Middleware used for modifying or handling messages before they are processed.
 	//
 	// middlewares are processed in FIFO order.
@@ -124,6 +235,12 @@ type Msg struct {
 	// parts is a slice that holds pointers to Part structures, which represent different parts of a Msg.
 	parts []*Part
 
+	// preformBody holds an already rendered MIME entity, including its Content-* header fields, that
+	// replaces the body parts, embeds and attachments of the Msg when it is written.
+	//
+	// It is set by middlewares like the SMIMESigner, which wrap the rendered body of a Msg.
+	preformBody []byte
+
 	// preformHeader maps Header types to their already preformatted string values.
 	//
 	// Preformatted Header values will not be affected by automatic line breaks.
@@ -144,6 +261,10 @@ type Msg struct {
 	//
 	// This can be useful in scenarios where headers are conditionally passed based on receipt - i. e. SMTP proxies.
 	noDefaultUserAgent bool
+
+	// noDefaultXMailer indicates whether the default X-Mailer header will be omitted for the Msg when it is
+	// being sent, while the default User-Agent header is still set.
+	noDefaultXMailer bool
 }
 
 // SendmailPath is the default system path to the sendmail binary - at least on standard Unix-like OS.
@@ -220,6 +341,10 @@ func WithCharset(charset Charset) MsgOption {
 // and decoded by email clients. This option should be called when creating a new Msg instance to
 // ensure that the desired encoding is set correctly.
 //
+// If EncodingAuto is passed, the encoding of each body part is selected based on its content when the
+// Msg is written. An unsupported Encoding is ignored and the default encoding is kept, use SetEncoding
+// to detect it.
+//
 // Parameters:
 //   - encoding: The Encoding value that specifies the desired encoding type for the Msg.
 //
@@ -230,6 +355,9 @@ func WithCharset(charset Charset) MsgOption {
 //   - https://datatracker.ietf.org/doc/html/rfc2047#section-6
 func WithEncoding(encoding Encoding) MsgOption {
 	return func(m *Msg) {
+		if !isValidEncoding(encoding) {
+			return
+		}
 		m.encoding = encoding
 	}
 }
@@ -275,6 +403,26 @@ func WithBoundary(boundary string) MsgOption {
 	}
 }
 
+// WithBoundaryGenerator sets a function that generates the MIME boundary of a Msg each time it
+// is rendered.
+//
+// By default, random MIME boundaries are created. With this option, the boundary is taken from the
+// provided generator instead, which allows deterministic output, e.g. for golden-file tests. The
+// generator is called once per rendering of the Msg. As with WithBoundary, nested multipart
+// sections use the generated boundary prefixed with their nesting depth, so that the boundaries
+// stay unique. A boundary set via WithBoundary or SetBoundary takes precedence over the generator.
+//
+// Parameters:
+//   - generator: A function returning the boundary to use for the Msg.
+//
+// Returns:
+//   - A MsgOption function that can be used to customize the Msg instance.
+func WithBoundaryGenerator(generator func() string) MsgOption {
+	return func(m *Msg) {
+		m.boundaryGenerator = generator
+	}
+}
+
 // WithMiddleware adds the given Middleware to the end of the list of the Client middlewares slice.
 // Middleware are processed in FIFO order.
 //
@@ -316,14 +464,67 @@ func WithPGPType(pgptype PGPType) MsgOption {
 	}
 }
 
+// WithMaxLineLength sets the maximum line length of quoted-printable and base64 encoded bodies
+// for a Msg during its creation or initialization.
+//
+// By default, encoded lines are wrapped at MaxBodyLength (76 characters) as suggested by RFC 2045.
+// Some legacy systems require shorter lines, which can be configured with this MsgOption. The line
+// length includes the soft line break character of quoted-printable encoded lines, and encoded
+// "=XX" sequences are never split across lines. Values above MaxBodyLength are capped to
+// MaxBodyLength, values below MinBodyLength are raised to MinBodyLength and a value of zero or
+// less restores the default.
+//
+// Parameters:
+//   - length: The maximum line length for encoded bodies.
+//
+// Returns:
+//   - A MsgOption function that can be used to customize the Msg instance.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.7
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.8
+func WithMaxLineLength(length int) MsgOption {
+	return func(m *Msg) {
+		m.maxLineLength = length
+	}
+}
+
+// WithHeaderFolding sets the column at which long header lines are folded for a Msg during its
+// creation or initialization.
+//
+// By default, header lines are folded at DefaultHeaderFoldColumn (78 characters, excluding the CRLF)
+// as recommended by RFC 5322. Previous versions folded header lines at MaxHeaderLength (76 characters),
+// which can be restored with WithHeaderFolding(MaxHeaderLength). Some mail gateways require shorter
+// header lines, which can be configured with this MsgOption as well. Header lines are folded at whitespace by inserting a CRLF before it, never inside
+// an encoded word. Lines that contain an encoded word are folded at MaxHeaderLength at the latest, as
+// required by RFC 2047. A single word that is longer than the column cannot be folded and exceeds it.
+// Preformatted headers set via SetGenHeaderPreformatted are not folded. A value of zero or less restores
+// the default.
+//
+// Parameters:
+//   - column: The maximum length of a header line, excluding the CRLF.
+//
+// Returns:
+//   - A MsgOption function that can be used to customize the Msg instance.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.1.1
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.2.3
+func WithHeaderFolding(column int) MsgOption {
+	return func(m *Msg) {
+		m.headerFoldColumn = column
+	}
+}
+
 // WithNoDefaultUserAgent disables the inclusion of a default User-Agent header in the Msg during
 // its creation or initialization.
 //
 // This MsgOption function allows you to customize the Msg instance by omitting the default
-// User-Agent header, which is typically included to provide information about the software
-// sending the email. This option can be useful when you want to have more control over the
+// User-Agent and X-Mailer headers, which are typically included to provide information about the
+// software sending the email. This option can be useful when you want to have more control over the
 // headers included in the message, such as when sending from a custom application or for
-// privacy reasons.
+// privacy reasons. The default User-Agent of a Client set via WithUserAgentVersion is omitted as well.
+// User-Agent and X-Mailer headers that are set explicitly, e.g. via SetUserAgent, are not affected.
 //
 // Returns:
 //   - A MsgOption function that can be used to customize the Msg instance.
@@ -333,6 +534,41 @@ func WithNoDefaultUserAgent() MsgOption {
 	}
 }
 
+// WithForceMultipart enforces that the Msg is always rendered as multipart message.
+//
+// By default, a Msg that consists of a single part only, e.g. a text/plain body without alternatives,
+// embeds or attachments, is rendered as single-part message with the Content-Type and
+// Content-Transfer-Encoding of that part as top-level header fields, since a multipart container would
+// only add overhead. This MsgOption function wraps such a single part in a multipart/mixed container
+// instead, which some processing pipelines expect. A Msg that is already rendered as multipart message
+// is not affected.
+//
+// Returns:
+//   - A MsgOption function that can be used to customize the Msg instance.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1.3
+func WithForceMultipart() MsgOption {
+	return func(m *Msg) {
+		m.forceMultipart = true
+	}
+}
+
+// WithNoDefaultXMailer disables the inclusion of a default X-Mailer header in the Msg during
+// its creation or initialization.
+//
+// This MsgOption function allows you to omit the default X-Mailer header, while the default
+// User-Agent header is still included. To omit both headers, use WithNoDefaultUserAgent instead.
+// An X-Mailer header that is set explicitly, e.g. via SetUserAgent, is not affected.
+//
+// Returns:
+//   - A MsgOption function that can be used to customize the Msg instance.
+func WithNoDefaultXMailer() MsgOption {
+	return func(m *Msg) {
+		m.noDefaultXMailer = true
+	}
+}
+
 // SetCharset sets or overrides the currently set encoding charset of the Msg.
 //
 // This method allows you to specify a character set for the email message. The charset is
@@ -340,25 +576,63 @@ func WithNoDefaultUserAgent() MsgOption {
 // mail clients. Common charset values include UTF-8, ISO-8859-1, and others. If a charset
 // is not explicitly set, CharsetUTF8 is used as default.
 //
+// The charset of the Msg is the default for all body parts and alternative parts that are added
+// afterward and is rendered in the charset parameter of their "Content-Type" header. Parts that
+// have already been added keep their charset, and the charset of a single part can still be
+// overridden with WithPartCharset or Part.SetCharset. Header values are encoded with the charset
+// that is set at the time the header is set, so SetCharset should be called before the headers
+// are set. Msg.Validate reports a charset that is not known to this package (see Charset.IsKnown).
+//
 // Parameters:
 //   - charset: The Charset value to set for the Msg, determining the encoding used for the message content.
 func (m *Msg) SetCharset(charset Charset) {
 	m.charset = charset
 }
 
+// SetMaxLineLength sets or overrides the maximum line length of quoted-printable and base64
+// encoded bodies of the Msg.
+//
+// See WithMaxLineLength for details on how the line length is applied.
+//
+// Parameters:
+//   - length: The maximum line length for encoded bodies.
+func (m *Msg) SetMaxLineLength(length int) {
+	m.maxLineLength = length
+}
+
+// SetHeaderFolding sets or overrides the column at which long header lines of the Msg are folded.
+//
+// See WithHeaderFolding for details on how the header lines are folded.
+//
+// Parameters:
+//   - column: The maximum length of a header line, excluding the CRLF.
+func (m *Msg) SetHeaderFolding(column int) {
+	m.headerFoldColumn = column
+}
+
 // SetEncoding sets or overrides the currently set Encoding of the Msg.
 //
 // This method allows you to specify the encoding type for the email message. The encoding
 // determines how the message content is represented and can affect the size and compatibility
 // of the email. Common encoding types include Base64 and Quoted-Printable. Setting a new
-// encoding may also adjust how the message content is processed and transmitted.
+// encoding may also adjust how the message content is processed and transmitted. With EncodingAuto,
+// the encoding of each body part is selected based on its content when the Msg is written, while
+// the headers are encoded with the "Q" encoding. Explicitly set encodings are used as they are.
 //
 // Parameters:
 //   - encoding: The Encoding value to set for the Msg, determining the method used to encode the
 //     message content.
-func (m *Msg) SetEncoding(encoding Encoding) {
+//
+// Returns:
+//   - An error if the Encoding is not supported (ErrInvalidEncoding). The currently set Encoding is
+//     kept in this case.
+func (m *Msg) SetEncoding(encoding Encoding) error {
+	if !isValidEncoding(encoding) {
+		return fmt.Errorf("%w: %q", ErrInvalidEncoding, encoding)
+	}
 	m.encoding = encoding
 	m.setEncoder()
+	return nil
 }
 
 // SetBoundary sets or overrides the currently set boundary of the Msg.
@@ -377,6 +651,18 @@ func (m *Msg) SetBoundary(boundary string) {
 	m.boundary = boundary
 }
 
+// SetBoundaryGenerator sets or overrides the function that generates the MIME boundary of the Msg
+// each time it is rendered.
+//
+// See WithBoundaryGenerator for details.
+//
+// Parameters:
+//   - generator: A function returning the boundary to use for the Msg. If nil, random boundaries
+//     are created.
+func (m *Msg) SetBoundaryGenerator(generator func() string) {
+	m.boundaryGenerator = generator
+}
+
 // SetMIMEVersion sets or overrides the currently set MIME version of the Msg.
 //
 // In the context of email, MIME Version 1.0 is the only officially standardized and
@@ -466,7 +752,11 @@ func (m *Msg) SetHeader(header Header, values ...string) {
 // storing them in the message's internal header map.
 //
 // Note: For adding email address-related headers (like "To:", "From", "Cc", etc.),
-// use SetAddrHeader instead to ensure proper formatting and validation.
+// use SetAddrHeader instead to ensure proper formatting and validation. A generic
+// "Content-Type" header is only rendered for messages without any body parts, embeds
+// or attachments, since the Content-Type of such messages is derived from their MIME
+// structure. Use the ContentType and the PartOption of SetBodyString and similar
+// methods (e.g. WithPartContentTypeParam) instead.
 //
 // Parameters:
 //   - header: The header field to set in the Msg.
@@ -488,6 +778,38 @@ func (m *Msg) SetGenHeader(header Header, values ...string) {
 	m.genHeader[header] = values
 }
 
+// SetGenHeaderEncoded sets a generic header field of the Msg to the provided value, using the given
+// RFC 2047 encoded-word encoding.
+//
+// While SetGenHeader encodes non-ASCII header values with the encoded-word encoding that matches the
+// Encoding of the Msg, this method allows to force the "Q" or the "B" encoding for a single header,
+// e.g. for interoperability with mail clients that mishandle one of them. Long values are split into
+// multiple encoded-words, without splitting multibyte characters across them, and are folded at the
+// maximum header length when the Msg is written. ASCII-only values are not encoded. If an unknown
+// HeaderEncoding is provided, the default encoding of the Msg is used.
+//
+// Parameters:
+//   - header: The header field to set in the Msg.
+//   - encoding: The HeaderEncoding to use for the encoded-words, i.e. HeaderEncodingQ or HeaderEncodingB.
+//   - value: The string value to associate with the header field.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2047#section-4
+//   - https://datatracker.ietf.org/doc/html/rfc2047#section-5
+func (m *Msg) SetGenHeaderEncoded(header Header, encoding HeaderEncoding, value string) {
+	if m.genHeader == nil {
+		m.genHeader = make(map[Header][]string)
+	}
+	encoder := m.encoder
+	switch encoding {
+	case HeaderEncodingB:
+		encoder = mime.BEncoding
+	case HeaderEncodingQ:
+		encoder = mime.QEncoding
+	}
+	m.genHeader[header] = []string{encoder.Encode(string(m.charset), value)}
+}
+
 // SetHeaderPreformatted sets a generic header field of the Msg, which content is already preformatted.
 //
 // Deprecated: This method only exists for compatibility reasons. Please use
@@ -508,7 +830,9 @@ func (m *Msg) SetHeaderPreformatted(header Header, value string) {
 //
 // This method does not take a slice of values but only a single value. The reason for this is that we do not
 // perform any content alteration on these kinds of headers and expect the user to have already taken care of
-// any kind of formatting required for the header.
+// any kind of formatting required for the header. Line breaks in the value are only permitted to fold it, i.e.
+// if they are followed by a space or a tab. Otherwise, writing the Msg fails with ErrInvalidHeader, so that
+// the value cannot be used to inject additional header fields.
 //
 // Note: This method should be used only as a last resort. Since the user is responsible for the formatting of
 // the message header, we cannot guarantee any compliance with RFC 2822. It is advised to use SetGenHeader
@@ -557,7 +881,7 @@ func (m *Msg) SetAddrHeader(header AddrHeader, values ...string) error {
 		addresses = append(addresses, address)
 	}
 	switch header {
-	case HeaderFrom:
+	case HeaderFrom, HeaderSender:
 		if len(addresses) > 0 {
 			m.addrHeader[header] = []*mail.Address{addresses[0]}
 		}
@@ -610,6 +934,47 @@ func (m *Msg) EnvelopeFrom(from string) error {
 	return m.SetAddrHeader(HeaderEnvelopeFrom, from)
 }
 
+// SetEnvelopeFrom sets the envelope sender address of the Msg, which the Client uses for the MAIL FROM
+// command.
+//
+// The envelope sender is independent of the "From" and "Sender" headers, so that e.g. bounces can be
+// directed to a dedicated bounce handling address while the visible "From" header stays unchanged. If no
+// envelope sender is set, the "Sender" address or the first "From" address is used, as described for
+// GetSender. An empty address or "<>" sets the null reverse-path, which RFC 5321 requires for
+// notifications like auto-replies or delivery status notifications, so that they do not cause any bounce
+// loops. A Msg with the null reverse-path still requires a "From" header. Other addresses are validated
+// according to RFC 5322.
+//
+// Parameters:
+//   - address: The envelope sender address, or an empty string or "<>" for the null reverse-path.
+//
+// Returns:
+//   - An error if the address cannot be parsed; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.5.5
+//   - https://datatracker.ietf.org/doc/html/rfc3834#section-3.3
+func (m *Msg) SetEnvelopeFrom(address string) error {
+	if address = strings.TrimSpace(address); address != "" && address != "<>" {
+		return m.EnvelopeFrom(address)
+	}
+	if m.addrHeader == nil {
+		m.addrHeader = make(map[AddrHeader][]*mail.Address)
+	}
+	m.addrHeader[HeaderEnvelopeFrom] = []*mail.Address{{}}
+	return nil
+}
+
+// hasNullEnvelopeFrom reports whether the null reverse-path has been set as envelope sender of the Msg
+// via SetEnvelopeFrom.
+//
+// Returns:
+//   - true if the envelope sender is the null reverse-path, false otherwise.
+func (m *Msg) hasNullEnvelopeFrom() bool {
+	from := m.addrHeader[HeaderEnvelopeFrom]
+	return len(from) > 0 && from[0] != nil && from[0].Address == ""
+}
+
 // EnvelopeFromFormat sets the provided name and mail address as HeaderEnvelopeFrom for the Msg.
 //
 // The HeaderEnvelopeFrom address is generally not included in the mail body but only used by the
@@ -645,6 +1010,80 @@ func (m *Msg) From(from string) error {
 	return m.SetAddrHeader(HeaderFrom, from)
 }
 
+// SetSender sets the "Sender" address in the mail body for the Msg.
+//
+// The "Sender" header specifies the mailbox of the agent responsible for the actual transmission of
+// the message, e.g. a mailing list or a system that sends on behalf of the author given in the "From"
+// header. Unless an envelope from address has been set via EnvelopeFrom, the "Sender" address is also
+// used as the envelope from address for the SMTP MAIL FROM command, which aligns the SPF checks with
+// the bounce address. The provided address is validated according to RFC 5322 and must be a single
+// address, a list of addresses returns an error.
+//
+// Parameters:
+//   - address: The "Sender" address to set in the Msg.
+//
+// Returns:
+//   - An error if the address is not a valid single mail address, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
+func (m *Msg) SetSender(address string) error {
+	return m.SetAddrHeader(HeaderSender, address)
+}
+
+// SetReturnPath sets the "Return-Path" header of the Msg.
+//
+// The "Return-Path" header records the reverse-path of the SMTP MAIL FROM command and is usually added
+// by the final delivering server. Setting it explicitly is useful for the archival of imported messages
+// and for gateways that key off it. The header is independent of the "From" and "Sender" headers and of
+// the envelope from address: it is neither derived from them nor changed by the Client when the Msg is
+// sent, and the SMTP MAIL FROM command still uses the address returned by GetSender. The provided address
+// is validated according to RFC 5322, a display name is dropped. An empty address or "<>" sets the null
+// reverse-path, which is used for bounces.
+//
+// Parameters:
+//   - address: The mail address to set as "Return-Path".
+//
+// Returns:
+//   - An error if the address is not a valid mail address, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.4
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.7
+func (m *Msg) SetReturnPath(address string) error {
+	address = strings.TrimSpace(address)
+	if address == "" || address == "<>" {
+		m.SetGenHeader(HeaderReturnPath, "<>")
+		return nil
+	}
+	parsedAddress, err := mail.ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf(errParseMailAddr, address, err)
+	}
+	m.SetGenHeader(HeaderReturnPath, "<"+parsedAddress.Address+">")
+	return nil
+}
+
+// GetReturnPath returns the address of the "Return-Path" header of the Msg.
+//
+// For a Msg parsed from an EML, this is the "Return-Path" recorded by the delivering server. The angle
+// brackets around the address are removed.
+//
+// Returns:
+//   - The address of the "Return-Path" header, or an empty string if the header is not set or holds
+//     the null reverse-path "<>".
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.4
+func (m *Msg) GetReturnPath() string {
+	returnPath := m.GetGenHeader(HeaderReturnPath)
+	if len(returnPath) == 0 {
+		return ""
+	}
+	address := strings.TrimSpace(returnPath[0])
+	return strings.TrimSuffix(strings.TrimPrefix(address, "<"), ">")
+}
+
 // FromFormat sets the provided name and mail address as the "FROM" address in the mail body for the Msg.
 //
 // The "FROM" address is included in the mail body and indicates the sender of the message to
@@ -663,6 +1102,36 @@ func (m *Msg) FromFormat(name, addr string) error {
 	return m.SetAddrHeader(HeaderFrom, fmt.Sprintf(`"%s" <%s>`, name, addr))
 }
 
+// AddFrom adds a single "FROM" address to the existing list of "FROM" addresses in the mail body for the Msg.
+//
+// RFC 5322 permits multiple authors of a message in the "FROM" header, e.g. for co-authored messages. In this
+// case, the "Sender" header must be set to the single mailbox that is responsible for the transmission of the
+// message, so a Msg with more than one "FROM" address requires a call of SetSender. Msg.Validate reports a
+// Msg with multiple "FROM" addresses but without "Sender" address, and the "Sender" address is used as the
+// envelope from address for the SMTP MAIL FROM command, unless an envelope from address has been set via
+// EnvelopeFrom. The provided address is validated according to RFC 5322 and will return an error if the
+// validation fails.
+//
+// Parameters:
+//   - address: The "FROM" address to add to the mail body.
+//
+// Returns:
+//   - An error if the address is not a valid mail address, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
+func (m *Msg) AddFrom(address string) error {
+	parsedAddress, err := mail.ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf(errParseMailAddr, address, err)
+	}
+	if m.addrHeader == nil {
+		m.addrHeader = make(map[AddrHeader][]*mail.Address)
+	}
+	m.addrHeader[HeaderFrom] = append(m.addrHeader[HeaderFrom], parsedAddress)
+	return nil
+}
+
 // To sets one or more "TO" addresses in the mail body for the Msg.
 //
 // The "TO" address specifies the primary recipient(s) of the message and is included in the mail body.
@@ -713,6 +1182,30 @@ func (m *Msg) AddToFormat(name, addr string) error {
 	return m.addAddr(HeaderTo, fmt.Sprintf(`"%s" <%s>`, name, addr))
 }
 
+// AddToNamed adds a single "TO" address with the provided display name to the existing list of recipients
+// in the mail body for the Msg.
+//
+// Unlike AddToFormat, which formats the name and address into a string that is parsed afterwards, this method
+// builds the address from its parts, so that the display name is always quoted and escaped properly. Display
+// names containing commas, quotes or non-ASCII characters are supported, non-ASCII display names are RFC 2047
+// encoded and line breaks in the display name cannot be used to inject additional headers. The original
+// display name is returned by GetTo.
+//
+// Parameters:
+//   - name: The display name of the recipient to add to the "TO" field.
+//   - address: The email address of the recipient to add to the "TO" field.
+//
+// Returns:
+//   - An error if the address cannot be parsed; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.4
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) AddToNamed(name, address string) error {
+	namedAddress := &mail.Address{Name: name, Address: address}
+	return m.addAddr(HeaderTo, namedAddress.String())
+}
+
 // ToIgnoreInvalid sets one or more "TO" addresses in the mail body for the Msg, ignoring any invalid addresses.
 //
 // This method allows you to add multiple "TO" recipients to the message body. Unlike the standard `To` method,
@@ -956,6 +1449,41 @@ func (m *Msg) ReplyToFormat(name, addr string) error {
 	return m.ReplyTo(fmt.Sprintf(`"%s" <%s>`, name, addr))
 }
 
+// SetReplyToList sets the "Reply-To" header of the Msg to a list of addresses, specifying where replies
+// should be sent.
+//
+// While ReplyTo sets a single address, RFC 5322 permits multiple addresses in the "Reply-To" header, e.g.
+// for shared inboxes where replies should reach several mailboxes. This method parses all provided
+// addresses and joins them into a single, correctly formatted "Reply-To" header, replacing any previously
+// set value. Display names are supported and non-ASCII display names are RFC 2047 encoded. If any of the
+// addresses cannot be parsed, the header is left unchanged and an error is returned. The addresses can be
+// retrieved via GetReplyTo.
+//
+// Parameters:
+//   - addresses: The email addresses to set as "Reply-To" addresses.
+//
+// Returns:
+//   - An error if no address is provided or any of the addresses cannot be parsed; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) SetReplyToList(addresses ...string) error {
+	if len(addresses) == 0 {
+		return ErrNoReplyToAddress
+	}
+	replyTo := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("failed to parse reply-to address %q: %w", address, err)
+		}
+		replyTo = append(replyTo, parsed.String())
+	}
+	m.SetGenHeader(HeaderReplyTo, strings.Join(replyTo, ", "))
+	return nil
+}
+
 // Subject sets the "Subject" header for the Msg, specifying the topic of the message.
 //
 // This method takes a single string as input and sets it as the "Subject" of the email. The subject line provides
@@ -1016,9 +1544,10 @@ func (m *Msg) GetMessageID() string {
 // SetMessageIDWithValue sets the "Message-ID" header for the Msg using the provided messageID string.
 //
 // This method formats the input messageID by enclosing it in angle brackets ("<>") and sets it as the "Message-ID"
-// header in the message. The "Message-ID" is a unique identifier for the email, helping email clients and servers
-// to track and reference the message. There are no validations performed on the input messageID, so it should
-// be in a suitable format for use as a Message-ID.
+// header in the message. If the messageID is already enclosed in angle brackets, it is used as is. The
+// "Message-ID" is a unique identifier for the email, helping email clients and servers to track and reference
+// the message. There are no validations performed on the input messageID, so it should be in a suitable format
+// for use as a Message-ID.
 //
 // Parameters:
 //   - messageID: The string to set as the "Message-ID" in the message header.
@@ -1026,61 +1555,208 @@ func (m *Msg) GetMessageID() string {
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
 func (m *Msg) SetMessageIDWithValue(messageID string) {
+	if strings.HasPrefix(messageID, "<") && strings.HasSuffix(messageID, ">") {
+		m.SetGenHeader(HeaderMessageID, messageID)
+		return
+	}
 	m.SetGenHeader(HeaderMessageID, fmt.Sprintf("<%s>", messageID))
 }
 
-// SetBulk sets the "Precedence: bulk" and "X-Auto-Response-Suppress: All" headers for the Msg,
-// which are recommended for automated emails such as out-of-office replies.
+// SetInReplyTo sets the "In-Reply-To" header of the Msg to the given message identifier.
 //
-// The "Precedence: bulk" header indicates that the message is a bulk email, and the "X-Auto-Response-Suppress: All"
-// header instructs mail servers and clients to suppress automatic responses to this message.
-// This is particularly useful for reducing unnecessary replies to automated notifications or replies.
+// The "In-Reply-To" header holds the "Message-ID" of the message this Msg is a reply to and is used by mail
+// clients like Gmail or Outlook to display the Msg in the conversation thread of the original message. The
+// message identifier is validated and enclosed in angle brackets, unless it already is. To thread the Msg
+// correctly, the "References" header should be set as well, e.g. via AddReferences or SetInReplyToMsg.
 //
-// References:
-//   - https://www.rfc-editor.org/rfc/rfc2076#section-3.9
-//   - https://learn.microsoft.com/en-us/openspecs/exchange_server_protocols/ms-oxcmail/ced68690-498a-4567-9d14-5c01f974d8b1#Appendix_A_Target_51
-func (m *Msg) SetBulk() {
-	m.SetGenHeader(HeaderPrecedence, "bulk")
-	m.SetGenHeader(HeaderXAutoResponseSuppress, "All")
-}
-
-// SetDate sets the "Date" header for the Msg to the current time in a valid RFC 1123 format.
+// Parameters:
+//   - messageID: The "Message-ID" of the original message, with or without angle brackets.
 //
-// This method retrieves the current time and formats it according to RFC 1123, ensuring that the "Date"
-// header is compliant with email standards. The "Date" header indicates when the message was created,
-// providing recipients with context for the timing of the email.
+// Returns:
+//   - An error wrapping ErrInvalidMessageID if the message identifier is invalid; otherwise, returns nil.
 //
 // References:
-//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.3
-//   - https://datatracker.ietf.org/doc/html/rfc1123
-func (m *Msg) SetDate() {
-	now := time.Now().Format(time.RFC1123Z)
-	m.SetGenHeader(HeaderDate, now)
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func (m *Msg) SetInReplyTo(messageID string) error {
+	normalized, err := normalizeMessageID(messageID)
+	if err != nil {
+		return err
+	}
+	m.SetGenHeader(HeaderInReplyTo, normalized)
+	return nil
 }
 
-// SetDateWithValue sets the "Date" header for the Msg using the provided time value in a valid RFC 1123 format.
+// AddReferences adds the given message identifiers to the "References" header of the Msg.
 //
-// This method takes a `time.Time` value as input and formats it according to RFC 1123, ensuring that the "Date"
-// header is compliant with email standards. The "Date" header indicates when the message was created,
-// providing recipients with context for the timing of the email. This allows for setting a custom date
-// rather than using the current time.
+// The "References" header holds the message identifiers of all messages of the conversation thread the Msg
+// belongs to, from the oldest to the most recent one. Each message identifier is validated and enclosed in
+// angle brackets, unless it already is, and the identifiers are joined with spaces. Identifiers that are
+// already part of the "References" header are not added again. If any of the message identifiers is invalid,
+// the header is left unchanged.
 //
 // Parameters:
-//   - timeVal: The time value used to set the "Date" header.
+//   - messageIDs: The message identifiers to add, with or without angle brackets.
+//
+// Returns:
+//   - An error wrapping ErrInvalidMessageID if any of the message identifiers is invalid; otherwise, returns nil.
 //
 // References:
-//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.3
-//   - https://datatracker.ietf.org/doc/html/rfc1123
-func (m *Msg) SetDateWithValue(timeVal time.Time) {
-	m.SetGenHeader(HeaderDate, timeVal.Format(time.RFC1123Z))
-}
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func (m *Msg) AddReferences(messageIDs ...string) error {
+	var references []string
+	if existing := m.GetGenHeader(HeaderReferences); len(existing) > 0 {
+		references = strings.Fields(existing[0])
+	}
+	for _, messageID := range messageIDs {
+		normalized, err := normalizeMessageID(messageID)
+		if err != nil {
+			return err
+		}
+		if !containsString(references, normalized) {
+			references = append(references, normalized)
+		}
+	}
+	if len(references) > 0 {
+		m.SetGenHeader(HeaderReferences, strings.Join(references, " "))
+	}
+	return nil
+}
+
+// SetInReplyToMsg sets the threading headers of the Msg, so that it is a reply to the given original Msg.
+//
+// The "In-Reply-To" header is set to the "Message-ID" of the original Msg and the "References" header is
+// replaced by the references of the original Msg followed by its "Message-ID". This way, the Msg is displayed
+// in the conversation thread of the original Msg, e.g. of a Msg imported via EMLToMsgFromFile. Invalid
+// message identifiers in the "References" header of the original Msg are dropped.
+//
+// Parameters:
+//   - original: A pointer to the Msg this Msg is a reply to.
+//
+// Returns:
+//   - An error wrapping ErrInvalidMessageID if the original Msg has no valid "Message-ID"; otherwise,
+//     returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func (m *Msg) SetInReplyToMsg(original *Msg) error {
+	if original == nil {
+		return fmt.Errorf("%w: no original message provided", ErrInvalidMessageID)
+	}
+	messageID, err := normalizeMessageID(original.GetMessageID())
+	if err != nil {
+		return fmt.Errorf("original message has no valid Message-ID: %w", err)
+	}
+	var references []string
+	candidates := strings.Fields(strings.Join(original.GetGenHeader(HeaderReferences), " "))
+	for _, candidate := range append(candidates, messageID) {
+		reference, refErr := normalizeMessageID(candidate)
+		if refErr == nil && !containsString(references, reference) {
+			references = append(references, reference)
+		}
+	}
+	m.SetGenHeader(HeaderInReplyTo, messageID)
+	m.SetGenHeader(HeaderReferences, strings.Join(references, " "))
+	return nil
+}
+
+// SetBulk sets the "Precedence: bulk", "Auto-Submitted: auto-generated" and "X-Auto-Response-Suppress: All"
+// headers for the Msg, which are recommended for automated emails such as newsletters, campaigns or
+// notifications.
+//
+// The "Precedence: bulk" header indicates that the message is a bulk email, the "Auto-Submitted: auto-generated"
+// header marks it as generated by an automatic process, and the "X-Auto-Response-Suppress: All" header instructs
+// mail servers and clients to suppress automatic responses to this message. This is particularly useful for
+// reducing unnecessary replies to automated notifications or replies. No other headers are set. Since
+// SetBulk overrides these headers, explicit values, e.g. via SetAutoSubmitted or SetGenHeader, must be
+// set after calling SetBulk.
+//
+// References:
+//   - https://www.rfc-editor.org/rfc/rfc2076#section-3.9
+//   - https://datatracker.ietf.org/doc/html/rfc3834#section-5
+//   - https://learn.microsoft.com/en-us/openspecs/exchange_server_protocols/ms-oxcmail/ced68690-498a-4567-9d14-5c01f974d8b1#Appendix_A_Target_51
+func (m *Msg) SetBulk() {
+	m.SetGenHeader(HeaderPrecedence, "bulk")
+	m.SetAutoSubmitted(AutoSubmittedAutoGenerated)
+	m.SetGenHeader(HeaderXAutoResponseSuppress, "All")
+}
+
+// SetAutoSubmitted sets the "Auto-Submitted" header for the Msg to the given value.
+//
+// The "Auto-Submitted" header indicates whether a message has been generated automatically, so that
+// automatic responders, like out-of-office replies, do not respond to it. Only the "Auto-Submitted"
+// header is set; use SetBulk to set the complete set of headers for bulk messages.
+//
+// Parameters:
+//   - value: The AutoSubmitted value, e.g. AutoSubmittedAutoGenerated or AutoSubmittedAutoReplied.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3834#section-5
+func (m *Msg) SetAutoSubmitted(value AutoSubmitted) {
+	m.SetGenHeader(HeaderAutoSubmitted, value.String())
+}
+
+// SetDate sets the "Date" header for the Msg to the current time in a valid RFC 1123 format.
+//
+// This method retrieves the current time and formats it according to RFC 1123, ensuring that the "Date"
+// header is compliant with email standards. The "Date" header indicates when the message was created,
+// providing recipients with context for the timing of the email.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.3
+//   - https://datatracker.ietf.org/doc/html/rfc1123
+func (m *Msg) SetDate() {
+	now := time.Now().Format(time.RFC1123Z)
+	m.SetGenHeader(HeaderDate, now)
+}
+
+// SetDateWithValue sets the "Date" header for the Msg using the provided time value in a valid RFC 1123 format.
+//
+// This method takes a `time.Time` value as input and formats it according to RFC 1123, ensuring that the "Date"
+// header is compliant with email standards. The "Date" header indicates when the message was created,
+// providing recipients with context for the timing of the email. This allows for setting a custom date
+// rather than using the current time.
+//
+// Parameters:
+//   - timeVal: The time value used to set the "Date" header.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.3
+//   - https://datatracker.ietf.org/doc/html/rfc1123
+func (m *Msg) SetDateWithValue(timeVal time.Time) {
+	m.SetGenHeader(HeaderDate, timeVal.Format(time.RFC1123Z))
+}
+
+// GetDate parses the "Date" header of the Msg and returns it as time.Time value.
+//
+// This method retrieves the first "Date" header value of the Msg and parses it according to the date-time
+// syntax of RFC 5322. The timezone offset of the header is preserved in the returned time.Time value.
+//
+// Returns:
+//   - The parsed time.Time value of the "Date" header.
+//   - ErrNoDateHeader if no "Date" header is set, or an error if the header value could not be parsed.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.3
+func (m *Msg) GetDate() (time.Time, error) {
+	date, ok := m.genHeader[HeaderDate]
+	if !ok || len(date) == 0 || date[0] == "" {
+		return time.Time{}, ErrNoDateHeader
+	}
+	parsed, err := mail.ParseDate(date[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse Date header: %w", err)
+	}
+	return parsed, nil
+}
 
 // SetImportance sets the "Importance" and "Priority" headers for the Msg to the specified Importance level.
 //
-// This method adjusts the email's importance based on the provided Importance value. If the importance level
-// is set to `ImportanceNormal`, no headers are modified. Otherwise, it sets the "Importance", "Priority",
-// "X-Priority", and "X-MSMail-Priority" headers accordingly, providing email clients with information on
-// how to prioritize the message. This allows the sender to indicate the significance of the email to recipients.
+// This method adjusts the email's importance based on the provided Importance value. It sets the
+// "Importance", "Priority", "X-Priority", and "X-MSMail-Priority" headers accordingly, providing email
+// clients with information on how to prioritize the message. This allows the sender to indicate the
+// significance of the email to recipients. If the importance level is set to `ImportanceNormal`, the
+// headers are removed, since a message without these headers is treated as normal priority. Unknown
+// Importance values are ignored and leave the headers untouched.
 //
 // Parameters:
 //   - importance: The Importance value that determines the priority of the email message.
@@ -1089,6 +1765,12 @@ func (m *Msg) SetDateWithValue(timeVal time.Time) {
 //   - https://datatracker.ietf.org/doc/html/rfc2156
 func (m *Msg) SetImportance(importance Importance) {
 	if importance == ImportanceNormal {
+		for _, header := range []Header{HeaderImportance, HeaderPriority, HeaderXPriority, HeaderXMSMailPriority} {
+			delete(m.genHeader, header)
+		}
+		return
+	}
+	if importance.String() == "" {
 		return
 	}
 	m.SetGenHeader(HeaderImportance, importance.String())
@@ -1097,6 +1779,57 @@ func (m *Msg) SetImportance(importance Importance) {
 	m.SetGenHeader(HeaderXMSMailPriority, importance.NumString())
 }
 
+// GetImportance returns the Importance level of the Msg.
+//
+// This method reconciles the importance headers of the Msg, e.g. of an imported EML, into a single
+// Importance level. The "Importance" header takes precedence, followed by the "X-Priority" header, since
+// these are the headers the major email clients honor, and finally the "Priority" header. A header that
+// holds an unknown value is skipped in favor of the next one. A "X-Priority" of 1 or 2 is considered
+// high, 4 or 5 is considered low. The "Priority" header is mapped according to RFC 2156, i.e. "urgent",
+// "normal" and "non-urgent", or according to the numeric values set by SetImportance. If none of the
+// headers is set, ImportanceNormal is returned.
+//
+// Returns:
+//   - The Importance level of the Msg, or ImportanceNormal if no header holds a known value.
+//   - An error wrapping ErrInvalidImportance if importance headers are set, but none of them holds a
+//     known value.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2156
+func (m *Msg) GetImportance() (Importance, error) {
+	var firstErr error
+	for _, header := range importanceHeaders {
+		value := m.GetGenHeader(header)
+		if len(value) == 0 {
+			continue
+		}
+		importance, err := importanceFromHeader(header, value[0])
+		if err == nil {
+			return importance, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return ImportanceNormal, firstErr
+}
+
+// Importance returns the Importance level of the Msg, reconciled from all of its importance headers.
+//
+// Unlike GetImportance, this method never fails, which makes it suited to route imported messages by
+// their priority. The headers are reconciled like by GetImportance. If none of the headers is set or
+// holds a known value, ImportanceNormal is returned.
+//
+// Returns:
+//   - The Importance level of the Msg.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2156
+func (m *Msg) Importance() Importance {
+	importance, _ := m.GetImportance()
+	return importance
+}
+
 // SetOrganization sets the "Organization" header for the Msg to the specified organization string.
 //
 // This method allows you to specify the organization associated with the email sender. The "Organization"
@@ -1112,6 +1845,124 @@ func (m *Msg) SetOrganization(org string) {
 	m.SetGenHeader(HeaderOrganization, org)
 }
 
+// SetContentLanguage sets the "Content-Language" header for the Msg to the specified language tags.
+//
+// This method validates each of the provided language tags against the BCP 47 language tag syntax and
+// sets the "Content-Language" header to the comma-separated list of the tags. Only the syntax of the tags
+// is validated, it is not checked whether the subtags are registered in the IANA language subtag registry.
+// If any of the tags is invalid, the header is not modified and an error is returned. Calling the method
+// without any tag removes the "Content-Language" header.
+//
+// Parameters:
+//   - langs: One or more BCP 47 language tags (e.g. "en", "de-DE" or "zh-Hant-TW").
+//
+// Returns:
+//   - An error if any of the language tags is not a valid BCP 47 language tag, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3282
+//   - https://datatracker.ietf.org/doc/html/rfc5646#section-2.1
+func (m *Msg) SetContentLanguage(langs ...string) error {
+	tags := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		tag := strings.TrimSpace(lang)
+		if !languageTagRegexp.MatchString(tag) {
+			return fmt.Errorf("%w: %q", ErrInvalidLanguageTag, lang)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) == 0 {
+		delete(m.genHeader, HeaderContentLang)
+		return nil
+	}
+	m.SetGenHeader(HeaderContentLang, strings.Join(tags, ", "))
+	return nil
+}
+
+// GetContentLanguage returns the language tags of the "Content-Language" header of the Msg.
+//
+// This method splits the comma-separated list of the "Content-Language" header into the individual
+// language tags.
+//
+// Returns:
+//   - A slice of strings containing the language tags, or an empty slice if the header is not set.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3282
+func (m *Msg) GetContentLanguage() []string {
+	var tags []string
+	for _, value := range m.GetGenHeader(HeaderContentLang) {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// SetListUnsubscribe sets the "List-Unsubscribe" header for the Msg to the specified URLs.
+//
+// This method validates each of the provided URLs and sets the "List-Unsubscribe" header to the
+// comma-separated list of the URLs, each enclosed in angle brackets as required by RFC 2369. Only
+// mailto, http and https URLs are accepted. URLs that are already enclosed in angle brackets are
+// accepted as well. If no URL is provided or any of the URLs is invalid, the header is not modified
+// and an error is returned.
+//
+// Parameters:
+//   - urls: One or more unsubscribe URLs (e.g. "mailto:unsubscribe@example.com" or
+//     "https://example.com/unsubscribe?id=123").
+//
+// Returns:
+//   - An error if no URL is provided or any of the URLs is invalid, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2369#section-3.2
+func (m *Msg) SetListUnsubscribe(urls ...string) error {
+	values := make([]string, 0, len(urls))
+	for _, rawURL := range urls {
+		value := strings.TrimSpace(rawURL)
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("%w: %q: %s", ErrInvalidListUnsubscribeURL, rawURL, err.Error())
+		}
+		switch strings.ToLower(parsed.Scheme) {
+		case "mailto":
+			if parsed.Opaque == "" {
+				return fmt.Errorf("%w: %q: missing address", ErrInvalidListUnsubscribeURL, rawURL)
+			}
+		case "http", "https":
+			if parsed.Host == "" {
+				return fmt.Errorf("%w: %q: missing host", ErrInvalidListUnsubscribeURL, rawURL)
+			}
+		default:
+			return fmt.Errorf("%w: %q: unsupported scheme", ErrInvalidListUnsubscribeURL, rawURL)
+		}
+		values = append(values, "<"+value+">")
+	}
+	if len(values) == 0 {
+		return ErrNoListUnsubscribeURL
+	}
+	m.SetGenHeader(HeaderListUnsubscribe, strings.Join(values, ", "))
+	return nil
+}
+
+// SetListUnsubscribePostOneClick sets the "List-Unsubscribe-Post" header for the Msg to signal support
+// for one-click unsubscription.
+//
+// This method sets the "List-Unsubscribe-Post" header to "List-Unsubscribe=One-Click" as specified in
+// RFC 8058. Mail clients will then unsubscribe the recipient by sending a POST request to the https URL
+// of the "List-Unsubscribe" header. Therefore, the header should only be used in combination with
+// SetListUnsubscribe and at least one https URL. Please note that RFC 8058 also requires the message to
+// carry a valid DKIM signature covering both headers.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8058#section-3.1
+func (m *Msg) SetListUnsubscribePostOneClick() {
+	m.SetGenHeader(HeaderListUnsubscribePost, "List-Unsubscribe=One-Click")
+}
+
 // SetUserAgent sets the "User-Agent" and "X-Mailer" headers for the Msg to the specified user agent string.
 //
 // This method allows you to specify the user agent or mailer software used to send the email.
@@ -1129,6 +1980,27 @@ func (m *Msg) SetUserAgent(userAgent string) {
 	m.SetGenHeader(HeaderXMailer, userAgent)
 }
 
+// SetUserAgentVersion sets the "User-Agent" and "X-Mailer" headers for the Msg to the given product and version.
+//
+// This method brands the message with the name and the version of the sending application, e.g. for the
+// triage of support requests. Both headers are set to the consistent string "product version". If the version
+// is empty, only the product is used.
+//
+// The headers set via SetUserAgentVersion or SetUserAgent take precedence over any default: they are neither
+// replaced by the default of a Client set via WithUserAgentVersion nor affected by WithNoDefaultUserAgent or
+// WithNoDefaultXMailer. If none of them is set, the default User-Agent of the Client is used, and if the
+// Client has no default either, the go-mail default is used.
+//
+// Parameters:
+//   - product: The name of the sending application.
+//   - version: The version of the sending application.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.7
+func (m *Msg) SetUserAgentVersion(product, version string) {
+	m.SetUserAgent(userAgentString(product, version))
+}
+
 // IsDelivered indicates whether the Msg has been delivered.
 //
 // This method checks the internal state of the message to determine if it has been successfully
@@ -1163,9 +2035,7 @@ func (m *Msg) RequestMDNTo(rcpts ...string) error {
 		}
 		addresses = append(addresses, address.String())
 	}
-	if _, ok := m.genHeader[HeaderDispositionNotificationTo]; ok {
-		m.genHeader[HeaderDispositionNotificationTo] = addresses
-	}
+	m.setReceiptHeader(HeaderDispositionNotificationTo, addresses)
 	return nil
 }
 
@@ -1206,9 +2076,7 @@ func (m *Msg) RequestMDNAddTo(rcpt string) error {
 	var addresses []string
 	addresses = append(addresses, m.genHeader[HeaderDispositionNotificationTo]...)
 	addresses = append(addresses, address.String())
-	if _, ok := m.genHeader[HeaderDispositionNotificationTo]; ok {
-		m.genHeader[HeaderDispositionNotificationTo] = addresses
-	}
+	m.setReceiptHeader(HeaderDispositionNotificationTo, addresses)
 	return nil
 }
 
@@ -1230,13 +2098,85 @@ func (m *Msg) RequestMDNAddToFormat(name, addr string) error {
 	return m.RequestMDNAddTo(fmt.Sprintf(`"%s" <%s>`, name, addr))
 }
 
-// GetSender returns the currently set envelope "FROM" address for the Msg. If no envelope
-// "FROM" address is set, it will use the first "FROM" address from the mail body. If the
-// useFullAddr parameter is true, it will return the full address string, including the name
-// if it is set.
+// RequestMDN requests a Message Disposition Notification (MDN), i.e. a read receipt, for the Msg.
+//
+// This method sets the "Disposition-Notification-To" header of RFC 8098 to the given address, so that the
+// mail client of the recipient can notify the sender once the Msg has been displayed. The address is
+// validated according to RFC 5322 and may contain a display name, which is RFC 2047 encoded if it holds
+// non-ASCII characters. Note that the "Return-Receipt-To" header, which is honored by some MTAs, requests
+// a delivery receipt rather than a read receipt and is set via RequestDeliveryReceipt.
+//
+// Parameters:
+//   - address: The address the MDN is sent to, e.g. "Toni Tester <toni@example.com>".
+//
+// Returns:
+//   - An error if the address cannot be parsed; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8098#section-2.1
+func (m *Msg) RequestMDN(address string) error {
+	return m.RequestMDNTo(address)
+}
+
+// GetMDNTo returns the addresses of the "Disposition-Notification-To" header of the Msg.
+//
+// Returns:
+//   - A slice of the addresses a Message Disposition Notification is requested for, or nil if no MDN is
+//     requested.
+//   - An error if the header value cannot be parsed as an address list.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8098#section-2.1
+func (m *Msg) GetMDNTo() ([]*mail.Address, error) {
+	return m.getReceiptHeader(HeaderDispositionNotificationTo)
+}
+
+// RequestDeliveryReceipt requests a delivery receipt for the Msg.
+//
+// This method sets the non-standard "Return-Receipt-To" header to the given address, which asks MTAs that
+// honor it to notify the sender once the Msg has been delivered to the mailbox of the recipient. Since the
+// header is not supported by all MTAs, the delivery status notifications of RFC 3461, which are requested
+// via the WithDSN option of the Client, are the more reliable way to obtain a delivery receipt. The
+// address is validated according to RFC 5322 and may contain a display name, which is RFC 2047 encoded if
+// it holds non-ASCII characters.
+//
+// Parameters:
+//   - address: The address the delivery receipt is sent to, e.g. "Toni Tester <toni@example.com>".
+//
+// Returns:
+//   - An error if the address cannot be parsed; otherwise, returns nil.
+func (m *Msg) RequestDeliveryReceipt(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf(errParseMailAddr, address, err)
+	}
+	m.setReceiptHeader(HeaderReturnReceiptTo, []string{parsed.String()})
+	return nil
+}
+
+// GetDeliveryReceiptTo returns the addresses of the "Return-Receipt-To" header of the Msg.
+//
+// Returns:
+//   - A slice of the addresses a delivery receipt is requested for, or nil if no delivery receipt is
+//     requested.
+//   - An error if the header value cannot be parsed as an address list.
+func (m *Msg) GetDeliveryReceiptTo() ([]*mail.Address, error) {
+	return m.getReceiptHeader(HeaderReturnReceiptTo)
+}
+
+// GetSender returns the effective envelope "FROM" address for the Msg. If no envelope "FROM"
+// address is set, it will use the "Sender" address from the mail body, and if that is not set
+// either, the first "FROM" address from the mail body. If the useFullAddr parameter is true, it
+// will return the full address string, including the name if it is set.
+//
+// If neither the envelope "FROM", nor the "Sender", nor the body "FROM" addresses are available,
+// it will return an error indicating that no "FROM" address is present.
+//
+// If the null reverse-path has been set via SetEnvelopeFrom, an empty string is returned.
 //
-// If neither the envelope "FROM" nor the body "FROM" addresses are available, it will return
-// an error indicating that no "FROM" address is present.
+// If multiple "FROM" addresses have been added via AddFrom, the "Sender" address is required and used
+// as envelope "FROM" address. Without it, the first "FROM" address is returned, but Msg.Validate reports
+// the Msg as invalid.
 //
 // Parameters:
 //   - useFullAddr: A boolean indicating whether to return the full address string (including
@@ -1248,7 +2188,13 @@ func (m *Msg) RequestMDNAddToFormat(name, addr string) error {
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
 func (m *Msg) GetSender(useFullAddr bool) (string, error) {
+	if m.hasNullEnvelopeFrom() {
+		return "", nil
+	}
 	from, ok := m.addrHeader[HeaderEnvelopeFrom]
+	if !ok || len(from) == 0 {
+		from, ok = m.addrHeader[HeaderSender]
+	}
 	if !ok || len(from) == 0 {
 		from, ok = m.addrHeader[HeaderFrom]
 		if !ok || len(from) == 0 {
@@ -1447,74 +2393,461 @@ func (m *Msg) GetBccString() []string {
 	return m.GetAddrHeaderString(HeaderBcc)
 }
 
-// GetGenHeader returns the content of the requested generic header of the Msg.
+// GetFromAddresses returns the content of the "From" header of the Msg as a list of parsed addresses.
 //
-// This method retrieves the list of string values associated with the specified generic header of the message.
-// It returns a slice of strings representing the header's values.
-//
-// Parameters:
-//   - header: The Header field whose values are being retrieved.
+// Unlike GetFrom, this method also parses a "From" header that has been set as raw value via
+// SetGenHeader or SetGenHeaderPreformatted. See GetReplyTo for the details of the parsing.
 //
 // Returns:
-//   - A slice of strings containing the values of the specified generic header.
-func (m *Msg) GetGenHeader(header Header) []string {
-	return m.genHeader[header]
+//   - A slice of `*mail.Address` containing the "From" header addresses, or nil if the header is not set.
+//   - An error if a raw "From" header cannot be parsed as address list.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) GetFromAddresses() ([]*mail.Address, error) {
+	return m.addressList(Header(HeaderFrom))
 }
 
-// GetParts returns the message parts of the Msg.
+// GetToAddresses returns the content of the "To" header of the Msg as a list of parsed addresses.
 //
-// This method retrieves the list of parts that make up the email message. Each part may represent
-// a different section of the email, such as a plain text body, HTML body, or attachments.
+// Unlike GetTo, this method also parses a "To" header that has been set as raw value via SetGenHeader or
+// SetGenHeaderPreformatted. See GetReplyTo for the details of the parsing.
 //
 // Returns:
-//   - A slice of Part pointers representing the message parts of the email.
-func (m *Msg) GetParts() []*Part {
-	return m.parts
+//   - A slice of `*mail.Address` containing the "To" header addresses, or nil if the header is not set.
+//   - An error if a raw "To" header cannot be parsed as address list.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.3
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) GetToAddresses() ([]*mail.Address, error) {
+	return m.addressList(Header(HeaderTo))
 }
 
-// GetAttachments returns the attachments of the Msg.
+// GetCcAddresses returns the content of the "Cc" header of the Msg as a list of parsed addresses.
 //
-// This method retrieves the list of files that have been attached to the email message.
-// Each attachment includes details about the file, such as its name, content type, and data.
+// Unlike GetCc, this method also parses a "Cc" header that has been set as raw value via SetGenHeader or
+// SetGenHeaderPreformatted. See GetReplyTo for the details of the parsing.
 //
 // Returns:
-//   - A slice of File pointers representing the attachments of the email.
-func (m *Msg) GetAttachments() []*File {
-	return m.attachments
+//   - A slice of `*mail.Address` containing the "Cc" header addresses, or nil if the header is not set.
+//   - An error if a raw "Cc" header cannot be parsed as address list.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.3
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) GetCcAddresses() ([]*mail.Address, error) {
+	return m.addressList(Header(HeaderCc))
 }
 
-// GetBoundary returns the boundary of the Msg.
+// GetBccAddresses returns the content of the "Bcc" header of the Msg as a list of parsed addresses.
 //
-// This method retrieves the MIME boundary that is used to separate different parts of the message,
-// particularly in multipart emails. The boundary helps to differentiate between various sections
-// such as plain text, HTML content, and attachments.
+// Unlike GetBcc, this method also parses a "Bcc" header that has been set as raw value via SetGenHeader or
+// SetGenHeaderPreformatted. See GetReplyTo for the details of the parsing.
 //
 // Returns:
-//   - A string representing the boundary of the message.
+//   - A slice of `*mail.Address` containing the "Bcc" header addresses, or nil if the header is not set.
+//   - An error if a raw "Bcc" header cannot be parsed as address list.
 //
 // References:
-//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1.1
-func (m *Msg) GetBoundary() string {
-	return m.boundary
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.3
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) GetBccAddresses() ([]*mail.Address, error) {
+	return m.addressList(Header(HeaderBcc))
 }
 
-// SetAttachments sets the attachments of the message.
+// GetReplyTo returns the content of the "Reply-To" header of the Msg as a list of parsed addresses.
 //
-// This method allows you to specify the attachments for the message by providing a slice of File pointers.
-// Each file represents an attachment that will be included in the email.
+// Unlike the To, Cc and Bcc address headers, the "Reply-To" header is stored as generic header of the Msg,
+// since it may have been set as raw value (e.g. when parsed from an EML). This method parses the address
+// list of the header, including RFC 2047 encoded display names, which are returned decoded as UTF-8,
+// quoted display names containing commas and group syntax, for which the group members are returned.
+// Encoded display names in all charsets that are supported by DefaultCharsetReader are decoded.
 //
-// Parameters:
-//   - files: A slice of pointers to File structures representing the attachments to set for the message.
+// Returns:
+//   - A slice of `*mail.Address` containing the "Reply-To" header addresses, or nil if the header is not set.
+//   - An error if the "Reply-To" header cannot be parsed as address list.
 //
 // References:
-//   - https://datatracker.ietf.org/doc/html/rfc2183
-func (m *Msg) SetAttachments(files []*File) {
-	m.attachments = files
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.2
+//   - https://datatracker.ietf.org/doc/html/rfc2047
+func (m *Msg) GetReplyTo() ([]*mail.Address, error) {
+	return m.addressList(HeaderReplyTo)
 }
 
-// SetAttachements sets the attachments of the message.
+// GetGenHeader returns the content of the requested generic header of the Msg.
 //
-// Deprecated: use SetAttachments instead.
+// This method retrieves the list of string values associated with the specified generic header of the message.
+// It returns a slice of strings representing the header's values.
+//
+// Parameters:
+//   - header: The Header field whose values are being retrieved.
+//
+// Returns:
+//   - A slice of strings containing the values of the specified generic header.
+func (m *Msg) GetGenHeader(header Header) []string {
+	return m.genHeader[header]
+}
+
+// HasGenHeader reports whether the given generic header is set for the Msg.
+//
+// This method checks whether the header has been set with at least one value, either via SetGenHeader or
+// via SetGenHeaderPreformatted, without the need to fetch its values. If the header is the name of an
+// address header, like "To" or "From", the addresses set via SetAddrHeader are checked as well. Default
+// headers like "Date" or "Message-ID", which are only added when the Msg is written, are not reported
+// before they have been added.
+//
+// Parameters:
+//   - header: The Header field to check.
+//
+// Returns:
+//   - true if the header is set for the Msg, false otherwise.
+func (m *Msg) HasGenHeader(header Header) bool {
+	if len(m.genHeader[header]) > 0 {
+		return true
+	}
+	if _, ok := m.preformHeader[header]; ok {
+		return true
+	}
+	if addrHeader, ok := addrHeaderByName(header); ok {
+		return m.HasAddrHeader(addrHeader)
+	}
+	return false
+}
+
+// RemoveGenHeader removes all instances of the given generic header from the Msg.
+//
+// This method deletes all values of the header, regardless of whether they have been set via SetGenHeader
+// or via SetGenHeaderPreformatted. If the header is the name of an address header, like "To" or "From",
+// the addresses set via SetAddrHeader are removed as well. Removing a header that is not set is a no-op.
+// Note that default headers like "Date", "Message-ID" or "User-Agent" are added again when the Msg is
+// written, unless they are disabled, e.g. via WithNoDefaultUserAgent.
+//
+// Parameters:
+//   - header: The Header field to remove from the Msg.
+func (m *Msg) RemoveGenHeader(header Header) {
+	delete(m.genHeader, header)
+	delete(m.preformHeader, header)
+	if addrHeader, ok := addrHeaderByName(header); ok {
+		m.RemoveAddrHeader(addrHeader)
+	}
+}
+
+// HasAddrHeader reports whether at least one address is set for the given AddrHeader of the Msg.
+//
+// Parameters:
+//   - header: The AddrHeader field to check (e.g., "From", "To", "Cc", "Bcc").
+//
+// Returns:
+//   - true if the address header is set for the Msg, false otherwise.
+func (m *Msg) HasAddrHeader(header AddrHeader) bool {
+	return len(m.addrHeader[header]) > 0
+}
+
+// RemoveAddrHeader removes all addresses of the given AddrHeader from the Msg.
+//
+// Removing an address header that is not set is a no-op.
+//
+// Parameters:
+//   - header: The AddrHeader field to remove from the Msg (e.g., "From", "To", "Cc", "Bcc").
+func (m *Msg) RemoveAddrHeader(header AddrHeader) {
+	delete(m.addrHeader, header)
+}
+
+// GetParts returns the message parts of the Msg.
+//
+// This method retrieves the list of parts that make up the email message. Each part may represent
+// a different section of the email, such as a plain text body, HTML body, or attachments.
+//
+// Returns:
+//   - A slice of Part pointers representing the message parts of the email.
+func (m *Msg) GetParts() []*Part {
+	return m.parts
+}
+
+// GetBodyDecoded returns the decoded content of the first body part of the Msg with the given content type.
+//
+// The content of the parts of a Msg is always held in its decoded form, i.e. base64 and quoted-printable
+// encoded bodies of a parsed EML are decoded when the EML is parsed. This method looks up the first body
+// part with the requested content type and converts its content from the charset of the part to UTF-8.
+// All charsets that are supported by DefaultCharsetReader can be converted.
+//
+// Parameters:
+//   - contentType: The ContentType of the requested body part. If empty, TypeTextPlain is used.
+//
+// Returns:
+//   - The UTF-8 encoded content of the body part.
+//   - An error if no body part with the given content type exists (ErrNoBodyPart), the content cannot
+//     be read or the charset of the part is not supported (ErrUnsupportedCharset).
+func (m *Msg) GetBodyDecoded(contentType ContentType) ([]byte, error) {
+	part, charset, err := m.bodyPart(contentType)
+	if err != nil {
+		return nil, err
+	}
+	content, err := part.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body part: %w", err)
+	}
+	return decodeCharsetToUTF8(content, charset)
+}
+
+// BodyReader returns a reader that streams the decoded content of the first body part of the Msg with the
+// given content type.
+//
+// This method is the streaming counterpart to GetBodyDecoded. Instead of returning the whole content at
+// once, the content of the body part is decoded while it is read, and converted from the charset of the
+// part to UTF-8 on the fly, so that e.g. a huge body can be piped to disk without holding a decoded copy
+// of it in memory. Body parts that have been parsed from an EML are held in their transfer encoded form,
+// e.g. base64 or quoted-printable, and are decoded by the returned reader. For other body parts, the
+// content is written into the returned reader by the write function of the part while it is read. All
+// charsets that are supported by DefaultCharsetReader can be converted. The returned reader must be
+// closed after use, so that the content is no longer written into it.
+//
+// Parameters:
+//   - contentType: The ContentType of the requested body part. If empty, TypeTextPlain is used.
+//
+// Returns:
+//   - An io.ReadCloser that streams the UTF-8 encoded content of the body part. An error that occurs
+//     while the content is written is returned by its Read method.
+//   - An error if no body part with the given content type exists (ErrNoBodyPart) or the charset of the
+//     part is not supported (ErrUnsupportedCharset).
+func (m *Msg) BodyReader(contentType ContentType) (io.ReadCloser, error) {
+	part, charset, err := m.bodyPart(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if part.encodedContent != nil {
+		decoder, err := newUTF8Reader(newTransferDecoder(bytes.NewReader(part.encodedContent), part.encodedWith),
+			charset)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(decoder), nil
+	}
+	reader, writer := io.Pipe()
+	decoder, err := newUTF8Reader(reader, charset)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if _, err := part.writeFunc(writer); err != nil {
+			_ = writer.CloseWithError(fmt.Errorf("failed to read body part: %w", err))
+			return
+		}
+		_ = writer.Close()
+	}()
+	return struct {
+		io.Reader
+		io.Closer
+	}{decoder, reader}, nil
+}
+
+// bodyPart returns the first body part of the Msg with the given content type and its charset.
+//
+// Parameters:
+//   - contentType: The ContentType of the requested body part. If empty, TypeTextPlain is used.
+//
+// Returns:
+//   - The first body part with the given content type.
+//   - The charset of the body part, which defaults to the charset of the Msg.
+//   - An error if no body part with the given content type exists (ErrNoBodyPart).
+func (m *Msg) bodyPart(contentType ContentType) (*Part, Charset, error) {
+	if contentType == "" {
+		contentType = TypeTextPlain
+	}
+	for _, part := range m.parts {
+		if part.isDeleted || !strings.EqualFold(part.contentType.String(), contentType.String()) {
+			continue
+		}
+		charset := part.charset
+		if charset == "" {
+			charset = m.charset
+		}
+		return part, charset, nil
+	}
+	return nil, "", fmt.Errorf("%w: %s", ErrNoBodyPart, contentType)
+}
+
+// TopReply returns the new content of a reply, i.e. the plain text body of the Msg above the quoted
+// reply text or the signature.
+//
+// This method is intended for systems that ingest replies, like ticketing systems, which are only
+// interested in what the sender has written and not in the quoted conversation. The body is cut at the
+// first line that starts a quote block ("> "), a signature (the "-- " delimiter), an attribution line
+// like "On ... wrote:", which may be wrapped over two lines, or a separator line like
+// "-----Original Message-----" or "---------- Forwarded message ---------". Leading and trailing white
+// space is removed from the result. The detection is based on heuristics and thus best-effort, since
+// mail clients do not mark quoted text in a standardized way.
+//
+// Returns:
+//   - The new content of the plain text body of the Msg, or the full body if no quoted text is found.
+//   - An error if the Msg has no plain text body part (ErrNoBodyPart) or it cannot be decoded.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4.3
+func (m *Msg) TopReply() (string, error) {
+	body, err := m.GetBodyDecoded(TypeTextPlain)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	end := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if isReplyBoundary(line, trimmed) {
+			end = i
+			break
+		}
+		if strings.HasPrefix(trimmed, "On ") && i+1 < len(lines) &&
+			replyAttributionRegexp.MatchString(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			end = i
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n")), nil
+}
+
+// isReplyBoundary reports whether the given line of a plain text body starts the quoted or signature
+// part of a reply.
+//
+// Parameters:
+//   - line: The line of the body.
+//   - trimmed: The line with leading and trailing white space removed.
+//
+// Returns:
+//   - true if the line starts a quote block, a signature, an attribution or a separator line.
+func isReplyBoundary(line, trimmed string) bool {
+	return line == "-- " || line == "--" || strings.HasPrefix(trimmed, ">") ||
+		replyAttributionRegexp.MatchString(trimmed) || replySeparatorRegexp.MatchString(trimmed)
+}
+
+// GetAttachments returns the attachments of the Msg.
+//
+// This method retrieves the list of files that have been attached to the email message.
+// Each attachment includes details about the file, such as its name, content type, and data.
+// For a Msg parsed from an EML, these are the parts with the "attachment" Content-Disposition.
+// The content type, the size and the content of each attachment can be retrieved via the
+// GetContentType, GetSize and GetContent methods of the File.
+//
+// Returns:
+//   - A slice of File pointers representing the attachments of the email.
+func (m *Msg) GetAttachments() []*File {
+	return m.attachments
+}
+
+// TotalAttachmentSize returns the total size of all attachments of the Msg after the transfer encoding.
+//
+// The content of each attachment is encoded with its transfer encoding, which is base64 unless a different
+// encoding has been set for the File, to determine the number of bytes it occupies in the rendered Msg.
+// This matches the size that is counted by the server, i.e. it includes the overhead of the base64 encoding
+// and the line breaks, but not the MIME headers of the attachments. Embeds are not included. If the content
+// of an attachment cannot be read, only the bytes encoded until then are counted.
+//
+// Returns:
+//   - The total size of all attachments in bytes after the transfer encoding.
+func (m *Msg) TotalAttachmentSize() int64 {
+	size, _ := m.encodedAttachmentSize()
+	return size
+}
+
+// Size returns the size of the Msg in bytes, as it is written by WriteTo and transmitted to the server.
+//
+// The Msg is rendered once, including the middlewares, into a writer that only counts the bytes, so
+// that the size includes all headers and the overhead of the transfer encodings, without holding the
+// rendered Msg in memory. The content of attachments and of bodies that are set via a writer function
+// or a template is produced to measure it. The size matches the number of bytes that WriteTo writes
+// as long as the Msg is not modified, and it is the size that is declared via the SIZE extension, i.e.
+// it does not include the dot-stuffing of the DATA command. Middlewares that add signatures of a varying
+// length, e.g. ECDSA based S/MIME signatures, can cause the size to differ by a few bytes. Since every
+// call renders the Msg again, including its middlewares and attachments that are added via AttachFunc,
+// the size is not cached by the Msg. It can be stored by the caller instead, e.g. for quota accounting,
+// as long as the Msg is not modified afterwards. The size checks of the Client do not call Size, but
+// measure the Msg while it is rendered for the transmission, so that it is rendered only once.
+//
+// Returns:
+//   - The size of the rendered Msg in bytes.
+//   - An error if the Msg cannot be rendered.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc1870
+func (m *Msg) Size() (int64, error) {
+	size, err := m.WriteTo(io.Discard)
+	if err != nil {
+		return size, fmt.Errorf("failed to render message: %w", err)
+	}
+	return size, nil
+}
+
+// encodedAttachmentSize returns the total size of all attachments of the Msg after the transfer encoding.
+//
+// Returns:
+//   - The total size of all attachments in bytes after the transfer encoding.
+//   - An error if the content of an attachment cannot be read.
+func (m *Msg) encodedAttachmentSize() (int64, error) {
+	var total int64
+	for _, file := range m.attachments {
+		if file == nil || file.Writer == nil {
+			continue
+		}
+		encoding := EncodingB64
+		if file.Enc != "" {
+			encoding = file.Enc
+		}
+		if headerEnc, ok := file.getHeader(HeaderContentTransferEnc); ok {
+			encoding = Encoding(headerEnc)
+		}
+		mw := &msgWriter{writer: io.Discard, maxLineLength: m.maxLineLength}
+		mw.writeBody(file.Writer, encoding)
+		total += mw.bytesWritten
+		if mw.err != nil {
+			return total, fmt.Errorf("failed to encode attachment %q: %w", file.Name, mw.err)
+		}
+	}
+	return total, nil
+}
+
+// GetBoundary returns the boundary of the Msg.
+//
+// This method retrieves the MIME boundary that is used to separate different parts of the message,
+// particularly in multipart emails. The boundary helps to differentiate between various sections
+// such as plain text, HTML content, and attachments.
+//
+// Returns:
+//   - A string representing the boundary of the message.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1.1
+func (m *Msg) GetBoundary() string {
+	return m.boundary
+}
+
+// GetBoundaryGenerator returns the function that generates the MIME boundary of the Msg each time it
+// is rendered.
+//
+// Returns:
+//   - The boundary generator set via WithBoundaryGenerator or SetBoundaryGenerator, or nil if none is set.
+func (m *Msg) GetBoundaryGenerator() func() string {
+	return m.boundaryGenerator
+}
+
+// SetAttachments sets the attachments of the message.
+//
+// This method allows you to specify the attachments for the message by providing a slice of File pointers.
+// Each file represents an attachment that will be included in the email.
+//
+// Parameters:
+//   - files: A slice of pointers to File structures representing the attachments to set for the message.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+func (m *Msg) SetAttachments(files []*File) {
+	m.attachments = files
+}
+
+// SetAttachements sets the attachments of the message.
+//
+// Deprecated: use SetAttachments instead.
 func (m *Msg) SetAttachements(files []*File) {
 	m.SetAttachments(files)
 }
@@ -1534,7 +2867,9 @@ func (m *Msg) UnsetAllAttachments() {
 //
 // This method retrieves the list of files that have been embedded in the message. Embeds are typically
 // images or other media files that are referenced directly in the content of the email, such as inline
-// images in HTML emails.
+// images in HTML emails. For a Msg parsed from an EML, these are the parts with the "inline"
+// Content-Disposition. The content type, the size and the content of each embed can be retrieved via
+// the GetContentType, GetSize and GetContent methods of the File.
 //
 // Returns:
 //   - A slice of pointers to File structures representing the embedded files in the message.
@@ -1626,6 +2961,26 @@ func (m *Msg) SetBodyWriter(
 	m.parts = []*Part{p}
 }
 
+// SetBodyWithContentType sets the body of the message to the given content of an arbitrary content type.
+//
+// This method generalizes the body API beyond plain text and HTML and allows to compose a body of any
+// content type, e.g. application/json or a text/calendar invite. Additional parameters of the
+// "Content-Type" header, like the "method" parameter of a calendar invite, can be set via the
+// WithPartContentTypeParam option, the charset and the encoding of the body via the WithPartCharset
+// and WithPartEncoding options.
+//
+// Parameters:
+//   - contentType: The ContentType of the body (e.g., application/json, text/calendar).
+//   - body: The content to set as the body of the message.
+//   - opts: Optional parameters for customizing the body part.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045
+//   - https://datatracker.ietf.org/doc/html/rfc2046
+func (m *Msg) SetBodyWithContentType(contentType ContentType, body []byte, opts ...PartOption) {
+	m.SetBodyWriter(contentType, writeFuncFromBuffer(bytes.NewBuffer(body)), opts...)
+}
+
 // SetBodyHTMLTemplate sets the body of the message from a given html/template.Template pointer.
 //
 // This method sets the body of the message using the provided HTML template and data. The content type
@@ -1732,6 +3087,27 @@ func (m *Msg) AddAlternativeWriter(
 	m.parts = append(m.parts, part)
 }
 
+// AddPartWithContentType adds a part with the given content of an arbitrary content type to the message.
+//
+// This method adds an alternative representation of the message body of any content type, e.g. a
+// text/calendar invite next to a text/plain and a text/html body. Additional parameters of the
+// "Content-Type" header, like the "method" parameter of a calendar invite, can be set via the
+// WithPartContentTypeParam option, the charset and the encoding of the part via the WithPartCharset
+// and WithPartEncoding options.
+//
+// Parameters:
+//   - contentType: The ContentType of the part (e.g., application/json, text/calendar).
+//   - body: The content of the part.
+//   - opts: Optional parameters for customizing the part.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045
+//   - https://datatracker.ietf.org/doc/html/rfc2046
+//   - https://datatracker.ietf.org/doc/html/rfc6047#section-2.4
+func (m *Msg) AddPartWithContentType(contentType ContentType, body []byte, opts ...PartOption) {
+	m.AddAlternativeWriter(contentType, writeFuncFromBuffer(bytes.NewBuffer(body)), opts...)
+}
+
 // AddAlternativeHTMLTemplate sets the alternative body of the message to an html/template.Template output.
 //
 // The content type will be set to "text/html" automatically. This method executes the provided HTML template
@@ -1837,11 +3213,99 @@ func (m *Msg) AttachReader(name string, reader io.Reader, opts ...FileOption) er
 	return nil
 }
 
+// AttachReaderWithContentType adds an attachment File via io.Reader with the given content type to the Msg.
+//
+// This method works like AttachReader, but instead of deriving the content type of the attachment from
+// the file extension of its name, the provided content type is used as is for the "Content-Type" header
+// of the attachment. This is useful for generated content, e.g. a PDF from a renderer, whose name does
+// not necessarily carry a meaningful file extension. If the content type is empty, it is derived from
+// the file name as usual.
+//
+// Parameters:
+//   - name: The name of the file to be attached.
+//   - reader: The io.Reader providing the file data to be attached.
+//   - contentType: The content type of the attachment (e.g. "application/pdf").
+//   - opts: Optional parameters for customizing the attachment.
+//
+// Returns:
+//   - An error if the file could not be read from the io.Reader, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-5
+func (m *Msg) AttachReaderWithContentType(name string, reader io.Reader, contentType ContentType,
+	opts ...FileOption,
+) error {
+	return m.AttachReader(name, reader, append([]FileOption{WithFileContentType(contentType)}, opts...)...)
+}
+
+// AttachReaderWithSize adds an attachment File via io.Reader with the given content type and size to the Msg.
+//
+// This method works like AttachReaderWithContentType, but reads at most size bytes from the io.Reader
+// into a buffer that is allocated upfront. The size therefore serves as both, the expected size of the
+// content and its upper limit. If the io.Reader provides more than size bytes, ErrFileSizeExceeded is
+// returned and the attachment is not added, before any encoding takes place.
+//
+// Parameters:
+//   - name: The name of the file to be attached.
+//   - reader: The io.Reader providing the file data to be attached.
+//   - contentType: The content type of the attachment (e.g. "application/pdf").
+//   - size: The size of the content in bytes, which must not be exceeded. Must not be negative.
+//   - opts: Optional parameters for customizing the attachment.
+//
+// Returns:
+//   - An error if the size is invalid or exceeded or the file could not be read from the io.Reader,
+//     otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-5
+func (m *Msg) AttachReaderWithSize(name string, reader io.Reader, contentType ContentType, size int64,
+	opts ...FileOption,
+) error {
+	file, err := fileFromReaderWithSize(name, reader, size)
+	if err != nil {
+		return err
+	}
+	m.attachments = m.appendFile(m.attachments, file,
+		append([]FileOption{WithFileContentType(contentType)}, opts...)...)
+	return nil
+}
+
+// AttachFunc adds an attachment File to the Msg, whose content is produced lazily by the given function.
+//
+// This method allows you to attach content that is expensive to generate, e.g. a report, so that it is
+// only generated if the Msg is actually written. The produce function is called each time the Msg is
+// written, e.g. via WriteTo, and the returned io.Reader is read until EOF and closed afterwards if it is
+// an io.Closer. When the Msg is sent via the Client, the content is produced once per delivery attempt
+// and held in memory for the attempt, so that it is not produced twice if e.g. the size of the Msg is
+// checked before it is sent. Since the content is not available before, the content type must be
+// provided and the charset of text attachments is not inferred, see WithFileCharset.
+//
+// Parameters:
+//   - name: The name of the file to be attached.
+//   - contentType: The content type of the attachment (e.g. "application/pdf").
+//   - produce: The function that produces the content of the attachment. If it returns an error,
+//     writing the Msg fails with an error wrapping it.
+//   - opts: Optional parameters for customizing the attachment.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+func (m *Msg) AttachFunc(name string, contentType ContentType, produce func() (io.Reader, error),
+	opts ...FileOption,
+) {
+	file := fileFromFunc(name, produce)
+	m.attachments = m.appendFile(m.attachments, file,
+		append([]FileOption{WithFileContentType(contentType)}, opts...)...)
+}
+
 // AttachReadSeeker adds an attachment File via io.ReadSeeker to the Msg.
 //
 // This method allows you to attach a file to the message using an io.ReadSeeker, which is more efficient
 // for larger files compared to AttachReader, as it allows for seeking through the data without needing
-// to load the entire content into memory.
+// to load the entire content into memory. The content is encoded on the fly while the Msg is written,
+// and the reader is rewound to its start before each write, so that the Msg can be written repeatedly,
+// e.g. when a delivery attempt is retried.
 //
 // Parameters:
 //   - name: The name of the file to be attached.
@@ -1855,6 +3319,82 @@ func (m *Msg) AttachReadSeeker(name string, reader io.ReadSeeker, opts ...FileOp
 	m.attachments = m.appendFile(m.attachments, file, opts...)
 }
 
+// AttachEML adds a raw message in EML format as "message/rfc822" attachment to the Msg.
+//
+// This method allows to forward a message as attachment, e.g. an incoming message that has been read from
+// an EML file, together with an own cover text in the body of the Msg. The bytes of the message are attached
+// unchanged, with the 7bit transfer encoding if they only consist of ASCII characters and the 8bit transfer
+// encoding otherwise, since RFC 2046 does not permit any other transfer encoding for "message/rfc822" parts.
+// The transfer encoding must therefore not be changed via WithFileEncoding. If the name is empty, the
+// attachment is named "message.eml".
+//
+// Parameters:
+//   - raw: The raw bytes of the message to be attached.
+//   - name: The file name of the attachment.
+//   - opts: Optional parameters for customizing the attachment.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.2.1
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+func (m *Msg) AttachEML(raw []byte, name string, opts ...FileOption) {
+	if name == "" {
+		name = "message.eml"
+	}
+	encoding := EncodingUSASCII
+	if !isASCIIString(string(raw)) {
+		encoding = NoEncoding
+	}
+	buffer := bytes.NewBuffer(append([]byte(nil), raw...))
+	file := &File{
+		ContentType: TypeMessageRFC822,
+		Enc:         encoding,
+		Header:      make(map[string][]string),
+		Name:        name,
+		Writer:      writeFuncFromBuffer(buffer),
+	}
+	m.attachments = m.appendFile(m.attachments, file, opts...)
+}
+
+// AttachMessage adds another Msg as "message/rfc822" attachment to the Msg.
+//
+// This method renders the provided Msg and attaches it like AttachEML, so that the Msg can be forwarded as
+// attachment, e.g. after it has been parsed from an EML. Since the Msg is rendered anew, its bytes may differ
+// from the original EML it has been parsed from. To preserve the original bytes, use AttachEML instead. The
+// attachment is named after the subject of the Msg, with the characters that are not permitted in file names
+// replaced, and the ".eml" file extension.
+//
+// Parameters:
+//   - message: The Msg to be attached.
+//   - opts: Optional parameters for customizing the attachment.
+//
+// Returns:
+//   - An error if no Msg is provided or the Msg cannot be rendered; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.2.1
+func (m *Msg) AttachMessage(message *Msg, opts ...FileOption) error {
+	if message == nil {
+		return ErrNoMessage
+	}
+	buffer := bytes.Buffer{}
+	if _, err := message.WriteTo(&buffer); err != nil {
+		return fmt.Errorf("failed to render message: %w", err)
+	}
+	name := "message.eml"
+	if subject := message.GetGenHeader(HeaderSubject); len(subject) > 0 {
+		wordDecoder := mime.WordDecoder{}
+		decoded, err := wordDecoder.DecodeHeader(subject[0])
+		if err != nil {
+			decoded = subject[0]
+		}
+		if sanitized := strings.TrimSpace(emlFilenameReplacer.Replace(decoded)); sanitized != "" {
+			name = sanitized + ".eml"
+		}
+	}
+	m.AttachEML(buffer.Bytes(), name, opts...)
+	return nil
+}
+
 // AttachHTMLTemplate adds the output of a html/template.Template pointer as a File attachment to the Msg.
 //
 // This method allows you to attach the rendered output of an HTML template as a file to the message.
@@ -1959,6 +3499,81 @@ func (m *Msg) EmbedFile(name string, opts ...FileOption) {
 	m.embeds = m.appendFile(m.embeds, file, opts...)
 }
 
+// EmbedAndReference adds an embedded File to the Msg and references it in the HTML body parts.
+//
+// This method embeds a file from the filesystem like EmbedFile and rewrites all src attributes in the
+// text/html body parts of the Msg whose value equals the given placeholder (e.g. <img src="logo.png">) to
+// the "cid:" URL of the embedded file (e.g. <img src="cid:logo.png">). Only src attributes with a quoted
+// value that exactly matches the placeholder are rewritten, so unrelated src attributes are left untouched.
+// The method can be called multiple times to embed and reference multiple files. Since the HTML body parts
+// are rewritten immediately, the HTML body must be set before calling this method.
+//
+// If no "Content-ID" has been set via the FileOption functions, a unique Content-ID is generated like
+// in EmbedReaderWithContentID, so that embedded files with the same base name do not collide.
+//
+// Parameters:
+//   - name: The name of the file to be embedded.
+//   - placeholder: The src attribute value to be replaced with the "cid:" URL. If empty, the base name
+//     of the file is used.
+//   - opts: Optional parameters for customizing the embedded file.
+//
+// Returns:
+//   - The Content-ID of the embedded file, without the enclosing angle brackets.
+//   - An error if the file cannot be read, if no HTML body part references the placeholder or if no
+//     Content-ID could be generated.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+//   - https://datatracker.ietf.org/doc/html/rfc2392
+func (m *Msg) EmbedAndReference(name, placeholder string, opts ...FileOption) (string, error) {
+	if placeholder == "" {
+		placeholder = filepath.Base(name)
+	}
+	pattern := regexp.MustCompile(`((?:^|\s)(?i:src)\s*=\s*)(?:"` + regexp.QuoteMeta(placeholder) + `"|'` +
+		regexp.QuoteMeta(placeholder) + `')`)
+
+	var htmlParts []*Part
+	var contents [][]byte
+	for _, part := range m.parts {
+		if part.isDeleted || part.contentType != TypeTextHTML {
+			continue
+		}
+		content, err := part.GetContent()
+		if err != nil {
+			return "", fmt.Errorf("failed to read HTML body part: %w", err)
+		}
+		if pattern.Match(content) {
+			htmlParts = append(htmlParts, part)
+			contents = append(contents, content)
+		}
+	}
+	if len(htmlParts) == 0 {
+		return "", fmt.Errorf("%w: %q", ErrNoEmbedReference, placeholder)
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		return "", fmt.Errorf("failed to embed file: %w", err)
+	}
+	generatedID, err := randomContentID()
+	if err != nil {
+		return "", err
+	}
+	file := fileFromFS(name)
+	m.embeds = m.appendFile(m.embeds, file, opts...)
+	contentID, ok := file.getHeader(HeaderContentID)
+	if !ok {
+		contentID = generatedID
+		file.setHeader(HeaderContentID, contentID)
+	}
+	contentID = strings.TrimSuffix(strings.TrimPrefix(contentID, "<"), ">")
+
+	replacement := `${1}"` + strings.ReplaceAll("cid:"+url.PathEscape(contentID), "$", "$$") + `"`
+	for i, part := range htmlParts {
+		part.SetContent(string(pattern.ReplaceAll(contents[i], []byte(replacement))))
+	}
+	return contentID, nil
+}
+
 // EmbedReader adds an embedded File from an io.Reader to the Msg.
 //
 // This method embeds a file into the email message by reading its content from an io.Reader.
@@ -1984,6 +3599,47 @@ func (m *Msg) EmbedReader(name string, reader io.Reader, opts ...FileOption) err
 	return nil
 }
 
+// EmbedReaderWithContentID adds an embedded File from an io.Reader to the Msg and returns its Content-ID.
+//
+// This method embeds inline content, e.g. an image that has been generated in memory, without the need to
+// write it to disk first. Like EmbedReader, it reads all data into memory. Unless a "Content-ID" has been
+// set via WithFileContentID, a unique Content-ID is generated for the embedded file. The returned Content-ID
+// can be used to reference the file in the HTML body via a "cid:" URL, e.g. <img src="cid:...">. The file
+// is embedded with the "inline" Content-Disposition. If the content type cannot be derived from the file
+// extension, it is detected from the content. It can be overridden via WithFileContentType.
+//
+// Parameters:
+//   - name: The name of the file to be embedded.
+//   - reader: The io.Reader providing the file data to be embedded.
+//   - opts: Optional parameters for customizing the embedded file.
+//
+// Returns:
+//   - The Content-ID of the embedded file, without the enclosing angle brackets.
+//   - An error if the file could not be read from the io.Reader or no Content-ID could be generated.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2183
+//   - https://datatracker.ietf.org/doc/html/rfc2392
+func (m *Msg) EmbedReaderWithContentID(name string, reader io.Reader, opts ...FileOption) (string, error) {
+	file, err := fileFromReader(name, reader)
+	if err != nil {
+		return "", err
+	}
+	generatedID, err := randomContentID()
+	if err != nil {
+		return "", err
+	}
+
+	file.detectContentType = true
+	m.embeds = m.appendFile(m.embeds, file, opts...)
+	contentID, ok := file.getHeader(HeaderContentID)
+	if !ok {
+		contentID = generatedID
+		file.setHeader(HeaderContentID, contentID)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(contentID, "<"), ">"), nil
+}
+
 // EmbedReadSeeker adds an embedded File from an io.ReadSeeker to the Msg.
 //
 // This method embeds a file into the email message by reading its content from an io.ReadSeeker.
@@ -2087,9 +3743,19 @@ func (m *Msg) EmbedFromEmbedFS(name string, fs *embed.FS, opts ...FileOption) er
 
 // Reset resets all headers, body parts, attachments, and embeds of the Msg.
 //
-// This method clears all address headers, attachments, embeds, generic headers, and body parts of the message.
-// However, it preserves the existing encoding, charset, boundary, and other message-level settings.
-// Use this method to reset the message content while keeping certain configurations intact.
+// This method clears all address headers (including the recipients), generic headers, preformatted headers
+// (including generated headers like Date and Message-ID), body parts, attachments and embeds of the message.
+// The delivery state and the send error of the Msg are cleared as well, so that the Msg can be repopulated
+// and sent again.
+//
+// The following message-level settings survive a Reset:
+//   - the charset, encoding and the mime.WordEncoder
+//   - the MIME boundary, the boundary generator and the MIME version
+//   - the forced multipart structure
+//   - the list of middlewares
+//   - the PGP type
+//   - the maximum line length and the header fold column
+//   - the suppression of the default User-Agent and X-Mailer headers
 //
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc5322
@@ -2098,7 +3764,212 @@ func (m *Msg) Reset() {
 	m.attachments = nil
 	m.embeds = nil
 	m.genHeader = make(map[Header][]string)
+	m.invalidDate = false
+	m.isDelivered = false
 	m.parts = nil
+	m.preformBody = nil
+	m.preformHeader = make(map[Header]string)
+	m.sendError = nil
+}
+
+// Clone returns a deep copy of the Msg.
+//
+// All headers, body parts, attachments, embeds and message-level settings of the Msg are copied, so that
+// changes to the clone do not affect the original Msg and vice versa. This allows to build a Msg once and
+// send independent copies of it, e.g. concurrently. The delivery state and the send error are not copied.
+//
+// Note that the content writers of the body parts, attachments and embeds as well as the middlewares are
+// shared between the original and the clone. Attachments and embeds that were created from an io.Reader
+// or an io.ReadSeeker share the same underlying reader and must therefore not be written concurrently.
+//
+// Returns:
+//   - A pointer to the cloned Msg.
+func (m *Msg) Clone() *Msg {
+	clone := *m
+	clone.isDelivered = false
+	clone.sendError = nil
+
+	clone.addrHeader = make(map[AddrHeader][]*mail.Address, len(m.addrHeader))
+	for header, addresses := range m.addrHeader {
+		list := make([]*mail.Address, len(addresses))
+		for i, address := range addresses {
+			if address == nil {
+				continue
+			}
+			addressCopy := *address
+			list[i] = &addressCopy
+		}
+		clone.addrHeader[header] = list
+	}
+	clone.genHeader = make(map[Header][]string, len(m.genHeader))
+	for header, values := range m.genHeader {
+		clone.genHeader[header] = append([]string(nil), values...)
+	}
+	clone.preformHeader = make(map[Header]string, len(m.preformHeader))
+	for header, value := range m.preformHeader {
+		clone.preformHeader[header] = value
+	}
+
+	if m.parts != nil {
+		clone.parts = make([]*Part, len(m.parts))
+		for i, part := range m.parts {
+			partCopy := *part
+			if part.contentTypeParams != nil {
+				partCopy.contentTypeParams = make(map[string]string, len(part.contentTypeParams))
+				for key, value := range part.contentTypeParams {
+					partCopy.contentTypeParams[key] = value
+				}
+			}
+			clone.parts[i] = &partCopy
+		}
+	}
+	if m.preformBody != nil {
+		clone.preformBody = append([]byte(nil), m.preformBody...)
+	}
+	clone.attachments = cloneFiles(m.attachments)
+	clone.embeds = cloneFiles(m.embeds)
+	if m.middlewares != nil {
+		clone.middlewares = append([]Middleware(nil), m.middlewares...)
+	}
+	return &clone
+}
+
+// with8BitParts returns a copy of the Msg, in which the quoted-printable encoded body parts and the body
+// parts with EncodingAuto are switched to the 8bit encoding, if their content can be transmitted unencoded.
+//
+// The content of each of these parts is rendered once and held in memory, so that the
+// Content-Transfer-Encoding header always matches the content that is written. Parts whose content is
+// not suitable for the 8bit encoding keep their encoding. Attachments and embeds are not
+// affected.
+//
+// Returns:
+//   - A pointer to the copy of the Msg.
+//   - An error if the content of a body part could not be rendered.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc6152
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-2.8
+func (m *Msg) with8BitParts() (*Msg, error) {
+	clone := m.Clone()
+	for _, part := range clone.parts {
+		if part.isDeleted || (part.encoding != EncodingQP && part.encoding != EncodingAuto) ||
+			part.writeFunc == nil {
+			continue
+		}
+		buffer := bytes.Buffer{}
+		if _, err := part.writeFunc(&buffer); err != nil {
+			return nil, fmt.Errorf("failed to render message part: %w", err)
+		}
+		part.writeFunc = writeFuncFromBuffer(&buffer)
+		part.encodedContent = nil
+		if is8BitSafe(buffer.Bytes()) {
+			part.encoding = NoEncoding
+		}
+	}
+	return clone, nil
+}
+
+// withProducedFiles returns a copy of the Msg, in which the content of the attachments that are added via
+// AttachFunc is produced once and held in memory, so that it can be written repeatedly.
+//
+// If the Msg has no such attachments, the Msg itself is returned.
+//
+// Returns:
+//   - A pointer to the Msg or to the copy of the Msg.
+//   - An error if the content of an attachment could not be produced.
+func (m *Msg) withProducedFiles() (*Msg, error) {
+	hasProduced := false
+	for _, file := range m.attachments {
+		hasProduced = hasProduced || (file != nil && file.isProduced)
+	}
+	if !hasProduced {
+		return m, nil
+	}
+	clone := m.Clone()
+	for _, file := range clone.attachments {
+		if file == nil || !file.isProduced || file.Writer == nil {
+			continue
+		}
+		buffer := bytes.Buffer{}
+		if _, err := file.Writer(&buffer); err != nil {
+			return nil, err
+		}
+		file.Writer = writeFuncFromBuffer(&buffer)
+	}
+	return clone, nil
+}
+
+// is8BitSafe reports whether the given content can be transmitted with the 8bit transfer encoding.
+//
+// 8bit data must not contain NUL characters or CR and LF characters other than as line breaks, and its
+// lines must not exceed 998 octets, excluding the line break.
+//
+// Parameters:
+//   - content: The content to check.
+//
+// Returns:
+//   - true if the content is suitable for the 8bit transfer encoding, false otherwise.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-2.8
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.1.1
+func is8BitSafe(content []byte) bool {
+	lineLength := 0
+	for i, char := range content {
+		switch char {
+		case 0:
+			return false
+		case '\r':
+			if i+1 >= len(content) || content[i+1] != '\n' {
+				return false
+			}
+		case '\n':
+			lineLength = 0
+			continue
+		default:
+			lineLength++
+		}
+		if lineLength > 998 {
+			return false
+		}
+	}
+	return true
+}
+
+// selectEncoding returns the Encoding that results in the smallest output for the given content.
+//
+// Content that is suitable for the 8bit transfer encoding and consists of US-ASCII characters only is
+// sent with the 7bit encoding. Otherwise, the quoted-printable encoding, which expands each octet that
+// needs to be escaped to three octets, is selected if less than a sixth of the octets need to be escaped,
+// since the base64 encoding expands the content by a third.
+//
+// Parameters:
+//   - content: The content to select the Encoding for.
+//
+// Returns:
+//   - EncodingUSASCII, EncodingQP or EncodingB64.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.7
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.8
+func selectEncoding(content []byte) Encoding {
+	escaped, ascii := 0, true
+	for _, char := range content {
+		if char > 0x7f {
+			ascii = false
+		}
+		if (char < ' ' && char != '\t' && char != '\r' && char != '\n') || char == '=' || char > '~' {
+			escaped++
+		}
+	}
+	switch {
+	case ascii && is8BitSafe(content):
+		return EncodingUSASCII
+	case escaped*6 < len(content):
+		return EncodingQP
+	default:
+		return EncodingB64
+	}
 }
 
 // ApplyMiddlewares applies the list of middlewares to a Msg.
@@ -2119,6 +3990,74 @@ func (m *Msg) applyMiddlewares(msg *Msg) *Msg {
 	return msg
 }
 
+// MIMEEntity renders the Msg without applying any Middleware and returns the MIME entity formed by its
+// Content-* header fields and its body.
+//
+// Middlewares that wrap the body of a Msg, e.g. to sign or encrypt it, use this method to obtain the MIME
+// entity to protect and SetMIMEEntity to replace the body of the Msg with the wrapped entity. All line
+// breaks of the returned entity are canonicalized to CRLF, so that it can be signed as it is.
+//
+// Returns:
+//   - The canonicalized MIME entity.
+//   - An error if the Msg cannot be rendered.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc1847
+func (m *Msg) MIMEEntity() ([]byte, error) {
+	return renderMIMEEntity(m)
+}
+
+// SetMIMEEntity replaces the body parts, embeds and attachments of the Msg with an already rendered MIME
+// entity when the Msg is written.
+//
+// The entity must include its own Content-* header fields, followed by an empty line and its body, as
+// returned by MIMEEntity. It is meant for middlewares that wrap the body of a Msg, like the SMIMESigner,
+// and should be set on a clone of the Msg passed to the Middleware. The entity is removed by Reset.
+//
+// Parameters:
+//   - entity: The rendered MIME entity that is written as body of the Msg.
+func (m *Msg) SetMIMEEntity(entity []byte) {
+	m.preformBody = entity
+}
+
+// SetWriteError makes writing the Msg fail with the given error.
+//
+// Middlewares use it to fail closed, so that a Msg that cannot be signed or encrypted is never sent
+// unprotected. The body, attachments and embeds of the Msg are dropped, so it should be called on a
+// clone of the Msg passed to the Middleware.
+//
+// Parameters:
+//   - err: The error that writing the Msg returns.
+func (m *Msg) SetWriteError(err error) {
+	failingMsg(m, err)
+}
+
+// failingMsg turns the given Msg into a Msg that fails to render with the given error.
+//
+// Middlewares use it to fail closed, so that a Msg that cannot be signed or encrypted is never sent
+// unprotected. The body, attachments and embeds of the Msg are replaced by a single body part that
+// returns the error when it is written.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to modify, usually a clone of the Msg passed to the Middleware.
+//   - err: The error that writing the Msg returns.
+//
+// Returns:
+//   - A pointer to the modified Msg.
+func failingMsg(msg *Msg, err error) *Msg {
+	msg.attachments = nil
+	msg.embeds = nil
+	msg.preformBody = nil
+	msg.parts = []*Part{{
+		contentType: TypeTextPlain,
+		encoding:    NoEncoding,
+		writeFunc: func(io.Writer) (int64, error) {
+			return 0, err
+		},
+	}}
+	return msg
+}
+
 // WriteTo writes the formatted Msg into the given io.Writer and satisfies the io.WriterTo interface.
 //
 // This method writes the email message, including its headers, body, and attachments, to the provided
@@ -2135,7 +4074,10 @@ func (m *Msg) applyMiddlewares(msg *Msg) *Msg {
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc5322
 func (m *Msg) WriteTo(writer io.Writer) (int64, error) {
-	mw := &msgWriter{writer: writer, charset: m.charset, encoder: m.encoder}
+	mw := &msgWriter{
+		writer: writer, charset: m.charset, encoder: m.encoder, foldColumn: m.headerFoldColumn,
+		maxLineLength: m.maxLineLength,
+	}
 	mw.writeMsg(m.applyMiddlewares(m))
 	return mw.bytesWritten, mw.err
 }
@@ -2167,12 +4109,40 @@ func (m *Msg) WriteToSkipMiddleware(writer io.Writer, middleWareType MiddlewareT
 		middlewares = append(middlewares, m.middlewares[i])
 	}
 	m.middlewares = middlewares
-	mw := &msgWriter{writer: writer, charset: m.charset, encoder: m.encoder}
+	mw := &msgWriter{
+		writer: writer, charset: m.charset, encoder: m.encoder, foldColumn: m.headerFoldColumn,
+		maxLineLength: m.maxLineLength,
+	}
 	mw.writeMsg(m.applyMiddlewares(m))
 	m.middlewares = origMiddlewares
 	return mw.bytesWritten, mw.err
 }
 
+// WriteToWithProgress writes the formatted Msg into the given io.Writer and reports the progress via a callback.
+//
+// This method behaves like WriteTo and produces the same output, but invokes the provided callback with the
+// total number of bytes written so far each time data is written to the io.Writer. This allows to display
+// the progress of writing large messages, e.g. with big attachments. The callback is invoked from the
+// writing goroutine, once per write to the io.Writer, and should therefore return quickly. If the callback
+// is nil, WriteToWithProgress is equivalent to WriteTo.
+//
+// Parameters:
+//   - writer: The io.Writer to which the formatted message will be written.
+//   - progress: The callback that is invoked with the total number of bytes written so far.
+//
+// Returns:
+//   - The total number of bytes written.
+//   - An error if any occurred during the writing process, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322
+func (m *Msg) WriteToWithProgress(writer io.Writer, progress func(written int64)) (int64, error) {
+	if progress == nil {
+		return m.WriteTo(writer)
+	}
+	return m.WriteTo(&progressWriter{progress: progress, writer: writer})
+}
+
 // Write is an alias method to WriteTo for compatibility reasons.
 //
 // This method provides a backward-compatible way to write the formatted Msg to the provided io.Writer
@@ -2192,6 +4162,110 @@ func (m *Msg) Write(writer io.Writer) (int64, error) {
 	return m.WriteTo(writer)
 }
 
+// Bytes returns the formatted Msg as byte slice.
+//
+// This method renders the email message, including headers, body, and attachments, by calling the WriteTo
+// method with an internal buffer. The returned bytes are identical to the message data that is sent to the
+// SMTP server, including the middlewares applied. Since the SMTP DATA command terminates the message data
+// with a CRLF, a final CRLF is appended if the rendered message does not already end with one.
+//
+// Returns:
+//   - A byte slice holding the formatted message.
+//   - An error if rendering the message fails (e.g. due to a template or encoding error), otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322
+func (m *Msg) Bytes() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	if _, err := m.WriteTo(&buffer); err != nil {
+		return nil, fmt.Errorf("failed to write message to buffer: %w", err)
+	}
+	if !bytes.HasSuffix(buffer.Bytes(), newlineBytes) {
+		buffer.WriteString(SingleNewLine)
+	}
+	return buffer.Bytes(), nil
+}
+
+// String returns the formatted Msg as string.
+//
+// This method is a convenience wrapper around Bytes and returns the same message data as string.
+//
+// Returns:
+//   - A string holding the formatted message.
+//   - An error if rendering the message fails (e.g. due to a template or encoding error), otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322
+func (m *Msg) String() (string, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// StringRedacted returns the formatted Msg as string with the values of the given headers redacted.
+//
+// This method renders the message like String, but replaces the value of each of the given headers in
+// the message header with a placeholder, including any folded continuation lines. The header names are
+// matched case-insensitively and the structure of the message is kept intact, so that the output can be
+// shared, e.g. in a support ticket, without disclosing tokens or other secrets that are carried in custom
+// headers. No header is redacted by default. Since only the message header is redacted, the headers of
+// the MIME parts are left untouched.
+//
+// Parameters:
+//   - headers: The headers whose values should be redacted (e.g. "X-Api-Token").
+//
+// Returns:
+//   - A string holding the formatted message with the given headers redacted.
+//   - An error if rendering the message fails (e.g. due to a template or encoding error), otherwise nil.
+func (m *Msg) StringRedacted(headers ...Header) (string, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return "", err
+	}
+	if len(headers) == 0 {
+		return string(data), nil
+	}
+
+	endOfHeader := bytes.Index(data, []byte(SingleNewLine+SingleNewLine))
+	if endOfHeader < 0 {
+		endOfHeader = len(data)
+	}
+	var builder strings.Builder
+	builder.Grow(len(data))
+	redacting := false
+	for _, line := range strings.SplitAfter(string(data[:endOfHeader]), SingleNewLine) {
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if !redacting {
+				builder.WriteString(line)
+			}
+			continue
+		}
+		redacting = false
+		index := strings.IndexByte(line, ':')
+		for _, header := range headers {
+			if index > 0 && strings.EqualFold(line[:index], header.String()) {
+				redacting = true
+				break
+			}
+		}
+		if !redacting {
+			builder.WriteString(line)
+			continue
+		}
+		builder.WriteString(line[:index+1] + " " + redactedHeaderValue)
+		if strings.HasSuffix(line, SingleNewLine) {
+			builder.WriteString(SingleNewLine)
+		}
+	}
+	builder.Write(data[endOfHeader:])
+	return builder.String(), nil
+}
+
 // WriteToFile stores the Msg as a file on disk. It will try to create the given filename,
 // and if the file already exists, it will be overwritten.
 //
@@ -2219,6 +4293,47 @@ func (m *Msg) WriteToFile(name string) error {
 	return file.Close()
 }
 
+// WriteEML writes the Msg in the EML format into the given io.Writer.
+//
+// This method is the counterpart to the EMLToMsg* functions. It renders the message exactly like WriteTo,
+// including the middlewares applied, so that a message that has been imported from an EML, modified and
+// written back keeps its encodings and its multipart structure. Since the MIME boundaries are generated
+// when the message is rendered, they will differ from the imported EML unless a fixed boundary is set via
+// SetBoundary. As the "Bcc" header is never rendered, the Bcc recipients are not part of the output.
+//
+// Parameters:
+//   - writer: The io.Writer to which the EML will be written.
+//
+// Returns:
+//   - An error if rendering or writing the message fails, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322
+func (m *Msg) WriteEML(writer io.Writer) error {
+	if _, err := m.WriteTo(writer); err != nil {
+		return fmt.Errorf("failed to write EML: %w", err)
+	}
+	return nil
+}
+
+// WriteEMLFile stores the Msg as EML file on disk. It will try to create the given file, and if the
+// file already exists, it will be overwritten.
+//
+// This method is an alias for WriteToFile, since WriteEML renders the message exactly like WriteTo. The
+// resulting file can be imported again using EMLToMsgFromFile.
+//
+// Parameters:
+//   - path: The path of the EML file to be created or overwritten.
+//
+// Returns:
+//   - An error if the file cannot be created or if writing to the file fails, otherwise nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322
+func (m *Msg) WriteEMLFile(path string) error {
+	return m.WriteToFile(path)
+}
+
 // WriteToSendmail returns WriteToSendmailWithCommand with a default sendmail path.
 //
 // This method sends the email message using the default sendmail path. It calls WriteToSendmailWithCommand
@@ -2366,6 +4481,17 @@ func (m *Msg) UpdateReader(reader *Reader) {
 	reader.err = err
 }
 
+// HasInvalidDate returns true if the Msg was parsed from an EML with an unparsable Date header.
+//
+// This flag is only set when the EML was parsed using the WithLenientDate option. In that case,
+// the raw value of the Date header is preserved and can be retrieved via GetGenHeader.
+//
+// Returns:
+//   - A boolean value indicating whether the Date header of the parsed EML was invalid.
+func (m *Msg) HasInvalidDate() bool {
+	return m.invalidDate
+}
+
 // HasSendError returns true if the Msg experienced an error during message delivery
 // and the sendError field of the Msg is not nil.
 //
@@ -2503,11 +4629,52 @@ func (m *Msg) hasAlt() bool {
 	return count > 1 && m.pgptype == 0
 }
 
+// alternativeParts returns the parts of the Msg in the order they are written.
+//
+// If the parts are written as multipart/alternative, RFC 2046 requires them to be ordered by
+// increasing faithfulness to the original content, since the receiving client displays the last
+// part it supports. Therefore, the text/plain parts are moved to the front, followed by other parts
+// and the text/html parts, regardless of the order in which they have been added. The text/calendar
+// parts of calendar invites are moved to the very end, since clients like Outlook and Gmail expect
+// them after the text/html part and would otherwise hide the invite behind the HTML body. Parts of
+// the same richness keep their order. Otherwise, the parts are returned in the order they have been
+// added.
+//
+// Returns:
+//   - A slice of the parts of the Msg in the order they are written.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1.4
+func (m *Msg) alternativeParts() []*Part {
+	if !m.hasAlt() {
+		return m.parts
+	}
+	richness := func(part *Part) int {
+		switch {
+		case strings.EqualFold(part.contentType.String(), TypeTextPlain.String()):
+			return 0
+		case strings.EqualFold(part.contentType.String(), TypeTextHTML.String()):
+			return 2
+		case strings.EqualFold(part.contentType.String(), TypeTextCalendar.String()):
+			return 3
+		default:
+			return 1
+		}
+	}
+	parts := make([]*Part, len(m.parts))
+	copy(parts, m.parts)
+	sort.SliceStable(parts, func(i, j int) bool {
+		return richness(parts[i]) < richness(parts[j])
+	})
+	return parts
+}
+
 // hasMixed returns true if the Msg has mixed parts.
 //
 // This method checks whether the message contains mixed content, such as attachments along with
 // message parts (e.g., text or HTML). A message is considered to have mixed parts if there are both
-// attachments and message parts, or if there are multiple attachments.
+// attachments and message parts, or if there are multiple attachments. If the WithForceMultipart option
+// is set, a message that would otherwise be rendered as single-part message has mixed parts as well.
 //
 // Returns:
 //   - A boolean value indicating whether the message has mixed parts.
@@ -2515,7 +4682,14 @@ func (m *Msg) hasAlt() bool {
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1.3
 func (m *Msg) hasMixed() bool {
-	return m.pgptype == 0 && ((len(m.parts) > 0 && len(m.attachments) > 0) || len(m.attachments) > 1)
+	if m.pgptype != 0 {
+		return false
+	}
+	if (len(m.parts) > 0 && len(m.attachments) > 0) || len(m.attachments) > 1 {
+		return true
+	}
+	return m.forceMultipart && !m.hasAlt() && !m.hasRelated() &&
+		(len(m.parts) > 0 || len(m.attachments) > 0 || len(m.embeds) > 0)
 }
 
 // hasRelated returns true if the Msg has related parts.
@@ -2533,6 +4707,33 @@ func (m *Msg) hasRelated() bool {
 	return m.pgptype == 0 && ((len(m.parts) > 0 && len(m.embeds) > 0) || len(m.embeds) > 1)
 }
 
+// relatedRootType returns the media type of the root part of the multipart/related section of the Msg.
+//
+// The root part of the multipart/related section is the first part in it, i.e. the multipart/alternative
+// section if the Msg has alternative parts, the only body part otherwise, or the first embedded file if the
+// Msg has no body parts. Its media type is announced in the "type" parameter of the multipart/related
+// section, which RFC 2387 requires and mail clients like Outlook rely on to display inline images.
+//
+// Returns:
+//   - The media type of the root part, or an empty string if it is unknown.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2387#section-3.1
+func (m *Msg) relatedRootType() string {
+	if m.hasAlt() {
+		return TypeMultipartAlternative.String()
+	}
+	for _, part := range m.parts {
+		if !part.isDeleted {
+			return part.contentType.String()
+		}
+	}
+	if len(m.embeds) > 0 {
+		return m.embeds[0].ContentType.String()
+	}
+	return ""
+}
+
 // hasPGPType returns true if the Msg should be treated as a PGP-encoded message.
 //
 // This method checks whether the message is configured to be treated as a PGP-encoded message by examining
@@ -2597,22 +4798,47 @@ func (m *Msg) setEncoder() {
 //
 // This method ensures that the message includes a User-Agent and X-Mailer header, unless the noDefaultUserAgent
 // flag is set. If neither of these headers is present, a default User-Agent string with the current library
-// version is added.
+// version is added. If the noDefaultXMailer flag is set, the default is only added as User-Agent header.
 //
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.7
 func (m *Msg) checkUserAgent() {
+	m.setDefaultUserAgent(fmt.Sprintf("go-mail v%s // https://github.com/wneessen/go-mail", VERSION))
+}
+
+// setDefaultUserAgent sets the given default User-Agent, if neither a User-Agent nor an X-Mailer header is set.
+//
+// The default is not set if the noDefaultUserAgent flag is set, and it is only set as User-Agent header if the
+// noDefaultXMailer flag is set.
+//
+// Parameters:
+//   - userAgent: The default User-Agent and X-Mailer string.
+func (m *Msg) setDefaultUserAgent(userAgent string) {
 	if m.noDefaultUserAgent {
 		return
 	}
 	_, uaok := m.genHeader[HeaderUserAgent]
 	_, xmok := m.genHeader[HeaderXMailer]
 	if !uaok && !xmok {
-		m.SetUserAgent(fmt.Sprintf("go-mail v%s // https://github.com/wneessen/go-mail",
-			VERSION))
+		m.SetGenHeader(HeaderUserAgent, userAgent)
+		if !m.noDefaultXMailer {
+			m.SetGenHeader(HeaderXMailer, userAgent)
+		}
 	}
 }
 
+// userAgentString returns the User-Agent string for the given product and version.
+//
+// Parameters:
+//   - product: The name of the sending application.
+//   - version: The version of the sending application.
+//
+// Returns:
+//   - The string "product version", or only the product if the version is empty.
+func userAgentString(product, version string) string {
+	return strings.TrimSpace(strings.TrimSpace(product) + " " + strings.TrimSpace(version))
+}
+
 // addDefaultHeader sets default headers if they haven't been set before.
 //
 // This method ensures that essential headers such as "Date", "Message-ID", and "MIME-Version" are set
@@ -2645,15 +4871,23 @@ func (m *Msg) addDefaultHeader() {
 //
 // Returns:
 //   - A pointer to the File structure representing the embedded file.
-//   - An error if the file cannot be opened or read from the embedded filesystem.
+//   - An error if the file cannot be opened from the embedded filesystem or is a directory.
 //
 // References:
 //   - https://datatracker.ietf.org/doc/html/rfc2183
 func fileFromEmbedFS(name string, fs *embed.FS) (*File, error) {
-	_, err := fs.Open(name)
+	file, err := fs.Open(name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file from embed.FS: %w", err)
 	}
+	stat, err := file.Stat()
+	_ = file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file from embed.FS: %w", err)
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("failed to open file from embed.FS: %q is a directory", name)
+	}
 	return &File{
 		Name:   filepath.Base(name),
 		Header: make(map[string][]string),
@@ -2732,7 +4966,53 @@ func fileFromReader(name string, reader io.Reader) (*File, error) {
 	if err != nil {
 		return &File{}, err
 	}
-	byteReader := bytes.NewReader(d)
+	return fileFromBytes(name, d), nil
+}
+
+// fileFromReaderWithSize returns a File pointer from a given io.Reader with a known size.
+//
+// This method reads the content of the io.Reader into a buffer of the given size and creates a File
+// structure from it. If the io.Reader provides more data than the given size, an error is returned.
+//
+// Parameters:
+//   - name: The name of the file to be represented by the reader's content.
+//   - reader: The io.Reader from which the file content will be read.
+//   - size: The maximum size of the content in bytes.
+//
+// Returns:
+//   - A pointer to the File structure representing the content of the io.Reader.
+//   - An error if the size is negative or exceeded or the content cannot be read from the io.Reader.
+func fileFromReaderWithSize(name string, reader io.Reader, size int64) (*File, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidFileSize, size)
+	}
+	data := make([]byte, size)
+	n, err := io.ReadFull(reader, data)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+	if int64(n) == size {
+		probe := make([]byte, 1)
+		if _, err = io.ReadFull(reader, probe); err == nil {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrFileSizeExceeded, size)
+		}
+		if !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read file content: %w", err)
+		}
+	}
+	return fileFromBytes(name, data[:n]), nil
+}
+
+// fileFromBytes returns a File pointer from a given byte slice.
+//
+// Parameters:
+//   - name: The name of the file to be represented by the content.
+//   - data: The content of the file.
+//
+// Returns:
+//   - A pointer to the File structure representing the content.
+func fileFromBytes(name string, data []byte) *File {
+	byteReader := bytes.NewReader(data)
 	return &File{
 		Name:   name,
 		Header: make(map[string][]string),
@@ -2744,14 +5024,14 @@ func fileFromReader(name string, reader io.Reader) (*File, error) {
 			_, copyErr = byteReader.Seek(0, io.SeekStart)
 			return readBytes, copyErr
 		},
-	}, nil
+	}
 }
 
 // fileFromReadSeeker returns a File pointer from a given io.ReadSeeker.
 //
 // This method creates a File structure from an io.ReadSeeker, allowing efficient handling of file content
 // by seeking and reading from the source without fully loading it into memory. The content is written
-// to an io.Writer when needed, and the reader's position is reset to the start after writing.
+// to an io.Writer when needed, and the reader's position is reset to the start before and after writing.
 //
 // Parameters:
 //   - name: The name of the file to be represented by the io.ReadSeeker.
@@ -2767,6 +5047,9 @@ func fileFromReadSeeker(name string, reader io.ReadSeeker) *File {
 		Name:   name,
 		Header: make(map[string][]string),
 		Writer: func(writer io.Writer) (int64, error) {
+			if _, err := reader.Seek(0, io.SeekStart); err != nil {
+				return 0, err
+			}
 			readBytes, err := io.Copy(writer, reader)
 			if err != nil {
 				return readBytes, err
@@ -2777,6 +5060,45 @@ func fileFromReadSeeker(name string, reader io.ReadSeeker) *File {
 	}
 }
 
+// fileFromFunc returns a File pointer, whose content is produced by the given function each time it is
+// written.
+//
+// Parameters:
+//   - name: The name of the file.
+//   - produce: The function that returns an io.Reader providing the file content.
+//
+// Returns:
+//   - A pointer to the File structure representing the produced content.
+func fileFromFunc(name string, produce func() (io.Reader, error)) *File {
+	return &File{
+		Name:   name,
+		Header: make(map[string][]string),
+		Writer: func(writer io.Writer) (int64, error) {
+			if produce == nil {
+				return 0, fmt.Errorf("failed to produce content of file %q: no produce function", name)
+			}
+			reader, err := produce()
+			if err != nil {
+				return 0, fmt.Errorf("failed to produce content of file %q: %w", name, err)
+			}
+			if closer, ok := reader.(io.Closer); ok {
+				defer func() {
+					_ = closer.Close()
+				}()
+			}
+			if reader == nil {
+				return 0, nil
+			}
+			readBytes, err := io.Copy(writer, reader)
+			if err != nil {
+				return readBytes, fmt.Errorf("failed to read produced content of file %q: %w", name, err)
+			}
+			return readBytes, nil
+		},
+		isProduced: true,
+	}
+}
+
 // fileFromHTMLTemplate returns a File pointer from a given html/template.Template.
 //
 // This method executes the provided HTML template with the given data and creates a File structure
@@ -2857,6 +5179,34 @@ func getEncoder(enc Encoding) mime.WordEncoder {
 	}
 }
 
+// cloneFiles returns a copy of the given list of File pointers.
+//
+// Each File and its MIME header are copied, so that changes to the copied Files do not affect the
+// original Files. The content writer of each File is shared.
+//
+// Parameters:
+//   - files: The list of File pointers to copy.
+//
+// Returns:
+//   - A new list of File pointers, or nil if files is nil.
+func cloneFiles(files []*File) []*File {
+	if files == nil {
+		return nil
+	}
+	clones := make([]*File, len(files))
+	for i, file := range files {
+		fileCopy := *file
+		if file.Header != nil {
+			fileCopy.Header = make(textproto.MIMEHeader, len(file.Header))
+			for key, values := range file.Header {
+				fileCopy.Header[key] = append([]string(nil), values...)
+			}
+		}
+		clones[i] = &fileCopy
+	}
+	return clones
+}
+
 // writeFuncFromBuffer converts a byte buffer into a writeFunc, which is commonly required by go-mail.
 //
 // This function wraps a byte buffer into a write function that can be used to write the buffer's content
@@ -2879,3 +5229,256 @@ func writeFuncFromBuffer(buffer *bytes.Buffer) func(io.Writer) (int64, error) {
 	}
 	return writeFunc
 }
+
+// decodeCharsetToUTF8 converts the given content from the given charset to UTF-8.
+//
+// Parameters:
+//   - content: The content to convert.
+//   - charset: The Charset of the content. Quotes around the charset name are ignored.
+//
+// Returns:
+//   - The UTF-8 encoded content.
+//   - An error if the charset is not supported (ErrUnsupportedCharset).
+func decodeCharsetToUTF8(content []byte, charset Charset) ([]byte, error) {
+	reader, err := newUTF8Reader(bytes.NewReader(content), charset)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := reader.(*bytes.Reader); ok {
+		return content, nil
+	}
+	return io.ReadAll(reader)
+}
+
+// newUTF8Reader returns an io.Reader that converts the content read from the given io.Reader from the
+// given charset to UTF-8.
+//
+// The conversion is done by DefaultCharsetReader, so that all charsets of the WHATWG Encoding Standard
+// are supported.
+//
+// Parameters:
+//   - input: The io.Reader providing the content.
+//   - charset: The Charset of the content. Quotes around the charset name are ignored.
+//
+// Returns:
+//   - An io.Reader that converts the content to UTF-8. For UTF-8 and US-ASCII, this is the given io.Reader.
+//   - An error if the charset is not supported (ErrUnsupportedCharset).
+func newUTF8Reader(input io.Reader, charset Charset) (io.Reader, error) {
+	name := strings.Trim(strings.TrimSpace(charset.String()), `"`)
+	switch {
+	case name == "", strings.EqualFold(name, CharsetUTF8.String()), strings.EqualFold(name, "utf8"),
+		strings.EqualFold(name, CharsetASCII.String()), strings.EqualFold(name, "ascii"):
+		return input, nil
+	default:
+		return DefaultCharsetReader(name, input)
+	}
+}
+
+// randomContentID generates a unique Content-ID for an embedded file.
+//
+// The generated Content-ID follows the format "<randomString@hostname>". If the hostname cannot be
+// retrieved, it defaults to "localhost.localdomain".
+//
+// Returns:
+//   - The generated Content-ID, enclosed in angle brackets.
+//   - An error if the random string could not be generated.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2392
+func randomContentID() (string, error) {
+	randString, err := randomStringSecure(24)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate Content-ID: %w", err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost.localdomain"
+	}
+	return fmt.Sprintf("<%s@%s>", randString, hostname), nil
+}
+
+// normalizeMessageID validates the given message identifier and encloses it in angle brackets.
+//
+// Parameters:
+//   - messageID: The message identifier, with or without angle brackets.
+//
+// Returns:
+//   - The message identifier enclosed in angle brackets.
+//   - An error wrapping ErrInvalidMessageID if the message identifier does not conform to the msg-id syntax.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func normalizeMessageID(messageID string) (string, error) {
+	messageID = strings.TrimSpace(messageID)
+	if !isValidContentID(messageID) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidMessageID, messageID)
+	}
+	return "<" + strings.TrimSuffix(strings.TrimPrefix(messageID, "<"), ">") + ">", nil
+}
+
+// containsString reports whether the given slice of strings contains the given value.
+//
+// Parameters:
+//   - values: The slice of strings to search.
+//   - value: The string to search for.
+//
+// Returns:
+//   - true if the value is part of the slice, false otherwise.
+func containsString(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
+
+// importanceFromHeader maps the value of an importance header to an Importance level.
+//
+// Parameters:
+//   - header: The importance header the value belongs to, i.e. "Importance", "X-Priority" or "Priority".
+//   - value: The value of the header.
+//
+// Returns:
+//   - The Importance level of the header value.
+//   - An error if the header holds an unknown value (ErrInvalidImportance).
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2156
+func importanceFromHeader(header Header, value string) (Importance, error) {
+	importance := strings.ToLower(strings.TrimSpace(value))
+	switch header {
+	case HeaderImportance:
+		for _, level := range []Importance{
+			ImportanceNonUrgent, ImportanceLow, ImportanceHigh, ImportanceUrgent,
+		} {
+			if importance == level.String() {
+				return level, nil
+			}
+		}
+		if importance == "normal" {
+			return ImportanceNormal, nil
+		}
+	case HeaderXPriority:
+		// The X-Priority value might be followed by a comment, e.g. "1 (Highest)"
+		if index := strings.IndexAny(importance, " ("); index > 0 {
+			importance = importance[:index]
+		}
+		switch importance {
+		case "1", "2":
+			return ImportanceHigh, nil
+		case "3":
+			return ImportanceNormal, nil
+		case "4", "5":
+			return ImportanceLow, nil
+		}
+	case HeaderPriority:
+		switch importance {
+		case ImportanceUrgent.String():
+			return ImportanceUrgent, nil
+		case "normal":
+			return ImportanceNormal, nil
+		case ImportanceNonUrgent.String():
+			return ImportanceNonUrgent, nil
+		case ImportanceHigh.NumString():
+			return ImportanceHigh, nil
+		case ImportanceLow.NumString():
+			return ImportanceLow, nil
+		}
+	}
+	return ImportanceNormal, fmt.Errorf("%w: %s: %q", ErrInvalidImportance, header, value)
+}
+
+// addrHeaderByName returns the AddrHeader whose name matches the given generic header.
+//
+// The header names are compared case-insensitively. The envelope from address is not a header field of
+// the Msg and is therefore not matched.
+//
+// Parameters:
+//   - header: The generic header to match.
+//
+// Returns:
+//   - The matching AddrHeader.
+//   - A boolean indicating whether a matching AddrHeader was found.
+func addrHeaderByName(header Header) (AddrHeader, bool) {
+	for _, addrHeader := range []AddrHeader{HeaderFrom, HeaderSender, HeaderTo, HeaderCc, HeaderBcc} {
+		if strings.EqualFold(header.String(), addrHeader.String()) {
+			return addrHeader, true
+		}
+	}
+	return "", false
+}
+
+// addressList returns the addresses of the given header of the Msg.
+//
+// For the address headers, like "From" or "To", the addresses set via SetAddrHeader are returned first.
+// They are copied, so that the Msg cannot be modified through them. Values of the header that have been
+// set as raw value via SetGenHeader or SetGenHeaderPreformatted are parsed as address list, decoding
+// RFC 2047 encoded display names with DefaultCharsetReader and resolving groups to their members.
+//
+// Parameters:
+//   - header: The header whose addresses are returned.
+//
+// Returns:
+//   - A slice of `*mail.Address` containing the addresses of the header, or nil if the header is not set.
+//   - An error if a raw value of the header cannot be parsed as address list.
+func (m *Msg) addressList(header Header) ([]*mail.Address, error) {
+	var addresses []*mail.Address
+	if addrHeader, ok := addrHeaderByName(header); ok {
+		for _, address := range m.addrHeader[addrHeader] {
+			if address == nil {
+				continue
+			}
+			addressCopy := *address
+			addresses = append(addresses, &addressCopy)
+		}
+	}
+	values := append([]string(nil), m.genHeader[header]...)
+	if value, ok := m.preformHeader[header]; ok {
+		values = append(values, value)
+	}
+	parser := mail.AddressParser{WordDecoder: &mime.WordDecoder{CharsetReader: DefaultCharsetReader}}
+	for _, value := range values {
+		parsed, err := parser.ParseList(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s address list: %w", strings.ToLower(header.String()), err)
+		}
+		addresses = append(addresses, parsed...)
+	}
+	return addresses, nil
+}
+
+// setReceiptHeader sets the given receipt header of the Msg to the already formatted addresses.
+//
+// The addresses are stored without further encoding, since mail.Address.String already RFC 2047 encodes
+// non-ASCII display names.
+//
+// Parameters:
+//   - header: The receipt header to set, e.g. "Disposition-Notification-To".
+//   - addresses: The formatted addresses of the header.
+func (m *Msg) setReceiptHeader(header Header, addresses []string) {
+	if m.genHeader == nil {
+		m.genHeader = make(map[Header][]string)
+	}
+	m.genHeader[header] = addresses
+}
+
+// getReceiptHeader parses the addresses of the given receipt header of the Msg.
+//
+// Parameters:
+//   - header: The receipt header to parse, e.g. "Disposition-Notification-To".
+//
+// Returns:
+//   - A slice of the parsed addresses, or nil if the header is not set.
+//   - An error if a header value cannot be parsed as an address list.
+func (m *Msg) getReceiptHeader(header Header) ([]*mail.Address, error) {
+	var addresses []*mail.Address
+	for _, value := range m.genHeader[header] {
+		parsed, err := mail.ParseAddressList(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s address list: %w", header, err)
+		}
+		addresses = append(addresses, parsed...)
+	}
+	return addresses, nil
+}
@@ -8,17 +8,22 @@ import (
 	"bufio"
 	"bytes"
 	"embed"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	htpl "html/template"
 	"io"
+	"mime"
 	"net/mail"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
+	"testing/iotest"
 	ttpl "text/template"
 	"time"
+	"unicode/utf8"
 )
 
 //go:embed README.md
@@ -100,6 +105,39 @@ func TestNewMsgCharset(t *testing.T) {
 	}
 }
 
+// TestMsg_SetCharset_parts tests that the charset set via Msg.SetCharset is the default for all parts
+// added afterward and is rendered in their Content-Type header
+func TestMsg_SetCharset_parts(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "Added before")
+	m.SetCharset(CharsetISO88591)
+	m.AddAlternativeString(TypeTextHTML, "<p>Added after</p>")
+	m.AddAlternativeString(TypeTextPlain, "Overridden", WithPartCharset(CharsetWindows1252))
+	parts := m.GetParts()
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got: %d", len(parts))
+	}
+	for i, want := range []Charset{CharsetUTF8, CharsetISO88591, CharsetWindows1252} {
+		if parts[i].GetCharset() != want {
+			t.Errorf("SetCharset() failed. Expected charset of part %d: %s, got: %s", i, want,
+				parts[i].GetCharset())
+		}
+	}
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() failed: %s", err)
+	}
+	for _, want := range []string{
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"Content-Type: text/html; charset=ISO-8859-1\r\n",
+		"Content-Type: text/plain; charset=windows-1252\r\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("SetCharset() failed. Expected output to contain %q, got: %s", want, buf.String())
+		}
+	}
+}
+
 // TestNewMsgWithCharset tests WithEncoding and Msg.SetEncoding
 func TestNewMsgWithEncoding(t *testing.T) {
 	tests := []struct {
@@ -110,6 +148,8 @@ func TestNewMsgWithEncoding(t *testing.T) {
 		{"encoding is Quoted-Printable", EncodingQP, "quoted-printable"},
 		{"encoding is Base64", EncodingB64, "base64"},
 		{"encoding is Unencoded 8-Bit", NoEncoding, "8bit"},
+		{"encoding is auto", EncodingAuto, "auto"},
+		{"invalid encoding is ignored", "binary", "quoted-printable"},
 	}
 
 	for _, tt := range tests {
@@ -126,6 +166,59 @@ func TestNewMsgWithEncoding(t *testing.T) {
 			if m.encoding != tt.want {
 				t.Errorf("SetEncoding() failed. Expected: %s, got: %s", tt.want, m.encoding)
 			}
+			if err := m.SetEncoding("binary"); !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("SetEncoding() with invalid encoding expected error: %s, got: %v", ErrInvalidEncoding, err)
+			}
+			if m.encoding != tt.want {
+				t.Errorf("SetEncoding() with invalid encoding changed the encoding to: %s", m.encoding)
+			}
+		})
+	}
+}
+
+// TestMsg_EncodingAuto tests that EncodingAuto selects the encoding of each body part by its content
+func TestMsg_EncodingAuto(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Encoding
+	}{
+		{"US-ASCII text", "Hello World\r\nThis is a test mail", EncodingUSASCII},
+		{"mostly US-ASCII text", "Grüße aus Köln,\r\nthis is mostly US-ASCII text", EncodingQP},
+		{"long US-ASCII line", strings.Repeat("a", 1000), EncodingQP},
+		{"non-latin text", "こんにちは世界、これはテストメールです", EncodingB64},
+		{"binary content", "\x00\x01\x02\xff\xfe\x80binary", EncodingB64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg(WithEncoding(EncodingAuto))
+			m.SetBodyString(TypeTextPlain, tt.content)
+			m.AddAlternativeString(TypeTextHTML, "<p>Hello World</p>", WithPartEncoding(EncodingB64))
+			buf := bytes.Buffer{}
+			if _, err := m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			parsed, err := EMLToMsgFromReader(&buf)
+			if err != nil {
+				t.Fatalf("failed to parse message: %s", err)
+			}
+			parts := parsed.GetParts()
+			if len(parts) != 2 {
+				t.Fatalf("expected 2 parts, got: %d", len(parts))
+			}
+			if parts[0].GetEncoding() != tt.want {
+				t.Errorf("EncodingAuto expected encoding: %s, got: %s", tt.want, parts[0].GetEncoding())
+			}
+			if parts[1].GetEncoding() != EncodingB64 {
+				t.Errorf("explicit encoding expected: %s, got: %s", EncodingB64, parts[1].GetEncoding())
+			}
+			content, err := parts[0].GetContent()
+			if err != nil {
+				t.Fatalf("failed to get part content: %s", err)
+			}
+			if string(content) != tt.content {
+				t.Errorf("EncodingAuto content mismatch. Want: %q, got: %q", tt.content, content)
+			}
 		})
 	}
 }
@@ -179,6 +272,54 @@ func TestNewMsgWithBoundary(t *testing.T) {
 	}
 }
 
+// TestNewMsgWithBoundaryGenerator tests WithBoundaryGenerator and Msg.SetBoundaryGenerator
+func TestNewMsgWithBoundaryGenerator(t *testing.T) {
+	calls := 0
+	generator := func() string {
+		calls++
+		return "deterministic"
+	}
+	render := func(m *Msg) string {
+		t.Helper()
+		buf := bytes.Buffer{}
+		if _, err := m.WriteTo(&buf); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+		return buf.String()
+	}
+	m := NewMsg(WithBoundaryGenerator(generator))
+	m.SetDateWithValue(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	m.SetMessageIDWithValue("message.id")
+	m.SetBodyString(TypeTextPlain, "plain")
+	m.AddAlternativeString(TypeTextHTML, "<p>html</p>")
+	if err := m.AttachReader("file.txt", strings.NewReader("attachment")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+
+	first, second := render(m), render(m)
+	if first != second {
+		t.Errorf("WithBoundaryGenerator() failed. Expected identical output, got:\n%s\n\n%s", first, second)
+	}
+	if calls != 2 {
+		t.Errorf("WithBoundaryGenerator() failed. Expected generator to be called 2 times, got: %d", calls)
+	}
+	for _, want := range []string{"boundary=deterministic\r\n", "boundary=1_deterministic\r\n", "--deterministic--"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("WithBoundaryGenerator() failed. Expected %q in output, got: %s", want, first)
+		}
+	}
+
+	m.SetBoundary("fixed")
+	if output := render(m); !strings.Contains(output, "boundary=fixed\r\n") || calls != 2 {
+		t.Errorf("SetBoundary() was expected to take precedence over the boundary generator, got: %s", output)
+	}
+	m.SetBoundary("")
+	m.SetBoundaryGenerator(nil)
+	if output := render(m); strings.Contains(output, "deterministic") {
+		t.Errorf("SetBoundaryGenerator(nil) was expected to restore random boundaries, got: %s", output)
+	}
+}
+
 // TestNewMsg_WithPGPType tests WithPGPType option
 func TestNewMsg_WithPGPType(t *testing.T) {
 	tests := []struct {
@@ -507,6 +648,49 @@ func TestMsg_EnvelopeFrom(t *testing.T) {
 	}
 }
 
+// TestMsg_SetEnvelopeFrom tests the Msg.SetEnvelopeFrom method, including the null reverse-path
+func TestMsg_SetEnvelopeFrom(t *testing.T) {
+	m := NewMsg()
+	if err := m.SetEnvelopeFrom("invalid"); err == nil {
+		t.Errorf("SetEnvelopeFrom with invalid address expected to fail")
+	}
+	if err := m.SetEnvelopeFrom("bounces@example.com"); err != nil {
+		t.Fatalf("SetEnvelopeFrom failed: %s", err)
+	}
+	if sender, err := m.GetSender(false); err != nil || sender != "bounces@example.com" {
+		t.Errorf("GetSender expected: bounces@example.com, got: %q, %v", sender, err)
+	}
+	for _, address := range []string{"<>", ""} {
+		if err := m.SetEnvelopeFrom(address); err != nil {
+			t.Fatalf("SetEnvelopeFrom(%q) failed: %s", address, err)
+		}
+		if sender, err := m.GetSender(false); err != nil || sender != "" {
+			t.Errorf("GetSender with null reverse-path expected empty sender, got: %q, %v", sender, err)
+		}
+	}
+	if err := m.To("toni.tester@example.com"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	m.SetBodyString(TypeTextPlain, "Test body")
+	if err := m.Validate(); !errors.Is(err, ErrNoFromAddress) {
+		t.Errorf("Validate with null reverse-path and without FROM expected error: %s, got: %v",
+			ErrNoFromAddress, err)
+	}
+	if err := m.From("newsletter@example.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate with null reverse-path failed: %s", err)
+	}
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buf.String(), "\r\nFrom: <newsletter@example.com>\r\n") {
+		t.Errorf("expected FROM header to be kept, got: %s", buf.String())
+	}
+}
+
 // TestMsg_AddToFormat tests the Msg.AddToFormat method
 func TestMsg_AddToFormat(t *testing.T) {
 	a := []string{"address1@example.com", "address2@example.com"}
@@ -534,6 +718,54 @@ func TestMsg_AddToFormat(t *testing.T) {
 	}
 }
 
+// TestMsg_AddToNamed tests the Msg.AddToNamed method
+func TestMsg_AddToNamed(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{"Toni Tester", "toni@example.com", false},
+		{"Tester, Toni", "toni@example.com", false},
+		{`Toni "The Tester" Tester`, "toni@example.com", false},
+		{`Back\slash`, "toni@example.com", false},
+		{"Tëster, Tönï", "toni@example.com", false},
+		{"Toni\r\nBcc: injected@example.com", "toni@example.com", false},
+		{"", "toni@example.com", false},
+		{"Toni Tester", "invalid", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := m.AddTo("first@example.com"); err != nil {
+				t.Fatalf("failed to set TO address: %s", err)
+			}
+			err := m.AddToNamed(tt.name, tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddToNamed() failed. Expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			addresses := m.GetTo()
+			if len(addresses) != 2 {
+				t.Fatalf("AddToNamed() failed. Expected 2 addresses, got: %d", len(addresses))
+			}
+			if addresses[1].Name != tt.name || addresses[1].Address != tt.addr {
+				t.Errorf("AddToNamed() failed. Expected name %q and address %q, got: %q and %q", tt.name,
+					tt.addr, addresses[1].Name, addresses[1].Address)
+			}
+			buf := bytes.Buffer{}
+			if _, err = m.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() failed: %s", err)
+			}
+			if strings.Contains(buf.String(), "Bcc: injected") {
+				t.Errorf("AddToNamed() failed. Display name injected a header: %s", buf.String())
+			}
+		})
+	}
+}
+
 // TestMsg_ToIgnoreInvalid tests the Msg.ToIgnoreInvalid method
 func TestMsg_ToIgnoreInvalid(t *testing.T) {
 	a := []string{"address1@example.com", "address2@example.com"}
@@ -712,6 +944,36 @@ func TestMsg_SetBulk(t *testing.T) {
 		t.Errorf("SetBulk() failed. Expected X-Auto-Response-Suppress header: %q, got: %q", "All",
 			m.genHeader[HeaderXAutoResponseSuppress][0])
 	}
+	if auto := m.GetGenHeader(HeaderAutoSubmitted); len(auto) != 1 || auto[0] != "auto-generated" {
+		t.Errorf("SetBulk() failed. Expected Auto-Submitted header: %q, got: %q", "auto-generated", auto)
+	}
+}
+
+// TestMsg_SetAutoSubmitted tests the Msg.SetAutoSubmitted method and its combination with Msg.SetBulk
+func TestMsg_SetAutoSubmitted(t *testing.T) {
+	tests := []struct {
+		name  string
+		value AutoSubmitted
+		want  string
+	}{
+		{"no", AutoSubmittedNo, "no"},
+		{"auto-generated", AutoSubmittedAutoGenerated, "auto-generated"},
+		{"auto-replied", AutoSubmittedAutoReplied, "auto-replied"},
+		{"auto-notified", AutoSubmittedAutoNotified, "auto-notified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			m.SetBulk()
+			m.SetAutoSubmitted(tt.value)
+			if auto := m.GetGenHeader(HeaderAutoSubmitted); len(auto) != 1 || auto[0] != tt.want {
+				t.Errorf("SetAutoSubmitted() failed. Expected Auto-Submitted header: %q, got: %q", tt.want, auto)
+			}
+			if precedence := m.GetGenHeader(HeaderPrecedence); len(precedence) != 1 || precedence[0] != "bulk" {
+				t.Errorf("SetAutoSubmitted() failed. Expected Precedence header to be kept, got: %q", precedence)
+			}
+		})
+	}
 }
 
 // TestMsg_SetDate tests the Msg.SetDate and Msg.SetDateWithValue method
@@ -755,6 +1017,38 @@ func TestMsg_SetDate(t *testing.T) {
 	}
 }
 
+// TestMsg_GetDate tests the Msg.GetDate method
+func TestMsg_GetDate(t *testing.T) {
+	m := NewMsg()
+	if _, err := m.GetDate(); !errors.Is(err, ErrNoDateHeader) {
+		t.Errorf("GetDate() failed. Expected error: %s, got: %v", ErrNoDateHeader, err)
+	}
+
+	zone := time.FixedZone("UTC-0930", -(9*3600 + 30*60))
+	want := time.Date(2023, time.November, 1, 12, 30, 15, 0, zone)
+	m.SetDateWithValue(time.Now())
+	m.SetDateWithValue(want)
+	if len(m.genHeader[HeaderDate]) != 1 {
+		t.Errorf("SetDateWithValue() failed. Expected 1 Date header, got: %d", len(m.genHeader[HeaderDate]))
+	}
+	got, err := m.GetDate()
+	if err != nil {
+		t.Fatalf("GetDate() failed: %s", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("GetDate() failed. Expected time: %s, got: %s", want, got)
+	}
+	_, wantOffset := want.Zone()
+	if _, offset := got.Zone(); offset != wantOffset {
+		t.Errorf("GetDate() failed. Expected timezone offset: %d, got: %d", wantOffset, offset)
+	}
+
+	m.SetGenHeader(HeaderDate, "invalid date")
+	if _, err = m.GetDate(); err == nil {
+		t.Error("GetDate() with invalid Date header was supposed to fail, but didn't")
+	}
+}
+
 // TestMsg_SetMessageIDWIthValue tests the Msg.SetMessageIDWithValue and Msg.SetMessageID methods
 func TestMsg_SetMessageIDWithValue(t *testing.T) {
 	m := NewMsg()
@@ -781,6 +1075,78 @@ func TestMsg_SetMessageIDWithValue(t *testing.T) {
 		t.Errorf("SetMessageIDWithValue() failed. Expected: %s, got: %s", vf, m.genHeader[HeaderMessageID][0])
 		return
 	}
+	m.SetMessageIDWithValue(vf)
+	if m.genHeader[HeaderMessageID][0] != vf {
+		t.Errorf("SetMessageIDWithValue() with brackets failed. Expected: %s, got: %s", vf,
+			m.genHeader[HeaderMessageID][0])
+	}
+}
+
+// TestMsg_SetInReplyTo tests the Msg.SetInReplyTo and Msg.AddReferences methods
+func TestMsg_SetInReplyTo(t *testing.T) {
+	m := NewMsg()
+	if err := m.SetInReplyTo(" 1234.5678@example.com "); err != nil {
+		t.Fatalf("SetInReplyTo() failed: %s", err)
+	}
+	if got := m.GetGenHeader(HeaderInReplyTo); len(got) != 1 || got[0] != "<1234.5678@example.com>" {
+		t.Errorf("SetInReplyTo() failed. Expected: %q, got: %q", "<1234.5678@example.com>", got)
+	}
+	if err := m.AddReferences("<first@example.com>", "second@example.com"); err != nil {
+		t.Fatalf("AddReferences() failed: %s", err)
+	}
+	if err := m.AddReferences("second@example.com", "<third@example.com>"); err != nil {
+		t.Fatalf("AddReferences() failed: %s", err)
+	}
+	want := "<first@example.com> <second@example.com> <third@example.com>"
+	if got := m.GetGenHeader(HeaderReferences); len(got) != 1 || got[0] != want {
+		t.Errorf("AddReferences() failed. Expected: %q, got: %q", want, got)
+	}
+
+	for _, messageID := range []string{"", "<>", "no-at-sign", "<open@example.com", "two words@example.com",
+		"@example.com", "local@"} {
+		if err := m.SetInReplyTo(messageID); !errors.Is(err, ErrInvalidMessageID) {
+			t.Errorf("SetInReplyTo(%q) expected error: %s, got: %v", messageID, ErrInvalidMessageID, err)
+		}
+		if err := m.AddReferences("fourth@example.com", messageID); !errors.Is(err, ErrInvalidMessageID) {
+			t.Errorf("AddReferences(%q) expected error: %s, got: %v", messageID, ErrInvalidMessageID, err)
+		}
+	}
+	if got := m.GetGenHeader(HeaderInReplyTo); len(got) != 1 || got[0] != "<1234.5678@example.com>" {
+		t.Errorf("SetInReplyTo() with invalid message identifier expected header to be unchanged, got: %q", got)
+	}
+	if got := m.GetGenHeader(HeaderReferences); len(got) != 1 || got[0] != want {
+		t.Errorf("AddReferences() with invalid message identifier expected header to be unchanged, got: %q", got)
+	}
+}
+
+// TestMsg_SetInReplyToMsg tests that Msg.SetInReplyToMsg threads a reply to an imported message
+func TestMsg_SetInReplyToMsg(t *testing.T) {
+	original, err := EMLToMsgFromString("From: Toni Tester <tester@example.com>\r\n" +
+		"To: Tina Tester <tina@example.com>\r\nSubject: Re: Planning\r\n" +
+		"Message-ID: <second@example.com>\r\nIn-Reply-To: <first@example.com>\r\n" +
+		"References: <first@example.com>\r\n invalid-reference\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\nSee you there.\r\n")
+	if err != nil {
+		t.Fatalf("EMLToMsgFromString() failed: %s", err)
+	}
+	reply := NewMsg()
+	if err = reply.SetInReplyToMsg(original); err != nil {
+		t.Fatalf("SetInReplyToMsg() failed: %s", err)
+	}
+	if got := reply.GetGenHeader(HeaderInReplyTo); len(got) != 1 || got[0] != "<second@example.com>" {
+		t.Errorf("SetInReplyToMsg() failed. Expected In-Reply-To: %q, got: %q", "<second@example.com>", got)
+	}
+	want := "<first@example.com> <second@example.com>"
+	if got := reply.GetGenHeader(HeaderReferences); len(got) != 1 || got[0] != want {
+		t.Errorf("SetInReplyToMsg() failed. Expected References: %q, got: %q", want, got)
+	}
+
+	if err = reply.SetInReplyToMsg(NewMsg()); !errors.Is(err, ErrInvalidMessageID) {
+		t.Errorf("SetInReplyToMsg() without Message-ID expected error: %s, got: %v", ErrInvalidMessageID, err)
+	}
+	if err = reply.SetInReplyToMsg(nil); !errors.Is(err, ErrInvalidMessageID) {
+		t.Errorf("SetInReplyToMsg() with nil message expected error: %s, got: %v", ErrInvalidMessageID, err)
+	}
 }
 
 // TestMsg_SetMessageIDRandomness tests the randomness of Msg.SetMessageID methods
@@ -818,6 +1184,81 @@ func TestMsg_GetMessageID(t *testing.T) {
 	}
 }
 
+// TestMsg_AddFrom tests the Msg.AddFrom method for single and multiple FROM addresses
+func TestMsg_AddFrom(t *testing.T) {
+	t.Run("single FROM address", func(t *testing.T) {
+		m := NewMsg()
+		if err := m.AddFrom("author@example.com"); err != nil {
+			t.Fatalf("AddFrom() failed: %s", err)
+		}
+		if err := m.To("rcpt@example.com"); err != nil {
+			t.Fatalf("failed to set TO address: %s", err)
+		}
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate() failed for a single FROM address without Sender: %s", err)
+		}
+		sender, err := m.GetSender(false)
+		if err != nil {
+			t.Fatalf("GetSender() failed: %s", err)
+		}
+		if sender != "author@example.com" {
+			t.Errorf("GetSender() failed. Expected: author@example.com, got: %s", sender)
+		}
+	})
+	t.Run("multiple FROM addresses", func(t *testing.T) {
+		m := NewMsg()
+		for _, from := range []string{"author@example.com", `"Co Author" <co-author@example.com>`} {
+			if err := m.AddFrom(from); err != nil {
+				t.Fatalf("AddFrom() failed: %s", err)
+			}
+		}
+		if err := m.To("rcpt@example.com"); err != nil {
+			t.Fatalf("failed to set TO address: %s", err)
+		}
+		if got := m.GetFromString(); len(got) != 2 {
+			t.Fatalf("AddFrom() failed. Expected 2 FROM addresses, got: %v", got)
+		}
+		if err := m.Validate(); !errors.Is(err, ErrNoSenderAddress) {
+			t.Errorf("Validate() expected error: %s, got: %v", ErrNoSenderAddress, err)
+		}
+
+		if err := m.SetSender("robot@example.com"); err != nil {
+			t.Fatalf("SetSender() failed: %s", err)
+		}
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate() failed for multiple FROM addresses with Sender: %s", err)
+		}
+		sender, err := m.GetSender(false)
+		if err != nil {
+			t.Fatalf("GetSender() failed: %s", err)
+		}
+		if sender != "robot@example.com" {
+			t.Errorf("GetSender() failed. Expected envelope from to be the Sender: robot@example.com, got: %s",
+				sender)
+		}
+		buf := bytes.Buffer{}
+		if _, err = m.WriteTo(&buf); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+		want := "From: <author@example.com>, \"Co Author\" <co-author@example.com>\r\n"
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("AddFrom() failed. Expected header %q in message: %s", want, buf.String())
+		}
+		if !strings.Contains(buf.String(), "Sender: <robot@example.com>\r\n") {
+			t.Errorf("AddFrom() failed. Sender header not found in message: %s", buf.String())
+		}
+	})
+	t.Run("invalid address", func(t *testing.T) {
+		m := NewMsg()
+		if err := m.AddFrom("invalid"); err == nil {
+			t.Error("AddFrom() with invalid address expected error, got nil")
+		}
+		if len(m.GetFrom()) != 0 {
+			t.Errorf("AddFrom() with invalid address expected no FROM address, got: %v", m.GetFrom())
+		}
+	})
+}
+
 // TestMsg_FromFormat tests the FromFormat and EnvelopeFrom methods for the Msg object
 func TestMsg_FromFormat(t *testing.T) {
 	tests := []struct {
@@ -997,6 +1438,147 @@ func TestMsg_ReplyTo(t *testing.T) {
 	}
 }
 
+// TestMsg_GetReplyTo tests the Msg.GetReplyTo method
+func TestMsg_GetReplyTo(t *testing.T) {
+	m := NewMsg()
+	addresses, err := m.GetReplyTo()
+	if err != nil || len(addresses) != 0 {
+		t.Errorf("GetReplyTo() without Reply-To header failed. Expected no addresses, got: %v, %v", addresses, err)
+	}
+	if err = m.ReplyToFormat("Tëster, Toni", "tester@example.com"); err != nil {
+		t.Fatalf("failed to set Reply-To address: %s", err)
+	}
+	addresses, err = m.GetReplyTo()
+	if err != nil {
+		t.Fatalf("GetReplyTo() failed: %s", err)
+	}
+	if len(addresses) != 1 || addresses[0].Name != "Tëster, Toni" || addresses[0].Address != "tester@example.com" {
+		t.Errorf("GetReplyTo() failed. Unexpected addresses: %v", addresses)
+	}
+	m.SetGenHeader(HeaderReplyTo, "invalid address")
+	if _, err = m.GetReplyTo(); err == nil {
+		t.Errorf("GetReplyTo() with invalid Reply-To header was supposed to fail, but didn't")
+	}
+}
+
+// TestMsg_GetAddresses tests the GetFromAddresses, GetToAddresses, GetCcAddresses and GetBccAddresses methods
+func TestMsg_GetAddresses(t *testing.T) {
+	getters := []struct {
+		header AddrHeader
+		get    func(*Msg) ([]*mail.Address, error)
+	}{
+		{HeaderFrom, (*Msg).GetFromAddresses},
+		{HeaderTo, (*Msg).GetToAddresses},
+		{HeaderCc, (*Msg).GetCcAddresses},
+		{HeaderBcc, (*Msg).GetBccAddresses},
+	}
+	tests := []struct {
+		name    string
+		raw     string
+		want    []mail.Address
+		wantErr bool
+	}{
+		{
+			"comma in quoted name", `"Tester, Toni" <toni@example.com>, tina@example.com`,
+			[]mail.Address{{Name: "Tester, Toni", Address: "toni@example.com"}, {Address: "tina@example.com"}},
+			false,
+		},
+		{
+			"encoded words", `=?UTF-8?q?T=C3=ABster?= <toni@example.com>, =?koi8-r?b?8NLJ18XU?= <ivan@example.com>`,
+			[]mail.Address{{Name: "Tëster", Address: "toni@example.com"}, {Name: "Привет", Address: "ivan@example.com"}},
+			false,
+		},
+		{
+			"group syntax", `Testers: toni@example.com, "Tina" <tina@example.com>;, undisclosed-recipients:;`,
+			[]mail.Address{{Address: "toni@example.com"}, {Name: "Tina", Address: "tina@example.com"}},
+			false,
+		},
+		{"missing angle bracket", `Toni Tester <toni@example.com`, nil, true},
+		{"missing domain", `toni@`, nil, true},
+		{"unterminated group", `Testers: toni@example.com`, nil, true},
+	}
+	for _, getter := range getters {
+		for _, tt := range tests {
+			t.Run(getter.header.String()+"/"+tt.name, func(t *testing.T) {
+				m := NewMsg()
+				addresses, err := getter.get(m)
+				if err != nil || len(addresses) != 0 {
+					t.Fatalf("getter without %s header failed. Expected no addresses, got: %v, %v",
+						getter.header, addresses, err)
+				}
+				if err = m.SetAddrHeader(getter.header, "Set Tester <set@example.com>"); err != nil {
+					t.Fatalf("failed to set %s header: %s", getter.header, err)
+				}
+				m.SetGenHeaderPreformatted(Header(getter.header), tt.raw)
+				addresses, err = getter.get(m)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("getter with malformed %s header was supposed to fail, but didn't", getter.header)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("getter for %s header failed: %s", getter.header, err)
+				}
+				want := append([]mail.Address{{Name: "Set Tester", Address: "set@example.com"}}, tt.want...)
+				if len(addresses) != len(want) {
+					t.Fatalf("getter for %s header failed. Expected %d addresses, got: %v", getter.header,
+						len(want), addresses)
+				}
+				for i, address := range addresses {
+					if *address != want[i] {
+						t.Errorf("getter for %s header failed. Expected address: %v, got: %v", getter.header,
+							want[i], *address)
+					}
+				}
+				addresses[0].Name = "Changed"
+				if m.GetAddrHeader(getter.header)[0].Name != "Set Tester" {
+					t.Errorf("getter for %s header returned the stored address instead of a copy", getter.header)
+				}
+			})
+		}
+	}
+}
+
+// TestMsg_SetReplyToList tests the Msg.SetReplyToList method
+func TestMsg_SetReplyToList(t *testing.T) {
+	m := NewMsg()
+	if err := m.SetReplyToList(); !errors.Is(err, ErrNoReplyToAddress) {
+		t.Errorf("SetReplyToList() without addresses expected error: %s, got: %v", ErrNoReplyToAddress, err)
+	}
+	if err := m.SetReplyToList("support@example.com", `"Tëster, Toni" <toni@example.com>`,
+		"Sales <sales@example.com>"); err != nil {
+		t.Fatalf("SetReplyToList() failed: %s", err)
+	}
+	want := `<support@example.com>, =?utf-8?b?VMOrc3RlciwgVG9uaQ==?= <toni@example.com>, "Sales" <sales@example.com>`
+	if header := m.GetGenHeader(HeaderReplyTo); len(header) != 1 || header[0] != want {
+		t.Errorf("SetReplyToList() failed. Expected header: %q, got: %q", want, header)
+	}
+	addresses, err := m.GetReplyTo()
+	if err != nil {
+		t.Fatalf("GetReplyTo() failed: %s", err)
+	}
+	wantAddresses := []mail.Address{
+		{Address: "support@example.com"},
+		{Name: "Tëster, Toni", Address: "toni@example.com"},
+		{Name: "Sales", Address: "sales@example.com"},
+	}
+	if len(addresses) != len(wantAddresses) {
+		t.Fatalf("GetReplyTo() failed. Expected %d addresses, got: %d", len(wantAddresses), len(addresses))
+	}
+	for i, address := range addresses {
+		if *address != wantAddresses[i] {
+			t.Errorf("GetReplyTo() failed. Expected address: %v, got: %v", wantAddresses[i], *address)
+		}
+	}
+	if err = m.SetReplyToList("valid@example.com", "invalid"); err == nil {
+		t.Errorf("SetReplyToList() with invalid address was supposed to fail, but didn't")
+	}
+	if header := m.GetGenHeader(HeaderReplyTo); len(header) != 1 || header[0] != want {
+		t.Errorf("SetReplyToList() with invalid address changed the header to: %q", header)
+	}
+}
+
 // TestMsg_Subject tests the Msg.Subject method
 func TestMsg_Subject(t *testing.T) {
 	tests := []struct {
@@ -1087,6 +1669,120 @@ func TestMsg_SetImportance(t *testing.T) {
 	}
 }
 
+// TestMsg_GetImportance tests the Msg.GetImportance method
+func TestMsg_GetImportance(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[Header]string
+		want    Importance
+		wantErr bool
+	}{
+		{"no headers", nil, ImportanceNormal, false},
+		{"importance high", map[Header]string{HeaderImportance: "High"}, ImportanceHigh, false},
+		{"importance normal", map[Header]string{HeaderImportance: "normal"}, ImportanceNormal, false},
+		{"importance non-urgent", map[Header]string{HeaderImportance: "non-urgent"}, ImportanceNonUrgent, false},
+		{"x-priority highest", map[Header]string{HeaderXPriority: "1 (Highest)"}, ImportanceHigh, false},
+		{"x-priority normal", map[Header]string{HeaderXPriority: "3"}, ImportanceNormal, false},
+		{"x-priority low", map[Header]string{HeaderXPriority: "4"}, ImportanceLow, false},
+		{
+			"importance precedence", map[Header]string{HeaderImportance: "low", HeaderXPriority: "1"},
+			ImportanceLow, false,
+		},
+		{"invalid importance", map[Header]string{HeaderImportance: "critical"}, ImportanceNormal, true},
+		{"invalid x-priority", map[Header]string{HeaderXPriority: "9"}, ImportanceNormal, true},
+		{"priority urgent", map[Header]string{HeaderPriority: "urgent"}, ImportanceUrgent, false},
+		{"invalid priority", map[Header]string{HeaderPriority: "asap"}, ImportanceNormal, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			for header, value := range tt.headers {
+				m.SetGenHeader(header, value)
+			}
+			got, err := m.GetImportance()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImportance) {
+					t.Errorf("GetImportance() expected error: %s, got: %v", ErrInvalidImportance, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetImportance() failed: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetImportance() failed. Expected: %s, got: %s", tt.want, got)
+			}
+		})
+	}
+
+	m := NewMsg()
+	for _, importance := range []Importance{ImportanceNonUrgent, ImportanceLow, ImportanceHigh, ImportanceUrgent} {
+		m.SetImportance(importance)
+		if got, err := m.GetImportance(); err != nil || got != importance {
+			t.Errorf("GetImportance() failed for SetImportance(%s). Got: %s, error: %v", importance, got, err)
+		}
+	}
+	m.SetImportance(Importance(9))
+	if got, _ := m.GetImportance(); got != ImportanceUrgent {
+		t.Errorf("SetImportance() with unknown value was expected to be ignored, got: %s", got)
+	}
+	m.SetImportance(ImportanceNormal)
+	if len(m.GetGenHeader(HeaderImportance)) != 0 || len(m.GetGenHeader(HeaderXPriority)) != 0 {
+		t.Error("SetImportance(ImportanceNormal) was expected to remove the importance headers")
+	}
+}
+
+// TestMsg_Importance tests that Msg.Importance and Msg.GetImportance reconcile the importance headers of an
+// imported message
+func TestMsg_Importance(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers string
+		want    Importance
+		wantErr bool
+	}{
+		{"no headers", "", ImportanceNormal, false},
+		{"importance", "Importance: High\r\n", ImportanceHigh, false},
+		{"x-priority", "X-Priority: 5 (Lowest)\r\n", ImportanceLow, false},
+		{"priority urgent", "Priority: urgent\r\n", ImportanceUrgent, false},
+		{"priority non-urgent", "Priority: Non-Urgent\r\n", ImportanceNonUrgent, false},
+		{"priority normal", "Priority: normal\r\n", ImportanceNormal, false},
+		{"priority numeric", "Priority: 1\r\n", ImportanceHigh, false},
+		{"importance over x-priority", "Importance: low\r\nX-Priority: 1\r\n", ImportanceLow, false},
+		{"x-priority over priority", "X-Priority: 2\r\nPriority: non-urgent\r\n", ImportanceHigh, false},
+		{
+			"importance over all", "Importance: urgent\r\nX-Priority: 5\r\nPriority: non-urgent\r\n",
+			ImportanceUrgent, false,
+		},
+		{"invalid importance is skipped", "Importance: critical\r\nX-Priority: 4\r\n", ImportanceLow, false},
+		{"invalid x-priority is skipped", "X-Priority: 9\r\nPriority: urgent\r\n", ImportanceUrgent, false},
+		{
+			"all invalid", "Importance: critical\r\nX-Priority: 9\r\nPriority: asap\r\n",
+			ImportanceNormal, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := EMLToMsgFromString("From: Toni Tester <tester@example.com>\r\n" +
+				"To: Tina Tester <tina@example.com>\r\nSubject: Importance\r\n" + tt.headers +
+				"Content-Type: text/plain; charset=UTF-8\r\n\r\nTest body\r\n")
+			if err != nil {
+				t.Fatalf("EMLToMsgFromString() failed: %s", err)
+			}
+			got, err := m.GetImportance()
+			if tt.wantErr != errors.Is(err, ErrInvalidImportance) {
+				t.Errorf("GetImportance() returned unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetImportance() failed. Expected: %d, got: %d", tt.want, got)
+			}
+			if got = m.Importance(); got != tt.want {
+				t.Errorf("Importance() failed. Expected: %d, got: %d", tt.want, got)
+			}
+		})
+	}
+}
+
 // TestMsg_SetOrganization tests the Msg.SetOrganization method
 func TestMsg_SetOrganization(t *testing.T) {
 	tests := []struct {
@@ -1111,11 +1807,138 @@ func TestMsg_SetOrganization(t *testing.T) {
 	}
 }
 
-// TestMsg_SetUserAgent tests the Msg.SetUserAgent method
-func TestMsg_SetUserAgent(t *testing.T) {
+// TestMsg_SetContentLanguage tests the Msg.SetContentLanguage and Msg.GetContentLanguage methods
+func TestMsg_SetContentLanguage(t *testing.T) {
 	tests := []struct {
-		name string
-		ua   string
+		name   string
+		langs  []string
+		header string
+		sf     bool
+	}{
+		{"Single language", []string{"en"}, "en", false},
+		{"Language and region", []string{"de-DE"}, "de-DE", false},
+		{"Multiple languages", []string{"en-US", " fr ", "zh-Hant-TW"}, "en-US, fr, zh-Hant-TW", false},
+		{"Extlang and numeric region", []string{"zh-yue-419"}, "zh-yue-419", false},
+		{"Variant and extension", []string{"sl-rozaj-biske", "en-a-bbb-x-a-ccc"}, "sl-rozaj-biske, en-a-bbb-x-a-ccc", false},
+		{"Private use", []string{"x-whatever"}, "x-whatever", false},
+		{"Grandfathered", []string{"i-klingon"}, "i-klingon", false},
+		{"Empty tag", []string{""}, "", true},
+		{"Underscore", []string{"en_US"}, "", true},
+		{"Too long subtag", []string{"de-DE-verylongvariant"}, "", true},
+		{"Header injection", []string{"en\r\nBcc: evil@domain.tld"}, "", true},
+		{"One invalid of many", []string{"en", "e"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			err := m.SetContentLanguage(tt.langs...)
+			if tt.sf {
+				if !errors.Is(err, ErrInvalidLanguageTag) {
+					t.Errorf("SetContentLanguage() expected error: %s, got: %v", ErrInvalidLanguageTag, err)
+				}
+				if len(m.GetGenHeader(HeaderContentLang)) != 0 {
+					t.Errorf("SetContentLanguage() failed. Header was set despite invalid tag")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetContentLanguage() failed: %s", err)
+			}
+			if h := m.GetGenHeader(HeaderContentLang); len(h) != 1 || h[0] != tt.header {
+				t.Errorf("SetContentLanguage() failed. Expected header: %s, got: %v", tt.header, h)
+			}
+			got := m.GetContentLanguage()
+			if len(got) != len(tt.langs) {
+				t.Fatalf("GetContentLanguage() failed. Expected %d tags, got: %v", len(tt.langs), got)
+			}
+			for i := range got {
+				if got[i] != strings.TrimSpace(tt.langs[i]) {
+					t.Errorf("GetContentLanguage() failed. Expected: %s, got: %s", tt.langs[i], got[i])
+				}
+			}
+		})
+	}
+
+	m := NewMsg()
+	if err := m.SetContentLanguage("en"); err != nil {
+		t.Fatalf("SetContentLanguage() failed: %s", err)
+	}
+	if err := m.SetContentLanguage(); err != nil {
+		t.Fatalf("SetContentLanguage() without tags failed: %s", err)
+	}
+	if got := m.GetContentLanguage(); len(got) != 0 {
+		t.Errorf("SetContentLanguage() without tags failed. Expected header to be removed, got: %v", got)
+	}
+}
+
+// TestMsg_SetListUnsubscribe tests the Msg.SetListUnsubscribe and Msg.SetListUnsubscribePostOneClick methods
+func TestMsg_SetListUnsubscribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		urls   []string
+		header string
+		want   error
+	}{
+		{"mailto", []string{"mailto:unsubscribe@example.com"}, "<mailto:unsubscribe@example.com>", nil},
+		{
+			"mailto and https", []string{"mailto:unsubscribe@example.com?subject=unsubscribe", "https://example.com/u?id=1"},
+			"<mailto:unsubscribe@example.com?subject=unsubscribe>, <https://example.com/u?id=1>", nil,
+		},
+		{"already bracketed", []string{" <https://example.com/u> "}, "<https://example.com/u>", nil},
+		{"no URL", nil, "", ErrNoListUnsubscribeURL},
+		{"unsupported scheme", []string{"ftp://example.com/u"}, "", ErrInvalidListUnsubscribeURL},
+		{"relative URL", []string{"/unsubscribe"}, "", ErrInvalidListUnsubscribeURL},
+		{"missing host", []string{"https:///unsubscribe"}, "", ErrInvalidListUnsubscribeURL},
+		{"missing address", []string{"mailto:"}, "", ErrInvalidListUnsubscribeURL},
+		{"header injection", []string{"https://example.com/u\r\nBcc: evil@domain.tld"}, "", ErrInvalidListUnsubscribeURL},
+		{"one invalid of many", []string{"https://example.com/u", "invalid"}, "", ErrInvalidListUnsubscribeURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			err := m.SetListUnsubscribe(tt.urls...)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("SetListUnsubscribe() expected error: %s, got: %v", tt.want, err)
+				}
+				if len(m.GetGenHeader(HeaderListUnsubscribe)) != 0 {
+					t.Errorf("SetListUnsubscribe() failed. Header was set despite invalid URL")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetListUnsubscribe() failed: %s", err)
+			}
+			if h := m.GetGenHeader(HeaderListUnsubscribe); len(h) != 1 || h[0] != tt.header {
+				t.Errorf("SetListUnsubscribe() failed. Expected header: %s, got: %v", tt.header, h)
+			}
+		})
+	}
+
+	m := NewMsg()
+	if err := m.SetListUnsubscribe("https://example.com/unsubscribe"); err != nil {
+		t.Fatalf("SetListUnsubscribe() failed: %s", err)
+	}
+	m.SetListUnsubscribePostOneClick()
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	for _, header := range []string{
+		"List-Unsubscribe: <https://example.com/unsubscribe>\r\n",
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n",
+	} {
+		if !strings.Contains(buf.String(), header) {
+			t.Errorf("SetListUnsubscribePostOneClick() failed. Expected %q in message, got: %s", header, buf.String())
+		}
+	}
+}
+
+// TestMsg_SetUserAgent tests the Msg.SetUserAgent method
+func TestMsg_SetUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
 	}{
 		{"UA: Testmail 1.0", "Testmailer 1.0"},
 	}
@@ -1143,6 +1966,31 @@ func TestMsg_SetUserAgent(t *testing.T) {
 	}
 }
 
+// TestMsg_SetUserAgentVersion tests the Msg.SetUserAgentVersion method
+func TestMsg_SetUserAgentVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		product string
+		version string
+		want    string
+	}{
+		{"product and version", "Invoicer", "2.4.1", "Invoicer 2.4.1"},
+		{"product without version", "Invoicer", "", "Invoicer"},
+		{"surrounding whitespace", " Invoicer ", " 2.4.1 ", "Invoicer 2.4.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg(WithNoDefaultUserAgent())
+			m.SetUserAgentVersion(tt.product, tt.version)
+			for _, header := range []Header{HeaderUserAgent, HeaderXMailer} {
+				if got := m.GetGenHeader(header); len(got) != 1 || got[0] != tt.want {
+					t.Errorf("SetUserAgentVersion() failed. Expected %s: %q, got: %v", header, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
 // TestMsg_RequestMDN tests the different RequestMDN* related methods of Msg
 func TestMsg_RequestMDN(t *testing.T) {
 	n := "Toni Tester"
@@ -1239,6 +2087,59 @@ func TestMsg_RequestMDN(t *testing.T) {
 	}
 }
 
+// TestMsg_RequestMDN_receipts tests the Msg.RequestMDN and Msg.RequestDeliveryReceipt methods and their getters
+func TestMsg_RequestMDN_receipts(t *testing.T) {
+	m := NewMsg()
+	if addresses, err := m.GetMDNTo(); err != nil || addresses != nil {
+		t.Errorf("GetMDNTo without MDN request expected no addresses, got: %v, %v", addresses, err)
+	}
+	if err := m.RequestMDN("Jörg Tester <joerg.tester@example.com>"); err != nil {
+		t.Fatalf("RequestMDN failed: %s", err)
+	}
+	if err := m.RequestDeliveryReceipt("postmaster@example.com"); err != nil {
+		t.Fatalf("RequestDeliveryReceipt failed: %s", err)
+	}
+	for _, address := range []string{"invalid", "Jörg Tester <joerg.tester@>"} {
+		if err := m.RequestMDN(address); err == nil {
+			t.Errorf("RequestMDN with invalid address %q was supposed to fail", address)
+		}
+		if err := m.RequestDeliveryReceipt(address); err == nil {
+			t.Errorf("RequestDeliveryReceipt with invalid address %q was supposed to fail", address)
+		}
+	}
+
+	mdnTo, err := m.GetMDNTo()
+	if err != nil || len(mdnTo) != 1 || mdnTo[0].Name != "Jörg Tester" ||
+		mdnTo[0].Address != "joerg.tester@example.com" {
+		t.Errorf("GetMDNTo failed. Expected: Jörg Tester <joerg.tester@example.com>, got: %v, %v", mdnTo, err)
+	}
+	receiptTo, err := m.GetDeliveryReceiptTo()
+	if err != nil || len(receiptTo) != 1 || receiptTo[0].Address != "postmaster@example.com" {
+		t.Errorf("GetDeliveryReceiptTo failed. Expected: postmaster@example.com, got: %v, %v", receiptTo, err)
+	}
+
+	buffer := bytes.Buffer{}
+	if _, err = m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buffer.String(), "Disposition-Notification-To: =?utf-8?q?J=C3=B6rg_Tester?=") {
+		t.Errorf("expected RFC 2047 encoded display name in message: %s", buffer.String())
+	}
+	parsed, err := mail.ReadMessage(&buffer)
+	if err != nil {
+		t.Fatalf("failed to parse message: %s", err)
+	}
+	for header, want := range map[Header]string{
+		HeaderDispositionNotificationTo: "joerg.tester@example.com",
+		HeaderReturnReceiptTo:           "postmaster@example.com",
+	} {
+		addresses, err := parsed.Header.AddressList(header.String())
+		if err != nil || len(addresses) != 1 || addresses[0].Address != want {
+			t.Errorf("expected %s header with address %s, got: %v, %v", header, want, addresses, err)
+		}
+	}
+}
+
 // TestMsg_SetBodyString tests the Msg.SetBodyString method
 func TestMsg_SetBodyString(t *testing.T) {
 	tests := []struct {
@@ -1274,6 +2175,69 @@ func TestMsg_SetBodyString(t *testing.T) {
 	}
 }
 
+// TestMsg_SetBodyWithContentType tests the Msg.SetBodyWithContentType method with a non-text content type
+func TestMsg_SetBodyWithContentType(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyWithContentType(TypeAppJSON, []byte(`{"invoice":4711}`), WithPartEncoding(EncodingB64))
+	if len(m.parts) != 1 {
+		t.Fatalf("SetBodyWithContentType() failed. Expected 1 part, got: %d", len(m.parts))
+	}
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	for _, want := range []string{
+		"Content-Type: application/json; charset=UTF-8\r\n",
+		"Content-Transfer-Encoding: base64\r\n",
+		base64.StdEncoding.EncodeToString([]byte(`{"invoice":4711}`)),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("SetBodyWithContentType() failed. Expected %q in message: %s", want, buf.String())
+		}
+	}
+}
+
+// TestMsg_AddPartWithContentType tests the Msg.AddPartWithContentType method with a calendar invite
+func TestMsg_AddPartWithContentType(t *testing.T) {
+	invite := "BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nEND:VCALENDAR\r\n"
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "You are invited")
+	m.AddPartWithContentType(TypeTextCalendar, []byte(invite), WithPartContentTypeParam("method", "REQUEST"),
+		WithPartCharset(CharsetISO88591), WithPartEncoding(NoEncoding))
+	if len(m.parts) != 2 {
+		t.Fatalf("AddPartWithContentType() failed. Expected 2 parts, got: %d", len(m.parts))
+	}
+	buf := bytes.Buffer{}
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	for _, want := range []string{
+		"multipart/alternative",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"Content-Type: text/calendar; charset=ISO-8859-1; method=REQUEST\r\n",
+		invite,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("AddPartWithContentType() failed. Expected %q in message: %s", want, buf.String())
+		}
+	}
+
+	t.Run("quoted and invalid parameters", func(t *testing.T) {
+		m.parts[1].SetContentTypeParam("component", "VEVENT VTODO")
+		buf.Reset()
+		if _, err := m.WriteTo(&buf); err != nil {
+			t.Fatalf("failed to write message: %s", err)
+		}
+		if want := `component="VEVENT VTODO"`; !strings.Contains(buf.String(), want) {
+			t.Errorf("AddPartWithContentType() failed. Expected %q in message: %s", want, buf.String())
+		}
+		m.parts[1].SetContentTypeParam("in valid", "value")
+		if _, err := m.WriteTo(io.Discard); err == nil {
+			t.Error("WriteTo() with invalid content type parameter expected error, got nil")
+		}
+	})
+}
+
 // TestMsg_AddAlternativeString tests the Msg.AddAlternativeString method
 func TestMsg_AddAlternativeString(t *testing.T) {
 	tests := []struct {
@@ -1397,6 +2361,146 @@ func TestMsg_GetAttachments(t *testing.T) {
 	}
 }
 
+// TestMsg_TotalAttachmentSize tests that Msg.TotalAttachmentSize returns the size after the transfer encoding
+func TestMsg_TotalAttachmentSize(t *testing.T) {
+	m := NewMsg()
+	if size := m.TotalAttachmentSize(); size != 0 {
+		t.Errorf("TotalAttachmentSize failed. Expected 0 bytes without attachments, got: %d", size)
+	}
+	// 57 bytes give exactly one base64 line of 76 characters plus CRLF
+	if err := m.AttachReader("first.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 57))); err != nil {
+		t.Fatalf("AttachReader failed: %s", err)
+	}
+	if size := m.TotalAttachmentSize(); size != 78 {
+		t.Errorf("TotalAttachmentSize failed. Expected 78 bytes, got: %d", size)
+	}
+	// 100 bytes give 136 base64 characters on two lines
+	if err := m.AttachReader("second.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 100))); err != nil {
+		t.Fatalf("AttachReader failed: %s", err)
+	}
+	if size := m.TotalAttachmentSize(); size != 218 {
+		t.Errorf("TotalAttachmentSize failed. Expected 218 bytes, got: %d", size)
+	}
+	m.EmbedReader("embed.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 100)))
+	if size := m.TotalAttachmentSize(); size != 218 {
+		t.Errorf("TotalAttachmentSize failed. Expected embeds not to be counted, got: %d bytes", size)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	if _, err := m.WriteTo(buffer); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	if size := int64(buffer.Len()); size <= m.TotalAttachmentSize() {
+		t.Errorf("TotalAttachmentSize failed. Expected %d bytes to be less than the message size of %d bytes",
+			m.TotalAttachmentSize(), size)
+	}
+}
+
+// TestMsg_Size tests that Msg.Size matches the number of bytes written by Msg.WriteTo
+func TestMsg_Size(t *testing.T) {
+	tpl, err := ttpl.New("test").Parse("Hello {{.Name}}, this is a test mail")
+	if err != nil {
+		t.Fatalf("failed to parse template: %s", err)
+	}
+	m := NewMsg(WithBoundary("testboundary"))
+	if err = m.From("toni@tester.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	m.Subject("Grüße aus Köln")
+	m.SetMessageIDWithValue("size@tester.com")
+	m.SetDate()
+	if err = m.SetBodyTextTemplate(tpl, struct{ Name string }{"Toni"}); err != nil {
+		t.Fatalf("failed to set template body: %s", err)
+	}
+	m.AddAlternativeString(TypeTextHTML, "<p>Grüße aus Köln</p>", WithPartEncoding(EncodingB64))
+	if err = m.AttachReader("data.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 100))); err != nil {
+		t.Fatalf("AttachReader failed: %s", err)
+	}
+	size, err := m.Size()
+	if err != nil {
+		t.Fatalf("Size failed: %s", err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	if _, err = m.WriteTo(buffer); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	if size != int64(buffer.Len()) {
+		t.Errorf("Size failed. Expected %d bytes, got: %d", buffer.Len(), size)
+	}
+
+	m.SetBodyWriter(TypeTextPlain, func(io.Writer) (int64, error) {
+		return 0, errors.New("body write failed")
+	})
+	if _, err = m.Size(); err == nil {
+		t.Error("Size of a Msg that cannot be rendered was supposed to fail, but didn't")
+	}
+}
+
+// TestMsg_AttachFunc tests the Msg.AttachFunc method
+func TestMsg_AttachFunc(t *testing.T) {
+	calls := 0
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "This is the body")
+	m.AttachFunc("report.csv", TypeTextPlain, func() (io.Reader, error) {
+		calls++
+		return strings.NewReader("id;name\n1;Toni\n"), nil
+	})
+	if calls != 0 {
+		t.Errorf("AttachFunc failed. Expected no call before writing, got: %d", calls)
+	}
+	if len(m.attachments) != 1 {
+		t.Fatalf("AttachFunc failed. Expected 1 attachment, got: %d", len(m.attachments))
+	}
+	if ct := m.attachments[0].GetContentType(); ct != TypeTextPlain {
+		t.Errorf("AttachFunc failed. Expected content type: %s, got: %s", TypeTextPlain, ct)
+	}
+	if charset := m.attachments[0].GetCharset(); charset != "" {
+		t.Errorf("AttachFunc failed. Expected no inferred charset, got: %s", charset)
+	}
+	buffer := bytes.NewBuffer(nil)
+	if _, err := m.WriteTo(buffer); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	if calls != 1 {
+		t.Errorf("AttachFunc failed. Expected 1 call per write, got: %d", calls)
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte("id;name\n1;Toni\n"))
+	if !strings.Contains(buffer.String(), encoded) {
+		t.Errorf("AttachFunc failed. Expected produced content %q in message, got: %s", encoded, buffer.String())
+	}
+
+	clone, err := m.withProducedFiles()
+	if err != nil {
+		t.Fatalf("withProducedFiles failed: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		buffer.Reset()
+		if _, err = clone.WriteTo(buffer); err != nil {
+			t.Fatalf("WriteTo failed: %s", err)
+		}
+		if !strings.Contains(buffer.String(), encoded) {
+			t.Errorf("AttachFunc failed. Expected produced content %q in message, got: %s", encoded,
+				buffer.String())
+		}
+	}
+	if calls != 2 {
+		t.Errorf("withProducedFiles failed. Expected content to be produced once, got %d calls", calls-1)
+	}
+
+	produceErr := errors.New("report generation failed")
+	m = NewMsg()
+	m.SetBodyString(TypeTextPlain, "This is the body")
+	m.AttachFunc("report.bin", TypeAppOctetStream, func() (io.Reader, error) {
+		return nil, produceErr
+	})
+	if _, err = m.WriteTo(io.Discard); !errors.Is(err, produceErr) {
+		t.Errorf("WriteTo with failing producer expected error: %s, got: %v", produceErr, err)
+	}
+	if _, err = m.withProducedFiles(); !errors.Is(err, produceErr) {
+		t.Errorf("withProducedFiles with failing producer expected error: %s, got: %v", produceErr, err)
+	}
+}
+
 // TestMsg_SetAttachments tests the Msg.GetAttachments method
 func TestMsg_SetAttachments(t *testing.T) {
 	tests := []struct {
@@ -1697,6 +2801,32 @@ func TestMsg_AttachFromEmbedFS(t *testing.T) {
 	}
 }
 
+// TestMsg_AttachFromEmbedFS_invalid tests that AttachFromEmbedFS and EmbedFromEmbedFS fail for
+// missing files and directories
+func TestMsg_AttachFromEmbedFS_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"missing file", "missing.file"},
+		{"directory", "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := m.AttachFromEmbedFS(tt.file, &efs); err == nil {
+				t.Error("AttachFromEmbedFS() was supposed to fail, but didn't")
+			}
+			if err := m.EmbedFromEmbedFS(tt.file, &efs); err == nil {
+				t.Error("EmbedFromEmbedFS() was supposed to fail, but didn't")
+			}
+			if len(m.attachments) != 0 || len(m.embeds) != 0 {
+				t.Errorf("expected no attachments or embeds, got: %d/%d", len(m.attachments), len(m.embeds))
+			}
+		})
+	}
+}
+
 // TestMsg_AttachFileBrokenFunc tests WriterFunc of the Msg.AttachFile  method
 func TestMsg_AttachFileBrokenFunc(t *testing.T) {
 	m := NewMsg()
@@ -1754,6 +2884,81 @@ func TestMsg_AttachReader(t *testing.T) {
 	}
 }
 
+// TestMsg_AttachReaderWithContentType tests the Msg.AttachReaderWithContentType and Msg.AttachReaderWithSize
+// methods
+func TestMsg_AttachReaderWithContentType(t *testing.T) {
+	content := "%PDF-1.4 This is a rendered document"
+	typePDF := ContentType("application/pdf")
+	tests := []struct {
+		name   string
+		attach func(*Msg) error
+		want   string
+	}{
+		{
+			"content type", func(m *Msg) error {
+				return m.AttachReaderWithContentType("invoice", strings.NewReader(content), typePDF)
+			}, `Content-Type: application/pdf; name="invoice"`,
+		},
+		{
+			"empty content type", func(m *Msg) error {
+				return m.AttachReaderWithContentType("invoice.txt", strings.NewReader(content), "")
+			}, `Content-Type: text/plain; charset=utf-8; name="invoice.txt"`,
+		},
+		{
+			"exact size", func(m *Msg) error {
+				return m.AttachReaderWithSize("invoice", strings.NewReader(content), typePDF, int64(len(content)))
+			}, `Content-Type: application/pdf; name="invoice"`,
+		},
+		{
+			"smaller content", func(m *Msg) error {
+				return m.AttachReaderWithSize("invoice", strings.NewReader(content), typePDF, 1024)
+			}, `Content-Type: application/pdf; name="invoice"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := tt.attach(m); err != nil {
+				t.Fatalf("failed to attach reader: %s", err)
+			}
+			if len(m.attachments) != 1 {
+				t.Fatalf("expected 1 attachment, got: %d", len(m.attachments))
+			}
+			wbuf := bytes.Buffer{}
+			if _, err := m.attachments[0].Writer(&wbuf); err != nil {
+				t.Fatalf("execute WriterFunc failed: %s", err)
+			}
+			if wbuf.String() != content {
+				t.Errorf("attachment content mismatch. Expected: %q, got: %q", content, wbuf.String())
+			}
+			msgbuf := bytes.Buffer{}
+			if _, err := m.WriteTo(&msgbuf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			if !strings.Contains(msgbuf.String(), tt.want) {
+				t.Errorf("expected %q in message, got: %s", tt.want, msgbuf.String())
+			}
+		})
+	}
+
+	m := NewMsg()
+	err := m.AttachReaderWithSize("invoice", strings.NewReader(content), typePDF, int64(len(content)-1))
+	if !errors.Is(err, ErrFileSizeExceeded) {
+		t.Errorf("AttachReaderWithSize expected error: %s, got: %v", ErrFileSizeExceeded, err)
+	}
+	if err = m.AttachReaderWithSize("invoice", strings.NewReader(content), typePDF, -1); !errors.Is(err,
+		ErrInvalidFileSize) {
+		t.Errorf("AttachReaderWithSize expected error: %s, got: %v", ErrInvalidFileSize, err)
+	}
+	if err = m.AttachReaderWithSize("invoice", iotest.ErrReader(errors.New("broken reader")), typePDF,
+		10); err == nil {
+		t.Error("AttachReaderWithSize with failing reader was expected to fail")
+	}
+	if len(m.attachments) != 0 {
+		t.Errorf("expected no attachments after failures, got: %d", len(m.attachments))
+	}
+}
+
 // TestMsg_EmbedFile tests the Msg.EmbedFile and the WithFilename FileOption method
 func TestMsg_EmbedFile(t *testing.T) {
 	tests := []struct {
@@ -1796,6 +3001,112 @@ func TestMsg_EmbedFile(t *testing.T) {
 	}
 }
 
+// TestMsg_EmbedAndReference tests the Msg.EmbedAndReference method
+func TestMsg_EmbedAndReference(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost.localdomain"
+	}
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, `Plain text with src="logo.png"`)
+	m.AddAlternativeString(TypeTextHTML, `<img src="logo.png"><img alt="x" SRC = 'image/footer.png'>`+
+		`<img src="other.png"><img data-src="logo.png"><img src="logo.png.bak">`)
+
+	cid, err := m.EmbedAndReference("README.md", "logo.png")
+	if err != nil {
+		t.Fatalf("EmbedAndReference failed: %s", err)
+	}
+	if !strings.HasSuffix(cid, "@"+hostname) || cid == "README.md" {
+		t.Errorf("EmbedAndReference failed. Expected generated Content-ID, got: %s", cid)
+	}
+	generatedID := cid
+	cid, err = m.EmbedAndReference("doc.go", "image/footer.png", WithFileContentID("<footer@domain.tld>"))
+	if err != nil {
+		t.Fatalf("EmbedAndReference failed: %s", err)
+	}
+	if cid != "footer@domain.tld" {
+		t.Errorf("EmbedAndReference failed. Expected Content-ID: %s, got: %s", "footer@domain.tld", cid)
+	}
+	if len(m.embeds) != 2 {
+		t.Fatalf("EmbedAndReference failed. Expected 2 embeds, got: %d", len(m.embeds))
+	}
+	if id := m.embeds[0].Header.Get(HeaderContentID.String()); id != "<"+generatedID+">" {
+		t.Errorf("EmbedAndReference failed. Expected Content-ID header: %s, got: %s", "<"+generatedID+">", id)
+	}
+
+	content, err := m.parts[1].GetContent()
+	if err != nil {
+		t.Fatalf("failed to get HTML part content: %s", err)
+	}
+	want := `<img src="cid:` + generatedID + `"><img alt="x" SRC = "cid:footer@domain.tld">` +
+		`<img src="other.png"><img data-src="logo.png"><img src="logo.png.bak">`
+	if string(content) != want {
+		t.Errorf("EmbedAndReference failed. Expected HTML: %s, got: %s", want, content)
+	}
+	content, err = m.parts[0].GetContent()
+	if err != nil {
+		t.Fatalf("failed to get plain text part content: %s", err)
+	}
+	if string(content) != `Plain text with src="logo.png"` {
+		t.Errorf("EmbedAndReference failed. Plain text part was changed: %s", content)
+	}
+
+	if _, err = m.EmbedAndReference("README.md", "missing.png"); !errors.Is(err, ErrNoEmbedReference) {
+		t.Errorf("EmbedAndReference expected error: %s, got: %v", ErrNoEmbedReference, err)
+	}
+	m.AddAlternativeString(TypeTextHTML, `<img src="nonexisting.file">`)
+	if _, err = m.EmbedAndReference("nonexisting.file", ""); err == nil {
+		t.Errorf("EmbedAndReference with nonexisting file was supposed to fail, but didn't")
+	}
+	if len(m.embeds) != 2 {
+		t.Errorf("EmbedAndReference failed. Expected 2 embeds after errors, got: %d", len(m.embeds))
+	}
+}
+
+// TestMsg_EmbedAndReference_SameBaseName tests that Msg.EmbedAndReference generates distinct Content-IDs
+// for files with the same base name
+func TestMsg_EmbedAndReference_SameBaseName(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, dir := range []string{"header", "footer"} {
+		if err := os.Mkdir(filepath.Join(tempDir, dir), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, dir, "logo.png"), []byte(dir), 0o600); err != nil {
+			t.Fatalf("failed to write file: %s", err)
+		}
+	}
+	m := NewMsg()
+	m.SetBodyString(TypeTextHTML, `<img src="header/logo.png"><img src="footer/logo.png">`)
+	headerID, err := m.EmbedAndReference(filepath.Join(tempDir, "header", "logo.png"), "header/logo.png")
+	if err != nil {
+		t.Fatalf("EmbedAndReference failed: %s", err)
+	}
+	footerID, err := m.EmbedAndReference(filepath.Join(tempDir, "footer", "logo.png"), "footer/logo.png")
+	if err != nil {
+		t.Fatalf("EmbedAndReference failed: %s", err)
+	}
+	if headerID == footerID {
+		t.Errorf("EmbedAndReference failed. Expected distinct Content-IDs, got: %s twice", headerID)
+	}
+	content, err := m.parts[0].GetContent()
+	if err != nil {
+		t.Fatalf("failed to get HTML part content: %s", err)
+	}
+	want := `<img src="cid:` + headerID + `"><img src="cid:` + footerID + `">`
+	if string(content) != want {
+		t.Errorf("EmbedAndReference failed. Expected HTML: %s, got: %s", want, content)
+	}
+	for i, dir := range []string{"header", "footer"} {
+		var buffer bytes.Buffer
+		if _, err = m.embeds[i].Writer(&buffer); err != nil {
+			t.Fatalf("failed to write embed: %s", err)
+		}
+		if buffer.String() != dir {
+			t.Errorf("EmbedAndReference failed. Expected embed content: %s, got: %s", dir, buffer.String())
+		}
+	}
+}
+
 // TestMsg_EmbedFromEmbedFS tests the Msg.EmbedFromEmbedFS and the WithFilename FileOption method
 func TestMsg_EmbedFromEmbedFS(t *testing.T) {
 	tests := []struct {
@@ -1897,6 +3208,56 @@ func TestMsg_EmbedReader(t *testing.T) {
 	}
 }
 
+// TestMsg_EmbedReaderWithContentID tests the Msg.EmbedReaderWithContentID method
+func TestMsg_EmbedReaderWithContentID(t *testing.T) {
+	pngData := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+	m := NewMsg()
+	m.SetBodyString(TypeTextHTML, "<img>")
+	cid1, err := m.EmbedReaderWithContentID("image", bytes.NewReader(pngData))
+	if err != nil {
+		t.Fatalf("EmbedReaderWithContentID() failed: %s", err)
+	}
+	cid2, err := m.EmbedReaderWithContentID("image", bytes.NewReader(pngData),
+		WithFileContentType(TypeAppOctetStream))
+	if err != nil {
+		t.Fatalf("EmbedReaderWithContentID() failed: %s", err)
+	}
+	cid3, err := m.EmbedReaderWithContentID("logo.png", bytes.NewReader(pngData), WithFileContentID("logo"))
+	if err != nil {
+		t.Fatalf("EmbedReaderWithContentID() failed: %s", err)
+	}
+	if cid1 == "" || cid1 == cid2 {
+		t.Errorf("EmbedReaderWithContentID() failed. Expected unique Content-IDs, got: %q and %q", cid1, cid2)
+	}
+	if strings.ContainsAny(cid1, "<>") || !strings.Contains(cid1, "@") {
+		t.Errorf("EmbedReaderWithContentID() failed. Unexpected Content-ID: %q", cid1)
+	}
+	if cid3 != "logo" {
+		t.Errorf("EmbedReaderWithContentID() failed. Expected Content-ID: %q, got: %q", "logo", cid3)
+	}
+	if len(m.embeds) != 3 {
+		t.Fatalf("EmbedReaderWithContentID() failed. Expected 3 embeds, got: %d", len(m.embeds))
+	}
+
+	buf := bytes.Buffer{}
+	if _, err = m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() failed: %s", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"Content-Id: <" + cid1 + ">",
+		"Content-Id: <" + cid2 + ">",
+		"Content-Id: logo",
+		`Content-Type: image/png; name="image"`,
+		`Content-Type: application/octet-stream; name="image"`,
+		`Content-Disposition: inline; filename="image"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("EmbedReaderWithContentID() failed. Expected output to contain %q", want)
+		}
+	}
+}
+
 // TestMsg_hasAlt tests the hasAlt() method of the Msg
 func TestMsg_hasAlt(t *testing.T) {
 	m := NewMsg()
@@ -1917,13 +3278,226 @@ func TestMsg_hasRelated(t *testing.T) {
 	}
 }
 
-// TestMsg_hasMixed tests the hasMixed() method of the Msg
-func TestMsg_hasMixed(t *testing.T) {
-	m := NewMsg()
-	m.SetBodyString(TypeTextPlain, "Plain")
-	m.AttachFile("README.md")
-	if !m.hasMixed() {
-		t.Errorf("mail has mixed parts but hasMixed() returned true")
+// TestMsg_hasMixed tests the hasMixed() method of the Msg
+func TestMsg_hasMixed(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "Plain")
+	m.AttachFile("README.md")
+	if !m.hasMixed() {
+		t.Errorf("mail has mixed parts but hasMixed() returned true")
+	}
+}
+
+// TestMsg_Reset tests the Reset() method of the Msg
+func TestMsg_Reset(t *testing.T) {
+	m := NewMsg(WithCharset(CharsetISO88591), WithEncoding(EncodingB64), WithBoundary("testboundary"),
+		WithMaxLineLength(60), WithNoDefaultUserAgent(), WithForceMultipart(), WithHeaderFolding(100))
+	if err := m.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := m.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	m.Subject("Test subject")
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "Test body")
+	m.AttachFile("README.md")
+	m.EmbedFile("README.md")
+	m.isDelivered = true
+	m.sendError = errors.New("send failed")
+
+	m.Reset()
+	if len(m.addrHeader) != 0 || len(m.genHeader) != 0 || len(m.preformHeader) != 0 {
+		t.Errorf("Reset failed. Expected headers to be empty, got: %v, %v, %v", m.addrHeader, m.genHeader,
+			m.preformHeader)
+	}
+	if len(m.parts) != 0 || len(m.attachments) != 0 || len(m.embeds) != 0 {
+		t.Errorf("Reset failed. Expected parts, attachments and embeds to be empty")
+	}
+	if m.IsDelivered() || m.HasSendError() {
+		t.Errorf("Reset failed. Expected delivery state and send error to be cleared")
+	}
+	if m.GetMessageID() != "" {
+		t.Errorf("Reset failed. Expected Message-ID to be cleared, got: %s", m.GetMessageID())
+	}
+	if m.Charset() != CharsetISO88591.String() || m.Encoding() != EncodingB64.String() ||
+		m.boundary != "testboundary" || m.maxLineLength != 60 || !m.noDefaultUserAgent || !m.forceMultipart ||
+		m.headerFoldColumn != 100 {
+		t.Errorf("Reset failed. Expected message-level settings to survive, got: %+v", m)
+	}
+
+	if err := m.To("valid-to@domain.tld"); err != nil {
+		t.Errorf("failed to set TO address after Reset: %s", err)
+	}
+	m.Subject("Test subject")
+	m.SetBodyString(TypeTextPlain, "Test body")
+	buffer := bytes.Buffer{}
+	if _, err := m.WriteTo(&buffer); err != nil {
+		t.Errorf("failed to write message after Reset: %s", err)
+	}
+}
+
+// TestMsg_Clone tests the Clone() method of the Msg
+func TestMsg_Clone(t *testing.T) {
+	m := NewMsg(WithBoundary("testboundary"))
+	if err := m.From("Toni Tester <valid-from@domain.tld>"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := m.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	m.Subject("Test subject")
+	m.SetDate()
+	m.SetMessageID()
+	m.SetBodyString(TypeTextPlain, "Test body")
+	m.AddAlternativeString(TypeTextHTML, "<p>Test body</p>")
+	m.GetParts()[1].SetContentTypeParam("format", "original")
+	if err := m.AttachReader("attachment.txt", strings.NewReader("attachment content")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	m.isDelivered = true
+	m.sendError = errors.New("send failed")
+
+	clone := m.Clone()
+	if clone.IsDelivered() || clone.HasSendError() {
+		t.Errorf("Clone failed. Expected delivery state and send error not to be copied")
+	}
+	original, cloned := bytes.Buffer{}, bytes.Buffer{}
+	if _, err := m.WriteTo(&original); err != nil {
+		t.Fatalf("failed to write original message: %s", err)
+	}
+	if _, err := clone.WriteTo(&cloned); err != nil {
+		t.Fatalf("failed to write cloned message: %s", err)
+	}
+	if original.String() != cloned.String() {
+		t.Errorf("Clone failed. Expected identical output.\nOriginal:\n%s\nClone:\n%s", original.String(),
+			cloned.String())
+	}
+
+	if err := clone.To("other-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address on clone: %s", err)
+	}
+	clone.GetFrom()[0].Name = "Other Tester"
+	clone.Subject("Other subject")
+	clone.SetMessageIDWithValue("other.id@domain.tld")
+	clone.GetParts()[0].SetContent("Other body")
+	clone.GetParts()[1].SetContentTypeParam("format", "other")
+	clone.GetAttachments()[0].Name = "other.txt"
+	clone.GetAttachments()[0].Header.Set(HeaderContentID.String(), "other")
+
+	if to := m.GetToString(); len(to) != 1 || to[0] != "<valid-to@domain.tld>" {
+		t.Errorf("Clone failed. Original TO address was changed: %v", to)
+	}
+	if from := m.GetFrom(); from[0].Name != "Toni Tester" {
+		t.Errorf("Clone failed. Original FROM address was changed: %s", from[0])
+	}
+	if subject := m.GetGenHeader(HeaderSubject); subject[0] != "Test subject" {
+		t.Errorf("Clone failed. Original subject was changed: %s", subject[0])
+	}
+	if m.GetMessageID() == "<other.id@domain.tld>" {
+		t.Errorf("Clone failed. Original Message-ID was changed")
+	}
+	content, err := m.GetParts()[0].GetContent()
+	if err != nil {
+		t.Fatalf("failed to get part content: %s", err)
+	}
+	if string(content) != "Test body" {
+		t.Errorf("Clone failed. Original part content was changed: %s", content)
+	}
+	if param := m.GetParts()[1].GetContentTypeParam("format"); param != "original" {
+		t.Errorf("Clone failed. Original part content type parameter was changed: %s", param)
+	}
+	attachment := m.GetAttachments()[0]
+	if attachment.Name != "attachment.txt" || attachment.Header.Get(HeaderContentID.String()) != "" {
+		t.Errorf("Clone failed. Original attachment was changed: %+v", attachment)
+	}
+}
+
+// TestMsg_Bytes tests the Bytes() and String() methods of the Msg
+func TestMsg_Bytes(t *testing.T) {
+	m := NewMsg()
+	if err := m.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := m.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	m.Subject("Test subject")
+	m.SetBodyString(TypeTextPlain, "Test body")
+	data, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %s", err)
+	}
+	buffer := bytes.Buffer{}
+	if _, err = m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	buffer.WriteString("\r\n")
+	if !bytes.Equal(data, buffer.Bytes()) {
+		t.Errorf("Bytes failed. Expected: %q, got: %q", buffer.String(), data)
+	}
+	str, err := m.String()
+	if err != nil {
+		t.Fatalf("String failed: %s", err)
+	}
+	if str != buffer.String() {
+		t.Errorf("String failed. Expected: %q, got: %q", buffer.String(), str)
+	}
+
+	m.SetBodyWriter(TypeTextPlain, func(io.Writer) (int64, error) {
+		return 0, errors.New("body write failed")
+	})
+	if _, err = m.Bytes(); err == nil {
+		t.Errorf("Bytes with failing body writer was supposed to fail, but didn't")
+	}
+	if _, err = m.String(); err == nil {
+		t.Errorf("String with failing body writer was supposed to fail, but didn't")
+	}
+}
+
+// TestMsg_StringRedacted tests the Msg.StringRedacted method
+func TestMsg_StringRedacted(t *testing.T) {
+	m := newPoolTestMsg(t)
+	m.SetGenHeader("X-Api-Token", "s3cr3t-t0k3n")
+	m.SetGenHeader("X-Long-Secret", strings.Repeat("s3cr3t-", 20))
+	m.SetGenHeaderPreformatted("Authorization", "Bearer s3cr3t-b3ar3r")
+	m.SetGenHeader("X-Api-Token-Info", "public information")
+	m.AddAlternativeString(TypeTextHTML, "<p>X-Api-Token: s3cr3t-in-body</p>")
+	m.SetBoundary("go-mail-test-boundary")
+
+	unredacted, err := m.String()
+	if err != nil {
+		t.Fatalf("String failed: %s", err)
+	}
+	redacted, err := m.StringRedacted()
+	if err != nil {
+		t.Fatalf("StringRedacted failed: %s", err)
+	}
+	if redacted != unredacted {
+		t.Errorf("StringRedacted without headers expected to match String. Expected: %q, got: %q",
+			unredacted, redacted)
+	}
+
+	redacted, err = m.StringRedacted("x-api-token", "X-Long-Secret", "Authorization")
+	if err != nil {
+		t.Fatalf("StringRedacted failed: %s", err)
+	}
+	for _, secret := range []string{"s3cr3t-t0k3n", "s3cr3t-b3ar3r", "s3cr3t-s3cr3t-"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("StringRedacted expected %q to be redacted, got: %s", secret, redacted)
+		}
+	}
+	for _, want := range []string{
+		"X-Api-Token: <redacted>\r\n", "X-Long-Secret: <redacted>\r\n", "Authorization: <redacted>\r\n",
+		"X-Api-Token-Info: public information\r\n", "Subject: Test subject\r\n", "s3cr3t-in-body",
+	} {
+		if !strings.Contains(redacted, want) {
+			t.Errorf("StringRedacted expected %q in output, got: %s", want, redacted)
+		}
+	}
+	if _, err = EMLToMsgFromString(redacted); err != nil {
+		t.Errorf("StringRedacted output expected to be a parsable message: %s", err)
 	}
 }
 
@@ -1974,6 +3548,53 @@ func TestMsg_WriteToSkipMiddleware(t *testing.T) {
 	}
 }
 
+// TestMsg_WriteToWithProgress tests the WriteToWithProgress() method of the Msg
+func TestMsg_WriteToWithProgress(t *testing.T) {
+	m := NewMsg(WithBoundary("go-mail-progress-boundary"))
+	m.SetMessageIDWithValue("progress@example.com")
+	m.SetDate()
+	m.Subject("This is a test")
+	m.SetBodyString(TypeTextPlain, "Plain")
+	m.AttachReadSeeker("attachment.bin", bytes.NewReader(bytes.Repeat([]byte("go-mail"), 10000)))
+
+	wbuf := bytes.Buffer{}
+	if _, err := m.WriteTo(&wbuf); err != nil {
+		t.Fatalf("WriteTo() failed: %s", err)
+	}
+
+	var calls int
+	var last int64
+	pbuf := bytes.Buffer{}
+	n, err := m.WriteToWithProgress(&pbuf, func(written int64) {
+		calls++
+		if written <= last {
+			t.Errorf("WriteToWithProgress() failed. Expected increasing progress, got %d after %d", written, last)
+		}
+		last = written
+	})
+	if err != nil {
+		t.Fatalf("WriteToWithProgress() failed: %s", err)
+	}
+	if !bytes.Equal(pbuf.Bytes(), wbuf.Bytes()) {
+		t.Errorf("WriteToWithProgress() failed. Expected output to match WriteTo() output")
+	}
+	if n != int64(pbuf.Len()) || last != n {
+		t.Errorf("WriteToWithProgress() failed: expected written byte length: %d, got: %d, last progress: %d",
+			pbuf.Len(), n, last)
+	}
+	if calls < 2 {
+		t.Errorf("WriteToWithProgress() failed. Expected multiple progress calls, got: %d", calls)
+	}
+
+	nbuf := bytes.Buffer{}
+	if _, err = m.WriteToWithProgress(&nbuf, nil); err != nil {
+		t.Fatalf("WriteToWithProgress() with nil callback failed: %s", err)
+	}
+	if !bytes.Equal(nbuf.Bytes(), wbuf.Bytes()) {
+		t.Errorf("WriteToWithProgress() with nil callback failed. Expected output to match WriteTo() output")
+	}
+}
+
 // TestMsg_WriteTo_fails tests the WriteTo() method of the Msg but with a failing body writer function
 func TestMsg_WriteTo_fails(t *testing.T) {
 	m := NewMsg()
@@ -2378,6 +3999,49 @@ func TestMsg_AddAlternativeHTMLTemplate(t *testing.T) {
 	}
 }
 
+// TestMsg_HTMLTemplate_ExecuteError tests that template execution errors of the HTML template
+// methods are returned and no part is added to the Msg
+func TestMsg_HTMLTemplate_ExecuteError(t *testing.T) {
+	tpl, err := htpl.New("test").Parse("<p>This is a {{.Placeholder.Missing}}</p>")
+	if err != nil {
+		t.Fatalf("failed to parse template: %s", err)
+	}
+	data := struct {
+		Placeholder string
+	}{Placeholder: "TemplateTest"}
+
+	m := NewMsg()
+	if err = m.SetBodyHTMLTemplate(tpl, data); err == nil {
+		t.Error("SetBodyHTMLTemplate with failing template execution was supposed to fail, but didn't")
+	}
+	if len(m.GetParts()) != 0 {
+		t.Errorf("SetBodyHTMLTemplate failed. Expected no parts, got: %d", len(m.GetParts()))
+	}
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	if err = m.AddAlternativeHTMLTemplate(tpl, data); err == nil {
+		t.Error("AddAlternativeHTMLTemplate with failing template execution was supposed to fail, but didn't")
+	}
+	if len(m.GetParts()) != 1 {
+		t.Errorf("AddAlternativeHTMLTemplate failed. Expected 1 part, got: %d", len(m.GetParts()))
+	}
+
+	tpl, err = htpl.New("test").Parse("<p>This is a {{.Placeholder}}</p>")
+	if err != nil {
+		t.Fatalf("failed to parse template: %s", err)
+	}
+	if err = m.AddAlternativeHTMLTemplate(tpl, data, WithPartCharset(CharsetISO88591)); err != nil {
+		t.Fatalf("failed to set template as alternative part: %s", err)
+	}
+	parts := m.GetParts()
+	if len(parts) != 2 {
+		t.Fatalf("AddAlternativeHTMLTemplate failed. Expected 2 parts, got: %d", len(parts))
+	}
+	if parts[1].GetContentType() != TypeTextHTML || parts[1].GetCharset() != CharsetISO88591 {
+		t.Errorf("AddAlternativeHTMLTemplate failed. Expected %s part with charset %s, got: %s/%s",
+			TypeTextHTML, CharsetISO88591, parts[1].GetContentType(), parts[1].GetCharset())
+	}
+}
+
 // TestMsg_AttachTextTemplate tests the Msg.AttachTextTemplate method
 func TestMsg_AttachTextTemplate(t *testing.T) {
 	tests := []struct {
@@ -2643,6 +4307,65 @@ func TestMsg_GetGenHeader(t *testing.T) {
 	}
 }
 
+// TestMsg_RemoveGenHeader tests the Msg.HasGenHeader and Msg.RemoveGenHeader methods
+func TestMsg_RemoveGenHeader(t *testing.T) {
+	m := NewMsg()
+	m.SetGenHeader(HeaderListUnsubscribe, "<mailto:unsubscribe@example.com>", "<https://example.com/u>")
+	m.SetGenHeaderPreformatted(HeaderListUnsubscribePost, "List-Unsubscribe=One-Click")
+	if err := m.To("to@example.com"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	for _, header := range []Header{HeaderListUnsubscribe, HeaderListUnsubscribePost, "to"} {
+		if !m.HasGenHeader(header) {
+			t.Errorf("HasGenHeader(%s) failed. Expected header to be set", header)
+		}
+	}
+	if m.HasGenHeader(HeaderOrganization) {
+		t.Errorf("HasGenHeader(%s) failed. Expected header not to be set", HeaderOrganization)
+	}
+
+	for _, header := range []Header{HeaderListUnsubscribe, HeaderListUnsubscribePost, HeaderOrganization} {
+		m.RemoveGenHeader(header)
+		if m.HasGenHeader(header) {
+			t.Errorf("RemoveGenHeader(%s) failed. Expected header to be removed", header)
+		}
+	}
+	buffer := bytes.Buffer{}
+	if _, err := m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if strings.Contains(buffer.String(), "List-Unsubscribe") {
+		t.Errorf("RemoveGenHeader failed. Expected no List-Unsubscribe headers in message: %s", buffer.String())
+	}
+
+	m.RemoveGenHeader(Header(HeaderTo))
+	if m.HasGenHeader(Header(HeaderTo)) || m.HasAddrHeader(HeaderTo) || len(m.GetTo()) != 0 {
+		t.Error("RemoveGenHeader(To) failed. Expected TO addresses to be removed")
+	}
+}
+
+// TestMsg_RemoveAddrHeader tests the Msg.HasAddrHeader and Msg.RemoveAddrHeader methods
+func TestMsg_RemoveAddrHeader(t *testing.T) {
+	m := NewMsg()
+	if err := m.Cc("cc1@example.com", "cc2@example.com"); err != nil {
+		t.Fatalf("failed to set CC addresses: %s", err)
+	}
+	if !m.HasAddrHeader(HeaderCc) || m.HasAddrHeader(HeaderBcc) {
+		t.Errorf("HasAddrHeader failed. Expected only CC to be set")
+	}
+	m.RemoveAddrHeader(HeaderCc)
+	m.RemoveAddrHeader(HeaderBcc)
+	if m.HasAddrHeader(HeaderCc) || len(m.GetCc()) != 0 {
+		t.Errorf("RemoveAddrHeader failed. Expected CC addresses to be removed")
+	}
+	if err := m.AddCc("cc3@example.com"); err != nil {
+		t.Fatalf("failed to add CC address after RemoveAddrHeader: %s", err)
+	}
+	if got := m.GetCcString(); len(got) != 1 || got[0] != "<cc3@example.com>" {
+		t.Errorf("AddCc after RemoveAddrHeader failed. Expected: %q, got: %q", "<cc3@example.com>", got)
+	}
+}
+
 // TestMsg_GetAddrHeader will test the Msg.GetAddrHeader method
 func TestMsg_GetAddrHeader(t *testing.T) {
 	m := NewMsg()
@@ -2881,6 +4604,65 @@ func TestMsg_GetBoundary(t *testing.T) {
 	}
 }
 
+// TestMsg_GetBoundaryGenerator tests the Msg.GetBoundaryGenerator method
+func TestMsg_GetBoundaryGenerator(t *testing.T) {
+	m := NewMsg()
+	if m.GetBoundaryGenerator() != nil {
+		t.Error("GetBoundaryGenerator failed. Expected nil generator")
+	}
+	m.SetBoundaryGenerator(func() string { return "generated" })
+	generator := m.GetBoundaryGenerator()
+	if generator == nil {
+		t.Fatal("GetBoundaryGenerator failed. Expected generator, got nil")
+	}
+	if boundary := generator(); boundary != "generated" {
+		t.Errorf("GetBoundaryGenerator failed. Expected boundary: %s, got: %s", "generated", boundary)
+	}
+}
+
+// TestMsg_MIMEEntity tests the Msg.MIMEEntity, Msg.SetMIMEEntity and Msg.SetWriteError methods, which
+// are used by middlewares that wrap the body of a Msg
+func TestMsg_MIMEEntity(t *testing.T) {
+	m := NewMsg()
+	if err := m.From("toni@tester.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	m.SetBodyString(TypeTextPlain, "This is the body\nwith LF line breaks", WithPartEncoding(NoEncoding))
+	entity, err := m.MIMEEntity()
+	if err != nil {
+		t.Fatalf("MIMEEntity failed: %s", err)
+	}
+	if !bytes.HasPrefix(entity, []byte("Content-Type: text/plain")) {
+		t.Errorf("MIMEEntity failed. Expected entity to start with the Content-Type, got: %s", entity)
+	}
+	if bytes.Contains(entity, []byte("From:")) {
+		t.Errorf("MIMEEntity failed. Expected entity without non-MIME header fields, got: %s", entity)
+	}
+	if !bytes.Contains(entity, []byte("This is the body\r\nwith LF line breaks")) {
+		t.Errorf("MIMEEntity failed. Expected canonicalized body, got: %s", entity)
+	}
+
+	clone := m.Clone()
+	clone.SetMIMEEntity([]byte("Content-Type: text/plain\r\n\r\nWrapped body\r\n"))
+	buffer := bytes.Buffer{}
+	if _, err = clone.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buffer.String(), "Wrapped body") ||
+		strings.Contains(buffer.String(), "This is the body") {
+		t.Errorf("SetMIMEEntity failed. Expected wrapped body, got: %s", buffer.String())
+	}
+
+	clone = m.Clone()
+	clone.SetWriteError(errors.New("signing failed"))
+	if _, err = clone.WriteTo(io.Discard); err == nil || !strings.Contains(err.Error(), "signing failed") {
+		t.Errorf("SetWriteError failed. Expected error: %s, got: %v", "signing failed", err)
+	}
+	if _, err = m.WriteTo(io.Discard); err != nil {
+		t.Errorf("SetWriteError modified the original Msg: %s", err)
+	}
+}
+
 // TestMsg_AttachEmbedReader_consecutive tests the Msg.AttachReader and Msg.EmbedReader
 // methods with consecutive calls to Msg.WriteTo to make sure the attachments are not
 // lost (see Github issue #110)
@@ -2953,6 +4735,69 @@ func TestMsg_AttachEmbedReadSeeker_consecutive(t *testing.T) {
 	}
 }
 
+// TestMsg_AttachEML tests the Msg.AttachEML and Msg.AttachMessage methods
+func TestMsg_AttachEML(t *testing.T) {
+	original, err := EMLToMsgFromString(exampleMailPlainB64WithAttachment)
+	if err != nil {
+		t.Fatalf("failed to parse EML: %s", err)
+	}
+	raw := []byte(exampleMailPlainB64WithAttachment)
+
+	forward := NewMsg()
+	forward.SetBodyString(TypeTextPlain, "Please see the forwarded message.")
+	forward.AttachEML(raw, "forwarded.eml")
+	if err = forward.AttachMessage(original); err != nil {
+		t.Fatalf("AttachMessage() failed: %s", err)
+	}
+	unencoded := NewMsg(WithEncoding(NoEncoding))
+	unencoded.Subject("Grüße: Invoice 4711/2024")
+	unencoded.SetBodyString(TypeTextPlain, "Grüße aus Köln")
+	if err = forward.AttachMessage(unencoded); err != nil {
+		t.Fatalf("AttachMessage() failed: %s", err)
+	}
+	if err = forward.AttachMessage(nil); !errors.Is(err, ErrNoMessage) {
+		t.Errorf("AttachMessage() expected error: %s, got: %v", ErrNoMessage, err)
+	}
+	buf := bytes.Buffer{}
+	if _, err = forward.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() failed: %s", err)
+	}
+	for _, want := range []string{
+		`Content-Type: message/rfc822; name="forwarded.eml"`,
+		`Content-Disposition: attachment; filename="forwarded.eml"`,
+		"Content-Transfer-Encoding: 7bit\r\n",
+		"Content-Transfer-Encoding: 8bit\r\n",
+		`filename*=UTF-8''Gr%C3%BC%C3%9Fe_%20Invoice%204711_2024.eml`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("AttachMessage() failed. Expected output to contain %q, got: %s", want, buf.String())
+		}
+	}
+
+	parsed, err := EMLToMsgFromReader(&buf)
+	if err != nil {
+		t.Fatalf("failed to parse forwarded message: %s", err)
+	}
+	attachments := parsed.GetAttachments()
+	if len(attachments) != 3 {
+		t.Fatalf("AttachEML() failed. Expected 3 attachments, got: %d", len(attachments))
+	}
+	content, err := attachments[0].GetContent()
+	if err != nil {
+		t.Fatalf("GetContent() failed: %s", err)
+	}
+	if !bytes.Equal(content, raw) {
+		t.Errorf("AttachEML() failed. Expected the original bytes, got: %q", content)
+	}
+	if attachments[0].GetContentType() != TypeMessageRFC822 {
+		t.Errorf("AttachEML() failed. Expected content type: %s, got: %s", TypeMessageRFC822,
+			attachments[0].GetContentType())
+	}
+	if attachments[1].Name != "Example mail __ plain text base64 with attachment.eml" {
+		t.Errorf("AttachMessage() failed. Unexpected attachment name: %q", attachments[1].Name)
+	}
+}
+
 // TestMsg_AttachReadSeeker tests the Msg.AttachReadSeeker method
 func TestMsg_AttachReadSeeker(t *testing.T) {
 	m := NewMsg()
@@ -2982,6 +4827,81 @@ func TestMsg_AttachReadSeeker(t *testing.T) {
 	}
 }
 
+// streamObserver is an io.ReadSeeker that records how many bytes have been written to an output
+// buffer at the time half of its content has been read
+type streamObserver struct {
+	reader      *bytes.Reader
+	output      *bytes.Buffer
+	outputAtMid int
+	read        int
+}
+
+func (s *streamObserver) Read(p []byte) (int, error) {
+	n, err := s.reader.Read(p)
+	s.read += n
+	if s.outputAtMid == 0 && s.read >= int(s.reader.Size())/2 {
+		s.outputAtMid = s.output.Len()
+	}
+	return n, err
+}
+
+func (s *streamObserver) Seek(offset int64, whence int) (int64, error) {
+	return s.reader.Seek(offset, whence)
+}
+
+// TestMsg_AttachReadSeeker_streaming tests that attachments from an io.ReadSeeker are encoded on the fly
+func TestMsg_AttachReadSeeker_streaming(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 1024*64)
+	output := &bytes.Buffer{}
+	reader := &streamObserver{reader: bytes.NewReader(content), output: output}
+
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "This is the body")
+	m.AttachReadSeeker("large.bin", reader)
+	if _, err := m.WriteTo(output); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if reader.outputAtMid < len(content)/4 {
+		t.Errorf("AttachReadSeeker() failed. Expected attachment to be streamed, but only %d bytes were "+
+			"written after reading half of the content", reader.outputAtMid)
+	}
+
+	first := output.String()
+	for _, line := range strings.Split(first, "\r\n") {
+		if len(line) > MaxBodyLength {
+			t.Fatalf("AttachReadSeeker() failed. Line exceeds %d characters: %d", MaxBodyLength, len(line))
+		}
+	}
+	parsed, err := EMLToMsgFromString(first)
+	if err != nil {
+		t.Fatalf("failed to parse written message: %s", err)
+	}
+	if len(parsed.attachments) != 1 {
+		t.Fatalf("AttachReadSeeker() failed. Expected 1 attachment, got: %d", len(parsed.attachments))
+	}
+	buffer := &bytes.Buffer{}
+	if _, err = parsed.attachments[0].Writer(buffer); err != nil {
+		t.Fatalf("failed to write attachment: %s", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), content) {
+		t.Error("AttachReadSeeker() failed. Decoded attachment does not match original content")
+	}
+
+	// Writing the message again must rewind the reader and produce the same attachment
+	reader.outputAtMid, reader.read = 0, 0
+	if _, err = reader.Seek(1024, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek reader: %s", err)
+	}
+	output.Reset()
+	if _, err = m.WriteTo(output); err != nil {
+		t.Fatalf("failed to write message a second time: %s", err)
+	}
+	if output.Len() != len(first) {
+		t.Errorf("AttachReadSeeker() failed. Expected second write to have length %d, got: %d", len(first),
+			output.Len())
+	}
+}
+
 // TestMsg_EmbedReadSeeker tests the Msg.EmbedReadSeeker method
 func TestMsg_EmbedReadSeeker(t *testing.T) {
 	m := NewMsg()
@@ -3190,17 +5110,21 @@ func TestMsg_BccFromString(t *testing.T) {
 
 // TestMsg_checkUserAgent tests the checkUserAgent method of the Msg
 func TestMsg_checkUserAgent(t *testing.T) {
+	defaultUserAgent := fmt.Sprintf("go-mail v%s // https://github.com/wneessen/go-mail", VERSION)
 	tests := []struct {
 		name               string
 		noDefaultUserAgent bool
+		noDefaultXMailer   bool
 		genHeader          map[Header][]string
 		wantUserAgent      string
+		wantXMailer        string
 		sf                 bool
 	}{
 		{
 			name:               "check default user agent",
 			noDefaultUserAgent: false,
-			wantUserAgent:      fmt.Sprintf("go-mail v%s // https://github.com/wneessen/go-mail", VERSION),
+			wantUserAgent:      defaultUserAgent,
+			wantXMailer:        defaultUserAgent,
 			sf:                 false,
 		},
 		{
@@ -3209,6 +5133,13 @@ func TestMsg_checkUserAgent(t *testing.T) {
 			wantUserAgent:      "",
 			sf:                 true,
 		},
+		{
+			name:             "check no default x-mailer",
+			noDefaultXMailer: true,
+			wantUserAgent:    defaultUserAgent,
+			wantXMailer:      "",
+			sf:               false,
+		},
 		{
 			name:               "check if ua and xm is already set",
 			noDefaultUserAgent: false,
@@ -3217,6 +5148,7 @@ func TestMsg_checkUserAgent(t *testing.T) {
 				HeaderXMailer:   {"custom XM"},
 			},
 			wantUserAgent: "custom UA",
+			wantXMailer:   "custom XM",
 			sf:            false,
 		},
 	}
@@ -3224,6 +5156,7 @@ func TestMsg_checkUserAgent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			msg := &Msg{
 				noDefaultUserAgent: tt.noDefaultUserAgent,
+				noDefaultXMailer:   tt.noDefaultXMailer,
 				genHeader:          tt.genHeader,
 			}
 			msg.checkUserAgent()
@@ -3234,6 +5167,13 @@ func TestMsg_checkUserAgent(t *testing.T) {
 			if gotUserAgent != tt.wantUserAgent && !tt.sf {
 				t.Errorf("UserAgent got = %v, want = %v", gotUserAgent, tt.wantUserAgent)
 			}
+			gotXMailer := ""
+			if val, ok := msg.genHeader[HeaderXMailer]; ok {
+				gotXMailer = val[0]
+			}
+			if gotXMailer != tt.wantXMailer && !tt.sf {
+				t.Errorf("XMailer got = %v, want = %v", gotXMailer, tt.wantXMailer)
+			}
 		})
 	}
 }
@@ -3246,6 +5186,113 @@ func TestNewMsgWithNoDefaultUserAgent(t *testing.T) {
 	}
 }
 
+// TestNewMsgWithNoDefaultXMailer tests WithNoDefaultXMailer
+func TestNewMsgWithNoDefaultXMailer(t *testing.T) {
+	m := NewMsg(WithNoDefaultXMailer())
+	if m.noDefaultXMailer != true {
+		t.Errorf("WithNoDefaultXMailer() failed. Expected: %t, got: %t", true, false)
+	}
+	buffer := bytes.Buffer{}
+	if _, err := m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buffer.String(), "User-Agent: go-mail") {
+		t.Errorf("WithNoDefaultXMailer() failed. Expected default User-Agent header, got: %s", buffer.String())
+	}
+	if strings.Contains(buffer.String(), "X-Mailer:") {
+		t.Errorf("WithNoDefaultXMailer() failed. Expected no X-Mailer header, got: %s", buffer.String())
+	}
+}
+
+// TestNewMsgWithForceMultipart tests that a single-part Msg is only rendered as multipart message if
+// WithForceMultipart is set
+func TestNewMsgWithForceMultipart(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []MsgOption
+		multipart bool
+	}{
+		{"single-part by default", nil, false},
+		{"multipart with WithForceMultipart", []MsgOption{WithForceMultipart()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]MsgOption{WithEncoding(EncodingB64), WithBoundary("testboundary")}, tt.options...)
+			m := NewMsg(options...)
+			m.SetBodyString(TypeTextPlain, "This is a single-part message")
+			buffer := bytes.Buffer{}
+			if _, err := m.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			message := strings.SplitN(buffer.String(), DoubleNewLine, 2)
+			header, body := message[0], message[1]
+			encoded := base64.StdEncoding.EncodeToString([]byte("This is a single-part message"))
+			if !strings.Contains(body, encoded) {
+				t.Errorf("expected base64 encoded body %q, got: %s", encoded, buffer.String())
+			}
+			if !tt.multipart {
+				if !strings.Contains(header, "Content-Type: text/plain; charset=UTF-8\r\n"+
+					"Content-Transfer-Encoding: base64") {
+					t.Errorf("expected top-level text/plain Content-Type, got: %s", buffer.String())
+				}
+				if strings.Contains(buffer.String(), "testboundary") {
+					t.Errorf("expected no boundary in single-part message, got: %s", buffer.String())
+				}
+				return
+			}
+			if !strings.Contains(header, "Content-Type: multipart/mixed;\r\n boundary=") ||
+				!strings.Contains(body, "--testboundary\r\n") ||
+				!strings.Contains(body, "Content-Type: text/plain; charset=UTF-8\r\n") {
+				t.Errorf("expected text/plain part in multipart/mixed container, got: %s", buffer.String())
+			}
+		})
+	}
+
+	m := NewMsg(WithForceMultipart())
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	m.AddAlternativeString(TypeTextHTML, "<p>This is the HTML body</p>")
+	if m.hasMixed() {
+		t.Error("WithForceMultipart() failed. Expected no multipart/mixed container for multipart/alternative Msg")
+	}
+}
+
+// TestNewMsgWithMaxLineLength tests WithMaxLineLength and Msg.SetMaxLineLength
+func TestNewMsgWithMaxLineLength(t *testing.T) {
+	body := strings.Repeat("This line is long enough to be wrapped by the encoder äöü. ", 10)
+	for _, encoding := range []Encoding{EncodingQP, EncodingB64} {
+		for _, length := range []int{0, 60, 72, 100} {
+			m := NewMsg(WithEncoding(encoding), WithMaxLineLength(length))
+			m.SetBodyString(TypeTextPlain, body)
+			m.AddAlternativeString(TypeTextHTML, "<p>"+body+"</p>")
+			buffer := bytes.Buffer{}
+			if _, err := m.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			want := normalizeLineLength(length)
+			longest := 0
+			rawBody := strings.SplitN(buffer.String(), DoubleNewLine, 2)[1]
+			for _, line := range strings.Split(rawBody, SingleNewLine) {
+				if strings.HasPrefix(line, "Content-") || strings.HasPrefix(line, "--") {
+					continue
+				}
+				if len(line) > longest {
+					longest = len(line)
+				}
+			}
+			if longest != want {
+				t.Errorf("WithMaxLineLength(%d) failed for %s. Expected longest line: %d, got: %d",
+					length, encoding, want, longest)
+			}
+		}
+	}
+
+	m := NewMsg()
+	m.SetMaxLineLength(72)
+	if m.maxLineLength != 72 {
+		t.Errorf("SetMaxLineLength() failed. Expected: %d, got: %d", 72, m.maxLineLength)
+	}
+}
+
 // Fuzzing tests
 func FuzzMsg_Subject(f *testing.F) {
 	f.Add("Testsubject")
@@ -3273,3 +5320,196 @@ func FuzzMsg_From(f *testing.F) {
 		m.Reset()
 	})
 }
+
+// TestMsg_SetReturnPath tests the Msg.SetReturnPath and Msg.GetReturnPath methods
+func TestMsg_SetReturnPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{"valid address", "bounces@example.com", "<bounces@example.com>", "bounces@example.com", false},
+		{"address with name", `"Bounces" <bounces@example.com>`, "<bounces@example.com>", "bounces@example.com", false},
+		{"null reverse-path", "<>", "<>", "", false},
+		{"empty address", "", "<>", "", false},
+		{"invalid address", "invalid", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			err := m.SetReturnPath(tt.address)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SetReturnPath() with %q expected error, got nil", tt.address)
+				}
+				if len(m.GetGenHeader(HeaderReturnPath)) != 0 {
+					t.Errorf("SetReturnPath() with %q expected no header to be set", tt.address)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetReturnPath() failed: %s", err)
+			}
+			if got := m.GetGenHeader(HeaderReturnPath); len(got) != 1 || got[0] != tt.header {
+				t.Errorf("SetReturnPath() failed. Expected header: %s, got: %v", tt.header, got)
+			}
+			if got := m.GetReturnPath(); got != tt.want {
+				t.Errorf("GetReturnPath() failed. Expected: %q, got: %q", tt.want, got)
+			}
+		})
+	}
+	t.Run("not changed by the envelope on send", func(t *testing.T) {
+		client, err := NewMemoryClient()
+		if err != nil {
+			t.Fatalf("NewMemoryClient failed: %s", err)
+		}
+		m := newPoolTestMsg(t)
+		if err = m.SetReturnPath("archive@domain.tld"); err != nil {
+			t.Fatalf("SetReturnPath() failed: %s", err)
+		}
+		if err = m.EnvelopeFrom("bounces@domain.tld"); err != nil {
+			t.Fatalf("failed to set envelope from address: %s", err)
+		}
+		if err = client.DialAndSend(m); err != nil {
+			t.Fatalf("DialAndSend failed: %s", err)
+		}
+		messages := client.Messages()
+		if len(messages) != 1 {
+			t.Fatalf("DialAndSend failed. Expected 1 delivered message, got: %d", len(messages))
+		}
+		if messages[0].EnvelopeFrom != "bounces@domain.tld" {
+			t.Errorf("DialAndSend failed. Expected envelope from: bounces@domain.tld, got: %s",
+				messages[0].EnvelopeFrom)
+		}
+		if !bytes.HasPrefix(messages[0].Data, []byte("Return-Path: <archive@domain.tld>\r\n")) {
+			t.Errorf("DialAndSend failed. Expected Return-Path to be preserved, got: %s", messages[0].Data)
+		}
+	})
+}
+
+// TestMsg_SetSender tests the Msg.SetSender method and its effect on the envelope from address
+func TestMsg_SetSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  string
+		want    string
+		wantErr bool
+	}{
+		{"valid address", "list@example.com", "<list@example.com>", false},
+		{"valid address with name", `"Mailing List" <list@example.com>`, `"Mailing List" <list@example.com>`, false},
+		{"address list", "list@example.com, other@example.com", "", true},
+		{"invalid address", "invalid", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			if err := m.From("author@example.com"); err != nil {
+				t.Fatalf("failed to set FROM address: %s", err)
+			}
+			err := m.SetSender(tt.sender)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SetSender() with %q expected error, got nil", tt.sender)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetSender() failed: %s", err)
+			}
+			if got := m.GetAddrHeaderString(HeaderSender); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("SetSender() failed. Expected header: %s, got: %v", tt.want, got)
+			}
+			sender, err := m.GetSender(false)
+			if err != nil {
+				t.Fatalf("GetSender() failed: %s", err)
+			}
+			if sender != "list@example.com" {
+				t.Errorf("GetSender() failed. Expected sender address: list@example.com, got: %s", sender)
+			}
+			buf := bytes.Buffer{}
+			if _, err = m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			if !strings.Contains(buf.String(), "Sender: "+tt.want+"\r\n") {
+				t.Errorf("SetSender() failed. Sender header not found in message: %s", buf.String())
+			}
+			if err = m.EnvelopeFrom("bounce@example.com"); err != nil {
+				t.Fatalf("failed to set envelope FROM address: %s", err)
+			}
+			if sender, _ = m.GetSender(false); sender != "bounce@example.com" {
+				t.Errorf("GetSender() failed. Expected envelope from to take precedence, got: %s", sender)
+			}
+		})
+	}
+}
+
+// TestMsg_SetGenHeaderEncoded tests the Msg.SetGenHeaderEncoded method and the folding of the
+// encoded-words at the maximum header length
+func TestMsg_SetGenHeaderEncoded(t *testing.T) {
+	subject := "Счёт за октябрь 📎 — пожалуйста, оплатите его до конца месяца, спасибо! 😀😀😀"
+	tests := []struct {
+		name     string
+		encoding HeaderEncoding
+		value    string
+		prefix   string
+	}{
+		{"Q encoding", HeaderEncodingQ, subject, "=?UTF-8?q?"},
+		{"B encoding", HeaderEncodingB, subject, "=?UTF-8?b?"},
+		{"unknown encoding uses default", "X", subject, "=?UTF-8?q?"},
+		{"ASCII value", HeaderEncodingB, "This is a plain subject", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			m.SetGenHeaderEncoded(HeaderSubject, tt.encoding, tt.value)
+			m.SetBodyString(TypeTextPlain, "This is the body")
+			buf := bytes.Buffer{}
+			if _, err := m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			header := buf.String()[:strings.Index(buf.String(), "\r\n\r\n")+2]
+			var subjectHeader strings.Builder
+			inSubject := false
+			for _, line := range strings.Split(header, "\r\n") {
+				if len(line) > MaxHeaderLength {
+					t.Errorf("SetGenHeaderEncoded failed. Header line exceeds %d chars: %q", MaxHeaderLength, line)
+				}
+				switch {
+				case strings.HasPrefix(line, "Subject:"):
+					inSubject = true
+					subjectHeader.WriteString(strings.TrimPrefix(line, "Subject:"))
+				case inSubject && strings.HasPrefix(line, " "):
+					subjectHeader.WriteString(line)
+				default:
+					inSubject = false
+				}
+			}
+
+			decoder := mime.WordDecoder{}
+			for _, word := range strings.Fields(subjectHeader.String()) {
+				if !strings.HasPrefix(word, tt.prefix) {
+					t.Errorf("SetGenHeaderEncoded failed. Expected encoded-word prefix %q, got: %s", tt.prefix, word)
+				}
+				if tt.prefix == "" {
+					continue
+				}
+				decoded, err := decoder.Decode(word)
+				if err != nil {
+					t.Fatalf("failed to decode encoded-word %q: %s", word, err)
+				}
+				if !utf8.ValidString(decoded) {
+					t.Errorf("SetGenHeaderEncoded failed. Multibyte character split across encoded-words: %q", word)
+				}
+			}
+			decoded, err := decoder.DecodeHeader(strings.TrimSpace(subjectHeader.String()))
+			if err != nil {
+				t.Fatalf("failed to decode Subject header: %s", err)
+			}
+			if decoded != tt.value {
+				t.Errorf("SetGenHeaderEncoded failed. Expected subject: %s, got: %s", tt.value, decoded)
+			}
+		})
+	}
+}
@@ -11,13 +11,17 @@ import (
 	"io"
 	"mime"
 	"mime/multipart"
-	"mime/quotedprintable"
 	"net/textproto"
-	"path/filepath"
 	"sort"
 	"strings"
 )
 
+const (
+	// rfc2231SegmentLength is the maximum length of a single segment of an RFC 2231 encoded parameter
+	// value, before the value is split into multiple continuation parameters.
+	rfc2231SegmentLength = 60
+)
+
 const (
 	// MaxHeaderLength defines the maximum line length for a mail header.
 	//
@@ -27,6 +31,16 @@ const (
 	//   - https://datatracker.ietf.org/doc/html/rfc2047
 	MaxHeaderLength = 76
 
+	// DefaultHeaderFoldColumn defines the default column at which long header lines are folded.
+	//
+	// This constant follows the recommendation of RFC 5322, which suggests that a line of a header
+	// field should not be longer than 78 characters, excluding the CRLF. Previous versions folded
+	// header lines at MaxHeaderLength (76 characters), which can be restored via WithHeaderFolding.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.1.1
+	DefaultHeaderFoldColumn = 78
+
 	// MaxBodyLength defines the maximum line length for the mail body.
 	//
 	// This constant follows the recommendation of RFC 2047, which suggests a maximum length of 76 characters.
@@ -35,6 +49,16 @@ const (
 	//   - https://datatracker.ietf.org/doc/html/rfc2047
 	MaxBodyLength = 76
 
+	// MinBodyLength defines the minimum line length for an encoded mail body.
+	//
+	// This is the shortest line length that still allows a complete base64 quantum or an encoded
+	// quoted-printable "=XX" sequence followed by a soft line break on a single line.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.7
+	//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.8
+	MinBodyLength = 4
+
 	// SingleNewLine represents a single newline character sequence ("\r\n").
 	//
 	// This constant can be used by the msgWriter to issue a carriage return when writing mail content.
@@ -57,6 +81,8 @@ type msgWriter struct {
 	depth           int8
 	encoder         mime.WordEncoder
 	err             error
+	foldColumn      int
+	maxLineLength   int
 	multiPartWriter [3]*multipart.Writer
 	partWriter      io.Writer
 	writer          io.Writer
@@ -85,6 +111,39 @@ func (mw *msgWriter) Write(payload []byte) (int, error) {
 	return n, mw.err
 }
 
+// progressWriter is an io.Writer that reports the progress of the writing process.
+//
+// This struct wraps an io.Writer, counts the bytes written to it and invokes the progress callback with the
+// total number of bytes written so far after each write.
+type progressWriter struct {
+	// progress is the callback that is invoked with the total number of bytes written after each write.
+	progress func(written int64)
+	// writer is the underlying io.Writer.
+	writer io.Writer
+	// written is the total number of bytes written to the underlying io.Writer.
+	written int64
+}
+
+// Write implements the io.Writer interface for progressWriter.
+//
+// This method writes the provided payload to the underlying writer and invokes the progress callback with
+// the total number of bytes written, if any bytes were written.
+//
+// Parameters:
+//   - payload: A byte slice containing the data to be written.
+//
+// Returns:
+//   - The number of bytes successfully written.
+//   - An error if the writing process fails.
+func (pw *progressWriter) Write(payload []byte) (int, error) {
+	n, err := pw.writer.Write(payload)
+	if n > 0 {
+		pw.written += int64(n)
+		pw.progress(pw.written)
+	}
+	return n, err
+}
+
 // writeMsg formats the message and writes it to the msgWriter's io.Writer.
 //
 // This method handles the process of writing the message headers and body content, including handling
@@ -104,17 +163,30 @@ func (mw *msgWriter) writeMsg(msg *Msg) {
 	mw.writeGenHeader(msg)
 	mw.writePreformattedGenHeader(msg)
 
-	// Set the FROM header (or envelope FROM if FROM is empty)
+	// Set the FROM header (or envelope FROM if FROM is empty). Multiple FROM addresses are only written if
+	// they have been set for the FROM header itself
 	hasFrom := true
 	from, ok := msg.addrHeader[HeaderFrom]
 	if !ok || (len(from) == 0 || from == nil) {
 		from, ok = msg.addrHeader[HeaderEnvelopeFrom]
-		if !ok || (len(from) == 0 || from == nil) {
+		if !ok || (len(from) == 0 || from == nil) || msg.hasNullEnvelopeFrom() {
 			hasFrom = false
 		}
+		if hasFrom {
+			from = from[:1]
+		}
 	}
 	if hasFrom && (len(from) > 0 && from[0] != nil) {
-		mw.writeHeader(Header(HeaderFrom), from[0].String())
+		var val []string
+		for _, addr := range from {
+			if addr != nil {
+				val = append(val, addr.String())
+			}
+		}
+		mw.writeHeader(Header(HeaderFrom), val...)
+	}
+	if sender, ok := msg.addrHeader[HeaderSender]; ok && len(sender) > 0 && sender[0] != nil {
+		mw.writeHeader(Header(HeaderSender), sender[0].String())
 	}
 
 	// Set the rest of the address headers
@@ -128,32 +200,46 @@ func (mw *msgWriter) writeMsg(msg *Msg) {
 		}
 	}
 
+	// A preformatted body replaces the whole MIME structure of the Msg
+	if msg.preformBody != nil {
+		_, _ = mw.Write(msg.preformBody)
+		return
+	}
+
+	boundary := msg.boundary
+	if boundary == "" && msg.boundaryGenerator != nil {
+		boundary = msg.boundaryGenerator()
+	}
 	if msg.hasMixed() {
-		mw.startMP(MIMEMixed, msg.boundary)
+		mw.startMP(MIMEMixed, boundary)
 		mw.writeString(DoubleNewLine)
 	}
 	if msg.hasRelated() {
-		mw.startMP(MIMERelated, msg.boundary)
+		mimeType := MIMERelated
+		if rootType := msg.relatedRootType(); rootType != "" {
+			mimeType = MIMEType(fmt.Sprintf("%s; type=%q", MIMERelated, rootType))
+		}
+		mw.startMP(mimeType, boundary)
 		mw.writeString(DoubleNewLine)
 	}
 	if msg.hasAlt() {
-		mw.startMP(MIMEAlternative, msg.boundary)
+		mw.startMP(MIMEAlternative, boundary)
 		mw.writeString(DoubleNewLine)
 	}
 	if msg.hasPGPType() {
 		switch msg.pgptype {
 		case PGPEncrypt:
 			mw.startMP(`encrypted; protocol="application/pgp-encrypted"`,
-				msg.boundary)
+				boundary)
 		case PGPSignature:
 			mw.startMP(`signed; protocol="application/pgp-signature";`,
-				msg.boundary)
+				boundary)
 		default:
 		}
 		mw.writeString(DoubleNewLine)
 	}
 
-	for _, part := range msg.parts {
+	for _, part := range msg.alternativeParts() {
 		if !part.isDeleted {
 			mw.writePart(part, msg.charset)
 		}
@@ -179,7 +265,11 @@ func (mw *msgWriter) writeMsg(msg *Msg) {
 // writeGenHeader writes out all generic headers to the msgWriter.
 //
 // This function extracts all generic headers from the provided Msg object, sorts them, and writes them
-// to the msgWriter in alphabetical order.
+// to the msgWriter in alphabetical order. A generic "Bcc" header is never written, since the "Bcc"
+// recipients are only used for the SMTP envelope. The "Return-Path" header is written first, since it
+// is a trace field that belongs at the top of the header section. A generic "Content-Type" header (e.g.
+// from an imported EML) is skipped if the message has any MIME content, since the Content-Type is then
+// written by the parts themselves and a second one would make the message ambiguous.
 //
 // Parameters:
 //   - msg: The Msg object containing the headers to be written.
@@ -189,7 +279,29 @@ func (mw *msgWriter) writeGenHeader(msg *Msg) {
 		keys = append(keys, string(key))
 	}
 	sort.Strings(keys)
+	// The Content-Type of the message is derived from its MIME structure, a generic Content-Type header
+	// (e.g. from an imported EML) would duplicate it
+	hasMIMEContent := msg.preformBody != nil || len(msg.parts) > 0 || len(msg.embeds) > 0 ||
+		len(msg.attachments) > 0
+	// The Return-Path and the Received headers are trace fields and therefore written at the top of the
+	// header section. Each Received header is a separate field, so that the order of the hops is kept
+	if returnPath, ok := msg.genHeader[HeaderReturnPath]; ok {
+		mw.writeHeader(HeaderReturnPath, returnPath...)
+	}
+	for _, received := range msg.genHeader[HeaderReceived] {
+		mw.writeHeader(HeaderReceived, received)
+	}
 	for _, key := range keys {
+		// The Bcc recipients must never be disclosed in the transmitted message
+		if strings.EqualFold(key, HeaderBcc.String()) {
+			continue
+		}
+		if key == HeaderReturnPath.String() || key == HeaderReceived.String() {
+			continue
+		}
+		if hasMIMEContent && strings.EqualFold(key, HeaderContentType.String()) {
+			continue
+		}
 		mw.writeHeader(Header(key), msg.genHeader[Header(key)]...)
 	}
 }
@@ -203,16 +315,43 @@ func (mw *msgWriter) writeGenHeader(msg *Msg) {
 //   - msg: The Msg object containing the preformatted headers to be written.
 func (mw *msgWriter) writePreformattedGenHeader(msg *Msg) {
 	for key, val := range msg.preformHeader {
+		if strings.EqualFold(key.String(), HeaderBcc.String()) {
+			continue
+		}
+		if err := mw.validateHeaderField(key.String(), val); err != nil {
+			return
+		}
 		mw.writeString(fmt.Sprintf("%s: %s%s", key, val, SingleNewLine))
 	}
 }
 
+// validateHeaderField checks a header field before it is written, so that illegal characters in the name
+// or the value of the header field cannot be used to inject additional header fields.
+//
+// If the header field is invalid, the error is recorded in the msgWriter, so that no further data is
+// written and the error is returned by Msg.WriteTo.
+//
+// Parameters:
+//   - key: The name of the header field.
+//   - value: The value of the header field.
+//
+// Returns:
+//   - An error wrapping ErrInvalidHeader if the header field contains illegal characters; otherwise, returns nil.
+func (mw *msgWriter) validateHeaderField(key, value string) error {
+	err := validateHeaderField(key, value)
+	if err != nil && mw.err == nil {
+		mw.err = err
+	}
+	return err
+}
+
 // startMP writes a multipart beginning.
 //
 // This function initializes a multipart writer for the msgWriter using the specified MIME type and
 // boundary. It sets the Content-Type header to indicate the multipart type and writes the boundary
-// information. If a boundary is provided, it is set explicitly; otherwise, a default boundary is
-// generated. It also handles writing a new part when nested multipart structures are used.
+// information. If a boundary is provided, it is set explicitly (prefixed with the nesting depth for
+// nested multipart sections); otherwise, a default boundary is generated. It also handles writing a
+// new part when nested multipart structures are used.
 //
 // Parameters:
 //   - mimeType: The MIME type of the multipart content (e.g., "mixed", "alternative").
@@ -223,6 +362,11 @@ func (mw *msgWriter) writePreformattedGenHeader(msg *Msg) {
 func (mw *msgWriter) startMP(mimeType MIMEType, boundary string) {
 	multiPartWriter := multipart.NewWriter(mw)
 	if boundary != "" {
+		// Nested multipart sections must not share the same boundary, therefore we derive a
+		// unique boundary for each nesting level from the provided boundary
+		if mw.depth > 0 {
+			boundary = fmt.Sprintf("%d_%s", mw.depth, boundary)
+		}
 		mw.err = multiPartWriter.SetBoundary(boundary)
 	}
 
@@ -255,8 +399,9 @@ func (mw *msgWriter) stopMP() {
 // This function iterates through the list of files, setting necessary headers for each file,
 // including Content-Type, Content-Transfer-Encoding, Content-Disposition, and Content-ID
 // (if the file is an embed). It determines the appropriate MIME type for each file based on
-// its extension or the provided ContentType. It writes file headers and file content
-// to the mail body using the appropriate encoding.
+// its extension, its content (if content type detection is enabled) or the provided ContentType, and
+// adds the charset of text files.
+// It writes file headers and file content to the mail body using the appropriate encoding.
 //
 // Parameters:
 //   - files: A slice of File objects to be added to the mail body.
@@ -265,14 +410,11 @@ func (mw *msgWriter) addFiles(files []*File, isAttachment bool) {
 	for _, file := range files {
 		encoding := EncodingB64
 		if _, ok := file.getHeader(HeaderContentType); !ok {
-			mimeType := mime.TypeByExtension(filepath.Ext(file.Name))
-			if mimeType == "" {
-				mimeType = "application/octet-stream"
+			contentType := file.GetContentType().String()
+			if charset := file.GetCharset(); charset != "" {
+				contentType += "; charset=" + charset.String()
 			}
-			if file.ContentType != "" {
-				mimeType = string(file.ContentType)
-			}
-			file.setHeader(HeaderContentType, fmt.Sprintf(`%s; name="%s"`, mimeType,
+			file.setHeader(HeaderContentType, fmt.Sprintf(`%s; name="%s"`, contentType,
 				mw.encoder.Encode(mw.charset.String(), file.Name)))
 		}
 
@@ -294,8 +436,12 @@ func (mw *msgWriter) addFiles(files []*File, isAttachment bool) {
 			if isAttachment {
 				disposition = "attachment"
 			}
-			file.setHeader(HeaderContentDisposition, fmt.Sprintf(`%s; filename="%s"`,
-				disposition, mw.encoder.Encode(mw.charset.String(), file.Name)))
+			contentDisposition := fmt.Sprintf(`%s; filename="%s"`, disposition,
+				mw.encoder.Encode(mw.charset.String(), file.Name))
+			if !isASCIIString(file.Name) {
+				contentDisposition += "; " + encodeRFC2231Param("filename", file.Name)
+			}
+			file.setHeader(HeaderContentDisposition, contentDisposition)
 		}
 
 		if !isAttachment {
@@ -328,6 +474,13 @@ func (mw *msgWriter) addFiles(files []*File, isAttachment bool) {
 // Parameters:
 //   - header: A map containing the header fields and their corresponding values for the new part.
 func (mw *msgWriter) newPart(header map[string][]string) {
+	for key, values := range header {
+		for _, value := range values {
+			if err := mw.validateHeaderField(key, value); err != nil {
+				return
+			}
+		}
+	}
 	mw.partWriter, mw.err = mw.multiPartWriter[mw.depth-1].CreatePart(header)
 }
 
@@ -335,9 +488,12 @@ func (mw *msgWriter) newPart(header map[string][]string) {
 //
 // This function writes a MIME part to the message body, setting the appropriate headers such
 // as Content-Type and Content-Transfer-Encoding. It determines the charset for the part,
-// either using the part's own charset or a fallback charset if none is specified. If the part
+// either using the part's own charset or a fallback charset if none is specified. Additional content
+// type parameters of the part are added to the Content-Type header in alphabetical order. If the part
 // is at the top level (depth 0), headers are written directly. For nested parts, it creates
-// a new MIME part with the provided headers.
+// a new MIME part with the provided headers. If the encoding of the part is EncodingAuto, the content
+// of the part is rendered before the headers are written, so that the selected encoding can be set
+// as Content-Transfer-Encoding.
 //
 // Parameters:
 //   - part: The Part object containing the data to be written.
@@ -348,8 +504,27 @@ func (mw *msgWriter) writePart(part *Part, charset Charset) {
 		partCharset = charset
 	}
 	contentType := fmt.Sprintf("%s; charset=%s", part.contentType, partCharset)
-	contentTransferEnc := part.encoding.String()
+	if len(part.contentTypeParams) > 0 {
+		params := make(map[string]string, len(part.contentTypeParams)+1)
+		for name, value := range part.contentTypeParams {
+			params[name] = value
+		}
+		params["charset"] = partCharset.String()
+		if contentType = mime.FormatMediaType(part.contentType.String(), params); contentType == "" {
+			mw.err = fmt.Errorf("invalid content type parameters for %s part", part.contentType)
+			return
+		}
+	}
+	writeFunc, encoding, err := partBody(part)
+	if err != nil {
+		mw.err = fmt.Errorf("bodyWriter function: %w", err)
+		return
+	}
+	contentTransferEnc := encoding.String()
 	if mw.depth == 0 {
+		if part.description != "" {
+			mw.writeHeader(HeaderContentDescription, part.description)
+		}
 		mw.writeHeader(HeaderContentType, contentType)
 		mw.writeHeader(HeaderContentTransferEnc, contentTransferEnc)
 		mw.writeString(SingleNewLine)
@@ -363,7 +538,39 @@ func (mw *msgWriter) writePart(part *Part, charset Charset) {
 		mimeHeader.Add(string(HeaderContentTransferEnc), contentTransferEnc)
 		mw.newPart(mimeHeader)
 	}
-	mw.writeBody(part.writeFunc, part.encoding)
+	mw.writeBody(writeFunc, encoding)
+}
+
+// partBody returns the write function and the Content-Transfer-Encoding used for the body of a Part.
+//
+// Text/plain parts with a wrap column are wrapped while they are written. If the encoding of the part
+// is EncodingAuto, the content of the part is rendered into a buffer, so that the encoding can be
+// selected based on it.
+//
+// Parameters:
+//   - part: The Part whose body is written.
+//
+// Returns:
+//   - The write function for the body of the Part.
+//   - The Encoding the body is written with.
+//   - An error if the content of the Part had to be rendered and rendering failed.
+func partBody(part *Part) (func(io.Writer) (int64, error), Encoding, error) {
+	writeFunc := part.writeFunc
+	if part.wrapAt > 0 && strings.EqualFold(part.contentType.String(), TypeTextPlain.String()) {
+		flowed := strings.EqualFold(part.GetContentTypeParam("format"), "flowed")
+		writeFunc = wrapWriteFunc(part.writeFunc, part.wrapAt, flowed,
+			flowed && strings.EqualFold(part.GetContentTypeParam("delsp"), "yes"))
+	}
+	encoding := part.encoding
+	if encoding == EncodingAuto {
+		buffer := bytes.Buffer{}
+		if _, err := writeFunc(&buffer); err != nil {
+			return nil, encoding, err
+		}
+		writeFunc = writeFuncFromBuffer(&buffer)
+		encoding = selectEncoding(buffer.Bytes())
+	}
+	return writeFunc, encoding, nil
 }
 
 // writeString writes a string into the msgWriter's io.Writer interface.
@@ -385,45 +592,62 @@ func (mw *msgWriter) writeString(s string) {
 
 // writeHeader writes a header into the msgWriter's io.Writer.
 //
-// This function writes a header key and its associated values to the msgWriter. It ensures
-// proper formatting of long headers by inserting line breaks as needed. The header values
-// are joined and split into words to ensure compliance with the maximum header length
-// (MaxHeaderLength). After processing the header, it is written to the underlying writer.
+// This function writes a header key and its associated values to the msgWriter. Long headers
+// are folded at whitespace by inserting a CRLF before it, so that no line exceeds the fold
+// column of the msgWriter, or DefaultHeaderFoldColumn if none is set. Since values are only
+// split at whitespace, a header is never folded inside an encoded word. Lines that contain an
+// encoded word are folded at MaxHeaderLength at the latest, as required by RFC 2047.
+//
+// A word that does not fit on the current line is moved to a continuation line, even if it is
+// the first word of the value. A single word that is longer than the fold column is written
+// unfolded.
 //
 // Parameters:
 //   - key: The Header key to be written.
 //   - values: A variadic parameter representing the values associated with the header.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.2.3
+//   - https://datatracker.ietf.org/doc/html/rfc2047#section-2
 func (mw *msgWriter) writeHeader(key Header, values ...string) {
+	for _, value := range values {
+		if err := mw.validateHeaderField(key.String(), value); err != nil {
+			return
+		}
+	}
 	buffer := strings.Builder{}
-	charLength := MaxHeaderLength - 2
 	buffer.WriteString(string(key))
-	charLength -= len(key)
 	if len(values) == 0 {
 		buffer.WriteString(":\r\n")
 		return
 	}
-	buffer.WriteString(": ")
-	charLength -= 2
+	buffer.WriteString(":")
 
-	fullValueStr := strings.Join(values, ", ")
-	words := strings.Split(fullValueStr, " ")
-	for i, val := range words {
-		if charLength-len(val) <= 1 {
-			buffer.WriteString(fmt.Sprintf("%s ", SingleNewLine))
-			charLength = MaxHeaderLength - 3
+	column := mw.foldColumn
+	if column <= 0 {
+		column = DefaultHeaderFoldColumn
+	}
+	lineLength := len(key) + 1
+	lineEncoded := false
+	words := strings.Split(strings.Join(values, ", "), " ")
+	for _, word := range words {
+		wordEncoded := strings.Contains(word, "=?") && strings.Contains(word, "?=")
+		limit := column
+		if (lineEncoded || wordEncoded) && limit > MaxHeaderLength {
+			limit = MaxHeaderLength
 		}
-		buffer.WriteString(val)
-		if i < len(words)-1 {
-			buffer.WriteString(" ")
-			charLength -= 1
+		if lineLength > 0 && lineLength+1+len(word) > limit {
+			buffer.WriteString(SingleNewLine)
+			lineLength = 0
+			lineEncoded = false
 		}
-		charLength -= len(val)
+		buffer.WriteString(" ")
+		buffer.WriteString(word)
+		lineLength += 1 + len(word)
+		lineEncoded = lineEncoded || wordEncoded
 	}
 
-	bufferString := buffer.String()
-	bufferString = strings.ReplaceAll(bufferString, fmt.Sprintf(" %s", SingleNewLine),
-		SingleNewLine)
-	mw.writeString(bufferString)
+	mw.writeString(buffer.String())
 	mw.writeString("\r\n")
 }
 
@@ -432,8 +656,9 @@ func (mw *msgWriter) writeHeader(key Header, values ...string) {
 // This function writes data from an io.Reader to the underlying writer using a specified
 // encoding (quoted-printable, base64, or no encoding). It handles encoding of the content
 // and manages writing the encoded data to the appropriate writer, depending on the depth
-// (whether the data is part of a multipart structure or not). It also tracks the number
-// of bytes written and manages any errors encountered during the process.
+// (whether the data is part of a multipart structure or not). Quoted-printable and base64
+// encoded lines are wrapped at the maximum line length of the msgWriter. It also tracks the
+// number of bytes written and manages any errors encountered during the process.
 //
 // Parameters:
 //   - writeFunc: A function that writes the body content to the given io.Writer.
@@ -441,38 +666,37 @@ func (mw *msgWriter) writeHeader(key Header, values ...string) {
 func (mw *msgWriter) writeBody(writeFunc func(io.Writer) (int64, error), encoding Encoding) {
 	var writer io.Writer
 	var encodedWriter io.WriteCloser
-	var n int64
 	var err error
+	if mw.err != nil {
+		return
+	}
+
+	// On the top level we write through the msgWriter itself, so that the bytes are accounted for.
+	// The part writer uses the msgWriter's Write() method, hence we don't need to count them twice
 	if mw.depth == 0 {
-		writer = mw.writer
+		writer = mw
 	}
 	if mw.depth > 0 {
 		writer = mw.partWriter
 	}
-	writeBuffer := bytes.Buffer{}
-	lineBreaker := Base64LineBreaker{}
-	lineBreaker.out = &writeBuffer
+	lineBreaker := Base64LineBreaker{lineLength: mw.maxLineLength}
+	lineBreaker.out = writer
 
+	// The body is encoded on the fly while it is written, so that large bodies and attachments
+	// (i. e. from an io.ReadSeeker) do not need to be held in memory as a whole
 	switch encoding {
 	case EncodingQP:
-		encodedWriter = quotedprintable.NewWriter(&writeBuffer)
+		encodedWriter = newQPWriter(writer, mw.maxLineLength)
 	case EncodingB64:
 		encodedWriter = base64.NewEncoder(base64.StdEncoding, &lineBreaker)
-	case NoEncoding:
-		_, err = writeFunc(&writeBuffer)
+	case NoEncoding, EncodingUSASCII:
+		_, err = writeFunc(writer)
 		if err != nil {
 			mw.err = fmt.Errorf("bodyWriter function: %w", err)
 		}
-		n, err = io.Copy(writer, &writeBuffer)
-		if err != nil && mw.err == nil {
-			mw.err = fmt.Errorf("bodyWriter io.Copy: %w", err)
-		}
-		if mw.depth == 0 {
-			mw.bytesWritten += n
-		}
 		return
 	default:
-		encodedWriter = quotedprintable.NewWriter(writer)
+		encodedWriter = newQPWriter(writer, mw.maxLineLength)
 	}
 
 	_, err = writeFunc(encodedWriter)
@@ -487,14 +711,108 @@ func (mw *msgWriter) writeBody(writeFunc func(io.Writer) (int64, error), encodin
 	if err != nil && mw.err == nil {
 		mw.err = fmt.Errorf("bodyWriter close linebreaker: %w", err)
 	}
-	n, err = io.Copy(writer, &writeBuffer)
-	if err != nil && mw.err == nil {
-		mw.err = fmt.Errorf("bodyWriter io.Copy: %w", err)
+}
+
+// normalizeLineLength returns the given line length for encoded bodies within the RFC compliant
+// limits.
+//
+// A line length of zero or less results in the default MaxBodyLength. Line lengths above
+// MaxBodyLength or below MinBodyLength are capped to the respective limit.
+//
+// Parameters:
+//   - length: The requested maximum line length.
+//
+// Returns:
+//   - The line length to use for encoding.
+func normalizeLineLength(length int) int {
+	switch {
+	case length <= 0 || length > MaxBodyLength:
+		return MaxBodyLength
+	case length < MinBodyLength:
+		return MinBodyLength
+	default:
+		return length
+	}
+}
+
+// encodeRFC2231Param returns the given parameter as RFC 2231 extended parameter with UTF-8 charset.
+//
+// All characters of the value that are not attribute characters are percent-encoded. If the encoded
+// value exceeds rfc2231SegmentLength, it is split into numbered continuation parameters, so that the
+// header can be folded between them. A percent-encoded octet is never split across two segments.
+//
+// Parameters:
+//   - name: The name of the parameter, e.g. "filename".
+//   - value: The parameter value to encode.
+//
+// Returns:
+//   - The encoded parameter string, consisting of the charset prefix and the percent-encoded value.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2231#section-3
+//   - https://datatracker.ietf.org/doc/html/rfc2231#section-4
+func encodeRFC2231Param(name, value string) string {
+	var segments []string
+	segment := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		token := string(value[i])
+		if !isRFC2231AttrChar(value[i]) {
+			token = fmt.Sprintf("%%%02X", value[i])
+		}
+		if segment.Len()+len(token) > rfc2231SegmentLength {
+			segments = append(segments, segment.String())
+			segment.Reset()
+		}
+		segment.WriteString(token)
 	}
+	segments = append(segments, segment.String())
 
-	// Since the part writer uses the WriteTo() method, we don't need to add the
-	// bytes twice
-	if mw.depth == 0 {
-		mw.bytesWritten += n
+	if len(segments) == 1 {
+		return fmt.Sprintf("%s*=UTF-8''%s", name, segments[0])
+	}
+	params := make([]string, len(segments))
+	for i, seg := range segments {
+		if i == 0 {
+			params[i] = fmt.Sprintf("%s*0*=UTF-8''%s", name, seg)
+			continue
+		}
+		params[i] = fmt.Sprintf("%s*%d*=%s", name, i, seg)
+	}
+	return strings.Join(params, "; ")
+}
+
+// isRFC2231AttrChar reports whether the given byte is an attribute character that does not need to
+// be percent-encoded in an RFC 2231 extended parameter value.
+//
+// Parameters:
+//   - char: The byte to check.
+//
+// Returns:
+//   - true if the byte can be used as is, false if it needs to be percent-encoded.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5987#section-3.2.1
+func isRFC2231AttrChar(char byte) bool {
+	switch {
+	case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$&+-.^_`|~", char) >= 0
+	}
+}
+
+// isASCIIString reports whether the given string consists of ASCII characters only.
+//
+// Parameters:
+//   - value: The string to check.
+//
+// Returns:
+//   - true if all characters of the string are ASCII characters, false otherwise.
+func isASCIIString(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] > 127 {
+			return false
+		}
 	}
+	return true
 }
@@ -6,12 +6,18 @@ package mail
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // brokenWriter implements a broken writer for io.Writer testing
@@ -154,3 +160,445 @@ func TestMsgWriter_writeMsg_PGP(t *testing.T) {
 		t.Errorf("writeMsg failed. Expected PGP encoding header but didn't find it in message output")
 	}
 }
+
+// TestMsgWriter_writeMsg_alternativeOrder tests that the alternative parts are written in the order of
+// increasing richness, regardless of the order in which they have been added
+func TestMsgWriter_writeMsg_alternativeOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []ContentType
+		want  []ContentType
+	}{
+		{
+			"plain then html", []ContentType{TypeTextPlain, TypeTextHTML},
+			[]ContentType{TypeTextPlain, TypeTextHTML},
+		},
+		{
+			"html then plain", []ContentType{TypeTextHTML, TypeTextPlain},
+			[]ContentType{TypeTextPlain, TypeTextHTML},
+		},
+		{
+			"html, amp and plain", []ContentType{TypeTextHTML, "text/x-amp-html", TypeTextPlain},
+			[]ContentType{TypeTextPlain, "text/x-amp-html", TypeTextHTML},
+		},
+		{
+			"calendar, html and plain", []ContentType{TypeTextCalendar, TypeTextHTML, TypeTextPlain},
+			[]ContentType{TypeTextPlain, TypeTextHTML, TypeTextCalendar},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg(WithBoundary("go-mail-test-boundary"))
+			_ = m.From(`"Toni Tester" <test@example.com>`)
+			_ = m.To(`"Toni Receiver" <receiver@example.com>`)
+			m.SetBodyString(tt.parts[0], "This is the body")
+			for _, contentType := range tt.parts[1:] {
+				m.AddAlternativeString(contentType, "This is the alternative body")
+			}
+			buf := bytes.Buffer{}
+			mw := &msgWriter{writer: &buf, charset: CharsetUTF8, encoder: mime.QEncoding}
+			mw.writeMsg(m)
+
+			sections := strings.Split(buf.String(), "--go-mail-test-boundary")
+			if len(sections) != len(tt.want)+2 {
+				t.Fatalf("writeMsg failed. Expected %d boundaries, got: %d", len(tt.want)+1, len(sections)-1)
+			}
+			for i, want := range tt.want {
+				header := fmt.Sprintf("Content-Type: %s; charset=UTF-8\r\n", want)
+				if !strings.Contains(sections[i+1], header) {
+					t.Errorf("writeMsg failed. Expected part %d to be %s, got: %q", i, want, sections[i+1])
+				}
+			}
+			if strings.TrimSpace(sections[len(sections)-1]) != "--" {
+				t.Errorf("writeMsg failed. Expected closing boundary, got: %q", sections[len(sections)-1])
+			}
+
+			nodes := m.PartTree().Children()
+			for i, want := range tt.want {
+				if nodes[i].ContentType() != want {
+					t.Errorf("PartTree failed. Expected node %d to be %s, got: %s", i, want, nodes[i].ContentType())
+				}
+			}
+			if m.parts[0].contentType != tt.parts[0] {
+				t.Errorf("writeMsg failed. The order of the parts of the Msg must not be changed")
+			}
+		})
+	}
+}
+
+// TestMsgWriter_addFiles_nonASCIIFilename tests that non-ASCII attachment filenames are encoded as RFC 2231
+// filename* parameter with an ASCII filename fallback
+func TestMsgWriter_addFiles_nonASCIIFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"ASCII", "invoice.pdf", ""},
+		{"Latin", "rechnung-müller.pdf", "filename*=UTF-8''rechnung-m%C3%BCller.pdf"},
+		{"Cyrillic", "счёт.pdf", "filename*=UTF-8''%D1%81%D1%87%D1%91%D1%82.pdf"},
+		{"Emoji", "report-📎.txt", "filename*=UTF-8''report-%F0%9F%93%8E.txt"},
+		{
+			"Long Cyrillic", "очень-длинное-имя-файла-для-проверки.txt",
+			"filename*0*=UTF-8''%D0%BE%D1%87%D0%B5%D0%BD%D1%8C-%D0%B4%D0%BB%D0%B8%D0%BD%D0; filename*1*=%BD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			if err := os.WriteFile(path, []byte("attachment content"), 0o600); err != nil {
+				t.Fatalf("failed to create attachment file: %s", err)
+			}
+			m := NewMsg()
+			_ = m.From(`"Toni Tester" <test@example.com>`)
+			_ = m.To(`"Toni Receiver" <receiver@example.com>`)
+			m.SetBodyString(TypeTextPlain, "This is the body")
+			m.AttachFile(path)
+			buf := bytes.Buffer{}
+			mw := &msgWriter{writer: &buf, charset: CharsetUTF8, encoder: mime.QEncoding}
+			mw.writeMsg(m)
+			if mw.err != nil {
+				t.Fatalf("writeMsg failed: %s", mw.err)
+			}
+
+			var disposition string
+			for _, line := range strings.Split(buf.String(), "\r\n") {
+				if strings.HasPrefix(line, "Content-Disposition: ") {
+					disposition = strings.TrimPrefix(line, "Content-Disposition: ")
+				}
+			}
+			if !strings.HasPrefix(disposition, `attachment; filename="`) {
+				t.Errorf("addFiles failed. Expected ASCII filename fallback, got: %s", disposition)
+			}
+			for _, char := range disposition {
+				if char > 127 {
+					t.Errorf("addFiles failed. Expected ASCII only Content-Disposition, got: %s", disposition)
+					break
+				}
+			}
+			if tt.want == "" && strings.Contains(disposition, "filename*") {
+				t.Errorf("addFiles failed. Expected no filename* for ASCII filename, got: %s", disposition)
+			}
+			if !strings.Contains(disposition, tt.want) {
+				t.Errorf("addFiles failed. Expected Content-Disposition to contain %q, got: %s", tt.want, disposition)
+			}
+			_, params, err := mime.ParseMediaType(disposition)
+			if err != nil {
+				t.Fatalf("failed to parse Content-Disposition: %s", err)
+			}
+			if params["filename"] != tt.filename {
+				t.Errorf("addFiles failed. Expected filename: %s, got: %s", tt.filename, params["filename"])
+			}
+
+			parsed, err := EMLToMsgFromString(buf.String())
+			if err != nil {
+				t.Fatalf("failed to parse message: %s", err)
+			}
+			if len(parsed.attachments) != 1 || parsed.attachments[0].Name != tt.filename {
+				t.Errorf("EMLToMsgFromString failed. Expected attachment with filename: %s", tt.filename)
+			}
+		})
+	}
+}
+
+// TestMsgWriter_writeHeader_folding tests that long header lines are folded at whitespace before the
+// fold column set via WithHeaderFolding
+func TestMsgWriter_writeHeader_folding(t *testing.T) {
+	var rcpts []string
+	for i := 1; i <= 8; i++ {
+		rcpts = append(rcpts, fmt.Sprintf("recipient-%d@example.com", i))
+	}
+	tests := []struct {
+		name   string
+		column int
+		want   string
+	}{
+		{
+			"default column", 0,
+			"To: <recipient-1@example.com>, <recipient-2@example.com>,\r\n" +
+				" <recipient-3@example.com>, <recipient-4@example.com>,\r\n" +
+				" <recipient-5@example.com>, <recipient-6@example.com>,\r\n" +
+				" <recipient-7@example.com>, <recipient-8@example.com>\r\n",
+		},
+		{
+			"wide column", 90,
+			"To: <recipient-1@example.com>, <recipient-2@example.com>, <recipient-3@example.com>,\r\n" +
+				" <recipient-4@example.com>, <recipient-5@example.com>, <recipient-6@example.com>,\r\n" +
+				" <recipient-7@example.com>, <recipient-8@example.com>\r\n",
+		},
+		{
+			"narrow column", 30,
+			"To: <recipient-1@example.com>,\r\n <recipient-2@example.com>,\r\n <recipient-3@example.com>,\r\n" +
+				" <recipient-4@example.com>,\r\n <recipient-5@example.com>,\r\n <recipient-6@example.com>,\r\n" +
+				" <recipient-7@example.com>,\r\n <recipient-8@example.com>\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := NewMsg(WithHeaderFolding(tt.column))
+			if err := message.To(rcpts...); err != nil {
+				t.Fatalf("failed to set TO addresses: %s", err)
+			}
+			message.SetBodyString(TypeTextPlain, "Test body")
+			buf := bytes.Buffer{}
+			if _, err := message.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() failed: %s", err)
+			}
+			if !strings.Contains(buf.String(), "\r\n"+tt.want) {
+				t.Errorf("WriteTo() failed. Expected To header %q, got: %s", tt.want, buf.String())
+			}
+		})
+	}
+
+	t.Run("encoded words are not split", func(t *testing.T) {
+		message := NewMsg()
+		message.SetHeaderFolding(120)
+		message.Subject(strings.Repeat("Grüße aus Köln, ", 8))
+		message.SetBodyString(TypeTextPlain, "Test body")
+		buf := bytes.Buffer{}
+		if _, err := message.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() failed: %s", err)
+		}
+		header := buf.String()[:strings.Index(buf.String(), "\r\n\r\n")]
+		for _, line := range strings.Split(header, "\r\n") {
+			if strings.Contains(line, "=?") && len(line) > MaxHeaderLength {
+				t.Errorf("WriteTo() failed. Line with encoded word exceeds %d chars: %q", MaxHeaderLength, line)
+			}
+			for _, word := range strings.Fields(line) {
+				if strings.Contains(word, "=?") && (!strings.HasPrefix(word, "=?") || !strings.HasSuffix(word, "?=")) {
+					t.Errorf("WriteTo() failed. Encoded word was split: %q", line)
+				}
+			}
+		}
+	})
+}
+
+// TestMsgWriter_writeHeader_longSubject tests that a long UTF-8 subject is split into multiple RFC 2047 encoded
+// words within the line length limit, which never split a multibyte character and decode back to the subject
+func TestMsgWriter_writeHeader_longSubject(t *testing.T) {
+	subject := []rune(strings.Repeat("📅 Planung — Grüße aus Köln 🎉 ", 10))[:200]
+	for _, encoding := range []Encoding{EncodingQP, EncodingB64} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			message := NewMsg(WithEncoding(encoding))
+			message.Subject(string(subject))
+			message.SetBodyString(TypeTextPlain, "Test body")
+			buf := bytes.Buffer{}
+			if _, err := message.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() failed: %s", err)
+			}
+			header := "\r\n" + buf.String()[:strings.Index(buf.String(), "\r\n\r\n")+2]
+			start := strings.Index(header, "\r\nSubject:") + 2
+			end := start + strings.Index(header[start:], "\r\n")
+			for strings.HasPrefix(header[end+2:], " ") {
+				end += 2 + strings.Index(header[end+2:], "\r\n")
+			}
+			lines := strings.Split(header[start:end], "\r\n")
+			if len(lines) < 2 {
+				t.Fatalf("WriteTo() failed. Expected the subject to be folded, got: %q", lines)
+			}
+
+			decoder := mime.WordDecoder{}
+			for _, line := range lines {
+				if len(line) > MaxHeaderLength {
+					t.Errorf("WriteTo() failed. Subject line exceeds %d chars: %q", MaxHeaderLength, line)
+				}
+				for _, word := range strings.Fields(strings.TrimPrefix(line, "Subject:")) {
+					if len(word) > 75 {
+						t.Errorf("WriteTo() failed. Encoded word exceeds 75 chars: %q", word)
+					}
+					decoded, err := decoder.Decode(word)
+					if err != nil {
+						t.Fatalf("failed to decode encoded word %q: %s", word, err)
+					}
+					if !utf8.ValidString(decoded) {
+						t.Errorf("WriteTo() failed. Encoded word splits a multibyte character: %q", word)
+					}
+				}
+			}
+			unfolded := strings.TrimPrefix(strings.ReplaceAll(header[start:end], "\r\n", ""), "Subject:")
+			decoded, err := decoder.DecodeHeader(strings.TrimSpace(unfolded))
+			if err != nil {
+				t.Fatalf("failed to decode subject: %s", err)
+			}
+			if decoded != string(subject) {
+				t.Errorf("WriteTo() failed. Subject does not decode back to the original.\nExpected: %q\nGot: %q",
+					string(subject), decoded)
+			}
+		})
+	}
+}
+
+// TestMsgWriter_writeMsg_headerInjection tests that line breaks in header values set by the user cannot be
+// used to inject additional header fields into the message
+func TestMsgWriter_writeMsg_headerInjection(t *testing.T) {
+	injection := "Subject\r\nBcc: attacker@evil"
+	tests := []struct {
+		name    string
+		setup   func(*Msg) error
+		wantErr bool
+	}{
+		{"subject", func(m *Msg) error { m.Subject(injection); return nil }, false},
+		{"generic header", func(m *Msg) error { m.SetGenHeader(HeaderOrganization, injection); return nil }, false},
+		{"generic header name", func(m *Msg) error { m.SetGenHeader("X-Custom\r\nBcc", "value"); return nil }, true},
+		{
+			"preformatted header", func(m *Msg) error {
+				m.SetGenHeaderPreformatted(HeaderSubject, injection)
+				return nil
+			}, true,
+		},
+		{"message id", func(m *Msg) error { m.SetMessageIDWithValue(injection); return nil }, false},
+		{"recipient", func(m *Msg) error { return m.AddTo("victim@example.com\r\nBcc: attacker@evil") }, true},
+		{"recipient name", func(m *Msg) error { return m.AddToNamed(injection, "victim@example.com") }, false},
+		{
+			"attachment name", func(m *Msg) error {
+				return m.AttachReader(injection, strings.NewReader("content"))
+			}, false,
+		},
+		{
+			"embed content id", func(m *Msg) error {
+				return m.EmbedReader("image.png", strings.NewReader("content"), WithFileContentID(injection))
+			}, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := NewMsg()
+			message.SetBodyString(TypeTextPlain, "Body")
+			err := tt.setup(message)
+			if err == nil {
+				var buf bytes.Buffer
+				_, err = message.WriteTo(&buf)
+				if strings.Contains(buf.String(), "\r\nBcc: attacker@evil") {
+					t.Errorf("header injection succeeded: %q", buf.String())
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %t, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	message := NewMsg()
+	message.SetGenHeaderPreformatted(HeaderSubject, injection)
+	if _, err := message.WriteTo(io.Discard); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("WriteTo expected error: %s, got: %v", ErrInvalidHeader, err)
+	}
+}
+
+// TestMsgWriter_addFiles_attachmentHeaders tests that the Content-ID and the Content-Description set for an
+// attachment are written to its part header
+func TestMsgWriter_addFiles_attachmentHeaders(t *testing.T) {
+	message := NewMsg()
+	message.SetBodyString(TypeTextPlain, "Body")
+	message.AttachFile("README.md", WithFileContentID("<edi-4711@partner.example.com>"),
+		WithFileDescription("Purchase order 4711"))
+	var buf bytes.Buffer
+	if _, err := message.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	for _, want := range []string{
+		"Content-Id: <edi-4711@partner.example.com>\r\n",
+		"Content-Description: Purchase order 4711\r\n",
+		`Content-Disposition: attachment; filename="README.md"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("addFiles expected attachment header %q, got: %s", want, buf.String())
+		}
+	}
+}
+
+// TestMsgWriter_writeMsg_relatedNesting tests that a Msg with alternative bodies and embedded images is
+// rendered as multipart/related with the multipart/alternative section as root part
+func TestMsgWriter_writeMsg_relatedNesting(t *testing.T) {
+	tests := []struct {
+		name     string
+		plain    bool
+		attach   bool
+		want     string
+		rootType string
+	}{
+		{
+			"plain, html and embed", true, false,
+			"multipart/related[multipart/alternative[text/plain,text/html],image/png]", "multipart/alternative",
+		},
+		{
+			"plain, html, embed and attachment", true, true,
+			"multipart/mixed[multipart/related[multipart/alternative[text/plain,text/html],image/png],text/plain]",
+			"multipart/alternative",
+		},
+		{"html and embed", false, false, "multipart/related[text/html,image/png]", "text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := NewMsg()
+			if tt.plain {
+				message.SetBodyString(TypeTextPlain, "Our logo")
+				message.AddAlternativeString(TypeTextHTML, `<p>Our logo: <img src="cid:logo.png"></p>`)
+			} else {
+				message.SetBodyString(TypeTextHTML, `<p>Our logo: <img src="cid:logo.png"></p>`)
+			}
+			if err := message.EmbedReader("logo.png", bytes.NewReader([]byte("PNG"))); err != nil {
+				t.Fatalf("failed to embed image: %s", err)
+			}
+			if tt.attach {
+				if err := message.AttachReader("terms.txt", strings.NewReader("Terms")); err != nil {
+					t.Fatalf("failed to attach file: %s", err)
+				}
+			}
+			buffer := bytes.Buffer{}
+			if _, err := message.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			parsed, err := netmail.ReadMessage(&buffer)
+			if err != nil {
+				t.Fatalf("failed to parse message: %s", err)
+			}
+			var rootTypes []string
+			structure, err := multipartStructure(parsed.Header.Get(HeaderContentType.String()), parsed.Body,
+				&rootTypes)
+			if err != nil {
+				t.Fatalf("failed to parse MIME structure: %s", err)
+			}
+			if structure != tt.want {
+				t.Errorf("unexpected MIME structure. Want: %s, got: %s", tt.want, structure)
+			}
+			if len(rootTypes) != 1 || rootTypes[0] != tt.rootType {
+				t.Errorf("expected multipart/related with type parameter %q, got: %q", tt.rootType, rootTypes)
+			}
+		})
+	}
+}
+
+// multipartStructure returns the MIME structure of the given body as string, e.g.
+// "multipart/related[text/html,image/png]", and collects the type parameters of the multipart/related
+// sections in relatedTypes
+func multipartStructure(contentType string, body io.Reader, relatedTypes *[]string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		_, err = io.Copy(io.Discard, body)
+		return mediaType, err
+	}
+	if mediaType == TypeMultipartRelated.String() {
+		*relatedTypes = append(*relatedTypes, params["type"])
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	var children []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		child, err := multipartStructure(part.Header.Get(HeaderContentType.String()), part, relatedTypes)
+		if err != nil {
+			return "", err
+		}
+		children = append(children, child)
+	}
+	return mediaType + "[" + strings.Join(children, ",") + "]", nil
+}
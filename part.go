@@ -6,7 +6,11 @@ package mail
 
 import (
 	"bytes"
+	"encoding/base64"
+	"fmt"
 	"io"
+	"mime/quotedprintable"
+	"strings"
 )
 
 // PartOption returns a function that can be used for grouping Part options
@@ -15,15 +19,21 @@ type PartOption func(*Part)
 // Part is a part of the Msg.
 //
 // This struct represents a single part of a multipart message. Each part has a content type,
-// charset, optional description, encoding, and a function to write its content to an io.Writer.
-// It also includes a flag to mark the part as deleted.
+// optional content type parameters, charset, optional description, encoding, and a function to
+// write its content to an io.Writer. It also includes a flag to mark the part as deleted and the
+// column at which a text/plain part is wrapped. The content of a Part that has been parsed from an
+// EML is kept in its transfer encoded form and only decoded when it is written.
 type Part struct {
-	contentType ContentType
-	charset     Charset
-	description string
-	encoding    Encoding
-	isDeleted   bool
-	writeFunc   func(io.Writer) (int64, error)
+	contentType       ContentType
+	contentTypeParams map[string]string
+	charset           Charset
+	description       string
+	encoding          Encoding
+	encodedContent    []byte
+	encodedWith       Encoding
+	isDeleted         bool
+	wrapAt            int
+	writeFunc         func(io.Writer) (int64, error)
 }
 
 // GetContent executes the WriteFunc of the Part and returns the content as a byte slice.
@@ -62,6 +72,17 @@ func (p *Part) GetContentType() ContentType {
 	return p.contentType
 }
 
+// GetContentTypeParam returns the value of the given parameter of the "Content-Type" header of the Part.
+//
+// Parameters:
+//   - name: The case-insensitive name of the parameter, e.g. "method" for a text/calendar Part.
+//
+// Returns:
+//   - The value of the parameter, or an empty string if the parameter is not set.
+func (p *Part) GetContentTypeParam(name string) string {
+	return p.contentTypeParams[strings.ToLower(name)]
+}
+
 // GetEncoding returns the currently set Encoding of the Part.
 //
 // This function returns the Encoding that is currently set for the Part.
@@ -72,6 +93,14 @@ func (p *Part) GetEncoding() Encoding {
 	return p.encoding
 }
 
+// GetWrapAt returns the column at which the content of the Part is wrapped.
+//
+// Returns:
+//   - The column at which the content of the Part is wrapped, or 0 if wrapping is disabled.
+func (p *Part) GetWrapAt() int {
+	return p.wrapAt
+}
+
 // GetWriteFunc returns the currently set WriteFunc of the Part.
 //
 // This function returns the WriteFunc that is currently set for the Part, which writes
@@ -104,6 +133,7 @@ func (p *Part) GetDescription() string {
 func (p *Part) SetContent(content string) {
 	buffer := bytes.NewBufferString(content)
 	p.writeFunc = writeFuncFromBuffer(buffer)
+	p.encodedContent = nil
 }
 
 // SetContentType overrides the ContentType of the Part.
@@ -116,6 +146,26 @@ func (p *Part) SetContentType(contentType ContentType) {
 	p.contentType = contentType
 }
 
+// SetContentTypeParam sets a parameter of the "Content-Type" header of the Part.
+//
+// This function adds the given parameter to the "Content-Type" header of the Part, e.g. the "method"
+// parameter of a text/calendar Part, or replaces its value if it is already set. The "charset"
+// parameter is always derived from the Charset of the Part and cannot be set this way.
+//
+// Parameters:
+//   - name: The case-insensitive name of the parameter.
+//   - value: The value of the parameter. It is quoted in the header if required.
+func (p *Part) SetContentTypeParam(name, value string) {
+	name = strings.ToLower(name)
+	if name == "" || name == "charset" {
+		return
+	}
+	if p.contentTypeParams == nil {
+		p.contentTypeParams = make(map[string]string)
+	}
+	p.contentTypeParams[name] = value
+}
+
 // SetCharset overrides the Charset of the Part.
 //
 // This function sets a new Charset for the Part, replacing the existing one.
@@ -128,12 +178,21 @@ func (p *Part) SetCharset(charset Charset) {
 
 // SetEncoding creates a new mime.WordEncoder based on the encoding setting of the message.
 //
-// This function sets a new Encoding for the Part, replacing the existing one.
+// This function sets a new Encoding for the Part, replacing the existing one. With EncodingAuto, the
+// encoding is selected based on the content of the Part when the Msg is written.
 //
 // Parameters:
 //   - encoding: The new Encoding to be set for the Part.
-func (p *Part) SetEncoding(encoding Encoding) {
+//
+// Returns:
+//   - An error if the Encoding is not supported (ErrInvalidEncoding). The currently set Encoding is
+//     kept in this case.
+func (p *Part) SetEncoding(encoding Encoding) error {
+	if !isValidEncoding(encoding) {
+		return fmt.Errorf("%w: %q", ErrInvalidEncoding, encoding)
+	}
 	p.encoding = encoding
+	return nil
 }
 
 // SetDescription overrides the Content-Description of the Part.
@@ -146,6 +205,19 @@ func (p *Part) SetDescription(description string) {
 	p.description = description
 }
 
+// SetWrapAt sets the column at which the content of the Part is wrapped.
+//
+// See WithPartWrapAt for details on how the content is wrapped.
+//
+// Parameters:
+//   - column: The column at which the content is wrapped. A column of 0 or less disables wrapping.
+func (p *Part) SetWrapAt(column int) {
+	if column < 0 {
+		column = 0
+	}
+	p.wrapAt = column
+}
+
 // SetWriteFunc overrides the WriteFunc of the Part.
 //
 // This function sets a new WriteFunc for the Part, replacing the existing one. The WriteFunc
@@ -156,6 +228,7 @@ func (p *Part) SetDescription(description string) {
 //     the number of bytes written and an error (if any).
 func (p *Part) SetWriteFunc(writeFunc func(io.Writer) (int64, error)) {
 	p.writeFunc = writeFunc
+	p.encodedContent = nil
 }
 
 // Delete removes the current part from the parts list of the Msg by setting the isDeleted flag to true.
@@ -185,7 +258,8 @@ func WithPartCharset(charset Charset) PartOption {
 // WithPartEncoding overrides the default Part encoding.
 //
 // This function returns a PartOption that allows the encoding of a Part to be overridden
-// with the specified Encoding.
+// with the specified Encoding. An unsupported Encoding is ignored and the encoding of the Part
+// remains unchanged.
 //
 // Parameters:
 //   - encoding: The Encoding to be set for the Part.
@@ -194,6 +268,9 @@ func WithPartCharset(charset Charset) PartOption {
 //   - A PartOption function that sets the Part's encoding.
 func WithPartEncoding(encoding Encoding) PartOption {
 	return func(p *Part) {
+		if !isValidEncoding(encoding) {
+			return
+		}
 		p.encoding = encoding
 	}
 }
@@ -213,3 +290,99 @@ func WithPartContentDescription(description string) PartOption {
 		p.description = description
 	}
 }
+
+// WithPartContentTypeParam sets a parameter of the "Content-Type" header of the Part.
+//
+// This function returns a PartOption that adds the given parameter to the "Content-Type" header of
+// the Part. This allows to compose parts that require additional parameters, e.g. a calendar invite
+// with the content type text/calendar and the "method" parameter set to "REQUEST".
+//
+// Parameters:
+//   - name: The case-insensitive name of the parameter.
+//   - value: The value of the parameter.
+//
+// Returns:
+//   - A PartOption function that sets the parameter of the Part's Content-Type.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-5.1
+//   - https://datatracker.ietf.org/doc/html/rfc5545#section-8.1
+func WithPartContentTypeParam(name, value string) PartOption {
+	return func(p *Part) {
+		p.SetContentTypeParam(name, value)
+	}
+}
+
+// WithPartWrapAt wraps the lines of a text/plain Part at the given column.
+//
+// This function returns a PartOption that enables the wrapping of the content of a text/plain Part,
+// which improves the rendering in mail clients that do not wrap long lines themselves, like old
+// terminal clients. The lines are wrapped on word boundaries before the content is encoded, so that
+// encoded sequences, like the "=XX" sequences of the quoted-printable encoding, are never split. Words
+// that are longer than the column are not split. If the "format" parameter of the Part's Content-Type
+// is set to "flowed", e.g. via WithPartContentTypeParam, the lines are wrapped with soft line breaks
+// according to RFC 3676, i.e. each wrapped line ends with a trailing space and lines are space-stuffed
+// where required. If the "delsp" parameter is set to "yes" in addition, each soft line break gets an
+// extra space, which is deleted again when the lines are unflowed. Otherwise, the lines are hard-wrapped.
+// Wrapping is disabled by default.
+//
+// Parameters:
+//   - column: The column at which the lines are wrapped, counted in characters. A column of 0 or less
+//     disables wrapping.
+//
+// Returns:
+//   - A PartOption function that sets the column at which the Part's content is wrapped.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676
+func WithPartWrapAt(column int) PartOption {
+	return func(p *Part) {
+		p.SetWrapAt(column)
+	}
+}
+
+// setEncodedContent sets the transfer encoded content of the Part, which is decoded whenever the Part
+// is written.
+//
+// This allows to keep large parts of a parsed EML in memory only once, in their encoded form. The content
+// is decoded once to validate it, but the decoded content is discarded.
+//
+// Parameters:
+//   - content: The transfer encoded content of the Part.
+//   - encoding: The Encoding the content is encoded with. Content in any other Encoding than
+//     EncodingB64 or EncodingQP is used as it is.
+//
+// Returns:
+//   - An error if the content cannot be decoded with the given Encoding; otherwise, nil.
+func (p *Part) setEncodedContent(content []byte, encoding Encoding) error {
+	if _, err := io.Copy(io.Discard, newTransferDecoder(bytes.NewReader(content), encoding)); err != nil {
+		return err
+	}
+	p.writeFunc = func(writer io.Writer) (int64, error) {
+		return io.Copy(writer, newTransferDecoder(bytes.NewReader(content), encoding))
+	}
+	p.encodedContent = content
+	p.encodedWith = encoding
+	return nil
+}
+
+// newTransferDecoder returns an io.Reader that decodes the content read from the given io.Reader
+// according to the given Content-Transfer-Encoding.
+//
+// Parameters:
+//   - reader: The io.Reader providing the transfer encoded content.
+//   - encoding: The Content-Transfer-Encoding of the content.
+//
+// Returns:
+//   - An io.Reader providing the decoded content. For encodings other than EncodingB64 and
+//     EncodingQP, this is the given io.Reader.
+func newTransferDecoder(reader io.Reader, encoding Encoding) io.Reader {
+	switch encoding {
+	case EncodingB64:
+		return base64.NewDecoder(base64.StdEncoding, reader)
+	case EncodingQP:
+		return quotedprintable.NewReader(reader)
+	default:
+		return reader
+	}
+}
@@ -6,6 +6,7 @@ package mail
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -22,6 +23,7 @@ func TestPartEncoding(t *testing.T) {
 		{"Part encoding: Base64", EncodingB64, "base64"},
 		{"Part encoding: Quoted-Printable", EncodingQP, "quoted-printable"},
 		{"Part encoding: 8bit", NoEncoding, "8bit"},
+		{"Part encoding: auto", EncodingAuto, "auto"},
 	}
 	for _, tt := range tests {
 		m := NewMsg()
@@ -36,11 +38,21 @@ func TestPartEncoding(t *testing.T) {
 					part.encoding.String())
 			}
 			part.encoding = ""
-			part.SetEncoding(tt.enc)
+			if err := part.SetEncoding(tt.enc); err != nil {
+				t.Errorf("newPart() SetEncoding() failed: %s", err)
+			}
 			if part.encoding.String() != tt.want {
 				t.Errorf("newPart() SetEncoding() failed: expected encoding: %s, got: %s", tt.want,
 					part.encoding.String())
 			}
+			if err := part.SetEncoding("binary"); !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("SetEncoding() with invalid encoding expected error: %s, got: %v", ErrInvalidEncoding, err)
+			}
+			WithPartEncoding("binary")(part)
+			if part.encoding.String() != tt.want {
+				t.Errorf("invalid encoding was set on part, expected encoding: %s, got: %s", tt.want,
+					part.encoding.String())
+			}
 		})
 	}
 }
@@ -376,3 +388,26 @@ func TestPart_SetCharset(t *testing.T) {
 		})
 	}
 }
+
+// TestPart_WithPartContentTypeParam tests the WithPartContentTypeParam option and the Part.SetContentTypeParam and
+// Part.GetContentTypeParam methods
+func TestPart_WithPartContentTypeParam(t *testing.T) {
+	m := NewMsg()
+	part := m.newPart(TypeTextCalendar, WithPartContentTypeParam("Method", "REQUEST"))
+	if got := part.GetContentTypeParam("method"); got != "REQUEST" {
+		t.Errorf("WithPartContentTypeParam() failed. Expected method: REQUEST, got: %q", got)
+	}
+	part.SetContentTypeParam("METHOD", "CANCEL")
+	if got := part.GetContentTypeParam("Method"); got != "CANCEL" {
+		t.Errorf("SetContentTypeParam() failed. Expected method: CANCEL, got: %q", got)
+	}
+	part.SetContentTypeParam("charset", "ISO-8859-1")
+	part.SetContentTypeParam("", "empty")
+	if len(part.contentTypeParams) != 1 {
+		t.Errorf("SetContentTypeParam() failed. Expected charset and empty names to be ignored, got: %v",
+			part.contentTypeParams)
+	}
+	if got := part.GetContentTypeParam("component"); got != "" {
+		t.Errorf("GetContentTypeParam() failed. Expected empty value for unset parameter, got: %q", got)
+	}
+}
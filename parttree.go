@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"mime"
+	"path/filepath"
+)
+
+const (
+	// DispositionAttachment represents the "attachment" Content-Disposition of a PartNode.
+	DispositionAttachment = "attachment"
+
+	// DispositionInline represents the "inline" Content-Disposition of a PartNode.
+	DispositionInline = "inline"
+)
+
+// PartNode represents a single node in the MIME part hierarchy of a Msg.
+//
+// A PartNode is either a multipart container (e.g. multipart/mixed or multipart/alternative)
+// holding child nodes, or a leaf node representing a message Part, an attachment or an embed.
+// The tree is derived from the current state of the Msg and follows the same structure that
+// is used when the Msg is written.
+type PartNode struct {
+	children      []*PartNode
+	contentType   ContentType
+	disposition   string
+	encoding      Encoding
+	file          *File
+	maxLineLength int
+	part          *Part
+}
+
+// PartTree returns the MIME part hierarchy of the Msg as a tree of PartNode values.
+//
+// The returned tree reflects the structure that the msgWriter would produce for the Msg, i. e.
+// message parts are grouped in a multipart/alternative container, embeds in a multipart/related
+// container and attachments in a multipart/mixed container, as required. If the Msg consists of
+// a single part only, the returned node is that leaf itself.
+//
+// Returns:
+//   - A pointer to the root PartNode, or nil if the Msg holds no parts, attachments or embeds.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2046#section-5.1
+func (m *Msg) PartTree() *PartNode {
+	root := &PartNode{}
+	current := root
+
+	if m.hasMixed() {
+		current = current.addChild(&PartNode{contentType: TypeMultipartMixed})
+	}
+	mixed := current
+	if m.hasRelated() {
+		current = current.addChild(&PartNode{contentType: TypeMultipartRelated})
+	}
+	related := current
+	if m.hasAlt() {
+		current = current.addChild(&PartNode{contentType: TypeMultipartAlternative})
+	}
+	if m.hasPGPType() {
+		switch m.pgptype {
+		case PGPEncrypt:
+			current = current.addChild(&PartNode{contentType: "multipart/encrypted"})
+		case PGPSignature:
+			current = current.addChild(&PartNode{contentType: "multipart/signed"})
+		default:
+		}
+		// The msgWriter does not write a multipart/mixed or multipart/related container for PGP
+		// messages, the embeds and attachments are placed in the PGP container instead
+		mixed, related = current, current
+	}
+
+	for _, part := range m.alternativeParts() {
+		if part.isDeleted {
+			continue
+		}
+		encoding := part.encoding
+		if encoding == "" {
+			encoding = m.encoding
+		}
+		current.addChild(&PartNode{
+			contentType: part.contentType, encoding: encoding, maxLineLength: m.maxLineLength, part: part,
+		})
+	}
+	for _, file := range m.embeds {
+		related.addChild(newFilePartNode(file, DispositionInline, m.maxLineLength))
+	}
+	for _, file := range m.attachments {
+		mixed.addChild(newFilePartNode(file, DispositionAttachment, m.maxLineLength))
+	}
+
+	switch len(root.children) {
+	case 0:
+		return nil
+	case 1:
+		return root.children[0]
+	default:
+		// Multiple top-level nodes are not grouped into a container by the msgWriter either, so
+		// we return an untyped root node holding them
+		return root
+	}
+}
+
+// Children returns the child nodes of the PartNode.
+//
+// Returns:
+//   - A slice of PartNode pointers. For leaf nodes, the slice is empty.
+func (n *PartNode) Children() []*PartNode {
+	return n.children
+}
+
+// Content returns the raw content of a leaf PartNode.
+//
+// The raw content is the body of the part as it is written into the message, i. e. encoded with
+// the Content-Transfer-Encoding of the part. It is generated on demand by executing the write
+// function of the underlying Part or File. For multipart container nodes, nil is returned.
+//
+// Returns:
+//   - A byte slice containing the raw content of the PartNode.
+//   - An error if the write function of the underlying Part or File fails.
+func (n *PartNode) Content() ([]byte, error) {
+	var buffer bytes.Buffer
+	mw := &msgWriter{writer: &buffer, maxLineLength: n.maxLineLength}
+	switch {
+	case n.part != nil:
+		writeFunc, encoding, err := partBody(n.part)
+		if err != nil {
+			return nil, err
+		}
+		mw.writeBody(writeFunc, encoding)
+	case n.file != nil && n.file.Writer != nil:
+		mw.writeBody(n.file.Writer, n.encoding)
+	default:
+		return nil, nil
+	}
+	if mw.err != nil {
+		return nil, mw.err
+	}
+	return buffer.Bytes(), nil
+}
+
+// DecodedContent returns the decoded content of a leaf PartNode.
+//
+// The content is generated on demand by executing the write function of the underlying Part
+// or File. For multipart container nodes, nil is returned.
+//
+// Returns:
+//   - A byte slice containing the decoded content of the PartNode.
+//   - An error if the write function of the underlying Part or File fails.
+func (n *PartNode) DecodedContent() ([]byte, error) {
+	switch {
+	case n.part != nil:
+		return n.part.GetContent()
+	case n.file != nil && n.file.Writer != nil:
+		var buffer bytes.Buffer
+		if _, err := n.file.Writer(&buffer); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// ContentType returns the content type of the PartNode.
+//
+// Returns:
+//   - The ContentType of the PartNode, e.g. "text/plain" or "multipart/mixed".
+func (n *PartNode) ContentType() ContentType {
+	return n.contentType
+}
+
+// Disposition returns the content disposition of the PartNode.
+//
+// Returns:
+//   - DispositionAttachment for attachments, DispositionInline for embeds or an empty string
+//     for message parts and multipart containers.
+func (n *PartNode) Disposition() string {
+	return n.disposition
+}
+
+// Encoding returns the Content-Transfer-Encoding of the PartNode.
+//
+// Returns:
+//   - The Encoding of the PartNode. For multipart container nodes an empty Encoding is returned.
+func (n *PartNode) Encoding() Encoding {
+	return n.encoding
+}
+
+// IsMultipart returns true if the PartNode is a multipart container.
+//
+// Returns:
+//   - A boolean value indicating whether the PartNode is a multipart container.
+func (n *PartNode) IsMultipart() bool {
+	return n.part == nil && n.file == nil
+}
+
+// Name returns the file name of an attachment or embed PartNode.
+//
+// Returns:
+//   - The file name of the underlying File, or an empty string for other nodes.
+func (n *PartNode) Name() string {
+	if n.file == nil {
+		return ""
+	}
+	return n.file.Name
+}
+
+// addChild appends the given PartNode as child to the PartNode.
+//
+// Parameters:
+//   - child: A pointer to the PartNode that is added as child.
+//
+// Returns:
+//   - A pointer to the added child PartNode.
+func (n *PartNode) addChild(child *PartNode) *PartNode {
+	n.children = append(n.children, child)
+	return child
+}
+
+// newFilePartNode returns a new leaf PartNode for an attachment or embed File.
+//
+// The content type and encoding are determined the same way the msgWriter determines them
+// when the File is written.
+//
+// Parameters:
+//   - file: A pointer to the File the PartNode represents.
+//   - disposition: The content disposition of the File.
+//   - maxLineLength: The maximum line length of the encoded content of the File.
+//
+// Returns:
+//   - A pointer to the newly created PartNode.
+func newFilePartNode(file *File, disposition string, maxLineLength int) *PartNode {
+	contentType := file.ContentType
+	if contentType == "" {
+		mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(file.Name)))
+		if err == nil {
+			contentType = ContentType(mediaType)
+		}
+	}
+	if contentType == "" {
+		contentType = TypeAppOctetStream
+	}
+	encoding := file.Enc
+	if encoding == "" {
+		encoding = EncodingB64
+	}
+	return &PartNode{
+		contentType:   contentType,
+		disposition:   disposition,
+		encoding:      encoding,
+		file:          file,
+		maxLineLength: maxLineLength,
+	}
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestMsg_PartTree tests the Msg.PartTree method
+func TestMsg_PartTree(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	m.AddAlternativeString(TypeTextHTML, "<p>This is the HTML body</p>")
+	if err := m.EmbedReader("embed.txt", strings.NewReader("embedded content")); err != nil {
+		t.Fatalf("failed to embed reader: %s", err)
+	}
+	if err := m.AttachReader("attachment.txt", strings.NewReader("attached content")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+
+	root := m.PartTree()
+	if root == nil {
+		t.Fatal("PartTree() returned nil")
+	}
+	if root.ContentType() != TypeMultipartMixed || !root.IsMultipart() {
+		t.Fatalf("PartTree() failed. Expected root content type: %s, got: %s", TypeMultipartMixed,
+			root.ContentType())
+	}
+	if len(root.Children()) != 2 {
+		t.Fatalf("PartTree() failed. Expected 2 children of root, got: %d", len(root.Children()))
+	}
+
+	related := root.Children()[0]
+	if related.ContentType() != TypeMultipartRelated {
+		t.Errorf("PartTree() failed. Expected content type: %s, got: %s", TypeMultipartRelated,
+			related.ContentType())
+	}
+	attachment := root.Children()[1]
+	if attachment.Disposition() != DispositionAttachment {
+		t.Errorf("PartTree() failed. Expected disposition: %s, got: %s", DispositionAttachment,
+			attachment.Disposition())
+	}
+	if attachment.Name() != "attachment.txt" {
+		t.Errorf("PartTree() failed. Expected attachment name: attachment.txt, got: %s", attachment.Name())
+	}
+	if attachment.ContentType() != TypeTextPlain {
+		t.Errorf("PartTree() failed. Expected attachment content type: %s, got: %s", TypeTextPlain,
+			attachment.ContentType())
+	}
+	if attachment.Encoding() != EncodingB64 {
+		t.Errorf("PartTree() failed. Expected attachment encoding: %s, got: %s", EncodingB64,
+			attachment.Encoding())
+	}
+	content, err := attachment.Content()
+	if err != nil {
+		t.Fatalf("failed to get raw attachment content: %s", err)
+	}
+	if !bytes.Equal(content, []byte("YXR0YWNoZWQgY29udGVudA==\r\n")) {
+		t.Errorf("PartTree() failed. Expected raw attachment content: %q, got: %q", "YXR0YWNoZWQgY29udGVudA==\r\n",
+			content)
+	}
+	content, err = attachment.DecodedContent()
+	if err != nil {
+		t.Fatalf("failed to get attachment content: %s", err)
+	}
+	if !bytes.Equal(content, []byte("attached content")) {
+		t.Errorf("PartTree() failed. Expected attachment content: %q, got: %q", "attached content", content)
+	}
+
+	if len(related.Children()) != 2 {
+		t.Fatalf("PartTree() failed. Expected 2 children of related, got: %d", len(related.Children()))
+	}
+	alternative := related.Children()[0]
+	if alternative.ContentType() != TypeMultipartAlternative {
+		t.Errorf("PartTree() failed. Expected content type: %s, got: %s", TypeMultipartAlternative,
+			alternative.ContentType())
+	}
+	if related.Children()[1].Disposition() != DispositionInline {
+		t.Errorf("PartTree() failed. Expected disposition: %s, got: %s", DispositionInline,
+			related.Children()[1].Disposition())
+	}
+
+	wantTypes := []ContentType{TypeTextPlain, TypeTextHTML}
+	if len(alternative.Children()) != len(wantTypes) {
+		t.Fatalf("PartTree() failed. Expected %d children of alternative, got: %d", len(wantTypes),
+			len(alternative.Children()))
+	}
+	for i, child := range alternative.Children() {
+		if child.ContentType() != wantTypes[i] {
+			t.Errorf("PartTree() failed. Expected content type: %s, got: %s", wantTypes[i], child.ContentType())
+		}
+		if child.IsMultipart() {
+			t.Errorf("PartTree() failed. Expected leaf node for %s", child.ContentType())
+		}
+		if child.Encoding() != EncodingQP {
+			t.Errorf("PartTree() failed. Expected encoding: %s, got: %s", EncodingQP, child.Encoding())
+		}
+		if len(child.Children()) != 0 {
+			t.Errorf("PartTree() failed. Expected no children for leaf, got: %d", len(child.Children()))
+		}
+	}
+	content, err = alternative.Children()[1].Content()
+	if err != nil {
+		t.Fatalf("failed to get raw part content: %s", err)
+	}
+	if string(content) != "<p>This is the HTML body</p>" {
+		t.Errorf("PartTree() failed. Expected raw HTML content, got: %q", content)
+	}
+	content, err = alternative.Children()[1].DecodedContent()
+	if err != nil {
+		t.Fatalf("failed to get part content: %s", err)
+	}
+	if string(content) != "<p>This is the HTML body</p>" {
+		t.Errorf("PartTree() failed. Expected HTML content, got: %q", content)
+	}
+	content, err = alternative.Content()
+	if err != nil || content != nil {
+		t.Errorf("PartTree() failed. Expected no content for multipart node, got: %q, %v", content, err)
+	}
+	content, err = alternative.DecodedContent()
+	if err != nil || content != nil {
+		t.Errorf("PartTree() failed. Expected no decoded content for multipart node, got: %q, %v", content, err)
+	}
+}
+
+// TestMsg_PartTree_SinglePart tests the Msg.PartTree method for single and empty messages
+func TestMsg_PartTree_SinglePart(t *testing.T) {
+	m := NewMsg()
+	if root := m.PartTree(); root != nil {
+		t.Errorf("PartTree() failed. Expected nil for empty message, got: %+v", root)
+	}
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	root := m.PartTree()
+	if root == nil {
+		t.Fatal("PartTree() returned nil")
+	}
+	if root.IsMultipart() || root.ContentType() != TypeTextPlain {
+		t.Errorf("PartTree() failed. Expected single %s leaf, got: %s", TypeTextPlain, root.ContentType())
+	}
+}
+
+// TestPartNode_Content tests that PartNode.Content returns the encoded body and PartNode.DecodedContent
+// the original content of a part
+func TestPartNode_Content(t *testing.T) {
+	m := NewMsg()
+	m.SetBodyString(TypeTextPlain, "Grüße")
+	root := m.PartTree()
+	if root == nil {
+		t.Fatal("PartTree() returned nil")
+	}
+	content, err := root.Content()
+	if err != nil {
+		t.Fatalf("failed to get raw part content: %s", err)
+	}
+	if string(content) != "Gr=C3=BC=C3=9Fe" {
+		t.Errorf("Content() failed. Expected: %q, got: %q", "Gr=C3=BC=C3=9Fe", content)
+	}
+	content, err = root.DecodedContent()
+	if err != nil {
+		t.Fatalf("failed to get part content: %s", err)
+	}
+	if string(content) != "Grüße" {
+		t.Errorf("DecodedContent() failed. Expected: %q, got: %q", "Grüße", content)
+	}
+}
+
+// TestMsg_PartTree_PGP tests that Msg.PartTree places attachments in the PGP container
+func TestMsg_PartTree_PGP(t *testing.T) {
+	m := NewMsg(WithPGPType(PGPEncrypt))
+	m.SetBodyString(TypeTextPlain, "This is the plain text body")
+	if err := m.AttachReader("attachment.txt", strings.NewReader("attached content")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	root := m.PartTree()
+	if root == nil {
+		t.Fatal("PartTree() returned nil")
+	}
+	if root.ContentType() != "multipart/encrypted" {
+		t.Fatalf("PartTree() failed. Expected root content type: multipart/encrypted, got: %s", root.ContentType())
+	}
+	if len(root.Children()) != 2 || root.Children()[1].Disposition() != DispositionAttachment {
+		t.Errorf("PartTree() failed. Expected the attachment in the PGP container, got: %d children",
+			len(root.Children()))
+	}
+}
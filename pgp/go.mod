@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+module github.com/wneessen/go-mail/pgp
+
+go 1.19
+
+require (
+	github.com/ProtonMail/go-crypto v1.0.0
+	github.com/wneessen/go-mail v0.5.0
+)
+
+require (
+	github.com/cloudflare/circl v1.3.3 // indirect
+	golang.org/x/crypto v0.28.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+)
+
+replace github.com/wneessen/go-mail => ../
@@ -0,0 +1,407 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+// Package pgp implements a go-mail Middleware that OpenPGP signs and optionally encrypts a Msg according
+// to PGP/MIME.
+//
+// The package is a separate Go module, so that the go-mail core module does not depend on the OpenPGP
+// implementation and keeps its minimum Go version.
+package pgp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/wneessen/go-mail"
+)
+
+// Option is a function type that modifies the settings of a Signer.
+type Option func(*Signer) error
+
+// Signer is a Middleware that OpenPGP signs and optionally encrypts the body of a Msg according to
+// PGP/MIME.
+//
+// The Signer renders the MIME structure of the Msg, canonicalizes its line breaks to CRLF and wraps it
+// into a multipart/signed structure with a detached, ASCII armored application/pgp-signature part. The
+// signature is created using SHA-256 with the signing key of the provided OpenPGP private key. If
+// encryption is enabled via WithEncryption, the signed entity is additionally encrypted for the given
+// recipient keys into a multipart/encrypted structure. The headers of the Msg (e.g. From, To and Subject)
+// are not protected. Since mail transfer agents may alter 8bit content, which breaks the signature, the
+// body parts should use the quoted-printable or base64 encoding.
+//
+// Since the Msg is modified on the fly when it is written, the original Msg stays untouched. If the
+// Signer is used together with the DKIMSigner, the Signer must be applied first.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156
+//   - https://datatracker.ietf.org/doc/html/rfc4880
+//   - https://datatracker.ietf.org/doc/html/rfc1847
+type Signer struct {
+	entity     *openpgp.Entity
+	recipients openpgp.EntityList
+}
+
+// MiddlewareType is the MiddlewareType of the Signer.
+const MiddlewareType mail.MiddlewareType = "pgp"
+
+var (
+	// ErrInvalidKey is returned when the key provided to the Signer cannot be read as ASCII armored
+	// OpenPGP key ring.
+	ErrInvalidKey = errors.New("PGP key cannot be read")
+
+	// ErrNoPrivateKey is returned when the key provided to the Signer does not hold an OpenPGP private
+	// key.
+	ErrNoPrivateKey = errors.New("PGP key must hold an OpenPGP private key")
+
+	// ErrInvalidPassphrase is returned when the private key provided to the Signer cannot be decrypted
+	// with the given passphrase.
+	ErrInvalidPassphrase = errors.New("PGP private key cannot be decrypted with the passphrase")
+
+	// ErrNoRecipients is returned when encryption is enabled without any recipient key.
+	ErrNoRecipients = errors.New("PGP encryption requires at least one recipient key")
+
+	// ErrInvalidRecipient is returned when a recipient key cannot be read or cannot be used for
+	// encryption.
+	ErrInvalidRecipient = errors.New("PGP recipient key must be an OpenPGP public key for encryption")
+)
+
+// NewSigner returns a new Signer for the given ASCII armored OpenPGP private key.
+//
+// By default, the Signer only signs the Msg. The optional WithEncryption option can be used to enable
+// encryption. If the private key or its subkeys are protected by a passphrase, they are decrypted with
+// the given passphrase once, when the Signer is created.
+//
+// Parameters:
+//   - armoredKey: The ASCII armored OpenPGP private key. If the key ring holds more than one key, the
+//     first private key is used.
+//   - passphrase: The passphrase of the private key. It is ignored if the key is not protected.
+//   - opts: Optional Option functions to customize the Signer.
+//
+// Returns:
+//   - A pointer to the Signer.
+//   - An error wrapping ErrInvalidKey if the key cannot be read, ErrNoPrivateKey if it holds no private
+//     key, ErrInvalidPassphrase if it cannot be decrypted, or the error of an invalid option.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156#section-5
+func NewSigner(armoredKey, passphrase []byte, opts ...Option) (*Signer, error) {
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, &wrapError{err: ErrInvalidKey, cause: err}
+	}
+	var entity *openpgp.Entity
+	for _, candidate := range keyRing {
+		if candidate.PrivateKey != nil {
+			entity = candidate
+			break
+		}
+	}
+	if entity == nil {
+		return nil, ErrNoPrivateKey
+	}
+	privateKeys := []*packet.PrivateKey{entity.PrivateKey}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil {
+			privateKeys = append(privateKeys, subkey.PrivateKey)
+		}
+	}
+	for _, privateKey := range privateKeys {
+		if !privateKey.Encrypted {
+			continue
+		}
+		if err = privateKey.Decrypt(passphrase); err != nil {
+			return nil, &wrapError{err: ErrInvalidPassphrase, cause: err}
+		}
+	}
+
+	signer := &Signer{entity: entity}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err = opt(signer); err != nil {
+			return nil, fmt.Errorf("failed to apply PGP signer option: %w", err)
+		}
+	}
+	return signer, nil
+}
+
+// WithEncryption enables the encryption of the signed Msg for the given recipient keys.
+//
+// The signed entity is encrypted for the encryption keys of all recipients into a single OpenPGP
+// message. To be able to decrypt the Msg later on, e.g. in the sent folder, the public key of the
+// sender should be included in the recipient keys.
+//
+// Parameters:
+//   - armoredKeys: The ASCII armored OpenPGP public keys of the recipients. Each key ring may hold
+//     more than one key.
+//
+// Returns:
+//   - An Option function that enables the encryption for the Signer.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156#section-6.1
+func WithEncryption(armoredKeys ...[]byte) Option {
+	return func(s *Signer) error {
+		if len(armoredKeys) == 0 {
+			return ErrNoRecipients
+		}
+		var recipients openpgp.EntityList
+		for _, armoredKey := range armoredKeys {
+			keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+			if err != nil {
+				return &wrapError{err: ErrInvalidRecipient, cause: err}
+			}
+			recipients = append(recipients, keyRing...)
+		}
+
+		// openpgp.Encrypt checks the encryption keys of all recipients before anything is written
+		plaintext, err := openpgp.Encrypt(io.Discard, recipients, nil, nil, nil)
+		if err != nil {
+			return &wrapError{err: ErrInvalidRecipient, cause: err}
+		}
+		if err = plaintext.Close(); err != nil {
+			return &wrapError{err: ErrInvalidRecipient, cause: err}
+		}
+		s.recipients = recipients
+		return nil
+	}
+}
+
+// Handle satisfies the Middleware interface and returns a PGP/MIME protected copy of the Msg.
+//
+// The returned Msg is a clone of the given Msg, whose body is replaced by the signed and, if
+// configured, encrypted MIME entity. If the Msg cannot be signed or encrypted, the returned Msg
+// fails to render with the corresponding error, so that it is never sent unprotected.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - A pointer to the PGP/MIME protected copy of the Msg.
+func (s *Signer) Handle(msg *mail.Msg) *mail.Msg {
+	entity, err := s.Sign(msg)
+	clone := msg.Clone()
+	if err != nil {
+		clone.SetWriteError(err)
+		return clone
+	}
+	clone.SetMIMEEntity(entity)
+	return clone
+}
+
+// Type returns the MiddlewareType of the Signer.
+//
+// This method satisfies the Middleware interface.
+//
+// Returns:
+//   - MiddlewareType
+func (s *Signer) Type() mail.MiddlewareType {
+	return MiddlewareType
+}
+
+// Sign renders the given Msg and returns its PGP/MIME protected MIME entity.
+//
+// The Msg is rendered without applying any Middleware. The Content-* header fields and the body of
+// the rendered Msg form the MIME entity that is signed. The returned entity includes its own
+// Content-Type header and replaces the body of the Msg. The boundaries of the multipart/signed and
+// multipart/encrypted entities are derived from the boundary settings of the Msg, see boundaryFor.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - The signed and, if encryption is enabled, encrypted MIME entity.
+//   - An error if the Msg cannot be rendered, signed or encrypted.
+func (s *Signer) Sign(msg *mail.Msg) ([]byte, error) {
+	content, err := msg.MIMEEntity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render message for PGP signing: %w", err)
+	}
+	boundary, err := boundaryFor(msg, "signed")
+	if err != nil {
+		return nil, err
+	}
+	entity, err := s.signEntity(content, boundary)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.recipients) == 0 {
+		return entity, nil
+	}
+	if boundary, err = boundaryFor(msg, "encrypted"); err != nil {
+		return nil, err
+	}
+	return s.encryptEntity(entity, boundary)
+}
+
+// boundaryFor returns the boundary of a multipart/signed or multipart/encrypted entity for the given
+// Msg.
+//
+// If the Msg has a boundary set or a boundary generator, the boundary is derived from it and prefixed
+// with the given label, so that the output is deterministic, but does not collide with the boundaries
+// of the wrapped MIME entity. Otherwise, a random boundary is returned.
+//
+// Parameters:
+//   - msg: A pointer to the Msg that is signed or encrypted.
+//   - label: The label that prefixes the derived boundary, e.g. "signed".
+//
+// Returns:
+//   - The boundary.
+//   - An error if the random boundary cannot be generated.
+func boundaryFor(msg *mail.Msg, label string) (string, error) {
+	boundary := msg.GetBoundary()
+	if generator := msg.GetBoundaryGenerator(); boundary == "" && generator != nil {
+		boundary = generator()
+	}
+	if boundary != "" {
+		return label + "_" + boundary, nil
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate boundary: %w", err)
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// signEntity wraps the given MIME entity into a multipart/signed entity with a detached signature.
+//
+// The signature is calculated over the exact bytes of the canonicalized entity, which are written
+// unchanged as first body part, so that the signature can be verified by e.g. GnuPG.
+//
+// Parameters:
+//   - entity: The canonicalized MIME entity to sign.
+//   - boundary: The boundary of the multipart/signed entity.
+//
+// Returns:
+//   - The multipart/signed MIME entity.
+//   - An error if the signature cannot be created.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156#section-5
+func (s *Signer) signEntity(entity []byte, boundary string) ([]byte, error) {
+	signature := bytes.Buffer{}
+	err := openpgp.ArmoredDetachSignText(&signature, s.entity, bytes.NewReader(entity),
+		&packet.Config{DefaultHash: crypto.SHA256})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PGP signature: %w", err)
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString(`Content-Type: multipart/signed; protocol="application/pgp-signature";` +
+		mail.SingleNewLine + ` micalg=pgp-sha256; boundary="` + boundary + `"` + mail.DoubleNewLine)
+	buffer.WriteString("This is an OpenPGP/MIME signed message (RFC 4880 and 3156)" + mail.DoubleNewLine)
+	buffer.WriteString("--" + boundary + mail.SingleNewLine)
+	buffer.Write(entity)
+	buffer.WriteString(mail.SingleNewLine + "--" + boundary + mail.SingleNewLine)
+	buffer.WriteString(`Content-Type: application/pgp-signature; name="signature.asc"` + mail.SingleNewLine +
+		`Content-Description: OpenPGP digital signature` + mail.SingleNewLine +
+		`Content-Disposition: attachment; filename="signature.asc"` + mail.DoubleNewLine)
+	buffer.Write(canonicalizeLineBreaks(signature.Bytes()))
+	buffer.WriteString(mail.SingleNewLine + "--" + boundary + "--" + mail.SingleNewLine)
+	return buffer.Bytes(), nil
+}
+
+// encryptEntity encrypts the given MIME entity into a multipart/encrypted entity.
+//
+// Parameters:
+//   - entity: The MIME entity to encrypt.
+//   - boundary: The boundary of the multipart/encrypted entity.
+//
+// Returns:
+//   - The multipart/encrypted MIME entity.
+//   - An error if the entity cannot be encrypted.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156#section-4
+func (s *Signer) encryptEntity(entity []byte, boundary string) ([]byte, error) {
+	message := bytes.Buffer{}
+	armored, err := armor.Encode(&message, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt PGP message: %w", err)
+	}
+	plaintext, err := openpgp.Encrypt(armored, s.recipients, nil, nil,
+		&packet.Config{DefaultHash: crypto.SHA256})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt PGP message: %w", err)
+	}
+	if _, err = plaintext.Write(entity); err != nil {
+		return nil, fmt.Errorf("failed to encrypt PGP message: %w", err)
+	}
+	if err = plaintext.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt PGP message: %w", err)
+	}
+	if err = armored.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt PGP message: %w", err)
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString(`Content-Type: multipart/encrypted; protocol="application/pgp-encrypted";` +
+		mail.SingleNewLine + ` boundary="` + boundary + `"` + mail.DoubleNewLine)
+	buffer.WriteString("This is an OpenPGP/MIME encrypted message (RFC 4880 and 3156)" + mail.DoubleNewLine)
+	buffer.WriteString("--" + boundary + mail.SingleNewLine)
+	buffer.WriteString(`Content-Type: application/pgp-encrypted` + mail.SingleNewLine +
+		`Content-Description: PGP/MIME version identification` + mail.DoubleNewLine +
+		`Version: 1` + mail.DoubleNewLine)
+	buffer.WriteString("--" + boundary + mail.SingleNewLine)
+	buffer.WriteString(`Content-Type: application/octet-stream; name="encrypted.asc"` + mail.SingleNewLine +
+		`Content-Description: OpenPGP encrypted message` + mail.SingleNewLine +
+		`Content-Disposition: inline; filename="encrypted.asc"` + mail.DoubleNewLine)
+	buffer.Write(canonicalizeLineBreaks(message.Bytes()))
+	buffer.WriteString(mail.SingleNewLine + "--" + boundary + "--" + mail.SingleNewLine)
+	return buffer.Bytes(), nil
+}
+
+// canonicalizeLineBreaks converts all line breaks of the given data to CRLF, as required for the
+// canonical form of a MIME entity.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3156#section-5
+func canonicalizeLineBreaks(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte(mail.SingleNewLine), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\n"), []byte(mail.SingleNewLine))
+}
+
+// wrapError is an error that matches both a sentinel error of this package and the error that caused
+// it, e.g. the error returned by the OpenPGP implementation.
+type wrapError struct {
+	err   error
+	cause error
+}
+
+// Error returns the message of the sentinel error followed by the message of the cause.
+//
+// Returns:
+//   - The error message.
+func (e *wrapError) Error() string {
+	return fmt.Sprintf("%s: %s", e.err, e.cause)
+}
+
+// Is reports whether the sentinel error of the wrapError matches the given target error. The cause is
+// matched by errors.Is via Unwrap.
+//
+// Parameters:
+//   - target: The target error to match.
+//
+// Returns:
+//   - true if the sentinel error matches the target; otherwise false.
+func (e *wrapError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// Unwrap returns the cause of the wrapError.
+//
+// Returns:
+//   - The error that caused the sentinel error.
+func (e *wrapError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,566 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolMaxConnections is the default maximum number of connections a Pool opens to the
+	// SMTP server at the same time.
+	DefaultPoolMaxConnections = 4
+
+	// DefaultPoolMaxIdleTime is the default duration after which an idle connection of a Pool is
+	// closed instead of being reused.
+	DefaultPoolMaxIdleTime = time.Minute
+
+	// smtpReplyServiceClosing is the SMTP reply code a server uses to indicate that it is closing
+	// the transmission channel, e.g. because a transaction limit was reached.
+	//
+	// References:
+	//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.2.3
+	smtpReplyServiceClosing = 421
+)
+
+var (
+	// ErrPoolClosed is returned when a message is sent through a Pool that has been closed.
+	ErrPoolClosed = errors.New("connection pool is closed")
+
+	// ErrInvalidPoolMaxConnections is returned when the maximum number of connections of a Pool
+	// is zero or negative.
+	ErrInvalidPoolMaxConnections = errors.New("maximum number of pool connections must be positive")
+
+	// ErrInvalidPoolMaxIdleTime is returned when the maximum idle time of a Pool is zero or negative.
+	ErrInvalidPoolMaxIdleTime = errors.New("maximum idle time of pool connections must be positive")
+
+	// ErrInvalidPoolMaxMessages is returned when the maximum number of messages per connection of a
+	// Pool is negative.
+	ErrInvalidPoolMaxMessages = errors.New("maximum number of messages per pool connection cannot be negative")
+
+	// ErrInvalidPoolConcurrency is returned when the concurrency level passed to Pool.SendConcurrent
+	// is zero or negative.
+	ErrInvalidPoolConcurrency = errors.New("concurrency level must be positive")
+)
+
+type (
+	// PoolOption is a function type that applies a configuration option to a Pool.
+	PoolOption func(*Pool) error
+
+	// Pool is a pool of authenticated connections to a single SMTP server.
+	//
+	// A Pool allows to send a large number of messages to the same SMTP server without dialing
+	// and authenticating a new connection for every message. It keeps a bounded set of Client
+	// connections, which are reused for subsequent messages and reset with RSET between messages.
+	// Idle connections are health-checked before reuse and dead connections are replaced
+	// transparently. A Pool is safe for concurrent use by multiple goroutines.
+	Pool struct {
+		// clientOpts holds the Option functions that are applied to each Client of the Pool.
+		clientOpts []Option
+
+		// closed indicates that the Pool has been closed.
+		closed bool
+
+		// host is the hostname of the SMTP server the Pool connects to.
+		host string
+
+		// idle holds the connections that are currently not in use, the most recently used
+		// connection last.
+		idle []*poolConn
+
+		// lastTLSState holds the TLS connection state of the connection that was used for the last
+		// message sent through the Pool, or nil if that connection was not encrypted.
+		lastTLSState *tls.ConnectionState
+
+		// maxConns is the maximum number of connections the Pool opens at the same time.
+		maxConns int
+
+		// maxIdleTime is the duration after which an idle connection is closed instead of reused.
+		maxIdleTime time.Duration
+
+		// maxMessages is the maximum number of messages sent through a single connection before it
+		// is recycled. A value of zero means no limit.
+		maxMessages int
+
+		// mutex synchronizes the access to the idle connections and the closed state.
+		mutex sync.Mutex
+
+		// slots limits the number of connections that are in use at the same time.
+		slots chan struct{}
+	}
+
+	// poolConn represents a single connection of a Pool.
+	poolConn struct {
+		// client is the connected Client of the connection.
+		client *Client
+
+		// lastUsed is the time the connection was last returned to the Pool.
+		lastUsed time.Time
+
+		// messages is the number of messages that have been sent through the connection.
+		messages int
+	}
+)
+
+// NewPool creates a new Pool of connections to the SMTP server at the given host.
+//
+// The connections of the Pool are established lazily once messages are sent. Each connection is a
+// Client created with the Option functions provided via WithPoolClientOptions.
+//
+// Parameters:
+//   - host: The hostname of the SMTP server.
+//   - opts: Optional PoolOption functions to customize the Pool.
+//
+// Returns:
+//   - A pointer to the newly created Pool.
+//   - An error if any of the options or the Client options are invalid.
+func NewPool(host string, opts ...PoolOption) (*Pool, error) {
+	pool := &Pool{
+		host:        host,
+		maxConns:    DefaultPoolMaxConnections,
+		maxIdleTime: DefaultPoolMaxIdleTime,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(pool); err != nil {
+			return pool, fmt.Errorf("failed to apply option: %w", err)
+		}
+	}
+
+	// Make sure that the Client options are valid before the first connection is dialed
+	if _, err := NewClient(pool.host, pool.clientOpts...); err != nil {
+		return pool, fmt.Errorf("failed to create pool client: %w", err)
+	}
+	pool.slots = make(chan struct{}, pool.maxConns)
+	return pool, nil
+}
+
+// WithPoolClientOptions sets the Option functions that are applied to each Client of the Pool.
+//
+// Parameters:
+//   - opts: The Option functions used to create the Client connections of the Pool.
+//
+// Returns:
+//   - A PoolOption function that sets the Client options for the Pool.
+func WithPoolClientOptions(opts ...Option) PoolOption {
+	return func(p *Pool) error {
+		p.clientOpts = append(p.clientOpts, opts...)
+		return nil
+	}
+}
+
+// WithPoolMaxConnections sets the maximum number of connections the Pool opens to the SMTP server at
+// the same time. If all connections are in use, sending blocks until a connection becomes available.
+//
+// Parameters:
+//   - maxConns: The maximum number of connections. Must be positive.
+//
+// Returns:
+//   - A PoolOption function that sets the maximum number of connections for the Pool.
+//   - An error if the maximum number of connections is zero or negative.
+func WithPoolMaxConnections(maxConns int) PoolOption {
+	return func(p *Pool) error {
+		if maxConns <= 0 {
+			return ErrInvalidPoolMaxConnections
+		}
+		p.maxConns = maxConns
+		return nil
+	}
+}
+
+// WithPoolMaxIdleTime sets the duration after which an idle connection of the Pool is closed instead
+// of being reused. This should be shorter than the idle timeout of the SMTP server.
+//
+// Parameters:
+//   - idleTime: The maximum idle time of a connection. Must be positive.
+//
+// Returns:
+//   - A PoolOption function that sets the maximum idle time for the Pool.
+//   - An error if the maximum idle time is zero or negative.
+func WithPoolMaxIdleTime(idleTime time.Duration) PoolOption {
+	return func(p *Pool) error {
+		if idleTime <= 0 {
+			return ErrInvalidPoolMaxIdleTime
+		}
+		p.maxIdleTime = idleTime
+		return nil
+	}
+}
+
+// WithPoolMaxMessages sets the maximum number of messages that are sent through a single connection
+// of the Pool before it is closed and replaced by a new connection.
+//
+// This is useful for SMTP servers that limit the number of transactions per connection. Independent
+// of this option, connections are recycled automatically when the server closes the transmission
+// channel with a 421 reply.
+//
+// Parameters:
+//   - maxMessages: The maximum number of messages per connection. Zero means no limit.
+//
+// Returns:
+//   - A PoolOption function that sets the maximum number of messages per connection for the Pool.
+//   - An error if the maximum number of messages is negative.
+func WithPoolMaxMessages(maxMessages int) PoolOption {
+	return func(p *Pool) error {
+		if maxMessages < 0 {
+			return ErrInvalidPoolMaxMessages
+		}
+		p.maxMessages = maxMessages
+		return nil
+	}
+}
+
+// Send sends out the given messages using the connections of the Pool.
+//
+// Each message is sent through a pooled connection. If the connection turns out to be dead or the
+// server closes it before the message has been transferred, the connection is replaced and the
+// message is sent once more through a new connection. As with Client.Send, the send error of each
+// message is stored with the message and can be retrieved via Msg.SendError.
+//
+// Parameters:
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - An error if sending any of the messages fails; otherwise, returns nil. If multiple messages
+//     fail, a SendError with the reason ErrAmbiguous is returned.
+func (p *Pool) Send(messages ...*Msg) error {
+	var errs []error
+	for _, message := range messages {
+		if err := p.sendSingleMsg(message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		returnErr := &SendError{Reason: ErrAmbiguous}
+		for _, err := range errs {
+			var sendErr *SendError
+			if !errors.As(err, &sendErr) {
+				returnErr.errlist = append(returnErr.errlist, err)
+				continue
+			}
+			returnErr.errlist = append(returnErr.errlist, sendErr.errlist...)
+			returnErr.rcpt = append(returnErr.rcpt, sendErr.rcpt...)
+			returnErr.rcptErrs = append(returnErr.rcptErrs, sendErr.rcptErrs...)
+			returnErr.isTemp = sendErr.isTemp
+		}
+		return returnErr
+	}
+}
+
+// SendConcurrent sends out the given messages in parallel using multiple connections of the Pool.
+//
+// The messages are distributed among up to concurrency workers, each of which sends its messages through
+// its own pooled connection, so that at most concurrency messages are in flight at the same time. The
+// number of connections is additionally bounded by the maximum number of connections of the Pool. As
+// with Client.SendWithResults, a message is delivered to all recipients accepted by the server and a
+// SendResult is returned for each recipient of each message, in the order of the messages. A connection
+// that turns out to be dead is replaced and the message is sent once more through a new connection.
+// Once the context is canceled or its deadline is exceeded, no further messages are sent, the
+// connections with a message in flight are closed and the remaining messages are reported with the
+// error of the context.
+//
+// Parameters:
+//   - ctx: The context.Context that controls the cancellation of the batch.
+//   - concurrency: The maximum number of messages that are sent at the same time.
+//   - messages: A variadic list of pointers to Msg objects to be sent.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of each message. Messages that could not be sent
+//     because no connection could be established are reported with the connection error.
+//   - An error if the concurrency level is invalid or if the context is done before all messages have
+//     been sent; otherwise, returns nil. Delivery errors are only reported via the returned SendResult
+//     values.
+func (p *Pool) SendConcurrent(ctx context.Context, concurrency int, messages ...*Msg) ([]SendResult, error) {
+	if concurrency <= 0 {
+		return nil, ErrInvalidPoolConcurrency
+	}
+	if concurrency > len(messages) {
+		concurrency = len(messages)
+	}
+
+	msgResults := make([][]SendResult, len(messages))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				msgResults[index] = p.sendSingleMsgWithResults(ctx, messages[index])
+			}
+		}()
+	}
+	for index := range messages {
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+
+	var results []SendResult
+	for _, msgResult := range msgResults {
+		results = append(results, msgResult...)
+	}
+	return results, ctx.Err()
+}
+
+// Close closes all idle connections of the Pool. Connections that are in use are closed once they
+// are returned to the Pool. After Close, no more messages can be sent through the Pool.
+//
+// Returns:
+//   - An error if closing any of the idle connections fails.
+func (p *Pool) Close() error {
+	p.mutex.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mutex.Unlock()
+
+	var errs []error
+	for _, conn := range idle {
+		if err := conn.client.Close(); err != nil {
+			_ = conn.client.smtpClient.Close()
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close pool connections: %w", errs[0])
+	}
+	return nil
+}
+
+// LastTLSState returns the TLS connection state of the pooled connection that was used for the last
+// message sent through the Pool.
+//
+// Like Client.LastTLSState, the connection state reflects the TLS handshake that was negotiated with the
+// SMTP server on that connection, either via STARTTLS or via implicit TLS. If messages are sent
+// concurrently, the connection state belongs to the message whose transaction was started last.
+//
+// Returns:
+//   - The TLS connection state of the connection used for the last message sent.
+//   - A boolean indicating whether the last message was sent through a TLS connection. It is false if no
+//     message has been sent yet or if the connection was not encrypted.
+func (p *Pool) LastTLSState() (tls.ConnectionState, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.lastTLSState == nil {
+		return tls.ConnectionState{}, false
+	}
+	return *p.lastTLSState, true
+}
+
+// sendSingleMsg sends a single message through a connection of the Pool, retrying once with a new
+// connection if the connection fails before the message has been transferred.
+//
+// Parameters:
+//   - message: A pointer to the Msg to be sent.
+//
+// Returns:
+//   - An error if sending the message fails; otherwise, returns nil.
+func (p *Pool) sendSingleMsg(message *Msg) error {
+	for attempt := 0; ; attempt++ {
+		conn, err := p.acquire(context.Background())
+		if err != nil {
+			return err
+		}
+		err = conn.client.Send(message)
+		conn.messages++
+		p.recordTLSState(conn)
+		broken := isBrokenConnError(err)
+		p.release(conn, !broken)
+		if err == nil || !broken || message.IsDelivered() || attempt > 0 {
+			if err != nil && message.sendError == nil {
+				message.sendError = err
+			}
+			return err
+		}
+		message.sendError = nil
+	}
+}
+
+// sendSingleMsgWithResults sends a single message through a connection of the Pool and returns the
+// delivery result for each recipient, retrying once with a new connection if the connection fails
+// before the message has been transferred.
+//
+// Parameters:
+//   - ctx: The context.Context that controls the cancellation of the delivery.
+//   - message: A pointer to the Msg to be sent.
+//
+// Returns:
+//   - A slice of SendResult, one for each recipient of the message.
+func (p *Pool) sendSingleMsgWithResults(ctx context.Context, message *Msg) []SendResult {
+	rcpts, _ := message.GetRecipients()
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return newSendResults(message, rcpts, err)
+		}
+		conn, err := p.acquire(ctx)
+		if err != nil {
+			return newSendResults(message, rcpts, err)
+		}
+		stopWatch := conn.client.watchContext(ctx)
+		results, err := conn.client.SendWithResults(message)
+		stopWatch()
+		conn.messages++
+		p.recordTLSState(conn)
+		if err != nil {
+			results = newSendResults(message, rcpts, err)
+		}
+		broken := isBrokenConnError(err) || ctx.Err() != nil
+		for _, result := range results {
+			broken = broken || isBrokenConnError(result.Err)
+		}
+		p.release(conn, !broken)
+		if !broken || ctx.Err() != nil || message.IsDelivered() || attempt > 0 {
+			return results
+		}
+		message.sendError = nil
+	}
+}
+
+// recordTLSState stores the TLS connection state of the given connection as the connection state of the
+// last message sent through the Pool.
+//
+// Parameters:
+//   - conn: A pointer to the poolConn that was used to send the last message.
+func (p *Pool) recordTLSState(conn *poolConn) {
+	state, ok := conn.client.LastTLSState()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.lastTLSState = nil
+	if ok {
+		p.lastTLSState = &state
+	}
+}
+
+// acquire returns a connection of the Pool, waiting for a free slot if all connections are in use.
+//
+// Idle connections are reused if they have not exceeded the maximum idle time; otherwise a new
+// connection is dialed.
+//
+// Parameters:
+//   - ctx: The context.Context that controls the waiting for a free slot and the dialing of a new
+//     connection.
+//
+// Returns:
+//   - A pointer to the poolConn to use.
+//   - An error if the Pool is closed, the context is done or a new connection cannot be established.
+func (p *Pool) acquire(ctx context.Context) (*poolConn, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		<-p.slots
+		return nil, ErrPoolClosed
+	}
+	var expired []*poolConn
+	var conn *poolConn
+	for len(p.idle) > 0 {
+		candidate := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if time.Since(candidate.lastUsed) > p.maxIdleTime {
+			expired = append(expired, candidate)
+			continue
+		}
+		conn = candidate
+		break
+	}
+	p.mutex.Unlock()
+
+	for _, idleConn := range expired {
+		idleConn.close()
+	}
+	if conn != nil {
+		return conn, nil
+	}
+
+	client, err := NewClient(p.host, p.clientOpts...)
+	if err != nil {
+		<-p.slots
+		return nil, fmt.Errorf("failed to create pool client: %w", err)
+	}
+	if err = client.DialWithContext(ctx); err != nil {
+		<-p.slots
+		return nil, fmt.Errorf("failed to dial pool connection: %w", err)
+	}
+	return &poolConn{client: client}, nil
+}
+
+// release returns a connection to the Pool and frees its slot.
+//
+// The connection is closed instead of being kept idle, if it is not reusable, if it reached the
+// maximum number of messages or if the Pool has been closed.
+//
+// Parameters:
+//   - conn: A pointer to the poolConn to return.
+//   - reusable: Whether the connection can be used for further messages.
+func (p *Pool) release(conn *poolConn, reusable bool) {
+	defer func() { <-p.slots }()
+
+	if p.maxMessages > 0 && conn.messages >= p.maxMessages {
+		reusable = false
+	}
+	p.mutex.Lock()
+	if reusable && !p.closed {
+		conn.lastUsed = time.Now()
+		p.idle = append(p.idle, conn)
+		p.mutex.Unlock()
+		return
+	}
+	p.mutex.Unlock()
+	conn.close()
+}
+
+// close closes the connection. If the connection cannot be closed gracefully with QUIT, e.g.
+// because it is already broken, the underlying connection is closed directly.
+func (c *poolConn) close() {
+	if err := c.client.Close(); err != nil && c.client.smtpClient != nil {
+		_ = c.client.smtpClient.Close()
+	}
+}
+
+// isBrokenConnError checks if the given error indicates that the connection to the SMTP server can
+// no longer be used.
+//
+// This is the case if the connection check failed or if the server replied with a 421 reply code,
+// indicating that it closes the transmission channel.
+//
+// Parameters:
+//   - err: The error returned by Client.Send.
+//
+// Returns:
+//   - true if the connection should be discarded, false otherwise.
+func isBrokenConnError(err error) bool {
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) {
+		return false
+	}
+	if sendErr.Reason == ErrConnCheck || sendErr.ErrorCode() == smtpReplyServiceClosing {
+		return true
+	}
+	for _, rcptErr := range sendErr.rcptErrs {
+		if rcptErr.ErrorCode() == smtpReplyServiceClosing {
+			return true
+		}
+	}
+	return false
+}
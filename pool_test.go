@@ -0,0 +1,396 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// poolTestServer is a SMTP test server that counts the accepted connections and delivered messages
+type poolTestServer struct {
+	connections int32
+	delivered   int32
+	listener    net.Listener
+	maxPerConn  int
+	mutex       sync.Mutex
+	openConns   []net.Conn
+}
+
+// TestNewPool tests the NewPool function and its PoolOption functions
+func TestNewPool(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []PoolOption
+		want error
+	}{
+		{"defaults", nil, nil},
+		{
+			"valid options",
+			[]PoolOption{
+				WithPoolMaxConnections(10), WithPoolMaxIdleTime(time.Second * 30),
+				WithPoolMaxMessages(100), WithPoolClientOptions(WithPort(2525)), nil,
+			},
+			nil,
+		},
+		{"invalid max connections", []PoolOption{WithPoolMaxConnections(0)}, ErrInvalidPoolMaxConnections},
+		{"invalid max idle time", []PoolOption{WithPoolMaxIdleTime(-1)}, ErrInvalidPoolMaxIdleTime},
+		{"invalid max messages", []PoolOption{WithPoolMaxMessages(-1)}, ErrInvalidPoolMaxMessages},
+		{"invalid client options", []PoolOption{WithPoolClientOptions(WithPort(-1))}, ErrInvalidPort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := NewPool(DefaultHost, tt.opts...)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("NewPool failed: %s", err)
+				}
+				if pool.maxConns <= 0 || pool.maxIdleTime <= 0 || cap(pool.slots) != pool.maxConns {
+					t.Errorf("NewPool failed. Unexpected pool settings: %+v", pool)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("NewPool expected error: %s, got: %v", tt.want, err)
+			}
+		})
+	}
+	if _, err := NewPool(""); !errors.Is(err, ErrNoHostname) {
+		t.Errorf("NewPool with empty host expected error: %s, got: %v", ErrNoHostname, err)
+	}
+}
+
+// TestPool_Send tests that the Pool reuses and recycles its connections
+func TestPool_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		portOffset      int
+		serverLimit     int
+		opts            []PoolOption
+		wantConnections int32
+	}{
+		{"reuse connection", 58, 0, nil, 1},
+		{"recycle after max messages", 59, 0, []PoolOption{WithPoolMaxMessages(2)}, 3},
+		{"recycle after server transaction limit", 60, 2, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startPoolTestServer(t, TestServerPortBase+tt.portOffset, tt.serverLimit)
+			pool := newTestPool(t, TestServerPortBase+tt.portOffset, tt.opts...)
+			messages := make([]*Msg, 5)
+			for i := range messages {
+				messages[i] = newPoolTestMsg(t)
+			}
+			if err := pool.Send(messages...); err != nil {
+				t.Fatalf("failed to send messages through pool: %s", err)
+			}
+			if err := pool.Close(); err != nil {
+				t.Errorf("failed to close pool: %s", err)
+			}
+			for i, message := range messages {
+				if !message.IsDelivered() || message.HasSendError() {
+					t.Errorf("Pool.Send failed. Message %d was not delivered: %v", i, message.SendError())
+				}
+			}
+			if delivered := atomic.LoadInt32(&server.delivered); delivered != int32(len(messages)) {
+				t.Errorf("Pool.Send failed. Expected %d delivered messages, got: %d", len(messages), delivered)
+			}
+			if conns := atomic.LoadInt32(&server.connections); conns != tt.wantConnections {
+				t.Errorf("Pool.Send failed. Expected %d connections, got: %d", tt.wantConnections, conns)
+			}
+		})
+	}
+}
+
+// TestPool_Send_replaceConnections tests that the Pool replaces expired and dead idle connections
+func TestPool_Send_replaceConnections(t *testing.T) {
+	serverPort := TestServerPortBase + 61
+	server := startPoolTestServer(t, serverPort, 0)
+	pool := newTestPool(t, serverPort, WithPoolMaxIdleTime(time.Millisecond*200))
+	defer func() { _ = pool.Close() }()
+
+	if err := pool.Send(newPoolTestMsg(t)); err != nil {
+		t.Fatalf("failed to send message through pool: %s", err)
+	}
+	time.Sleep(time.Millisecond * 300)
+	if err := pool.Send(newPoolTestMsg(t)); err != nil {
+		t.Fatalf("failed to send message through pool after idle time: %s", err)
+	}
+	if conns := atomic.LoadInt32(&server.connections); conns != 2 {
+		t.Errorf("Pool.Send failed. Expected expired connection to be replaced, got %d connections", conns)
+	}
+
+	server.closeConnections()
+	time.Sleep(time.Millisecond * 50)
+	message := newPoolTestMsg(t)
+	if err := pool.Send(message); err != nil {
+		t.Fatalf("failed to send message through pool after connection loss: %s", err)
+	}
+	if !message.IsDelivered() || message.HasSendError() {
+		t.Errorf("Pool.Send failed. Message was not delivered: %v", message.SendError())
+	}
+	if conns := atomic.LoadInt32(&server.connections); conns != 3 {
+		t.Errorf("Pool.Send failed. Expected dead connection to be replaced, got %d connections", conns)
+	}
+}
+
+// TestPool_Send_concurrent tests that the Pool does not exceed the maximum number of connections
+func TestPool_Send_concurrent(t *testing.T) {
+	serverPort := TestServerPortBase + 62
+	server := startPoolTestServer(t, serverPort, 0)
+	pool := newTestPool(t, serverPort, WithPoolMaxConnections(2))
+	defer func() { _ = pool.Close() }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			message := NewMsg()
+			_ = message.From("valid-from@domain.tld")
+			_ = message.To("valid-to@domain.tld")
+			message.SetBodyString(TypeTextPlain, "Test body")
+			if err := pool.Send(message); err != nil {
+				t.Errorf("failed to send message through pool: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if delivered := atomic.LoadInt32(&server.delivered); delivered != 10 {
+		t.Errorf("Pool.Send failed. Expected 10 delivered messages, got: %d", delivered)
+	}
+	if conns := atomic.LoadInt32(&server.connections); conns > 2 {
+		t.Errorf("Pool.Send failed. Expected at most 2 connections, got: %d", conns)
+	}
+}
+
+// TestPool_SendConcurrent tests that Pool.SendConcurrent sends the messages in parallel and returns the
+// results in the order of the messages
+func TestPool_SendConcurrent(t *testing.T) {
+	serverPort := TestServerPortBase + 74
+	server := startPoolTestServer(t, serverPort, 2)
+	pool := newTestPool(t, serverPort, WithPoolMaxConnections(4))
+	defer func() { _ = pool.Close() }()
+
+	for _, concurrency := range []int{0, -1} {
+		_, err := pool.SendConcurrent(context.Background(), concurrency, newPoolTestMsg(t))
+		if !errors.Is(err, ErrInvalidPoolConcurrency) {
+			t.Errorf("SendConcurrent with concurrency %d expected error: %s, got: %v", concurrency,
+				ErrInvalidPoolConcurrency, err)
+		}
+	}
+
+	messages := make([]*Msg, 10)
+	for i := range messages {
+		messages[i] = newPoolTestMsg(t)
+	}
+	results, err := pool.SendConcurrent(context.Background(), 3, messages...)
+	if err != nil {
+		t.Fatalf("SendConcurrent failed: %s", err)
+	}
+	if len(results) != len(messages) {
+		t.Fatalf("SendConcurrent expected %d results, got: %d", len(messages), len(results))
+	}
+	for i, result := range results {
+		if result.Msg != messages[i] || result.Recipient != "valid-to@domain.tld" || result.Err != nil {
+			t.Errorf("SendConcurrent result %d unexpected: %+v", i, result)
+		}
+		if !messages[i].IsDelivered() {
+			t.Errorf("SendConcurrent failed. Message %d was not delivered", i)
+		}
+	}
+	if delivered := atomic.LoadInt32(&server.delivered); delivered != 10 {
+		t.Errorf("SendConcurrent expected 10 delivered messages, got: %d", delivered)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = pool.SendConcurrent(ctx, 2, newPoolTestMsg(t), newPoolTestMsg(t))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SendConcurrent with canceled context expected error: %s, got: %v", context.Canceled, err)
+	}
+	if len(results) != 2 || !errors.Is(results[0].Err, context.Canceled) ||
+		!errors.Is(results[1].Err, context.Canceled) {
+		t.Errorf("SendConcurrent with canceled context expected context errors in results, got: %+v", results)
+	}
+	if delivered := atomic.LoadInt32(&server.delivered); delivered != 10 {
+		t.Errorf("SendConcurrent with canceled context expected no delivered messages, got: %d", delivered-10)
+	}
+}
+
+// TestPool_Send_closed tests sending through a closed Pool
+func TestPool_Send_closed(t *testing.T) {
+	pool, err := NewPool(DefaultHost)
+	if err != nil {
+		t.Fatalf("failed to create pool: %s", err)
+	}
+	if err = pool.Close(); err != nil {
+		t.Fatalf("failed to close pool: %s", err)
+	}
+	if err = pool.Send(newPoolTestMsg(t)); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Pool.Send expected error: %s, got: %v", ErrPoolClosed, err)
+	}
+}
+
+// TestPool_LastTLSState tests that Pool.LastTLSState reflects the TLS handshake of the pooled connection
+// that was used to send the last message
+func TestPool_LastTLSState(t *testing.T) {
+	certificate, rootCAs := newTLSServerNameTestCertificate(t, "pool.go-mail.internal")
+	startTLSServerNameTestServer(t, TestServerPortBase+71, certificate, false)
+	pool, err := NewPool(TestServerAddr, WithPoolClientOptions(WithPort(TestServerPortBase+71),
+		WithTLSPortPolicy(TLSMandatory), WithTLSServerName("pool.go-mail.internal"),
+		WithTLSConfig(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12}),
+		WithSMTPAuth(SMTPAuthXOAUTH2), WithUsername("user"), WithPassword("token")))
+	if err != nil {
+		t.Fatalf("failed to create pool: %s", err)
+	}
+	defer func() { _ = pool.Close() }()
+	if _, ok := pool.LastTLSState(); ok {
+		t.Error("Pool.LastTLSState expected no connection state before the first message is sent")
+	}
+	if err = pool.Send(newPoolTestMsg(t)); err != nil {
+		t.Fatalf("failed to send message through pool: %s", err)
+	}
+	state, ok := pool.LastTLSState()
+	if !ok || !state.HandshakeComplete || state.ServerName != "pool.go-mail.internal" {
+		t.Errorf("Pool.LastTLSState expected completed handshake with server name %s, got: %t, %s",
+			"pool.go-mail.internal", ok, state.ServerName)
+	}
+
+	server := startPoolTestServer(t, TestServerPortBase+72, 0)
+	plainPool := newTestPool(t, TestServerPortBase+72)
+	defer func() { _ = plainPool.Close() }()
+	if err = plainPool.Send(newPoolTestMsg(t)); err != nil {
+		t.Fatalf("failed to send message through pool: %s", err)
+	}
+	if _, ok = plainPool.LastTLSState(); ok || atomic.LoadInt32(&server.delivered) != 1 {
+		t.Error("Pool.LastTLSState expected no connection state for unencrypted connection")
+	}
+}
+
+// newTestPool returns a new Pool connecting to the pool test server
+func newTestPool(t *testing.T, port int, opts ...PoolOption) *Pool {
+	t.Helper()
+	opts = append(opts, WithPoolClientOptions(WithPort(port), WithTLSPortPolicy(NoTLS),
+		WithSMTPAuth(SMTPAuthPlain), WithUsername("toni@tester.com"), WithPassword("V3ryS3cr3t+")))
+	pool, err := NewPool(TestServerAddr, opts...)
+	if err != nil {
+		t.Fatalf("failed to create pool: %s", err)
+	}
+	return pool
+}
+
+// newPoolTestMsg returns a new Msg for the pool tests
+func newPoolTestMsg(t *testing.T) *Msg {
+	t.Helper()
+	message := NewMsg()
+	if err := message.From("valid-from@domain.tld"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := message.To("valid-to@domain.tld"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	message.Subject("Test subject")
+	message.SetBodyString(TypeTextPlain, "Test body")
+	return message
+}
+
+// startPoolTestServer starts a pool test server on the given port. If maxPerConn is set, the server
+// closes a connection with a 421 reply once maxPerConn messages have been delivered on it.
+func startPoolTestServer(t *testing.T, port, maxPerConn int) *poolTestServer {
+	t.Helper()
+	listener, err := net.Listen(TestServerProto, fmt.Sprintf("%s:%d", TestServerAddr, port))
+	if err != nil {
+		t.Fatalf("unable to listen on %s:%d: %s", TestServerAddr, port, err)
+	}
+	server := &poolTestServer{listener: listener, maxPerConn: maxPerConn}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		server.closeConnections()
+	})
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&server.connections, 1)
+			server.mutex.Lock()
+			server.openConns = append(server.openConns, connection)
+			server.mutex.Unlock()
+			go server.handle(connection)
+		}
+	}()
+	return server
+}
+
+// closeConnections closes all connections of the pool test server
+func (s *poolTestServer) closeConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, connection := range s.openConns {
+		_ = connection.Close()
+	}
+	s.openConns = nil
+}
+
+// handle handles a single connection of the pool test server
+func (s *poolTestServer) handle(connection net.Conn) {
+	defer func() { _ = connection.Close() }()
+	reader := bufio.NewReader(connection)
+	writeLine := func(data string) {
+		_, _ = connection.Write([]byte(data + "\r\n"))
+	}
+
+	writeLine("220 Welcome to the go-mail pool test server")
+	delivered := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			writeLine("250-localhost.localdomain\r\n250-AUTH PLAIN\r\n250 8BITMIME")
+		case strings.HasPrefix(line, "AUTH"):
+			writeLine("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(line, "MAIL FROM"):
+			if s.maxPerConn > 0 && delivered >= s.maxPerConn {
+				writeLine("421 4.7.0 Too many messages for this connection")
+				return
+			}
+			writeLine("250 2.0.0 OK")
+		case strings.HasPrefix(line, "DATA"):
+			writeLine("354 End data with <CR><LF>.<CR><LF>")
+			for {
+				data, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if data == ".\r\n" {
+					break
+				}
+			}
+			delivered++
+			atomic.AddInt32(&s.delivered, 1)
+			writeLine("250 2.0.0 Ok: queued")
+		case strings.HasPrefix(line, "QUIT"):
+			writeLine("221 2.0.0 Bye")
+			return
+		default:
+			writeLine("250 2.0.0 OK")
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: Copyright 2015 The Go Authors. All rights reserved.
+// SPDX-FileCopyrightText: Copyright (c) 2022-2023 The go-mail Authors
+//
+// Original mime/quotedprintable code from the Go stdlib by the Go Authors.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSES directory.
+//
+// go-mail specific modifications by the go-mail Authors.
+// Licensed under the MIT License.
+// See [PROJECT ROOT]/LICENSES directory for more information.
+//
+// SPDX-License-Identifier: BSD-3-Clause AND MIT
+
+package mail
+
+import "io"
+
+// qpUpperHex holds the upper case hexadecimal digits used for quoted-printable encoding.
+const qpUpperHex = "0123456789ABCDEF"
+
+// qpWriter is a quoted-printable writer that implements the io.WriteCloser interface.
+//
+// It is based on the mime/quotedprintable.Writer of the Go stdlib, but allows to configure the
+// maximum line length of the encoded output, instead of using a fixed length of 76 characters.
+// Encoded "=XX" sequences are never split across lines.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2045#section-6.7
+type qpWriter struct {
+	cr      bool
+	i       int
+	line    [MaxBodyLength + 2]byte
+	lineLen int
+	w       io.Writer
+}
+
+// newQPWriter returns a new qpWriter that writes to the given io.Writer.
+//
+// Parameters:
+//   - writer: The io.Writer the quoted-printable encoded output is written to.
+//   - lineLength: The maximum line length of the encoded output, including the soft line break
+//     character. The length is normalized using normalizeLineLength.
+//
+// Returns:
+//   - A pointer to the newly created qpWriter.
+func newQPWriter(writer io.Writer, lineLength int) *qpWriter {
+	return &qpWriter{w: writer, lineLen: normalizeLineLength(lineLength)}
+}
+
+// Write encodes the given data using quoted-printable encoding and writes it to the underlying
+// io.Writer. The encoded bytes are not necessarily flushed until the qpWriter is closed.
+//
+// Parameters:
+//   - data: A byte slice containing the data to be encoded.
+//
+// Returns:
+//   - The number of bytes consumed from data.
+//   - An error if writing to the underlying io.Writer fails.
+func (w *qpWriter) Write(data []byte) (n int, err error) {
+	for i, b := range data {
+		switch {
+		// Simple writes are done in batch.
+		case b >= '!' && b <= '~' && b != '=':
+			continue
+		case isQPWhitespace(b) || b == '\n' || b == '\r':
+			continue
+		}
+
+		if i > n {
+			if err = w.write(data[n:i]); err != nil {
+				return n, err
+			}
+			n = i
+		}
+
+		if err = w.encode(b); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if n == len(data) {
+		return n, nil
+	}
+
+	if err = w.write(data[n:]); err != nil {
+		return n, err
+	}
+
+	return len(data), nil
+}
+
+// Close flushes any unwritten data to the underlying io.Writer, but does not close the
+// underlying io.Writer.
+//
+// Returns:
+//   - An error if writing to the underlying io.Writer fails.
+func (w *qpWriter) Close() error {
+	if err := w.checkLastByte(); err != nil {
+		return err
+	}
+
+	return w.flush()
+}
+
+// write limits the quoted-printable encoded text to the configured line length.
+func (w *qpWriter) write(data []byte) error {
+	for _, b := range data {
+		if b == '\n' || b == '\r' {
+			// If the previous byte was \r, the CRLF has already been inserted.
+			if w.cr && b == '\n' {
+				w.cr = false
+				continue
+			}
+
+			if b == '\r' {
+				w.cr = true
+			}
+
+			if err := w.checkLastByte(); err != nil {
+				return err
+			}
+			if err := w.insertCRLF(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if w.i == w.lineLen-1 {
+			if err := w.insertSoftLineBreak(); err != nil {
+				return err
+			}
+		}
+
+		w.line[w.i] = b
+		w.i++
+		w.cr = false
+	}
+
+	return nil
+}
+
+// encode writes the given byte as "=XX" sequence, inserting a soft line break first if the
+// sequence would not fit into the current line.
+func (w *qpWriter) encode(b byte) error {
+	if w.lineLen-1-w.i < 3 {
+		if err := w.insertSoftLineBreak(); err != nil {
+			return err
+		}
+	}
+
+	w.line[w.i] = '='
+	w.line[w.i+1] = qpUpperHex[b>>4]
+	w.line[w.i+2] = qpUpperHex[b&0x0f]
+	w.i += 3
+
+	return nil
+}
+
+// checkLastByte encodes the last buffered byte if it is a space or a tab.
+func (w *qpWriter) checkLastByte() error {
+	if w.i == 0 {
+		return nil
+	}
+
+	b := w.line[w.i-1]
+	if isQPWhitespace(b) {
+		w.i--
+		if err := w.encode(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertSoftLineBreak terminates the current line with a soft line break.
+func (w *qpWriter) insertSoftLineBreak() error {
+	w.line[w.i] = '='
+	w.i++
+
+	return w.insertCRLF()
+}
+
+// insertCRLF terminates the current line with a CRLF and flushes it.
+func (w *qpWriter) insertCRLF() error {
+	w.line[w.i] = '\r'
+	w.line[w.i+1] = '\n'
+	w.i += 2
+
+	return w.flush()
+}
+
+// flush writes the buffered line to the underlying io.Writer.
+func (w *qpWriter) flush() error {
+	if _, err := w.w.Write(w.line[:w.i]); err != nil {
+		return err
+	}
+
+	w.i = 0
+	return nil
+}
+
+// isQPWhitespace returns true if the given byte is a space or a tab.
+func isQPWhitespace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
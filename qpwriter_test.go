@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime/quotedprintable"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// qpTestData holds test inputs for the qpWriter tests
+var qpTestData = []string{
+	"",
+	"Simple text",
+	"Trailing whitespace \r\nand a\ttab\t",
+	"Umlauts: äöüß and emojis: ☝️💪👍, followed by a very long line that needs to be wrapped at some point",
+	strings.Repeat("=", 120),
+	strings.Repeat("a", 74) + "äöü" + strings.Repeat("b", 100),
+	"Mixed\nline\rendings\r\nare\n\nnormalized",
+}
+
+// TestQPWriter_DefaultLength tests that the qpWriter produces the same output as the stdlib
+// quoted-printable writer with the default line length
+func TestQPWriter_DefaultLength(t *testing.T) {
+	for _, data := range qpTestData {
+		var want, got bytes.Buffer
+		stdWriter := quotedprintable.NewWriter(&want)
+		if _, err := stdWriter.Write([]byte(data)); err != nil {
+			t.Fatalf("failed to write to stdlib writer: %s", err)
+		}
+		if err := stdWriter.Close(); err != nil {
+			t.Fatalf("failed to close stdlib writer: %s", err)
+		}
+		writer := newQPWriter(&got, 0)
+		if _, err := writer.Write([]byte(data)); err != nil {
+			t.Fatalf("failed to write to qpWriter: %s", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("failed to close qpWriter: %s", err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("qpWriter output differs from stdlib. Expected: %q, got: %q", want.String(), got.String())
+		}
+	}
+}
+
+// TestQPWriter_LineLength tests the qpWriter with custom line lengths
+func TestQPWriter_LineLength(t *testing.T) {
+	splitSeq := regexp.MustCompile(`=[0-9A-F]?=\r\n`)
+	for _, length := range []int{MinBodyLength, 10, 72, MaxBodyLength} {
+		for _, data := range qpTestData {
+			var buffer bytes.Buffer
+			writer := newQPWriter(&buffer, length)
+			if _, err := writer.Write([]byte(data)); err != nil {
+				t.Fatalf("failed to write to qpWriter: %s", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close qpWriter: %s", err)
+			}
+			for _, line := range strings.Split(buffer.String(), "\r\n") {
+				if len(line) > length {
+					t.Errorf("qpWriter line exceeds %d characters: %q", length, line)
+				}
+			}
+			if splitSeq.MatchString(buffer.String()) {
+				t.Errorf("qpWriter split an encoded sequence across lines: %q", buffer.String())
+			}
+			decoded, err := io.ReadAll(quotedprintable.NewReader(&buffer))
+			if err != nil {
+				t.Fatalf("failed to decode qpWriter output: %s", err)
+			}
+			want := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+			got := strings.ReplaceAll(string(decoded), "\r\n", "\n")
+			if got != want {
+				t.Errorf("qpWriter round trip failed. Expected: %q, got: %q", want, got)
+			}
+		}
+	}
+}
+
+// TestQPWriter_WriteError tests the qpWriter with a failing io.Writer
+func TestQPWriter_WriteError(t *testing.T) {
+	writer := newQPWriter(errorWriter{}, 10)
+	if _, err := writer.Write([]byte(strings.Repeat("äöü", 10))); err == nil {
+		t.Error("writing to qpWriter with errorWriter was supposed to fail, but didn't")
+	}
+}
+
+// TestNormalizeLineLength tests the normalizeLineLength function
+func TestNormalizeLineLength(t *testing.T) {
+	tests := []struct {
+		length int
+		want   int
+	}{
+		{-1, MaxBodyLength},
+		{0, MaxBodyLength},
+		{1, MinBodyLength},
+		{MinBodyLength, MinBodyLength},
+		{72, 72},
+		{MaxBodyLength, MaxBodyLength},
+		{1000, MaxBodyLength},
+	}
+	for _, tt := range tests {
+		if got := normalizeLineLength(tt.length); got != tt.want {
+			t.Errorf("normalizeLineLength(%d) failed. Expected: %d, got: %d", tt.length, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRateLimit is returned when the provided rate limit is zero or negative.
+var ErrInvalidRateLimit = errors.New("invalid rate limit - must be greater than zero")
+
+// RateLimiter is a token bucket rate limiter that limits the number of messages sent per second.
+//
+// The bucket is refilled with tokens at the configured rate and holds at most a single token, so
+// that the messages are spread evenly over time and the limit is never exceeded within any second.
+// Each message consumes one token. A RateLimiter is safe for concurrent use, so it can be shared by
+// multiple senders, and its limit can be adjusted at runtime via SetLimit.
+type RateLimiter struct {
+	// last is the point in time at which the tokens have been refilled for the last time.
+	last time.Time
+
+	// limit is the number of tokens that are added to the bucket per second.
+	limit int
+
+	// mutex is used to synchronize the access to the state of the RateLimiter.
+	mutex sync.Mutex
+
+	// tokens is the number of tokens that are currently available in the bucket.
+	tokens float64
+}
+
+// NewRateLimiter returns a new RateLimiter that permits the given number of messages per second.
+//
+// The bucket of the returned RateLimiter is initially full, so that the first message is permitted
+// without waiting.
+//
+// Parameters:
+//   - perSecond: The maximum number of messages per second. Must be greater than zero.
+//
+// Returns:
+//   - A pointer to the new RateLimiter.
+//   - An error if the provided limit is zero or negative.
+func NewRateLimiter(perSecond int) (*RateLimiter, error) {
+	if perSecond <= 0 {
+		return nil, ErrInvalidRateLimit
+	}
+	return &RateLimiter{last: time.Now(), limit: perSecond, tokens: 1}, nil
+}
+
+// Limit returns the number of messages per second that is currently permitted by the RateLimiter.
+//
+// Returns:
+//   - The maximum number of messages per second.
+func (r *RateLimiter) Limit() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.limit
+}
+
+// SetLimit adjusts the number of messages per second that are permitted by the RateLimiter.
+//
+// The new limit applies to all waiting and future calls of Wait. Tokens that have been accumulated
+// at the previous rate are kept.
+//
+// Parameters:
+//   - perSecond: The maximum number of messages per second. Must be greater than zero.
+//
+// Returns:
+//   - An error if the provided limit is zero or negative, in which case the limit is not changed.
+func (r *RateLimiter) SetLimit(perSecond int) error {
+	if perSecond <= 0 {
+		return ErrInvalidRateLimit
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.refill(time.Now())
+	r.limit = perSecond
+	return nil
+}
+
+// Wait blocks until the RateLimiter permits sending a message, or until the provided context is done.
+//
+// Parameters:
+//   - ctx: The context.Context to cancel the waiting.
+//
+// Returns:
+//   - The error of the context if it is done before a message is permitted; otherwise, returns nil.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mutex.Lock()
+		r.refill(time.Now())
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mutex.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - r.tokens) / float64(r.limit) * float64(time.Second))
+		r.mutex.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refill adds the tokens to the bucket that have accumulated since the last refill. The bucket never
+// holds more than a single token. The caller must hold the mutex of the RateLimiter.
+//
+// Parameters:
+//   - now: The current point in time.
+func (r *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(r.last)
+	r.last = now
+	if elapsed <= 0 {
+		return
+	}
+	r.tokens += elapsed.Seconds() * float64(r.limit)
+	if r.tokens > 1 {
+		r.tokens = 1
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestNewRateLimiter tests the NewRateLimiter function and the RateLimiter.SetLimit method
+func TestNewRateLimiter(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		if _, err := NewRateLimiter(limit); !errors.Is(err, ErrInvalidRateLimit) {
+			t.Errorf("NewRateLimiter(%d) expected error: %s, got: %v", limit, ErrInvalidRateLimit, err)
+		}
+	}
+	limiter, err := NewRateLimiter(5)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %s", err)
+	}
+	if limiter.Limit() != 5 {
+		t.Errorf("NewRateLimiter failed. Expected limit: 5, got: %d", limiter.Limit())
+	}
+	if err = limiter.SetLimit(0); !errors.Is(err, ErrInvalidRateLimit) {
+		t.Errorf("SetLimit(0) expected error: %s, got: %v", ErrInvalidRateLimit, err)
+	}
+	if err = limiter.SetLimit(20); err != nil {
+		t.Errorf("SetLimit failed: %s", err)
+	}
+	if limiter.Limit() != 20 {
+		t.Errorf("SetLimit failed. Expected limit: 20, got: %d", limiter.Limit())
+	}
+}
+
+// TestRateLimiter_Wait tests that the RateLimiter spreads concurrent waits evenly and that the
+// waiting can be canceled
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter, err := NewRateLimiter(20)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %s", err)
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Wait failed: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < time.Millisecond*190 {
+		t.Errorf("Wait failed. Expected 5 waits at 20 per second to take at least 200ms, took: %s", elapsed)
+	}
+
+	if err = limiter.SetLimit(1); err != nil {
+		t.Fatalf("SetLimit failed: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	start = time.Now()
+	if err = limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait expected error: %s, got: %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("Wait failed. Expected canceled wait to return early, took: %s", elapsed)
+	}
+}
+
+// TestClient_WithRateLimit tests the WithRateLimit option of the Client
+func TestClient_WithRateLimit(t *testing.T) {
+	if _, err := NewMemoryClient(WithRateLimit(0)); !errors.Is(err, ErrInvalidRateLimit) {
+		t.Errorf("WithRateLimit(0) expected error: %s, got: %v", ErrInvalidRateLimit, err)
+	}
+	client, err := NewMemoryClient(WithRateLimit(20))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	if client.RateLimiter() == nil || client.RateLimiter().Limit() != 20 {
+		t.Fatalf("WithRateLimit failed. Expected RateLimiter with limit 20, got: %+v", client.RateLimiter())
+	}
+	start := time.Now()
+	if err = client.DialAndSend(newPoolTestMsg(t), newPoolTestMsg(t), newPoolTestMsg(t)); err != nil {
+		t.Fatalf("DialAndSend failed: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*90 {
+		t.Errorf("DialAndSend failed. Expected 3 messages at 20 per second to take at least 100ms, took: %s",
+			elapsed)
+	}
+	if len(client.Messages()) != 3 {
+		t.Errorf("DialAndSend failed. Expected 3 delivered messages, got: %d", len(client.Messages()))
+	}
+
+	if err = client.RateLimiter().SetLimit(1); err != nil {
+		t.Fatalf("SetLimit failed: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	message := newPoolTestMsg(t)
+	err = client.DialAndSendWithContext(ctx, message)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DialAndSendWithContext expected error: %s, got: %v", context.DeadlineExceeded, err)
+	}
+	if message.IsDelivered() {
+		t.Error("DialAndSendWithContext expected the message not to be delivered")
+	}
+	var sendErr *SendError
+	if !errors.As(message.SendError(), &sendErr) || sendErr.Reason != ErrRateLimit {
+		t.Errorf("DialAndSendWithContext expected rate limit send error, got: %v", message.SendError())
+	}
+}
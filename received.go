@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReceivedHeader indicates that a "Received" header of the Msg could not be parsed completely.
+var ErrInvalidReceivedHeader = errors.New("invalid Received header")
+
+// ReceivedHop represents a single hop of the delivery path of a Msg, as recorded in a "Received" header.
+//
+// The clauses of the header are extracted on a best-effort basis, since the format of the "Received" header
+// varies greatly between MTAs. Each clause holds the complete text that follows its keyword up to the next
+// clause, including comments, e.g. "mail.example.com (mail.example.com [192.0.2.1])" for the "from" clause.
+// Empty clauses have not been present in the header.
+type ReceivedHop struct {
+	// By is the "by" clause, i.e. the host that received the Msg.
+	By string
+
+	// For is the "for" clause, i.e. the recipient the Msg was received for.
+	For string
+
+	// From is the "from" clause, i.e. the host the Msg was received from.
+	From string
+
+	// ID is the "id" clause, i.e. the queue ID the receiving host assigned to the Msg.
+	ID string
+
+	// Raw is the complete, unfolded value of the "Received" header.
+	Raw string
+
+	// Timestamp is the date and time at which the Msg was received. It is the zero time if the header
+	// holds no valid RFC 5322 date-time.
+	Timestamp time.Time
+
+	// Unparsed holds the portions of the header that could not be assigned to a clause or the date-time,
+	// e.g. text in front of the first clause or an invalid date-time.
+	Unparsed string
+
+	// Via is the "via" clause, i.e. the link type the Msg was received over.
+	Via string
+
+	// With is the "with" clause, i.e. the protocol the Msg was received with, e.g. "ESMTPS".
+	With string
+}
+
+// ReceivedChain returns the hops of the delivery path of the Msg, as recorded in its "Received" headers.
+//
+// Each "Received" header, e.g. of a Msg that has been imported via EMLToMsgFromReader, is parsed into a
+// ReceivedHop. The hops are returned in the order of the headers, i.e. the first hop is the topmost header,
+// which has been added by the most recent host. Since the format of the "Received" header varies greatly,
+// the clauses are extracted on a best-effort basis and portions that cannot be parsed are kept in
+// ReceivedHop.Unparsed. The complete header value is always kept in ReceivedHop.Raw.
+//
+// Returns:
+//   - A slice of ReceivedHop, one for each "Received" header, or nil if the Msg has no "Received" header.
+//   - An error wrapping ErrInvalidReceivedHeader if the date-time of any header could not be parsed. The
+//     hops are returned nonetheless, with a zero ReceivedHop.Timestamp for the affected headers.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.4
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.7
+func (m *Msg) ReceivedChain() ([]ReceivedHop, error) {
+	values := m.genHeader[HeaderReceived]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	hops := make([]ReceivedHop, 0, len(values))
+	var invalid []string
+	for i, value := range values {
+		hop, ok := parseReceivedHop(value)
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%d", i+1))
+		}
+		hops = append(hops, hop)
+	}
+	if len(invalid) > 0 {
+		return hops, fmt.Errorf("%w: no valid date-time in header(s) %s", ErrInvalidReceivedHeader,
+			strings.Join(invalid, ", "))
+	}
+	return hops, nil
+}
+
+// parseReceivedHop parses the value of a single "Received" header into a ReceivedHop.
+//
+// Parameters:
+//   - value: The value of the "Received" header.
+//
+// Returns:
+//   - The parsed ReceivedHop.
+//   - A boolean indicating whether the date-time of the header could be parsed.
+func parseReceivedHop(value string) (ReceivedHop, bool) {
+	hop := ReceivedHop{Raw: strings.Join(strings.Fields(value), " ")}
+	clauses, dateTime := hop.Raw, ""
+	if separator := receivedDateSeparator(hop.Raw); separator != -1 {
+		clauses, dateTime = hop.Raw[:separator], strings.TrimSpace(hop.Raw[separator+1:])
+	}
+
+	var unparsed []string
+	var current *string
+	var text []string
+	flush := func() {
+		if current != nil {
+			*current = strings.Join(text, " ")
+		}
+		if current == nil && len(text) > 0 {
+			unparsed = append(unparsed, strings.Join(text, " "))
+		}
+		text = nil
+	}
+	fields := map[string]*string{
+		"from": &hop.From, "by": &hop.By, "via": &hop.Via, "with": &hop.With, "id": &hop.ID, "for": &hop.For,
+	}
+	for _, token := range receivedTokens(clauses) {
+		if field, ok := fields[strings.ToLower(token)]; ok && *field == "" && field != current {
+			flush()
+			current = field
+			continue
+		}
+		text = append(text, token)
+	}
+	flush()
+
+	valid := false
+	if dateTime != "" {
+		if timestamp, err := parseReceivedDate(dateTime); err == nil {
+			hop.Timestamp, valid = timestamp, true
+		}
+	}
+	if dateTime != "" && !valid {
+		unparsed = append(unparsed, dateTime)
+	}
+	hop.Unparsed = strings.Join(unparsed, " ")
+	return hop, valid
+}
+
+// receivedDateSeparator returns the index of the semicolon that separates the clauses of a "Received"
+// header from its date-time.
+//
+// Semicolons within comments are ignored.
+//
+// Parameters:
+//   - value: The value of the "Received" header.
+//
+// Returns:
+//   - The index of the last semicolon outside of comments, or -1 if there is none.
+func receivedDateSeparator(value string) int {
+	separator, depth := -1, 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ';':
+			if depth == 0 {
+				separator = i
+			}
+		}
+	}
+	return separator
+}
+
+// receivedTokens splits the clauses of a "Received" header into tokens separated by spaces.
+//
+// Comments are kept as a single token, including their parentheses, so that keywords within comments
+// are not mistaken for clauses.
+//
+// Parameters:
+//   - clauses: The clauses of the "Received" header, without the date-time.
+//
+// Returns:
+//   - A slice of the tokens of the clauses.
+func receivedTokens(clauses string) []string {
+	var tokens []string
+	var token strings.Builder
+	depth := 0
+	for i := 0; i < len(clauses); i++ {
+		char := clauses[i]
+		switch {
+		case char == '(':
+			depth++
+		case char == ')' && depth > 0:
+			depth--
+		case char == ' ' && depth == 0:
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+			continue
+		}
+		token.WriteByte(char)
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+// parseReceivedDate parses the date-time of a "Received" header.
+//
+// A trailing comment, like the "(PDT)" that many MTAs append to the date-time, is removed before the
+// date-time is parsed.
+//
+// Parameters:
+//   - dateTime: The date-time of the "Received" header.
+//
+// Returns:
+//   - The parsed date and time.
+//   - An error if the date-time is not a valid RFC 5322 date-time.
+func parseReceivedDate(dateTime string) (time.Time, error) {
+	if strings.HasSuffix(dateTime, ")") {
+		if start := strings.LastIndex(dateTime, "("); start > 0 {
+			dateTime = strings.TrimSpace(dateTime[:start])
+		}
+	}
+	return mail.ParseDate(dateTime)
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testReceivedEML = "Received: from relay.example.net (relay.example.net [198.51.100.7])\r\n" +
+	"\tby mx.example.org (Postfix) with ESMTPS id 4XyZ123\r\n" +
+	"\tfor <toni.tester@example.org>; Tue, 1 Oct 2024 10:00:05 -0700 (PDT)\r\n" +
+	"Received: from mail.example.com (mail.example.com [192.0.2.1]) by relay.example.net\r\n" +
+	" with ESMTP id abc-123; Tue, 1 Oct 2024 17:00:02 +0000\r\n" +
+	"Received: (qmail 12345 invoked by uid 1000); 1 Oct 2024 17:00:00 +0000\r\n" +
+	"From: sender@example.com\r\n" +
+	"To: toni.tester@example.org\r\n" +
+	"Subject: Trace test\r\n" +
+	"Date: Tue, 1 Oct 2024 17:00:00 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"Content-Transfer-Encoding: 7bit\r\n" +
+	"\r\n" +
+	"Trace test\r\n"
+
+// TestMsg_ReceivedChain tests that Msg.ReceivedChain parses the Received headers of an imported EML in order
+func TestMsg_ReceivedChain(t *testing.T) {
+	message, err := EMLToMsgFromString(testReceivedEML)
+	if err != nil {
+		t.Fatalf("failed to parse EML: %s", err)
+	}
+	want := []ReceivedHop{
+		{
+			From: "relay.example.net (relay.example.net [198.51.100.7])", By: "mx.example.org (Postfix)",
+			With: "ESMTPS", ID: "4XyZ123", For: "<toni.tester@example.org>",
+			Timestamp: time.Date(2024, 10, 1, 17, 0, 5, 0, time.UTC),
+		},
+		{
+			From: "mail.example.com (mail.example.com [192.0.2.1])", By: "relay.example.net",
+			With: "ESMTP", ID: "abc-123", Timestamp: time.Date(2024, 10, 1, 17, 0, 2, 0, time.UTC),
+		},
+		{
+			Unparsed: "(qmail 12345 invoked by uid 1000)", Timestamp: time.Date(2024, 10, 1, 17, 0, 0, 0, time.UTC),
+		},
+	}
+	checkChain := func(t *testing.T, message *Msg) {
+		t.Helper()
+		hops, err := message.ReceivedChain()
+		if err != nil {
+			t.Fatalf("ReceivedChain failed: %s", err)
+		}
+		if len(hops) != len(want) {
+			t.Fatalf("ReceivedChain expected %d hops, got: %d", len(want), len(hops))
+		}
+		for i, hop := range hops {
+			if hop.From != want[i].From || hop.By != want[i].By || hop.With != want[i].With ||
+				hop.ID != want[i].ID || hop.For != want[i].For || hop.Via != "" || hop.Unparsed != want[i].Unparsed {
+				t.Errorf("ReceivedChain hop %d unexpected. Want: %+v, got: %+v", i, want[i], hop)
+			}
+			if !hop.Timestamp.Equal(want[i].Timestamp) {
+				t.Errorf("ReceivedChain hop %d expected timestamp: %s, got: %s", i, want[i].Timestamp, hop.Timestamp)
+			}
+			if hop.Raw == "" {
+				t.Errorf("ReceivedChain hop %d expected raw header value", i)
+			}
+		}
+	}
+	checkChain(t, message)
+
+	// The chain is written back in the same order, so that it survives a round trip
+	buffer := bytes.Buffer{}
+	if _, err = message.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !bytes.HasPrefix(buffer.Bytes(), []byte("Received: from relay.example.net")) {
+		t.Errorf("expected Received headers at the top of the message, got: %s", buffer.String())
+	}
+	reparsed, err := EMLToMsgFromReader(&buffer)
+	if err != nil {
+		t.Fatalf("failed to parse written message: %s", err)
+	}
+	checkChain(t, reparsed)
+
+	if hops, err := NewMsg().ReceivedChain(); hops != nil || err != nil {
+		t.Errorf("ReceivedChain without Received headers expected no hops, got: %v, %v", hops, err)
+	}
+}
+
+// TestMsg_ReceivedChain_invalid tests that Msg.ReceivedChain keeps unparseable portions of Received headers
+func TestMsg_ReceivedChain_invalid(t *testing.T) {
+	message := NewMsg()
+	message.SetGenHeader(HeaderReceived, "from a.example.com by b.example.com; yesterday",
+		"by c.example.com (comment with by and ; semicolon) via UUCP")
+	hops, err := message.ReceivedChain()
+	if !errors.Is(err, ErrInvalidReceivedHeader) {
+		t.Errorf("ReceivedChain expected error: %s, got: %v", ErrInvalidReceivedHeader, err)
+	}
+	if len(hops) != 2 {
+		t.Fatalf("ReceivedChain expected 2 hops, got: %d", len(hops))
+	}
+	if hops[0].From != "a.example.com" || hops[0].By != "b.example.com" || hops[0].Unparsed != "yesterday" ||
+		!hops[0].Timestamp.IsZero() {
+		t.Errorf("ReceivedChain hop with invalid date-time unexpected: %+v", hops[0])
+	}
+	if hops[1].By != "c.example.com (comment with by and ; semicolon)" || hops[1].Via != "UUCP" ||
+		hops[1].Unparsed != "" || !hops[1].Timestamp.IsZero() {
+		t.Errorf("ReceivedChain hop without date-time unexpected: %+v", hops[1])
+	}
+}
@@ -6,6 +6,7 @@ package mail
 
 import (
 	"errors"
+	"net/textproto"
 	"strings"
 )
 
@@ -52,18 +53,34 @@ const (
 	// ErrAmbiguous is a generalized delivery error for the SendError type that is
 	// returned if the exact reason for the delivery failure is ambiguous
 	ErrAmbiguous
+
+	// ErrMsgValidation is returned if the Msg delivery failed because the Msg did not pass
+	// the validation of Msg.Validate
+	ErrMsgValidation
+
+	// ErrRateLimit is returned if the Msg was not sent because the waiting for the rate limit
+	// of the Client was canceled
+	ErrRateLimit
+
+	// ErrMessageTooLarge is returned if the Msg was not sent because its size exceeds the maximum
+	// message size of the Client or the SIZE limit advertised by the server
+	ErrMessageTooLarge
 )
 
 // SendError is an error wrapper for delivery errors of the Msg.
 //
 // This struct represents an error that occurs during the delivery of a message. It holds
-// details about the affected message, a list of errors, the recipient list, and whether
-// the error is temporary or permanent. It also includes a reason code for the error.
+// details about the affected message, a list of errors, the recipient list, the SMTP reply
+// code and whether the error is temporary or permanent. It also includes a reason code for
+// the error. If the delivery failed for multiple recipients, the individual errors for each
+// recipient are available via RecipientErrors.
 type SendError struct {
 	affectedMsg *Msg
+	errcode     int
 	errlist     []error
 	isTemp      bool
 	rcpt        []string
+	rcptErrs    []*SendError
 	Reason      SendErrReason
 }
 
@@ -74,14 +91,14 @@ type SendErrReason int
 //
 // This function returns a detailed error message string for the SendError, including the
 // reason for failure, list of errors, affected recipients, and the message ID of the
-// affected message (if available). If the reason is unknown (greater than 10), it returns
+// affected message (if available). If the reason is unknown (greater than 12), it returns
 // "unknown reason". The error message is built dynamically based on the content of the
 // error list, recipient list, and message ID.
 //
 // Returns:
 //   - A string representing the error message.
 func (e *SendError) Error() string {
-	if e.Reason > 10 {
+	if e.Reason > 13 {
 		return "unknown reason"
 	}
 
@@ -133,6 +150,24 @@ func (e *SendError) Is(errType error) bool {
 	return false
 }
 
+// ErrorCode returns the SMTP reply code of the server response that caused the delivery error.
+//
+// This function returns the three-digit SMTP reply code (e.g. 450 or 550) of the server response
+// that caused the SendError. If the error was not caused by a SMTP server response (e.g. a network
+// or local error) or the SendError is nil, it returns 0.
+//
+// Returns:
+//   - The SMTP reply code of the error, or 0 if not available.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5321#section-4.2
+func (e *SendError) ErrorCode() int {
+	if e == nil {
+		return 0
+	}
+	return e.errcode
+}
+
 // IsTemp returns true if the delivery error is of a temporary nature and can be retried.
 //
 // This function checks whether the SendError indicates a temporary error, which suggests
@@ -147,6 +182,33 @@ func (e *SendError) IsTemp() bool {
 	return e.isTemp
 }
 
+// Recipients returns the list of recipients affected by the delivery error.
+//
+// Returns:
+//   - A slice of recipient addresses, or nil if the SendError is nil or no recipient was affected.
+func (e *SendError) Recipients() []string {
+	if e == nil {
+		return nil
+	}
+	return e.rcpt
+}
+
+// RecipientErrors returns the individual delivery errors for each affected recipient.
+//
+// If the delivery failed during the RCPT TO command for one or more recipients, this function
+// returns a SendError for each of the affected recipients, holding the error, SMTP reply code
+// and temporary state of that specific recipient. This allows to distinguish recipients that
+// can be retried from recipients that were rejected permanently.
+//
+// Returns:
+//   - A slice of SendError pointers, one for each affected recipient, or nil if not available.
+func (e *SendError) RecipientErrors() []*SendError {
+	if e == nil {
+		return nil
+	}
+	return e.rcptErrs
+}
+
 // MessageID returns the message ID of the affected Msg that caused the error.
 //
 // This function retrieves the message ID of the Msg associated with the SendError.
@@ -207,14 +269,39 @@ func (r SendErrReason) String() string {
 		return ErrServerNoUnencoded.Error()
 	case ErrAmbiguous:
 		return "ambiguous reason, check Msg.SendError for message specific reasons"
+	case ErrMsgValidation:
+		return "validating message"
+	case ErrRateLimit:
+		return "waiting for rate limit"
+	case ErrMessageTooLarge:
+		return "checking message size"
 	}
 	return "unknown reason"
 }
 
+// errorCode returns the SMTP reply code of the given error.
+//
+// This function checks if the given error is or wraps a textproto.Error as returned by the
+// smtp.Client for negative server responses and returns its reply code.
+//
+// Parameters:
+//   - err: The error to inspect.
+//
+// Returns:
+//   - The SMTP reply code of the error, or 0 if the error is not a SMTP server response.
+func errorCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
 // isTempError checks if the given SMTP error is of a temporary nature and should be retried.
 //
-// This function inspects the error message and returns true if the first character of the
-// error message is '4', indicating a temporary SMTP error that can be retried.
+// This function inspects the SMTP reply code of the error and returns true if it is a 4xx code,
+// indicating a temporary SMTP error that can be retried. If the error does not carry a SMTP reply
+// code, it returns true if the first character of the error message is '4'.
 //
 // Parameters:
 //   - err: The error to check.
@@ -222,5 +309,8 @@ func (r SendErrReason) String() string {
 // Returns:
 //   - true if the error is temporary, false otherwise.
 func isTempError(err error) bool {
+	if code := errorCode(err); code > 0 {
+		return code >= 400 && code < 500
+	}
 	return err.Error()[0] == '4'
 }
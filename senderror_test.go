@@ -7,6 +7,7 @@ package mail
 import (
 	"errors"
 	"fmt"
+	"net/textproto"
 	"strings"
 	"testing"
 )
@@ -40,6 +41,12 @@ func TestSendError_Error(t *testing.T) {
 		{"ErrNoUnencoded/perm", ErrNoUnencoded, false},
 		{"ErrAmbiguous/temp", ErrAmbiguous, true},
 		{"ErrAmbiguous/perm", ErrAmbiguous, false},
+		{"ErrMsgValidation/temp", ErrMsgValidation, true},
+		{"ErrMsgValidation/perm", ErrMsgValidation, false},
+		{"ErrRateLimit/temp", ErrRateLimit, true},
+		{"ErrRateLimit/perm", ErrRateLimit, false},
+		{"ErrMessageTooLarge/temp", ErrMessageTooLarge, true},
+		{"ErrMessageTooLarge/perm", ErrMessageTooLarge, false},
 		{"Unknown/temp", 9999, true},
 		{"Unknown/perm", 9999, false},
 	}
@@ -83,6 +90,47 @@ func TestSendError_IsTemp(t *testing.T) {
 	}
 }
 
+func TestSendError_ErrorCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   int
+		isTemp bool
+	}{
+		{"temporary SMTP error", &textproto.Error{Code: 421, Msg: "4.4.2 Timeout"}, 421, true},
+		{"permanent SMTP error", &textproto.Error{Code: 550, Msg: "5.1.1 User unknown"}, 550, false},
+		{
+			"wrapped SMTP error", fmt.Errorf("wrapped: %w", &textproto.Error{Code: 452, Msg: "4.3.1 Full"}),
+			452, true,
+		},
+		{"non-SMTP error", errors.New("connection reset by peer"), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			se := &SendError{
+				Reason: ErrSMTPMailFrom, errlist: []error{tt.err}, isTemp: isTempError(tt.err),
+				errcode: errorCode(tt.err),
+			}
+			if se.ErrorCode() != tt.code {
+				t.Errorf("expected error code: %d, got: %d", tt.code, se.ErrorCode())
+			}
+			if se.IsTemp() != tt.isTemp {
+				t.Errorf("expected temporary state: %t, got: %t", tt.isTemp, se.IsTemp())
+			}
+		})
+	}
+}
+
+func TestSendError_ErrorCodeNil(t *testing.T) {
+	var se *SendError
+	if se.ErrorCode() != 0 {
+		t.Error("expected 0 on nil-senderror")
+	}
+	if se.Recipients() != nil || se.RecipientErrors() != nil {
+		t.Error("expected nil recipients on nil-senderror")
+	}
+}
+
 func TestSendError_IsTempNil(t *testing.T) {
 	var se *SendError
 	if se.IsTemp() {
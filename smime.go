@@ -0,0 +1,644 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SMIMESignerOption is a function type that modifies the settings of a SMIMESigner.
+type SMIMESignerOption func(*SMIMESigner) error
+
+// SMIMESigner is a Middleware that S/MIME signs and optionally encrypts the body of a Msg.
+//
+// The SMIMESigner renders the MIME structure of the Msg, canonicalizes its line breaks to CRLF and
+// wraps it into a multipart/signed structure with a detached application/pkcs7-signature part. The
+// signature is a CMS SignedData structure using SHA-256, created with the provided RSA or ECDSA
+// private key. If encryption is enabled via WithSMIMEEncryption, the signed entity is additionally
+// encrypted for the given recipient certificates into an application/pkcs7-mime enveloped-data
+// structure using AES-256-CBC. The headers of the Msg (e.g. From, To and Subject) are not protected.
+//
+// Since the Msg is modified on the fly when it is written, the original Msg stays untouched. If the
+// SMIMESigner is used together with the DKIMSigner, the SMIMESigner must be applied first.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551
+//   - https://datatracker.ietf.org/doc/html/rfc5652
+//   - https://datatracker.ietf.org/doc/html/rfc1847
+type SMIMESigner struct {
+	certificate   *x509.Certificate
+	intermediates []*x509.Certificate
+	key           crypto.Signer
+	recipients    []*x509.Certificate
+}
+
+// MiddlewareTypeSMIME is the MiddlewareType of the SMIMESigner.
+const MiddlewareTypeSMIME MiddlewareType = "smime"
+
+var (
+	// ErrSMIMENoCertificate is returned when no signing certificate is provided to the SMIMESigner.
+	ErrSMIMENoCertificate = errors.New("S/MIME signing certificate must not be nil")
+
+	// ErrSMIMEInvalidKey is returned when the private key provided to the SMIMESigner is nil, of an
+	// unsupported type or does not match the public key of the signing certificate.
+	ErrSMIMEInvalidKey = errors.New("S/MIME private key must be a RSA or ECDSA key matching the certificate")
+
+	// ErrSMIMENoRecipients is returned when encryption is enabled without any recipient certificate.
+	ErrSMIMENoRecipients = errors.New("S/MIME encryption requires at least one recipient certificate")
+
+	// ErrSMIMEInvalidRecipient is returned when a recipient certificate is nil or does not hold a RSA
+	// public key.
+	ErrSMIMEInvalidRecipient = errors.New("S/MIME recipient certificate must hold a RSA public key")
+)
+
+var (
+	// smimeOIDData is the object identifier of the CMS data content type.
+	smimeOIDData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+
+	// smimeOIDSignedData is the object identifier of the CMS signed-data content type.
+	smimeOIDSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+	// smimeOIDEnvelopedData is the object identifier of the CMS enveloped-data content type.
+	smimeOIDEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+
+	// smimeOIDContentType is the object identifier of the content-type signed attribute.
+	smimeOIDContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+
+	// smimeOIDMessageDigest is the object identifier of the message-digest signed attribute.
+	smimeOIDMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	// smimeOIDSigningTime is the object identifier of the signing-time signed attribute.
+	smimeOIDSigningTime = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+
+	// smimeOIDSHA256 is the object identifier of the SHA-256 digest algorithm.
+	smimeOIDSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+	// smimeOIDRSAEncryption is the object identifier of the RSA signature and key transport algorithm.
+	smimeOIDRSAEncryption = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+	// smimeOIDECDSASHA256 is the object identifier of the ECDSA with SHA-256 signature algorithm.
+	smimeOIDECDSASHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+
+	// smimeOIDAES256CBC is the object identifier of the AES-256-CBC content encryption algorithm.
+	smimeOIDAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// smimeContentInfo represents the CMS ContentInfo structure.
+type smimeContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+// smimeSignedData represents the CMS SignedData structure of a detached signature.
+type smimeSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo smimeEncapContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      asn1.RawValue
+}
+
+// smimeEncapContentInfo represents the CMS EncapsulatedContentInfo structure without content.
+type smimeEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+}
+
+// smimeSignerInfo represents the CMS SignerInfo structure.
+type smimeSignerInfo struct {
+	Version            int
+	SID                smimeIssuerAndSerial
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+// smimeIssuerAndSerial represents the CMS IssuerAndSerialNumber structure.
+type smimeIssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+// smimeAttribute represents a CMS Attribute structure.
+type smimeAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// smimeEnvelopedData represents the CMS EnvelopedData structure.
+type smimeEnvelopedData struct {
+	Version              int
+	RecipientInfos       asn1.RawValue
+	EncryptedContentInfo smimeEncryptedContentInfo
+}
+
+// smimeKeyTransRecipientInfo represents the CMS KeyTransRecipientInfo structure.
+type smimeKeyTransRecipientInfo struct {
+	Version                int
+	RID                    smimeIssuerAndSerial
+	KeyEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedKey           []byte
+}
+
+// smimeEncryptedContentInfo represents the CMS EncryptedContentInfo structure.
+type smimeEncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue
+}
+
+// NewSMIMESigner returns a new SMIMESigner for the given certificate and private key.
+//
+// By default, the SMIMESigner only signs the Msg. Optional SMIMESignerOption functions can be used
+// to include intermediate certificates in the signature or to enable encryption.
+//
+// Parameters:
+//   - certificate: The signing certificate, which is included in the signature.
+//   - key: The RSA (*rsa.PrivateKey) or ECDSA (*ecdsa.PrivateKey) private key of the certificate.
+//   - opts: Optional SMIMESignerOption functions to customize the SMIMESigner.
+//
+// Returns:
+//   - A pointer to the SMIMESigner.
+//   - An error if any of the provided parameters or options is invalid.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551#section-2.5
+func NewSMIMESigner(certificate *x509.Certificate, key crypto.Signer, opts ...SMIMESignerOption,
+) (*SMIMESigner, error) {
+	if certificate == nil {
+		return nil, ErrSMIMENoCertificate
+	}
+	if key == nil {
+		return nil, ErrSMIMEInvalidKey
+	}
+	switch key.Public().(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, ErrSMIMEInvalidKey
+	}
+	publicKey, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !publicKey.Equal(certificate.PublicKey) {
+		return nil, ErrSMIMEInvalidKey
+	}
+
+	signer := &SMIMESigner{certificate: certificate, key: key}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(signer); err != nil {
+			return nil, fmt.Errorf("failed to apply S/MIME signer option: %w", err)
+		}
+	}
+	return signer, nil
+}
+
+// WithSMIMEIntermediates adds intermediate certificates to the signature of the SMIMESigner.
+//
+// The intermediate certificates are included in the signature, so that recipients are able to build
+// the certificate chain of the signing certificate.
+//
+// Parameters:
+//   - certificates: The intermediate certificates of the signing certificate.
+//
+// Returns:
+//   - A SMIMESignerOption function that adds the intermediate certificates to the SMIMESigner.
+func WithSMIMEIntermediates(certificates ...*x509.Certificate) SMIMESignerOption {
+	return func(s *SMIMESigner) error {
+		for _, certificate := range certificates {
+			if certificate == nil {
+				return ErrSMIMENoCertificate
+			}
+		}
+		s.intermediates = append(s.intermediates, certificates...)
+		return nil
+	}
+}
+
+// WithSMIMEEncryption enables the encryption of the signed Msg for the given recipient certificates.
+//
+// The signed entity is encrypted using AES-256-CBC with a random content encryption key, which is
+// encrypted for each of the recipients using RSA. To be able to decrypt the Msg later on, e.g. in
+// the sent folder, the certificate of the sender should be included in the recipient certificates.
+//
+// Parameters:
+//   - recipients: The certificates of the recipients. Each certificate must hold a RSA public key.
+//
+// Returns:
+//   - A SMIMESignerOption function that enables the encryption for the SMIMESigner.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551#section-3.3
+func WithSMIMEEncryption(recipients ...*x509.Certificate) SMIMESignerOption {
+	return func(s *SMIMESigner) error {
+		if len(recipients) == 0 {
+			return ErrSMIMENoRecipients
+		}
+		for _, recipient := range recipients {
+			if recipient == nil {
+				return ErrSMIMEInvalidRecipient
+			}
+			if _, ok := recipient.PublicKey.(*rsa.PublicKey); !ok {
+				return ErrSMIMEInvalidRecipient
+			}
+		}
+		s.recipients = recipients
+		return nil
+	}
+}
+
+// Handle satisfies the Middleware interface and returns a S/MIME protected copy of the Msg.
+//
+// The returned Msg is a clone of the given Msg, whose body is replaced by the signed and, if
+// configured, encrypted MIME entity. If the Msg cannot be signed or encrypted, the returned Msg
+// fails to render with the corresponding error, so that it is never sent unprotected.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - A pointer to the S/MIME protected copy of the Msg.
+func (s *SMIMESigner) Handle(msg *Msg) *Msg {
+	entity, err := s.Sign(msg)
+	clone := msg.Clone()
+	if err != nil {
+		return failingMsg(clone, err)
+	}
+	clone.preformBody = entity
+	return clone
+}
+
+// Type returns the MiddlewareType of the SMIMESigner.
+//
+// This method satisfies the Middleware interface.
+//
+// Returns:
+//   - MiddlewareTypeSMIME
+func (s *SMIMESigner) Type() MiddlewareType {
+	return MiddlewareTypeSMIME
+}
+
+// Sign renders the given Msg and returns its S/MIME protected MIME entity.
+//
+// The Msg is rendered without applying any Middleware. The Content-* header fields and the body of
+// the rendered Msg form the MIME entity that is signed. The returned entity includes its own
+// Content-Type header and replaces the body of the Msg.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to sign.
+//
+// Returns:
+//   - The signed and, if encryption is enabled, encrypted MIME entity.
+//   - An error if the Msg cannot be rendered, signed or encrypted.
+func (s *SMIMESigner) Sign(msg *Msg) ([]byte, error) {
+	content, err := renderMIMEEntity(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render message for S/MIME signing: %w", err)
+	}
+	entity, err := s.signEntity(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.recipients) == 0 {
+		return entity, nil
+	}
+	return s.encryptEntity(entity)
+}
+
+// renderMIMEEntity renders the given Msg without applying any Middleware and returns the MIME entity
+// formed by its Content-* header fields and its body, with all line breaks canonicalized to CRLF.
+//
+// Parameters:
+//   - msg: A pointer to the Msg to render.
+//
+// Returns:
+//   - The canonicalized MIME entity.
+//   - An error if the Msg cannot be rendered.
+func renderMIMEEntity(msg *Msg) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	mw := &msgWriter{
+		writer: &buffer, charset: msg.charset, encoder: msg.encoder,
+		foldColumn: msg.headerFoldColumn, maxLineLength: msg.maxLineLength,
+	}
+	mw.writeMsg(msg)
+	if mw.err != nil {
+		return nil, mw.err
+	}
+	rawHeader, rawBody := splitDKIMMessage(buffer.Bytes())
+	content := bytes.Buffer{}
+	for _, field := range parseDKIMHeaderFields(rawHeader) {
+		if strings.HasPrefix(strings.ToLower(field.name), "content-") {
+			content.WriteString(field.raw)
+		}
+	}
+	content.WriteString(SingleNewLine)
+	content.Write(rawBody)
+	return canonicalizeSMIMELineBreaks(content.Bytes()), nil
+}
+
+// signEntity wraps the given MIME entity into a multipart/signed entity with a detached signature.
+//
+// Parameters:
+//   - entity: The canonicalized MIME entity to sign.
+//
+// Returns:
+//   - The multipart/signed MIME entity.
+//   - An error if the signature cannot be created.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551#section-3.5.3
+func (s *SMIMESigner) signEntity(entity []byte) ([]byte, error) {
+	signature, err := s.signature(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S/MIME signature: %w", err)
+	}
+	boundary, err := randomStringSecure(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate boundary: %w", err)
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString(`Content-Type: multipart/signed; protocol="application/pkcs7-signature";` +
+		SingleNewLine + ` micalg=sha-256; boundary="` + boundary + `"` + DoubleNewLine)
+	buffer.WriteString("This is a S/MIME signed message" + DoubleNewLine)
+	buffer.WriteString("--" + boundary + SingleNewLine)
+	buffer.Write(entity)
+	buffer.WriteString(SingleNewLine + "--" + boundary + SingleNewLine)
+	buffer.WriteString(`Content-Type: application/pkcs7-signature; name="smime.p7s"` + SingleNewLine +
+		`Content-Transfer-Encoding: base64` + SingleNewLine +
+		`Content-Disposition: attachment; filename="smime.p7s"` + DoubleNewLine)
+	if err = writeSMIMEBase64(&buffer, signature); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("--" + boundary + "--" + SingleNewLine)
+	return buffer.Bytes(), nil
+}
+
+// encryptEntity encrypts the given MIME entity into an application/pkcs7-mime enveloped-data entity.
+//
+// Parameters:
+//   - entity: The MIME entity to encrypt.
+//
+// Returns:
+//   - The application/pkcs7-mime MIME entity.
+//   - An error if the entity cannot be encrypted.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551#section-3.3
+func (s *SMIMESigner) encryptEntity(entity []byte) ([]byte, error) {
+	envelope, err := s.envelope(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt S/MIME message: %w", err)
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteString(`Content-Type: application/pkcs7-mime; smime-type=enveloped-data;` + SingleNewLine +
+		` name="smime.p7m"` + SingleNewLine +
+		`Content-Transfer-Encoding: base64` + SingleNewLine +
+		`Content-Disposition: attachment; filename="smime.p7m"` + DoubleNewLine)
+	if err = writeSMIMEBase64(&buffer, envelope); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// signature creates the DER encoded CMS SignedData structure of a detached signature over the
+// given content.
+//
+// Parameters:
+//   - content: The content to sign.
+//
+// Returns:
+//   - The DER encoded CMS ContentInfo holding the SignedData.
+//   - An error if the content cannot be signed.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5652#section-5
+func (s *SMIMESigner) signature(content []byte) ([]byte, error) {
+	digest := sha256.Sum256(content)
+	contentType, err := asn1.Marshal(smimeOIDData)
+	if err != nil {
+		return nil, err
+	}
+	signingTime, err := asn1.Marshal(time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	messageDigest, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return nil, err
+	}
+	var attributes [][]byte
+	for _, attribute := range []smimeAttribute{
+		{Type: smimeOIDContentType, Values: smimeSet(contentType)},
+		{Type: smimeOIDSigningTime, Values: smimeSet(signingTime)},
+		{Type: smimeOIDMessageDigest, Values: smimeSet(messageDigest)},
+	} {
+		encoded, err := asn1.Marshal(attribute)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, encoded)
+	}
+
+	// The signature is calculated over the DER encoding of the signed attributes as SET OF, while
+	// they are stored with an implicit [0] tag in the SignerInfo
+	signedAttrs := smimeSet(attributes...)
+	encodedAttrs, err := asn1.Marshal(signedAttrs)
+	if err != nil {
+		return nil, err
+	}
+	attrsDigest := sha256.Sum256(encodedAttrs)
+	sig, err := s.key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	signedAttrs.Class = asn1.ClassContextSpecific
+	signedAttrs.Tag = 0
+
+	signatureAlgorithm := pkix.AlgorithmIdentifier{Algorithm: smimeOIDRSAEncryption, Parameters: asn1.NullRawValue}
+	if _, ok := s.key.Public().(*ecdsa.PublicKey); ok {
+		signatureAlgorithm = pkix.AlgorithmIdentifier{Algorithm: smimeOIDECDSASHA256}
+	}
+	digestAlgorithm := pkix.AlgorithmIdentifier{Algorithm: smimeOIDSHA256}
+	signerInfo, err := asn1.Marshal(smimeSignerInfo{
+		Version:            1,
+		SID:                smimeIssuerAndSerialNumber(s.certificate),
+		DigestAlgorithm:    digestAlgorithm,
+		SignedAttrs:        signedAttrs,
+		SignatureAlgorithm: signatureAlgorithm,
+		Signature:          sig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encodedDigestAlgorithm, err := asn1.Marshal(digestAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []byte
+	for _, certificate := range append([]*x509.Certificate{s.certificate}, s.intermediates...) {
+		certificates = append(certificates, certificate.Raw...)
+	}
+	signedData, err := asn1.Marshal(smimeSignedData{
+		Version:          1,
+		DigestAlgorithms: smimeSet(encodedDigestAlgorithm),
+		EncapContentInfo: smimeEncapContentInfo{EContentType: smimeOIDData},
+		Certificates: asn1.RawValue{
+			Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates,
+		},
+		SignerInfos: smimeSet(signerInfo),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marshalSMIMEContentInfo(smimeOIDSignedData, signedData)
+}
+
+// envelope creates the DER encoded CMS EnvelopedData structure of the given content for the
+// recipients of the SMIMESigner.
+//
+// Parameters:
+//   - content: The content to encrypt.
+//
+// Returns:
+//   - The DER encoded CMS ContentInfo holding the EnvelopedData.
+//   - An error if the content cannot be encrypted.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5652#section-6
+//   - https://datatracker.ietf.org/doc/html/rfc3565
+func (s *SMIMESigner) envelope(content []byte) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(content)%aes.BlockSize
+	encrypted := append(append([]byte(nil), content...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	var recipientInfos [][]byte
+	for _, recipient := range s.recipients {
+		publicKey, ok := recipient.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, ErrSMIMEInvalidRecipient
+		}
+		encryptedKey, err := rsa.EncryptPKCS1v15(rand.Reader, publicKey, key)
+		if err != nil {
+			return nil, err
+		}
+		recipientInfo, err := asn1.Marshal(smimeKeyTransRecipientInfo{
+			Version: 0,
+			RID:     smimeIssuerAndSerialNumber(recipient),
+			KeyEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm: smimeOIDRSAEncryption, Parameters: asn1.NullRawValue,
+			},
+			EncryptedKey: encryptedKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		recipientInfos = append(recipientInfos, recipientInfo)
+	}
+	encodedIV, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	envelopedData, err := asn1.Marshal(smimeEnvelopedData{
+		Version:        0,
+		RecipientInfos: smimeSet(recipientInfos...),
+		EncryptedContentInfo: smimeEncryptedContentInfo{
+			ContentType: smimeOIDData,
+			ContentEncryptionAlgorithm: pkix.AlgorithmIdentifier{
+				Algorithm: smimeOIDAES256CBC, Parameters: asn1.RawValue{FullBytes: encodedIV},
+			},
+			EncryptedContent: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: encrypted},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return marshalSMIMEContentInfo(smimeOIDEnvelopedData, envelopedData)
+}
+
+// smimeIssuerAndSerialNumber returns the CMS IssuerAndSerialNumber of the given certificate.
+func smimeIssuerAndSerialNumber(certificate *x509.Certificate) smimeIssuerAndSerial {
+	return smimeIssuerAndSerial{
+		Issuer:       asn1.RawValue{FullBytes: certificate.RawIssuer},
+		SerialNumber: certificate.SerialNumber,
+	}
+}
+
+// smimeSet returns the given DER encoded elements as ASN.1 SET OF, sorted as required by DER.
+func smimeSet(elements ...[]byte) asn1.RawValue {
+	sorted := make([][]byte, len(elements))
+	copy(sorted, elements)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	return asn1.RawValue{
+		Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: bytes.Join(sorted, nil),
+	}
+}
+
+// marshalSMIMEContentInfo returns the DER encoded CMS ContentInfo for the given content type and
+// DER encoded content.
+func marshalSMIMEContentInfo(contentType asn1.ObjectIdentifier, content []byte) ([]byte, error) {
+	return asn1.Marshal(smimeContentInfo{
+		ContentType: contentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: content},
+	})
+}
+
+// canonicalizeSMIMELineBreaks converts all line breaks of the given data to CRLF, as required for
+// the canonical form of a signed MIME entity.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc8551#section-3.1.1
+func canonicalizeSMIMELineBreaks(data []byte) []byte {
+	data = bytes.ReplaceAll(data, []byte(SingleNewLine), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\n"), []byte(SingleNewLine))
+}
+
+// writeSMIMEBase64 writes the given data base64 encoded with line breaks to the given buffer. The
+// encoded data is always terminated by a CRLF.
+func writeSMIMEBase64(buffer *bytes.Buffer, data []byte) error {
+	lineBreaker := Base64LineBreaker{out: buffer}
+	encoder := base64.NewEncoder(base64.StdEncoding, &lineBreaker)
+	if _, err := encoder.Write(data); err != nil {
+		return fmt.Errorf("failed to base64 encode S/MIME structure: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to base64 encode S/MIME structure: %w", err)
+	}
+	return lineBreaker.Close()
+}
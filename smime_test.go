@@ -0,0 +1,336 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/big"
+	"mime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNewSMIMESigner tests the validation of NewSMIMESigner and its options
+func TestNewSMIMESigner(t *testing.T) {
+	rsaCert, rsaKey := newSMIMETestCertificate(t, "rsa")
+	ecCert, _ := newSMIMETestCertificate(t, "ecdsa")
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %s", err)
+	}
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		key  crypto.Signer
+		opts []SMIMESignerOption
+		want error
+	}{
+		{"valid", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEIntermediates(ecCert), nil}, nil},
+		{"valid with encryption", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEEncryption(rsaCert)}, nil},
+		{"no certificate", nil, rsaKey, nil, ErrSMIMENoCertificate},
+		{"no key", rsaCert, nil, nil, ErrSMIMEInvalidKey},
+		{"unsupported key", rsaCert, edKey, nil, ErrSMIMEInvalidKey},
+		{"key not matching certificate", ecCert, rsaKey, nil, ErrSMIMEInvalidKey},
+		{"nil intermediate", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEIntermediates(nil)}, ErrSMIMENoCertificate},
+		{"no recipients", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEEncryption()}, ErrSMIMENoRecipients},
+		{"nil recipient", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEEncryption(nil)}, ErrSMIMEInvalidRecipient},
+		{"non-RSA recipient", rsaCert, rsaKey, []SMIMESignerOption{WithSMIMEEncryption(ecCert)}, ErrSMIMEInvalidRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSMIMESigner(tt.cert, tt.key, tt.opts...)
+			if tt.want == nil && err != nil {
+				t.Errorf("NewSMIMESigner failed: %s", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("NewSMIMESigner expected error: %s, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestSMIMESigner_Handle tests that a Msg signed by the SMIMESigner middleware can be verified
+func TestSMIMESigner_Handle(t *testing.T) {
+	for _, keyType := range []string{"rsa", "ecdsa"} {
+		t.Run(keyType, func(t *testing.T) {
+			cert, key := newSMIMETestCertificate(t, keyType)
+			signer, err := NewSMIMESigner(cert, key)
+			if err != nil {
+				t.Fatalf("failed to create S/MIME signer: %s", err)
+			}
+			m := newSMIMETestMsg(t, signer)
+			buffer := bytes.Buffer{}
+			if _, err = m.WriteTo(&buffer); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			if m.preformBody != nil {
+				t.Errorf("S/MIME signing modified the original Msg")
+			}
+			header, body := splitDKIMMessage(buffer.Bytes())
+			verifySMIMESignature(t, string(header), body, cert)
+		})
+	}
+}
+
+// TestSMIMESigner_Handle_encryption tests that a Msg encrypted by the SMIMESigner middleware can be
+// decrypted and its signature verified
+func TestSMIMESigner_Handle_encryption(t *testing.T) {
+	cert, key := newSMIMETestCertificate(t, "ecdsa")
+	recipientCert, recipientKey := newSMIMETestCertificate(t, "rsa")
+	signer, err := NewSMIMESigner(cert, key, WithSMIMEEncryption(recipientCert))
+	if err != nil {
+		t.Fatalf("failed to create S/MIME signer: %s", err)
+	}
+	m := newSMIMETestMsg(t, signer)
+	buffer := bytes.Buffer{}
+	if _, err = m.WriteTo(&buffer); err != nil {
+		t.Fatalf("failed to write message: %s", err)
+	}
+	if !strings.Contains(buffer.String(), "Content-Type: application/pkcs7-mime; smime-type=enveloped-data;") {
+		t.Fatalf("encrypted message has no application/pkcs7-mime content type: %s", buffer.String())
+	}
+	if strings.Contains(buffer.String(), "This is the plain text body") {
+		t.Errorf("encrypted message contains the plain text body")
+	}
+	_, body := splitDKIMMessage(buffer.Bytes())
+	envelope, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(body), SingleNewLine, ""))
+	if err != nil {
+		t.Fatalf("failed to decode envelope: %s", err)
+	}
+	decrypted := decryptSMIMEEnvelope(t, envelope, recipientKey.(*rsa.PrivateKey))
+	header, signedBody := splitDKIMMessage(decrypted)
+	verifySMIMESignature(t, string(header), signedBody, cert)
+}
+
+// TestSMIMESigner_Handle_failure tests that a Msg that cannot be signed fails to render
+func TestSMIMESigner_Handle_failure(t *testing.T) {
+	cert, key := newSMIMETestCertificate(t, "ecdsa")
+	signer, err := NewSMIMESigner(cert, key)
+	if err != nil {
+		t.Fatalf("failed to create S/MIME signer: %s", err)
+	}
+	m := NewMsg(WithMiddleware(signer))
+	m.SetBodyWriter(TypeTextPlain, func(io.Writer) (int64, error) {
+		return 0, errors.New("body write failed")
+	})
+	buffer := bytes.Buffer{}
+	if _, err = m.WriteTo(&buffer); err == nil {
+		t.Errorf("writing a Msg that cannot be signed was supposed to fail, but didn't")
+	}
+	if signer.Type() != MiddlewareTypeSMIME {
+		t.Errorf("SMIMESigner.Type failed. Expected: %s, got: %s", MiddlewareTypeSMIME, signer.Type())
+	}
+}
+
+// newSMIMETestCertificate returns a new self-signed certificate and its private key
+func newSMIMETestCertificate(t *testing.T, keyType string) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "rsa":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatalf("failed to generate %s key: %s", keyType, err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(time.Now().UnixNano()),
+		Subject:        pkix.Name{CommonName: "toni@tester.com"},
+		EmailAddresses: []string{"toni@tester.com"},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	return cert, key
+}
+
+// newSMIMETestMsg returns a new multipart Msg with the given signing middleware
+func newSMIMETestMsg(t *testing.T, signer Middleware) *Msg {
+	t.Helper()
+	m := NewMsg(WithMiddleware(signer))
+	if err := m.From("toni@tester.com"); err != nil {
+		t.Fatalf("failed to set FROM address: %s", err)
+	}
+	if err := m.To("recipient@tester.com"); err != nil {
+		t.Fatalf("failed to set TO address: %s", err)
+	}
+	m.Subject("This is a S/MIME signed message")
+	m.SetBodyString(TypeTextPlain, "This is the plain text body\nwith LF line breaks and trailing whitespace  \n",
+		WithPartEncoding(NoEncoding))
+	m.AddAlternativeString(TypeTextHTML, "<p>This is the HTML body</p>")
+	if err := m.AttachReader("attachment.txt", strings.NewReader("attachment content")); err != nil {
+		t.Fatalf("failed to attach reader: %s", err)
+	}
+	return m
+}
+
+// verifySMIMESignature verifies the multipart/signed entity with the given header and body
+func verifySMIMESignature(t *testing.T, header string, body []byte, cert *x509.Certificate) {
+	t.Helper()
+	var contentType string
+	for _, field := range parseDKIMHeaderFields([]byte(header)) {
+		if strings.EqualFold(field.name, HeaderContentType.String()) {
+			contentType = strings.TrimSpace(strings.SplitN(field.raw, ":", 2)[1])
+		}
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("failed to parse content type %q: %s", contentType, err)
+	}
+	if mediaType != "multipart/signed" || params["protocol"] != "application/pkcs7-signature" ||
+		params["micalg"] != "sha-256" {
+		t.Fatalf("unexpected content type of signed message: %s", contentType)
+	}
+	delimiter := "--" + params["boundary"] + SingleNewLine
+	start := bytes.Index(body, []byte(delimiter))
+	if start < 0 {
+		t.Fatalf("signed message has no valid multipart structure: %s", body)
+	}
+	start += len(delimiter)
+	end := bytes.Index(body[start:], []byte(SingleNewLine+delimiter))
+	if end < 0 {
+		t.Fatalf("signed message has no valid multipart structure: %s", body)
+	}
+	end += start
+	entity := body[start:end]
+	if bytes.Contains(bytes.ReplaceAll(entity, []byte(SingleNewLine), nil), []byte("\n")) {
+		t.Errorf("signed entity contains bare LF line breaks")
+	}
+	if !bytes.Contains(entity, []byte("This is the plain text body")) {
+		t.Errorf("signed entity does not contain the plain text body: %s", entity)
+	}
+
+	signaturePart := body[end+len(SingleNewLine+delimiter):]
+	_, encodedSignature := splitDKIMMessage(signaturePart)
+	encodedSignature = encodedSignature[:bytes.Index(encodedSignature, []byte("--"+params["boundary"]+"--"))]
+	signature, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encodedSignature), SingleNewLine, ""))
+	if err != nil {
+		t.Fatalf("failed to decode signature: %s", err)
+	}
+	var contentInfo smimeContentInfo
+	if _, err = asn1.Unmarshal(signature, &contentInfo); err != nil {
+		t.Fatalf("failed to parse content info: %s", err)
+	}
+	if !contentInfo.ContentType.Equal(smimeOIDSignedData) {
+		t.Fatalf("unexpected content type of signature: %s", contentInfo.ContentType)
+	}
+	var signedData smimeSignedData
+	if _, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		t.Fatalf("failed to parse signed data: %s", err)
+	}
+	if !bytes.HasPrefix(signedData.Certificates.Bytes, cert.Raw) {
+		t.Errorf("signed data does not include the signing certificate")
+	}
+	var signerInfo smimeSignerInfo
+	if _, err = asn1.Unmarshal(signedData.SignerInfos.Bytes, &signerInfo); err != nil {
+		t.Fatalf("failed to parse signer info: %s", err)
+	}
+	if signerInfo.SID.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		t.Errorf("signer info does not reference the signing certificate")
+	}
+
+	digest := sha256.Sum256(entity)
+	var messageDigest []byte
+	rest := signerInfo.SignedAttrs.Bytes
+	for len(rest) > 0 {
+		var attribute smimeAttribute
+		if rest, err = asn1.Unmarshal(rest, &attribute); err != nil {
+			t.Fatalf("failed to parse signed attribute: %s", err)
+		}
+		if attribute.Type.Equal(smimeOIDMessageDigest) {
+			if _, err = asn1.Unmarshal(attribute.Values.Bytes, &messageDigest); err != nil {
+				t.Fatalf("failed to parse message digest: %s", err)
+			}
+		}
+	}
+	if !bytes.Equal(messageDigest, digest[:]) {
+		t.Errorf("message digest mismatch. Expected: %x, got: %x", digest, messageDigest)
+	}
+
+	signedAttrs := signerInfo.SignedAttrs
+	signedAttrs.FullBytes = nil
+	signedAttrs.Class = asn1.ClassUniversal
+	signedAttrs.Tag = asn1.TagSet
+	encodedAttrs, err := asn1.Marshal(signedAttrs)
+	if err != nil {
+		t.Fatalf("failed to encode signed attributes: %s", err)
+	}
+	attrsDigest := sha256.Sum256(encodedAttrs)
+	switch publicKey := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, attrsDigest[:], signerInfo.Signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(publicKey, attrsDigest[:], signerInfo.Signature) {
+			err = errors.New("ecdsa signature verification failed")
+		}
+	}
+	if err != nil {
+		t.Errorf("S/MIME signature verification failed: %s", err)
+	}
+}
+
+// decryptSMIMEEnvelope decrypts the given DER encoded CMS EnvelopedData with the given key
+func decryptSMIMEEnvelope(t *testing.T, envelope []byte, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	var contentInfo smimeContentInfo
+	if _, err := asn1.Unmarshal(envelope, &contentInfo); err != nil {
+		t.Fatalf("failed to parse content info: %s", err)
+	}
+	if !contentInfo.ContentType.Equal(smimeOIDEnvelopedData) {
+		t.Fatalf("unexpected content type of envelope: %s", contentInfo.ContentType)
+	}
+	var envelopedData smimeEnvelopedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &envelopedData); err != nil {
+		t.Fatalf("failed to parse enveloped data: %s", err)
+	}
+	var recipientInfo smimeKeyTransRecipientInfo
+	if _, err := asn1.Unmarshal(envelopedData.RecipientInfos.Bytes, &recipientInfo); err != nil {
+		t.Fatalf("failed to parse recipient info: %s", err)
+	}
+	contentKey, err := rsa.DecryptPKCS1v15(rand.Reader, key, recipientInfo.EncryptedKey)
+	if err != nil {
+		t.Fatalf("failed to decrypt content encryption key: %s", err)
+	}
+	var iv []byte
+	if _, err = asn1.Unmarshal(envelopedData.EncryptedContentInfo.ContentEncryptionAlgorithm.Parameters.FullBytes,
+		&iv); err != nil {
+		t.Fatalf("failed to parse IV: %s", err)
+	}
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		t.Fatalf("failed to create cipher: %s", err)
+	}
+	content := append([]byte(nil), envelopedData.EncryptedContentInfo.EncryptedContent.Bytes...)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(content, content)
+	return content[:len(content)-int(content[len(content)-1])]
+}
@@ -13,7 +13,12 @@
 
 package smtp
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/secure/precis"
+)
 
 var (
 	// ErrUnencrypted is an error indicating that the connection is not encrypted.
@@ -52,6 +57,27 @@ type ServerInfo struct {
 	Auth []string // advertised authentication mechanisms
 }
 
+// SASLprep prepares the given credential string for the use in a SASL authentication mechanism.
+//
+// The string is normalized according to the OpaqueString profile of the PRECIS framework as
+// defined in RFC 8265, which obsoletes the SASLprep profile of RFC 4013. Non-ASCII spaces are
+// mapped to the ASCII space and the string is normalized to Unicode NFC. An error is returned
+// if the string contains disallowed characters or the result is empty.
+//
+// This is useful for credentials containing non-ASCII characters that might be represented in
+// different ways, e.g. precomposed or decomposed accented characters, since the PLAIN and LOGIN
+// mechanisms transmit the raw UTF-8 bytes of the credentials.
+func SASLprep(value string) (string, error) {
+	prepared, err := precis.OpaqueString.String(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare string with SASLprep: %w", err)
+	}
+	if prepared == "" {
+		return "", errors.New("failed to prepare string with SASLprep: result is empty")
+	}
+	return prepared, nil
+}
+
 func isLocalhost(name string) bool {
 	return name == "localhost" || name == "127.0.0.1" || name == "::1"
 }
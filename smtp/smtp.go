@@ -18,6 +18,9 @@
 //	AUTH      RFC 2554
 //	STARTTLS  RFC 3207
 //	DSN       RFC 1891
+//	PIPELINING RFC 2920
+//	CHUNKING  RFC 3030
+//	SIZE      RFC 1870
 package smtp
 
 import (
@@ -38,14 +41,25 @@ import (
 
 var (
 
+	// ErrInvalidParam is returned when a parameter for the MAIL or RCPT command is empty or contains
+	// spaces or control characters.
+	ErrInvalidParam = errors.New("invalid MAIL or RCPT parameter")
+
 	// ErrNonTLSConnection is returned when an attempt is made to retrieve TLS state on a non-TLS connection.
 	ErrNonTLSConnection = errors.New("connection is not using TLS")
 
 	// ErrNoConnection is returned when attempting to perform an operation that requires an established
 	// connection but none exists.
 	ErrNoConnection = errors.New("connection is not established")
+
+	// ErrNoChunking is returned when the BDAT command is used with a server that does not advertise the
+	// CHUNKING extension.
+	ErrNoChunking = errors.New("server does not support CHUNKING")
 )
 
+// bdatChunkSize is the maximum size of a single chunk that is sent with the BDAT command.
+const bdatChunkSize = 64 * 1024
+
 // A Client represents a client connection to an SMTP server.
 type Client struct {
 	// Text is the textproto.Conn used by the Client. It is exported to allow for clients to add extensions.
@@ -54,6 +68,10 @@ type Client struct {
 	// auth supported auth mechanisms
 	auth []string
 
+	// commandTimeout is the timeout that is set as deadline on the connection before each command, if greater
+	// than zero
+	commandTimeout time.Duration
+
 	// keep a reference to the connection so it can be used to create a TLS connection later
 	conn net.Conn
 
@@ -75,6 +93,10 @@ type Client struct {
 	// helloError is the error from the hello
 	helloError error
 
+	// isAuth indicates that an AUTH exchange is in progress, so that the credentials sent by the Client
+	// are redacted from the debug log
+	isAuth bool
+
 	// isConnected indicates if the Client has an active connection
 	isConnected bool
 
@@ -84,10 +106,19 @@ type Client struct {
 	// logger will be used for debug logging
 	logger log.Logger
 
+	// mailParams holds additional parameters that are appended to the MAIL command as they are
+	mailParams []string
+
+	// mailSize is the size of the message that is declared with the SIZE parameter of the MAIL command
+	mailSize int64
+
 	// mutex is used to synchronize access to shared resources, ensuring that only one goroutine can access
 	// the resource at a time.
 	mutex sync.RWMutex
 
+	// rcptParams holds additional parameters that are appended to each RCPT command as they are
+	rcptParams []string
+
 	// tls indicates whether the Client is using TLS
 	tls bool
 
@@ -174,6 +205,10 @@ func (c *Client) Hello(localName string) error {
 func (c *Client) cmd(expectCode int, format string, args ...interface{}) (int, string, error) {
 	c.mutex.Lock()
 
+	if err := c.setCommandDeadline(); err != nil {
+		c.mutex.Unlock()
+		return 0, "", err
+	}
 	c.debugLog(log.DirClientToServer, format, args...)
 	id, err := c.Text.Cmd(format, args...)
 	if err != nil {
@@ -268,6 +303,14 @@ func (c *Client) Auth(a Auth) error {
 		}
 		return err
 	}
+	c.mutex.Lock()
+	c.isAuth = true
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		c.isAuth = false
+		c.mutex.Unlock()
+	}()
 	resp64 := make([]byte, encoding.EncodedLen(len(resp)))
 	encoding.Encode(resp64, resp)
 	code, msg64, err := c.cmd(0, "%s", strings.TrimSpace(fmt.Sprintf("AUTH %s %s", mech,
@@ -291,6 +334,9 @@ func (c *Client) Auth(a Auth) error {
 				// abort the AUTH. Not required for XOAUTH2
 				_, _, _ = c.cmd(501, "*")
 			}
+			c.mutex.Lock()
+			c.isAuth = false
+			c.mutex.Unlock()
 			_ = c.Quit()
 			break
 		}
@@ -307,7 +353,9 @@ func (c *Client) Auth(a Auth) error {
 // Mail issues a MAIL command to the server using the provided email address.
 // If the server supports the 8BITMIME extension, Mail adds the BODY=8BITMIME
 // parameter. If the server supports the SMTPUTF8 extension, Mail adds the
-// SMTPUTF8 parameter.
+// SMTPUTF8 parameter. If the server supports the SIZE extension and a size
+// has been set via [Client.SetMailSize], Mail adds the SIZE parameter. Parameters
+// set via [Client.SetMailParams] are appended to the command as they are.
 // This initiates a mail transaction and is followed by one or more [Client.Rcpt] calls.
 func (c *Client) Mail(from string) error {
 	if err := validateLine(from); err != nil {
@@ -316,7 +364,14 @@ func (c *Client) Mail(from string) error {
 	if err := c.hello(); err != nil {
 		return err
 	}
-	cmdStr := "MAIL FROM:<%s>"
+	_, _, err := c.cmd(250, "%s", c.mailCommand(from))
+	return err
+}
+
+// mailCommand returns the MAIL command for the given sender address including the parameters
+// for the extensions supported by the server.
+func (c *Client) mailCommand(from string) string {
+	cmdStr := "MAIL FROM:<" + from + ">"
 
 	c.mutex.RLock()
 	if c.ext != nil {
@@ -326,15 +381,20 @@ func (c *Client) Mail(from string) error {
 		if _, ok := c.ext["SMTPUTF8"]; ok {
 			cmdStr += " SMTPUTF8"
 		}
+		if _, ok := c.ext["SIZE"]; ok && c.mailSize > 0 {
+			cmdStr += fmt.Sprintf(" SIZE=%d", c.mailSize)
+		}
 		_, ok := c.ext["DSN"]
 		if ok && c.dsnmrtype != "" {
 			cmdStr += fmt.Sprintf(" RET=%s", c.dsnmrtype)
 		}
 	}
+	for _, param := range c.mailParams {
+		cmdStr += " " + param
+	}
 	c.mutex.RUnlock()
 
-	_, _, err := c.cmd(250, cmdStr, from)
-	return err
+	return cmdStr
 }
 
 // Rcpt issues a RCPT command to the server using the provided email address.
@@ -344,17 +404,150 @@ func (c *Client) Rcpt(to string) error {
 	if err := validateLine(to); err != nil {
 		return err
 	}
+	_, _, err := c.cmd(25, "%s", c.rcptCommand(to))
+	return err
+}
+
+// rcptCommand returns the RCPT command for the given recipient address including the DSN
+// notify parameter, if supported by the server, and the parameters set via [Client.SetRcptParams].
+func (c *Client) rcptCommand(to string) string {
+	cmdStr := "RCPT TO:<" + to + ">"
 
 	c.mutex.RLock()
-	_, ok := c.ext["DSN"]
+	if _, ok := c.ext["DSN"]; ok && c.dsnrntype != "" {
+		cmdStr += " NOTIFY=" + c.dsnrntype
+	}
+	for _, param := range c.rcptParams {
+		cmdStr += " " + param
+	}
 	c.mutex.RUnlock()
 
-	if ok && c.dsnrntype != "" {
-		_, _, err := c.cmd(25, "RCPT TO:<%s> NOTIFY=%s", to, c.dsnrntype)
-		return err
+	return cmdStr
+}
+
+// MailRcptPipelined issues the MAIL command for the sender and the RCPT commands for all recipients
+// to the server in a single batch and reads the responses afterwards, as specified by the PIPELINING
+// extension (RFC 2920). This saves a round trip for each command on high-latency connections.
+//
+// The returned slice holds the error of the RCPT command for each recipient in the order of the
+// given recipients, or nil if the recipient was accepted. The returned error is non-nil if the MAIL
+// command failed or the communication with the server failed. If the server does not advertise
+// the PIPELINING extension, the commands are issued one after another instead.
+//
+// Like [Client.Mail], a successful call may be followed by a [Client.Data] call.
+func (c *Client) MailRcptPipelined(from string, to []string) ([]error, error) {
+	rcptErrs, _, _, err := c.pipelineTransaction(from, to, false)
+	return rcptErrs, err
+}
+
+// MailRcptDataPipelined issues the MAIL command for the sender, the RCPT commands for all recipients
+// and the DATA command to the server in a single batch and reads the responses afterwards, as
+// specified by the PIPELINING extension (RFC 2920). Compared to [Client.MailRcptPipelined], this
+// saves the round trip of the DATA command as well.
+//
+// The returned slice holds the error of the RCPT command for each recipient in the order of the
+// given recipients, or nil if the recipient was accepted. If the server accepted the DATA command,
+// the returned writer can be used to write the mail headers and body, like the writer returned by
+// [Client.Data]. Otherwise, the third return value holds the error of the DATA command. The last
+// return value is non-nil if the MAIL command failed or the communication with the server failed.
+// If the server does not advertise the PIPELINING extension, the commands are issued one after
+// another instead.
+//
+// Since the server is in the data phase once it accepted the DATA command, the transaction cannot
+// be reset anymore. If the message must not be sent, e.g. because some recipients were rejected,
+// the caller has to close the connection instead of closing the writer.
+func (c *Client) MailRcptDataPipelined(from string, to []string) ([]error, io.WriteCloser, error, error) {
+	return c.pipelineTransaction(from, to, true)
+}
+
+// pipelineTransaction issues the MAIL command, the RCPT commands and, if data is true, the DATA
+// command in a single batch and reads the responses afterwards. It returns the errors of the RCPT
+// commands, the writer and the error of the DATA command, and the error of the MAIL command or of
+// the communication with the server.
+func (c *Client) pipelineTransaction(from string, to []string, data bool) ([]error, io.WriteCloser, error,
+	error,
+) {
+	if err := validateLine(from); err != nil {
+		return nil, nil, nil, err
 	}
-	_, _, err := c.cmd(25, "RCPT TO:<%s>", to)
-	return err
+	for _, rcpt := range to {
+		if err := validateLine(rcpt); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if err := c.hello(); err != nil {
+		return nil, nil, nil, err
+	}
+	rcptErrs := make([]error, len(to))
+	if ok, _ := c.Extension("PIPELINING"); !ok {
+		if err := c.Mail(from); err != nil {
+			return nil, nil, nil, err
+		}
+		for i, rcpt := range to {
+			rcptErrs[i] = c.Rcpt(rcpt)
+		}
+		if !data {
+			return rcptErrs, nil, nil, nil
+		}
+		writer, err := c.Data()
+		return rcptErrs, writer, err, nil
+	}
+
+	commands := make([]string, 0, len(to)+2)
+	commands = append(commands, c.mailCommand(from))
+	for _, rcpt := range to {
+		commands = append(commands, c.rcptCommand(rcpt))
+	}
+	if data {
+		commands = append(commands, "DATA")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.setCommandDeadline(); err != nil {
+		return nil, nil, nil, err
+	}
+	for _, command := range commands {
+		c.debugLog(log.DirClientToServer, "%s", command)
+		if _, err := c.Text.W.WriteString(command + "\r\n"); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if err := c.Text.W.Flush(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// All responses have to be read, even if the MAIL command was rejected, so that the
+	// responses stay associated with their commands
+	var mailErr, dataErr error
+	for i := range commands {
+		expectCode := 25
+		switch {
+		case i == 0:
+			expectCode = 250
+		case data && i == len(commands)-1:
+			expectCode = 354
+		}
+		code, msg, err := c.Text.ReadResponse(expectCode)
+		c.debugLog(log.DirServerToClient, "%d %s", code, msg)
+		var protoErr *textproto.Error
+		if err != nil && !errors.As(err, &protoErr) {
+			return nil, nil, nil, err
+		}
+		switch {
+		case i == 0:
+			mailErr = err
+		case data && i == len(commands)-1:
+			dataErr = err
+		default:
+			rcptErrs[i-1] = err
+		}
+	}
+	var writer io.WriteCloser
+	if data && dataErr == nil {
+		writer = &dataCloser{c: c, WriteCloser: c.Text.DotWriter()}
+	}
+	return rcptErrs, writer, dataErr, mailErr
 }
 
 type dataCloser struct {
@@ -398,6 +591,95 @@ func (c *Client) Data() (io.WriteCloser, error) {
 	return datacloser, nil
 }
 
+// bdatWriter is the io.WriteCloser that transfers the message content in chunks of the BDAT command.
+type bdatWriter struct {
+	// buffer holds the content of the chunk that has not been sent yet.
+	buffer []byte
+
+	// c is the Client the chunks are sent with.
+	c *Client
+
+	// err is the error of the first failed chunk. Once a chunk failed, no further chunks are sent.
+	err error
+}
+
+// Bdat issues the BDAT commands to the server, as specified by the CHUNKING extension (RFC 3030),
+// and returns a writer that can be used to write the mail headers and body. The content is sent
+// in chunks, each prefixed with its size, so no dot-stuffing is applied and the content is sent
+// unchanged. Unlike with [Client.Data], bare LF line endings are not converted to CRLF, hence the
+// caller must provide the message with CRLF line endings. The final chunk is sent with the LAST
+// keyword when the writer is closed. The caller should close the writer before calling any more methods on c.
+// A call to Bdat must be preceded by one or more calls to [Client.Rcpt].
+func (c *Client) Bdat() (io.WriteCloser, error) {
+	if err := c.hello(); err != nil {
+		return nil, err
+	}
+	if ok, _ := c.Extension("CHUNKING"); !ok {
+		return nil, ErrNoChunking
+	}
+	return &bdatWriter{buffer: make([]byte, 0, bdatChunkSize), c: c}, nil
+}
+
+// Write buffers the given data and sends a BDAT chunk each time the buffer is full.
+func (w *bdatWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	written := 0
+	for written < len(p) {
+		size := bdatChunkSize - len(w.buffer)
+		if size > len(p)-written {
+			size = len(p) - written
+		}
+		w.buffer = append(w.buffer, p[written:written+size]...)
+		written += size
+		if len(w.buffer) < bdatChunkSize {
+			continue
+		}
+		if err := w.sendChunk(false); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Close sends the remaining buffered data as the final BDAT chunk and returns the response of the
+// server to the message.
+func (w *bdatWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.sendChunk(true)
+}
+
+// sendChunk sends the buffered data with the BDAT command and reads the response of the server.
+func (w *bdatWriter) sendChunk(last bool) error {
+	command := fmt.Sprintf("BDAT %d", len(w.buffer))
+	if last {
+		command += " LAST"
+	}
+
+	w.c.mutex.Lock()
+	defer w.c.mutex.Unlock()
+	w.c.debugLog(log.DirClientToServer, "%s", command)
+	_, err := w.c.Text.W.WriteString(command + "\r\n")
+	if err == nil {
+		_, err = w.c.Text.W.Write(w.buffer)
+	}
+	if err == nil {
+		err = w.c.Text.W.Flush()
+	}
+	if err == nil {
+		var code int
+		var msg string
+		code, msg, err = w.c.Text.ReadResponse(250)
+		w.c.debugLog(log.DirServerToClient, "%d %s", code, msg)
+	}
+	w.buffer = w.buffer[:0]
+	w.err = err
+	return err
+}
+
 var testHookStartTLS func(*tls.Config) // nil, except for tests
 
 // SendMail connects to the server at addr, switches to TLS if
@@ -518,6 +800,39 @@ func (c *Client) Noop() error {
 	return err
 }
 
+// Command sends an arbitrary command with the given verb and arguments to the server and returns
+// the reply code and message of the server. It allows to issue commands that the Client does not
+// model itself, like EXPN or commands of custom ESMTP extensions. The arguments are omitted from
+// the command line if empty. A reply code of 400 or greater is returned as *textproto.Error, along
+// with the code and message.
+//
+// Command does not track the state of the SMTP session. Commands that change the state, like MAIL,
+// DATA or STARTTLS, should be sent via the corresponding methods of the Client instead, since the
+// Client is left in an inconsistent state otherwise.
+func (c *Client) Command(verb, args string) (int, string, error) {
+	if err := validateLine(verb); err != nil {
+		return 0, "", err
+	}
+	if err := validateLine(args); err != nil {
+		return 0, "", err
+	}
+	if strings.TrimSpace(verb) == "" || strings.ContainsAny(verb, " \t") {
+		return 0, "", fmt.Errorf("smtp: invalid command verb %q", verb)
+	}
+	if err := c.hello(); err != nil {
+		return 0, "", err
+	}
+	line := verb
+	if args != "" {
+		line += " " + args
+	}
+	code, msg, err := c.cmd(0, "%s", line)
+	if err == nil && code >= 400 {
+		err = &textproto.Error{Code: code, Msg: msg}
+	}
+	return code, msg, err
+}
+
 // Quit sends the QUIT command and closes the connection to the server.
 func (c *Client) Quit() error {
 	if err := c.hello(); err != nil {
@@ -566,6 +881,56 @@ func (c *Client) SetDSNRcptNotifyOption(d string) {
 	c.dsnrntype = d
 }
 
+// SetMailSize sets the size of the message that is declared with the SIZE parameter of
+// the MAIL command, if the server supports the SIZE extension (RFC 1870). A size of zero
+// or less omits the SIZE parameter.
+func (c *Client) SetMailSize(size int64) {
+	c.mutex.Lock()
+	c.mailSize = size
+	c.mutex.Unlock()
+}
+
+// SetCommandTimeout sets the timeout that is set as deadline on the connection before each SMTP
+// command, so that each command and its response is bound by the timeout on its own. The transfer
+// of the message content after the DATA command and the BDAT chunks are not bound by it. A timeout
+// of zero or less disables the per-command deadline, so that the deadline set via UpdateDeadline
+// applies.
+func (c *Client) SetCommandTimeout(timeout time.Duration) {
+	c.mutex.Lock()
+	c.commandTimeout = timeout
+	c.mutex.Unlock()
+}
+
+// SetMailParams sets additional parameters that are appended to the MAIL command, e.g.
+// "AUTH=<>" (RFC 4954) or "ENVID=QQ314159" (RFC 3461). Each parameter has the form
+// "keyword" or "keyword=value". The parameters are sent as they are, regardless of the
+// extensions advertised by the server. Calling SetMailParams without parameters removes
+// all previously set parameters.
+func (c *Client) SetMailParams(params ...string) error {
+	if err := ValidateParams(params...); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.mailParams = append([]string(nil), params...)
+	c.mutex.Unlock()
+	return nil
+}
+
+// SetRcptParams sets additional parameters that are appended to each RCPT command, e.g.
+// "ORCPT=rfc822;user@example.com" (RFC 3461). Each parameter has the form "keyword" or
+// "keyword=value". The parameters are sent as they are, regardless of the extensions
+// advertised by the server. Calling SetRcptParams without parameters removes all
+// previously set parameters.
+func (c *Client) SetRcptParams(params ...string) error {
+	if err := ValidateParams(params...); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.rcptParams = append([]string(nil), params...)
+	c.mutex.Unlock()
+	return nil
+}
+
 // HasConnection checks if the client has an active connection.
 // Returns true if the `conn` field is not nil, indicating an active connection.
 func (c *Client) HasConnection() bool {
@@ -585,6 +950,18 @@ func (c *Client) UpdateDeadline(timeout time.Duration) error {
 	return nil
 }
 
+// setCommandDeadline sets the deadline for the next command on the connection, if a command
+// timeout is set. The caller must hold the mutex.
+func (c *Client) setCommandDeadline() error {
+	if c.commandTimeout <= 0 {
+		return nil
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.commandTimeout)); err != nil {
+		return fmt.Errorf("smtp: failed to update deadline: %w", err)
+	}
+	return nil
+}
+
 // GetTLSConnectionState retrieves the TLS connection state of the client's current connection.
 // Returns an error if the connection is not using TLS or if the connection is not established.
 func (c *Client) GetTLSConnectionState() (*tls.ConnectionState, error) {
@@ -605,13 +982,35 @@ func (c *Client) GetTLSConnectionState() (*tls.ConnectionState, error) {
 }
 
 // debugLog checks if the debug flag is set and if so logs the provided message to
-// the log.Logger interface
+// the log.Logger interface. While an AUTH exchange is in progress, the credentials sent
+// to the server are redacted.
 func (c *Client) debugLog(d log.Direction, f string, a ...interface{}) {
 	if c.debug {
+		if c.isAuth && d == log.DirClientToServer {
+			f, a = "%s", []interface{}{redactAuthLine(fmt.Sprintf(f, a...))}
+		}
 		c.logger.Debugf(log.Log{Direction: d, Format: f, Messages: a})
 	}
 }
 
+// redactAuthLine replaces the credentials of a line sent during the AUTH exchange for
+// the debug log. The AUTH command keeps its mechanism and the "*" to cancel the exchange
+// is not redacted, since neither of them holds any credentials.
+func redactAuthLine(line string) string {
+	const redacted = "<redacted>"
+	if line == "*" {
+		return line
+	}
+	fields := strings.Fields(line)
+	if len(fields) > 0 && strings.EqualFold(fields[0], "AUTH") {
+		if len(fields) > 2 {
+			return fmt.Sprintf("%s %s %s", fields[0], fields[1], redacted)
+		}
+		return line
+	}
+	return redacted
+}
+
 // validateLine checks to see if a line has CR or LF as per RFC 5321.
 func validateLine(line string) error {
 	if strings.ContainsAny(line, "\n\r") {
@@ -619,3 +1018,20 @@ func validateLine(line string) error {
 	}
 	return nil
 }
+
+// ValidateParams checks that none of the given MAIL or RCPT parameters is empty or
+// contains spaces or control characters, which would break the command line. The
+// returned error wraps ErrInvalidParam.
+func ValidateParams(params ...string) error {
+	for _, param := range params {
+		if param == "" {
+			return ErrInvalidParam
+		}
+		for i := 0; i < len(param); i++ {
+			if param[i] <= ' ' || param[i] == 0x7f {
+				return fmt.Errorf("%w: %q", ErrInvalidParam, param)
+			}
+		}
+	}
+	return nil
+}
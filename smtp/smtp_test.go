@@ -22,6 +22,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"flag"
 	"fmt"
 	"hash"
@@ -295,6 +296,40 @@ func TestAuthLogin(t *testing.T) {
 	}
 }
 
+func TestSASLprep(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"ascii", "V3ryS3cr3t+", "V3ryS3cr3t+", false},
+		{"precomposed accents", "p\u00e4ssw\u00f6rd", "p\u00e4ssw\u00f6rd", false},
+		{"decomposed accents", "pa\u0308sswo\u0308rd", "p\u00e4ssw\u00f6rd", false},
+		{"non-ascii space", "secret\u00a0phrase", "secret phrase", false},
+		{"emoji", "p\u00e4ss\U0001F600", "p\u00e4ss\U0001F600", false},
+		{"empty", "", "", true},
+		{"control character", "pass\u0007word", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SASLprep(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SASLprep(%q) expected error, got: %q", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SASLprep(%q) failed: %s", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("SASLprep(%q) = %q; want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestXOAuth2OK(t *testing.T) {
 	server := []string{
 		"220 Fake server ready ESMTP",
@@ -662,6 +697,343 @@ func (f faker) SetDeadline(time.Time) error      { return nil }
 func (f faker) SetReadDeadline(time.Time) error  { return nil }
 func (f faker) SetWriteDeadline(time.Time) error { return nil }
 
+// pipelineWriter records every write to the connection as a separate chunk
+type pipelineWriter struct {
+	chunks []string
+}
+
+func (w *pipelineWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func TestClient_MailRcptPipelined(t *testing.T) {
+	tests := []struct {
+		name       string
+		ehlo       string
+		replies    []string
+		wantMail   bool
+		wantRcpt   []bool
+		wantBatch  bool
+		wantMailTo string
+	}{
+		{
+			"pipelining", "250-PIPELINING\r\n250 8BITMIME",
+			[]string{"250 OK", "250 OK", "550 5.1.1 No such user", "251 User not local; will forward"},
+			false, []bool{false, true, false}, true, "MAIL FROM:<from@domain.tld> BODY=8BITMIME",
+		},
+		{
+			"pipelining with rejected sender", "250 PIPELINING",
+			[]string{
+				"550 5.7.1 Sender rejected", "503 5.5.1 Need MAIL first", "503 5.5.1 Need MAIL first",
+				"503 5.5.1 Need MAIL first",
+			},
+			true, []bool{true, true, true}, true, "MAIL FROM:<from@domain.tld>",
+		},
+		{
+			"no pipelining", "250 8BITMIME",
+			[]string{"250 OK", "250 OK", "550 5.1.1 No such user", "250 OK"},
+			false, []bool{false, true, false}, false, "MAIL FROM:<from@domain.tld> BODY=8BITMIME",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := append([]string{"220 Fake server ready ESMTP", "250-fake.server\r\n" + tt.ehlo}, tt.replies...)
+			writer := &pipelineWriter{}
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				writer,
+			}
+			c, err := NewClient(fake, "fake.host")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			rcpts := []string{"one@domain.tld", "two@domain.tld", "three@domain.tld"}
+			rcptErrs, err := c.MailRcptPipelined("from@domain.tld", rcpts)
+			if (err != nil) != tt.wantMail {
+				t.Fatalf("MailRcptPipelined: unexpected MAIL error: %v", err)
+			}
+			if len(rcptErrs) != len(rcpts) {
+				t.Fatalf("MailRcptPipelined: expected %d recipient results, got: %d", len(rcpts), len(rcptErrs))
+			}
+			for i, wantErr := range tt.wantRcpt {
+				if (rcptErrs[i] != nil) != wantErr {
+					t.Errorf("MailRcptPipelined: unexpected error for recipient %s: %v", rcpts[i], rcptErrs[i])
+				}
+			}
+			batch := writer.chunks[len(writer.chunks)-1]
+			want := tt.wantMailTo + "\r\nRCPT TO:<one@domain.tld>\r\nRCPT TO:<two@domain.tld>\r\n" +
+				"RCPT TO:<three@domain.tld>\r\n"
+			if tt.wantBatch && batch != want {
+				t.Errorf("MailRcptPipelined: expected commands to be sent in a single batch %q, got: %q", want, batch)
+			}
+			if !tt.wantBatch && batch != "RCPT TO:<three@domain.tld>\r\n" {
+				t.Errorf("MailRcptPipelined: expected commands to be sent one by one, got: %q", writer.chunks)
+			}
+		})
+	}
+}
+
+func TestClient_MailRcptDataPipelined(t *testing.T) {
+	tests := []struct {
+		name      string
+		ehlo      string
+		replies   []string
+		wantRcpt  bool
+		wantData  bool
+		wantBatch bool
+	}{
+		{
+			"pipelining", "250 PIPELINING",
+			[]string{"250 OK", "250 OK", "354 Go ahead", "250 Queued"},
+			false, false, true,
+		},
+		{
+			"pipelining with rejected recipient", "250 PIPELINING",
+			[]string{"250 OK", "550 5.1.1 No such user", "554 5.5.1 No valid recipients"},
+			true, true, true,
+		},
+		{
+			"no pipelining", "250 8BITMIME",
+			[]string{"250 OK", "250 OK", "354 Go ahead", "250 Queued"},
+			false, false, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := append([]string{"220 Fake server ready ESMTP", "250-fake.server\r\n" + tt.ehlo}, tt.replies...)
+			writer := &pipelineWriter{}
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				writer,
+			}
+			c, err := NewClient(fake, "fake.host")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			rcptErrs, data, dataErr, err := c.MailRcptDataPipelined("from@domain.tld", []string{"to@domain.tld"})
+			if err != nil {
+				t.Fatalf("MailRcptDataPipelined: unexpected MAIL error: %v", err)
+			}
+			if len(rcptErrs) != 1 || (rcptErrs[0] != nil) != tt.wantRcpt {
+				t.Errorf("MailRcptDataPipelined: unexpected recipient results: %v", rcptErrs)
+			}
+			if (dataErr != nil) != tt.wantData || (data == nil) != tt.wantData {
+				t.Fatalf("MailRcptDataPipelined: unexpected DATA result: %v", dataErr)
+			}
+			batch := writer.chunks[len(writer.chunks)-1]
+			want := "MAIL FROM:<from@domain.tld>\r\nRCPT TO:<to@domain.tld>\r\nDATA\r\n"
+			if tt.wantBatch && batch != want {
+				t.Errorf("MailRcptDataPipelined: expected commands to be sent in a single write %q, got: %q",
+					want, writer.chunks)
+			}
+			if !tt.wantBatch && batch != "DATA\r\n" {
+				t.Errorf("MailRcptDataPipelined: expected commands to be sent one by one, got: %q", writer.chunks)
+			}
+			if data == nil {
+				return
+			}
+			if _, err = data.Write([]byte("Subject: test\r\n\r\nbody\r\n")); err != nil {
+				t.Fatalf("failed to write message: %v", err)
+			}
+			if err = data.Close(); err != nil {
+				t.Errorf("failed to close data writer: %v", err)
+			}
+			if got := strings.Join(writer.chunks, ""); !strings.HasSuffix(got, "body\r\n.\r\n") {
+				t.Errorf("MailRcptDataPipelined: expected message to be terminated by a dot, got: %q", got)
+			}
+		})
+	}
+}
+
+func TestClient_MailRcptParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		ehlo     string
+		wantMail string
+		wantRcpt string
+	}{
+		{
+			"with DSN", "250 DSN",
+			"MAIL FROM:<from@domain.tld> RET=HDRS AUTH=<> ENVID=QQ314159\r\n",
+			"RCPT TO:<to@domain.tld> NOTIFY=SUCCESS,FAILURE ORCPT=rfc822;to@domain.tld\r\n",
+		},
+		{
+			"without DSN", "250 8BITMIME",
+			"MAIL FROM:<from@domain.tld> BODY=8BITMIME AUTH=<> ENVID=QQ314159\r\n",
+			"RCPT TO:<to@domain.tld> ORCPT=rfc822;to@domain.tld\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := []string{"220 Fake server ready ESMTP", "250-fake.server\r\n" + tt.ehlo, "250 OK", "250 OK"}
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			c, err := NewClient(fake, "fake.host")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			c.SetDSNMailReturnOption("HDRS")
+			c.SetDSNRcptNotifyOption("SUCCESS,FAILURE")
+			if err = c.SetMailParams("AUTH=<>", "ENVID=QQ314159"); err != nil {
+				t.Fatalf("SetMailParams: %v", err)
+			}
+			if err = c.SetRcptParams("ORCPT=rfc822;to@domain.tld"); err != nil {
+				t.Fatalf("SetRcptParams: %v", err)
+			}
+			if err = c.Mail("from@domain.tld"); err != nil {
+				t.Fatalf("Mail: %v", err)
+			}
+			if err = c.Rcpt("to@domain.tld"); err != nil {
+				t.Fatalf("Rcpt: %v", err)
+			}
+			if !strings.HasSuffix(wrote.String(), tt.wantMail+tt.wantRcpt) {
+				t.Errorf("expected commands %q, got: %q", tt.wantMail+tt.wantRcpt, wrote.String())
+			}
+		})
+	}
+	c := &Client{}
+	for _, param := range []string{"", "AUTH=<> BODY=8BITMIME", "ENVID=1\r\nDATA"} {
+		if err := c.SetMailParams(param); !errors.Is(err, ErrInvalidParam) {
+			t.Errorf("SetMailParams(%q): expected error %s, got: %v", param, ErrInvalidParam, err)
+		}
+		if err := c.SetRcptParams(param); !errors.Is(err, ErrInvalidParam) {
+			t.Errorf("SetRcptParams(%q): expected error %s, got: %v", param, ErrInvalidParam, err)
+		}
+	}
+}
+
+func TestClient_Bdat(t *testing.T) {
+	// The content of the chunks is sent unchanged, hence bare line feeds and binary data are kept
+	body := ".leading dot\nbare line feed\r\nbinary \x00\xff\r\n" + strings.Repeat("x", bdatChunkSize)
+	wantContent := body
+	tests := []struct {
+		name     string
+		ehlo     string
+		replies  []string
+		wantErr  error
+		wantSent bool
+		closeErr bool
+	}{
+		{
+			"chunking", "250 CHUNKING", []string{"250 OK", "250 OK", "250 2.0.0 OK", "250 2.0.0 Queued"},
+			nil, true, false,
+		},
+		{
+			"chunk rejected", "250 CHUNKING", []string{"250 OK", "250 OK", "552 5.3.4 Message too big"},
+			nil, false, true,
+		},
+		{"no chunking", "250 8BITMIME", []string{"250 OK", "250 OK"}, ErrNoChunking, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := append([]string{"220 Fake server ready ESMTP", "250-fake.server\r\n" + tt.ehlo}, tt.replies...)
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			c, err := NewClient(fake, "fake.host")
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if err = c.Mail("from@domain.tld"); err != nil {
+				t.Fatalf("Mail: %v", err)
+			}
+			if err = c.Rcpt("to@domain.tld"); err != nil {
+				t.Fatalf("Rcpt: %v", err)
+			}
+			writer, err := c.Bdat()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Bdat: expected error %v, got: %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			_, writeErr := io.WriteString(writer, body)
+			closeErr := writer.Close()
+			if (writeErr != nil || closeErr != nil) != tt.closeErr {
+				t.Fatalf("Bdat: unexpected errors: %v, %v", writeErr, closeErr)
+			}
+			if !tt.wantSent {
+				return
+			}
+			sent := wrote.String()
+			wantFirst := fmt.Sprintf("BDAT %d\r\n%s", bdatChunkSize, wantContent[:bdatChunkSize])
+			wantLast := fmt.Sprintf("BDAT %d LAST\r\n%s", len(wantContent)-bdatChunkSize,
+				wantContent[bdatChunkSize:])
+			if !strings.HasSuffix(sent, wantFirst+wantLast) {
+				t.Errorf("Bdat: unexpected chunks sent: %q", sent[strings.Index(sent, "BDAT"):])
+			}
+		})
+	}
+}
+
+func TestClient_Command(t *testing.T) {
+	server := strings.Join([]string{
+		"220 Fake server ready ESMTP",
+		"250-fake.server\r\n250 8BITMIME",
+		"250 2.1.5 list members follow",
+		"252 2.0.0 Cannot VRFY user",
+		"550 5.7.1 Access denied",
+	}, "\r\n") + "\r\n"
+	var wrote strings.Builder
+	var fake faker
+	fake.ReadWriter = struct {
+		io.Reader
+		io.Writer
+	}{
+		strings.NewReader(server),
+		&wrote,
+	}
+	c, err := NewClient(fake, "fake.host")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for _, verb := range []string{"", "X-CMD ARG", "X-CMD\r\nQUIT"} {
+		if _, _, err = c.Command(verb, ""); err == nil {
+			t.Errorf("Command with verb %q should have failed", verb)
+		}
+	}
+	if _, _, err = c.Command("NOOP", "\r\nQUIT"); err == nil {
+		t.Error("Command should have failed due to a command injection attempt")
+	}
+	code, msg, err := c.Command("EXPN", "list")
+	if err != nil || code != 250 || msg != "2.1.5 list members follow" {
+		t.Errorf("Command EXPN: unexpected reply: %d %q, %v", code, msg, err)
+	}
+	if code, _, err = c.Command("VRFY", "user"); err != nil || code != 252 {
+		t.Errorf("Command VRFY: unexpected reply: %d, %v", code, err)
+	}
+	var protoErr *textproto.Error
+	code, _, err = c.Command("X-CUSTOM", "")
+	if !errors.As(err, &protoErr) || code != 550 || protoErr.Code != 550 {
+		t.Errorf("Command X-CUSTOM: expected error with code 550, got: %d, %v", code, err)
+	}
+	if !strings.HasSuffix(wrote.String(), "EXPN list\r\nVRFY user\r\nX-CUSTOM\r\n") {
+		t.Errorf("Command: unexpected commands sent: %q", wrote.String())
+	}
+}
+
 func TestBasic(t *testing.T) {
 	server := strings.Join(strings.Split(basicServer, "\n"), "\r\n")
 	client := strings.Join(strings.Split(basicClient, "\n"), "\r\n")
@@ -1080,6 +1452,54 @@ func TestClient_SetDebugLog(t *testing.T) {
 	}
 }
 
+// TestClient_SetCommandTimeout tests that the command timeout of the Client bounds each command on its own
+func TestClient_SetCommandTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go func() {
+		reader := bufio.NewReader(serverConn)
+		_, _ = serverConn.Write([]byte("220 hello world\r\n"))
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "EHLO") {
+				_, _ = serverConn.Write([]byte("250 fake.host\r\n"))
+				continue
+			}
+			// The server answers the first NOOP and stalls on the second one
+			if strings.HasPrefix(line, "NOOP") {
+				_, _ = serverConn.Write([]byte("250 OK\r\n"))
+				_, _ = io.Copy(io.Discard, reader)
+				return
+			}
+		}
+	}()
+	c, err := NewClient(clientConn, "fake.host")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer func() {
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+	}()
+	c.SetCommandTimeout(time.Millisecond * 200)
+	if err = c.UpdateDeadline(time.Millisecond * 50); err != nil {
+		t.Fatalf("failed to update deadline: %s", err)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if err = c.Noop(); err != nil {
+		t.Fatalf("Noop failed despite the command timeout renewing the deadline: %s", err)
+	}
+	start := time.Now()
+	if err = c.Noop(); err == nil {
+		t.Fatal("Noop on stalling server was supposed to fail, but didn't")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Errorf("Noop did not honor the command timeout, took: %s", elapsed)
+	}
+}
+
 // TestClient_SetLogger tests the Client method with the Client.SetLogger method
 // to provide a custom logger
 func TestClient_SetLogger(t *testing.T) {
@@ -1111,6 +1531,78 @@ func TestClient_SetLogger(t *testing.T) {
 	c.logger.Debugf(log.Log{Direction: log.DirServerToClient, Format: "%s", Messages: []interface{}{"test"}})
 }
 
+// TestClient_Auth_redactedDebugLog tests that the credentials of the AUTH exchange are redacted in the
+// debug log, while the commands sent to the server are not modified
+func TestClient_Auth_redactedDebugLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    Auth
+		server  []string
+		wantLog []string
+		secret  string
+	}{
+		{
+			"PLAIN", PlainAuth("", "toni@tester.com", "V3ryS3cr3t+", "localhost"),
+			[]string{"220 hello world", "250-localhost\r\n250 AUTH PLAIN LOGIN", "235 2.7.0 Accepted", "221 OK"},
+			[]string{"C --> S: AUTH PLAIN <redacted>", "C --> S: QUIT"},
+			base64.StdEncoding.EncodeToString([]byte("\x00toni@tester.com\x00V3ryS3cr3t+")),
+		},
+		{
+			"LOGIN", LoginAuth("toni@tester.com", "V3ryS3cr3t+", "localhost"),
+			[]string{
+				"220 hello world", "250-localhost\r\n250 AUTH PLAIN LOGIN", "334 VXNlcm5hbWU6", "334 UGFzc3dvcmQ6",
+				"235 2.7.0 Accepted", "221 OK",
+			},
+			[]string{"C --> S: AUTH LOGIN", "C --> S: <redacted>", "C <-- S: 334 VXNlcm5hbWU6", "C --> S: QUIT"},
+			base64.StdEncoding.EncodeToString([]byte("V3ryS3cr3t+")),
+		},
+		{
+			"failed LOGIN", LoginAuth("toni@tester.com", "V3ryS3cr3t+", "localhost"),
+			[]string{
+				"220 hello world", "250-localhost\r\n250 AUTH PLAIN LOGIN", "334 VXNlcm5hbWU6",
+				"535 5.7.8 Authentication failed", "501 Cancelled", "221 OK",
+			},
+			[]string{"C --> S: <redacted>", "C --> S: *", "C --> S: QUIT"},
+			base64.StdEncoding.EncodeToString([]byte("toni@tester.com")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wrote strings.Builder
+			var fake faker
+			fake.ReadWriter = struct {
+				io.Reader
+				io.Writer
+			}{
+				strings.NewReader(strings.Join(tt.server, "\r\n") + "\r\n"),
+				&wrote,
+			}
+			c, err := NewClient(fake, "localhost")
+			if err != nil {
+				t.Fatalf("NewClient: %s", err)
+			}
+			var logbuf bytes.Buffer
+			c.SetDebugLog(true)
+			c.SetLogger(log.New(&logbuf, log.LevelDebug))
+			_ = c.Auth(tt.auth)
+			_ = c.Quit()
+
+			logged := logbuf.String()
+			for _, want := range tt.wantLog {
+				if !strings.Contains(logged, want) {
+					t.Errorf("expected debug log to contain %q, got: %s", want, logged)
+				}
+			}
+			if strings.Contains(logged, tt.secret) {
+				t.Errorf("debug log must not contain the credentials, got: %s", logged)
+			}
+			if !strings.Contains(wrote.String(), tt.secret) {
+				t.Errorf("expected the credentials to be sent to the server unmodified, got: %s", wrote.String())
+			}
+		})
+	}
+}
+
 var newClientServer = `220 hello world
 250-mx.google.com at your service
 250-SIZE 35651584
@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+var (
+	// ErrInvalidAddress indicates that an address of the Msg does not conform to RFC 5322.
+	ErrInvalidAddress = errors.New("invalid mail address")
+
+	// ErrInvalidContentID indicates that the "Content-ID" of an attachment or embed of the Msg does not
+	// conform to RFC 2392.
+	ErrInvalidContentID = errors.New("invalid Content-ID")
+
+	// ErrNoSenderAddress indicates that the Msg has multiple "From" addresses but no "Sender" address, which
+	// RFC 5322 requires in this case.
+	ErrNoSenderAddress = errors.New("no Sender address set for multiple FROM addresses")
+
+	// ErrInvalidDateHeader indicates that the "Date" header of the Msg is not a valid RFC 5322 date.
+	ErrInvalidDateHeader = errors.New("invalid Date header")
+
+	// ErrUnknownCharset indicates that the charset of the Msg or of one of its parts is not a charset
+	// known to this package.
+	ErrUnknownCharset = errors.New("unknown charset")
+
+	// ErrInvalidHeader indicates that the name or the value of a header field contains line breaks or
+	// control characters, which could be used to inject additional header fields into the Msg.
+	ErrInvalidHeader = errors.New("invalid header field")
+
+	// ErrTooManyAttachments indicates that the number of attachments of the Msg exceeds the configured limit.
+	ErrTooManyAttachments = errors.New("too many attachments")
+
+	// ErrAttachmentsTooLarge indicates that the total encoded size of the attachments of the Msg exceeds the
+	// configured limit.
+	ErrAttachmentsTooLarge = errors.New("attachments too large")
+)
+
+// ValidationError is an error wrapper for all problems found while validating a Msg.
+//
+// This struct collects every problem that Msg.Validate detects, so that all of them can be reported
+// at once instead of one after another. The individual errors are available via the Errors method
+// and can be checked using errors.Is on the ValidationError.
+type ValidationError struct {
+	errlist []error
+}
+
+// Error implements the error interface for the ValidationError type.
+//
+// Returns:
+//   - A string listing all problems found during validation.
+func (e *ValidationError) Error() string {
+	var errMessage strings.Builder
+	errMessage.WriteString("message validation failed:")
+	for i := range e.errlist {
+		errMessage.WriteRune(' ')
+		errMessage.WriteString(e.errlist[i].Error())
+		if i != len(e.errlist)-1 {
+			errMessage.WriteString(",")
+		}
+	}
+	return errMessage.String()
+}
+
+// Errors returns the list of problems found during the validation of the Msg.
+//
+// Returns:
+//   - A slice of errors, one for each problem found.
+func (e *ValidationError) Errors() []error {
+	return e.errlist
+}
+
+// Is implements the errors.Is functionality for the ValidationError type.
+//
+// This function reports whether any of the problems found during validation matches the
+// provided error, so that e.g. errors.Is(err, ErrNoRcptAddresses) works on a ValidationError.
+//
+// Parameters:
+//   - errType: The error to compare against the errors of the ValidationError.
+//
+// Returns:
+//   - true if any of the collected errors matches errType, false otherwise.
+func (e *ValidationError) Is(errType error) bool {
+	for _, err := range e.errlist {
+		if errors.Is(err, errType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the Msg for problems that would cause its delivery to fail.
+//
+// This method verifies that all addresses of the "From", "Sender", "To", "Cc" and "Bcc" headers as well as the
+// envelope from address, unless it is the null reverse-path, conform to RFC 5322, that a sender and at
+// least one recipient address is set, that a "Sender" address is set if the Msg has multiple "From" addresses,
+// that the "Date" header, if already set, holds a valid RFC 5322 date, that the "Content-ID" headers set for
+// attachments and embeds conform to RFC 2392 and that the charsets of the Msg and its parts are known. Instead
+// of stopping at the first problem, all problems are collected and returned at once as ValidationError. Header
+// fields whose name or value contains line breaks or control characters are reported as well. Validate does not
+// perform any network I/O. The Client performs the validation automatically before sending a Msg if the
+// WithValidation option is set.
+//
+// Returns:
+//   - A ValidationError listing all problems found, or nil if the Msg is valid.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.4
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6
+func (m *Msg) Validate() error {
+	var errs []error
+
+	if len(m.addrHeader[HeaderFrom]) == 0 && (len(m.addrHeader[HeaderEnvelopeFrom]) == 0 || m.hasNullEnvelopeFrom()) {
+		errs = append(errs, ErrNoFromAddress)
+	}
+	for _, header := range []AddrHeader{
+		HeaderEnvelopeFrom, HeaderFrom, HeaderSender, HeaderTo, HeaderCc, HeaderBcc,
+	} {
+		for _, address := range m.addrHeader[header] {
+			if header == HeaderEnvelopeFrom && m.hasNullEnvelopeFrom() {
+				continue
+			}
+			if address == nil || address.Address == "" {
+				errs = append(errs, fmt.Errorf("%w: %s: empty address", ErrInvalidAddress, header))
+				continue
+			}
+			if _, err := mail.ParseAddress(address.String()); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %q: %s", ErrInvalidAddress, header, address.Address,
+					err.Error()))
+			}
+		}
+	}
+	if len(m.addrHeader[HeaderFrom]) > 1 && len(m.addrHeader[HeaderSender]) == 0 {
+		errs = append(errs, fmt.Errorf("%w: %d FROM addresses are set", ErrNoSenderAddress,
+			len(m.addrHeader[HeaderFrom])))
+	}
+	if _, err := m.GetRecipients(); err != nil {
+		errs = append(errs, err)
+	}
+	for header, values := range m.genHeader {
+		for _, value := range values {
+			if err := validateHeaderField(header.String(), value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for header, value := range m.preformHeader {
+		if err := validateHeaderField(header.String(), value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, files := range [][]*File{m.attachments, m.embeds} {
+		for _, file := range files {
+			if contentID := file.Header.Get(HeaderContentID.String()); contentID != "" && !isValidContentID(contentID) {
+				errs = append(errs, fmt.Errorf("%w: %s: %q", ErrInvalidContentID, file.Name, contentID))
+			}
+		}
+	}
+	if m.charset != "" && !m.charset.IsKnown() {
+		errs = append(errs, fmt.Errorf("%w: %q", ErrUnknownCharset, m.charset))
+	}
+	for _, part := range m.parts {
+		if !part.isDeleted && part.charset != "" && part.charset != m.charset && !part.charset.IsKnown() {
+			errs = append(errs, fmt.Errorf("%w: %s part: %q", ErrUnknownCharset, part.contentType, part.charset))
+		}
+	}
+	if date := m.GetGenHeader(HeaderDate); len(date) > 0 {
+		if _, err := mail.ParseDate(date[0]); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidDateHeader, date[0]))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{errlist: errs}
+	}
+	return nil
+}
+
+// ValidateAttachmentLimits checks the attachments of the Msg against the given count and size limits.
+//
+// This method allows to enforce the attachment policy of a mail provider, e.g. "at most 10 attachments
+// with a total of 25 MB", before the Msg is sent. The size of the attachments is determined via
+// TotalAttachmentSize, i.e. it includes the overhead of the transfer encoding, so that it matches the
+// size counted by the server. Both limits are checked and all violations are returned at once as
+// ValidationError. The Client performs this check automatically before sending a Msg if the
+// WithAttachmentLimits option is set.
+//
+// Parameters:
+//   - maxCount: The maximum number of attachments. Zero means that the number is not limited.
+//   - maxTotalBytes: The maximum total size of all attachments in bytes after the transfer encoding.
+//     Zero means that the size is not limited.
+//
+// Returns:
+//   - A ValidationError listing the exceeded limits, or nil if the attachments are within the limits.
+func (m *Msg) ValidateAttachmentLimits(maxCount int, maxTotalBytes int64) error {
+	var errs []error
+	if maxCount > 0 && len(m.attachments) > maxCount {
+		errs = append(errs, fmt.Errorf("%w: %d attachments exceed the limit of %d attachments",
+			ErrTooManyAttachments, len(m.attachments), maxCount))
+	}
+	if maxTotalBytes > 0 {
+		size, err := m.encodedAttachmentSize()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if err == nil && size > maxTotalBytes {
+			errs = append(errs, fmt.Errorf("%w: encoded size of %d bytes exceeds the limit of %d bytes",
+				ErrAttachmentsTooLarge, size, maxTotalBytes))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{errlist: errs}
+	}
+	return nil
+}
+
+// validateHeaderField checks a header field for characters that would break the header section of a Msg.
+//
+// The field name must only consist of printable ASCII characters except the colon. The field value must
+// not contain any control characters except the horizontal tab. A line break (CRLF) in the field value is
+// only permitted if it is followed by a space or a tab, i.e. if it folds the value, so that no additional
+// header field can be injected.
+//
+// Parameters:
+//   - key: The name of the header field.
+//   - value: The value of the header field.
+//
+// Returns:
+//   - An error wrapping ErrInvalidHeader if the header field contains illegal characters; otherwise, returns nil.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-2.2
+func validateHeaderField(key, value string) error {
+	if key == "" {
+		return fmt.Errorf("%w: empty field name", ErrInvalidHeader)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] >= 0x7f || key[i] == ':' {
+			return fmt.Errorf("%w: %q: illegal character in field name", ErrInvalidHeader, key)
+		}
+	}
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+		switch {
+		case char == '\r' && i+2 < len(value) && value[i+1] == '\n' && (value[i+2] == ' ' || value[i+2] == '\t'):
+			i++
+		case char == '\t':
+		case char < ' ' || char == 0x7f:
+			return fmt.Errorf("%w: %s: illegal control character in field value", ErrInvalidHeader, key)
+		}
+	}
+	return nil
+}
+
+// isValidContentID reports whether the given value is a valid "Content-ID".
+//
+// A valid Content-ID has the form of an addr-spec, i.e. "id-left@id-right", and is optionally enclosed in
+// angle brackets. Both sides of the "@" must not be empty and must only consist of printable ASCII
+// characters except the RFC 5322 specials.
+//
+// Parameters:
+//   - contentID: The value of the "Content-ID" header.
+//
+// Returns:
+//   - true if the Content-ID is valid, false otherwise.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc2392#section-2
+//   - https://datatracker.ietf.org/doc/html/rfc5322#section-3.6.4
+func isValidContentID(contentID string) bool {
+	if strings.HasPrefix(contentID, "<") != strings.HasSuffix(contentID, ">") {
+		return false
+	}
+	contentID = strings.TrimSuffix(strings.TrimPrefix(contentID, "<"), ">")
+	separator := strings.LastIndex(contentID, "@")
+	if separator <= 0 || separator == len(contentID)-1 {
+		return false
+	}
+	for i := 0; i < len(contentID); i++ {
+		char := contentID[i]
+		if i == separator {
+			continue
+		}
+		if char <= ' ' || char >= 0x7f || strings.IndexByte(`()<>[]:;@\,"`, char) != -1 {
+			return false
+		}
+	}
+	return true
+}
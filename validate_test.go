@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+// TestMsg_Validate tests the Msg.Validate method
+func TestMsg_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*Msg)
+		want  []error
+	}{
+		{"valid message", func(*Msg) {}, nil},
+		{
+			"envelope from only", func(m *Msg) {
+				delete(m.addrHeader, HeaderFrom)
+				_ = m.EnvelopeFrom("bounces@domain.tld")
+			}, nil,
+		},
+		{"no sender", func(m *Msg) { delete(m.addrHeader, HeaderFrom) }, []error{ErrNoFromAddress}},
+		{"no recipients", func(m *Msg) { delete(m.addrHeader, HeaderTo) }, []error{ErrNoRcptAddresses}},
+		{
+			"multiple from without sender", func(m *Msg) { _ = m.AddFrom("co-author@domain.tld") },
+			[]error{ErrNoSenderAddress},
+		},
+		{
+			"multiple from with sender", func(m *Msg) {
+				_ = m.AddFrom("co-author@domain.tld")
+				_ = m.SetSender("robot@domain.tld")
+			}, nil,
+		},
+		{
+			"invalid recipient", func(m *Msg) {
+				m.addrHeader[HeaderCc] = []*mail.Address{{Address: "invalid"}}
+			}, []error{ErrInvalidAddress},
+		},
+		{
+			"empty bcc address", func(m *Msg) {
+				m.addrHeader[HeaderBcc] = []*mail.Address{{Name: "Nobody"}}
+			}, []error{ErrInvalidAddress},
+		},
+		{"invalid date", func(m *Msg) { m.SetGenHeader(HeaderDate, "yesterday") }, []error{ErrInvalidDateHeader}},
+		{
+			"attachment content id", func(m *Msg) {
+				m.AttachFile("README.md", WithFileContentID("<edi-4711@partner.example.com>"))
+				m.AttachFile("doc.go", WithFileContentID("edi-4712@partner.example.com"))
+			}, nil,
+		},
+		{
+			"invalid content id", func(m *Msg) {
+				m.AttachFile("README.md", WithFileContentID("no address"))
+				m.EmbedFile("doc.go", WithFileContentID("<unbalanced@example.com"))
+			}, []error{ErrInvalidContentID, ErrInvalidContentID},
+		},
+		{
+			"header injection", func(m *Msg) {
+				m.SetGenHeaderPreformatted(HeaderSubject, "Subject\r\nBcc: attacker@evil")
+			}, []error{ErrInvalidHeader},
+		},
+		{
+			"folded preformatted header", func(m *Msg) {
+				m.SetGenHeaderPreformatted(HeaderSubject, "Folded\r\n subject")
+			}, nil,
+		},
+		{
+			"legacy charset", func(m *Msg) {
+				m.SetCharset(CharsetISO88591)
+				m.AddAlternativeString(TypeTextHTML, "<p>Test body</p>", WithPartCharset("iso-8859-15"))
+			}, nil,
+		},
+		{
+			"unknown charset", func(m *Msg) {
+				m.SetCharset("X-LEGACY")
+				m.AddAlternativeString(TypeTextHTML, "<p>Test body</p>", WithPartCharset(CharsetUnknown))
+			}, []error{ErrUnknownCharset, ErrUnknownCharset},
+		},
+		{
+			"multiple problems", func(m *Msg) {
+				m.addrHeader = make(map[AddrHeader][]*mail.Address)
+				m.addrHeader[HeaderFrom] = []*mail.Address{{Address: "invalid@"}}
+			}, []error{ErrInvalidAddress, ErrNoRcptAddresses},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := newPoolTestMsg(t)
+			tt.setup(message)
+			err := message.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() failed: %s", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() expected ValidationError, got: %v", err)
+			}
+			if len(validationErr.Errors()) != len(tt.want) {
+				t.Errorf("Validate() expected %d errors, got: %v", len(tt.want), validationErr.Errors())
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate() expected error: %s, got: %s", want, err)
+				}
+			}
+		})
+	}
+}
+
+// TestClient_WithValidation tests that the Client validates each Msg before sending it
+func TestClient_WithValidation(t *testing.T) {
+	output := &strings.Builder{}
+	client, err := NewClient("invalid.host.tld", WithDryRunOutput(output), WithValidation())
+	if err != nil {
+		t.Fatalf("failed to create new client: %s", err)
+	}
+	valid := newPoolTestMsg(t)
+	invalid := newPoolTestMsg(t)
+	invalid.addrHeader[HeaderTo] = []*mail.Address{{Address: "invalid"}}
+	invalid.SetGenHeader(HeaderDate, "yesterday")
+
+	err = client.DialAndSend(valid, invalid)
+	if !errors.Is(err, &SendError{Reason: ErrMsgValidation}) {
+		t.Fatalf("DialAndSend expected validation error, got: %v", err)
+	}
+	for _, want := range []string{ErrInvalidAddress.Error(), ErrInvalidDateHeader.Error()} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("DialAndSend expected error to contain %q, got: %s", want, err)
+		}
+	}
+	if valid.HasSendError() || !strings.Contains(output.String(), "Test body") {
+		t.Errorf("DialAndSend failed. Valid message was expected to be sent: %v", valid.SendError())
+	}
+	if !invalid.HasSendError() {
+		t.Error("DialAndSend failed. Invalid message was expected to have a send error")
+	}
+}
+
+// TestMsg_ValidateAttachmentLimits tests the Msg.ValidateAttachmentLimits method
+func TestMsg_ValidateAttachmentLimits(t *testing.T) {
+	message := newPoolTestMsg(t)
+	for i := 0; i < 3; i++ {
+		if err := message.AttachReader(fmt.Sprintf("file%d.bin", i),
+			bytes.NewReader(bytes.Repeat([]byte("a"), 57))); err != nil {
+			t.Fatalf("AttachReader failed: %s", err)
+		}
+	}
+	tests := []struct {
+		name          string
+		maxCount      int
+		maxTotalBytes int64
+		wantErrs      []error
+	}{
+		{"no limits", 0, 0, nil},
+		{"within limits", 3, 234, nil},
+		{"too many attachments", 2, 0, []error{ErrTooManyAttachments}},
+		{"encoded size exceeded", 0, 233, []error{ErrAttachmentsTooLarge}},
+		{"raw size within limit", 0, 171, []error{ErrAttachmentsTooLarge}},
+		{"both limits exceeded", 1, 100, []error{ErrTooManyAttachments, ErrAttachmentsTooLarge}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := message.ValidateAttachmentLimits(tt.maxCount, tt.maxTotalBytes)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateAttachmentLimits failed: %s", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateAttachmentLimits expected ValidationError, got: %v", err)
+			}
+			if len(validationErr.Errors()) != len(tt.wantErrs) {
+				t.Errorf("ValidateAttachmentLimits expected %d errors, got: %s", len(tt.wantErrs), err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("ValidateAttachmentLimits expected error: %s, got: %s", want, err)
+				}
+			}
+		})
+	}
+}
+
+// TestClient_WithAttachmentLimits tests that the Client enforces the attachment limits before sending each Msg
+func TestClient_WithAttachmentLimits(t *testing.T) {
+	for _, limits := range [][2]int64{{-1, 0}, {0, -1}, {0, 0}} {
+		_, err := NewMemoryClient(WithAttachmentLimits(int(limits[0]), limits[1]))
+		if !errors.Is(err, ErrInvalidAttachmentLimits) {
+			t.Errorf("WithAttachmentLimits(%d, %d) expected error: %s, got: %v", limits[0], limits[1],
+				ErrInvalidAttachmentLimits, err)
+		}
+	}
+	client, err := NewMemoryClient(WithAttachmentLimits(1, 100))
+	if err != nil {
+		t.Fatalf("NewMemoryClient failed: %s", err)
+	}
+	valid := newPoolTestMsg(t)
+	if err = valid.AttachReader("small.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 57))); err != nil {
+		t.Fatalf("AttachReader failed: %s", err)
+	}
+	tooLarge := newPoolTestMsg(t)
+	if err = tooLarge.AttachReader("large.bin", bytes.NewReader(bytes.Repeat([]byte("a"), 100))); err != nil {
+		t.Fatalf("AttachReader failed: %s", err)
+	}
+
+	err = client.DialAndSend(valid, tooLarge)
+	if !errors.Is(err, &SendError{Reason: ErrMsgValidation}) {
+		t.Fatalf("DialAndSend expected validation error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), ErrAttachmentsTooLarge.Error()) {
+		t.Errorf("DialAndSend expected error to contain %q, got: %s", ErrAttachmentsTooLarge, err)
+	}
+	if !valid.IsDelivered() || tooLarge.IsDelivered() {
+		t.Errorf("DialAndSend failed. Expected only the message within the limits to be delivered")
+	}
+	if len(client.Messages()) != 1 {
+		t.Errorf("DialAndSend failed. Expected 1 delivered message, got: %d", len(client.Messages()))
+	}
+}
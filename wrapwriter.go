@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// flowedSignatureSeparator is the signature separator line, which must not be treated as flowed line
+// in a format=flowed body, although it ends with a space.
+const flowedSignatureSeparator = "-- "
+
+// wrapWriteFunc returns a write function that wraps the content written by the given write function via
+// a wrapWriter.
+//
+// Parameters:
+//   - writeFunc: The write function of the content to be wrapped.
+//   - column: The column at which the lines are wrapped.
+//   - flowed: If true, the lines are wrapped according to RFC 3676 for a format=flowed body.
+//   - delSp: If true, the soft line breaks of a format=flowed body are written for delsp=yes.
+//
+// Returns:
+//   - A write function that writes the wrapped content to the given io.Writer.
+func wrapWriteFunc(writeFunc func(io.Writer) (int64, error), column int,
+	flowed, delSp bool,
+) func(io.Writer) (int64, error) {
+	return func(writer io.Writer) (int64, error) {
+		wrapper := newWrapWriter(writer, column, flowed, delSp)
+		n, err := writeFunc(wrapper)
+		if err != nil {
+			return n, err
+		}
+		return n, wrapper.Close()
+	}
+}
+
+// wrapWriter is a writer that wraps the lines of plain text at a given column on word boundaries and
+// implements the io.WriteCloser interface.
+//
+// The text is wrapped before it is encoded, so that encoded sequences, like the "=XX" sequences of the
+// quoted-printable encoding, are never affected. Words that are longer than the column are not split.
+// If flowed is set, the lines are wrapped with soft line breaks according to RFC 3676, i.e. a wrapped
+// line ends with a trailing space, and lines are space-stuffed where required. If delSp is set in
+// addition, an extra space is added to each soft line break, since it is deleted when the lines are
+// unflowed again. Otherwise, the lines are hard-wrapped.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4
+type wrapWriter struct {
+	column int
+	delSp  bool
+	flowed bool
+	line   []byte
+	w      io.Writer
+}
+
+// newWrapWriter returns a new wrapWriter that writes to the given io.Writer.
+//
+// Parameters:
+//   - writer: The io.Writer the wrapped text is written to.
+//   - column: The column at which the lines are wrapped.
+//   - flowed: If true, the lines are wrapped according to RFC 3676 for a format=flowed body.
+//   - delSp: If true, the soft line breaks of a format=flowed body are written for delsp=yes.
+//
+// Returns:
+//   - A pointer to the newly created wrapWriter.
+func newWrapWriter(writer io.Writer, column int, flowed, delSp bool) *wrapWriter {
+	return &wrapWriter{column: column, delSp: delSp, flowed: flowed, w: writer}
+}
+
+// Write buffers the given data line by line and writes each complete line wrapped to the underlying
+// io.Writer. An incomplete last line is not written until the wrapWriter is closed.
+//
+// Parameters:
+//   - data: A byte slice containing the text to be wrapped.
+//
+// Returns:
+//   - The number of bytes consumed from data.
+//   - An error if writing to the underlying io.Writer fails.
+func (w *wrapWriter) Write(data []byte) (int, error) {
+	n := 0
+	for len(data) > 0 {
+		index := bytes.IndexByte(data, '\n')
+		if index == -1 {
+			w.line = append(w.line, data...)
+			return n + len(data), nil
+		}
+		w.line = append(w.line, data[:index+1]...)
+		if err := w.writeLine(); err != nil {
+			return n, err
+		}
+		n += index + 1
+		data = data[index+1:]
+	}
+	return n, nil
+}
+
+// Close writes the buffered incomplete last line, if any, to the underlying io.Writer.
+//
+// Returns:
+//   - An error if writing to the underlying io.Writer fails.
+func (w *wrapWriter) Close() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	return w.writeLine()
+}
+
+// writeLine wraps the buffered line and writes it to the underlying io.Writer.
+//
+// Inserted line breaks use the line ending of the buffered line, or CRLF if the line has no line ending.
+//
+// Returns:
+//   - An error if writing to the underlying io.Writer fails.
+func (w *wrapWriter) writeLine() error {
+	line := string(w.line)
+	w.line = w.line[:0]
+	ending := ""
+	switch {
+	case strings.HasSuffix(line, "\r\n"):
+		ending = "\r\n"
+	case strings.HasSuffix(line, "\n"):
+		ending = "\n"
+	}
+	line = strings.TrimSuffix(line, ending)
+	lineBreak := ending
+	if lineBreak == "" {
+		lineBreak = "\r\n"
+	}
+
+	lines := wrapLine(line, w.column, w.flowed, w.delSp)
+	_, err := io.WriteString(w.w, strings.Join(lines, lineBreak)+ending)
+	return err
+}
+
+// wrapLine wraps a single line of text at the given column on word boundaries.
+//
+// For a format=flowed body, trailing spaces of the line are removed, since it ends with a hard line
+// break, and the wrapped lines end with a space as soft line break, or with two spaces for delsp=yes,
+// so that the space between the words is kept when the soft line break is deleted. Quoted lines keep their quote
+// depth on each wrapped line, and lines are space-stuffed if they would otherwise be interpreted as
+// quoted, or if they start with a space or "From ". The signature separator is kept untouched.
+//
+// Parameters:
+//   - line: The line of text without its line ending.
+//   - column: The column at which the line is wrapped, counted in characters.
+//   - flowed: If true, the line is wrapped according to RFC 3676.
+//   - delSp: If true, the soft line breaks are written for delsp=yes.
+//
+// Returns:
+//   - A slice of the wrapped lines, without line endings.
+//
+// References:
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4.2
+//   - https://datatracker.ietf.org/doc/html/rfc3676#section-4.4
+func wrapLine(line string, column int, flowed, delSp bool) []string {
+	prefix := ""
+	if flowed {
+		if line == flowedSignatureSeparator {
+			return []string{line}
+		}
+		line = strings.TrimRight(line, " ")
+		if quotes := len(line) - len(strings.TrimLeft(line, ">")); quotes > 0 {
+			prefix = line[:quotes] + " "
+			line = strings.TrimPrefix(line[quotes:], " ")
+		}
+	}
+
+	// The soft line break of a format=flowed body counts towards the column
+	softBreak := " "
+	if delSp {
+		softBreak = "  "
+	}
+	width := column
+	if flowed {
+		width -= len(softBreak)
+	}
+	var lines []string
+	current := ""
+	for i, word := range strings.Split(line, " ") {
+		if i == 0 {
+			current = word
+			continue
+		}
+		if strings.TrimSpace(current) != "" &&
+			utf8.RuneCountInString(prefix+current+" "+word) > width {
+			if flowed {
+				lines = append(lines, current+softBreak)
+			}
+			if !flowed {
+				lines = append(lines, strings.TrimRight(current, " "))
+			}
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	lines = append(lines, current)
+
+	for i := range lines {
+		if flowed && prefix == "" && (strings.HasPrefix(lines[i], " ") || strings.HasPrefix(lines[i], ">") ||
+			strings.HasPrefix(lines[i], "From ")) {
+			lines[i] = " " + lines[i]
+		}
+		if prefix != "" && lines[i] == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + lines[i]
+	}
+	return lines
+}
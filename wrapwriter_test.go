@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2022-2023 The go-mail Authors
+//
+// SPDX-License-Identifier: MIT
+
+package mail
+
+import (
+	"bytes"
+	"mime/quotedprintable"
+	"strings"
+	"testing"
+)
+
+// TestWrapWriter tests that the wrapWriter wraps lines on word boundaries, hard or flowed
+func TestWrapWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		flowed bool
+		data   string
+		want   string
+	}{
+		{"short line", false, "Short line\r\n", "Short line\r\n"},
+		{
+			"hard wrap", false, "This line is very long so it should be wrapped.\r\nNext",
+			"This line is very\r\nlong so it should be\r\nwrapped.\r\nNext",
+		},
+		{"unix line endings", false, "This line is very long\n", "This line is very\nlong\n"},
+		{
+			"long word", false, "Link: https://example.com/a/very/long/path ok",
+			"Link:\r\nhttps://example.com/a/very/long/path\r\nok",
+		},
+		{"umlauts", false, "Grüße aus Köln, Düsseldorf und Aachen", "Grüße aus Köln,\r\nDüsseldorf und\r\nAachen"},
+		{
+			"flowed wrap", true, "This line is very long so it should be wrapped.  \r\n",
+			"This line is very \r\nlong so it should \r\nbe wrapped.\r\n",
+		},
+		{
+			"flowed quoted", true, ">> Quoted text that is long enough to be wrapped\r\n>\r\n",
+			">> Quoted text that \r\n>> is long enough \r\n>> to be wrapped\r\n>\r\n",
+		},
+		{
+			"flowed space-stuffing", true, " Indented\r\nSome words before From the sender >quoted\r\n",
+			"  Indented\r\nSome words before \r\n From the sender \r\n >quoted\r\n",
+		},
+		{"flowed signature separator", true, "-- \r\nToni", "-- \r\nToni"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			writer := newWrapWriter(&buf, 20, tt.flowed, false)
+			// Write byte by byte, to make sure that lines are buffered across writes
+			for i := 0; i < len(tt.data); i++ {
+				if _, err := writer.Write([]byte{tt.data[i]}); err != nil {
+					t.Fatalf("failed to write: %s", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close writer: %s", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("wrapWriter failed. Want: %q, got: %q", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+// TestMsg_SetBodyString_WithPartWrapAt tests that the plain text body of a Msg is wrapped before it is
+// quoted-printable encoded
+func TestMsg_SetBodyString_WithPartWrapAt(t *testing.T) {
+	body := "Grüße! This is a test mail. Please do not reply to this. Also this line is very long so it " +
+		"should be wrapped."
+	tests := []struct {
+		name  string
+		opts  []PartOption
+		lines []string
+	}{
+		{"disabled", nil, []string{body}},
+		{
+			"hard wrap", []PartOption{WithPartWrapAt(40)},
+			[]string{
+				"Grüße! This is a test mail. Please do", "not reply to this. Also this line is",
+				"very long so it should be wrapped.",
+			},
+		},
+		{
+			"flowed", []PartOption{WithPartWrapAt(40), WithPartContentTypeParam("format", "flowed")},
+			[]string{
+				"Grüße! This is a test mail. Please do ", "not reply to this. Also this line is ",
+				"very long so it should be wrapped.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMsg()
+			m.SetBodyString(TypeTextPlain, body, tt.opts...)
+			m.AddAlternativeString(TypeTextHTML, "<p>"+body+"</p>", WithPartWrapAt(40))
+			buf := bytes.Buffer{}
+			if _, err := m.WriteTo(&buf); err != nil {
+				t.Fatalf("failed to write message: %s", err)
+			}
+			message := buf.String()
+			start := strings.Index(message, "Content-Type: text/plain")
+			end := strings.Index(message, "Content-Type: text/html")
+			if start == -1 || end == -1 {
+				t.Fatalf("expected text/plain and text/html parts in message: %s", message)
+			}
+			plain := message[strings.Index(message[start:], "\r\n\r\n")+start+4 : end]
+			plain = plain[:strings.LastIndex(plain, "\r\n--")]
+			decoded := bytes.Buffer{}
+			if _, err := decoded.ReadFrom(quotedprintable.NewReader(strings.NewReader(plain))); err != nil {
+				t.Fatalf("failed to decode text/plain part: %s", err)
+			}
+			if got := strings.Split(decoded.String(), "\r\n"); strings.Join(got, "|") != strings.Join(tt.lines, "|") {
+				t.Errorf("unexpected text/plain lines. Want: %q, got: %q", tt.lines, got)
+			}
+			if !strings.Contains(message, "Please do not reply to this. Also") {
+				t.Errorf("expected text/html part not to be wrapped, got: %s", message)
+			}
+		})
+	}
+}